@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/alist-org/alist/v3/internal/backup"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var backupPassword string
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Export or import the settings, users, storages and metas",
+}
+
+var backupExportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Export the settings, users, storages and metas to the file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		b, err := backup.Export(backupPassword)
+		if err != nil {
+			utils.Log.Errorf("failed to export: %+v", err)
+			return
+		}
+		data, err := utils.Json.MarshalIndent(b, "", "  ")
+		if err != nil {
+			utils.Log.Errorf("failed to marshal the backup: %+v", err)
+			return
+		}
+		// the backup may contain the secrets in plain
+		if err = os.WriteFile(args[0], data, 0600); err != nil {
+			utils.Log.Errorf("failed to write the backup: %+v", err)
+			return
+		}
+		utils.Log.Infof("exported %d settings, %d users, %d groups, %d storages and %d metas to %s",
+			len(b.Settings), len(b.Users), len(b.Groups), len(b.Storages), len(b.Metas), args[0])
+	},
+}
+
+var backupImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import the settings, users, storages and metas from the file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			utils.Log.Errorf("failed to read the backup: %+v", err)
+			return
+		}
+		var b backup.Bundle
+		if err = utils.Json.Unmarshal(data, &b); err != nil {
+			utils.Log.Errorf("invalid backup: %+v", err)
+			return
+		}
+		res, err := backup.Import(&b, backupPassword)
+		if err != nil {
+			utils.Log.Errorf("failed to import: %+v", err)
+			return
+		}
+		for name, msg := range res.Failed {
+			utils.Log.Warnf("failed to import %s: %s", name, msg)
+		}
+		utils.Log.Infof("imported %d settings, %d users, %d groups, %d storages and %d metas",
+			res.Settings, res.Users, res.Groups, res.Storages, res.Metas)
+	},
+}
+
+func init() {
+	backupCmd.PersistentFlags().StringVar(&backupPassword, "password", "", "the password encrypting the secrets in the backup")
+	backupCmd.AddCommand(backupExportCmd, backupImportCmd)
+	rootCmd.AddCommand(backupCmd)
+}
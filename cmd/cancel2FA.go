@@ -4,6 +4,7 @@ Copyright © 2022 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/spf13/cobra"
@@ -22,6 +23,15 @@ var cancel2FACmd = &cobra.Command{
 			err := db.Cancel2FAByUser(admin)
 			if err != nil {
 				utils.Log.Errorf("failed to cancel 2FA: %+v", err)
+				return
+			}
+			// the admin can't login if 2FA is still required
+			item, err := db.GetSettingItemByKey(conf.AdminRequire2FA)
+			if err == nil && item.Value == "true" {
+				item.Value = "false"
+				if err := db.SaveSettingItem(*item); err != nil {
+					utils.Log.Errorf("failed to disable %s: %+v", conf.AdminRequire2FA, err)
+				}
 			}
 		}
 	},
@@ -10,4 +10,5 @@ func Init() {
 	bootstrap.Log()
 	bootstrap.InitDB()
 	data.InitData()
+	bootstrap.InitSecrets()
 }
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/bootstrap"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var migrateStatus bool
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply the migrations of the database",
+	Long: `Apply the migrations of the database not applied,
+it's required before starting the upgraded server if auto_migrate of the database is disabled`,
+	Run: func(cmd *cobra.Command, args []string) {
+		bootstrap.InitConfig()
+		bootstrap.Log()
+		bootstrap.OpenDB()
+		if migrateStatus {
+			status, err := db.GetMigrationStatus()
+			if err != nil {
+				utils.Log.Errorf("failed to get migrations: %+v", err)
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tAPPLIED AT")
+			for _, s := range status {
+				appliedAt := "pending"
+				if s.Applied {
+					appliedAt = s.AppliedAt.Format(time.RFC3339)
+				}
+				fmt.Fprintf(w, "%s\t%s\n", s.ID, appliedAt)
+			}
+			_ = w.Flush()
+			return
+		}
+		if err := db.Migrate(); err != nil {
+			utils.Log.Errorf("failed to migrate: %+v", err)
+			return
+		}
+		utils.Log.Infof("the migrations are applied")
+	},
+}
+
+func init() {
+	migrateCmd.Flags().BoolVar(&migrateStatus, "status", false, "show the migrations and if they're applied")
+	rootCmd.AddCommand(migrateCmd)
+}
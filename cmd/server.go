@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,8 +14,13 @@ import (
 	_ "github.com/alist-org/alist/v3/drivers"
 	"github.com/alist-org/alist/v3/internal/bootstrap"
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/transcode"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server"
+	"github.com/alist-org/alist/v3/server/ftp"
+	"github.com/alist-org/alist/v3/server/s3"
+	"github.com/alist-org/alist/v3/server/sftp"
+	"github.com/alist-org/alist/v3/server/static"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -28,8 +34,16 @@ var serverCmd = &cobra.Command{
 the address is defined in config file`,
 	Run: func(cmd *cobra.Command, args []string) {
 		Init()
-		bootstrap.InitAria2()
+		bootstrap.InitEvent()
+		bootstrap.InitOfflineDownloadTools()
+		bootstrap.LoadPlugins()
 		bootstrap.LoadStorages()
+		bootstrap.LoadHooks()
+		bootstrap.LoadRateLimits()
+		bootstrap.LoadAntiLeeches()
+		bootstrap.StartScheduler()
+		bootstrap.InitIndex()
+		bootstrap.InitAudit()
 		if !flags.Debug && !flags.Dev {
 			gin.SetMode(gin.ReleaseMode)
 		}
@@ -51,8 +65,79 @@ the address is defined in config file`,
 				utils.Log.Fatalf("failed to start: %s", err.Error())
 			}
 		}()
+		var s3Srv *http.Server
+		if conf.Conf.S3.Enable {
+			s3Base := fmt.Sprintf("%s:%d", conf.Conf.Address, conf.Conf.S3.Port)
+			utils.Log.Infof("start s3 server @ %s", s3Base)
+			s3Srv = &http.Server{Addr: s3Base, Handler: s3.NewServer()}
+			go func() {
+				var err error
+				if conf.Conf.S3.SSL {
+					err = s3Srv.ListenAndServeTLS(conf.Conf.Scheme.CertFile, conf.Conf.Scheme.KeyFile)
+				} else {
+					err = s3Srv.ListenAndServe()
+				}
+				if err != nil && err != http.ErrServerClosed {
+					utils.Log.Fatalf("failed to start s3 server: %s", err.Error())
+				}
+			}()
+		}
+		var sftpSrv *sftp.Server
+		if conf.Conf.SFTP.Enable {
+			sftpBase := fmt.Sprintf("%s:%d", conf.Conf.Address, conf.Conf.SFTP.Port)
+			utils.Log.Infof("start sftp server @ %s", sftpBase)
+			var err error
+			sftpSrv, err = sftp.NewServer(conf.Conf.SFTP.HostKeyFile)
+			if err != nil {
+				utils.Log.Fatalf("failed to init sftp server: %+v", err)
+			}
+			l, err := net.Listen("tcp", sftpBase)
+			if err != nil {
+				utils.Log.Fatalf("failed to start sftp server: %s", err.Error())
+			}
+			go func() {
+				err := sftpSrv.Serve(l)
+				if err != nil && err != sftp.ErrServerClosed {
+					utils.Log.Fatalf("failed to start sftp server: %s", err.Error())
+				}
+			}()
+		}
+		var ftpSrv *ftp.Server
+		if conf.Conf.FTP.Enable {
+			ftpBase := fmt.Sprintf("%s:%d", conf.Conf.Address, conf.Conf.FTP.Port)
+			utils.Log.Infof("start ftp server @ %s", ftpBase)
+			var err error
+			ftpSrv, err = ftp.NewServer()
+			if err != nil {
+				utils.Log.Fatalf("failed to init ftp server: %+v", err)
+			}
+			l, err := net.Listen("tcp", ftpBase)
+			if err != nil {
+				utils.Log.Fatalf("failed to start ftp server: %s", err.Error())
+			}
+			go func() {
+				err := ftpSrv.Serve(l)
+				if err != nil && err != ftp.ErrServerClosed {
+					utils.Log.Fatalf("failed to start ftp server: %s", err.Error())
+				}
+			}()
+		}
+		// reload the config and the storages on SIGHUP
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				res, err := bootstrap.Reload(context.Background())
+				if err != nil {
+					utils.Log.Errorf("failed reload: %+v", err)
+					continue
+				}
+				static.UpdateIndex()
+				utils.Log.Infof("reloaded, storages: %+v, restart required: %v", *res.Storages, res.RestartRequired)
+			}
+		}()
 		// Wait for interrupt signal to gracefully shutdown the server with
-		// a timeout of 5 seconds.
+		// a timeout of conf.Conf.ShutdownTimeout seconds.
 		quit := make(chan os.Signal)
 		// kill (no param) default send syscanll.SIGTERM
 		// kill -2 is syscall.SIGINT
@@ -61,16 +146,38 @@ the address is defined in config file`,
 		<-quit
 		utils.Log.Println("Shutdown Server ...")
 
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		// the requests and the tasks in flight are waited for the grace period
+		grace := time.Duration(conf.Conf.ShutdownTimeout) * time.Second
+		ctx, cancel := context.WithTimeout(context.Background(), grace)
 		defer cancel()
+		drained := make(chan struct{})
+		go func() {
+			bootstrap.DrainTasks(ctx)
+			close(drained)
+		}()
 		if err := srv.Shutdown(ctx); err != nil {
-			utils.Log.Fatal("Server Shutdown:", err)
+			utils.Log.Errorf("Server Shutdown: %+v", err)
 		}
-		// catching ctx.Done(). timeout of 3 seconds.
-		select {
-		case <-ctx.Done():
-			utils.Log.Println("timeout of 3 seconds.")
+		if s3Srv != nil {
+			if err := s3Srv.Shutdown(ctx); err != nil {
+				utils.Log.Errorf("S3 Server Shutdown: %+v", err)
+			}
+		}
+		if sftpSrv != nil {
+			if err := sftpSrv.Close(); err != nil {
+				utils.Log.Errorf("SFTP Server Shutdown: %+v", err)
+			}
+		}
+		if ftpSrv != nil {
+			if err := ftpSrv.Close(); err != nil {
+				utils.Log.Errorf("FTP Server Shutdown: %+v", err)
+			}
 		}
+		transcode.StopAll()
+		<-drained
+		stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer stopCancel()
+		bootstrap.Shutdown(stopCtx)
 		utils.Log.Println("Server exiting")
 	},
 }
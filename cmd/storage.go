@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// the storages are changed in the database, a running server applies them after reloading
+const storageReloadTips = "send SIGHUP or POST /api/admin/reload to apply it to the running server"
+
+var storageAddReq struct {
+	driver          string
+	mountPath       string
+	addition        string
+	order           int
+	remark          string
+	cacheExpiration int
+	webdavPolicy    string
+	disabled        bool
+}
+
+// storageCmd represents the storage command
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Manage the storages",
+}
+
+var storageListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the storages",
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		storages, err := db.GetAllStorages()
+		if err != nil {
+			utils.Log.Errorf("failed to get storages: %+v", err)
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tMOUNT PATH\tDRIVER\tORDER\tSTATUS\tDISABLED")
+		for _, s := range storages {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%t\n", s.ID, s.MountPath, s.Driver, s.Order, s.Status, s.Disabled)
+		}
+		_ = w.Flush()
+	},
+}
+
+var storageAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a storage, the fields of the addition not set are the defaults of the driver",
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		info, ok := op.GetDriverInfoMap()[storageAddReq.driver]
+		if !ok {
+			utils.Log.Errorf("no driver named: %s", storageAddReq.driver)
+			return
+		}
+		addition, err := fillAddition(info, storageAddReq.addition)
+		if err != nil {
+			utils.Log.Errorf("invalid addition: %+v", err)
+			return
+		}
+		storage := model.Storage{
+			MountPath:       utils.StandardizePath(storageAddReq.mountPath),
+			Order:           storageAddReq.order,
+			Driver:          storageAddReq.driver,
+			CacheExpiration: storageAddReq.cacheExpiration,
+			Addition:        addition,
+			Remark:          storageAddReq.remark,
+			Modified:        time.Now(),
+			Disabled:        storageAddReq.disabled,
+		}
+		storage.WebdavPolicy = storageAddReq.webdavPolicy
+		if storage.WebdavPolicy == "" {
+			storage.WebdavPolicy = defaultOf(info.Common, "webdav_policy")
+		}
+		if err = db.CreateStorage(&storage); err != nil {
+			utils.Log.Errorf("failed to create storage: %+v", err)
+			return
+		}
+		utils.Log.Infof("storage [%s] is added with id %d, %s", storage.MountPath, storage.ID, storageReloadTips)
+	},
+}
+
+var storageEnableCmd = &cobra.Command{
+	Use:   "enable <id|mount path>",
+	Short: "Enable the storage",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setStorageDisabled(args[0], false)
+	},
+}
+
+var storageDisableCmd = &cobra.Command{
+	Use:   "disable <id|mount path>",
+	Short: "Disable the storage",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setStorageDisabled(args[0], true)
+	},
+}
+
+var storageDeleteCmd = &cobra.Command{
+	Use:   "delete <id|mount path>",
+	Short: "Delete the storage",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		storage, err := getStorage(args[0])
+		if err != nil {
+			utils.Log.Errorf("failed to get storage: %+v", err)
+			return
+		}
+		if err = db.DeleteStorageById(storage.ID); err != nil {
+			utils.Log.Errorf("failed to delete storage: %+v", err)
+			return
+		}
+		utils.Log.Infof("storage [%s] is deleted, %s", storage.MountPath, storageReloadTips)
+	},
+}
+
+// getStorage get the storage by the id or the mount path
+func getStorage(s string) (*model.Storage, error) {
+	if id, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return db.GetStorageById(uint(id))
+	}
+	return db.GetStorageByMountPath(utils.StandardizePath(s))
+}
+
+func setStorageDisabled(s string, disabled bool) {
+	Init()
+	storage, err := getStorage(s)
+	if err != nil {
+		utils.Log.Errorf("failed to get storage: %+v", err)
+		return
+	}
+	state := "enabled"
+	if disabled {
+		state = "disabled"
+	}
+	if storage.Disabled == disabled {
+		utils.Log.Infof("storage [%s] is already %s", storage.MountPath, state)
+		return
+	}
+	storage.Disabled = disabled
+	storage.Modified = time.Now()
+	if err = db.UpdateStorage(storage); err != nil {
+		utils.Log.Errorf("failed to update storage: %+v", err)
+		return
+	}
+	utils.Log.Infof("storage [%s] is %s, %s", storage.MountPath, state, storageReloadTips)
+}
+
+func defaultOf(items []driver.Item, name string) string {
+	for _, item := range items {
+		if item.Name == name {
+			return item.Default
+		}
+	}
+	return ""
+}
+
+// fillAddition set the defaults of the driver to the fields not in the addition,
+// the required fields without the default must be set
+func fillAddition(info driver.Info, s string) (string, error) {
+	addition := make(map[string]interface{})
+	if s != "" {
+		if err := json.Unmarshal([]byte(s), &addition); err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+	for _, item := range info.Additional {
+		if _, ok := addition[item.Name]; ok {
+			continue
+		}
+		if item.Default == "" {
+			if item.Required && item.Type != conf.TypeBool {
+				return "", errors.Errorf("[%s] is required", item.Name)
+			}
+			// the zero value is used
+			continue
+		}
+		switch item.Type {
+		case conf.TypeBool:
+			addition[item.Name] = item.Default == "true"
+		case conf.TypeNumber, "int", "int64", "float64":
+			n, err := strconv.ParseFloat(item.Default, 64)
+			if err != nil {
+				return "", errors.Wrapf(err, "invalid default of [%s]", item.Name)
+			}
+			addition[item.Name] = n
+		default:
+			addition[item.Name] = item.Default
+		}
+	}
+	data, err := json.Marshal(addition)
+	return string(data), errors.WithStack(err)
+}
+
+func init() {
+	storageAddCmd.Flags().StringVar(&storageAddReq.driver, "driver", "", "the driver of the storage, such as Local")
+	storageAddCmd.Flags().StringVar(&storageAddReq.mountPath, "mount-path", "", "the mount path of the storage")
+	storageAddCmd.Flags().StringVar(&storageAddReq.addition, "addition", "", `the addition of the driver in json, such as {"root_folder_path":"/data"}`)
+	storageAddCmd.Flags().IntVar(&storageAddReq.order, "order", 0, "the order of the storage")
+	storageAddCmd.Flags().StringVar(&storageAddReq.remark, "remark", "", "the remark of the storage")
+	storageAddCmd.Flags().IntVar(&storageAddReq.cacheExpiration, "cache-expiration", 30, "the cache expiration in minutes")
+	storageAddCmd.Flags().StringVar(&storageAddReq.webdavPolicy, "webdav-policy", "", "the webdav policy, the default of the driver if not set")
+	storageAddCmd.Flags().BoolVar(&storageAddReq.disabled, "disabled", false, "add the storage disabled")
+	_ = storageAddCmd.MarkFlagRequired("driver")
+	_ = storageAddCmd.MarkFlagRequired("mount-path")
+	storageCmd.AddCommand(storageListCmd, storageAddCmd, storageEnableCmd, storageDisableCmd, storageDeleteCmd)
+	rootCmd.AddCommand(storageCmd)
+}
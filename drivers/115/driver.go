@@ -0,0 +1,226 @@
+package _115
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+type Pan115 struct {
+	model.Storage
+	Addition
+}
+
+func (d *Pan115) Config() driver.Config {
+	return config
+}
+
+func (d *Pan115) GetAddition() driver.Additional {
+	return d.Addition
+}
+
+func (d *Pan115) Init(ctx context.Context, storage model.Storage) error {
+	d.Storage = storage
+	err := utils.Json.UnmarshalFromString(d.Storage.Addition, &d.Addition)
+	if err != nil {
+		return err
+	}
+	if d.Cookie == "" {
+		if err = d.qrcodeLogin(); err != nil {
+			return err
+		}
+	}
+	// check the cookie by listing the root
+	_, err = d.getFiles(d.GetRootId())
+	return err
+}
+
+func (d *Pan115) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *Pan115) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	files, err := d.getFiles(dir.GetID())
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(files, func(src File) (model.Obj, error) {
+		return fileToObj(src), nil
+	})
+}
+
+func (d *Pan115) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	f, ok := file.(*FileObj)
+	if !ok || f.PickCode == "" {
+		return nil, fmt.Errorf("115: no pick code of %s", file.GetName())
+	}
+	// the url is bound to the User-Agent, so the one of the client is used if it's proxied or redirected
+	ua := args.Header.Get("User-Agent")
+	if ua == "" {
+		ua = base.UserAgent
+	}
+	u, err := d.download(f.PickCode, ua)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Link{
+		URL: u,
+		Header: http.Header{
+			"User-Agent": []string{ua},
+		},
+	}, nil
+}
+
+func (d *Pan115) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	_, err := d.request(http.MethodPost, webApiUrl+"/files/add", func(req *resty.Request) {
+		req.SetFormData(map[string]string{
+			"pid":   parentDir.GetID(),
+			"cname": dirName,
+		})
+	}, nil)
+	return err
+}
+
+func (d *Pan115) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.BatchMove(ctx, []model.Obj{srcObj}, dstDir)
+}
+
+func (d *Pan115) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	_, err := d.request(http.MethodPost, webApiUrl+"/files/batch_rename", func(req *resty.Request) {
+		req.SetFormData(map[string]string{
+			fmt.Sprintf("files_new_name[%s]", srcObj.GetID()): newName,
+		})
+	}, nil)
+	return err
+}
+
+func (d *Pan115) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.BatchCopy(ctx, []model.Obj{srcObj}, dstDir)
+}
+
+func (d *Pan115) Remove(ctx context.Context, obj model.Obj) error {
+	return d.BatchRemove(ctx, []model.Obj{obj})
+}
+
+func (d *Pan115) BatchMove(ctx context.Context, srcObjs []model.Obj, dstDir model.Obj) error {
+	return d.batch("/files/move", srcObjs, map[string]string{"pid": dstDir.GetID()})
+}
+
+func (d *Pan115) BatchCopy(ctx context.Context, srcObjs []model.Obj, dstDir model.Obj) error {
+	return d.batch("/files/copy", srcObjs, map[string]string{"pid": dstDir.GetID()})
+}
+
+func (d *Pan115) BatchRemove(ctx context.Context, objs []model.Obj) error {
+	return d.batch("/rb/delete", objs, nil)
+}
+
+func (d *Pan115) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	// the stream is spooled to a temp file as the driver needs a seekable one
+	src, ok := stream.GetReadCloser().(io.ReaderAt)
+	if !ok {
+		tempFile, err := utils.CreateTempFile(stream.GetReadCloser())
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tempFile.Close()
+			_ = os.Remove(tempFile.Name())
+		}()
+		src = tempFile
+	}
+	return d.upload(ctx, src, stream.GetSize(), stream.GetName(), dstDir.GetID(), up)
+}
+
+// Other the methods of the offline download, offline_download submits the url or the urls in data
+// to the dir of obj, offline_tasks lists the tasks of the page in data
+func (d *Pan115) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
+	switch args.Method {
+	case "offline_download":
+		var urls []string
+		switch data := args.Data.(type) {
+		case string:
+			urls = []string{data}
+		case []string:
+			urls = data
+		case []interface{}:
+			for _, u := range data {
+				if s, ok := u.(string); ok {
+					urls = append(urls, s)
+				}
+			}
+		}
+		if len(urls) == 0 {
+			return nil, fmt.Errorf("115: no url to download")
+		}
+		return d.addOfflineTasks(urls, args.Obj.GetID())
+	case "offline_tasks":
+		page := 1
+		if p, ok := args.Data.(float64); ok && p > 0 {
+			page = int(p)
+		}
+		return d.getOfflineTasks(page)
+	default:
+		return nil, errs.NotSupport
+	}
+}
+
+// OfflineDownload the magnet, ed2k or http url is fetched to dstDir by 115, the id of the task is the info hash
+func (d *Pan115) OfflineDownload(ctx context.Context, url string, dstDir model.Obj) (string, error) {
+	hashes, err := d.addOfflineTasks([]string{url}, dstDir.GetID())
+	if err != nil {
+		return "", err
+	}
+	if len(hashes) == 0 {
+		return "", fmt.Errorf("115: failed add the offline task of %s", url)
+	}
+	return hashes[0], nil
+}
+
+func (d *Pan115) OfflineDownloadStatus(ctx context.Context, id string) (*model.OfflineDownloadStatus, error) {
+	for page := 1; ; page++ {
+		resp, err := d.getOfflineTasks(page)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range resp.Tasks {
+			if t.InfoHash != id {
+				continue
+			}
+			status := &model.OfflineDownloadStatus{
+				Progress:  t.PercentDone,
+				Completed: t.Status == 2,
+			}
+			switch t.Status {
+			case -1:
+				status.Status = "failed"
+				status.Error = "115 failed to download the url"
+			case 0:
+				status.Status = "waiting"
+			case 1:
+				status.Status = "downloading"
+			case 2:
+				status.Status = "completed"
+			}
+			return status, nil
+		}
+		if page >= resp.PageCount || len(resp.Tasks) == 0 {
+			return nil, errs.ObjectNotFound
+		}
+	}
+}
+
+var _ driver.Driver = (*Pan115)(nil)
+var _ driver.BatchMove = (*Pan115)(nil)
+var _ driver.BatchCopy = (*Pan115)(nil)
+var _ driver.BatchRemove = (*Pan115)(nil)
+var _ driver.Other = (*Pan115)(nil)
+var _ driver.OfflineDownload = (*Pan115)(nil)
@@ -0,0 +1,34 @@
+package _115
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	// the cookie of UID, CID and SEID, it's got by the qr code if it's empty
+	Cookie string `json:"cookie" secret:"true" help:"leave it empty to login by the qr code, the url of it is shown in the status of the storage"`
+	// the app logged in by the qr code, the other sessions of the same app are logged out
+	QRCodeSource string `json:"qrcode_source" type:"select" options:"web,android,ios,tv,alipaymini,wechatmini,qandroid" default:"tv" help:"the app to login as by the qr code"`
+	// the pending qr code session of uid,time,sign, it's cleared after the login
+	QRCodeToken string `json:"qrcode_token" help:"the pending qr code session, it's filled and cleared automatically"`
+	driver.RootID
+	PageSize int `json:"page_size" type:"number" default:"1000" help:"the count of the files of a page of the list, at most 1150"`
+}
+
+var config = driver.Config{
+	Name:              "115 Cloud",
+	LocalSort:         true,
+	DefaultRoot:       "0",
+	SupportsRangeRead: true,
+	// the sha1 of the file is needed before the upload
+	NeedsSeekableUpload: true,
+}
+
+func New() driver.Driver {
+	return &Pan115{}
+}
+
+func init() {
+	op.RegisterDriver(config, New)
+}
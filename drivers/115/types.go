@@ -0,0 +1,171 @@
+package _115
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+// BaseResp is the common part of the responses of webapi
+type BaseResp struct {
+	State bool   `json:"state"`
+	Error string `json:"error"`
+	Errno int    `json:"errno"`
+}
+
+type File struct {
+	// the id of the file, it's empty for the dirs
+	FileID string `json:"fid"`
+	// the id of the dir for the dirs, or the id of the parent for the files
+	CategoryID string `json:"cid"`
+	Name       string `json:"n"`
+	Size       int64  `json:"s"`
+	Sha1       string `json:"sha"`
+	PickCode   string `json:"pc"`
+	// the unix time of the last change, it's a string
+	EditTime string `json:"te"`
+}
+
+func (f File) IsDir() bool {
+	return f.FileID == ""
+}
+
+func (f File) ID() string {
+	if f.IsDir() {
+		return f.CategoryID
+	}
+	return f.FileID
+}
+
+type ListResp struct {
+	BaseResp
+	Data   []File `json:"data"`
+	Count  int    `json:"count"`
+	Offset int    `json:"offset"`
+}
+
+type DownloadResp struct {
+	BaseResp
+	FileURL string `json:"file_url"`
+}
+
+type QRCodeTokenResp struct {
+	State int `json:"state"`
+	Data  struct {
+		UID  string `json:"uid"`
+		Time int64  `json:"time"`
+		Sign string `json:"sign"`
+	} `json:"data"`
+}
+
+// QRCodeStatusResp is the status of the qr code, 0 waiting, 1 scanned, 2 confirmed, -1 expired and -2 canceled
+type QRCodeStatusResp struct {
+	State int `json:"state"`
+	Data  struct {
+		Status int    `json:"status"`
+		Msg    string `json:"msg"`
+	} `json:"data"`
+}
+
+type QRCodeLoginResp struct {
+	State   int    `json:"state"`
+	Message string `json:"message"`
+	Data    struct {
+		Cookie map[string]string `json:"cookie"`
+	} `json:"data"`
+}
+
+type UploadInfoResp struct {
+	State   bool   `json:"state"`
+	UserID  int64  `json:"user_id"`
+	UserKey string `json:"userkey"`
+}
+
+// InitUploadResp is the result of initupload, the status is 2 if it's rapid uploaded,
+// 1 if the content should be uploaded to the oss, and 7 if the sha1 of the range of sign_check is needed
+type InitUploadResp struct {
+	Status     int    `json:"status"`
+	StatusCode int    `json:"statuscode"`
+	StatusMsg  string `json:"statusmsg"`
+	PickCode   string `json:"pickcode"`
+	SignKey    string `json:"sign_key"`
+	SignCheck  string `json:"sign_check"`
+	Bucket     string `json:"bucket"`
+	Object     string `json:"object"`
+	Callback   struct {
+		Callback    string `json:"callback"`
+		CallbackVar string `json:"callback_var"`
+	} `json:"callback"`
+}
+
+type OSSTokenResp struct {
+	StatusCode      string `json:"StatusCode"`
+	AccessKeyID     string `json:"AccessKeyId"`
+	AccessKeySecret string `json:"AccessKeySecret"`
+	SecurityToken   string `json:"SecurityToken"`
+	Endpoint        string `json:"endpoint"`
+}
+
+type OfflineSignResp struct {
+	State bool   `json:"state"`
+	Sign  string `json:"sign"`
+	Time  int64  `json:"time"`
+}
+
+type OfflineAddResp struct {
+	State    bool   `json:"state"`
+	ErrorMsg string `json:"error_msg"`
+	ErrCode  int    `json:"errcode"`
+	InfoHash string `json:"info_hash"`
+	Result   []struct {
+		State    bool   `json:"state"`
+		ErrorMsg string `json:"error_msg"`
+		InfoHash string `json:"info_hash"`
+		URL      string `json:"url"`
+	} `json:"result"`
+}
+
+// OfflineTask is a task of the offline download, the status is -1 failed, 0 waiting, 1 downloading and 2 completed
+type OfflineTask struct {
+	InfoHash    string  `json:"info_hash"`
+	Name        string  `json:"name"`
+	Size        int64   `json:"size"`
+	URL         string  `json:"url"`
+	Status      int     `json:"status"`
+	PercentDone float64 `json:"percentDone"`
+	FileID      string  `json:"file_id"`
+	AddTime     int64   `json:"add_time"`
+}
+
+type OfflineTasksResp struct {
+	State     bool          `json:"state"`
+	Page      int           `json:"page"`
+	PageCount int           `json:"page_count"`
+	Tasks     []OfflineTask `json:"tasks"`
+}
+
+// FileObj is the object with the pick code, which is needed to download the file
+type FileObj struct {
+	model.Object
+	PickCode string
+}
+
+func fileToObj(f File) *FileObj {
+	edit, _ := strconv.ParseInt(f.EditTime, 10, 64)
+	obj := &FileObj{
+		Object: model.Object{
+			ID:       f.ID(),
+			Name:     f.Name,
+			Size:     f.Size,
+			Modified: time.Unix(edit, 0),
+			IsFolder: f.IsDir(),
+		},
+		PickCode: f.PickCode,
+	}
+	if f.Sha1 != "" {
+		obj.Hash = model.HashInfo{Type: "sha1", Value: strings.ToLower(f.Sha1)}
+	}
+	return obj
+}
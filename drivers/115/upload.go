@@ -0,0 +1,236 @@
+package _115
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+const (
+	uploadUrl  = "https://uplb.115.com/3.0"
+	appVersion = "2.0.3.6"
+	// the salt of the token of initupload
+	tokenSalt = "Qclm8MGWUv59TnrR0XPg"
+	// the bytes of the beginning of the file to get the preid
+	preHashSize = 128 * 1024
+)
+
+func sha1Hex(r io.Reader) (string, error) {
+	h := sha1.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(h.Sum(nil))), nil
+}
+
+func md5Hex(s string) string {
+	h := md5.Sum([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func (d *Pan115) uploadInfo() (*UploadInfoResp, error) {
+	var resp UploadInfoResp
+	res, err := base.RestyClient.R().SetHeaders(map[string]string{
+		"Cookie":     d.Cookie,
+		"User-Agent": base.UserAgent,
+	}).Get("https://proapi.115.com/app/uploadinfo")
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.Json.Unmarshal(res.Body(), &resp); err != nil {
+		return nil, err
+	}
+	if !resp.State || resp.UserKey == "" {
+		return nil, errNotLogin
+	}
+	return &resp, nil
+}
+
+// upload upload the file by the sha1 of it, the content is sent to the oss only if the server doesn't have it
+func (d *Pan115) upload(ctx context.Context, src io.ReaderAt, size int64, name, dirID string, up driver.UpdateProgress) error {
+	info, err := d.uploadInfo()
+	if err != nil {
+		return err
+	}
+	fileID, err := sha1Hex(io.NewSectionReader(src, 0, size))
+	if err != nil {
+		return err
+	}
+	preSize := int64(preHashSize)
+	if size < preSize {
+		preSize = size
+	}
+	preID, err := sha1Hex(io.NewSectionReader(src, 0, preSize))
+	if err != nil {
+		return err
+	}
+	up(10)
+	userID := strconv.FormatInt(info.UserID, 10)
+	target := "U_1_" + dirID
+	inner := sha1.Sum([]byte(userID + fileID + target + "0"))
+	sig := sha1.Sum([]byte(info.UserKey + hex.EncodeToString(inner[:]) + "000000"))
+	form := map[string]string{
+		"appid":      "0",
+		"appversion": appVersion,
+		"userid":     userID,
+		"filename":   name,
+		"filesize":   strconv.FormatInt(size, 10),
+		"fileid":     fileID,
+		"preid":      preID,
+		"target":     target,
+		"sig":        strings.ToUpper(hex.EncodeToString(sig[:])),
+	}
+	var resp *InitUploadResp
+	signKey, signVal := "", ""
+	// the server may ask for the sha1 of a range of the file several times
+	for i := 0; i < 3; i++ {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		resp, err = d.initUpload(form, signKey, signVal)
+		if err != nil {
+			return err
+		}
+		if resp.Status != 7 {
+			break
+		}
+		signKey = resp.SignKey
+		if signVal, err = rangeSha1(src, resp.SignCheck); err != nil {
+			return err
+		}
+	}
+	switch resp.Status {
+	case 2:
+		// the rapid upload succeeded
+		up(100)
+		return nil
+	case 1:
+		return d.uploadOSS(ctx, src, size, resp, up)
+	}
+	return fmt.Errorf("115: failed init the upload of %s: %d %s", name, resp.StatusCode, resp.StatusMsg)
+}
+
+func (d *Pan115) initUpload(form map[string]string, signKey, signVal string) (*InitUploadResp, error) {
+	t := strconv.FormatInt(time.Now().Unix(), 10)
+	data := make(map[string]string, len(form)+4)
+	for k, v := range form {
+		data[k] = v
+	}
+	userID := form["userid"]
+	data["t"] = t
+	data["token"] = md5Hex(tokenSalt + form["fileid"] + form["filesize"] + signKey + signVal + userID + t + md5Hex(userID) + appVersion)
+	if signKey != "" {
+		data["sign_key"] = signKey
+		data["sign_val"] = signVal
+	}
+	res, err := base.RestyClient.R().SetHeaders(map[string]string{
+		"Cookie":     d.Cookie,
+		"User-Agent": base.UserAgent,
+	}).SetFormData(data).Post(uploadUrl + "/initupload.php")
+	if err != nil {
+		return nil, err
+	}
+	var resp InitUploadResp
+	if err = utils.Json.Unmarshal(res.Body(), &resp); err != nil {
+		return nil, fmt.Errorf("115: failed init the upload: %s", res.String())
+	}
+	return &resp, nil
+}
+
+// rangeSha1 get the sha1 of the range of the file such as 0-1023, both ends are included
+func rangeSha1(src io.ReaderAt, r string) (string, error) {
+	s, e, ok := strings.Cut(r, "-")
+	start, err1 := strconv.ParseInt(s, 10, 64)
+	end, err2 := strconv.ParseInt(e, 10, 64)
+	if !ok || err1 != nil || err2 != nil || end < start {
+		return "", fmt.Errorf("115: invalid sign check %s", r)
+	}
+	return sha1Hex(io.NewSectionReader(src, start, end-start+1))
+}
+
+// uploadOSS put the file to the oss with the sts token, the oss calls back 115 to create the file
+func (d *Pan115) uploadOSS(ctx context.Context, src io.ReaderAt, size int64, init *InitUploadResp, up driver.UpdateProgress) error {
+	var token OSSTokenResp
+	res, err := base.RestyClient.R().SetHeaders(map[string]string{
+		"Cookie":     d.Cookie,
+		"User-Agent": base.UserAgent,
+	}).Get(uploadUrl + "/gettoken.php")
+	if err != nil {
+		return err
+	}
+	if err = utils.Json.Unmarshal(res.Body(), &token); err != nil || token.AccessKeyID == "" {
+		return fmt.Errorf("115: failed get the token of the oss: %s", res.String())
+	}
+	endpoint, err := url.Parse(token.Endpoint)
+	if err != nil {
+		return err
+	}
+	u := fmt.Sprintf("%s://%s.%s/%s", endpoint.Scheme, init.Bucket, endpoint.Host, init.Object)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, io.NewSectionReader(src, 0, size))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-oss-security-token", token.SecurityToken)
+	req.Header.Set("x-oss-callback", base64.StdEncoding.EncodeToString([]byte(init.Callback.Callback)))
+	req.Header.Set("x-oss-callback-var", base64.StdEncoding.EncodeToString([]byte(init.Callback.CallbackVar)))
+	req.Header.Set("Authorization", "OSS "+token.AccessKeyID+":"+ossSign(req, token.AccessKeySecret, init.Bucket, init.Object))
+	up(20)
+	resp, err := base.HttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	// the body is the reply of the callback
+	var r BaseResp
+	if resp.StatusCode != http.StatusOK || utils.Json.Unmarshal(body, &r) != nil || !r.State {
+		return fmt.Errorf("115: failed upload to the oss: %s %s", resp.Status, body)
+	}
+	up(100)
+	return nil
+}
+
+// ossSign sign the request by the v1 signature of the oss
+func ossSign(req *http.Request, secret, bucket, object string) string {
+	var ossHeaders []string
+	for k, v := range req.Header {
+		k = strings.ToLower(k)
+		if strings.HasPrefix(k, "x-oss-") {
+			ossHeaders = append(ossHeaders, k+":"+strings.Join(v, ","))
+		}
+	}
+	sort.Strings(ossHeaders)
+	var sb strings.Builder
+	sb.WriteString(req.Method + "\n")
+	sb.WriteString(req.Header.Get("Content-MD5") + "\n")
+	sb.WriteString(req.Header.Get("Content-Type") + "\n")
+	sb.WriteString(req.Header.Get("Date") + "\n")
+	for _, h := range ossHeaders {
+		sb.WriteString(h + "\n")
+	}
+	sb.WriteString("/" + bucket + "/" + object)
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(sb.String()))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,281 @@
+package _115
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+// do others that not defined in Driver interface
+
+const (
+	webApiUrl = "https://webapi.115.com"
+	qrcodeUrl = "https://qrcodeapi.115.com"
+	// the page of the qr code to scan by the 115 app
+	qrcodeImageUrl = qrcodeUrl + "/api/1.0/web/1.0/qrcode?uid="
+)
+
+var errNotLogin = errors.New("the cookie of 115 is expired, clear it to login by the qr code again")
+
+func (d *Pan115) request(method, u string, callback base.ReqCallback, resp interface{}) ([]byte, error) {
+	req := base.RestyClient.R()
+	req.SetHeaders(map[string]string{
+		"Cookie":     d.Cookie,
+		"User-Agent": base.UserAgent,
+	})
+	if callback != nil {
+		callback(req)
+	}
+	res, err := req.Execute(method, u)
+	if err != nil {
+		return nil, err
+	}
+	// the content type of the responses is text/html, so they're unmarshalled here
+	var r BaseResp
+	_ = utils.Json.Unmarshal(res.Body(), &r)
+	if !r.State {
+		if r.Errno == 99 || r.Errno == 990001 {
+			return nil, errNotLogin
+		}
+		msg := r.Error
+		if msg == "" {
+			msg = res.String()
+		}
+		return nil, fmt.Errorf("115: %s", msg)
+	}
+	if resp != nil {
+		if err = utils.Json.Unmarshal(res.Body(), resp); err != nil {
+			return nil, err
+		}
+	}
+	return res.Body(), nil
+}
+
+// userID get the id of the user from the UID of the cookie, such as UID=123456_A1_1690000000
+func (d *Pan115) userID() string {
+	for _, c := range strings.Split(d.Cookie, ";") {
+		k, v, _ := strings.Cut(strings.TrimSpace(c), "=")
+		if k == "UID" {
+			id, _, _ := strings.Cut(v, "_")
+			return id
+		}
+	}
+	return ""
+}
+
+// qrcodeLogin login by the qr code, the session of the qr code is saved in the addition, the error returned
+// tells the url of the qr code to scan, then the cookie is got when the storage is loaded again after confirming
+func (d *Pan115) qrcodeLogin() error {
+	if d.QRCodeToken != "" {
+		uid, t, sign := splitQRCodeToken(d.QRCodeToken)
+		var status QRCodeStatusResp
+		res, err := base.RestyClient.R().SetQueryParams(map[string]string{
+			"uid":  uid,
+			"time": t,
+			"sign": sign,
+			"_":    strconv.FormatInt(time.Now().Unix(), 10),
+		}).Get(qrcodeUrl + "/get/status/")
+		if err != nil {
+			return err
+		}
+		if err = utils.Json.Unmarshal(res.Body(), &status); err != nil {
+			return err
+		}
+		switch status.Data.Status {
+		case 0, 1:
+			return qrcodeHint(uid)
+		case 2:
+			return d.qrcodeConfirmed(uid)
+		}
+		// the qr code is expired or canceled, get a new one
+	}
+	var resp QRCodeTokenResp
+	res, err := base.RestyClient.R().Get(qrcodeUrl + "/api/1.0/web/1.0/token/")
+	if err != nil {
+		return err
+	}
+	if err = utils.Json.Unmarshal(res.Body(), &resp); err != nil {
+		return err
+	}
+	if resp.Data.UID == "" {
+		return fmt.Errorf("115: failed get the qr code: %s", res.String())
+	}
+	d.QRCodeToken = strings.Join([]string{resp.Data.UID, strconv.FormatInt(resp.Data.Time, 10), resp.Data.Sign}, ",")
+	op.MustSaveDriverStorage(d)
+	return qrcodeHint(resp.Data.UID)
+}
+
+func (d *Pan115) qrcodeConfirmed(uid string) error {
+	app := d.QRCodeSource
+	if app == "" {
+		app = "tv"
+	}
+	var resp QRCodeLoginResp
+	res, err := base.RestyClient.R().SetFormData(map[string]string{
+		"account": uid,
+		"app":     app,
+	}).Post(fmt.Sprintf("https://passportapi.115.com/app/1.0/%s/1.0/login/qrcode/", app))
+	if err != nil {
+		return err
+	}
+	if err = utils.Json.Unmarshal(res.Body(), &resp); err != nil {
+		return err
+	}
+	if len(resp.Data.Cookie) == 0 {
+		return fmt.Errorf("115: failed login by the qr code: %s", resp.Message)
+	}
+	cookies := make([]string, 0, len(resp.Data.Cookie))
+	for k, v := range resp.Data.Cookie {
+		cookies = append(cookies, k+"="+v)
+	}
+	sort.Strings(cookies)
+	d.Cookie, d.QRCodeToken = strings.Join(cookies, "; "), ""
+	op.MustSaveDriverStorage(d)
+	return nil
+}
+
+func splitQRCodeToken(token string) (uid, t, sign string) {
+	parts := strings.SplitN(token, ",", 3)
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+	return parts[0], parts[1], parts[2]
+}
+
+func qrcodeHint(uid string) error {
+	return fmt.Errorf("scan the qr code %s by the 115 app and confirm, then reload the storage", qrcodeImageUrl+uid)
+}
+
+func (d *Pan115) getFiles(dirID string) ([]File, error) {
+	limit := d.PageSize
+	if limit <= 0 || limit > 1150 {
+		limit = 1000
+	}
+	files := make([]File, 0)
+	for offset := 0; ; offset += limit {
+		var resp ListResp
+		_, err := d.request(http.MethodGet, webApiUrl+"/files", func(req *resty.Request) {
+			req.SetQueryParams(map[string]string{
+				"aid":      "1",
+				"cid":      dirID,
+				"o":        "user_ptime",
+				"asc":      "0",
+				"offset":   strconv.Itoa(offset),
+				"limit":    strconv.Itoa(limit),
+				"show_dir": "1",
+				"snap":     "0",
+				"natsort":  "1",
+				"format":   "json",
+			})
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, resp.Data...)
+		if len(resp.Data) < limit || len(files) >= resp.Count {
+			break
+		}
+	}
+	return files, nil
+}
+
+// download get the url of the file, it's valid for the User-Agent that requests it only
+func (d *Pan115) download(pickCode, ua string) (string, error) {
+	var resp DownloadResp
+	_, err := d.request(http.MethodGet, webApiUrl+"/files/download", func(req *resty.Request) {
+		req.SetHeader("User-Agent", ua)
+		req.SetQueryParam("pickcode", pickCode)
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.FileURL == "" {
+		return "", fmt.Errorf("115: no url of %s", pickCode)
+	}
+	return resp.FileURL, nil
+}
+
+// batch call the batch operation of the files such as move, copy and delete
+func (d *Pan115) batch(path string, objs []model.Obj, form map[string]string) error {
+	if form == nil {
+		form = map[string]string{}
+	}
+	for i, obj := range objs {
+		form[fmt.Sprintf("fid[%d]", i)] = obj.GetID()
+	}
+	_, err := d.request(http.MethodPost, webApiUrl+path, func(req *resty.Request) {
+		req.SetFormData(form)
+	}, nil)
+	return err
+}
+
+// offlineSign get the sign and the time needed by the apis of the offline download
+func (d *Pan115) offlineSign() (*OfflineSignResp, error) {
+	var resp OfflineSignResp
+	_, err := d.request(http.MethodGet, "https://115.com/", func(req *resty.Request) {
+		req.SetQueryParams(map[string]string{"ct": "offline", "ac": "space"})
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (d *Pan115) offlineRequest(ac string, form map[string]string, resp interface{}) error {
+	sign, err := d.offlineSign()
+	if err != nil {
+		return err
+	}
+	form["uid"] = d.userID()
+	form["sign"] = sign.Sign
+	form["time"] = strconv.FormatInt(sign.Time, 10)
+	_, err = d.request(http.MethodPost, "https://115.com/web/lixian/", func(req *resty.Request) {
+		req.SetQueryParams(map[string]string{"ct": "lixian", "ac": ac})
+		req.SetFormData(form)
+	}, resp)
+	return err
+}
+
+// addOfflineTasks submit the urls such as the magnet links to the offline download, the info hashes of the tasks are returned
+func (d *Pan115) addOfflineTasks(urls []string, dirID string) ([]string, error) {
+	form := map[string]string{"wp_path_id": dirID}
+	for i, u := range urls {
+		form[fmt.Sprintf("url[%d]", i)] = u
+	}
+	var resp OfflineAddResp
+	if err := d.offlineRequest("add_task_urls", form, &resp); err != nil {
+		return nil, err
+	}
+	hashes := make([]string, 0, len(resp.Result))
+	var errs []string
+	for _, r := range resp.Result {
+		if !r.State && r.InfoHash == "" {
+			errs = append(errs, fmt.Sprintf("%s: %s", r.URL, r.ErrorMsg))
+			continue
+		}
+		hashes = append(hashes, r.InfoHash)
+	}
+	if len(hashes) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("115: %s", strings.Join(errs, "; "))
+	}
+	return hashes, nil
+}
+
+// getOfflineTasks get the tasks of the page of the offline download, the page starts from 1
+func (d *Pan115) getOfflineTasks(page int) (*OfflineTasksResp, error) {
+	var resp OfflineTasksResp
+	if err := d.offlineRequest("task_lists", map[string]string{"page": strconv.Itoa(page)}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
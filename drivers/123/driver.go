@@ -17,10 +17,6 @@ import (
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/utils"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/go-resty/resty/v2"
 	log "github.com/sirupsen/logrus"
 )
@@ -29,6 +25,8 @@ type Pan123 struct {
 	model.Storage
 	Addition
 	AccessToken string
+	// the uid of the user to sign the direct links
+	uid int64
 }
 
 func (d *Pan123) Config() driver.Config {
@@ -45,7 +43,13 @@ func (d *Pan123) Init(ctx context.Context, storage model.Storage) error {
 	if err != nil {
 		return err
 	}
-	return d.login()
+	if err = d.login(); err != nil {
+		return err
+	}
+	if d.DirectLink && d.DirectLinkPrivateKey != "" {
+		d.uid, err = d.getUID()
+	}
+	return err
 }
 
 func (d *Pan123) Drop(ctx context.Context) error {
@@ -58,7 +62,7 @@ func (d *Pan123) List(ctx context.Context, dir model.Obj, args model.ListArgs) (
 		return nil, err
 	}
 	return utils.SliceConvert(files, func(src File) (model.Obj, error) {
-		return src, nil
+		return &src, nil
 	})
 }
 
@@ -68,7 +72,10 @@ func (d *Pan123) List(ctx context.Context, dir model.Obj, args model.ListArgs) (
 //}
 
 func (d *Pan123) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
-	if f, ok := file.(File); ok {
+	if f, ok := file.(*File); ok {
+		if d.DirectLink && f.GetPath() != "" {
+			return d.directLink(f.GetPath())
+		}
 		var resp DownResp
 		var headers map[string]string
 		if !utils.IsLocalIPAddr(args.IP) {
@@ -158,11 +165,11 @@ func (d *Pan123) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
 }
 
 func (d *Pan123) Remove(ctx context.Context, obj model.Obj) error {
-	if f, ok := obj.(File); ok {
+	if f, ok := obj.(*File); ok {
 		data := base.Json{
 			"driveId":           0,
 			"operation":         true,
-			"fileTrashInfoList": []File{f},
+			"fileTrashInfoList": []File{*f},
 		}
 		_, err := d.request("https://www.123pan.com/a/api/file/trash", http.MethodPost, func(req *resty.Request) {
 			req.SetBody(data)
@@ -226,26 +233,11 @@ func (d *Pan123) Put(ctx context.Context, dstDir model.Obj, stream model.FileStr
 	if err != nil {
 		return err
 	}
-	if resp.Data.Key == "" {
+	if resp.Data.Reuse || resp.Data.Key == "" {
+		// it's rapid uploaded by the md5
 		return nil
 	}
-	cfg := &aws.Config{
-		Credentials:      credentials.NewStaticCredentials(resp.Data.AccessKeyId, resp.Data.SecretAccessKey, resp.Data.SessionToken),
-		Region:           aws.String("123pan"),
-		Endpoint:         aws.String("file.123pan.com"),
-		S3ForcePathStyle: aws.Bool(true),
-	}
-	s, err := session.NewSession(cfg)
-	if err != nil {
-		return err
-	}
-	uploader := s3manager.NewUploader(s)
-	input := &s3manager.UploadInput{
-		Bucket: &resp.Data.Bucket,
-		Key:    &resp.Data.Key,
-		Body:   uploadFile,
-	}
-	_, err = uploader.Upload(input)
+	err = d.uploadS3(ctx, &resp, uploadFile, stream.GetSize(), up)
 	if err != nil {
 		return err
 	}
@@ -7,18 +7,24 @@ import (
 
 type Addition struct {
 	Username       string `json:"username" required:"true"`
-	Password       string `json:"password" required:"true"`
+	Password       string `json:"password" required:"true" secret:"true"`
 	OrderBy        string `json:"order_by" type:"select" options:"name,fileId,updateAt,createAt" default:"name"`
 	OrderDirection string `json:"order_direction" type:"select" options:"asc,desc" default:"asc"`
 	driver.RootID
 	// define other
 	StreamUpload bool `json:"stream_upload"`
+	// the direct link space, see https://www.123pan.com/faq
+	DirectLink           bool   `json:"direct_link" help:"download by the direct link space of 123pan, the root of the storage should be in the space"`
+	DirectLinkURL        string `json:"direct_link_url" help:"the url of the root of the storage in the direct link space, such as https://vip.123pan.cn/<uid>/<dir>"`
+	DirectLinkPrivateKey string `json:"direct_link_private_key" secret:"true" help:"the key of the url authentication of the direct link space, leave it empty if it's disabled"`
+	DirectLinkExpire     int    `json:"direct_link_expire" type:"number" default:"30" help:"the minutes the signed direct links are valid"`
 	//Field string `json:"field" type:"select" required:"true" options:"a,b,c" default:"a"`
 }
 
 var config = driver.Config{
-	Name:        "123Pan",
-	DefaultRoot: "0",
+	Name:              "123Pan",
+	DefaultRoot:       "0",
+	SupportsRangeRead: true,
 }
 
 func New() driver.Driver {
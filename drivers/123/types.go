@@ -2,6 +2,7 @@ package _123
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/model"
@@ -28,10 +29,23 @@ type File struct {
 	Etag        string    `json:"Etag"`
 	S3KeyFlag   string    `json:"S3KeyFlag"`
 	DownloadUrl string    `json:"DownloadUrl"`
+	// the path in the storage, it's set by op.Get to sign the direct link
+	Path string `json:"-"`
 }
 
 func (f File) GetPath() string {
-	return ""
+	return f.Path
+}
+
+func (f *File) SetPath(path string) {
+	f.Path = path
+}
+
+func (f File) GetHash() model.HashInfo {
+	if f.Etag == "" {
+		return model.HashInfo{}
+	}
+	return model.HashInfo{Type: "md5", Value: strings.ToLower(f.Etag)}
 }
 
 func (f File) GetSize() int64 {
@@ -55,6 +69,7 @@ func (f File) GetID() string {
 }
 
 var _ model.Obj = (*File)(nil)
+var _ model.SetPath = (*File)(nil)
 
 //func (f File) Thumb() string {
 //
@@ -79,11 +94,26 @@ type DownResp struct {
 type UploadResp struct {
 	BaseResp
 	Data struct {
-		AccessKeyId     string `json:"AccessKeyId"`
-		Bucket          string `json:"Bucket"`
-		Key             string `json:"Key"`
-		SecretAccessKey string `json:"SecretAccessKey"`
-		SessionToken    string `json:"SessionToken"`
-		FileId          int64  `json:"FileId"`
+		Bucket      string `json:"Bucket"`
+		Key         string `json:"Key"`
+		UploadId    string `json:"UploadId"`
+		StorageNode string `json:"StorageNode"`
+		FileId      int64  `json:"FileId"`
+		Reuse       bool   `json:"Reuse"`
+	} `json:"data"`
+}
+
+// S3PreSignedUrls the urls to put the parts, the keys are the part numbers
+type S3PreSignedUrls struct {
+	BaseResp
+	Data struct {
+		PreSignedUrls map[string]string `json:"presignedUrls"`
+	} `json:"data"`
+}
+
+type UserInfoResp struct {
+	BaseResp
+	Data struct {
+		UID int64 `json:"UID"`
 	} `json:"data"`
 }
@@ -1,17 +1,33 @@
 package _123
 
 import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/go-resty/resty/v2"
 	jsoniter "github.com/json-iterator/go"
 )
 
 // do others that not defined in Driver interface
 
+// the size of the parts of the uploads, the files not larger than it are put by one request
+const chunkSize int64 = 16 * 1024 * 1024
+
 func (d *Pan123) login() error {
 	url := "https://www.123pan.com/api/user/sign_in"
 	var resp TokenResp
@@ -85,3 +101,141 @@ func (d *Pan123) getFiles(parentId string) ([]File, error) {
 	}
 	return res, nil
 }
+
+// getUID get the uid of the user, it's a part of the auth key of the direct links
+func (d *Pan123) getUID() (int64, error) {
+	var resp UserInfoResp
+	_, err := d.request("https://www.123pan.com/api/user/info", http.MethodGet, nil, &resp)
+	if err != nil {
+		return 0, err
+	}
+	return resp.Data.UID, nil
+}
+
+// directLink get the url of the file in the direct link space, it's signed by the private key if it's set,
+// the auth_key is timestamp-rand-uid-md5(path-timestamp-rand-uid-privateKey) and the timestamp is the expiry
+func (d *Pan123) directLink(path string) (*model.Link, error) {
+	u, err := url.Parse(strings.TrimSuffix(d.DirectLinkURL, "/") + utils.EncodePath(path, true))
+	if err != nil {
+		return nil, err
+	}
+	link := &model.Link{}
+	if d.DirectLinkPrivateKey != "" {
+		expire := time.Duration(d.DirectLinkExpire) * time.Minute
+		if expire <= 0 {
+			expire = 30 * time.Minute
+		}
+		ts := time.Now().Add(expire).Unix()
+		r := rand.Int63n(1 << 31)
+		sum := md5.Sum([]byte(fmt.Sprintf("%s-%d-%d-%d-%s", u.Path, ts, r, d.uid, d.DirectLinkPrivateKey)))
+		q := u.Query()
+		q.Set("auth_key", fmt.Sprintf("%d-%d-%d-%s", ts, r, d.uid, hex.EncodeToString(sum[:])))
+		u.RawQuery = q.Encode()
+		// it's signed again when the cached one expires
+		link.Expiration = &expire
+	}
+	link.URL = u.String()
+	return link, nil
+}
+
+// getUploadUrls get the presigned urls of the parts from start to end, both are included
+func (d *Pan123) getUploadUrls(upReq *UploadResp, start, end int, multipart bool) (map[string]string, error) {
+	data := base.Json{
+		"bucket":          upReq.Data.Bucket,
+		"key":             upReq.Data.Key,
+		"uploadId":        upReq.Data.UploadId,
+		"StorageNode":     upReq.Data.StorageNode,
+		"partNumberStart": start,
+		"partNumberEnd":   end,
+	}
+	u := "https://www.123pan.com/b/api/file/s3_repare_upload_parts_batch"
+	if !multipart {
+		// the file of a single part is put by one request
+		u = "https://www.123pan.com/b/api/file/s3_upload_object/auth"
+	}
+	var resp S3PreSignedUrls
+	_, err := d.request(u, http.MethodPost, func(req *resty.Request) {
+		req.SetBody(data)
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data.PreSignedUrls, nil
+}
+
+// uploadPart put the part to the presigned url, it's retried 3 times
+func (d *Pan123) uploadPart(ctx context.Context, u string, data []byte) error {
+	var err error
+	for i := 0; i < 3; i++ {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		var req *http.Request
+		req, err = http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.ContentLength = int64(len(data))
+		var res *http.Response
+		res, err = base.HttpClient.Do(req)
+		if err != nil {
+			continue
+		}
+		_ = res.Body.Close()
+		if res.StatusCode == http.StatusOK {
+			return nil
+		}
+		err = fmt.Errorf("failed upload the part: %s", res.Status)
+	}
+	return err
+}
+
+// uploadS3 upload the file by the parts of chunkSize, the urls of the parts are got by batches
+func (d *Pan123) uploadS3(ctx context.Context, upReq *UploadResp, file io.Reader, size int64, up driver.UpdateProgress) error {
+	chunkCount := int((size + chunkSize - 1) / chunkSize)
+	if chunkCount == 0 {
+		chunkCount = 1
+	}
+	isMultipart := chunkCount > 1
+	buf := make([]byte, chunkSize)
+	const batchSize = 10
+	for start := 1; start <= chunkCount; start += batchSize {
+		end := start + batchSize - 1
+		if end > chunkCount {
+			end = chunkCount
+		}
+		urls, err := d.getUploadUrls(upReq, start, end, isMultipart)
+		if err != nil {
+			return err
+		}
+		for i := start; i <= end; i++ {
+			n := chunkSize
+			if i == chunkCount {
+				n = size - chunkSize*int64(chunkCount-1)
+			}
+			if _, err = io.ReadFull(file, buf[:n]); err != nil {
+				return err
+			}
+			u, ok := urls[strconv.Itoa(i)]
+			if !ok {
+				return fmt.Errorf("no upload url of the part %d", i)
+			}
+			if err = d.uploadPart(ctx, u, buf[:n]); err != nil {
+				return err
+			}
+			up(i * 100 / chunkCount)
+		}
+	}
+	_, err := d.request("https://www.123pan.com/b/api/file/s3_complete_multipart_upload", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"StorageNode": upReq.Data.StorageNode,
+			"bucket":      upReq.Data.Bucket,
+			"fileId":      upReq.Data.FileId,
+			"fileSize":    size,
+			"isMultipart": isMultipart,
+			"key":         upReq.Data.Key,
+			"uploadId":    upReq.Data.UploadId,
+		})
+	}, nil)
+	return err
+}
@@ -36,6 +36,9 @@ func (d *Yun139) Init(ctx context.Context, storage model.Storage) error {
 	if err != nil {
 		return err
 	}
+	if (d.isFamily() || d.isGroup()) && d.CloudID == "" {
+		return fmt.Errorf("the cloud id of the %s space is required", d.Type)
+	}
 	_, err = d.post("/orchestration/personalCloud/user/v1.0/qryUserExternInfo", base.Json{
 		"qryUserExternInfoReq": base.Json{
 			"commonAccountInfo": base.Json{
@@ -52,9 +55,12 @@ func (d *Yun139) Drop(ctx context.Context) error {
 }
 
 func (d *Yun139) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
-	if d.isFamily() {
-		return d.familyGetFiles(dir.GetID())
-	} else {
+	switch {
+	case d.isFamily():
+		return d.familyGetFiles(dir)
+	case d.isGroup():
+		return d.groupGetFiles(dir)
+	default:
 		return d.getFiles(dir.GetID())
 	}
 }
@@ -65,7 +71,7 @@ func (d *Yun139) List(ctx context.Context, dir model.Obj, args model.ListArgs) (
 //}
 
 func (d *Yun139) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
-	u, err := d.getLink(file.GetID())
+	u, err := d.getLink(file)
 	if err != nil {
 		return nil, err
 	}
@@ -84,7 +90,8 @@ func (d *Yun139) MakeDir(ctx context.Context, parentDir model.Obj, dirName strin
 		},
 	}
 	pathname := "/orchestration/personalCloud/catalog/v1.0/createCatalogExt"
-	if d.isFamily() {
+	switch {
+	case d.isFamily():
 		data = base.Json{
 			"cloudID": d.CloudID,
 			"commonAccountInfo": base.Json{
@@ -92,8 +99,21 @@ func (d *Yun139) MakeDir(ctx context.Context, parentDir model.Obj, dirName strin
 				"accountType": 1,
 			},
 			"docLibName": dirName,
+			"path":       catalogPath(parentDir),
 		}
 		pathname = "/orchestration/familyCloud/cloudCatalog/v1.0/createCloudDoc"
+	case d.isGroup():
+		data = base.Json{
+			"catalogName":  dirName,
+			"groupID":      d.CloudID,
+			"parentFileId": parentDir.GetID(),
+			"path":         catalogPath(parentDir),
+			"commonAccountInfo": base.Json{
+				"account":     d.Account,
+				"accountType": 1,
+			},
+		}
+		pathname = "/orchestration/group-rebuild/catalog/v1.0/createGroupCatalog"
 	}
 	_, err := d.post(pathname,
 		data, nil)
@@ -104,6 +124,9 @@ func (d *Yun139) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
 	if d.isFamily() {
 		return errs.NotImplement
 	}
+	if d.isGroup() {
+		return d.groupMove(srcObj, dstDir)
+	}
 	var contentInfoList []string
 	var catalogInfoList []string
 	if srcObj.IsDir() {
@@ -132,8 +155,11 @@ func (d *Yun139) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
 }
 
 func (d *Yun139) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
-	if d.isFamily() {
-		return errs.NotImplement
+	switch {
+	case d.isFamily():
+		return d.familyRename(srcObj, newName)
+	case d.isGroup():
+		return d.groupRename(srcObj, newName)
 	}
 	var data base.Json
 	var pathname string
@@ -163,7 +189,7 @@ func (d *Yun139) Rename(ctx context.Context, srcObj model.Obj, newName string) e
 }
 
 func (d *Yun139) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
-	if d.isFamily() {
+	if d.isFamily() || d.isGroup() {
 		return errs.NotImplement
 	}
 	var contentInfoList []string
@@ -217,18 +243,35 @@ func (d *Yun139) Remove(ctx context.Context, obj model.Obj) error {
 		},
 	}
 	pathname := "/orchestration/personalCloud/batchOprTask/v1.0/createBatchOprTask"
-	if d.isFamily() {
+	switch {
+	case d.isFamily():
+		catalogList, contentList := spaceLists(obj)
 		data = base.Json{
-			"catalogList": catalogInfoList,
-			"contentList": contentInfoList,
+			"catalogList": catalogList,
+			"contentList": contentList,
 			"commonAccountInfo": base.Json{
 				"account":     d.Account,
 				"accountType": 1,
 			},
+			"sourceCloudID":     d.CloudID,
 			"sourceCatalogType": 1002,
 			"taskType":          2,
+			"path":              parentPath(obj),
 		}
 		pathname = "/orchestration/familyCloud/batchOprTask/v1.0/createBatchOprTask"
+	case d.isGroup():
+		catalogList, contentList := spaceLists(obj)
+		data = base.Json{
+			"taskType":    2,
+			"srcGroupID":  d.CloudID,
+			"catalogList": catalogList,
+			"contentList": contentList,
+			"commonAccountInfo": base.Json{
+				"account":     d.Account,
+				"accountType": 1,
+			},
+		}
+		pathname = "/orchestration/group-rebuild/content/v1.0/deleteContent"
 	}
 	_, err := d.post(pathname, data, nil)
 	return err
@@ -253,12 +296,13 @@ func (d *Yun139) Put(ctx context.Context, dstDir model.Obj, stream model.FileStr
 		},
 	}
 	pathname := "/orchestration/personalCloud/uploadAndDownload/v1.0/pcUploadFileRequest"
-	if d.isFamily() {
+	switch {
+	case d.isFamily():
 		data = d.newJson(base.Json{
 			"fileCount":    1,
 			"manualRename": 2,
 			"operation":    0,
-			"path":         "",
+			"path":         catalogPath(dstDir),
 			"seqNo":        "",
 			"totalSize":    stream.GetSize(),
 			"uploadContentList": []base.Json{{
@@ -268,7 +312,25 @@ func (d *Yun139) Put(ctx context.Context, dstDir model.Obj, stream model.FileStr
 			}},
 		})
 		pathname = "/orchestration/familyCloud/content/v1.0/getFileUploadURL"
-		return errs.NotImplement
+	case d.isGroup():
+		data = base.Json{
+			"fileCount":    1,
+			"groupID":      d.CloudID,
+			"manualRename": 2,
+			"operation":    0,
+			"path":         catalogPath(dstDir),
+			"seqNo":        "",
+			"totalSize":    stream.GetSize(),
+			"uploadContentList": []base.Json{{
+				"contentName": stream.GetName(),
+				"contentSize": stream.GetSize(),
+			}},
+			"commonAccountInfo": base.Json{
+				"account":     d.Account,
+				"accountType": 1,
+			},
+		}
+		pathname = "/orchestration/group-rebuild/content/v1.0/getGroupFileUploadURL"
 	}
 	var resp UploadResp
 	_, err := d.post(pathname, data, &resp)
@@ -302,7 +364,7 @@ func (d *Yun139) Put(ctx context.Context, dstDir model.Obj, stream model.FileStr
 			"rangeType":      "0",
 			"Referer":        "https://yun.139.com/",
 			"User-Agent":     "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_2) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/95.0.4638.69 Safari/537.36 Edg/95.0.1020.44",
-			"x-SvcType":      "1",
+			"x-SvcType":      d.svcType(),
 		}
 		for k, v := range headers {
 			req.Header.Set(k, v)
@@ -7,15 +7,17 @@ import (
 
 type Addition struct {
 	Account string `json:"account" required:"true"`
-	Cookie  string `json:"cookie" type:"text" required:"true"`
+	Cookie  string `json:"cookie" type:"text" required:"true" secret:"true"`
 	driver.RootID
-	Type    string `json:"type" type:"select" options:"personal,family" default:"personal"`
-	CloudID string `json:"cloud_id"`
+	Type    string `json:"type" type:"select" options:"personal,family,group" default:"personal" help:"the personal space, the family cloud or the group space"`
+	CloudID string `json:"cloud_id" help:"the id of the family cloud or the group, it's required if the type isn't personal"`
 }
 
 var config = driver.Config{
-	Name:      "139Yun",
-	LocalSort: true,
+	Name:                   "139Yun",
+	LocalSort:              true,
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
 }
 
 func init() {
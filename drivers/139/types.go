@@ -1,5 +1,14 @@
 package _139
 
+import "github.com/alist-org/alist/v3/internal/model"
+
+// Object is the object of the family and group spaces, which are located by the path of the ids of the catalogs
+type Object struct {
+	model.ObjThumb
+	// the path of the ids of the catalogs the object is in
+	ParentPath string
+}
+
 type BaseResp struct {
 	Success bool   `json:"success"`
 	Code    string `json:"code"`
@@ -185,3 +194,33 @@ type QueryContentListResp struct {
 		RecallContent    interface{}    `json:"recallContent"`
 	} `json:"data"`
 }
+
+type GroupCatalog struct {
+	CatalogID   string `json:"catalogID"`
+	CatalogName string `json:"catalogName"`
+	UpdateTime  string `json:"updateTime"`
+}
+
+type GroupContent struct {
+	ContentID    string `json:"contentID"`
+	ContentName  string `json:"contentName"`
+	ContentSize  int64  `json:"contentSize"`
+	UpdateTime   string `json:"updateTime"`
+	ThumbnailURL string `json:"thumbnailURL"`
+}
+
+type QueryGroupContentListResp struct {
+	BaseResp
+	Data struct {
+		Result struct {
+			ResultCode string `json:"resultCode"`
+			ResultDesc string `json:"resultDesc"`
+		} `json:"result"`
+		GetGroupContentResult struct {
+			ParentCatalogID string         `json:"parentCatalogID"`
+			CatalogList     []GroupCatalog `json:"catalogList"`
+			ContentList     []GroupContent `json:"contentList"`
+			NodeCountNum    int            `json:"nodeCountNum"`
+		} `json:"getGroupContentResult"`
+	} `json:"data"`
+}
@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	stdpath "path"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/pkg/utils/random"
@@ -25,6 +27,38 @@ func (d *Yun139) isFamily() bool {
 	return d.Type == "family"
 }
 
+func (d *Yun139) isGroup() bool {
+	return d.Type == "group"
+}
+
+// svcType the x-SvcType of the space, which tells the api the space requested
+func (d *Yun139) svcType() string {
+	switch {
+	case d.isFamily():
+		return "2"
+	case d.isGroup():
+		return "3"
+	}
+	return "1"
+}
+
+// catalogPath get the path of the ids of the catalogs to the dir in the family and group spaces,
+// it's the id of the root for the root
+func catalogPath(dir model.Obj) string {
+	if o, ok := dir.(*Object); ok {
+		return stdpath.Join(o.ParentPath, o.GetID())
+	}
+	return dir.GetID()
+}
+
+// parentPath get the path of the ids of the catalogs the object is in
+func parentPath(obj model.Obj) string {
+	if o, ok := obj.(*Object); ok {
+		return o.ParentPath
+	}
+	return ""
+}
+
 func encodeURIComponent(str string) string {
 	r := url.QueryEscape(str)
 	r = strings.Replace(r, "+", "%20", -1)
@@ -62,10 +96,6 @@ func (d *Yun139) request(pathname string, method string, callback base.ReqCallba
 		return nil, err
 	}
 	sign := calSign(string(body), ts, randStr)
-	svcType := "1"
-	if d.isFamily() {
-		svcType = "2"
-	}
 	req.SetHeaders(map[string]string{
 		"Accept":         "application/json, text/plain, */*",
 		"CMS-DEVICE":     "default",
@@ -83,7 +113,7 @@ func (d *Yun139) request(pathname string, method string, callback base.ReqCallba
 		"x-inner-ntwk":        "2",
 		"x-m4c-caller":        "PC",
 		"x-m4c-src":           "10002",
-		"x-SvcType":           svcType,
+		"x-SvcType":           d.svcType(),
 	})
 
 	var e BaseResp
@@ -174,12 +204,13 @@ func (d *Yun139) newJson(data map[string]interface{}) base.Json {
 	return utils.MergeMap(data, common)
 }
 
-func (d *Yun139) familyGetFiles(catalogID string) ([]model.Obj, error) {
+func (d *Yun139) familyGetFiles(dir model.Obj) ([]model.Obj, error) {
 	pageNum := 1
 	files := make([]model.Obj, 0)
+	path := catalogPath(dir)
 	for {
 		data := d.newJson(base.Json{
-			"catalogID":       catalogID,
+			"catalogID":       dir.GetID(),
 			"contentSortType": 0,
 			"pageInfo": base.Json{
 				"pageNum":  pageNum,
@@ -193,25 +224,32 @@ func (d *Yun139) familyGetFiles(catalogID string) ([]model.Obj, error) {
 			return nil, err
 		}
 		for _, catalog := range resp.Data.CloudCatalogList {
-			f := model.Object{
-				ID:       catalog.CatalogID,
-				Name:     catalog.CatalogName,
-				Size:     0,
-				IsFolder: true,
-				Modified: getTime(catalog.LastUpdateTime),
+			f := Object{
+				ObjThumb: model.ObjThumb{
+					Object: model.Object{
+						ID:       catalog.CatalogID,
+						Name:     catalog.CatalogName,
+						Size:     0,
+						IsFolder: true,
+						Modified: getTime(catalog.LastUpdateTime),
+					},
+				},
+				ParentPath: path,
 			}
 			files = append(files, &f)
 		}
 		for _, content := range resp.Data.CloudContentList {
-			f := model.ObjThumb{
-				Object: model.Object{
-					ID:       content.ContentID,
-					Name:     content.ContentName,
-					Size:     content.ContentSize,
-					Modified: getTime(content.LastUpdateTime),
+			f := Object{
+				ObjThumb: model.ObjThumb{
+					Object: model.Object{
+						ID:       content.ContentID,
+						Name:     content.ContentName,
+						Size:     content.ContentSize,
+						Modified: getTime(content.LastUpdateTime),
+					},
+					Thumbnail: model.Thumbnail{Thumbnail: content.ThumbnailURL},
 				},
-				Thumbnail: model.Thumbnail{Thumbnail: content.ThumbnailURL},
-				//Thumbnail: content.BigthumbnailURL,
+				ParentPath: path,
 			}
 			files = append(files, &f)
 		}
@@ -223,17 +261,101 @@ func (d *Yun139) familyGetFiles(catalogID string) ([]model.Obj, error) {
 	return files, nil
 }
 
-func (d *Yun139) getLink(contentId string) (string, error) {
-	data := base.Json{
-		"appName":   "",
-		"contentID": contentId,
-		"commonAccountInfo": base.Json{
-			"account":     d.Account,
-			"accountType": 1,
-		},
+func (d *Yun139) groupGetFiles(dir model.Obj) ([]model.Obj, error) {
+	start := 0
+	limit := 100
+	files := make([]model.Obj, 0)
+	path := catalogPath(dir)
+	for {
+		data := base.Json{
+			"groupID":         d.CloudID,
+			"catalogID":       dir.GetID(),
+			"contentSortType": 0,
+			"sortDirection":   1,
+			"startNumber":     start + 1,
+			"endNumber":       start + limit,
+			"path":            path,
+			"commonAccountInfo": base.Json{
+				"account":     d.Account,
+				"accountType": 1,
+			},
+		}
+		var resp QueryGroupContentListResp
+		_, err := d.post("/orchestration/group-rebuild/content/v1.0/queryGroupContentList", data, &resp)
+		if err != nil {
+			return nil, err
+		}
+		result := resp.Data.GetGroupContentResult
+		for _, catalog := range result.CatalogList {
+			f := Object{
+				ObjThumb: model.ObjThumb{
+					Object: model.Object{
+						ID:       catalog.CatalogID,
+						Name:     catalog.CatalogName,
+						IsFolder: true,
+						Modified: getTime(catalog.UpdateTime),
+					},
+				},
+				ParentPath: path,
+			}
+			files = append(files, &f)
+		}
+		for _, content := range result.ContentList {
+			f := Object{
+				ObjThumb: model.ObjThumb{
+					Object: model.Object{
+						ID:       content.ContentID,
+						Name:     content.ContentName,
+						Size:     content.ContentSize,
+						Modified: getTime(content.UpdateTime),
+					},
+					Thumbnail: model.Thumbnail{Thumbnail: content.ThumbnailURL},
+				},
+				ParentPath: path,
+			}
+			files = append(files, &f)
+		}
+		if start+limit >= result.NodeCountNum {
+			break
+		}
+		start += limit
+	}
+	return files, nil
+}
+
+func (d *Yun139) getLink(file model.Obj) (string, error) {
+	var data base.Json
+	var pathname string
+	switch {
+	case d.isFamily():
+		data = d.newJson(base.Json{
+			"contentID": file.GetID(),
+			"path":      parentPath(file),
+		})
+		pathname = "/orchestration/familyCloud/content/v1.0/getFileDownLoadURL"
+	case d.isGroup():
+		data = base.Json{
+			"contentID": file.GetID(),
+			"groupID":   d.CloudID,
+			"path":      parentPath(file),
+			"commonAccountInfo": base.Json{
+				"account":     d.Account,
+				"accountType": 1,
+			},
+		}
+		pathname = "/orchestration/group-rebuild/groupManage/v1.0/getGroupFileDownLoadURL"
+	default:
+		data = base.Json{
+			"appName":   "",
+			"contentID": file.GetID(),
+			"commonAccountInfo": base.Json{
+				"account":     d.Account,
+				"accountType": 1,
+			},
+		}
+		pathname = "/orchestration/personalCloud/uploadAndDownload/v1.0/downloadRequest"
 	}
-	res, err := d.post("/orchestration/personalCloud/uploadAndDownload/v1.0/downloadRequest",
-		data, nil)
+	res, err := d.post(pathname, data, nil)
 	if err != nil {
 		return "", err
 	}
@@ -245,3 +367,69 @@ func unicode(str string) string {
 	textUnquoted := textQuoted[1 : len(textQuoted)-1]
 	return textUnquoted
 }
+
+// spaceLists get the catalog list and the content list of the object in the family and group spaces,
+// the items are the paths of the ids
+func spaceLists(obj model.Obj) (catalogList, contentList []string) {
+	p := stdpath.Join(parentPath(obj), obj.GetID())
+	if obj.IsDir() {
+		return []string{p}, []string{}
+	}
+	return []string{}, []string{p}
+}
+
+func (d *Yun139) familyRename(srcObj model.Obj, newName string) error {
+	if srcObj.IsDir() {
+		return errs.NotImplement
+	}
+	data := d.newJson(base.Json{
+		"contentID":   srcObj.GetID(),
+		"contentName": newName,
+		"path":        parentPath(srcObj),
+	})
+	_, err := d.post("/orchestration/familyCloud/photoContent/v1.0/modifyContentInfo", data, nil)
+	return err
+}
+
+func (d *Yun139) groupRename(srcObj model.Obj, newName string) error {
+	data := base.Json{
+		"groupID": d.CloudID,
+		"path":    parentPath(srcObj),
+		"commonAccountInfo": base.Json{
+			"account":     d.Account,
+			"accountType": 1,
+		},
+	}
+	pathname := "/orchestration/group-rebuild/content/v1.0/modifyGroupContent"
+	if srcObj.IsDir() {
+		data["modifyCatalogID"] = srcObj.GetID()
+		data["modifyCatalogName"] = newName
+		data["path"] = catalogPath(srcObj)
+		pathname = "/orchestration/group-rebuild/catalog/v1.0/modifyGroupCatalog"
+	} else {
+		data["contentID"] = srcObj.GetID()
+		data["contentName"] = newName
+	}
+	_, err := d.post(pathname, data, nil)
+	return err
+}
+
+func (d *Yun139) groupMove(srcObj, dstDir model.Obj) error {
+	catalogList, contentList := spaceLists(srcObj)
+	data := base.Json{
+		"taskType":    3,
+		"srcType":     2,
+		"srcGroupID":  d.CloudID,
+		"destType":    2,
+		"destGroupID": d.CloudID,
+		"destPath":    catalogPath(dstDir),
+		"catalogList": catalogList,
+		"contentList": contentList,
+		"commonAccountInfo": base.Json{
+			"account":     d.Account,
+			"accountType": 1,
+		},
+	}
+	_, err := d.post("/orchestration/group-rebuild/content/v1.0/moveContent", data, nil)
+	return err
+}
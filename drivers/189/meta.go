@@ -7,14 +7,16 @@ import (
 
 type Addition struct {
 	Username string `json:"username" required:"true"`
-	Password string `json:"password" required:"true"`
+	Password string `json:"password" required:"true" secret:"true"`
 	driver.RootID
 }
 
 var config = driver.Config{
-	Name:        "189Cloud",
-	LocalSort:   true,
-	DefaultRoot: "-11",
+	Name:                   "189Cloud",
+	LocalSort:              true,
+	DefaultRoot:            "-11",
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
 }
 
 func init() {
@@ -247,27 +247,7 @@ func (y *Yun189PC) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
 }
 
 func (y *Yun189PC) Remove(ctx context.Context, obj model.Obj) error {
-	_, err := y.post(API_URL+"/batch/createBatchTask.action", func(req *resty.Request) {
-		req.SetContext(ctx)
-		req.SetFormData(map[string]string{
-			"type": "DELETE",
-			"taskInfos": MustString(utils.Json.MarshalToString(
-				[]*BatchTaskInfo{
-					{
-						FileId:   obj.GetID(),
-						FileName: obj.GetName(),
-						IsFolder: BoolToNumber(obj.IsDir()),
-					},
-				})),
-		})
-
-		if y.isFamily() {
-			req.SetFormData(map[string]string{
-				"familyId": y.FamilyID,
-			})
-		}
-	}, nil)
-	return err
+	return y.batchTask(ctx, "DELETE", obj)
 }
 
 func (y *Yun189PC) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
@@ -276,3 +256,15 @@ func (y *Yun189PC) Put(ctx context.Context, dstDir model.Obj, stream model.FileS
 	}
 	return y.CommonUpload(ctx, dstDir, stream, up)
 }
+
+func (y *Yun189PC) ListTrash(ctx context.Context) ([]model.Obj, error) {
+	return y.getRecycleFiles(ctx)
+}
+
+func (y *Yun189PC) Restore(ctx context.Context, obj model.Obj) error {
+	return y.batchTask(ctx, "RESTORE", obj)
+}
+
+func (y *Yun189PC) Purge(ctx context.Context, obj model.Obj) error {
+	return y.batchTask(ctx, "CLEAR_RECYCLE", obj)
+}
@@ -7,7 +7,7 @@ import (
 
 type Addition struct {
 	Username string `json:"username" required:"true"`
-	Password string `json:"password" required:"true"`
+	Password string `json:"password" required:"true" secret:"true"`
 	VCode    string `json:"validate_code"`
 	driver.RootID
 	OrderBy        string `json:"order_by" type:"select" options:"filename,filesize,lastOpTime" default:"filename"`
@@ -18,8 +18,10 @@ type Addition struct {
 }
 
 var config = driver.Config{
-	Name:        "189CloudPC",
-	DefaultRoot: "-11",
+	Name:                   "189CloudPC",
+	DefaultRoot:            "-11",
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
 }
 
 func init() {
@@ -6,6 +6,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
 )
 
 // 居然有四种返回方式
@@ -144,7 +146,13 @@ func (c *Cloud189File) ModTime() time.Time {
 func (c *Cloud189File) IsDir() bool     { return false }
 func (c *Cloud189File) GetID() string   { return fmt.Sprint(c.ID) }
 func (c *Cloud189File) GetPath() string { return "" }
-func (c *Cloud189File) Thumb() string   { return c.Icon.SmallUrl }
+func (c *Cloud189File) GetHash() model.HashInfo {
+	if c.Md5 == "" {
+		return model.HashInfo{}
+	}
+	return model.HashInfo{Type: "md5", Value: strings.ToLower(c.Md5)}
+}
+func (c *Cloud189File) Thumb() string { return c.Icon.SmallUrl }
 
 // 文件夹
 type Cloud189Folder struct {
@@ -173,9 +181,10 @@ func (c *Cloud189Folder) ModTime() time.Time {
 	}
 	return *c.parseTime
 }
-func (c *Cloud189Folder) IsDir() bool     { return true }
-func (c *Cloud189Folder) GetID() string   { return fmt.Sprint(c.ID) }
-func (c *Cloud189Folder) GetPath() string { return "" }
+func (c *Cloud189Folder) IsDir() bool             { return true }
+func (c *Cloud189Folder) GetID() string           { return fmt.Sprint(c.ID) }
+func (c *Cloud189Folder) GetPath() string         { return "" }
+func (c *Cloud189Folder) GetHash() model.HashInfo { return model.HashInfo{} }
 
 type Cloud189FilesResp struct {
 	//ResCode    int    `json:"res_code"`
@@ -187,6 +196,13 @@ type Cloud189FilesResp struct {
 	} `json:"fileListAO"`
 }
 
+// 回收站文件
+type Cloud189RecycleFilesResp struct {
+	Count      int              `json:"count"`
+	FileList   []Cloud189File   `json:"fileList"`
+	FolderList []Cloud189Folder `json:"folderList"`
+}
+
 // TaskInfo 任务信息
 type BatchTaskInfo struct {
 	// FileId 文件ID
@@ -184,6 +184,68 @@ func (y *Yun189PC) getFiles(ctx context.Context, fileId string) ([]model.Obj, er
 	return res, nil
 }
 
+// 获取回收站文件
+func (y *Yun189PC) getRecycleFiles(ctx context.Context) ([]model.Obj, error) {
+	fullUrl := API_URL + "/listRecycleBinFiles.action"
+	res := make([]model.Obj, 0, 100)
+	for pageNum := 1; ; pageNum++ {
+		var resp Cloud189RecycleFilesResp
+		_, err := y.get(fullUrl, func(r *resty.Request) {
+			r.SetContext(ctx)
+			r.SetQueryParams(map[string]string{
+				"iconOption": "5",
+				"pageNum":    fmt.Sprint(pageNum),
+				"pageSize":   "100",
+			})
+			if y.isFamily() {
+				r.SetQueryParams(map[string]string{
+					"familyId": y.FamilyID,
+				})
+			}
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		// 获取完毕跳出
+		if len(resp.FolderList) == 0 && len(resp.FileList) == 0 {
+			break
+		}
+
+		for i := 0; i < len(resp.FolderList); i++ {
+			res = append(res, &resp.FolderList[i])
+		}
+		for i := 0; i < len(resp.FileList); i++ {
+			res = append(res, &resp.FileList[i])
+		}
+	}
+	return res, nil
+}
+
+// 创建批量任务，如删除、恢复、彻底删除
+func (y *Yun189PC) batchTask(ctx context.Context, taskType string, obj model.Obj) error {
+	_, err := y.post(API_URL+"/batch/createBatchTask.action", func(req *resty.Request) {
+		req.SetContext(ctx)
+		req.SetFormData(map[string]string{
+			"type": taskType,
+			"taskInfos": MustString(utils.Json.MarshalToString(
+				[]*BatchTaskInfo{
+					{
+						FileId:   obj.GetID(),
+						FileName: obj.GetName(),
+						IsFolder: BoolToNumber(obj.IsDir()),
+					},
+				})),
+		})
+
+		if y.isFamily() {
+			req.SetFormData(map[string]string{
+				"familyId": y.FamilyID,
+			})
+		}
+	}, nil)
+	return err
+}
+
 func (y *Yun189PC) login() (err error) {
 	// 初始化登陆所需参数
 	if y.loginParam == nil {
@@ -0,0 +1,226 @@
+package alias
+
+import (
+	"context"
+	"io"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Alias expose the paths of other storages in the root with the names, so the curated views
+// can be composed without copying the files
+type Alias struct {
+	model.Storage
+	Addition
+	aliases []alias
+}
+
+func (d *Alias) Config() driver.Config {
+	return config
+}
+
+func (d *Alias) GetAddition() driver.Additional {
+	return d.Addition
+}
+
+func (d *Alias) Init(ctx context.Context, storage model.Storage) error {
+	d.Storage = storage
+	err := utils.Json.UnmarshalFromString(d.Storage.Addition, &d.Addition)
+	if err != nil {
+		return err
+	}
+	d.aliases, err = parseAliases(d.Paths)
+	if err != nil {
+		return err
+	}
+	if len(d.aliases) == 0 {
+		return errors.New("the paths of the aliases are required")
+	}
+	for _, a := range d.aliases {
+		if utils.IsSubPath(d.MountPath, a.path) {
+			return errors.Errorf("the path [%s] can't be in the alias storage itself", a.path)
+		}
+	}
+	return nil
+}
+
+func (d *Alias) Drop(ctx context.Context) error {
+	return nil
+}
+
+// convert the object in the storage of the target to the one in the path
+func convert(path string, obj model.Obj) model.Obj {
+	o := model.Object{
+		Name:     stdpath.Base(path),
+		Path:     path,
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		IsFolder: obj.IsDir(),
+	}
+	if t, ok := obj.(model.Thumb); ok && t.Thumb() != "" {
+		return &model.ObjThumb{Object: o, Thumbnail: model.Thumbnail{Thumbnail: t.Thumb()}}
+	}
+	return &o
+}
+
+func (d *Alias) Get(ctx context.Context, path string) (model.Obj, error) {
+	path = utils.StandardizePath(path)
+	if path == "/" {
+		return &model.Object{
+			Name:     "root",
+			Path:     "/",
+			Modified: d.Modified,
+			IsFolder: true,
+		}, nil
+	}
+	storage, actualPath, err := d.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := op.Get(ctx, storage, actualPath)
+	if err != nil {
+		return nil, err
+	}
+	return convert(path, obj), nil
+}
+
+func (d *Alias) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	dirPath := utils.StandardizePath(dir.GetPath())
+	if dirPath == "/" {
+		res := make([]model.Obj, 0, len(d.aliases))
+		for _, a := range d.aliases {
+			obj, err := d.Get(ctx, "/"+a.name)
+			if err != nil {
+				// the broken alias shouldn't hide the others
+				log.Warnf("failed get the alias [%s] of [%s]: %+v", a.name, a.path, err)
+				continue
+			}
+			res = append(res, obj)
+		}
+		return res, nil
+	}
+	target, err := d.target(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(target)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := op.List(ctx, storage, actualPath, model.ListArgs{ReqPath: target})
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.Obj, 0, len(objs))
+	for _, obj := range objs {
+		res = append(res, convert(stdpath.Join(dirPath, obj.GetName()), obj))
+	}
+	return res, nil
+}
+
+func (d *Alias) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	target, err := d.target(file.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(target)
+	if err != nil {
+		return nil, err
+	}
+	link, _, err := op.Link(ctx, storage, actualPath, args)
+	if err != nil {
+		return nil, err
+	}
+	// the local files are redirected to the target, and read directly if proxied
+	if link.URL == "" {
+		res := *link
+		res.URL = common.GetDownUrl(target, args.IP)
+		return &res, nil
+	}
+	return link, nil
+}
+
+// isAlias check if the path is an alias in the root, they can't be renamed or removed
+func isAlias(path string) bool {
+	return stdpath.Dir(utils.StandardizePath(path)) == "/"
+}
+
+func (d *Alias) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	if utils.PathEqual(parentDir.GetPath(), "/") {
+		return errors.WithStack(errs.NotSupport)
+	}
+	storage, path, err := d.resolve(stdpath.Join(parentDir.GetPath(), dirName))
+	if err != nil {
+		return err
+	}
+	return op.MakeDir(ctx, storage, path)
+}
+
+func (d *Alias) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	if isAlias(srcObj.GetPath()) {
+		return errors.WithStack(errs.NotSupport)
+	}
+	storage, srcPath, dstDirPath, err := d.resolveSame(srcObj.GetPath(), dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.Move(ctx, storage, srcPath, dstDirPath)
+}
+
+func (d *Alias) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	if isAlias(srcObj.GetPath()) {
+		return errors.WithStack(errs.NotSupport)
+	}
+	storage, path, err := d.resolve(srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.Rename(ctx, storage, path, newName)
+}
+
+func (d *Alias) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	storage, srcPath, dstDirPath, err := d.resolveSame(srcObj.GetPath(), dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.Copy(ctx, storage, srcPath, dstDirPath)
+}
+
+func (d *Alias) Remove(ctx context.Context, obj model.Obj) error {
+	if isAlias(obj.GetPath()) {
+		return errors.WithStack(errs.NotSupport)
+	}
+	storage, path, err := d.resolve(obj.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.Remove(ctx, storage, path)
+}
+
+func (d *Alias) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	if utils.PathEqual(dstDir.GetPath(), "/") {
+		return errors.WithStack(errs.NotSupport)
+	}
+	storage, dirPath, err := d.resolve(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	// the stream is closed by the caller, so the one put to the target mustn't close it again
+	return op.Put(ctx, storage, dirPath, &model.FileStream{
+		Obj:        stream,
+		ReadCloser: io.NopCloser(stream),
+		Mimetype:   stream.GetMimetype(),
+		Sha1:       stream.GetSha1(),
+	}, up)
+}
+
+var _ driver.Driver = (*Alias)(nil)
+var _ driver.Getter = (*Alias)(nil)
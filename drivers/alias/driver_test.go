@@ -0,0 +1,70 @@
+package alias_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/alias"
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	conf.Conf = conf.DefaultConfig()
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	db.Init(dB)
+}
+
+// the read only storage can't be changed through the alias
+func TestReadOnlyThroughAlias(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	addition, _ := utils.Json.MarshalToString(map[string]string{"root_folder_path": root})
+	if err := op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: "/ro", ReadOnly: true, Addition: addition}); err != nil {
+		t.Fatalf("failed to create the local storage: %+v", err)
+	}
+	if err := op.CreateStorage(ctx, model.Storage{Driver: "Alias", MountPath: "/alias", Addition: `{"paths":"/ro"}`}); err != nil {
+		t.Fatalf("failed to create the alias storage: %+v", err)
+	}
+	alias, err := op.GetStorageByVirtualPath("/alias")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := op.MakeDir(ctx, alias, "/ro/dir"); !errors.Is(err, errs.StorageReadOnly) {
+		t.Errorf("make dir: %v, want read only", err)
+	}
+	if err := op.Remove(ctx, alias, "/ro/a.txt"); !errors.Is(err, errs.StorageReadOnly) {
+		t.Errorf("remove: %v, want read only", err)
+	}
+	err = op.Put(ctx, alias, "/ro", &model.FileStream{
+		Obj:        &model.Object{Name: "b.txt", Size: 1},
+		ReadCloser: io.NopCloser(strings.NewReader("b")),
+	}, nil)
+	if !errors.Is(err, errs.StorageReadOnly) {
+		t.Errorf("put: %v, want read only", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "a.txt")); err != nil {
+		t.Errorf("the file is removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "b.txt")); !os.IsNotExist(err) {
+		t.Errorf("the file is put: %v", err)
+	}
+}
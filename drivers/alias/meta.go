@@ -0,0 +1,25 @@
+package alias
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	// the paths in alist exposed in the root, `name:/path` renames it
+	Paths string `json:"paths" type:"text" required:"true" help:"the paths in alist exposed in the root, one per line, use name:/path to rename it"`
+}
+
+var config = driver.Config{
+	Name:                   "Alias",
+	LocalSort:              true,
+	NoCache:                true,
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
+}
+
+func init() {
+	op.RegisterDriver(config, func() driver.Driver {
+		return &Alias{}
+	})
+}
@@ -0,0 +1,87 @@
+package alias
+
+import (
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// alias is a path in alist exposed in the root with the name
+type alias struct {
+	name string
+	path string
+}
+
+// parseAliases get the aliases, one per line, such as `/path` or `name:/path`
+func parseAliases(paths string) ([]alias, error) {
+	var res []alias
+	names := make(map[string]bool)
+	for _, line := range strings.Split(paths, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		a := alias{path: line}
+		// the path always starts with /, so the colon before it is the separator of the name
+		if i := strings.Index(line, ":/"); i > 0 {
+			a.name, a.path = strings.TrimSpace(line[:i]), line[i+1:]
+		}
+		a.path = utils.StandardizePath(a.path)
+		if a.name == "" {
+			a.name = stdpath.Base(a.path)
+		}
+		if a.path == "/" || strings.Contains(a.name, "/") {
+			return nil, errors.Errorf("invalid alias [%s]", line)
+		}
+		if names[a.name] {
+			return nil, errors.Errorf("duplicate name [%s] of the aliases", a.name)
+		}
+		names[a.name] = true
+		res = append(res, a)
+	}
+	return res, nil
+}
+
+// target get the path in alist of the path in the alias storage, the root has no target
+func (d *Alias) target(path string) (string, error) {
+	path = utils.StandardizePath(path)
+	name, rest := path[1:], ""
+	if i := strings.Index(name, "/"); i >= 0 {
+		name, rest = name[:i], name[i:]
+	}
+	for _, a := range d.aliases {
+		if a.name == name {
+			return stdpath.Join(a.path, rest), nil
+		}
+	}
+	return "", errors.WithStack(errs.ObjectNotFound)
+}
+
+// resolve get the storage and the actual path of the path in the alias storage
+func (d *Alias) resolve(path string) (driver.Driver, string, error) {
+	target, err := d.target(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return op.GetStorageAndActualPath(target)
+}
+
+// resolveSame resolve the paths, they must be in the same storage
+func (d *Alias) resolveSame(srcPath, dstPath string) (driver.Driver, string, string, error) {
+	storage, src, err := d.resolve(srcPath)
+	if err != nil {
+		return nil, "", "", err
+	}
+	dstStorage, dst, err := d.resolve(dstPath)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if storage.GetStorage().MountPath != dstStorage.GetStorage().MountPath {
+		return nil, "", "", errors.WithStack(errs.NotSupport)
+	}
+	return storage, src, dst, nil
+}
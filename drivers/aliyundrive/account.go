@@ -0,0 +1,67 @@
+package aliyundrive
+
+import (
+	"sync"
+)
+
+// account is shared by the storages with the same refresh token, such as mounting
+// the resource drive and backup drive of the same account, since the refresh token
+// is changed after refreshing, and the old one can't be used by the other storages
+type account struct {
+	mu           sync.Mutex
+	refreshToken string
+	accessToken  string
+	drivers      []*AliDrive
+}
+
+var (
+	accountsMu sync.Mutex
+	// refresh token -> account, the old refresh tokens are kept to find the account
+	accounts = make(map[string]*account)
+)
+
+// joinAccount find the account of the refresh token or create one, and add the driver to it
+func joinAccount(d *AliDrive) *account {
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+	acc, ok := accounts[d.RefreshToken]
+	if !ok {
+		acc = &account{refreshToken: d.RefreshToken}
+		accounts[d.RefreshToken] = acc
+	}
+	acc.mu.Lock()
+	acc.drivers = append(acc.drivers, d)
+	acc.mu.Unlock()
+	return acc
+}
+
+// leaveAccount remove the driver from the account, the account is deleted if no driver left
+func leaveAccount(d *AliDrive) {
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+	acc := d.account
+	if acc == nil {
+		return
+	}
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+	for i, driver := range acc.drivers {
+		if driver == d {
+			acc.drivers = append(acc.drivers[:i], acc.drivers[i+1:]...)
+			break
+		}
+	}
+	if len(acc.drivers) == 0 {
+		for token, a := range accounts {
+			if a == acc {
+				delete(accounts, token)
+			}
+		}
+	}
+}
+
+func addAccountToken(token string, acc *account) {
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+	accounts[token] = acc
+}
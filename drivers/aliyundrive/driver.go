@@ -9,16 +9,16 @@ import (
 	"fmt"
 	"io"
 	"math"
-	"math/big"
 	"net/http"
-	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/alist-org/alist/v3/drivers/base"
-	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/cron"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/go-resty/resty/v2"
@@ -31,6 +31,7 @@ type AliDrive struct {
 	AccessToken string
 	cron        *cron.Cron
 	DriveId     string
+	account     *account
 }
 
 func (d *AliDrive) Config() driver.Config {
@@ -47,24 +48,23 @@ func (d *AliDrive) Init(ctx context.Context, storage model.Storage) error {
 	if err != nil {
 		return err
 	}
-	// TODO login / refresh token
-	//op.MustSaveDriverStorage(d)
+	d.account = joinAccount(d)
 	err = d.refreshToken()
 	if err != nil {
 		return err
 	}
 	// get driver id
-	res, err, _ := d.request("https://api.aliyundrive.com/v2/user/get", http.MethodPost, nil, nil)
+	d.DriveId, err = d.getDriveId()
 	if err != nil {
 		return err
 	}
-	d.DriveId = utils.Json.Get(res, "default_drive_id").ToString()
 	d.cron = cron.NewCron(time.Hour * 2)
 	d.cron.Do(func() {
 		err := d.refreshToken()
 		if err != nil {
 			log.Errorf("%+v", err)
 		}
+		op.ReportTokenRefresh(d, err)
 	})
 	return err
 }
@@ -73,6 +73,8 @@ func (d *AliDrive) Drop(ctx context.Context) error {
 	if d.cron != nil {
 		d.cron.Stop()
 	}
+	leaveAccount(d)
+	d.account = nil
 	return nil
 }
 
@@ -86,6 +88,21 @@ func (d *AliDrive) List(ctx context.Context, dir model.Obj, args model.ListArgs)
 	})
 }
 
+func (d *AliDrive) ListPage(ctx context.Context, dir model.Obj, marker string, limit int) ([]model.Obj, string, error) {
+	// the max limit of the api
+	if limit > 200 {
+		limit = 200
+	}
+	files, next, err := d.getFilesPage(dir.GetID(), marker, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	objs, err := utils.SliceConvert(files, func(src File) (model.Obj, error) {
+		return fileToObj(src), nil
+	})
+	return objs, next, err
+}
+
 //func (d *AliDrive) Get(ctx context.Context, path string) (model.Obj, error) {
 //	// TODO this is optional
 //	return nil, errs.NotImplement
@@ -103,11 +120,13 @@ func (d *AliDrive) Link(ctx context.Context, file model.Obj, args model.LinkArgs
 	if err != nil {
 		return nil, err
 	}
+	exp := 14400 * time.Second
 	return &model.Link{
 		Header: http.Header{
 			"Referer": []string{"https://www.aliyundrive.com/"},
 		},
-		URL: utils.Json.Get(res, "url").ToString(),
+		URL:        utils.Json.Get(res, "url").ToString(),
+		Expiration: &exp,
 	}, nil
 }
 
@@ -156,6 +175,123 @@ func (d *AliDrive) Remove(ctx context.Context, obj model.Obj) error {
 	return err
 }
 
+func (d *AliDrive) Search(ctx context.Context, keyword string, dir model.Obj) ([]model.SearchNode, error) {
+	files, err := d.searchFiles(keyword)
+	if err != nil {
+		return nil, err
+	}
+	parents := make(map[string]string)
+	res := make([]model.SearchNode, 0, len(files))
+	for _, f := range files {
+		parent, ok := parents[f.ParentFileId]
+		if !ok {
+			p, in, err := d.getPath(f.ParentFileId)
+			if err != nil {
+				return nil, err
+			}
+			if in {
+				parent = p
+			}
+			parents[f.ParentFileId] = parent
+		}
+		// not in the root folder
+		if parent == "" {
+			continue
+		}
+		res = append(res, model.SearchNode{
+			Parent: parent,
+			Name:   f.Name,
+			IsDir:  f.Type == "folder",
+			Size:   f.Size,
+		})
+	}
+	return res, nil
+}
+
+func (d *AliDrive) GetQuota(ctx context.Context) (*model.StorageQuota, error) {
+	res, err, _ := d.request("https://api.aliyundrive.com/v2/databox/get_personal_info", http.MethodPost, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	total := utils.Json.Get(res, "personal_space_info", "total_size").ToInt64()
+	used := utils.Json.Get(res, "personal_space_info", "used_size").ToInt64()
+	return &model.StorageQuota{
+		Total: total,
+		Used:  used,
+		Free:  total - used,
+	}, nil
+}
+
+func (d *AliDrive) BatchMove(ctx context.Context, srcObjs []model.Obj, dstDir model.Obj) error {
+	return d.batchFiles(getIds(srcObjs), dstDir.GetID(), "/file/move")
+}
+
+func (d *AliDrive) BatchCopy(ctx context.Context, srcObjs []model.Obj, dstDir model.Obj) error {
+	return d.batchFiles(getIds(srcObjs), dstDir.GetID(), "/file/copy")
+}
+
+func (d *AliDrive) BatchRemove(ctx context.Context, objs []model.Obj) error {
+	return d.batchFiles(getIds(objs), "", "/recyclebin/trash")
+}
+
+// CrossCopy copy the file to another aliyundrive storage, the storages of the same account copy it
+// to the other drive directly, or the file is rapid uploaded to the other account with its content hash,
+// it is renamed instead of replacing the existing one with the same name
+func (d *AliDrive) CrossCopy(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	dstDrive, ok := dst.(*AliDrive)
+	if !ok {
+		return errs.NotSupport
+	}
+	if d.account == dstDrive.account {
+		return d.batchFilesTo([]string{srcObj.GetID()}, dstDrive.DriveId, dstDir.GetID(), "/file/copy")
+	}
+	if srcObj.IsDir() {
+		return errs.NotSupport
+	}
+	file, err := d.getFile(srcObj.GetID())
+	if err != nil {
+		return err
+	}
+	if file.ContentHash == "" {
+		return errs.NotSupport
+	}
+	link, err := d.Link(ctx, srcObj, model.LinkArgs{})
+	if err != nil {
+		return err
+	}
+	var proof []byte
+	if file.Size > 0 {
+		proof, err = readRange(ctx, link, dstDrive.proofOffset(file.Size), 8)
+		if err != nil {
+			return err
+		}
+	}
+	var resp UploadResp
+	_, err, _ = dstDrive.request("https://api.aliyundrive.com/adrive/v2/file/createWithFolders", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"check_name_mode":   "auto_rename",
+			"drive_id":          dstDrive.DriveId,
+			"name":              srcObj.GetName(),
+			"parent_file_id":    dstDir.GetID(),
+			"part_info_list":    []base.Json{{"part_number": 1}},
+			"size":              file.Size,
+			"type":              "file",
+			"content_hash":      file.ContentHash,
+			"content_hash_name": "sha1",
+			"proof_version":     "v1",
+			"proof_code":        base64.StdEncoding.EncodeToString(proof),
+		})
+	}, &resp)
+	if err != nil {
+		return err
+	}
+	// the upload created isn't completed, it's dropped by the provider
+	if !resp.RapidUpload {
+		return errs.NotSupport
+	}
+	return nil
+}
+
 func (d *AliDrive) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
 	file := model.FileStream{
 		Obj:        stream,
@@ -179,17 +315,25 @@ func (d *AliDrive) Put(ctx context.Context, dstDir model.Obj, stream model.FileS
 		"type":            "file",
 	}
 
+	// the source file can be read again without spooling, such as the local file
+	src, seekable := stream.GetReadCloser().(source)
 	if d.RapidUpload {
 		buf := bytes.NewBuffer(make([]byte, 0, 1024))
 		io.CopyN(buf, file, 1024)
 		reqBody["pre_hash"] = utils.GetSHA1Encode(buf.String())
-		// 把头部拼接回去
-		file.ReadCloser = struct {
-			io.Reader
-			io.Closer
-		}{
-			Reader: io.MultiReader(buf, file),
-			Closer: file,
+		if seekable {
+			if _, err := src.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		} else {
+			// 把头部拼接回去
+			file.ReadCloser = struct {
+				io.Reader
+				io.Closer
+			}{
+				Reader: io.MultiReader(buf, file),
+				Closer: file,
+			}
 		}
 	} else {
 		reqBody["content_hash_name"] = "none"
@@ -206,36 +350,48 @@ func (d *AliDrive) Put(ctx context.Context, dstDir model.Obj, stream model.FileS
 	}
 
 	if d.RapidUpload && e.Code == "PreHashMatched" {
-		tempFile, err := os.CreateTemp(conf.Conf.TempDir, "file-*")
-		if err != nil {
-			return err
-		}
-		defer func() {
-			_ = tempFile.Close()
-			_ = os.Remove(tempFile.Name())
-		}()
 		delete(reqBody, "pre_hash")
-		h := sha1.New()
-		if _, err = io.Copy(io.MultiWriter(tempFile, h), file); err != nil {
-			return err
+		contentHash := strings.ToLower(stream.GetSha1())
+		offset := d.proofOffset(file.GetSize())
+		if !seekable {
+			// keep the bytes read for the hash or proof code to upload them if the rapid upload fails,
+			// only the bytes before the proof code are needed if the sha1 is known
+			n := int64(-1)
+			var w io.Writer = io.Discard
+			h := sha1.New()
+			if contentHash != "" {
+				n = offset + 8
+			} else {
+				w = h
+			}
+			prefix, err := readPrefix(file, n, w)
+			if err != nil {
+				return err
+			}
+			defer prefix.Close()
+			if contentHash == "" {
+				contentHash = hex.EncodeToString(h.Sum(nil))
+			}
+			src = prefix
+			file.ReadCloser = struct {
+				io.Reader
+				io.Closer
+			}{
+				Reader: io.MultiReader(prefix, file),
+				Closer: file,
+			}
+		} else if contentHash == "" {
+			h := sha1.New()
+			if _, err = io.Copy(h, io.NewSectionReader(src, 0, file.GetSize())); err != nil {
+				return err
+			}
+			contentHash = hex.EncodeToString(h.Sum(nil))
 		}
-		reqBody["content_hash"] = hex.EncodeToString(h.Sum(nil))
+		reqBody["content_hash"] = contentHash
 		reqBody["content_hash_name"] = "sha1"
 		reqBody["proof_version"] = "v1"
-
-		/*
-			js 隐性转换太坑不知道有没有bug
-			var n = e.access_token，
-			r = new BigNumber('0x'.concat(md5(n).slice(0, 16)))，
-			i = new BigNumber(t.file.size)，
-			o = i ? r.mod(i) : new gt.BigNumber(0);
-			(t.file.slice(o.toNumber(), Math.min(o.plus(8).toNumber(), t.file.size)))
-		*/
 		buf := make([]byte, 8)
-		r, _ := new(big.Int).SetString(utils.GetMD5Encode(d.AccessToken)[:16], 16)
-		i := new(big.Int).SetInt64(file.GetSize())
-		o := r.Mod(r, i)
-		n, _ := io.NewSectionReader(tempFile, o.Int64(), 8).Read(buf[:8])
+		n, _ := src.ReadAt(buf, offset)
 		reqBody["proof_code"] = base64.StdEncoding.EncodeToString(buf[:n])
 
 		_, err, e := d.request("https://api.aliyundrive.com/adrive/v2/file/createWithFolders", http.MethodPost, func(req *resty.Request) {
@@ -247,26 +403,10 @@ func (d *AliDrive) Put(ctx context.Context, dstDir model.Obj, stream model.FileS
 		if resp.RapidUpload {
 			return nil
 		}
-		// 秒传失败
-		if _, err = tempFile.Seek(0, io.SeekStart); err != nil {
-			return err
-		}
-		file.ReadCloser = tempFile
 	}
 
-	for i, partInfo := range resp.PartInfoList {
-		req, err := http.NewRequest("PUT", partInfo.UploadUrl, io.LimitReader(file, DEFAULT))
-		if err != nil {
-			return err
-		}
-		res, err := base.HttpClient.Do(req)
-		if err != nil {
-			return err
-		}
-		res.Body.Close()
-		if count > 0 {
-			up(i * 100 / count)
-		}
+	if err = d.uploadParts(ctx, file, resp, DEFAULT, up); err != nil {
+		return err
 	}
 	var resp2 base.Json
 	_, err, e = d.request("https://api.aliyundrive.com/v2/file/complete", http.MethodPost, func(req *resty.Request) {
@@ -311,4 +451,111 @@ func (d *AliDrive) Other(ctx context.Context, args model.OtherArgs) (interface{}
 	return resp, nil
 }
 
+func (d *AliDrive) VideoPreview(ctx context.Context, file model.Obj) (*model.VideoPreview, error) {
+	var resp VideoPreviewPlayInfo
+	_, err, _ := d.request("https://api.aliyundrive.com/v2/file/get_video_preview_play_info", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"drive_id": d.DriveId,
+			"file_id":  file.GetID(),
+			"category": "live_transcoding",
+		})
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	preview := &model.VideoPreview{}
+	// the unfinished ones can't be played yet
+	for _, t := range resp.VideoPreviewPlayInfo.LiveTranscodingTaskList {
+		if t.Status == "finished" && t.Url != "" {
+			preview.Qualities = append(preview.Qualities, model.VideoQuality{
+				Name:   t.TemplateId,
+				Width:  t.TemplateWidth,
+				Height: t.TemplateHeight,
+				URL:    t.Url,
+			})
+		}
+	}
+	sort.SliceStable(preview.Qualities, func(i, j int) bool {
+		return preview.Qualities[i].Height < preview.Qualities[j].Height
+	})
+	for _, t := range resp.VideoPreviewPlayInfo.LiveTranscodingSubtitleTaskList {
+		if t.Status == "finished" && t.Url != "" {
+			preview.Subtitles = append(preview.Subtitles, model.VideoSubtitle{
+				Language: t.Language,
+				URL:      t.Url,
+			})
+		}
+	}
+	return preview, nil
+}
+
+// OfflineDownload the provider fetches the magnet or http url to dstDir by itself
+func (d *AliDrive) OfflineDownload(ctx context.Context, url string, dstDir model.Obj) (string, error) {
+	var resp OfflineTask
+	_, err, _ := d.request("https://api.aliyundrive.com/adrive/v1/offline_task/create", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"drive_id":       d.DriveId,
+			"parent_file_id": dstDir.GetID(),
+			"url":            url,
+		})
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	return resp.TaskId, nil
+}
+
+func (d *AliDrive) OfflineDownloadStatus(ctx context.Context, id string) (*model.OfflineDownloadStatus, error) {
+	var resp OfflineTask
+	_, err, _ := d.request("https://api.aliyundrive.com/adrive/v1/offline_task/get", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"drive_id": d.DriveId,
+			"task_id":  id,
+		})
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	status := &model.OfflineDownloadStatus{
+		Progress:  resp.Progress,
+		Status:    resp.Status,
+		Completed: resp.Status == "succeeded",
+	}
+	if resp.Status == "failed" {
+		status.Error = resp.ErrorMessage
+		if status.Error == "" {
+			status.Error = "the provider failed to download the url"
+		}
+	}
+	return status, nil
+}
+
+func (d *AliDrive) ListTrash(ctx context.Context) ([]model.Obj, error) {
+	files, err := d.getTrashFiles()
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(files, func(src File) (model.Obj, error) {
+		return fileToObj(src), nil
+	})
+}
+
+func (d *AliDrive) Restore(ctx context.Context, obj model.Obj) error {
+	return d.batch(obj.GetID(), "", "/recyclebin/restore")
+}
+
+func (d *AliDrive) Purge(ctx context.Context, obj model.Obj) error {
+	return d.batch(obj.GetID(), "", "/file/delete")
+}
+
 var _ driver.Driver = (*AliDrive)(nil)
+var _ driver.Searcher = (*AliDrive)(nil)
+var _ driver.Quota = (*AliDrive)(nil)
+var _ driver.BatchMove = (*AliDrive)(nil)
+var _ driver.BatchCopy = (*AliDrive)(nil)
+var _ driver.BatchRemove = (*AliDrive)(nil)
+var _ driver.Trash = (*AliDrive)(nil)
+var _ driver.CrossCopy = (*AliDrive)(nil)
+var _ driver.OfflineDownload = (*AliDrive)(nil)
+var _ driver.VideoPreviewer = (*AliDrive)(nil)
+var _ driver.ListPager = (*AliDrive)(nil)
@@ -7,15 +7,19 @@ import (
 
 type Addition struct {
 	driver.RootID
-	RefreshToken   string `json:"refresh_token" required:"true"`
+	RefreshToken   string `json:"refresh_token" required:"true" secret:"true"`
+	DriveType      string `json:"drive_type" type:"select" options:"default,resource,backup,album" default:"default"`
 	OrderBy        string `json:"order_by" type:"select" options:"name,size,updated_at,created_at"`
 	OrderDirection string `json:"order_direction" type:"select" options:"ASC,DESC"`
 	RapidUpload    bool   `json:"rapid_upload"`
+	UploadThread   int    `json:"upload_thread" type:"number" default:"4" help:"the count of parts uploaded concurrently"`
 }
 
 var config = driver.Config{
-	Name:        "Aliyundrive",
-	DefaultRoot: "root",
+	Name:                   "Aliyundrive",
+	DefaultRoot:            "root",
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
 }
 
 func New() driver.Driver {
@@ -1,6 +1,7 @@
 package aliyundrive
 
 import (
+	"strings"
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/model"
@@ -29,10 +30,11 @@ type File struct {
 	Size          int64      `json:"size"`
 	Thumbnail     string     `json:"thumbnail"`
 	Url           string     `json:"url"`
+	ContentHash   string     `json:"content_hash"`
 }
 
 func fileToObj(f File) *model.ObjThumb {
-	return &model.ObjThumb{
+	obj := &model.ObjThumb{
 		Object: model.Object{
 			ID:       f.FileId,
 			Name:     f.Name,
@@ -40,15 +42,57 @@ func fileToObj(f File) *model.ObjThumb {
 			Modified: f.UpdatedAt,
 			IsFolder: f.Type == "folder",
 		},
+		Thumbnail: model.Thumbnail{Thumbnail: f.Thumbnail},
 	}
+	if f.ContentHash != "" {
+		obj.Hash = model.HashInfo{Type: "sha1", Value: strings.ToLower(f.ContentHash)}
+	}
+	return obj
+}
+
+func getIds(objs []model.Obj) []string {
+	ids := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		ids = append(ids, obj.GetID())
+	}
+	return ids
+}
+
+type PartInfo struct {
+	PartNumber int    `json:"part_number"`
+	UploadUrl  string `json:"upload_url"`
 }
 
 type UploadResp struct {
-	FileId       string `json:"file_id"`
-	UploadId     string `json:"upload_id"`
-	PartInfoList []struct {
-		UploadUrl string `json:"upload_url"`
-	} `json:"part_info_list"`
+	FileId       string     `json:"file_id"`
+	UploadId     string     `json:"upload_id"`
+	PartInfoList []PartInfo `json:"part_info_list"`
 
 	RapidUpload bool `json:"rapid_upload"`
 }
+
+// OfflineTask is the offline download task of the provider,
+// Status is one of running, succeeded and failed
+type OfflineTask struct {
+	TaskId       string  `json:"task_id"`
+	Status       string  `json:"status"`
+	Progress     float64 `json:"progress"`
+	ErrorMessage string  `json:"error_message"`
+}
+
+type VideoPreviewPlayInfo struct {
+	VideoPreviewPlayInfo struct {
+		LiveTranscodingTaskList []struct {
+			TemplateId     string `json:"template_id"`
+			TemplateWidth  int    `json:"template_width"`
+			TemplateHeight int    `json:"template_height"`
+			Status         string `json:"status"`
+			Url            string `json:"url"`
+		} `json:"live_transcoding_task_list"`
+		LiveTranscodingSubtitleTaskList []struct {
+			Language string `json:"language"`
+			Status   string `json:"status"`
+			Url      string `json:"url"`
+		} `json:"live_transcoding_subtitle_task_list"`
+	} `json:"video_preview_play_info"`
+}
@@ -0,0 +1,218 @@
+package aliyundrive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// the max times of retrying a failed part
+const partRetry = 3
+
+// uploadParts upload the parts with UploadThread workers, the parts are read from
+// the stream sequentially and kept in memory until uploaded, so they can be retried
+func (d *AliDrive) uploadParts(ctx context.Context, r io.Reader, resp UploadResp, partSize int64, up driver.UpdateProgress) error {
+	threads := d.UploadThread
+	if threads < 1 {
+		threads = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type part struct {
+		info PartInfo
+		data []byte
+	}
+	parts := make(chan part)
+	errCh := make(chan error, threads)
+	var (
+		mu       sync.Mutex
+		uploaded int
+		wg       sync.WaitGroup
+	)
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range parts {
+				if err := d.uploadPart(ctx, resp, p.info, p.data); err != nil {
+					errCh <- err
+					cancel()
+					return
+				}
+				mu.Lock()
+				uploaded++
+				up(uploaded * 100 / len(resp.PartInfoList))
+				mu.Unlock()
+			}
+		}()
+	}
+	var err error
+loop:
+	for _, info := range resp.PartInfoList {
+		buf := make([]byte, partSize)
+		n, e := io.ReadFull(r, buf)
+		if e != nil && e != io.ErrUnexpectedEOF && e != io.EOF {
+			err = e
+			break
+		}
+		select {
+		case parts <- part{info: info, data: buf[:n]}:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break loop
+		}
+	}
+	close(parts)
+	wg.Wait()
+	// the error of the workers causes the ctx canceled, so it's preferred
+	select {
+	case e := <-errCh:
+		return e
+	default:
+		return err
+	}
+}
+
+// uploadPart upload the part with retrying, the upload url is refreshed if it's expired
+func (d *AliDrive) uploadPart(ctx context.Context, resp UploadResp, info PartInfo, data []byte) error {
+	url := info.UploadUrl
+	for i := 0; ; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		res, err := base.HttpClient.Do(req)
+		if err == nil {
+			_ = res.Body.Close()
+			switch res.StatusCode {
+			// the part may be uploaded by the last try
+			case http.StatusOK, http.StatusConflict:
+				return nil
+			case http.StatusForbidden:
+				if u, e := d.getUploadUrl(resp, info.PartNumber); e == nil {
+					url = u
+				}
+			}
+			err = fmt.Errorf("failed to upload part %d, status: %d", info.PartNumber, res.StatusCode)
+		}
+		if i >= partRetry || ctx.Err() != nil {
+			return err
+		}
+		log.Warnf("[aliyundrive] %+v, retry %d", err, i+1)
+		select {
+		case <-time.After(time.Second << i):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// getUploadUrl get a new upload url of the part, the urls are expired after 1 hour
+func (d *AliDrive) getUploadUrl(resp UploadResp, partNumber int) (string, error) {
+	var res UploadResp
+	_, err, _ := d.request("https://api.aliyundrive.com/v2/file/get_upload_url", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"drive_id":       d.DriveId,
+			"file_id":        resp.FileId,
+			"upload_id":      resp.UploadId,
+			"part_info_list": []base.Json{{"part_number": partNumber}},
+		})
+	}, &res)
+	if err != nil {
+		return "", err
+	}
+	if len(res.PartInfoList) == 0 {
+		return "", fmt.Errorf("no upload url of part %d", partNumber)
+	}
+	return res.PartInfoList[0].UploadUrl, nil
+}
+
+// source is the file that can be read at any offset, such as *os.File
+type source interface {
+	io.ReaderAt
+	io.Seeker
+}
+
+type spooled interface {
+	io.Reader
+	source
+	io.Closer
+}
+
+// the max size of the bytes spooled in memory
+const memSpoolLimit = 16 * 1024 * 1024
+
+type tempFile struct {
+	*os.File
+}
+
+func (f tempFile) Close() error {
+	_ = f.File.Close()
+	return os.Remove(f.File.Name())
+}
+
+// readPrefix read the first n bytes of r, or all if n < 0, to memory if it's small or a temp file,
+// the bytes are also written to w, such as a hash
+func readPrefix(r io.Reader, n int64, w io.Writer) (spooled, error) {
+	if n >= 0 && n <= memSpoolLimit {
+		buf := bytes.NewBuffer(make([]byte, 0, n))
+		if _, err := io.Copy(io.MultiWriter(buf, w), io.LimitReader(r, n)); err != nil {
+			return nil, err
+		}
+		return struct {
+			*bytes.Reader
+			io.Closer
+		}{
+			Reader: bytes.NewReader(buf.Bytes()),
+			Closer: io.NopCloser(nil),
+		}, nil
+	}
+	f, err := os.CreateTemp(conf.Conf.TempDir, "file-*")
+	if err != nil {
+		return nil, err
+	}
+	tf := tempFile{File: f}
+	if n >= 0 {
+		r = io.LimitReader(r, n)
+	}
+	if _, err = io.Copy(io.MultiWriter(f, w), r); err != nil {
+		_ = tf.Close()
+		return nil, err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		_ = tf.Close()
+		return nil, err
+	}
+	return tf, nil
+}
+
+// proofOffset get the offset of the 8 bytes proof code for the rapid upload
+func (d *AliDrive) proofOffset(size int64) int64 {
+	/*
+		js 隐性转换太坑不知道有没有bug
+		var n = e.access_token，
+		r = new BigNumber('0x'.concat(md5(n).slice(0, 16)))，
+		i = new BigNumber(t.file.size)，
+		o = i ? r.mod(i) : new gt.BigNumber(0);
+		(t.file.slice(o.toNumber(), Math.min(o.plus(8).toNumber(), t.file.size)))
+	*/
+	if size <= 0 {
+		return 0
+	}
+	r, _ := new(big.Int).SetString(utils.GetMD5Encode(d.AccessToken)[:16], 16)
+	i := new(big.Int).SetInt64(size)
+	return r.Mod(r, i).Int64()
+}
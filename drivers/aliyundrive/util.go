@@ -1,11 +1,16 @@
 package aliyundrive
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/go-resty/resty/v2"
@@ -14,23 +19,39 @@ import (
 // do others that not defined in Driver interface
 
 func (d *AliDrive) refreshToken() error {
+	acc := d.account
+	acc.mu.Lock()
+	// the token has been refreshed by the other storage of the account
+	if acc.accessToken != "" && acc.accessToken != d.AccessToken {
+		d.RefreshToken, d.AccessToken = acc.refreshToken, acc.accessToken
+		acc.mu.Unlock()
+		return nil
+	}
 	url := "https://auth.aliyundrive.com/v2/account/token"
 	var resp base.TokenResp
 	var e RespErr
 	_, err := base.RestyClient.R().
 		//ForceContentType("application/json").
-		SetBody(base.Json{"refresh_token": d.RefreshToken, "grant_type": "refresh_token"}).
+		SetBody(base.Json{"refresh_token": acc.refreshToken, "grant_type": "refresh_token"}).
 		SetResult(&resp).
 		SetError(&e).
 		Post(url)
 	if err != nil {
+		acc.mu.Unlock()
 		return err
 	}
 	if e.Code != "" {
-		return fmt.Errorf("failed to refresh token: %s", e.Message)
+		acc.mu.Unlock()
+		return fmt.Errorf("failed to refresh token: %s: %w", e.Message, errs.RefreshTokenRejected)
+	}
+	acc.refreshToken, acc.accessToken = resp.RefreshToken, resp.AccessToken
+	drivers := append([]*AliDrive(nil), acc.drivers...)
+	acc.mu.Unlock()
+	addAccountToken(resp.RefreshToken, acc)
+	for _, driver := range drivers {
+		driver.RefreshToken, driver.AccessToken = resp.RefreshToken, resp.AccessToken
+		op.MustSaveDriverStorage(driver)
 	}
-	d.RefreshToken, d.AccessToken = resp.RefreshToken, resp.AccessToken
-	op.MustSaveDriverStorage(d)
 	return nil
 }
 
@@ -67,6 +88,47 @@ func (d *AliDrive) request(url, method string, callback base.ReqCallback, resp i
 }
 
 func (d *AliDrive) getFiles(fileId string) ([]File, error) {
+	marker := "first"
+	res := make([]File, 0)
+	for marker != "" {
+		if marker == "first" {
+			marker = ""
+		}
+		files, next, err := d.getFilesPage(fileId, marker, 200)
+		if err != nil {
+			return nil, err
+		}
+		marker = next
+		res = append(res, files...)
+	}
+	return res, nil
+}
+
+func (d *AliDrive) getFilesPage(fileId, marker string, limit int) ([]File, string, error) {
+	var resp Files
+	data := base.Json{
+		"drive_id":                d.DriveId,
+		"fields":                  "*",
+		"image_thumbnail_process": "image/resize,w_400/format,jpeg",
+		"image_url_process":       "image/resize,w_1920/format,jpeg",
+		"limit":                   limit,
+		"marker":                  marker,
+		"order_by":                d.OrderBy,
+		"order_direction":         d.OrderDirection,
+		"parent_file_id":          fileId,
+		"video_thumbnail_process": "video/snapshot,t_0,f_jpg,ar_auto,w_300",
+		"url_expire_sec":          14400,
+	}
+	_, err, _ := d.request("https://api.aliyundrive.com/v2/file/list", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(data)
+	}, &resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp.Items, resp.NextMarker, nil
+}
+
+func (d *AliDrive) getTrashFiles() ([]File, error) {
 	marker := "first"
 	res := make([]File, 0)
 	for marker != "" {
@@ -74,23 +136,41 @@ func (d *AliDrive) getFiles(fileId string) ([]File, error) {
 			marker = ""
 		}
 		var resp Files
-		data := base.Json{
-			"drive_id":                d.DriveId,
-			"fields":                  "*",
-			"image_thumbnail_process": "image/resize,w_400/format,jpeg",
-			"image_url_process":       "image/resize,w_1920/format,jpeg",
-			"limit":                   200,
-			"marker":                  marker,
-			"order_by":                d.OrderBy,
-			"order_direction":         d.OrderDirection,
-			"parent_file_id":          fileId,
-			"video_thumbnail_process": "video/snapshot,t_0,f_jpg,ar_auto,w_300",
-			"url_expire_sec":          14400,
-		}
-		_, err, _ := d.request("https://api.aliyundrive.com/v2/file/list", http.MethodPost, func(req *resty.Request) {
-			req.SetBody(data)
+		_, err, _ := d.request("https://api.aliyundrive.com/v2/recyclebin/list", http.MethodPost, func(req *resty.Request) {
+			req.SetBody(base.Json{
+				"drive_id":        d.DriveId,
+				"limit":           100,
+				"marker":          marker,
+				"order_by":        "name",
+				"order_direction": "DESC",
+			})
 		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		marker = resp.NextMarker
+		res = append(res, resp.Items...)
+	}
+	return res, nil
+}
 
+func (d *AliDrive) searchFiles(keyword string) ([]File, error) {
+	marker := "first"
+	res := make([]File, 0)
+	for marker != "" {
+		if marker == "first" {
+			marker = ""
+		}
+		var resp Files
+		_, err, _ := d.request("https://api.aliyundrive.com/adrive/v3/file/search", http.MethodPost, func(req *resty.Request) {
+			req.SetBody(base.Json{
+				"drive_id": d.DriveId,
+				"limit":    100,
+				"marker":   marker,
+				"order_by": "updated_at DESC",
+				"query":    fmt.Sprintf(`name match "%s"`, strings.ReplaceAll(keyword, `"`, `\"`)),
+			})
+		}, &resp)
 		if err != nil {
 			return nil, err
 		}
@@ -100,34 +180,220 @@ func (d *AliDrive) getFiles(fileId string) ([]File, error) {
 	return res, nil
 }
 
-func (d *AliDrive) batch(srcId, dstId string, url string) error {
-	res, err, _ := d.request("https://api.aliyundrive.com/v3/batch", http.MethodPost, func(req *resty.Request) {
+// getPath get the path of the folder relative to the root folder,
+// return false if the folder is not in the root folder
+func (d *AliDrive) getPath(fileId string) (string, bool, error) {
+	if fileId == d.RootFolderID {
+		return "/", true, nil
+	}
+	var resp Files
+	_, err, _ := d.request("https://api.aliyundrive.com/adrive/v1/file/get_path", http.MethodPost, func(req *resty.Request) {
 		req.SetBody(base.Json{
-			"requests": []base.Json{
-				{
-					"headers": base.Json{
-						"Content-Type": "application/json",
-					},
-					"method": "POST",
-					"id":     srcId,
-					"body": base.Json{
-						"drive_id":          d.DriveId,
-						"file_id":           srcId,
-						"to_drive_id":       d.DriveId,
-						"to_parent_file_id": dstId,
-					},
-					"url": url,
+			"drive_id": d.DriveId,
+			"file_id":  fileId,
+		})
+	}, &resp)
+	if err != nil {
+		return "", false, err
+	}
+	// items are ordered from the folder itself to the top folder
+	names := make([]string, 0, len(resp.Items))
+	for _, item := range resp.Items {
+		if item.FileId == d.RootFolderID {
+			return "/" + strings.Join(reverse(names), "/"), true, nil
+		}
+		names = append(names, item.Name)
+	}
+	if d.RootFolderID == "root" {
+		return "/" + strings.Join(reverse(names), "/"), true, nil
+	}
+	return "", false, nil
+}
+
+func reverse(s []string) []string {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+	return s
+}
+
+// the max count of requests in one batch
+const batchLimit = 100
+
+func (d *AliDrive) batch(srcId, dstId string, url string) error {
+	return d.batchFiles([]string{srcId}, dstId, url)
+}
+
+// batchFiles do the same operation on the files with id in srcIds,
+// dstId is ignored if the operation doesn't need a target, such as trash
+func (d *AliDrive) batchFiles(srcIds []string, dstId string, url string) error {
+	return d.batchFilesTo(srcIds, d.DriveId, dstId, url)
+}
+
+// batchFilesTo is like batchFiles, but the target folder can be in another drive of the account
+func (d *AliDrive) batchFilesTo(srcIds []string, toDriveId, dstId string, url string) error {
+	for start := 0; start < len(srcIds); start += batchLimit {
+		end := start + batchLimit
+		if end > len(srcIds) {
+			end = len(srcIds)
+		}
+		requests := make([]base.Json, 0, end-start)
+		for _, srcId := range srcIds[start:end] {
+			body := base.Json{
+				"drive_id": d.DriveId,
+				"file_id":  srcId,
+			}
+			if dstId != "" {
+				body["to_drive_id"] = toDriveId
+				body["to_parent_file_id"] = dstId
+			}
+			requests = append(requests, base.Json{
+				"headers": base.Json{
+					"Content-Type": "application/json",
+				},
+				"method": "POST",
+				"id":     srcId,
+				"body":   body,
+				"url":    url,
+			})
+		}
+		res, err, _ := d.request("https://api.aliyundrive.com/v3/batch", http.MethodPost, func(req *resty.Request) {
+			req.SetBody(base.Json{
+				"requests": requests,
+				"resource": "file",
+			})
+		}, nil)
+		if err != nil {
+			return err
+		}
+		for i := range requests {
+			status := utils.Json.Get(res, "responses", i, "status").ToInt()
+			if status >= 400 || status < 100 {
+				return errors.New(string(res))
+			}
+		}
+	}
+	return nil
+}
+
+// SaveShareFiles save the files with id in fileIds of the share to the folder with id dstId
+func (d *AliDrive) SaveShareFiles(shareId, shareToken string, fileIds []string, dstId string) error {
+	for start := 0; start < len(fileIds); start += batchLimit {
+		end := start + batchLimit
+		if end > len(fileIds) {
+			end = len(fileIds)
+		}
+		requests := make([]base.Json, 0, end-start)
+		for _, fileId := range fileIds[start:end] {
+			requests = append(requests, base.Json{
+				"headers": base.Json{
+					"Content-Type": "application/json",
+				},
+				"method": "POST",
+				"id":     fileId,
+				"body": base.Json{
+					"share_id":          shareId,
+					"file_id":           fileId,
+					"to_drive_id":       d.DriveId,
+					"to_parent_file_id": dstId,
+					"auto_rename":       true,
 				},
-			},
-			"resource": "file",
+				"url": "/file/copy",
+			})
+		}
+		res, err, _ := d.request("https://api.aliyundrive.com/adrive/v2/batch", http.MethodPost, func(req *resty.Request) {
+			req.SetHeader("X-Share-Token", shareToken)
+			req.SetBody(base.Json{
+				"requests": requests,
+				"resource": "file",
+			})
+		}, nil)
+		if err != nil {
+			return err
+		}
+		for i := range requests {
+			status := utils.Json.Get(res, "responses", i, "status").ToInt()
+			if status >= 400 || status < 100 {
+				return errors.New(string(res))
+			}
+		}
+	}
+	return nil
+}
+
+// getDriveId get the id of the drive selected by DriveType
+func (d *AliDrive) getDriveId() (string, error) {
+	if d.DriveType == "album" {
+		res, err, _ := d.request("https://api.aliyundrive.com/adrive/v1/user/albums_info", http.MethodPost, nil, nil)
+		if err != nil {
+			return "", err
+		}
+		id := utils.Json.Get(res, "data", "driveId").ToString()
+		if id == "" {
+			return "", errors.New("the account has no album drive")
+		}
+		return id, nil
+	}
+	res, err, _ := d.request("https://api.aliyundrive.com/v2/user/get", http.MethodPost, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	key := "default_drive_id"
+	switch d.DriveType {
+	case "resource":
+		key = "resource_drive_id"
+	case "backup":
+		key = "backup_drive_id"
+	}
+	id := utils.Json.Get(res, key).ToString()
+	if id == "" {
+		return "", fmt.Errorf("the account has no %s drive", d.DriveType)
+	}
+	return id, nil
+}
+
+func (d *AliDrive) getFile(fileId string) (*File, error) {
+	var file File
+	_, err, _ := d.request("https://api.aliyundrive.com/v2/file/get", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"drive_id": d.DriveId,
+			"file_id":  fileId,
 		})
-	}, nil)
+	}, &file)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	status := utils.Json.Get(res, "responses", 0, "status").ToInt()
-	if status < 400 && status >= 100 {
-		return nil
+	return &file, nil
+}
+
+// readRange read `length` bytes from `offset` of the file with a range request of the link
+func readRange(ctx context.Context, link *model.Link, offset, length int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for h, val := range link.Header {
+		req.Header[h] = val
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusPartialContent && res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read range of the file, status: %s", res.Status)
+	}
+	if res.StatusCode == http.StatusOK {
+		// the range is ignored by the server
+		if _, err = io.CopyN(io.Discard, res.Body, offset); err != nil {
+			return nil, err
+		}
+	}
+	buf := make([]byte, length)
+	n, err := io.ReadFull(res.Body, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
 	}
-	return errors.New(string(res))
+	return buf[:n], nil
 }
@@ -0,0 +1,240 @@
+package aliyundrive_open
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+type AliyundriveOpen struct {
+	model.Storage
+	Addition
+	base        string
+	AccessToken string
+	DriveId     string
+	cron        *cron.Cron
+}
+
+func (d *AliyundriveOpen) Config() driver.Config {
+	return config
+}
+
+func (d *AliyundriveOpen) GetAddition() driver.Additional {
+	return d.Addition
+}
+
+func (d *AliyundriveOpen) Init(ctx context.Context, storage model.Storage) error {
+	d.Storage = storage
+	err := utils.Json.UnmarshalFromString(d.Storage.Addition, &d.Addition)
+	if err != nil {
+		return err
+	}
+	d.base = "https://openapi.aliyundrive.com"
+	err = d.refreshToken()
+	if err != nil {
+		return err
+	}
+	res, err := d.request(ctx, "/adrive/v1.0/user/getDriveInfo", http.MethodPost, nil)
+	if err != nil {
+		return err
+	}
+	d.DriveId = utils.Json.Get(res, "default_drive_id").ToString()
+	// the access token expires in 2 hours
+	d.cron = cron.NewCron(time.Hour)
+	d.cron.Do(func() {
+		err := d.refreshToken()
+		if err != nil {
+			log.Errorf("%+v", err)
+		}
+		op.ReportTokenRefresh(d, err)
+	})
+	return nil
+}
+
+func (d *AliyundriveOpen) Drop(ctx context.Context) error {
+	if d.cron != nil {
+		d.cron.Stop()
+	}
+	return nil
+}
+
+func (d *AliyundriveOpen) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	files, err := d.getFiles(ctx, dir.GetID())
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(files, func(src File) (model.Obj, error) {
+		return fileToObj(src), nil
+	})
+}
+
+func (d *AliyundriveOpen) ListPage(ctx context.Context, dir model.Obj, marker string, limit int) ([]model.Obj, string, error) {
+	// the max limit of the api
+	if limit > 200 {
+		limit = 200
+	}
+	files, next, err := d.getFilesPage(ctx, dir.GetID(), marker, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	objs, err := utils.SliceConvert(files, func(src File) (model.Obj, error) {
+		return fileToObj(src), nil
+	})
+	return objs, next, err
+}
+
+func (d *AliyundriveOpen) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	res, err := d.request(ctx, "/adrive/v1.0/openFile/getDownloadUrl", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"drive_id":   d.DriveId,
+			"file_id":    file.GetID(),
+			"expire_sec": 14400,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	exp := 14400 * time.Second
+	return &model.Link{
+		URL:        utils.Json.Get(res, "url").ToString(),
+		Expiration: &exp,
+	}, nil
+}
+
+func (d *AliyundriveOpen) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	_, err := d.request(ctx, "/adrive/v1.0/openFile/create", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"drive_id":        d.DriveId,
+			"parent_file_id":  parentDir.GetID(),
+			"name":            dirName,
+			"type":            "folder",
+			"check_name_mode": "refuse",
+		})
+	})
+	return err
+}
+
+func (d *AliyundriveOpen) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	_, err := d.request(ctx, "/adrive/v1.0/openFile/move", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"drive_id":          d.DriveId,
+			"file_id":           srcObj.GetID(),
+			"to_parent_file_id": dstDir.GetID(),
+			"check_name_mode":   "refuse",
+		})
+	})
+	return err
+}
+
+func (d *AliyundriveOpen) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	_, err := d.request(ctx, "/adrive/v1.0/openFile/update", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"drive_id": d.DriveId,
+			"file_id":  srcObj.GetID(),
+			"name":     newName,
+		})
+	})
+	return err
+}
+
+func (d *AliyundriveOpen) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	_, err := d.request(ctx, "/adrive/v1.0/openFile/copy", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"drive_id":          d.DriveId,
+			"file_id":           srcObj.GetID(),
+			"to_parent_file_id": dstDir.GetID(),
+			"auto_rename":       true,
+		})
+	})
+	return err
+}
+
+func (d *AliyundriveOpen) Remove(ctx context.Context, obj model.Obj) error {
+	_, err := d.request(ctx, "/adrive/v1.0/openFile/recyclebin/trash", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"drive_id": d.DriveId,
+			"file_id":  obj.GetID(),
+		})
+	})
+	return err
+}
+
+func (d *AliyundriveOpen) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	const DEFAULT int64 = 10485760
+	count := int(math.Ceil(float64(stream.GetSize()) / float64(DEFAULT)))
+	partInfoList := make([]base.Json, 0, count)
+	for i := 1; i <= count; i++ {
+		partInfoList = append(partInfoList, base.Json{"part_number": i})
+	}
+	var resp CreateResp
+	_, err := d.request(ctx, "/adrive/v1.0/openFile/create", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"drive_id":        d.DriveId,
+			"parent_file_id":  dstDir.GetID(),
+			"name":            stream.GetName(),
+			"type":            "file",
+			"check_name_mode": "ignore",
+			"size":            stream.GetSize(),
+			"part_info_list":  partInfoList,
+		}).SetResult(&resp)
+	})
+	if err != nil {
+		return err
+	}
+	for i, partInfo := range resp.PartInfoList {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, partInfo.UploadUrl, io.LimitReader(stream, DEFAULT))
+		if err != nil {
+			return err
+		}
+		res, err := base.HttpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		_ = res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return fmt.Errorf("failed to upload part %d, status: %d", partInfo.PartNumber, res.StatusCode)
+		}
+		up((i + 1) * 100 / len(resp.PartInfoList))
+	}
+	_, err = d.request(ctx, "/adrive/v1.0/openFile/complete", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"drive_id":  d.DriveId,
+			"file_id":   resp.FileId,
+			"upload_id": resp.UploadId,
+		})
+	})
+	return err
+}
+
+func (d *AliyundriveOpen) GetQuota(ctx context.Context) (*model.StorageQuota, error) {
+	res, err := d.request(ctx, "/adrive/v1.0/user/getSpaceInfo", http.MethodPost, nil)
+	if err != nil {
+		return nil, err
+	}
+	total := utils.Json.Get(res, "personal_space_info", "total_size").ToInt64()
+	used := utils.Json.Get(res, "personal_space_info", "used_size").ToInt64()
+	return &model.StorageQuota{
+		Total: total,
+		Used:  used,
+		Free:  total - used,
+	}, nil
+}
+
+var _ driver.Driver = (*AliyundriveOpen)(nil)
+var _ driver.Quota = (*AliyundriveOpen)(nil)
+var _ driver.ListPager = (*AliyundriveOpen)(nil)
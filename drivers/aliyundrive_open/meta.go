@@ -0,0 +1,28 @@
+package aliyundrive_open
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	driver.RootID
+	RefreshToken   string `json:"refresh_token" required:"true" secret:"true"`
+	OrderBy        string `json:"order_by" type:"select" options:"name,size,updated_at,created_at" default:"name"`
+	OrderDirection string `json:"order_direction" type:"select" options:"ASC,DESC" default:"ASC"`
+	ClientID       string `json:"client_id" required:"true" help:"the app id of the open platform"`
+	ClientSecret   string `json:"client_secret" required:"true" secret:"true"`
+}
+
+var config = driver.Config{
+	Name:                   "AliyundriveOpen",
+	DefaultRoot:            "root",
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
+}
+
+func init() {
+	op.RegisterDriver(config, func() driver.Driver {
+		return &AliyundriveOpen{}
+	})
+}
@@ -0,0 +1,138 @@
+package aliyundrive_open
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// do others that not defined in Driver interface
+
+const (
+	// the max times of retrying when the requests are limited
+	maxRetry = 5
+	// the wait time before retrying if the response has no Retry-After header
+	retryInterval = time.Second
+	// the max wait time before retrying, the Retry-After header longer than it is ignored
+	maxRetryWait = 30 * time.Second
+)
+
+func (d *AliyundriveOpen) refreshToken() error {
+	var resp base.TokenResp
+	var e ErrResp
+	res, err := base.RestyClient.R().
+		SetBody(base.Json{
+			"client_id":     d.ClientID,
+			"client_secret": d.ClientSecret,
+			"grant_type":    "refresh_token",
+			"refresh_token": d.RefreshToken,
+		}).
+		SetResult(&resp).
+		SetError(&e).
+		Post(d.base + "/oauth/access_token")
+	if err != nil {
+		return err
+	}
+	if e.Code != "" {
+		return errors.Wrapf(errs.RefreshTokenRejected, "failed to refresh token: %s", e.Message)
+	}
+	if resp.RefreshToken == "" {
+		return errors.Errorf("failed to refresh token: refresh token is empty, %s", res.String())
+	}
+	d.RefreshToken, d.AccessToken = resp.RefreshToken, resp.AccessToken
+	op.MustSaveDriverStorage(d)
+	return nil
+}
+
+func (d *AliyundriveOpen) request(ctx context.Context, uri, method string, callback base.ReqCallback, retry ...bool) ([]byte, error) {
+	for i := 0; ; i++ {
+		req := base.RestyClient.R().SetContext(ctx)
+		req.SetHeader("Authorization", "Bearer "+d.AccessToken)
+		if method == http.MethodPost {
+			req.SetHeader("Content-Type", "application/json")
+		}
+		if callback != nil {
+			callback(req)
+		} else {
+			req.SetBody("{}")
+		}
+		var e ErrResp
+		req.SetError(&e)
+		res, err := req.Execute(method, d.base+uri)
+		if err != nil {
+			return nil, err
+		}
+		// the requests are limited by the qps of the app
+		if res.StatusCode() == http.StatusTooManyRequests && i < maxRetry {
+			wait := retryInterval << i
+			if after, err := strconv.Atoi(res.Header().Get("Retry-After")); err == nil && after > 0 {
+				wait = time.Duration(after) * time.Second
+			}
+			if wait > maxRetryWait {
+				wait = maxRetryWait
+			}
+			log.Debugf("[aliyundrive_open] request %s is limited, retry after %s", uri, wait)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		if e.Code != "" {
+			if (e.Code == "AccessTokenInvalid" || e.Code == "AccessTokenExpired") && len(retry) == 0 {
+				if err := d.refreshToken(); err != nil {
+					return nil, err
+				}
+				return d.request(ctx, uri, method, callback, true)
+			}
+			return nil, fmt.Errorf("%s: %s", e.Code, e.Message)
+		}
+		return res.Body(), nil
+	}
+}
+
+func (d *AliyundriveOpen) getFiles(ctx context.Context, fileId string) ([]File, error) {
+	marker := "first"
+	res := make([]File, 0)
+	for marker != "" {
+		if marker == "first" {
+			marker = ""
+		}
+		files, next, err := d.getFilesPage(ctx, fileId, marker, 200)
+		if err != nil {
+			return nil, err
+		}
+		marker = next
+		res = append(res, files...)
+	}
+	return res, nil
+}
+
+func (d *AliyundriveOpen) getFilesPage(ctx context.Context, fileId, marker string, limit int) ([]File, string, error) {
+	var resp Files
+	data := base.Json{
+		"drive_id":        d.DriveId,
+		"limit":           limit,
+		"marker":          marker,
+		"order_by":        d.OrderBy,
+		"order_direction": d.OrderDirection,
+		"parent_file_id":  fileId,
+	}
+	_, err := d.request(ctx, "/adrive/v1.0/openFile/list", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(data).SetResult(&resp)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return resp.Items, resp.NextMarker, nil
+}
@@ -0,0 +1,142 @@
+package aliyundrive_share
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/aliyundrive"
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// AliyundriveShare mounts a share of aliyundrive read only, the files can be saved
+// to an Aliyundrive storage by copying them without transferring the content
+type AliyundriveShare struct {
+	model.Storage
+	Addition
+	AccessToken string
+	ShareToken  string
+	cron        *cron.Cron
+}
+
+func (d *AliyundriveShare) Config() driver.Config {
+	return config
+}
+
+func (d *AliyundriveShare) GetAddition() driver.Additional {
+	return d.Addition
+}
+
+func (d *AliyundriveShare) Init(ctx context.Context, storage model.Storage) error {
+	d.Storage = storage
+	err := utils.Json.UnmarshalFromString(d.Storage.Addition, &d.Addition)
+	if err != nil {
+		return err
+	}
+	err = d.refreshToken()
+	if err != nil {
+		return err
+	}
+	err = d.getShareToken()
+	if err != nil {
+		return err
+	}
+	d.cron = cron.NewCron(time.Hour * 2)
+	d.cron.Do(func() {
+		err := d.refreshToken()
+		if err != nil {
+			log.Errorf("%+v", err)
+		}
+		err = d.getShareToken()
+		if err != nil {
+			log.Errorf("%+v", err)
+		}
+	})
+	return nil
+}
+
+func (d *AliyundriveShare) Drop(ctx context.Context) error {
+	if d.cron != nil {
+		d.cron.Stop()
+	}
+	return nil
+}
+
+func (d *AliyundriveShare) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	files, err := d.getFiles(dir.GetID())
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(files, func(src File) (model.Obj, error) {
+		return fileToObj(src), nil
+	})
+}
+
+func (d *AliyundriveShare) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	data := base.Json{
+		"share_id":   d.ShareId,
+		"file_id":    file.GetID(),
+		"expire_sec": 600,
+	}
+	res, err := d.request("https://api.aliyundrive.com/v2/file/get_share_link_download_url", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(data)
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	exp := 10 * time.Minute
+	return &model.Link{
+		Header: http.Header{
+			"Referer": []string{"https://www.aliyundrive.com/"},
+		},
+		URL:        utils.Json.Get(res, "download_url").ToString(),
+		Expiration: &exp,
+	}, nil
+}
+
+func (d *AliyundriveShare) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	return errs.NotSupport
+}
+
+func (d *AliyundriveShare) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return errs.NotSupport
+}
+
+func (d *AliyundriveShare) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	return errs.NotSupport
+}
+
+func (d *AliyundriveShare) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return errs.NotSupport
+}
+
+func (d *AliyundriveShare) Remove(ctx context.Context, obj model.Obj) error {
+	return errs.NotSupport
+}
+
+func (d *AliyundriveShare) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	return errs.NotSupport
+}
+
+// CrossCopy save the object in the share to the drive of an Aliyundrive storage
+func (d *AliyundriveShare) CrossCopy(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	dstDrive, ok := dst.(*aliyundrive.AliDrive)
+	if !ok {
+		return errs.NotSupport
+	}
+	// the saving isn't retried by the request, so make sure the share token is valid
+	if err := d.getShareToken(); err != nil {
+		return err
+	}
+	return dstDrive.SaveShareFiles(d.ShareId, d.ShareToken, []string{srcObj.GetID()}, dstDir.GetID())
+}
+
+var _ driver.Driver = (*AliyundriveShare)(nil)
+var _ driver.CrossCopy = (*AliyundriveShare)(nil)
@@ -0,0 +1,28 @@
+package aliyundrive_share
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	driver.RootID
+	RefreshToken   string `json:"refresh_token" required:"true" help:"the account used to get the download links, use one not used by other storages" secret:"true"`
+	ShareId        string `json:"share_id" required:"true"`
+	SharePwd       string `json:"share_pwd" secret:"true"`
+	OrderBy        string `json:"order_by" type:"select" options:"name,size,updated_at,created_at"`
+	OrderDirection string `json:"order_direction" type:"select" options:"ASC,DESC"`
+}
+
+var config = driver.Config{
+	Name:              "AliyundriveShare",
+	NoUpload:          true,
+	DefaultRoot:       "root",
+	SupportsRangeRead: true,
+}
+
+func init() {
+	op.RegisterDriver(config, func() driver.Driver {
+		return &AliyundriveShare{}
+	})
+}
@@ -0,0 +1,47 @@
+package aliyundrive_share
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+type RespErr struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ShareTokenResp struct {
+	ShareToken string `json:"share_token"`
+	ExpiresIn  int64  `json:"expires_in"`
+}
+
+type ListResp struct {
+	Items      []File `json:"items"`
+	NextMarker string `json:"next_marker"`
+}
+
+type File struct {
+	DriveId      string    `json:"drive_id"`
+	ShareId      string    `json:"share_id"`
+	FileId       string    `json:"file_id"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	ParentFileId string    `json:"parent_file_id"`
+	Size         int64     `json:"size"`
+	Thumbnail    string    `json:"thumbnail"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func fileToObj(f File) *model.ObjThumb {
+	return &model.ObjThumb{
+		Object: model.Object{
+			ID:       f.FileId,
+			Name:     f.Name,
+			Size:     f.Size,
+			Modified: f.UpdatedAt,
+			IsFolder: f.Type == "folder",
+		},
+		Thumbnail: model.Thumbnail{Thumbnail: f.Thumbnail},
+	}
+}
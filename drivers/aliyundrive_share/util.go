@@ -0,0 +1,126 @@
+package aliyundrive_share
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/go-resty/resty/v2"
+)
+
+// do others that not defined in Driver interface
+
+func (d *AliyundriveShare) refreshToken() error {
+	url := "https://auth.aliyundrive.com/v2/account/token"
+	var resp base.TokenResp
+	var e RespErr
+	_, err := base.RestyClient.R().
+		SetBody(base.Json{"refresh_token": d.RefreshToken, "grant_type": "refresh_token"}).
+		SetResult(&resp).
+		SetError(&e).
+		Post(url)
+	if err != nil {
+		return err
+	}
+	if e.Code != "" {
+		return fmt.Errorf("failed to refresh token: %s", e.Message)
+	}
+	d.RefreshToken, d.AccessToken = resp.RefreshToken, resp.AccessToken
+	op.MustSaveDriverStorage(d)
+	return nil
+}
+
+// getShareToken get the token to access the share, it expires in 2 hours
+func (d *AliyundriveShare) getShareToken() error {
+	var resp ShareTokenResp
+	var e RespErr
+	_, err := base.RestyClient.R().
+		SetBody(base.Json{"share_id": d.ShareId, "share_pwd": d.SharePwd}).
+		SetResult(&resp).
+		SetError(&e).
+		Post("https://api.aliyundrive.com/v2/share_link/get_share_token")
+	if err != nil {
+		return err
+	}
+	if e.Code != "" {
+		return fmt.Errorf("failed to get share token: %s", e.Message)
+	}
+	d.ShareToken = resp.ShareToken
+	return nil
+}
+
+func (d *AliyundriveShare) request(url, method string, callback base.ReqCallback, resp interface{}) ([]byte, error) {
+	return d.requestRetry(url, method, callback, resp, true)
+}
+
+// requestRetry do the request, the expired access token or share token is refreshed
+// and the request is retried once if `retry` is true
+func (d *AliyundriveShare) requestRetry(url, method string, callback base.ReqCallback, resp interface{}, retry bool) ([]byte, error) {
+	req := base.RestyClient.R()
+	req.SetHeader("Authorization", "Bearer\t"+d.AccessToken)
+	req.SetHeader("X-Share-Token", d.ShareToken)
+	req.SetHeader("content-type", "application/json")
+	req.SetHeader("origin", "https://www.aliyundrive.com")
+	if callback != nil {
+		callback(req)
+	} else {
+		req.SetBody("{}")
+	}
+	if resp != nil {
+		req.SetResult(resp)
+	}
+	var e RespErr
+	req.SetError(&e)
+	res, err := req.Execute(method, url)
+	if err != nil {
+		return nil, err
+	}
+	if e.Code != "" {
+		if retry && (e.Code == "AccessTokenInvalid" || e.Code == "ShareLinkTokenInvalid") {
+			if e.Code == "AccessTokenInvalid" {
+				err = d.refreshToken()
+			} else {
+				err = d.getShareToken()
+			}
+			if err != nil {
+				return nil, err
+			}
+			return d.requestRetry(url, method, callback, resp, false)
+		}
+		return nil, errors.New(e.Message)
+	}
+	return res.Body(), nil
+}
+
+func (d *AliyundriveShare) getFiles(fileId string) ([]File, error) {
+	marker := "first"
+	res := make([]File, 0)
+	for marker != "" {
+		if marker == "first" {
+			marker = ""
+		}
+		var resp ListResp
+		data := base.Json{
+			"share_id":                d.ShareId,
+			"parent_file_id":          fileId,
+			"limit":                   200,
+			"marker":                  marker,
+			"order_by":                d.OrderBy,
+			"order_direction":         d.OrderDirection,
+			"image_thumbnail_process": "image/resize,w_400/format,jpeg",
+			"image_url_process":       "image/resize,w_1920/format,jpeg",
+			"video_thumbnail_process": "video/snapshot,t_0,f_jpg,ar_auto,w_300",
+		}
+		_, err := d.request("https://api.aliyundrive.com/adrive/v3/file/list", http.MethodPost, func(req *resty.Request) {
+			req.SetBody(data)
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		marker = resp.NextMarker
+		res = append(res, resp.Items...)
+	}
+	return res, nil
+}
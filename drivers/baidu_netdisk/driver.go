@@ -1,17 +1,12 @@
 package baidu_netdisk
 
 import (
-	"bytes"
 	"context"
-	"crypto/md5"
-	"encoding/hex"
 	"fmt"
 	"io"
-	"math"
 	"os"
 	stdpath "path"
 	"strconv"
-	"strings"
 
 	"github.com/alist-org/alist/v3/drivers/base"
 	"github.com/alist-org/alist/v3/internal/driver"
@@ -24,6 +19,8 @@ type BaiduNetdisk struct {
 	model.Storage
 	Addition
 	AccessToken string
+	// the vip type of the user, it decides the size of the blocks of the uploads
+	vipType int
 }
 
 func (d *BaiduNetdisk) Config() driver.Config {
@@ -40,7 +37,16 @@ func (d *BaiduNetdisk) Init(ctx context.Context, storage model.Storage) error {
 	if err != nil {
 		return err
 	}
-	return d.refreshToken()
+	if d.RefreshToken == "" {
+		err = d.deviceLogin()
+	} else {
+		err = d.refreshToken()
+	}
+	if err != nil {
+		return err
+	}
+	d.vipType, err = d.getVipType()
+	return err
 }
 
 func (d *BaiduNetdisk) Drop(ctx context.Context) error {
@@ -63,10 +69,7 @@ func (d *BaiduNetdisk) List(ctx context.Context, dir model.Obj, args model.ListA
 //}
 
 func (d *BaiduNetdisk) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
-	if d.DownloadAPI == "crack" {
-		return d.linkCrack(file, args)
-	}
-	return d.linkOfficial(file, args)
+	return d.link(file)
 }
 
 func (d *BaiduNetdisk) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
@@ -98,12 +101,11 @@ func (d *BaiduNetdisk) Rename(ctx context.Context, srcObj model.Obj, newName str
 }
 
 func (d *BaiduNetdisk) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
-	dest, newname := stdpath.Split(dstDir.GetPath())
 	data := []base.Json{
 		{
 			"path":    srcObj.GetPath(),
-			"dest":    dest,
-			"newname": newname,
+			"dest":    dstDir.GetPath(),
+			"newname": srcObj.GetName(),
 		},
 	}
 	_, err := d.manage("copy", data)
@@ -117,116 +119,75 @@ func (d *BaiduNetdisk) Remove(ctx context.Context, obj model.Obj) error {
 }
 
 func (d *BaiduNetdisk) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
-	tempFile, err := utils.CreateTempFile(stream.GetReadCloser())
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = tempFile.Close()
-		_ = os.Remove(tempFile.Name())
-	}()
-	var Default int64 = 4 * 1024 * 1024
-	defaultByteData := make([]byte, Default)
-	count := int(math.Ceil(float64(stream.GetSize()) / float64(Default)))
-	var SliceSize int64 = 256 * 1024
-	// cal md5
-	h1 := md5.New()
-	h2 := md5.New()
-	block_list := make([]string, 0)
-	content_md5 := ""
-	slice_md5 := ""
-	left := stream.GetSize()
-	for i := 0; i < count; i++ {
-		byteSize := Default
-		var byteData []byte
-		if left < Default {
-			byteSize = left
-			byteData = make([]byte, byteSize)
-		} else {
-			byteData = defaultByteData
-		}
-		left -= byteSize
-		_, err = io.ReadFull(tempFile, byteData)
+	// the stream is spooled to a temp file as the driver needs a seekable one
+	src, ok := stream.GetReadCloser().(io.ReaderAt)
+	if !ok {
+		tempFile, err := utils.CreateTempFile(stream.GetReadCloser())
 		if err != nil {
 			return err
 		}
-		h1.Write(byteData)
-		h2.Write(byteData)
-		block_list = append(block_list, fmt.Sprintf("\"%s\"", hex.EncodeToString(h2.Sum(nil))))
-		h2.Reset()
+		defer func() {
+			_ = tempFile.Close()
+			_ = os.Remove(tempFile.Name())
+		}()
+		src = tempFile
+	}
+	size := stream.GetSize()
+	blockSize := blockSizes[d.vipType]
+	if blockSize == 0 {
+		blockSize = blockSizes[0]
 	}
-	content_md5 = hex.EncodeToString(h1.Sum(nil))
-	_, err = tempFile.Seek(0, io.SeekStart)
+	contentMd5, sliceMd5, blockList, err := hashBlocks(src, size, blockSize)
 	if err != nil {
 		return err
 	}
-	if stream.GetSize() <= SliceSize {
-		slice_md5 = content_md5
-	} else {
-		sliceData := make([]byte, SliceSize)
-		_, err = io.ReadFull(tempFile, sliceData)
-		if err != nil {
-			return err
-		}
-		h2.Write(sliceData)
-		slice_md5 = hex.EncodeToString(h2.Sum(nil))
-		_, err = tempFile.Seek(0, io.SeekStart)
-		if err != nil {
-			return err
-		}
-	}
-	path := encodeURIComponent(stdpath.Join(dstDir.GetPath(), stream.GetName()))
-	block_list_str := fmt.Sprintf("[%s]", strings.Join(block_list, ","))
-	data := fmt.Sprintf("path=%s&size=%d&isdir=0&autoinit=1&block_list=%s&content-md5=%s&slice-md5=%s",
-		path, stream.GetSize(),
-		block_list_str,
-		content_md5, slice_md5)
-	params := map[string]string{
-		"method": "precreate",
+	blockListStr, err := utils.Json.MarshalToString(blockList)
+	if err != nil {
+		return err
 	}
+	path := stdpath.Join(dstDir.GetPath(), stream.GetName())
 	var precreateResp PrecreateResp
-	_, err = d.post("/xpan/file", params, data, &precreateResp)
+	_, err = d.post("/xpan/file", map[string]string{"method": "precreate"}, map[string]string{
+		"path":        path,
+		"size":        strconv.FormatInt(size, 10),
+		"isdir":       "0",
+		"autoinit":    "1",
+		"rtype":       "3",
+		"block_list":  blockListStr,
+		"content-md5": contentMd5,
+		"slice-md5":   sliceMd5,
+	}, &precreateResp)
 	if err != nil {
 		return err
 	}
 	log.Debugf("%+v", precreateResp)
+	// the file is rapid uploaded by the md5
 	if precreateResp.ReturnType == 2 {
+		up(100)
 		return nil
 	}
-	params = map[string]string{
-		"method":       "upload",
-		"access_token": d.AccessToken,
-		"type":         "tmpfile",
-		"path":         path,
-		"uploadid":     precreateResp.Uploadid,
-	}
-	left = stream.GetSize()
+	buf := make([]byte, blockSize)
 	for i, partseq := range precreateResp.BlockList {
-		byteSize := Default
-		var byteData []byte
-		if left < Default {
-			byteSize = left
-			byteData = make([]byte, byteSize)
-		} else {
-			byteData = defaultByteData
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
 		}
-		left -= byteSize
-		_, err = io.ReadFull(tempFile, byteData)
-		if err != nil {
-			return err
+		if partseq < 0 || partseq >= len(blockList) {
+			return fmt.Errorf("invalid partseq %d", partseq)
 		}
-		u := "https://d.pcs.baidu.com/rest/2.0/pcs/superfile2"
-		params["partseq"] = strconv.Itoa(partseq)
-		res, err := base.RestyClient.R().SetQueryParams(params).SetFileReader("file", stream.GetName(), bytes.NewReader(byteData)).Post(u)
-		if err != nil {
+		offset := int64(partseq) * blockSize
+		n := blockSize
+		if left := size - offset; left < n {
+			n = left
+		}
+		if _, err = src.ReadAt(buf[:n], offset); err != nil && err != io.EOF {
 			return err
 		}
-		log.Debugln(res.String())
-		if len(precreateResp.BlockList) > 0 {
-			up(i * 100 / len(precreateResp.BlockList))
+		if err = d.uploadBlock(path, precreateResp.Uploadid, partseq, buf[:n], blockList[partseq]); err != nil {
+			return err
 		}
+		up((i + 1) * 100 / len(precreateResp.BlockList))
 	}
-	_, err = d.create(path, stream.GetSize(), 0, precreateResp.Uploadid, block_list_str)
+	_, err = d.create(path, size, 0, precreateResp.Uploadid, blockListStr)
 	return err
 }
 
@@ -6,18 +6,24 @@ import (
 )
 
 type Addition struct {
-	RefreshToken string `json:"refresh_token" required:"true"`
+	// it's got by the device code flow if it's empty, see the status of the storage for the code to enter
+	RefreshToken string `json:"refresh_token" secret:"true" help:"leave it empty to login by the device code, the code to enter is shown in the status of the storage"`
 	driver.RootPath
 	OrderBy        string `json:"order_by" type:"select" options:"name,time,size" default:"name"`
 	OrderDirection string `json:"order_direction" type:"select" options:"asc,desc" default:"asc"`
-	DownloadAPI    string `json:"download_api" type:"select" options:"official,crack" default:"official"`
 	ClientID       string `json:"client_id" required:"true" default:"iYCeC9g08h5vuP9UqvPHKKSVrKFXGa1v"`
-	ClientSecret   string `json:"client_secret" required:"true" default:"jXiFMOPVPCWlO2M5CwWQzffpNPaGTRBG"`
+	ClientSecret   string `json:"client_secret" required:"true" default:"jXiFMOPVPCWlO2M5CwWQzffpNPaGTRBG" secret:"true"`
+	// the pending device code and the user code of the device code flow, they're cleared after the login
+	DeviceCode string `json:"device_code" help:"the pending device code of the login, it's filled and cleared automatically"`
+	UserCode   string `json:"user_code" help:"the code to enter on the verification page, it's filled and cleared automatically"`
 }
 
 var config = driver.Config{
-	Name:        "BaiduNetdisk",
-	DefaultRoot: "/",
+	Name:                   "BaiduNetdisk",
+	DefaultRoot:            "/",
+	NeedsSeekableUpload:    true,
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
 }
 
 func New() driver.Driver {
@@ -1,11 +1,15 @@
 package baidu_netdisk
 
 import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"net/url"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/alist-org/alist/v3/drivers/base"
 	"github.com/alist-org/alist/v3/internal/errs"
@@ -17,6 +21,25 @@ import (
 
 // do others that not defined in Driver interface
 
+const (
+	oauthUrl  = "https://openapi.baidu.com/oauth/2.0"
+	apiUrl    = "https://pan.baidu.com/rest/2.0"
+	uploadUrl = "https://d.pcs.baidu.com/rest/2.0/pcs/superfile2"
+	// the User-Agent required by the dlinks and the signed urls of them
+	userAgent = "pan.baidu.com"
+	// the bytes of the beginning of the file to get the slice-md5
+	sliceSize = 256 * 1024
+	// the signed download urls expire in 8 hours
+	linkExpiration = 8*time.Hour - 10*time.Minute
+)
+
+// blockSizes the sizes of the blocks of the uploads by the vip types, the files are at most 1024 blocks
+var blockSizes = map[int]int64{
+	0: 4 * 1024 * 1024,
+	1: 16 * 1024 * 1024,
+	2: 32 * 1024 * 1024,
+}
+
 func (d *BaiduNetdisk) refreshToken() error {
 	err := d._refreshToken()
 	if err != nil && err == errs.EmptyToken {
@@ -26,21 +49,13 @@ func (d *BaiduNetdisk) refreshToken() error {
 }
 
 func (d *BaiduNetdisk) _refreshToken() error {
-	u := "https://openapi.baidu.com/oauth/2.0/token"
-	var resp base.TokenResp
-	var e TokenErrResp
-	_, err := base.RestyClient.R().SetResult(&resp).SetError(&e).SetQueryParams(map[string]string{
+	resp, err := d.tokenRequest(map[string]string{
 		"grant_type":    "refresh_token",
 		"refresh_token": d.RefreshToken,
-		"client_id":     d.ClientID,
-		"client_secret": d.ClientSecret,
-	}).Get(u)
+	})
 	if err != nil {
 		return err
 	}
-	if e.Error != "" {
-		return fmt.Errorf("%s : %s", e.Error, e.ErrorDescription)
-	}
 	if resp.RefreshToken == "" {
 		return errs.EmptyToken
 	}
@@ -49,6 +64,74 @@ func (d *BaiduNetdisk) _refreshToken() error {
 	return nil
 }
 
+func (d *BaiduNetdisk) tokenRequest(params map[string]string) (*base.TokenResp, error) {
+	var resp base.TokenResp
+	var e TokenErrResp
+	params["client_id"] = d.ClientID
+	params["client_secret"] = d.ClientSecret
+	_, err := base.RestyClient.R().SetResult(&resp).SetError(&e).SetQueryParams(params).Get(oauthUrl + "/token")
+	if err != nil {
+		return nil, err
+	}
+	if e.Error != "" {
+		return nil, &tokenError{e}
+	}
+	return &resp, nil
+}
+
+type tokenError struct {
+	TokenErrResp
+}
+
+func (e *tokenError) Error() string {
+	return fmt.Sprintf("%s : %s", e.TokenErrResp.Error, e.ErrorDescription)
+}
+
+// deviceLogin login by the device code flow, the user enters the user code on the verification page,
+// then the token is got when the storage is loaded again, the error returned tells the user what to do
+func (d *BaiduNetdisk) deviceLogin() error {
+	if d.DeviceCode != "" {
+		resp, err := d.tokenRequest(map[string]string{
+			"grant_type": "device_token",
+			"code":       d.DeviceCode,
+		})
+		var e *tokenError
+		switch {
+		case err == nil:
+			d.AccessToken, d.RefreshToken = resp.AccessToken, resp.RefreshToken
+			d.DeviceCode, d.UserCode = "", ""
+			op.MustSaveDriverStorage(d)
+			return nil
+		case errors.As(err, &e) && (e.TokenErrResp.Error == "authorization_pending" || e.TokenErrResp.Error == "slow_down"):
+			return d.deviceLoginHint()
+		case errors.As(err, &e):
+			// the code is expired or declined, get a new one
+		default:
+			return err
+		}
+	}
+	var resp DeviceCodeResp
+	var e TokenErrResp
+	_, err := base.RestyClient.R().SetResult(&resp).SetError(&e).SetQueryParams(map[string]string{
+		"response_type": "device_code",
+		"client_id":     d.ClientID,
+		"scope":         "basic,netdisk",
+	}).Get(oauthUrl + "/device/code")
+	if err != nil {
+		return err
+	}
+	if e.Error != "" {
+		return fmt.Errorf("%s : %s", e.Error, e.ErrorDescription)
+	}
+	d.DeviceCode, d.UserCode = resp.DeviceCode, resp.UserCode
+	op.MustSaveDriverStorage(d)
+	return d.deviceLoginHint()
+}
+
+func (d *BaiduNetdisk) deviceLoginHint() error {
+	return fmt.Errorf("open https://openapi.baidu.com/device and enter the code %s to authorize, then reload the storage", d.UserCode)
+}
+
 func (d *BaiduNetdisk) request(furl string, method string, callback base.ReqCallback, resp interface{}) ([]byte, error) {
 	req := base.RestyClient.R()
 	req.SetQueryParam("access_token", d.AccessToken)
@@ -77,15 +160,15 @@ func (d *BaiduNetdisk) request(furl string, method string, callback base.ReqCall
 }
 
 func (d *BaiduNetdisk) get(pathname string, params map[string]string, resp interface{}) ([]byte, error) {
-	return d.request("https://pan.baidu.com/rest/2.0"+pathname, http.MethodGet, func(req *resty.Request) {
+	return d.request(apiUrl+pathname, http.MethodGet, func(req *resty.Request) {
 		req.SetQueryParams(params)
 	}, resp)
 }
 
-func (d *BaiduNetdisk) post(pathname string, params map[string]string, data interface{}, resp interface{}) ([]byte, error) {
-	return d.request("https://pan.baidu.com/rest/2.0"+pathname, http.MethodPost, func(req *resty.Request) {
+func (d *BaiduNetdisk) post(pathname string, params map[string]string, form map[string]string, resp interface{}) ([]byte, error) {
+	return d.request(apiUrl+pathname, http.MethodPost, func(req *resty.Request) {
 		req.SetQueryParams(params)
-		req.SetBody(data)
+		req.SetFormData(form)
 	}, resp)
 }
 
@@ -121,7 +204,19 @@ func (d *BaiduNetdisk) getFiles(dir string) ([]File, error) {
 	return res, nil
 }
 
-func (d *BaiduNetdisk) linkOfficial(file model.Obj, args model.LinkArgs) (*model.Link, error) {
+// getVipType get the vip type of the user, which decides the size of the blocks of the uploads
+func (d *BaiduNetdisk) getVipType() (int, error) {
+	var resp UinfoResp
+	_, err := d.get("/xpan/nas", map[string]string{"method": "uinfo"}, &resp)
+	if err != nil {
+		return 0, err
+	}
+	return resp.VipType, nil
+}
+
+// link get the dlink of the file, and sign it by requesting it with the access token,
+// the location it's redirected to is the signed url that can be downloaded with the User-Agent
+func (d *BaiduNetdisk) link(file model.Obj) (*model.Link, error) {
 	var resp DownloadResp
 	params := map[string]string{
 		"method": "filemetas",
@@ -132,42 +227,31 @@ func (d *BaiduNetdisk) linkOfficial(file model.Obj, args model.LinkArgs) (*model
 	if err != nil {
 		return nil, err
 	}
+	if len(resp.List) == 0 || resp.List[0].Dlink == "" {
+		return nil, fmt.Errorf("no dlink of %s", file.GetName())
+	}
 	u := fmt.Sprintf("%s&access_token=%s", resp.List[0].Dlink, d.AccessToken)
-	res, err := base.NoRedirectClient.R().SetHeader("User-Agent", "pan.baidu.com").Head(u)
+	res, err := base.NoRedirectClient.R().SetHeader("User-Agent", userAgent).Head(u)
 	if err != nil {
 		return nil, err
 	}
-	//if res.StatusCode() == 302 {
-	u = res.Header().Get("location")
-	//}
-	return &model.Link{
+	exp := linkExpiration
+	link := &model.Link{
 		URL: u,
 		Header: http.Header{
-			"User-Agent": []string{"pan.baidu.com"},
+			"User-Agent": []string{userAgent},
 		},
-	}, nil
-}
-
-func (d *BaiduNetdisk) linkCrack(file model.Obj, args model.LinkArgs) (*model.Link, error) {
-	var resp DownloadResp2
-	param := map[string]string{
-		"target": fmt.Sprintf("[\"%s\"]", file.GetPath()),
-		"dlink":  "1",
-		"web":    "5",
-		"origin": "dlna",
+		Expiration: &exp,
 	}
-	_, err := d.request("https://pan.baidu.com/api/filemetas", http.MethodGet, func(req *resty.Request) {
-		req.SetQueryParams(param)
-	}, &resp)
-	if err != nil {
-		return nil, err
+	switch res.StatusCode() {
+	case http.StatusFound, http.StatusMovedPermanently, http.StatusTemporaryRedirect:
+		link.URL = res.Header().Get("Location")
+	case http.StatusOK:
+		// the dlink is downloaded directly with the access token
+	default:
+		return nil, fmt.Errorf("failed sign the dlink of %s: %s", file.GetName(), res.Status())
 	}
-	return &model.Link{
-		URL: resp.Info[0].Dlink,
-		Header: http.Header{
-			"User-Agent": []string{"pan.baidu.com"},
-		},
-	}, nil
+	return link, nil
 }
 
 func (d *BaiduNetdisk) manage(opera string, filelist interface{}) ([]byte, error) {
@@ -179,23 +263,83 @@ func (d *BaiduNetdisk) manage(opera string, filelist interface{}) ([]byte, error
 	if err != nil {
 		return nil, err
 	}
-	data := fmt.Sprintf("async=0&filelist=%s&ondup=newcopy", string(marshal))
-	return d.post("/xpan/file", params, data, nil)
+	return d.post("/xpan/file", params, map[string]string{
+		"async":    "0",
+		"filelist": string(marshal),
+		"ondup":    "newcopy",
+	}, nil)
 }
 
-func (d *BaiduNetdisk) create(path string, size int64, isdir int, uploadid, block_list string) ([]byte, error) {
+func (d *BaiduNetdisk) create(path string, size int64, isdir int, uploadid, blockList string) ([]byte, error) {
 	params := map[string]string{
 		"method": "create",
 	}
-	data := fmt.Sprintf("path=%s&size=%d&isdir=%d", path, size, isdir)
+	form := map[string]string{
+		"path":  path,
+		"size":  strconv.FormatInt(size, 10),
+		"isdir": strconv.Itoa(isdir),
+	}
 	if uploadid != "" {
-		data += fmt.Sprintf("&uploadid=%s&block_list=%s", uploadid, block_list)
+		// overwrite the file of the same name
+		form["rtype"] = "3"
+		form["uploadid"] = uploadid
+		form["block_list"] = blockList
+	}
+	return d.post("/xpan/file", params, form, nil)
+}
+
+// hashBlocks get the md5 of the whole file, the md5 of the first 256KB and the md5 of each block
+func hashBlocks(src io.ReaderAt, size, blockSize int64) (contentMd5, sliceMd5 string, blockList []string, err error) {
+	slice := md5.New()
+	if _, err = io.Copy(slice, io.NewSectionReader(src, 0, sliceSize)); err != nil {
+		return
 	}
-	return d.post("/xpan/file", params, data, nil)
+	whole := md5.New()
+	block := md5.New()
+	blockList = make([]string, 0, (size+blockSize-1)/blockSize)
+	// the empty file has one block of the md5 of nothing
+	for offset := int64(0); offset < size || len(blockList) == 0; offset += blockSize {
+		block.Reset()
+		n := blockSize
+		if left := size - offset; left < n {
+			n = left
+		}
+		if _, err = io.Copy(io.MultiWriter(whole, block), io.NewSectionReader(src, offset, n)); err != nil {
+			return
+		}
+		blockList = append(blockList, hex.EncodeToString(block.Sum(nil)))
+	}
+	return hex.EncodeToString(whole.Sum(nil)), hex.EncodeToString(slice.Sum(nil)), blockList, nil
 }
 
-func encodeURIComponent(str string) string {
-	r := url.QueryEscape(str)
-	r = strings.ReplaceAll(r, "+", "%20")
-	return r
+// uploadBlock upload the block and check the md5 the server replied, it's retried 3 times
+func (d *BaiduNetdisk) uploadBlock(path, uploadID string, partseq int, data []byte, md5sum string) error {
+	var err error
+	for i := 0; i < 3; i++ {
+		var resp UploadResp
+		var res *resty.Response
+		res, err = base.RestyClient.R().SetQueryParams(map[string]string{
+			"method":       "upload",
+			"access_token": d.AccessToken,
+			"type":         "tmpfile",
+			"path":         path,
+			"uploadid":     uploadID,
+			"partseq":      strconv.Itoa(partseq),
+		}).SetFileReader("file", "file", bytes.NewReader(data)).Post(uploadUrl)
+		if err == nil {
+			// the content type of the response may not be json
+			err = utils.Json.Unmarshal(res.Body(), &resp)
+		}
+		if err == nil {
+			switch {
+			case resp.ErrorCode != 0:
+				err = fmt.Errorf("failed upload the block %d: %d %s", partseq, resp.ErrorCode, resp.ErrorMsg)
+			case resp.Md5 != md5sum:
+				err = fmt.Errorf("the md5 of the block %d is %s, not %s", partseq, resp.Md5, md5sum)
+			default:
+				return nil
+			}
+		}
+	}
+	return err
 }
@@ -6,12 +6,12 @@ import (
 )
 
 type Addition struct {
-	RefreshToken string `json:"refresh_token" required:"true"`
+	RefreshToken string `json:"refresh_token" required:"true" secret:"true"`
 	ShowType     string `json:"show_type" type:"select" options:"root,root_only_album,root_only_file" default:"root"`
 	AlbumID      string `json:"album_id"`
 	//AlbumPassword string `json:"album_password"`
 	ClientID     string `json:"client_id" required:"true" default:"iYCeC9g08h5vuP9UqvPHKKSVrKFXGa1v"`
-	ClientSecret string `json:"client_secret" required:"true" default:"jXiFMOPVPCWlO2M5CwWQzffpNPaGTRBG"`
+	ClientSecret string `json:"client_secret" required:"true" default:"jXiFMOPVPCWlO2M5CwWQzffpNPaGTRBG" secret:"true"`
 }
 
 func (a Addition) GetRootId() string {
@@ -19,8 +19,11 @@ func (a Addition) GetRootId() string {
 }
 
 var config = driver.Config{
-	Name:      "BaiduPhoto",
-	LocalSort: true,
+	Name:                   "BaiduPhoto",
+	LocalSort:              true,
+	NeedsSeekableUpload:    true,
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
 }
 
 func init() {
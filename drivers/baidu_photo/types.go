@@ -3,6 +3,8 @@ package baiduphoto
 import (
 	"fmt"
 	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
 )
 
 type TokenErrResp struct {
@@ -54,9 +56,10 @@ func (c *File) ModTime() time.Time {
 	}
 	return *c.parseTime
 }
-func (c *File) IsDir() bool     { return false }
-func (c *File) GetID() string   { return joinID(c.Fsid) }
-func (c *File) GetPath() string { return "file" }
+func (c *File) IsDir() bool             { return false }
+func (c *File) GetID() string           { return joinID(c.Fsid) }
+func (c *File) GetPath() string         { return "file" }
+func (c *File) GetHash() model.HashInfo { return model.HashInfo{} }
 func (c *File) Thumb() string {
 	if len(c.Thumburl) > 0 {
 		return c.Thumburl[0]
@@ -107,9 +110,10 @@ func (a *Album) ModTime() time.Time {
 	}
 	return *a.parseTime
 }
-func (a *Album) IsDir() bool     { return true }
-func (a *Album) GetID() string   { return joinID(a.AlbumID, a.Tid) }
-func (a *Album) GetPath() string { return "album" }
+func (a *Album) IsDir() bool             { return true }
+func (a *Album) GetID() string           { return joinID(a.AlbumID, a.Tid) }
+func (a *Album) GetPath() string         { return "album" }
+func (a *Album) GetHash() model.HashInfo { return model.HashInfo{} }
 
 func (af *AlbumFile) GetID() string  { return joinID(af.Fsid, af.AlbumID, af.Tid, af.Uk) }
 func (c *AlbumFile) GetPath() string { return "albumfile" }
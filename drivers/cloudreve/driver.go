@@ -0,0 +1,153 @@
+package cloudreve
+
+import (
+	"context"
+	"net/http"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+type Cloudreve struct {
+	model.Storage
+	Addition
+}
+
+func (d *Cloudreve) Config() driver.Config {
+	return config
+}
+
+func (d *Cloudreve) GetAddition() driver.Additional {
+	return d.Addition
+}
+
+func (d *Cloudreve) Init(ctx context.Context, storage model.Storage) error {
+	d.Storage = storage
+	err := utils.Json.UnmarshalFromString(d.Storage.Addition, &d.Addition)
+	if err != nil {
+		return err
+	}
+	d.Address = strings.TrimSuffix(d.Address, "/")
+	if d.Cookie == "" {
+		if d.Username == "" {
+			return errSessionExpired
+		}
+		if err = d.login(); err != nil {
+			return err
+		}
+	}
+	// check the session
+	return d.request(http.MethodGet, "/user/storage", nil, nil)
+}
+
+func (d *Cloudreve) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *Cloudreve) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	data, err := d.listDir(dir.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(data.Objects, func(src Object) (model.Obj, error) {
+		return objectToObj(src, dir.GetPath()), nil
+	})
+}
+
+func (d *Cloudreve) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	var u string
+	err := d.request(http.MethodPut, "/file/download/"+file.GetID(), nil, &u)
+	if err != nil {
+		return nil, err
+	}
+	// the url of the local policy is relative to the site
+	if strings.HasPrefix(u, "/") {
+		u = d.Address + u
+	}
+	return &model.Link{URL: u}, nil
+}
+
+func (d *Cloudreve) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	return d.request(http.MethodPut, "/directory", func(req *resty.Request) {
+		req.SetBody(map[string]string{
+			"path": stdpath.Join(parentDir.GetPath(), dirName),
+		})
+	}, nil)
+}
+
+func (d *Cloudreve) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.request(http.MethodPatch, "/object", func(req *resty.Request) {
+		req.SetBody(map[string]interface{}{
+			"action":  "move",
+			"src_dir": stdpath.Dir(srcObj.GetPath()),
+			"src":     objectSrc(srcObj),
+			"dst":     dstDir.GetPath(),
+		})
+	}, nil)
+}
+
+func (d *Cloudreve) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	return d.request(http.MethodPost, "/object/rename", func(req *resty.Request) {
+		req.SetBody(map[string]interface{}{
+			"action":   "rename",
+			"src":      objectSrc(srcObj),
+			"new_name": newName,
+		})
+	}, nil)
+}
+
+func (d *Cloudreve) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.request(http.MethodPost, "/object/copy", func(req *resty.Request) {
+		req.SetBody(map[string]interface{}{
+			"src_dir": stdpath.Dir(srcObj.GetPath()),
+			"src":     objectSrc(srcObj),
+			"dst":     dstDir.GetPath(),
+		})
+	}, nil)
+}
+
+func (d *Cloudreve) Remove(ctx context.Context, obj model.Obj) error {
+	src := objectSrc(obj)
+	return d.request(http.MethodDelete, "/object", func(req *resty.Request) {
+		req.SetBody(map[string]interface{}{
+			"dirs":   src["dirs"],
+			"items":  src["items"],
+			"force":  true,
+			"unlink": false,
+		})
+	}, nil)
+}
+
+func (d *Cloudreve) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	// the policy of the dir decides where the file is uploaded to
+	dir, err := d.listDir(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	var info UploadInfo
+	err = d.request(http.MethodPut, "/file/upload", func(req *resty.Request) {
+		req.SetBody(map[string]interface{}{
+			"path":          dstDir.GetPath(),
+			"size":          stream.GetSize(),
+			"name":          stream.GetName(),
+			"policy_id":     dir.Policy.ID,
+			"last_modified": stream.ModTime().UnixMilli(),
+			"mime_type":     stream.GetMimetype(),
+		})
+	}, &info)
+	if err != nil {
+		return err
+	}
+	err = d.upload(ctx, &info, dir.Policy.Type, stream, up)
+	if err != nil {
+		// cancel the session so the placeholder of the file is removed
+		_ = d.request(http.MethodDelete, "/file/upload/"+info.SessionID, nil, nil)
+	}
+	return err
+}
+
+var _ driver.Driver = (*Cloudreve)(nil)
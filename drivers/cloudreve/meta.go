@@ -0,0 +1,30 @@
+package cloudreve
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	Address  string `json:"address" required:"true" help:"the url of the cloudreve, such as https://cloudreve.example.com"`
+	Username string `json:"username"`
+	Password string `json:"password" secret:"true"`
+	// the cloudreve-session cookie, it's renewed by the username and password if they're set
+	Cookie string `json:"cookie" secret:"true" help:"the session of the account, such as cloudreve-session=xxx, it's used instead of the username and password, or it's filled by the login"`
+	driver.RootPath
+}
+
+var config = driver.Config{
+	Name:              "Cloudreve",
+	LocalSort:         true,
+	DefaultRoot:       "/",
+	SupportsRangeRead: true,
+}
+
+func New() driver.Driver {
+	return &Cloudreve{}
+}
+
+func init() {
+	op.RegisterDriver(config, New)
+}
@@ -0,0 +1,58 @@
+package cloudreve
+
+import (
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+// Resp is the response of the api v3, the code is 0 if it succeeded, 401 if the session is expired
+type Resp struct {
+	Code int         `json:"code"`
+	Msg  string      `json:"msg"`
+	Data interface{} `json:"data"`
+}
+
+type Policy struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	MaxSize int64  `json:"max_size"`
+}
+
+type Object struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// the path of the dir the object is in
+	Path string    `json:"path"`
+	Pic  string    `json:"pic"`
+	Size int64     `json:"size"`
+	Type string    `json:"type"`
+	Date time.Time `json:"date"`
+}
+
+type DirectoryData struct {
+	Parent  string   `json:"parent"`
+	Objects []Object `json:"objects"`
+	Policy  Policy   `json:"policy"`
+}
+
+type UploadInfo struct {
+	SessionID  string   `json:"sessionID"`
+	ChunkSize  int64    `json:"chunkSize"`
+	Expires    int64    `json:"expires"`
+	UploadURLs []string `json:"uploadURLs"`
+	Credential string   `json:"credential"`
+}
+
+func objectToObj(f Object, dir string) *model.Object {
+	return &model.Object{
+		ID:       f.ID,
+		Path:     stdpath.Join(dir, f.Name),
+		Name:     f.Name,
+		Size:     f.Size,
+		Modified: f.Date,
+		IsFolder: f.Type == "dir",
+	}
+}
@@ -0,0 +1,220 @@
+package cloudreve
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/cookie"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+// do others that not defined in Driver interface
+
+const sessionCookie = "cloudreve-session"
+
+var errSessionExpired = errors.New("the session of cloudreve is expired, update the cookie or set the username and password")
+
+func (d *Cloudreve) apiUrl(route string) string {
+	return strings.TrimSuffix(d.Address, "/") + "/api/v3" + route
+}
+
+func (d *Cloudreve) request(method, route string, callback base.ReqCallback, out interface{}) error {
+	err := d._request(method, route, callback, out)
+	if err == errSessionExpired && d.Username != "" {
+		if err = d.login(); err != nil {
+			return err
+		}
+		return d._request(method, route, callback, out)
+	}
+	return err
+}
+
+func (d *Cloudreve) _request(method, route string, callback base.ReqCallback, out interface{}) error {
+	req := base.RestyClient.R()
+	req.SetHeader("Cookie", d.Cookie)
+	req.SetHeader("Accept", "application/json, text/plain, */*")
+	if callback != nil {
+		callback(req)
+	}
+	res, err := req.Execute(method, d.apiUrl(route))
+	if err != nil {
+		return err
+	}
+	r := Resp{Data: out}
+	if err = utils.Json.Unmarshal(res.Body(), &r); err != nil {
+		return fmt.Errorf("cloudreve: %s %s", res.Status(), res.String())
+	}
+	switch r.Code {
+	case 0:
+		return nil
+	case 401:
+		return errSessionExpired
+	case 404:
+		return errs.ObjectNotFound
+	}
+	return fmt.Errorf("cloudreve: %d %s", r.Code, r.Msg)
+}
+
+// login login by the username and password, the session cookie is saved to the addition
+func (d *Cloudreve) login() error {
+	res, err := base.RestyClient.R().SetBody(base.Json{
+		"userName":    d.Username,
+		"Password":    d.Password,
+		"captchaCode": "",
+	}).Post(d.apiUrl("/user/session"))
+	if err != nil {
+		return err
+	}
+	var r Resp
+	if err = utils.Json.Unmarshal(res.Body(), &r); err != nil {
+		return fmt.Errorf("cloudreve: %s %s", res.Status(), res.String())
+	}
+	if r.Code != 0 {
+		return fmt.Errorf("cloudreve: failed login: %s", r.Msg)
+	}
+	c := cookie.GetCookie(res.Cookies(), sessionCookie)
+	if c == nil {
+		return fmt.Errorf("cloudreve: no session is returned by the login")
+	}
+	d.Cookie = sessionCookie + "=" + c.Value
+	op.MustSaveDriverStorage(d)
+	return nil
+}
+
+func (d *Cloudreve) listDir(dir string) (*DirectoryData, error) {
+	var data DirectoryData
+	err := d.request(http.MethodGet, "/directory"+utils.EncodePath(dir, true), nil, &data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// objectSrc get the src of the object operations, the dirs and the files are in the different lists
+func objectSrc(objs ...model.Obj) base.Json {
+	dirs, items := make([]string, 0), make([]string, 0)
+	for _, obj := range objs {
+		if obj.IsDir() {
+			dirs = append(dirs, obj.GetID())
+		} else {
+			items = append(items, obj.GetID())
+		}
+	}
+	return base.Json{"dirs": dirs, "items": items}
+}
+
+// upload upload the file by the session of the policy of the dir, the local, remote (slave) and onedrive
+// policies are supported, the others need the sdks of them
+func (d *Cloudreve) upload(ctx context.Context, info *UploadInfo, policy string, stream model.FileStreamer, up driver.UpdateProgress) error {
+	size := stream.GetSize()
+	chunkSize := info.ChunkSize
+	if chunkSize <= 0 || chunkSize > size {
+		chunkSize = size
+	}
+	var buf []byte
+	// the empty file is uploaded by one empty chunk
+	for index, offset := 0, int64(0); index == 0 || offset < size; index++ {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		n := chunkSize
+		if left := size - offset; left < n {
+			n = left
+		}
+		if int64(len(buf)) < n {
+			buf = make([]byte, n)
+		}
+		if _, err := io.ReadFull(stream, buf[:n]); err != nil {
+			return err
+		}
+		var err error
+		switch policy {
+		case "local":
+			err = d.request(http.MethodPost, fmt.Sprintf("/file/upload/%s/%d", info.SessionID, index), func(req *resty.Request) {
+				req.SetHeader("Content-Type", "application/octet-stream")
+				req.SetBody(buf[:n])
+			}, nil)
+		case "remote":
+			err = d.uploadRemote(ctx, info, index, buf[:n])
+		case "onedrive":
+			err = d.uploadOneDrive(ctx, info, offset, size, buf[:n])
+		default:
+			return fmt.Errorf("cloudreve: the %s policy isn't supported", policy)
+		}
+		if err != nil {
+			return err
+		}
+		offset += n
+		if size > 0 {
+			up(int(offset * 100 / size))
+		}
+	}
+	if policy == "onedrive" {
+		return d.request(http.MethodPost, "/callback/onedrive/finish/"+info.SessionID, func(req *resty.Request) {
+			req.SetBody("{}")
+		}, nil)
+	}
+	return nil
+}
+
+// uploadRemote upload the chunk to the slave node of the remote policy with the credential
+func (d *Cloudreve) uploadRemote(ctx context.Context, info *UploadInfo, index int, data []byte) error {
+	if len(info.UploadURLs) == 0 {
+		return fmt.Errorf("cloudreve: no upload url of the remote policy")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, info.UploadURLs[0]+"?chunk="+strconv.Itoa(index), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(data))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Authorization", info.Credential)
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	var r Resp
+	if err = utils.Json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return fmt.Errorf("cloudreve: failed upload to the slave: %s", res.Status)
+	}
+	if r.Code != 0 {
+		return fmt.Errorf("cloudreve: failed upload to the slave: %d %s", r.Code, r.Msg)
+	}
+	return nil
+}
+
+// uploadOneDrive put the range of the file to the upload session of onedrive
+func (d *Cloudreve) uploadOneDrive(ctx context.Context, info *UploadInfo, offset, size int64, data []byte) error {
+	if len(info.UploadURLs) == 0 {
+		return fmt.Errorf("cloudreve: no upload url of the onedrive policy")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, info.UploadURLs[0], bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(data))
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(len(data))-1, size))
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusAccepted && res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("cloudreve: failed upload to onedrive: %s %s", res.Status, body)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base32"
+	"encoding/base64"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+// the salt used by rclone if the password2 isn't set
+var defaultSalt = []byte{0xA8, 0x0D, 0xF4, 0x3A, 0x8F, 0xBD, 0x03, 0x08, 0xA7, 0xCA, 0xB8, 0x3E, 0x58, 0x1F, 0x86, 0xB1}
+
+const (
+	nameEncryptionStandard = "standard"
+	nameEncryptionOff      = "off"
+	// the suffix of the files when the names aren't encrypted
+	encryptedSuffix = ".bin"
+)
+
+var base32Encoding = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+// cipherKeys encrypt the names and the contents in the same way as rclone crypt
+type cipherKeys struct {
+	dataKey   [32]byte
+	nameKey   [32]byte
+	nameTweak [16]byte
+	block     cipher.Block
+	// encrypt the file names or not
+	encryptName bool
+	// encrypt the dir names or not, only used if the file names are encrypted
+	encryptDir bool
+	base64     bool
+}
+
+func newCipher(password, salt, nameEncryption, encoding string, encryptDir bool) (*cipherKeys, error) {
+	c := &cipherKeys{
+		encryptName: nameEncryption != nameEncryptionOff,
+		encryptDir:  encryptDir,
+		base64:      encoding == "base64",
+	}
+	saltBytes := defaultSalt
+	if salt != "" {
+		saltBytes = []byte(salt)
+	}
+	keySize := len(c.dataKey) + len(c.nameKey) + len(c.nameTweak)
+	key := make([]byte, keySize)
+	if password != "" {
+		var err error
+		key, err = scrypt.Key([]byte(password), saltBytes, 16384, 8, 1, keySize)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	copy(c.dataKey[:], key)
+	copy(c.nameKey[:], key[len(c.dataKey):])
+	copy(c.nameTweak[:], key[len(c.dataKey)+len(c.nameKey):])
+	block, err := aes.NewCipher(c.nameKey[:])
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	c.block = block
+	return c, nil
+}
+
+func (c *cipherKeys) encode(b []byte) string {
+	if c.base64 {
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	return strings.ToLower(base32Encoding.EncodeToString(b))
+}
+
+func (c *cipherKeys) decode(s string) ([]byte, error) {
+	if c.base64 {
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return base32Encoding.DecodeString(strings.ToUpper(s))
+}
+
+// encryptSegment encrypt a segment of the path with eme, it's padded with pkcs#7 first
+func (c *cipherKeys) encryptSegment(name string) string {
+	if name == "" {
+		return ""
+	}
+	padding := aes.BlockSize - len(name)%aes.BlockSize
+	padded := make([]byte, len(name)+padding)
+	copy(padded, name)
+	for i := len(name); i < len(padded); i++ {
+		padded[i] = byte(padding)
+	}
+	return c.encode(emeTransform(c.block, c.nameTweak[:], padded, true))
+}
+
+func (c *cipherKeys) decryptSegment(name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	raw, err := c.decode(name)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode the name")
+	}
+	if len(raw) == 0 || len(raw)%aes.BlockSize != 0 {
+		return "", errors.New("the length of the encrypted name is invalid")
+	}
+	padded := emeTransform(c.block, c.nameTweak[:], raw, false)
+	padding := int(padded[len(padded)-1])
+	if padding == 0 || padding > aes.BlockSize || padding > len(padded) {
+		return "", errors.New("the padding of the encrypted name is invalid")
+	}
+	for _, b := range padded[len(padded)-padding:] {
+		if int(b) != padding {
+			return "", errors.New("the padding of the encrypted name is invalid")
+		}
+	}
+	return string(padded[:len(padded)-padding]), nil
+}
+
+// EncryptName get the name stored in the remote
+func (c *cipherKeys) EncryptName(name string, isDir bool) string {
+	if !c.encryptName {
+		if isDir {
+			return name
+		}
+		return name + encryptedSuffix
+	}
+	if isDir && !c.encryptDir {
+		return name
+	}
+	return c.encryptSegment(name)
+}
+
+// DecryptName get the real name of the object in the remote
+func (c *cipherKeys) DecryptName(name string, isDir bool) (string, error) {
+	if !c.encryptName {
+		if isDir {
+			return name, nil
+		}
+		if !strings.HasSuffix(name, encryptedSuffix) {
+			return "", errors.New("the name isn't ended with " + encryptedSuffix)
+		}
+		return strings.TrimSuffix(name, encryptedSuffix), nil
+	}
+	if isDir && !c.encryptDir {
+		return name, nil
+	}
+	return c.decryptSegment(name)
+}
+
+// EncryptPath encrypt all the segments of the dir path
+func (c *cipherKeys) EncryptPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i := range segments {
+		segments[i] = c.EncryptName(segments[i], true)
+	}
+	return strings.Join(segments, "/")
+}
+
+// emeTransform encrypt or decrypt the data with the ECB-Mix-ECB mode, the length of the data
+// must be a multiple of the block size, see https://eprint.iacr.org/2003/147
+func emeTransform(block cipher.Block, tweak, data []byte, encrypt bool) []byte {
+	const size = aes.BlockSize
+	transform := block.Decrypt
+	if encrypt {
+		transform = block.Encrypt
+	}
+	m := len(data) / size
+	out := make([]byte, len(data))
+	// the table of L*2^j
+	l := make([]byte, size)
+	block.Encrypt(l, make([]byte, size))
+	table := make([][]byte, m)
+	for j := 0; j < m; j++ {
+		multByTwo(l)
+		table[j] = append([]byte(nil), l...)
+	}
+	buf := make([]byte, size)
+	for j := 0; j < m; j++ {
+		xorBlock(buf, data[j*size:(j+1)*size], table[j])
+		transform(out[j*size:(j+1)*size], buf)
+	}
+	mp := make([]byte, size)
+	xorBlock(mp, out[:size], tweak)
+	for j := 1; j < m; j++ {
+		xorBlock(mp, mp, out[j*size:(j+1)*size])
+	}
+	mc := make([]byte, size)
+	transform(mc, mp)
+	mask := make([]byte, size)
+	xorBlock(mask, mp, mc)
+	for j := 1; j < m; j++ {
+		multByTwo(mask)
+		xorBlock(out[j*size:(j+1)*size], out[j*size:(j+1)*size], mask)
+	}
+	first := make([]byte, size)
+	xorBlock(first, mc, tweak)
+	for j := 1; j < m; j++ {
+		xorBlock(first, first, out[j*size:(j+1)*size])
+	}
+	copy(out[:size], first)
+	for j := 0; j < m; j++ {
+		transform(out[j*size:(j+1)*size], out[j*size:(j+1)*size])
+		xorBlock(out[j*size:(j+1)*size], out[j*size:(j+1)*size], table[j])
+	}
+	return out
+}
+
+// multByTwo multiply the block by 2 in GF(2^128)
+func multByTwo(b []byte) {
+	carry := b[len(b)-1] >= 128
+	for j := len(b) - 1; j > 0; j-- {
+		b[j] = b[j]<<1 | b[j-1]>>7
+	}
+	b[0] <<= 1
+	if carry {
+		b[0] ^= 135
+	}
+}
+
+func xorBlock(dst, a, b []byte) {
+	for i := range dst {
+		dst[i] = a[i] ^ b[i]
+	}
+}
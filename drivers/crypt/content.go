@@ -0,0 +1,193 @@
+package crypt
+
+import (
+	"crypto/rand"
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// the format of the encrypted files is the header followed by the blocks, each block of
+// the data is sealed by the secretbox with the nonce increased from the one in the header
+const (
+	fileMagic       = "RCLONE\x00\x00"
+	nonceSize       = 24
+	fileHeaderSize  = len(fileMagic) + nonceSize
+	blockHeaderSize = secretbox.Overhead
+	blockDataSize   = 64 * 1024
+	blockSize       = blockHeaderSize + blockDataSize
+)
+
+var errEncryptedBadBlock = errors.New("failed to authenticate the decrypted block, bad password?")
+
+type nonce [nonceSize]byte
+
+// add the number to the nonce as a little endian integer
+func (n *nonce) add(x uint64) {
+	carry := uint16(0)
+	for i := 0; i < 8; i++ {
+		t := uint16(n[i]) + uint16(x&0xff) + carry
+		n[i] = byte(t)
+		carry, x = t>>8, x>>8
+	}
+	for i := 8; i < len(n) && carry != 0; i++ {
+		t := uint16(n[i]) + carry
+		n[i] = byte(t)
+		carry = t >> 8
+	}
+}
+
+// EncryptedSize get the size of the file stored in the remote
+func EncryptedSize(size int64) int64 {
+	blocks, residue := size/blockDataSize, size%blockDataSize
+	encrypted := int64(fileHeaderSize) + blocks*blockSize
+	if residue != 0 {
+		encrypted += blockHeaderSize + residue
+	}
+	return encrypted
+}
+
+// DecryptedSize get the real size of the file stored in the remote
+func DecryptedSize(size int64) (int64, error) {
+	size -= int64(fileHeaderSize)
+	if size < 0 {
+		return 0, errors.New("the file is too short to be encrypted")
+	}
+	blocks, residue := size/blockSize, size%blockSize
+	decrypted := blocks * blockDataSize
+	if residue != 0 {
+		residue -= blockHeaderSize
+		if residue <= 0 {
+			return 0, errors.New("the size of the encrypted file is invalid")
+		}
+	}
+	return decrypted + residue, nil
+}
+
+// encrypter read the encrypted content of the reader
+type encrypter struct {
+	r     io.Reader
+	key   *[32]byte
+	nonce nonce
+	buf   []byte
+	// the encrypted data not read yet
+	out []byte
+	err error
+}
+
+func (c *cipherKeys) newEncrypter(r io.Reader) (*encrypter, error) {
+	e := &encrypter{r: r, key: &c.dataKey, buf: make([]byte, blockDataSize)}
+	if _, err := io.ReadFull(rand.Reader, e.nonce[:]); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	e.out = append([]byte(fileMagic), e.nonce[:]...)
+	return e, nil
+}
+
+func (e *encrypter) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.err != nil {
+			return 0, e.err
+		}
+		n, err := io.ReadFull(e.r, e.buf)
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		if n > 0 {
+			e.out = secretbox.Seal(e.out[:0], e.buf[:n], (*[nonceSize]byte)(&e.nonce), e.key)
+			e.nonce.add(1)
+		}
+		e.err = err
+	}
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+	return n, nil
+}
+
+// decrypter read the decrypted content of the encrypted reader from the start of a block
+type decrypter struct {
+	rc    io.ReadCloser
+	key   *[32]byte
+	nonce nonce
+	buf   []byte
+	// the decrypted data not read yet
+	out []byte
+	// the bytes skipped in the first block
+	skip int
+	err  error
+}
+
+// readNonce read the header of the encrypted file and get the nonce
+func readNonce(r io.Reader) (nonce, error) {
+	var n nonce
+	header := make([]byte, fileHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return n, errors.New("the file is too short to be encrypted")
+		}
+		return n, errors.WithStack(err)
+	}
+	if string(header[:len(fileMagic)]) != fileMagic {
+		return n, errors.New("the file isn't encrypted, the magic is not found")
+	}
+	copy(n[:], header[len(fileMagic):])
+	return n, nil
+}
+
+// newDecrypter decrypt the rc that starts from the block of the offset, n is the nonce in the header
+func (c *cipherKeys) newDecrypter(rc io.ReadCloser, n nonce, offset int64) *decrypter {
+	n.add(uint64(offset / blockDataSize))
+	return &decrypter{
+		rc:    rc,
+		key:   &c.dataKey,
+		nonce: n,
+		buf:   make([]byte, blockSize),
+		skip:  int(offset % blockDataSize),
+	}
+}
+
+// blockOffset get the offset of the block in the encrypted file where the offset is
+func blockOffset(offset int64) int64 {
+	return int64(fileHeaderSize) + offset/blockDataSize*blockSize
+}
+
+func (d *decrypter) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		n, err := io.ReadFull(d.rc, d.buf)
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return 0, errors.WithStack(err)
+		}
+		if n > 0 {
+			if n <= blockHeaderSize {
+				return 0, errors.New("the size of the encrypted block is invalid")
+			}
+			out, ok := secretbox.Open(d.out[:0], d.buf[:n], (*[nonceSize]byte)(&d.nonce), d.key)
+			if !ok {
+				return 0, errors.WithStack(errEncryptedBadBlock)
+			}
+			d.nonce.add(1)
+			d.out = out
+			if d.skip > 0 {
+				if d.skip > len(d.out) {
+					d.skip = len(d.out)
+				}
+				d.out, d.skip = d.out[d.skip:], 0
+			}
+		}
+		d.err = err
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+func (d *decrypter) Close() error {
+	return d.rc.Close()
+}
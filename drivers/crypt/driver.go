@@ -0,0 +1,215 @@
+package crypt
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Crypt encrypt the contents and the names of the files in another storage, the format is
+// the same as rclone crypt, so the files can be read by rclone too
+type Crypt struct {
+	model.Storage
+	Addition
+	cipher *cipherKeys
+}
+
+func (d *Crypt) Config() driver.Config {
+	return config
+}
+
+func (d *Crypt) GetAddition() driver.Additional {
+	return d.Addition
+}
+
+func (d *Crypt) Init(ctx context.Context, storage model.Storage) error {
+	d.Storage = storage
+	err := utils.Json.UnmarshalFromString(d.Storage.Addition, &d.Addition)
+	if err != nil {
+		return err
+	}
+	d.RemotePath = utils.StandardizePath(d.RemotePath)
+	if utils.IsSubPath(d.MountPath, d.RemotePath) {
+		return errors.New("the remote path can't be in the crypt storage itself")
+	}
+	password := d.Password
+	if password == "" {
+		// the key of the remote path is kept after the storage is deleted
+		key, err := op.GetDataKey(model.PathKeyScope(d.RemotePath))
+		if err != nil {
+			return err
+		}
+		password = base64.StdEncoding.EncodeToString(key)
+	}
+	d.cipher, err = newCipher(password, d.Salt, d.FilenameEncryption, d.FilenameEncoding, d.DirectoryNameEncryption)
+	return err
+}
+
+func (d *Crypt) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *Crypt) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "root",
+			Path:     "/",
+			Modified: d.Modified,
+			IsFolder: true,
+		}, nil
+	}
+	dir, name := stdpath.Split(path)
+	storage, dirPath, err := d.getRemote(dir)
+	if err != nil {
+		return nil, err
+	}
+	// the name of the dir is different from the file with the same name
+	for _, remoteName := range []string{d.cipher.EncryptName(name, false), d.cipher.EncryptName(name, true)} {
+		obj, err := op.Get(ctx, storage, stdpath.Join(dirPath, remoteName))
+		if err != nil {
+			if errs.IsObjectNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if res, ok := d.convert(utils.StandardizePath(dir), obj); ok {
+			return res, nil
+		}
+	}
+	return nil, errors.WithStack(errs.ObjectNotFound)
+}
+
+func (d *Crypt) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	storage, dirPath, err := d.getRemote(dir.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	objs, err := op.List(ctx, storage, dirPath, model.ListArgs{})
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.Obj, 0, len(objs))
+	for _, obj := range objs {
+		// the objects not encrypted are ignored as rclone does
+		if o, ok := d.convert(dir.GetPath(), obj); ok {
+			res = append(res, o)
+		} else {
+			log.Debugf("ignore the object not encrypted: %s", stdpath.Join(dirPath, obj.GetName()))
+		}
+	}
+	return res, nil
+}
+
+func (d *Crypt) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	storage, path, err := d.getRemoteFile(file.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	rc, err := d.openDecrypted(ctx, storage, path, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Link{
+		Data: rc,
+		RangeReader: func(offset int64) (io.ReadCloser, error) {
+			return d.openDecrypted(ctx, storage, path, offset)
+		},
+	}, nil
+}
+
+func (d *Crypt) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	storage, dirPath, err := d.getRemote(stdpath.Join(parentDir.GetPath(), dirName))
+	if err != nil {
+		return err
+	}
+	return op.MakeDir(ctx, storage, dirPath)
+}
+
+// remotePath get the actual path of the object in the remote
+func (d *Crypt) remotePath(obj model.Obj) (driver.Driver, string, error) {
+	if obj.IsDir() {
+		return d.getRemote(obj.GetPath())
+	}
+	return d.getRemoteFile(obj.GetPath())
+}
+
+// getSrcAndDst get the actual paths in the remote, they must be in the same storage
+func (d *Crypt) getSrcAndDst(srcObj, dstDir model.Obj) (driver.Driver, string, string, error) {
+	storage, srcPath, err := d.remotePath(srcObj)
+	if err != nil {
+		return nil, "", "", err
+	}
+	dstStorage, dstDirPath, err := d.getRemote(dstDir.GetPath())
+	if err != nil {
+		return nil, "", "", err
+	}
+	if storage.GetStorage().MountPath != dstStorage.GetStorage().MountPath {
+		return nil, "", "", errors.WithStack(errs.NotSupport)
+	}
+	return storage, srcPath, dstDirPath, nil
+}
+
+func (d *Crypt) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	storage, srcPath, dstDirPath, err := d.getSrcAndDst(srcObj, dstDir)
+	if err != nil {
+		return err
+	}
+	return op.Move(ctx, storage, srcPath, dstDirPath)
+}
+
+func (d *Crypt) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	storage, srcPath, err := d.remotePath(srcObj)
+	if err != nil {
+		return err
+	}
+	return op.Rename(ctx, storage, srcPath, d.cipher.EncryptName(newName, srcObj.IsDir()))
+}
+
+func (d *Crypt) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	storage, srcPath, dstDirPath, err := d.getSrcAndDst(srcObj, dstDir)
+	if err != nil {
+		return err
+	}
+	return op.Copy(ctx, storage, srcPath, dstDirPath)
+}
+
+func (d *Crypt) Remove(ctx context.Context, obj model.Obj) error {
+	storage, path, err := d.remotePath(obj)
+	if err != nil {
+		return err
+	}
+	return op.Remove(ctx, storage, path)
+}
+
+func (d *Crypt) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	storage, dirPath, err := d.getRemote(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	r, err := d.cipher.newEncrypter(stream)
+	if err != nil {
+		return err
+	}
+	// the stream is closed by the caller, so the encrypted one mustn't close it again
+	return op.Put(ctx, storage, dirPath, &model.FileStream{
+		Obj: &model.Object{
+			Name:     d.cipher.EncryptName(stream.GetName(), false),
+			Size:     EncryptedSize(stream.GetSize()),
+			Modified: stream.ModTime(),
+		},
+		ReadCloser: io.NopCloser(r),
+		Mimetype:   "application/octet-stream",
+	}, up)
+}
+
+var _ driver.Driver = (*Crypt)(nil)
+var _ driver.Getter = (*Crypt)(nil)
@@ -0,0 +1,31 @@
+package crypt
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	// the mount path of the storage where the encrypted files are, such as /aliyun/crypt
+	RemotePath              string `json:"remote_path" required:"true" help:"the path in alist where the encrypted files are stored"`
+	Password                string `json:"password" help:"the password of rclone crypt, not obscured. if empty, a random key kept by alist is used, and the files can't be read by rclone" secret:"true"`
+	Salt                    string `json:"salt" help:"the password2 of rclone crypt, not obscured, the default salt of rclone is used if empty" secret:"true"`
+	FilenameEncryption      string `json:"filename_encryption" type:"select" options:"standard,off" default:"standard"`
+	FilenameEncoding        string `json:"filename_encoding" type:"select" options:"base32,base64" default:"base32"`
+	DirectoryNameEncryption bool   `json:"directory_name_encryption" default:"true"`
+}
+
+var config = driver.Config{
+	Name:                   "Crypt",
+	LocalSort:              true,
+	OnlyProxy:              true,
+	NoCache:                true,
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
+}
+
+func init() {
+	op.RegisterDriver(config, func() driver.Driver {
+		return &Crypt{}
+	})
+}
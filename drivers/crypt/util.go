@@ -0,0 +1,152 @@
+package crypt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/pkg/errors"
+)
+
+// getRemote get the storage and the actual path of the encrypted path in the remote
+func (d *Crypt) getRemote(path string) (driver.Driver, string, error) {
+	encrypted := d.cipher.EncryptPath(path)
+	return op.GetStorageAndActualPath(stdpath.Join(d.RemotePath, encrypted))
+}
+
+// getRemoteFile get the storage and the actual path of the encrypted file in the remote
+func (d *Crypt) getRemoteFile(path string) (driver.Driver, string, error) {
+	dir, name := stdpath.Split(path)
+	storage, dirPath, err := d.getRemote(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	return storage, stdpath.Join(dirPath, d.cipher.EncryptName(name, false)), nil
+}
+
+// convert the object in the remote to the decrypted one in the dir, false if it isn't encrypted
+func (d *Crypt) convert(dirPath string, obj model.Obj) (model.Obj, bool) {
+	name, err := d.cipher.DecryptName(obj.GetName(), obj.IsDir())
+	if err != nil {
+		return nil, false
+	}
+	size := obj.GetSize()
+	if !obj.IsDir() {
+		if size, err = DecryptedSize(size); err != nil {
+			return nil, false
+		}
+	}
+	return &model.Object{
+		Name:     name,
+		Path:     stdpath.Join(dirPath, name),
+		Size:     size,
+		Modified: obj.ModTime(),
+		IsFolder: obj.IsDir(),
+	}, true
+}
+
+// openRemote read the encrypted file in the remote from the offset
+func (d *Crypt) openRemote(ctx context.Context, storage driver.Driver, path string, offset int64) (io.ReadCloser, error) {
+	link, _, err := op.Link(ctx, storage, path, model.LinkArgs{})
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case link.FilePath != nil && *link.FilePath != "":
+		f, err := os.Open(*link.FilePath)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if _, err = f.Seek(offset, io.SeekStart); err != nil {
+			_ = f.Close()
+			return nil, errors.WithStack(err)
+		}
+		return f, nil
+	case link.Data != nil:
+		if link.RangeReader != nil && offset > 0 {
+			_ = link.Data.Close()
+			return link.RangeReader(offset)
+		}
+		if _, err = io.CopyN(io.Discard, link.Data, offset); err != nil {
+			_ = link.Data.Close()
+			return nil, errors.WithStack(err)
+		}
+		return link.Data, nil
+	case link.URL != "":
+		return requestRemote(ctx, link, offset)
+	}
+	return nil, errors.New("the link of the remote file is empty")
+}
+
+func requestRemote(ctx context.Context, link *model.Link, offset int64) (io.ReadCloser, error) {
+	res, err := request(ctx, link, offset)
+	if err != nil {
+		return nil, err
+	}
+	// the url may be expired
+	if res.StatusCode == http.StatusForbidden && link.Refresh != nil {
+		_ = res.Body.Close()
+		if link, err = link.Refresh(ctx); err != nil {
+			return nil, err
+		}
+		if res, err = request(ctx, link, offset); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case res.StatusCode == http.StatusPartialContent:
+		return res.Body, nil
+	case res.StatusCode == http.StatusOK:
+		// the range isn't supported by the remote
+		if _, err = io.CopyN(io.Discard, res.Body, offset); err != nil {
+			_ = res.Body.Close()
+			return nil, errors.WithStack(err)
+		}
+		return res.Body, nil
+	}
+	_ = res.Body.Close()
+	return nil, errors.Errorf("failed to read the remote file from %d: unexpected status %s", offset, res.Status)
+}
+
+func request(ctx context.Context, link *model.Link, offset int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link.URL, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for h, val := range link.Header {
+		req.Header[h] = val
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	res, err := base.HttpClient.Do(req)
+	return res, errors.WithStack(err)
+}
+
+// openDecrypted read the decrypted content of the file in the remote from the offset
+func (d *Crypt) openDecrypted(ctx context.Context, storage driver.Driver, path string, offset int64) (io.ReadCloser, error) {
+	rc, err := d.openRemote(ctx, storage, path, 0)
+	if err != nil {
+		return nil, err
+	}
+	n, err := readNonce(rc)
+	if err != nil {
+		_ = rc.Close()
+		return nil, err
+	}
+	// request again from the block if it's not the first one
+	if offset >= blockDataSize {
+		_ = rc.Close()
+		if rc, err = d.openRemote(ctx, storage, path, blockOffset(offset)); err != nil {
+			return nil, err
+		}
+	}
+	return d.cipher.newDecrypter(rc, n, offset), nil
+}
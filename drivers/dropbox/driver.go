@@ -0,0 +1,150 @@
+package dropbox
+
+import (
+	"context"
+	"errors"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type Dropbox struct {
+	model.Storage
+	Addition
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+func (d *Dropbox) Config() driver.Config {
+	return config
+}
+
+func (d *Dropbox) GetAddition() driver.Additional {
+	return d.Addition
+}
+
+func (d *Dropbox) Init(ctx context.Context, storage model.Storage) error {
+	d.Storage = storage
+	err := utils.Json.UnmarshalFromString(d.Storage.Addition, &d.Addition)
+	if err != nil {
+		return err
+	}
+	if d.RefreshToken == "" {
+		if d.AuthorizationCode == "" {
+			return errors.New("the refresh token or the authorization code is required")
+		}
+		return d.getToken()
+	}
+	return d.refreshToken()
+}
+
+func (d *Dropbox) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *Dropbox) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	files, err := d.getFiles(dir.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(files, func(src File) (model.Obj, error) {
+		return fileToObj(src), nil
+	})
+}
+
+func (d *Dropbox) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	var resp struct {
+		Link string `json:"link"`
+	}
+	_, err := d.request(apiUrl+"/files/get_temporary_link", base.Json{"path": file.GetPath()}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	// the temporary link expires in 4 hours
+	exp := 4*time.Hour - time.Minute
+	return &model.Link{
+		URL:        resp.Link,
+		Expiration: &exp,
+	}, nil
+}
+
+func (d *Dropbox) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	_, err := d.request(apiUrl+"/files/create_folder_v2", base.Json{
+		"path":       stdpath.Join(parentDir.GetPath(), dirName),
+		"autorename": false,
+	}, nil)
+	return err
+}
+
+func (d *Dropbox) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.relocate("/files/move_v2", srcObj.GetPath(), stdpath.Join(dstDir.GetPath(), srcObj.GetName()))
+}
+
+func (d *Dropbox) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	return d.relocate("/files/move_v2", srcObj.GetPath(), stdpath.Join(stdpath.Dir(srcObj.GetPath()), newName))
+}
+
+func (d *Dropbox) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.relocate("/files/copy_v2", srcObj.GetPath(), stdpath.Join(dstDir.GetPath(), srcObj.GetName()))
+}
+
+func (d *Dropbox) Remove(ctx context.Context, obj model.Obj) error {
+	_, err := d.request(apiUrl+"/files/delete_v2", base.Json{"path": obj.GetPath()}, nil)
+	return err
+}
+
+func (d *Dropbox) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	path := stdpath.Join(dstDir.GetPath(), stream.GetName())
+	if stream.GetSize() <= singleUploadLimit {
+		err := d.upload(ctx, path, stream)
+		if err == nil {
+			up(100)
+		}
+		return err
+	}
+	return d.uploadSession(ctx, path, stream, up)
+}
+
+func (d *Dropbox) GetQuota(ctx context.Context) (*model.StorageQuota, error) {
+	var resp SpaceUsage
+	_, err := d.request(apiUrl+"/users/get_space_usage", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	total := resp.Allocation.Allocated
+	return &model.StorageQuota{
+		Total: total,
+		Used:  resp.Used,
+		Free:  total - resp.Used,
+	}, nil
+}
+
+func (d *Dropbox) BatchMove(ctx context.Context, srcObjs []model.Obj, dstDir model.Obj) error {
+	return d.batch(ctx, "/files/move_batch_v2", "/files/move_batch/check_v2",
+		relocationEntries(srcObjs, dstDir), base.Json{"autorename": false})
+}
+
+func (d *Dropbox) BatchCopy(ctx context.Context, srcObjs []model.Obj, dstDir model.Obj) error {
+	return d.batch(ctx, "/files/copy_batch_v2", "/files/copy_batch/check_v2",
+		relocationEntries(srcObjs, dstDir), base.Json{"autorename": false})
+}
+
+func (d *Dropbox) BatchRemove(ctx context.Context, objs []model.Obj) error {
+	entries := make([]base.Json, 0, len(objs))
+	for _, obj := range objs {
+		entries = append(entries, base.Json{"path": obj.GetPath()})
+	}
+	return d.batch(ctx, "/files/delete_batch", "/files/delete_batch/check", entries, nil)
+}
+
+var _ driver.Driver = (*Dropbox)(nil)
+var _ driver.Quota = (*Dropbox)(nil)
+var _ driver.BatchMove = (*Dropbox)(nil)
+var _ driver.BatchCopy = (*Dropbox)(nil)
+var _ driver.BatchRemove = (*Dropbox)(nil)
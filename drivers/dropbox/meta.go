@@ -0,0 +1,33 @@
+package dropbox
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	driver.RootPath
+	// the app key and the app secret of the scoped app, the secret is empty for the apps using pkce
+	ClientID     string `json:"client_id" required:"true" help:"the app key"`
+	ClientSecret string `json:"client_secret" secret:"true" help:"the app secret, it's empty if the refresh token is got by pkce"`
+	RefreshToken string `json:"refresh_token" secret:"true" help:"it's got by the authorization code if it's empty"`
+	// the code got from https://www.dropbox.com/oauth2/authorize?client_id=<app key>&response_type=code&token_access_type=offline,
+	// it's exchanged for the refresh token once
+	AuthorizationCode string `json:"authorization_code" help:"open https://www.dropbox.com/oauth2/authorize?client_id=<app key>&response_type=code&token_access_type=offline and paste the code, it's exchanged for the refresh token"`
+	RedirectUri       string `json:"redirect_uri" help:"the redirect uri the authorization code is got with, empty if it's got without redirecting"`
+}
+
+var config = driver.Config{
+	Name:              "Dropbox",
+	LocalSort:         true,
+	DefaultRoot:       "/",
+	SupportsRangeRead: true,
+}
+
+func New() driver.Driver {
+	return &Dropbox{}
+}
+
+func init() {
+	op.RegisterDriver(config, New)
+}
@@ -0,0 +1,74 @@
+package dropbox
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+type TokenResp struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type TokenErr struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// RespErr is the error of the api, such as the 409 of the endpoint errors
+type RespErr struct {
+	ErrorSummary string `json:"error_summary"`
+}
+
+type File struct {
+	Tag            string    `json:".tag"`
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	PathDisplay    string    `json:"path_display"`
+	Size           int64     `json:"size"`
+	ServerModified time.Time `json:"server_modified"`
+	ContentHash    string    `json:"content_hash"`
+}
+
+type ListResp struct {
+	Entries []File `json:"entries"`
+	Cursor  string `json:"cursor"`
+	HasMore bool   `json:"has_more"`
+}
+
+// BatchResp is the result of the batch operations and the checks of them
+type BatchResp struct {
+	Tag        string `json:".tag"`
+	AsyncJobID string `json:"async_job_id"`
+	Entries    []struct {
+		Tag     string                 `json:".tag"`
+		Failure map[string]interface{} `json:"failure"`
+	} `json:"entries"`
+	// the reason if the job is failed
+	Reason map[string]interface{} `json:"reason"`
+}
+
+type SpaceUsage struct {
+	Used       int64 `json:"used"`
+	Allocation struct {
+		Tag       string `json:".tag"`
+		Allocated int64  `json:"allocated"`
+	} `json:"allocation"`
+}
+
+func fileToObj(f File) *model.Object {
+	obj := &model.Object{
+		ID:       f.ID,
+		Name:     f.Name,
+		Size:     f.Size,
+		Modified: f.ServerModified,
+		IsFolder: f.Tag == "folder",
+	}
+	if f.ContentHash != "" {
+		// the hash of dropbox, the sha256 of the sha256 of the 4 MiB blocks
+		obj.Hash = model.HashInfo{Type: "dropbox", Value: f.ContentHash}
+	}
+	return obj
+}
@@ -0,0 +1,400 @@
+package dropbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// do others that not defined in Driver interface
+
+const (
+	apiUrl     = "https://api.dropboxapi.com/2"
+	contentUrl = "https://content.dropboxapi.com/2"
+	tokenUrl   = "https://api.dropboxapi.com/oauth2/token"
+
+	// the files larger than it are uploaded by the upload session, the limit of files/upload is 150 MB
+	singleUploadLimit = 150 * 1024 * 1024
+	// the size of the chunks of the upload session
+	chunkSize = 48 * 1024 * 1024
+	// the most entries of a batch operation
+	batchLimit = 1000
+)
+
+// getToken exchange the authorization code for the refresh token
+func (d *Dropbox) getToken() error {
+	form := map[string]string{
+		"grant_type": "authorization_code",
+		"code":       d.AuthorizationCode,
+		"client_id":  d.ClientID,
+	}
+	if d.ClientSecret != "" {
+		form["client_secret"] = d.ClientSecret
+	}
+	if d.RedirectUri != "" {
+		form["redirect_uri"] = d.RedirectUri
+	}
+	resp, err := d.tokenRequest(form)
+	if err != nil {
+		return err
+	}
+	if resp.RefreshToken == "" {
+		return errs.EmptyToken
+	}
+	d.RefreshToken, d.AuthorizationCode = resp.RefreshToken, ""
+	d.setAccessToken(resp)
+	op.MustSaveDriverStorage(d)
+	return nil
+}
+
+// refreshToken get the access token by the refresh token, the refresh token of dropbox doesn't change
+func (d *Dropbox) refreshToken() error {
+	form := map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": d.RefreshToken,
+		"client_id":     d.ClientID,
+	}
+	if d.ClientSecret != "" {
+		form["client_secret"] = d.ClientSecret
+	}
+	resp, err := d.tokenRequest(form)
+	if err != nil {
+		return err
+	}
+	d.setAccessToken(resp)
+	return nil
+}
+
+func (d *Dropbox) tokenRequest(form map[string]string) (*TokenResp, error) {
+	var resp TokenResp
+	var e TokenErr
+	_, err := base.RestyClient.R().SetResult(&resp).SetError(&e).SetFormData(form).Post(tokenUrl)
+	if err != nil {
+		return nil, err
+	}
+	if e.Error != "" {
+		return nil, fmt.Errorf("%s: %s", e.Error, e.ErrorDescription)
+	}
+	if resp.AccessToken == "" {
+		return nil, errs.EmptyToken
+	}
+	return &resp, nil
+}
+
+func (d *Dropbox) setAccessToken(resp *TokenResp) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.accessToken = resp.AccessToken
+	// refresh it a minute before it expires
+	d.expiresAt = time.Now().Add(time.Duration(resp.ExpiresIn)*time.Second - time.Minute)
+}
+
+// getAccessToken get the access token, it's refreshed if it expires
+func (d *Dropbox) getAccessToken() (string, error) {
+	d.mu.Lock()
+	token, expiresAt := d.accessToken, d.expiresAt
+	d.mu.Unlock()
+	if token != "" && time.Now().Before(expiresAt) {
+		return token, nil
+	}
+	if err := d.refreshToken(); err != nil {
+		return "", err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.accessToken, nil
+}
+
+// apiArg encode the argument of the content endpoints in the Dropbox-API-Arg header,
+// the characters not in ascii must be escaped in the header
+func apiArg(v interface{}) (string, error) {
+	data, err := utils.Json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, r := range string(data) {
+		if r < 0x80 {
+			sb.WriteRune(r)
+		} else if r > 0xffff {
+			r1, r2 := utf16Pair(r)
+			fmt.Fprintf(&sb, "\\u%04x\\u%04x", r1, r2)
+		} else {
+			fmt.Fprintf(&sb, "\\u%04x", r)
+		}
+	}
+	return sb.String(), nil
+}
+
+func utf16Pair(r rune) (rune, rune) {
+	r -= 0x10000
+	return 0xd800 + (r>>10)&0x3ff, 0xdc00 + r&0x3ff
+}
+
+// request call the rpc endpoint with the json argument, the argument is nil if the endpoint has no argument
+func (d *Dropbox) request(url string, arg interface{}, resp interface{}) ([]byte, error) {
+	for i := 0; ; i++ {
+		token, err := d.getAccessToken()
+		if err != nil {
+			return nil, err
+		}
+		req := base.RestyClient.R()
+		req.SetHeader("Authorization", "Bearer "+token)
+		if arg != nil {
+			req.SetBody(arg)
+		} else {
+			req.SetHeader("Content-Type", "application/json")
+			req.SetBody("null")
+		}
+		if resp != nil {
+			req.SetResult(resp)
+		}
+		var e RespErr
+		req.SetError(&e)
+		res, err := req.Post(url)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode() == http.StatusUnauthorized && i == 0 {
+			// the access token is revoked or expired, refresh it once
+			d.resetAccessToken()
+			continue
+		}
+		if res.IsError() {
+			if e.ErrorSummary != "" {
+				return nil, fmt.Errorf("dropbox: %s", e.ErrorSummary)
+			}
+			return nil, fmt.Errorf("dropbox: %s %s", res.Status(), res.String())
+		}
+		return res.Body(), nil
+	}
+}
+
+func (d *Dropbox) resetAccessToken() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.accessToken = ""
+}
+
+// contentRequest upload the content of `size` to the content endpoint, the argument is in the Dropbox-API-Arg header
+func (d *Dropbox) contentRequest(ctx context.Context, url string, arg interface{}, content io.Reader, size int64, resp interface{}) error {
+	token, err := d.getAccessToken()
+	if err != nil {
+		return err
+	}
+	a, err := apiArg(arg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, content)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Dropbox-API-Arg", a)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode >= 400 {
+		if res.StatusCode == http.StatusUnauthorized {
+			d.resetAccessToken()
+		}
+		var e RespErr
+		if utils.Json.Unmarshal(data, &e) == nil && e.ErrorSummary != "" {
+			return fmt.Errorf("dropbox: %s", e.ErrorSummary)
+		}
+		return fmt.Errorf("dropbox: %s %s", res.Status, data)
+	}
+	if resp != nil {
+		return utils.Json.Unmarshal(data, resp)
+	}
+	return nil
+}
+
+// apiPath get the path of dropbox, the root is "" instead of "/"
+func apiPath(path string) string {
+	if path == "/" {
+		return ""
+	}
+	return path
+}
+
+func (d *Dropbox) getFiles(path string) ([]File, error) {
+	var files []File
+	var resp ListResp
+	_, err := d.request(apiUrl+"/files/list_folder", base.Json{
+		"path":  apiPath(path),
+		"limit": 2000,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, resp.Entries...)
+	for resp.HasMore {
+		cursor := resp.Cursor
+		resp = ListResp{}
+		_, err = d.request(apiUrl+"/files/list_folder/continue", base.Json{"cursor": cursor}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, resp.Entries...)
+	}
+	return files, nil
+}
+
+// upload upload the small file in one request
+func (d *Dropbox) upload(ctx context.Context, path string, stream model.FileStreamer) error {
+	return d.contentRequest(ctx, contentUrl+"/files/upload", base.Json{
+		"path":       path,
+		"mode":       "overwrite",
+		"autorename": false,
+		"mute":       true,
+	}, stream, stream.GetSize(), nil)
+}
+
+// uploadSession upload the large file by the chunks of the upload session
+func (d *Dropbox) uploadSession(ctx context.Context, path string, stream model.FileStreamer, up driver.UpdateProgress) error {
+	size := stream.GetSize()
+	buf := make([]byte, chunkSize)
+	var sessionID string
+	var offset int64
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		n := int64(chunkSize)
+		if left := size - offset; left < n {
+			n = left
+		}
+		if _, err := io.ReadFull(stream, buf[:n]); err != nil {
+			return err
+		}
+		chunk := bytes.NewReader(buf[:n])
+		last := offset+n >= size
+		var err error
+		switch {
+		case sessionID == "":
+			var resp struct {
+				SessionID string `json:"session_id"`
+			}
+			err = d.contentRequest(ctx, contentUrl+"/files/upload_session/start", base.Json{"close": false}, chunk, n, &resp)
+			sessionID = resp.SessionID
+			if err == nil && sessionID == "" {
+				err = fmt.Errorf("dropbox: empty session id")
+			}
+		case !last:
+			err = d.contentRequest(ctx, contentUrl+"/files/upload_session/append_v2", base.Json{
+				"cursor": base.Json{"session_id": sessionID, "offset": offset},
+				"close":  false,
+			}, chunk, n, nil)
+		}
+		if err != nil {
+			return err
+		}
+		if last {
+			// the last chunk is uploaded with finishing, unless it's the first one
+			var rest io.Reader = bytes.NewReader(nil)
+			restSize, finishOffset := int64(0), offset+n
+			if offset > 0 {
+				rest, restSize, finishOffset = chunk, n, offset
+			}
+			err = d.contentRequest(ctx, contentUrl+"/files/upload_session/finish", base.Json{
+				"cursor": base.Json{"session_id": sessionID, "offset": finishOffset},
+				"commit": base.Json{
+					"path":       path,
+					"mode":       "overwrite",
+					"autorename": false,
+					"mute":       true,
+				},
+			}, rest, restSize, nil)
+			if err == nil {
+				up(100)
+			}
+			return err
+		}
+		offset += n
+		up(int(offset * 100 / size))
+	}
+}
+
+// batch run the batch operation of the entries and wait for it completing, at most batchLimit entries a call
+func (d *Dropbox) batch(ctx context.Context, endpoint, checkEndpoint string, entries []base.Json, extra base.Json) error {
+	for len(entries) > 0 {
+		n := len(entries)
+		if n > batchLimit {
+			n = batchLimit
+		}
+		body := base.Json{"entries": entries[:n]}
+		for k, v := range extra {
+			body[k] = v
+		}
+		entries = entries[n:]
+		var resp BatchResp
+		if _, err := d.request(apiUrl+endpoint, body, &resp); err != nil {
+			return err
+		}
+		for resp.Tag == "async_job_id" || resp.Tag == "in_progress" {
+			jobID := resp.AsyncJobID
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+			}
+			resp = BatchResp{}
+			if _, err := d.request(apiUrl+checkEndpoint, base.Json{"async_job_id": jobID}, &resp); err != nil {
+				return err
+			}
+			resp.AsyncJobID = jobID
+		}
+		if resp.Tag == "failed" {
+			return fmt.Errorf("dropbox: the batch is failed: %v", resp.Reason)
+		}
+		for _, e := range resp.Entries {
+			if e.Tag == "failure" {
+				return fmt.Errorf("dropbox: failed %s: %v", endpoint, e.Failure)
+			}
+		}
+	}
+	return nil
+}
+
+// relocate move or copy the file or the dir from `from` to `to`
+func (d *Dropbox) relocate(endpoint, from, to string) error {
+	_, err := d.request(apiUrl+endpoint, base.Json{
+		"from_path":  from,
+		"to_path":    to,
+		"autorename": false,
+	}, nil)
+	return err
+}
+
+func relocationEntries(srcObjs []model.Obj, dstDir model.Obj) []base.Json {
+	entries := make([]base.Json, 0, len(srcObjs))
+	for _, obj := range srcObjs {
+		entries = append(entries, base.Json{
+			"from_path": obj.GetPath(),
+			"to_path":   stdpath.Join(dstDir.GetPath(), obj.GetName()),
+		})
+	}
+	return entries
+}
@@ -2,6 +2,7 @@ package ftp
 
 import (
 	"context"
+	"io"
 	stdpath "path"
 
 	"github.com/alist-org/alist/v3/internal/driver"
@@ -81,6 +82,9 @@ func (d *FTP) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*m
 	}
 	return &model.Link{
 		Data: resp,
+		RangeReader: func(offset int64) (io.ReadCloser, error) {
+			return d.conn.RetrFrom(file.GetPath(), uint64(offset))
+		},
 	}, nil
 }
 
@@ -8,15 +8,16 @@ import (
 type Addition struct {
 	Address  string `json:"address" required:"true"`
 	Username string `json:"username" required:"true"`
-	Password string `json:"password" required:"true"`
+	Password string `json:"password" required:"true" secret:"true"`
 	driver.RootPath
 }
 
 var config = driver.Config{
-	Name:        "FTP",
-	LocalSort:   true,
-	OnlyLocal:   true,
-	DefaultRoot: "/",
+	Name:              "FTP",
+	LocalSort:         true,
+	OnlyLocal:         true,
+	DefaultRoot:       "/",
+	SupportsRangeRead: true,
 }
 
 func New() driver.Driver {
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
 
 	"github.com/alist-org/alist/v3/drivers/base"
 	"github.com/alist-org/alist/v3/internal/driver"
@@ -17,6 +20,8 @@ type GoogleDrive struct {
 	model.Storage
 	Addition
 	AccessToken string
+	// the type of the native files to the extension they are exported as
+	exports map[string]string
 }
 
 func (d *GoogleDrive) Config() driver.Config {
@@ -33,6 +38,9 @@ func (d *GoogleDrive) Init(ctx context.Context, storage model.Storage) error {
 	if err != nil {
 		return err
 	}
+	if d.exports, err = parseExportFormats(d.ExportFormats); err != nil {
+		return err
+	}
 	return d.refreshToken()
 }
 
@@ -41,13 +49,34 @@ func (d *GoogleDrive) Drop(ctx context.Context) error {
 }
 
 func (d *GoogleDrive) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
-	files, err := d.getFiles(dir.GetID())
+	id := targetID(dir)
+	if id == sharedDrivesID {
+		return d.getSharedDrives(ctx)
+	}
+	files, err := d.getFiles(id)
 	if err != nil {
 		return nil, err
 	}
-	return utils.SliceConvert(files, func(src File) (model.Obj, error) {
-		return fileToObj(src), nil
-	})
+	objs := make([]model.Obj, 0, len(files)+1)
+	for _, f := range files {
+		obj := fileToObj(f)
+		// the size of the target of the shortcut is shown
+		if obj.TargetID != "" && !obj.IsFolder {
+			if target, err := d.getFile(ctx, obj.TargetID, "size,modifiedTime"); err == nil {
+				obj.Size, _ = strconv.ParseInt(target.Size, 10, 64)
+				obj.Modified = target.ModifiedTime
+			}
+		}
+		// the native files are named with the extensions they are exported as
+		if ext := d.exportExt(obj.MimeType); ext != "" {
+			obj.Name += "." + ext
+		}
+		objs = append(objs, obj)
+	}
+	if d.SharedDrives && id == d.RootFolderID {
+		objs = append(objs, &model.Object{ID: sharedDrivesID, Name: sharedDrivesName, IsFolder: true})
+	}
+	return objs, nil
 }
 
 //func (d *GoogleDrive) Get(ctx context.Context, path string) (model.Obj, error) {
@@ -55,10 +84,40 @@ func (d *GoogleDrive) List(ctx context.Context, dir model.Obj, args model.ListAr
 //	return nil, errs.NotImplement
 //}
 
+func (d *GoogleDrive) GetQuota(ctx context.Context) (*model.StorageQuota, error) {
+	var about About
+	_, err := d.request("https://www.googleapis.com/drive/v3/about", http.MethodGet, func(req *resty.Request) {
+		req.SetQueryParam("fields", "storageQuota")
+	}, &about)
+	if err != nil {
+		return nil, err
+	}
+	// limit is empty if the storage is unlimited
+	total, _ := strconv.ParseInt(about.StorageQuota.Limit, 10, 64)
+	used, _ := strconv.ParseInt(about.StorageQuota.Usage, 10, 64)
+	quota := &model.StorageQuota{
+		Total: total,
+		Used:  used,
+	}
+	if total > used {
+		quota.Free = total - used
+	}
+	return quota, nil
+}
+
 func (d *GoogleDrive) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
-	url := fmt.Sprintf("https://www.googleapis.com/drive/v3/files/%s?includeItemsFromAllDrives=true&supportsAllDrives=true", file.GetID())
+	id := targetID(file)
+	url := fmt.Sprintf("https://www.googleapis.com/drive/v3/files/%s?includeItemsFromAllDrives=true&supportsAllDrives=true", id) + "&alt=media"
+	// the native files can't be downloaded, they are exported
+	if o, ok := file.(*Obj); ok && strings.HasPrefix(o.MimeType, nativeMimePrefix) {
+		ext := d.exportExt(o.MimeType)
+		if ext == "" {
+			return nil, fmt.Errorf("%w: the file of %s can't be exported", errs.NotSupport, o.MimeType)
+		}
+		url = fmt.Sprintf("https://www.googleapis.com/drive/v3/files/%s/export?mimeType=%s", id, neturl.QueryEscape(exportMimeTypes[ext]))
+	}
 	link := model.Link{
-		URL: url + "&alt=media",
+		URL: url,
 		Header: http.Header{
 			"Authorization": []string{"Bearer " + d.AccessToken},
 		},
@@ -67,9 +126,12 @@ func (d *GoogleDrive) Link(ctx context.Context, file model.Obj, args model.LinkA
 }
 
 func (d *GoogleDrive) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	if targetID(parentDir) == sharedDrivesID {
+		return errs.NotSupport
+	}
 	data := base.Json{
 		"name":     dirName,
-		"parents":  []string{parentDir.GetID()},
+		"parents":  []string{targetID(parentDir)},
 		"mimeType": "application/vnd.google-apps.folder",
 	}
 	_, err := d.request("https://www.googleapis.com/drive/v3/files", http.MethodPost, func(req *resty.Request) {
@@ -79,18 +141,31 @@ func (d *GoogleDrive) MakeDir(ctx context.Context, parentDir model.Obj, dirName
 }
 
 func (d *GoogleDrive) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	if targetID(dstDir) == sharedDrivesID {
+		return errs.NotSupport
+	}
+	src, err := d.getFile(ctx, srcObj.GetID(), "parents")
+	if err != nil {
+		return err
+	}
 	query := map[string]string{
-		"addParents":    dstDir.GetID(),
-		"removeParents": "root",
+		"addParents":    targetID(dstDir),
+		"removeParents": strings.Join(src.Parents, ","),
 	}
 	url := "https://www.googleapis.com/drive/v3/files/" + srcObj.GetID()
-	_, err := d.request(url, http.MethodPatch, func(req *resty.Request) {
+	_, err = d.request(url, http.MethodPatch, func(req *resty.Request) {
 		req.SetQueryParams(query)
 	}, nil)
 	return err
 }
 
 func (d *GoogleDrive) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	// the extensions of the native files are added by alist
+	if o, ok := srcObj.(*Obj); ok {
+		if ext := d.exportExt(o.MimeType); ext != "" {
+			newName = strings.TrimSuffix(newName, "."+ext)
+		}
+	}
 	data := base.Json{
 		"name": newName,
 	}
@@ -112,9 +187,12 @@ func (d *GoogleDrive) Remove(ctx context.Context, obj model.Obj) error {
 }
 
 func (d *GoogleDrive) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	if targetID(dstDir) == sharedDrivesID {
+		return errs.NotSupport
+	}
 	data := base.Json{
 		"name":    stream.GetName(),
-		"parents": []string{dstDir.GetID()},
+		"parents": []string{targetID(dstDir)},
 	}
 	var e Error
 	url := "https://www.googleapis.com/upload/drive/v3/files?uploadType=resumable&supportsAllDrives=true"
@@ -141,4 +219,86 @@ func (d *GoogleDrive) Put(ctx context.Context, dstDir model.Obj, stream model.Fi
 	return err
 }
 
+func (d *GoogleDrive) ListTrash(ctx context.Context) ([]model.Obj, error) {
+	files, err := d.getTrashFiles()
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(files, func(src File) (model.Obj, error) {
+		return fileToObj(src), nil
+	})
+}
+
+func (d *GoogleDrive) Restore(ctx context.Context, obj model.Obj) error {
+	url := "https://www.googleapis.com/drive/v3/files/" + obj.GetID()
+	_, err := d.request(url, http.MethodPatch, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"trashed": false,
+		})
+	}, nil)
+	return err
+}
+
+func (d *GoogleDrive) Purge(ctx context.Context, obj model.Obj) error {
+	url := "https://www.googleapis.com/drive/v3/files/" + obj.GetID()
+	_, err := d.request(url, http.MethodDelete, nil, nil)
+	return err
+}
+
+// Changes get the parents of the files changed with the changes api, the page token is the cursor.
+// the parents of the files deleted permanently are unknown, they are found by the reconciliation of the index
+func (d *GoogleDrive) Changes(ctx context.Context, cursor string) ([]string, string, error) {
+	if cursor == "" {
+		var resp struct {
+			StartPageToken string `json:"startPageToken"`
+		}
+		_, err := d.request("https://www.googleapis.com/drive/v3/changes/startPageToken", http.MethodGet, func(req *resty.Request) {
+			req.SetContext(ctx)
+		}, &resp)
+		return nil, resp.StartPageToken, err
+	}
+	// the root folder id may be an alias such as `root`
+	root, err := d.getFile(ctx, d.RootFolderID, "id,name,parents")
+	if err != nil {
+		return nil, "", err
+	}
+	paths := make(map[string]string)
+	var dirs []string
+	pageToken := cursor
+	for {
+		var resp Changes
+		_, err := d.request("https://www.googleapis.com/drive/v3/changes", http.MethodGet, func(req *resty.Request) {
+			req.SetContext(ctx).SetQueryParams(map[string]string{
+				"pageToken": pageToken,
+				"pageSize":  "1000",
+				"fields":    "nextPageToken,newStartPageToken,changes(removed,file(id,name,parents))",
+			})
+		}, &resp)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, change := range resp.Changes {
+			if change.Removed || change.File == nil {
+				continue
+			}
+			for _, parent := range change.File.Parents {
+				dir, ok, err := d.dirPath(ctx, parent, root.Id, paths)
+				if err != nil {
+					return nil, "", err
+				}
+				if ok {
+					dirs = append(dirs, dir)
+				}
+			}
+		}
+		if resp.NextPageToken == "" {
+			return dirs, resp.NewStartPageToken, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
 var _ driver.Driver = (*GoogleDrive)(nil)
+var _ driver.Quota = (*GoogleDrive)(nil)
+var _ driver.Trash = (*GoogleDrive)(nil)
+var _ driver.ChangeNotifier = (*GoogleDrive)(nil)
@@ -0,0 +1,63 @@
+package google_drive
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	folderMimeType = "application/vnd.google-apps.folder"
+	// the prefix of the mime types of the google docs, sheets, slides and the others native to google drive
+	nativeMimePrefix = "application/vnd.google-apps."
+	// the id of the virtual folder listing the shared drives
+	sharedDrivesID   = "shared-drives"
+	sharedDrivesName = "Shared drives"
+)
+
+// the mime types of the formats the native files can be exported as
+var exportMimeTypes = map[string]string{
+	"docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	"odt":  "application/vnd.oasis.opendocument.text",
+	"ods":  "application/x-vnd.oasis.opendocument.spreadsheet",
+	"odp":  "application/vnd.oasis.opendocument.presentation",
+	"pdf":  "application/pdf",
+	"txt":  "text/plain",
+	"csv":  "text/csv",
+	"html": "text/html",
+	"rtf":  "application/rtf",
+	"epub": "application/epub+zip",
+	"png":  "image/png",
+	"jpg":  "image/jpeg",
+	"svg":  "image/svg+xml",
+}
+
+// parseExportFormats parse the formats like document:docx,spreadsheet:xlsx to the map
+// from the type of the native files to the extension
+func parseExportFormats(s string) (map[string]string, error) {
+	res := make(map[string]string)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		kind, ext, ok := strings.Cut(item, ":")
+		kind, ext = strings.TrimSpace(kind), strings.ToLower(strings.TrimSpace(ext))
+		if !ok || kind == "" {
+			return nil, fmt.Errorf("invalid export format: %s", item)
+		}
+		if _, ok = exportMimeTypes[ext]; !ok {
+			return nil, fmt.Errorf("unsupported export format: %s", ext)
+		}
+		res[kind] = ext
+	}
+	return res, nil
+}
+
+// exportExt get the extension the native file of the mime type is exported as, empty if it can't be exported
+func (d *GoogleDrive) exportExt(mimeType string) string {
+	if !strings.HasPrefix(mimeType, nativeMimePrefix) || mimeType == folderMimeType {
+		return ""
+	}
+	return d.exports[strings.TrimPrefix(mimeType, nativeMimePrefix)]
+}
@@ -7,17 +7,20 @@ import (
 
 type Addition struct {
 	driver.RootID
-	RefreshToken   string `json:"refresh_token" required:"true"`
+	RefreshToken   string `json:"refresh_token" required:"true" secret:"true"`
 	OrderBy        string `json:"order_by" type:"string" help:"such as: folder,name,modifiedTime"`
 	OrderDirection string `json:"order_direction" type:"select" options:"asc,desc"`
 	ClientID       string `json:"client_id" required:"true" default:"202264815644.apps.googleusercontent.com"`
-	ClientSecret   string `json:"client_secret" required:"true" default:"X4Z3ca8xfWDb1Voo-F9a7ZxJ"`
+	ClientSecret   string `json:"client_secret" required:"true" default:"X4Z3ca8xfWDb1Voo-F9a7ZxJ" secret:"true"`
+	SharedDrives   bool   `json:"shared_drives" help:"list the shared drives in the folder named Shared drives in the root"`
+	ExportFormats  string `json:"export_formats" default:"document:docx,spreadsheet:xlsx,presentation:pdf,drawing:png" help:"the formats the google docs, sheets, slides and drawings are exported as on download"`
 }
 
 var config = driver.Config{
-	Name:        "GoogleDrive",
-	OnlyProxy:   true,
-	DefaultRoot: "root",
+	Name:              "GoogleDrive",
+	OnlyProxy:         true,
+	DefaultRoot:       "root",
+	SupportsRangeRead: true,
 }
 
 func New() driver.Driver {
@@ -24,20 +24,79 @@ type File struct {
 	ModifiedTime  time.Time `json:"modifiedTime"`
 	Size          string    `json:"size"`
 	ThumbnailLink string    `json:"thumbnailLink"`
+	// only set for the shortcuts
+	ShortcutDetails *struct {
+		TargetId       string `json:"targetId"`
+		TargetMimeType string `json:"targetMimeType"`
+	} `json:"shortcutDetails"`
+	// only requested when listing the trash
+	ExplicitlyTrashed bool `json:"explicitlyTrashed"`
+	// only requested when getting the changes
+	Parents []string `json:"parents"`
 }
 
-func fileToObj(f File) *model.ObjThumb {
+type Changes struct {
+	NextPageToken     string `json:"nextPageToken"`
+	NewStartPageToken string `json:"newStartPageToken"`
+	Changes           []struct {
+		Removed bool  `json:"removed"`
+		File    *File `json:"file"`
+	} `json:"changes"`
+}
+
+// Obj is the file of google drive, the shortcuts are resolved to the targets
+type Obj struct {
+	model.ObjThumb
+	MimeType string
+	// the id of the target of the shortcut, the target is listed and linked,
+	// but the others such as removing are on the shortcut itself. empty if it isn't a shortcut
+	TargetID string
+}
+
+// targetID get the id of the object to list or link, it's the target of the shortcut
+func targetID(obj model.Obj) string {
+	if o, ok := obj.(*Obj); ok && o.TargetID != "" {
+		return o.TargetID
+	}
+	return obj.GetID()
+}
+
+func fileToObj(f File) *Obj {
 	size, _ := strconv.ParseInt(f.Size, 10, 64)
-	return &model.ObjThumb{
-		Object: model.Object{
-			ID:       f.Id,
-			Name:     f.Name,
-			Size:     size,
-			Modified: time.Time{},
-			IsFolder: f.MimeType == "application/vnd.google-apps.folder",
+	obj := &Obj{
+		ObjThumb: model.ObjThumb{
+			Object: model.Object{
+				ID:       f.Id,
+				Name:     f.Name,
+				Size:     size,
+				Modified: f.ModifiedTime,
+				IsFolder: f.MimeType == folderMimeType,
+			},
+			Thumbnail: model.Thumbnail{Thumbnail: f.ThumbnailLink},
 		},
-		Thumbnail: model.Thumbnail{},
+		MimeType: f.MimeType,
 	}
+	if f.ShortcutDetails != nil {
+		obj.TargetID = f.ShortcutDetails.TargetId
+		obj.MimeType = f.ShortcutDetails.TargetMimeType
+		obj.IsFolder = obj.MimeType == folderMimeType
+	}
+	return obj
+}
+
+type Drives struct {
+	NextPageToken string `json:"nextPageToken"`
+	Drives        []struct {
+		Id   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"drives"`
+}
+
+type About struct {
+	StorageQuota struct {
+		Limit string `json:"limit"`
+		Usage string `json:"usage"`
+	} `json:"storageQuota"`
 }
 
 type Error struct {
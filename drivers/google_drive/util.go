@@ -1,10 +1,13 @@
 package google_drive
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	stdpath "path"
 
 	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/go-resty/resty/v2"
 	log "github.com/sirupsen/logrus"
 )
@@ -77,13 +80,17 @@ func (d *GoogleDrive) getFiles(id string) ([]File, error) {
 		}
 		query := map[string]string{
 			"orderBy":  orderBy,
-			"fields":   "files(id,name,mimeType,size,modifiedTime,thumbnailLink),nextPageToken",
+			"fields":   "files(id,name,mimeType,size,modifiedTime,thumbnailLink,shortcutDetails),nextPageToken",
 			"pageSize": "1000",
 			"q":        fmt.Sprintf("'%s' in parents and trashed = false", id),
 			//"includeItemsFromAllDrives": "true",
 			//"supportsAllDrives":         "true",
 			"pageToken": pageToken,
 		}
+		// the files in the shared drives are only listed in all the drives
+		if d.SharedDrives {
+			query["corpora"] = "allDrives"
+		}
 		_, err := d.request("https://www.googleapis.com/drive/v3/files", http.MethodGet, func(req *resty.Request) {
 			req.SetQueryParams(query)
 		}, &resp)
@@ -95,3 +102,97 @@ func (d *GoogleDrive) getFiles(id string) ([]File, error) {
 	}
 	return res, nil
 }
+
+// getTrashFiles get the files trashed explicitly,
+// the children of a trashed folder will be restored with the folder
+func (d *GoogleDrive) getTrashFiles() ([]File, error) {
+	pageToken := "first"
+	res := make([]File, 0)
+	for pageToken != "" {
+		if pageToken == "first" {
+			pageToken = ""
+		}
+		var resp Files
+		query := map[string]string{
+			"fields":    "files(id,name,mimeType,size,modifiedTime,thumbnailLink,explicitlyTrashed),nextPageToken",
+			"pageSize":  "1000",
+			"q":         "trashed = true",
+			"pageToken": pageToken,
+		}
+		_, err := d.request("https://www.googleapis.com/drive/v3/files", http.MethodGet, func(req *resty.Request) {
+			req.SetQueryParams(query)
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		pageToken = resp.NextPageToken
+		for _, f := range resp.Files {
+			if f.ExplicitlyTrashed {
+				res = append(res, f)
+			}
+		}
+	}
+	return res, nil
+}
+
+// getFile get the fields of the file, such as id,name,parents
+func (d *GoogleDrive) getFile(ctx context.Context, id, fields string) (*File, error) {
+	var file File
+	_, err := d.request("https://www.googleapis.com/drive/v3/files/"+id, http.MethodGet, func(req *resty.Request) {
+		req.SetContext(ctx).SetQueryParam("fields", fields)
+	}, &file)
+	return &file, err
+}
+
+// getSharedDrives get the shared drives of the user, they are listed as the folders
+func (d *GoogleDrive) getSharedDrives(ctx context.Context) ([]model.Obj, error) {
+	var res []model.Obj
+	pageToken := ""
+	for {
+		var resp Drives
+		_, err := d.request("https://www.googleapis.com/drive/v3/drives", http.MethodGet, func(req *resty.Request) {
+			req.SetContext(ctx).SetQueryParams(map[string]string{
+				"pageSize":  "100",
+				"pageToken": pageToken,
+			})
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		for _, drive := range resp.Drives {
+			res = append(res, &model.Object{ID: drive.Id, Name: drive.Name, IsFolder: true})
+		}
+		if resp.NextPageToken == "" {
+			return res, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+// dirPath get the path of the folder relative to the root folder, the paths
+// of the folders got are kept in `paths`, false is returned if it's out of the root folder
+func (d *GoogleDrive) dirPath(ctx context.Context, id, rootID string, paths map[string]string) (string, bool, error) {
+	if id == rootID {
+		return "/", true, nil
+	}
+	if path, ok := paths[id]; ok {
+		return path, path != "", nil
+	}
+	file, err := d.getFile(ctx, id, "id,name,parents")
+	if err != nil {
+		return "", false, err
+	}
+	path := ""
+	// the folder of the shared drives may have no parents
+	if len(file.Parents) > 0 {
+		parent, ok, err := d.dirPath(ctx, file.Parents[0], rootID, paths)
+		if err != nil {
+			return "", false, err
+		}
+		if ok {
+			path = stdpath.Join(parent, file.Name)
+		}
+	}
+	paths[id] = path
+	return path, path != "", nil
+}
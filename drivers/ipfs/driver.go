@@ -0,0 +1,164 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type IPFS struct {
+	model.Storage
+	Addition
+}
+
+func (d *IPFS) Config() driver.Config {
+	return config
+}
+
+func (d *IPFS) GetAddition() driver.Additional {
+	return d.Addition
+}
+
+func (d *IPFS) Init(ctx context.Context, storage model.Storage) error {
+	d.Storage = storage
+	err := utils.Json.UnmarshalFromString(d.Storage.Addition, &d.Addition)
+	if err != nil {
+		return err
+	}
+	if d.APIAddress == "" {
+		if d.Gateway == "" {
+			return errors.New("the api address or the gateway is required")
+		}
+		if !strings.HasPrefix(d.GetRootPath(), "/ipfs/") && !strings.HasPrefix(d.GetRootPath(), "/ipns/") {
+			return errors.New("the root must be /ipfs/<cid> or /ipns/<name> without the api")
+		}
+		_, err = d.block(ctx, d.GetRootPath())
+		return err
+	}
+	_, err = d.stat(ctx, d.GetRootPath())
+	return err
+}
+
+func (d *IPFS) Drop(ctx context.Context) error {
+	return nil
+}
+
+// writable the storage is writable only with the api
+func (d *IPFS) writable() error {
+	if d.APIAddress == "" {
+		return errs.NotSupport
+	}
+	return nil
+}
+
+func (d *IPFS) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	if d.APIAddress == "" {
+		return d.listGateway(ctx, dir.GetPath())
+	}
+	return d.listMFS(ctx, dir.GetPath())
+}
+
+func (d *IPFS) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	if d.APIAddress == "" {
+		return &model.Link{URL: d.gatewayUrl(file.GetPath()) + "?filename=" + url.QueryEscape(file.GetName())}, nil
+	}
+	st, err := d.stat(ctx, file.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	if d.Gateway != "" {
+		return &model.Link{URL: d.gatewayUrl("/ipfs/"+st.Hash) + "?filename=" + url.QueryEscape(file.GetName())}, nil
+	}
+	// read by the api if there's no gateway, the context of the request isn't used as the link is read after it
+	open := func(offset int64) (io.ReadCloser, error) {
+		return d.cat(context.Background(), st.Hash, offset)
+	}
+	data, err := open(0)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Link{
+		Data:        data,
+		RangeReader: open,
+	}, nil
+}
+
+func (d *IPFS) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	if err := d.writable(); err != nil {
+		return err
+	}
+	params := args(stdpath.Join(parentDir.GetPath(), dirName))
+	params.Set("parents", "true")
+	if err := d.rpc(ctx, "files/mkdir", params, nil); err != nil {
+		return err
+	}
+	d.pinRoot(ctx)
+	return nil
+}
+
+func (d *IPFS) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.mv(ctx, srcObj.GetPath(), stdpath.Join(dstDir.GetPath(), srcObj.GetName()))
+}
+
+func (d *IPFS) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	return d.mv(ctx, srcObj.GetPath(), stdpath.Join(stdpath.Dir(srcObj.GetPath()), newName))
+}
+
+func (d *IPFS) mv(ctx context.Context, src, dst string) error {
+	if err := d.writable(); err != nil {
+		return err
+	}
+	if err := d.rpc(ctx, "files/mv", args(src, dst), nil); err != nil {
+		return err
+	}
+	d.pinRoot(ctx)
+	return nil
+}
+
+func (d *IPFS) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	if err := d.writable(); err != nil {
+		return err
+	}
+	// the copy of the mfs links the same cid, the content isn't copied
+	err := d.rpc(ctx, "files/cp", args(srcObj.GetPath(), stdpath.Join(dstDir.GetPath(), srcObj.GetName())), nil)
+	if err != nil {
+		return err
+	}
+	d.pinRoot(ctx)
+	return nil
+}
+
+func (d *IPFS) Remove(ctx context.Context, obj model.Obj) error {
+	if err := d.writable(); err != nil {
+		return err
+	}
+	params := args(obj.GetPath())
+	params.Set("recursive", "true")
+	if err := d.rpc(ctx, "files/rm", params, nil); err != nil {
+		return err
+	}
+	d.pinRoot(ctx)
+	return nil
+}
+
+func (d *IPFS) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	if err := d.writable(); err != nil {
+		return err
+	}
+	if err := d.write(ctx, stdpath.Join(dstDir.GetPath(), stream.GetName()), stream); err != nil {
+		return err
+	}
+	up(100)
+	d.pinRoot(ctx)
+	return nil
+}
+
+var _ driver.Driver = (*IPFS)(nil)
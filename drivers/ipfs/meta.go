@@ -0,0 +1,34 @@
+package ipfs
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	// the path of the mfs if the api is set, otherwise it's /ipfs/<cid> or /ipns/<name> read through the gateway
+	driver.RootPath
+	// the rpc api of the node such as kubo, the storage is read-only without it
+	APIAddress string `json:"api_address" help:"the rpc api of the node such as http://127.0.0.1:5001, the storage is writable in the mfs with it, otherwise it's read-only and the root is /ipfs/<cid> or /ipns/<name>"`
+	// the value of the Authorization header of the api, such as Basic xxx or Bearer xxx
+	APIAuth string `json:"api_auth" secret:"true" help:"the value of the Authorization header of the api if it's protected"`
+	Gateway string `json:"gateway" default:"https://ipfs.io" help:"the path gateway to list the dirs without the api and to download the files"`
+	// the remote pinning service (https://ipfs.github.io/pinning-services-api-spec/) that pins the root after the changes
+	PinningService string `json:"pinning_service" help:"the endpoint of the remote pinning service api to pin the root after the changes, such as https://api.pinata.cloud/psa"`
+	PinningToken   string `json:"pinning_token" secret:"true"`
+}
+
+var config = driver.Config{
+	Name:              "IPFS",
+	LocalSort:         true,
+	DefaultRoot:       "/",
+	SupportsRangeRead: true,
+}
+
+func New() driver.Driver {
+	return &IPFS{}
+}
+
+func init() {
+	op.RegisterDriver(config, New)
+}
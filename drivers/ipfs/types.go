@@ -0,0 +1,55 @@
+package ipfs
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+// ErrResp is the error of the rpc api
+type ErrResp struct {
+	Message string `json:"Message"`
+	Code    int    `json:"Code"`
+	Type    string `json:"Type"`
+}
+
+// MFSEntry is the entry of files/ls, Type is 0 for the files and 1 for the dirs
+type MFSEntry struct {
+	Name string `json:"Name"`
+	Type int    `json:"Type"`
+	Size int64  `json:"Size"`
+	Hash string `json:"Hash"`
+}
+
+type MFSLsResp struct {
+	Entries []MFSEntry `json:"Entries"`
+}
+
+type MFSStat struct {
+	Hash string `json:"Hash"`
+	Size int64  `json:"Size"`
+	Type string `json:"Type"`
+}
+
+type Pin struct {
+	Cid  string `json:"cid"`
+	Name string `json:"name"`
+}
+
+type PinStatus struct {
+	RequestID string `json:"requestid"`
+	Status    string `json:"status"`
+	Pin       Pin    `json:"pin"`
+}
+
+type PinResults struct {
+	Count   int         `json:"count"`
+	Results []PinStatus `json:"results"`
+}
+
+func entryToObj(e MFSEntry) *model.Object {
+	return &model.Object{
+		ID:       e.Hash,
+		Name:     e.Name,
+		Size:     e.Size,
+		IsFolder: e.Type == 1,
+	}
+}
@@ -0,0 +1,197 @@
+package ipfs
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// the dag-pb nodes and the unixfs data of them, see https://github.com/ipfs/specs/blob/main/UNIXFS.md
+
+// the types of the unixfs data
+const (
+	unixfsRaw       = 0
+	unixfsDirectory = 1
+	unixfsFile      = 2
+	unixfsMetadata  = 3
+	unixfsSymlink   = 4
+	unixfsHAMTShard = 5
+)
+
+// the codecs of the cids
+const (
+	codecRaw   = 0x55
+	codecDagPB = 0x70
+)
+
+var errInvalidNode = errors.New("ipfs: invalid dag-pb node")
+
+type pbLink struct {
+	Name  string
+	Tsize uint64
+}
+
+type unixfsNode struct {
+	Type     uint64
+	FileSize uint64
+	Links    []pbLink
+}
+
+// Size get the size of the content of the node
+func (n *unixfsNode) Size() int64 {
+	return int64(n.FileSize)
+}
+
+func (n *unixfsNode) IsDir() bool {
+	return n.Type == unixfsDirectory || n.Type == unixfsHAMTShard
+}
+
+// decodeNode decode the dag-pb block of a unixfs node
+func decodeNode(b []byte) (*unixfsNode, error) {
+	node := &unixfsNode{}
+	var data []byte
+	hasData := false
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 || typ != protowire.BytesType {
+			return nil, errInvalidNode
+		}
+		b = b[n:]
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return nil, errInvalidNode
+		}
+		b = b[n:]
+		switch num {
+		case 1:
+			data, hasData = v, true
+		case 2:
+			link, err := decodeLink(v)
+			if err != nil {
+				return nil, err
+			}
+			node.Links = append(node.Links, link)
+		default:
+			return nil, errInvalidNode
+		}
+	}
+	if !hasData {
+		return nil, errInvalidNode
+	}
+	if err := node.decodeData(data); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func decodeLink(b []byte) (pbLink, error) {
+	var link pbLink
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return link, errInvalidNode
+		}
+		b = b[n:]
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			_, n = protowire.ConsumeBytes(b)
+		case num == 2 && typ == protowire.BytesType:
+			var v []byte
+			v, n = protowire.ConsumeBytes(b)
+			link.Name = string(v)
+		case num == 3 && typ == protowire.VarintType:
+			link.Tsize, n = protowire.ConsumeVarint(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return link, errInvalidNode
+		}
+		b = b[n:]
+	}
+	return link, nil
+}
+
+func (node *unixfsNode) decodeData(b []byte) error {
+	var blockSizes, inline uint64
+	hasSize := false
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errInvalidNode
+		}
+		b = b[n:]
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			node.Type, n = protowire.ConsumeVarint(b)
+		case num == 2 && typ == protowire.BytesType:
+			var v []byte
+			v, n = protowire.ConsumeBytes(b)
+			inline = uint64(len(v))
+		case num == 3 && typ == protowire.VarintType:
+			node.FileSize, n = protowire.ConsumeVarint(b)
+			hasSize = true
+		case num == 4 && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			blockSizes += v
+		case num == 4 && typ == protowire.BytesType:
+			// the packed block sizes
+			var v []byte
+			v, n = protowire.ConsumeBytes(b)
+			for len(v) > 0 {
+				s, m := protowire.ConsumeVarint(v)
+				if m < 0 {
+					return errInvalidNode
+				}
+				blockSizes += s
+				v = v[m:]
+			}
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return errInvalidNode
+		}
+		b = b[n:]
+	}
+	if node.Type > unixfsHAMTShard {
+		return errInvalidNode
+	}
+	if !hasSize && (node.Type == unixfsFile || node.Type == unixfsRaw) {
+		node.FileSize = inline + blockSizes
+	}
+	return nil
+}
+
+// cidCodec get the codec of the cid in the Etag of the gateway, 0 if it's unknown
+func cidCodec(etag string) uint64 {
+	cid := strings.TrimPrefix(etag, "W/")
+	cid = strings.Trim(cid, `"`)
+	// such as <cid>.raw of the raw responses
+	cid, _, _ = strings.Cut(cid, ".")
+	switch {
+	case strings.HasPrefix(cid, "Qm"):
+		// the cid v0 is always dag-pb
+		return codecDagPB
+	case strings.HasPrefix(cid, "b"):
+		// the cid v1 in the lower base32
+		b, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(cid[1:]))
+		if err != nil {
+			return 0
+		}
+		version, n := binary.Uvarint(b)
+		if n <= 0 || version != 1 {
+			return 0
+		}
+		codec, m := binary.Uvarint(b[n:])
+		if m <= 0 {
+			return 0
+		}
+		return codec
+	}
+	return 0
+}
@@ -0,0 +1,262 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// do others that not defined in Driver interface
+
+// the most bytes of a block read from the gateway, the blocks are at most 1MB usually
+const maxBlockSize = 2 * 1024 * 1024
+
+// rpcRequest call the command of the rpc api, all of the commands are POST
+func (d *IPFS) rpcRequest(ctx context.Context, cmd string, params url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	u := strings.TrimSuffix(d.APIAddress, "/") + "/api/v0/" + cmd + "?" + params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if d.APIAuth != "" {
+		req.Header.Set("Authorization", d.APIAuth)
+	}
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		data, _ := io.ReadAll(res.Body)
+		var e ErrResp
+		if utils.Json.Unmarshal(data, &e) == nil && e.Message != "" {
+			if strings.Contains(e.Message, "does not exist") {
+				return nil, errs.ObjectNotFound
+			}
+			return nil, fmt.Errorf("ipfs: %s: %s", cmd, e.Message)
+		}
+		return nil, fmt.Errorf("ipfs: %s: %s %s", cmd, res.Status, data)
+	}
+	return res, nil
+}
+
+// rpc call the command and decode the json result
+func (d *IPFS) rpc(ctx context.Context, cmd string, params url.Values, resp interface{}) error {
+	res, err := d.rpcRequest(ctx, cmd, params, nil, "")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if resp == nil {
+		_, err = io.Copy(io.Discard, res.Body)
+		return err
+	}
+	return utils.Json.NewDecoder(res.Body).Decode(resp)
+}
+
+func args(values ...string) url.Values {
+	params := url.Values{}
+	for _, v := range values {
+		params.Add("arg", v)
+	}
+	return params
+}
+
+func (d *IPFS) stat(ctx context.Context, path string) (*MFSStat, error) {
+	var resp MFSStat
+	if err := d.rpc(ctx, "files/stat", args(path), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (d *IPFS) listMFS(ctx context.Context, path string) ([]model.Obj, error) {
+	params := args(path)
+	params.Set("long", "true")
+	var resp MFSLsResp
+	if err := d.rpc(ctx, "files/ls", params, &resp); err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(resp.Entries, func(src MFSEntry) (model.Obj, error) {
+		return entryToObj(src), nil
+	})
+}
+
+// write write the stream to the path of the mfs, the file is truncated if it exists
+func (d *IPFS) write(ctx context.Context, path string, stream model.FileStreamer) error {
+	r, w := io.Pipe()
+	mw := multipart.NewWriter(w)
+	go func() {
+		part, err := mw.CreateFormFile("file", stream.GetName())
+		if err == nil {
+			_, err = io.Copy(part, stream)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		_ = w.CloseWithError(err)
+	}()
+	params := args(path)
+	params.Set("create", "true")
+	params.Set("truncate", "true")
+	params.Set("parents", "true")
+	res, err := d.rpcRequest(ctx, "files/write", params, r, mw.FormDataContentType())
+	// stop the writing goroutine if the request fails before reading all of the body
+	_ = r.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	return nil
+}
+
+// cat read the content of the cid from the offset by the api
+func (d *IPFS) cat(ctx context.Context, cid string, offset int64) (io.ReadCloser, error) {
+	params := args("/ipfs/" + cid)
+	if offset > 0 {
+		params.Set("offset", fmt.Sprint(offset))
+	}
+	res, err := d.rpcRequest(ctx, "cat", params, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// gatewayUrl get the url of the path on the gateway, the path is /ipfs/<cid>/... or /ipns/<name>/...
+func (d *IPFS) gatewayUrl(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.TrimSuffix(d.Gateway, "/") + "/" + strings.Join(segments, "/")
+}
+
+// block get the unixfs node of the path from the gateway by the raw block of it
+func (d *IPFS) block(ctx context.Context, path string) (*unixfsNode, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.gatewayUrl(path)+"?format=raw", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.ipld.raw")
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, errs.ObjectNotFound
+	default:
+		return nil, fmt.Errorf("ipfs: failed get the block of %s: %s", path, res.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxBlockSize))
+	if err != nil {
+		return nil, err
+	}
+	codec := cidCodec(res.Header.Get("Etag"))
+	if codec == codecRaw {
+		return &unixfsNode{Type: unixfsRaw, FileSize: uint64(len(data))}, nil
+	}
+	node, err := decodeNode(data)
+	if err != nil {
+		if codec == codecDagPB {
+			return nil, err
+		}
+		// the codec is unknown, it's taken as a raw leaf
+		return &unixfsNode{Type: unixfsRaw, FileSize: uint64(len(data))}, nil
+	}
+	return node, nil
+}
+
+// listGateway list the dir by the gateway, the type and the size of the children are got by their blocks
+func (d *IPFS) listGateway(ctx context.Context, path string) ([]model.Obj, error) {
+	node, err := d.block(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if !node.IsDir() {
+		return nil, errs.NotFolder
+	}
+	if node.Type == unixfsHAMTShard {
+		return nil, fmt.Errorf("ipfs: the sharded dir %s can't be listed by the gateway", path)
+	}
+	objs := make([]model.Obj, 0, len(node.Links))
+	for _, link := range node.Links {
+		child, err := d.block(ctx, stdpath.Join(path, link.Name))
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, &model.Object{
+			Name:     link.Name,
+			Size:     child.Size(),
+			IsFolder: child.IsDir(),
+		})
+	}
+	return objs, nil
+}
+
+func (d *IPFS) pinRequest(method, u string, body interface{}, resp interface{}) error {
+	req := base.RestyClient.R().SetHeader("Authorization", "Bearer "+d.PinningToken)
+	if body != nil {
+		req.SetBody(body)
+	}
+	res, err := req.Execute(method, strings.TrimSuffix(d.PinningService, "/")+u)
+	if err != nil {
+		return err
+	}
+	if res.IsError() {
+		return fmt.Errorf("ipfs: the pinning service replied %s %s", res.Status(), res.String())
+	}
+	if resp != nil {
+		return utils.Json.Unmarshal(res.Body(), resp)
+	}
+	return nil
+}
+
+// pinRoot pin the cid of the root on the pinning service, the pin of the storage is replaced
+// so the files removed are unpinned too, it's logged instead of failing the change if it fails
+func (d *IPFS) pinRoot(ctx context.Context) {
+	if d.PinningService == "" {
+		return
+	}
+	if err := d.replacePin(ctx); err != nil {
+		log.Warnf("failed pin the root of the ipfs storage %s: %+v", d.MountPath, err)
+	}
+}
+
+func (d *IPFS) replacePin(ctx context.Context) error {
+	st, err := d.stat(ctx, d.GetRootPath())
+	if err != nil {
+		return err
+	}
+	pin := Pin{Cid: st.Hash, Name: "alist:" + d.MountPath}
+	var pins PinResults
+	// only the pinned ones are listed by default
+	query := url.Values{"name": {pin.Name}, "match": {"exact"}, "status": {"queued,pinning,pinned,failed"}, "limit": {"1"}}
+	if err = d.pinRequest(http.MethodGet, "/pins?"+query.Encode(), nil, &pins); err != nil {
+		return err
+	}
+	if len(pins.Results) == 0 {
+		return d.pinRequest(http.MethodPost, "/pins", pin, nil)
+	}
+	if pins.Results[0].Pin.Cid == pin.Cid {
+		return nil
+	}
+	return d.pinRequest(http.MethodPost, "/pins/"+pins.Results[0].RequestID, pin, nil)
+}
@@ -111,6 +111,10 @@ func (d *Local) Get(ctx context.Context, path string) (model.Obj, error) {
 	return &file, nil
 }
 
+func (d *Local) GetQuota(ctx context.Context) (*model.StorageQuota, error) {
+	return getDiskUsage(d.GetRootPath())
+}
+
 func (d *Local) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
 	fullPath := file.GetPath()
 	var link model.Link
@@ -218,3 +222,4 @@ func (d *Local) Put(ctx context.Context, dstDir model.Obj, stream model.FileStre
 }
 
 var _ driver.Driver = (*Local)(nil)
+var _ driver.Quota = (*Local)(nil)
@@ -11,11 +11,13 @@ type Addition struct {
 }
 
 var config = driver.Config{
-	Name:        "Local",
-	OnlyLocal:   true,
-	LocalSort:   true,
-	NoCache:     true,
-	DefaultRoot: "/",
+	Name:                   "Local",
+	OnlyLocal:              true,
+	LocalSort:              true,
+	NoCache:                true,
+	DefaultRoot:            "/",
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
 }
 
 func New() driver.Driver {
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package local
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"golang.org/x/sys/unix"
+)
+
+func getDiskUsage(path string) (*model.StorageQuota, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return nil, err
+	}
+	total := int64(stat.Blocks) * int64(stat.Bsize)
+	free := int64(stat.Bavail) * int64(stat.Bsize)
+	return &model.StorageQuota{
+		Total: total,
+		Used:  total - int64(stat.Bfree)*int64(stat.Bsize),
+		Free:  free,
+	}, nil
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package local
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"golang.org/x/sys/windows"
+)
+
+func getDiskUsage(path string) (*model.StorageQuota, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	var free, total, totalFree uint64
+	if err = windows.GetDiskFreeSpaceEx(p, &free, &total, &totalFree); err != nil {
+		return nil, err
+	}
+	return &model.StorageQuota{
+		Total: int64(total),
+		Used:  int64(total - totalFree),
+		Free:  int64(free),
+	}, nil
+}
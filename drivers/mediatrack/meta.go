@@ -6,7 +6,7 @@ import (
 )
 
 type Addition struct {
-	AccessToken string `json:"access_token" required:"true"`
+	AccessToken string `json:"access_token" required:"true" secret:"true"`
 	ProjectID   string `json:"project_id"`
 	driver.RootID
 	OrderBy   string `json:"order_by" type:"select" options:"updated_at,title,size" default:"title"`
@@ -14,7 +14,10 @@ type Addition struct {
 }
 
 var config = driver.Config{
-	Name: "MediaTrack",
+	Name:                   "MediaTrack",
+	NeedsSeekableUpload:    true,
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
 }
 
 func init() {
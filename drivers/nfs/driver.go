@@ -0,0 +1,169 @@
+package nfs
+
+import (
+	"context"
+	"io"
+	"os"
+	stdpath "path"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/nfs"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type NFS struct {
+	model.Storage
+	Addition
+	mu     sync.Mutex
+	target *nfs.Target
+}
+
+func (d *NFS) Config() driver.Config {
+	return config
+}
+
+func (d *NFS) GetAddition() driver.Additional {
+	return d.Addition
+}
+
+func (d *NFS) Init(ctx context.Context, storage model.Storage) error {
+	d.Storage = storage
+	err := utils.Json.UnmarshalFromString(d.Storage.Addition, &d.Addition)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.connect()
+}
+
+func (d *NFS) Drop(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.close()
+	return nil
+}
+
+func (d *NFS) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	target, err := d.getTarget()
+	if err != nil {
+		return nil, err
+	}
+	files, err := target.ReadDir(dir.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(files, func(src os.FileInfo) (model.Obj, error) {
+		return fileToObj(src), nil
+	})
+}
+
+//func (d *NFS) Get(ctx context.Context, path string) (model.Obj, error) {
+//	// this is optional
+//	return nil, errs.NotImplement
+//}
+
+func (d *NFS) GetQuota(ctx context.Context) (*model.StorageQuota, error) {
+	target, err := d.getTarget()
+	if err != nil {
+		return nil, err
+	}
+	stat, err := target.FSStat(d.GetRootPath())
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageQuota{
+		Total: int64(stat.TotalBytes),
+		Used:  int64(stat.TotalBytes - stat.FreeBytes),
+		Free:  int64(stat.AvailBytes),
+	}, nil
+}
+
+func (d *NFS) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	target, err := d.getTarget()
+	if err != nil {
+		return nil, err
+	}
+	remoteFile, err := target.Open(file.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	return &model.Link{
+		Data: remoteFile,
+		RangeReader: func(offset int64) (io.ReadCloser, error) {
+			f, err := target.Open(file.GetPath())
+			if err != nil {
+				return nil, err
+			}
+			if _, err = f.Seek(offset, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return f, nil
+		},
+	}, nil
+}
+
+func (d *NFS) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	target, err := d.getTarget()
+	if err != nil {
+		return err
+	}
+	return target.MkdirAll(stdpath.Join(parentDir.GetPath(), dirName), 0755)
+}
+
+func (d *NFS) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	target, err := d.getTarget()
+	if err != nil {
+		return err
+	}
+	return target.Rename(srcObj.GetPath(), stdpath.Join(dstDir.GetPath(), srcObj.GetName()))
+}
+
+func (d *NFS) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	target, err := d.getTarget()
+	if err != nil {
+		return err
+	}
+	return target.Rename(srcObj.GetPath(), stdpath.Join(stdpath.Dir(srcObj.GetPath()), newName))
+}
+
+func (d *NFS) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	target, err := d.getTarget()
+	if err != nil {
+		return err
+	}
+	dst := stdpath.Join(dstDir.GetPath(), srcObj.GetName())
+	if srcObj.IsDir() {
+		return copyDir(target, srcObj.GetPath(), dst)
+	}
+	return copyFile(target, srcObj.GetPath(), dst)
+}
+
+func (d *NFS) Remove(ctx context.Context, obj model.Obj) error {
+	target, err := d.getTarget()
+	if err != nil {
+		return err
+	}
+	return target.RemoveAll(obj.GetPath())
+}
+
+func (d *NFS) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	target, err := d.getTarget()
+	if err != nil {
+		return err
+	}
+	dstFile, err := target.Create(stdpath.Join(dstDir.GetPath(), stream.GetName()), 0644)
+	if err != nil {
+		return err
+	}
+	err = utils.CopyWithCtx(ctx, dstFile, stream, stream.GetSize(), up)
+	if e := dstFile.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+var _ driver.Driver = (*NFS)(nil)
+var _ driver.Quota = (*NFS)(nil)
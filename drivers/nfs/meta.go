@@ -0,0 +1,33 @@
+package nfs
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	Address   string `json:"address" required:"true" help:"the host of the server"`
+	Export    string `json:"export" required:"true" help:"the path of the export, such as /volume1/share"`
+	UID       int    `json:"uid" type:"number" default:"0" help:"the uid the server checks the permissions with"`
+	GID       int    `json:"gid" type:"number" default:"0" help:"the gid the server checks the permissions with"`
+	MountPort int    `json:"mount_port" type:"number" default:"0" help:"it's got from the portmapper of the server if it's 0"`
+	NFSPort   int    `json:"nfs_port" type:"number" default:"0" help:"it's got from the portmapper of the server if it's 0, and 2049 is used if it isn't registered"`
+	driver.RootPath
+}
+
+var config = driver.Config{
+	Name:              "NFS",
+	LocalSort:         true,
+	OnlyLocal:         true,
+	DefaultRoot:       "/",
+	CheckStatus:       true,
+	SupportsRangeRead: true,
+}
+
+func New() driver.Driver {
+	return &NFS{}
+}
+
+func init() {
+	op.RegisterDriver(config, New)
+}
@@ -0,0 +1,92 @@
+package nfs
+
+import (
+	"io"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/pkg/nfs"
+)
+
+// do others that not defined in Driver interface
+
+// connect mount the export, the connection before is closed
+func (d *NFS) connect() error {
+	d.close()
+	target, err := nfs.Mount(d.Address, d.Export, nfs.Options{
+		Auth: &nfs.Auth{
+			MachineName: "alist",
+			UID:         uint32(d.UID),
+			GID:         uint32(d.GID),
+		},
+		MountPort: d.MountPort,
+		NFSPort:   d.NFSPort,
+		Timeout:   time.Minute,
+	})
+	if err != nil {
+		return err
+	}
+	d.target = target
+	return nil
+}
+
+func (d *NFS) close() {
+	if d.target != nil {
+		_ = d.target.Close()
+		d.target = nil
+	}
+}
+
+// getTarget get the export mounted, it's mounted again if the connection is broken,
+// such as the server restarted or closed the idle connection
+func (d *NFS) getTarget() (*nfs.Target, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.target != nil {
+		if _, err := d.target.Stat("/"); err == nil {
+			return d.target, nil
+		}
+	}
+	if err := d.connect(); err != nil {
+		return nil, err
+	}
+	return d.target, nil
+}
+
+func copyFile(target *nfs.Target, src, dst string) error {
+	srcFile, err := target.Open(src)
+	if err != nil {
+		return err
+	}
+	dstFile, err := target.Create(dst, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(dstFile, srcFile); err != nil {
+		_ = dstFile.Close()
+		return err
+	}
+	return dstFile.Close()
+}
+
+func copyDir(target *nfs.Target, src, dst string) error {
+	if err := target.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	files, err := target.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		s, t := stdpath.Join(src, f.Name()), stdpath.Join(dst, f.Name())
+		if f.IsDir() {
+			err = copyDir(target, s, t)
+		} else {
+			err = copyFile(target, s, t)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -51,6 +51,110 @@ func (d *Onedrive) List(ctx context.Context, dir model.Obj, args model.ListArgs)
 	})
 }
 
+func (d *Onedrive) Search(ctx context.Context, keyword string, dir model.Obj) ([]model.SearchNode, error) {
+	files, err := d.searchFiles(dir.GetPath(), keyword)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.SearchNode, 0, len(files))
+	for _, f := range files {
+		parent := parentPath(f.ParentReference.Path)
+		if parent == "" {
+			continue
+		}
+		res = append(res, model.SearchNode{
+			Parent: parent,
+			Name:   f.Name,
+			IsDir:  f.File == nil,
+			Size:   f.Size,
+		})
+	}
+	return res, nil
+}
+
+// Changes get the parents of the items changed with the delta api, the delta link is the cursor
+func (d *Onedrive) Changes(ctx context.Context, cursor string) ([]string, string, error) {
+	link := cursor
+	if link == "" {
+		link = d.GetDriveUrl() + "/root/delta?token=latest"
+	}
+	var dirs []string
+	for {
+		var resp DeltaResp
+		_, err := d.Request(link, http.MethodGet, func(req *resty.Request) {
+			req.SetContext(ctx)
+		}, &resp)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, f := range resp.Value {
+			// the deleted items may have no path
+			if parent := parentPath(f.ParentReference.Path); parent != "" {
+				dirs = append(dirs, parent)
+			}
+		}
+		if resp.NextLink == "" {
+			return dirs, resp.DeltaLink, nil
+		}
+		link = resp.NextLink
+	}
+}
+
+func (d *Onedrive) GetQuota(ctx context.Context) (*model.StorageQuota, error) {
+	var drive Drive
+	_, err := d.Request(d.GetDriveUrl(), http.MethodGet, nil, &drive)
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageQuota{
+		Total: drive.Quota.Total,
+		Used:  drive.Quota.Used,
+		Free:  drive.Quota.Remaining,
+	}, nil
+}
+
+func (d *Onedrive) ListTrash(ctx context.Context) ([]model.Obj, error) {
+	if !d.IsSharepoint {
+		return nil, errs.NotSupport
+	}
+	items, err := d.getRecycleBinItems()
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(items, func(src RecycleBinItem) (model.Obj, error) {
+		return &model.Object{
+			ID:       src.Id,
+			Name:     src.Name,
+			Size:     src.Size,
+			Modified: src.DeletedDateTime,
+		}, nil
+	})
+}
+
+func (d *Onedrive) Restore(ctx context.Context, obj model.Obj) error {
+	if !d.IsSharepoint {
+		return errs.NotSupport
+	}
+	_, err := d.Request(d.GetRecycleBinUrl()+"/restore", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"ids": []string{obj.GetID()},
+		})
+	}, nil)
+	return err
+}
+
+func (d *Onedrive) Purge(ctx context.Context, obj model.Obj) error {
+	if !d.IsSharepoint {
+		return errs.NotSupport
+	}
+	_, err := d.Request(d.GetRecycleBinUrl()+"/delete", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"ids": []string{obj.GetID()},
+		})
+	}, nil)
+	return err
+}
+
 func (d *Onedrive) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
 	f, err := d.GetFile(file.GetPath())
 	if err != nil {
@@ -128,6 +232,26 @@ func (d *Onedrive) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
 	return err
 }
 
+// CrossCopy copy the object to another onedrive storage with the copy api of graph,
+// it only works if the drive of the other storage can be accessed by this account, such as
+// another sharepoint site of the same tenant, or errs.NotSupport is returned
+func (d *Onedrive) CrossCopy(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	dstDrive, ok := dst.(*Onedrive)
+	if !ok || d.Region != dstDrive.Region {
+		return errs.NotSupport
+	}
+	var drive Drive
+	_, err := dstDrive.Request(dstDrive.GetDriveUrl(), http.MethodGet, nil, &drive)
+	if err != nil {
+		return err
+	}
+	dstFolder, err := dstDrive.GetFile(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	return d.copyTo(ctx, srcObj, drive.Id, dstFolder.Id)
+}
+
 func (d *Onedrive) Remove(ctx context.Context, obj model.Obj) error {
 	url := d.GetMetaUrl(false, obj.GetPath())
 	_, err := d.Request(url, http.MethodDelete, nil, nil)
@@ -145,3 +269,8 @@ func (d *Onedrive) Put(ctx context.Context, dstDir model.Obj, stream model.FileS
 }
 
 var _ driver.Driver = (*Onedrive)(nil)
+var _ driver.Searcher = (*Onedrive)(nil)
+var _ driver.Quota = (*Onedrive)(nil)
+var _ driver.Trash = (*Onedrive)(nil)
+var _ driver.CrossCopy = (*Onedrive)(nil)
+var _ driver.ChangeNotifier = (*Onedrive)(nil)
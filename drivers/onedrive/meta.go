@@ -10,16 +10,20 @@ type Addition struct {
 	Region       string `json:"region" type:"select" required:"true" options:"global,cn,us,de"`
 	IsSharepoint bool   `json:"is_sharepoint"`
 	ClientID     string `json:"client_id" required:"true"`
-	ClientSecret string `json:"client_secret" required:"true"`
+	ClientSecret string `json:"client_secret" required:"true" secret:"true"`
 	RedirectUri  string `json:"redirect_uri" required:"true" default:"https://tool.nn.ci/onedrive/callback"`
-	RefreshToken string `json:"refresh_token" required:"true"`
+	RefreshToken string `json:"refresh_token" required:"true" secret:"true"`
 	SiteId       string `json:"site_id"`
+	// what to do if the file uploaded exists
+	ConflictBehavior string `json:"conflict_behavior" type:"select" options:"replace,rename,fail" default:"replace"`
 }
 
 var config = driver.Config{
-	Name:        "Onedrive",
-	LocalSort:   true,
-	DefaultRoot: "/",
+	Name:                   "Onedrive",
+	LocalSort:              true,
+	DefaultRoot:            "/",
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
 }
 
 func New() driver.Driver {
@@ -1,6 +1,9 @@
 package onedrive
 
 import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/model"
@@ -31,6 +34,10 @@ type File struct {
 	Url                  string    `json:"@microsoft.graph.downloadUrl"`
 	File                 *struct {
 		MimeType string `json:"mimeType"`
+		Hashes   struct {
+			Sha1Hash     string `json:"sha1Hash"`
+			QuickXorHash string `json:"quickXorHash"`
+		} `json:"hashes"`
 	} `json:"file"`
 	Thumbnails []struct {
 		Medium struct {
@@ -39,6 +46,7 @@ type File struct {
 	} `json:"thumbnails"`
 	ParentReference struct {
 		DriveId string `json:"driveId"`
+		Path    string `json:"path"`
 	} `json:"parentReference"`
 }
 
@@ -47,7 +55,7 @@ func fileToObj(f File) *model.ObjThumbURL {
 	if len(f.Thumbnails) > 0 {
 		thumb = f.Thumbnails[0].Medium.Url
 	}
-	return &model.ObjThumbURL{
+	obj := &model.ObjThumbURL{
 		Object: model.Object{
 			ID:       f.Id,
 			Name:     f.Name,
@@ -58,9 +66,62 @@ func fileToObj(f File) *model.ObjThumbURL {
 		Thumbnail: model.Thumbnail{Thumbnail: thumb},
 		Url:       model.Url{Url: f.Url},
 	}
+	if f.File != nil {
+		obj.Hash = fileHash(f)
+	}
+	return obj
+}
+
+// fileHash get the hash of the file, the personal accounts have sha1,
+// and the business accounts only have quickXor
+func fileHash(f File) model.HashInfo {
+	if f.File.Hashes.Sha1Hash != "" {
+		return model.HashInfo{Type: "sha1", Value: strings.ToLower(f.File.Hashes.Sha1Hash)}
+	}
+	// the quickXor is in base64, convert it to hex like the others
+	if b, err := base64.StdEncoding.DecodeString(f.File.Hashes.QuickXorHash); err == nil && len(b) > 0 {
+		return model.HashInfo{Type: "quickxor", Value: hex.EncodeToString(b)}
+	}
+	return model.HashInfo{}
+}
+
+type Drive struct {
+	Id    string `json:"id"`
+	Quota struct {
+		Total     int64 `json:"total"`
+		Used      int64 `json:"used"`
+		Remaining int64 `json:"remaining"`
+	} `json:"quota"`
 }
 
 type Files struct {
 	Value    []File `json:"value"`
 	NextLink string `json:"@odata.nextLink"`
 }
+
+type DeltaResp struct {
+	Files
+	DeltaLink string `json:"@odata.deltaLink"`
+}
+
+type RecycleBinItem struct {
+	Id                   string    `json:"id"`
+	Name                 string    `json:"name"`
+	Size                 int64     `json:"size"`
+	DeletedDateTime      time.Time `json:"deletedDateTime"`
+	LastModifiedDateTime time.Time `json:"lastModifiedDateTime"`
+}
+
+type RecycleBinItems struct {
+	Value    []RecycleBinItem `json:"value"`
+	NextLink string           `json:"@odata.nextLink"`
+}
+
+// CopyStatus is the status of the asynchronous copy got from the monitor url
+type CopyStatus struct {
+	Status string `json:"status"`
+	Error  struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
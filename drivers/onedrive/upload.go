@@ -0,0 +1,237 @@
+package onedrive
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/go-resty/resty/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// the size of the chunks uploaded, it must be a multiple of 320 KiB
+const chunkSize = 32 * 320 * 1024
+
+// the times to retry a chunk after the network errors, from the offset acknowledged by onedrive
+const chunkRetries = 3
+
+// ErrSessionGone is returned if the upload session is expired or not found
+var ErrSessionGone = errors.New("the upload session is expired or not found")
+
+func (d *Onedrive) conflictBehavior() string {
+	if d.ConflictBehavior == "" {
+		return "replace"
+	}
+	return d.ConflictBehavior
+}
+
+// uploadSession is persisted in the upload dir, so the upload is resumed after the task is retried,
+// even after restarting alist
+type uploadSession struct {
+	UploadUrl          string    `json:"uploadUrl"`
+	ExpirationDateTime time.Time `json:"expirationDateTime"`
+}
+
+// sessionFile get the file the session of uploading the file of the size to the path is persisted to
+func (d *Onedrive) sessionFile(path string, size int64) string {
+	sum := md5.Sum([]byte(fmt.Sprintf("%d:%s:%d", d.ID, path, size)))
+	return filepath.Join(conf.Conf.UploadDir, "onedrive", hex.EncodeToString(sum[:])+".json")
+}
+
+func loadSession(file string) *uploadSession {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var s uploadSession
+	if err = json.Unmarshal(data, &s); err != nil || s.UploadUrl == "" || time.Now().After(s.ExpirationDateTime) {
+		_ = os.Remove(file)
+		return nil
+	}
+	return &s
+}
+
+func saveSession(file string, s *uploadSession) {
+	data, _ := json.Marshal(s)
+	err := os.MkdirAll(filepath.Dir(file), 0700)
+	if err == nil {
+		err = os.WriteFile(file, data, 0600)
+	}
+	if err != nil {
+		log.Warnf("failed persist the upload session of onedrive: %+v", err)
+	}
+}
+
+// uploadStatus get the offset onedrive expects next of the session
+func uploadStatus(ctx context.Context, uploadUrl string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uploadUrl, nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return 0, ErrSessionGone
+	}
+	if res.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(res.Body)
+		return 0, fmt.Errorf("failed get the upload status: %s", data)
+	}
+	var status struct {
+		NextExpectedRanges []string `json:"nextExpectedRanges"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&status); err != nil {
+		return 0, err
+	}
+	if len(status.NextExpectedRanges) == 0 {
+		return 0, ErrSessionGone
+	}
+	// such as "12345-" or "12345-67890"
+	start, _, _ := strings.Cut(status.NextExpectedRanges[0], "-")
+	return strconv.ParseInt(start, 10, 64)
+}
+
+// uploadRange upload the data at the offset of the file of the size
+func uploadRange(ctx context.Context, uploadUrl string, data []byte, offset, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadUrl, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(data))
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(len(data))-1, size))
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
+		return nil
+	case http.StatusNotFound:
+		return ErrSessionGone
+	}
+	body, _ := io.ReadAll(res.Body)
+	return fmt.Errorf("failed upload the range at %d: %s", offset, body)
+}
+
+// createSession create the upload session of the file to the path
+func (d *Onedrive) createSession(path string) (*uploadSession, error) {
+	var s uploadSession
+	_, err := d.Request(d.GetMetaUrl(false, path)+"/createUploadSession", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"item": base.Json{
+				"@microsoft.graph.conflictBehavior": d.conflictBehavior(),
+			},
+		})
+	}, &s)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// upBig upload the file by the upload session, the session is persisted, so the upload retried
+// later is resumed from the offset acknowledged by onedrive instead of from zero
+func (d *Onedrive) upBig(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	path := stdpath.Join(dstDir.GetPath(), stream.GetName())
+	size := stream.GetSize()
+	file := d.sessionFile(path, size)
+	var finish int64
+	s := loadSession(file)
+	if s != nil {
+		next, err := uploadStatus(ctx, s.UploadUrl)
+		if err != nil {
+			log.Infof("failed resume the upload of %s, restart it: %v", path, err)
+			s = nil
+		} else {
+			log.Infof("resume the upload of %s from %d", path, next)
+			finish = next
+		}
+	}
+	if s == nil {
+		var err error
+		if s, err = d.createSession(path); err != nil {
+			return err
+		}
+		saveSession(file, s)
+	}
+	// the bytes uploaded are skipped
+	if finish > 0 {
+		if _, err := io.CopyN(io.Discard, stream, finish); err != nil {
+			return err
+		}
+	}
+	buf := make([]byte, chunkSize)
+	for finish < size {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		n := int64(chunkSize)
+		if left := size - finish; left < n {
+			n = left
+		}
+		if _, err := io.ReadFull(stream, buf[:n]); err != nil {
+			return err
+		}
+		if err := d.uploadChunk(ctx, s.UploadUrl, buf[:n], finish, size); err != nil {
+			if errors.Is(err, ErrSessionGone) {
+				_ = os.Remove(file)
+			}
+			return err
+		}
+		finish += n
+		up(int(finish * 100 / size))
+	}
+	_ = os.Remove(file)
+	return nil
+}
+
+// uploadChunk upload the chunk at the offset, it's retried from the offset acknowledged by
+// onedrive after the network errors, so only the bytes not received are uploaded again
+func (d *Onedrive) uploadChunk(ctx context.Context, uploadUrl string, chunk []byte, offset, size int64) error {
+	end := offset + int64(len(chunk))
+	cur := offset
+	for i := 0; ; i++ {
+		err := uploadRange(ctx, uploadUrl, chunk[cur-offset:], cur, size)
+		if err == nil || errors.Is(err, ErrSessionGone) || i >= chunkRetries || ctx.Err() != nil {
+			return err
+		}
+		log.Warnf("failed upload the range at %d, retry: %v", cur, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(i+1) * time.Second):
+		}
+		next, e := uploadStatus(ctx, uploadUrl)
+		if e != nil {
+			return err
+		}
+		if next >= end {
+			return nil
+		}
+		if next < offset {
+			// the bytes before the chunk are discarded, they can't be uploaded again
+			return err
+		}
+		cur = next
+	}
+}
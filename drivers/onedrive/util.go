@@ -1,24 +1,22 @@
 package onedrive
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	stdpath "path"
-	"strconv"
+	"strings"
+	"time"
 
 	"github.com/alist-org/alist/v3/drivers/base"
-	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/go-resty/resty/v2"
-	jsoniter "github.com/json-iterator/go"
-	log "github.com/sirupsen/logrus"
 )
 
 var onedriveHostMap = map[string]Host{
@@ -60,6 +58,23 @@ func (d *Onedrive) GetMetaUrl(auth bool, path string) string {
 	}
 }
 
+// GetDriveUrl get url of the drive resource, such as https://graph.microsoft.com/v1.0/me/drive
+func (d *Onedrive) GetDriveUrl() string {
+	host, _ := onedriveHostMap[d.Region]
+	if d.IsSharepoint {
+		return fmt.Sprintf("%s/v1.0/sites/%s/drive", host.Api, d.SiteId)
+	}
+	return fmt.Sprintf("%s/v1.0/me/drive", host.Api)
+}
+
+// GetRecycleBinUrl get url of the recycle bin of the sharepoint site,
+// graph api doesn't provide the recycle bin of personal onedrive,
+// and only the beta version supports restore and delete
+func (d *Onedrive) GetRecycleBinUrl() string {
+	host, _ := onedriveHostMap[d.Region]
+	return fmt.Sprintf("%s/beta/sites/%s/recycleBin/items", host.Api, d.SiteId)
+}
+
 func (d *Onedrive) refreshToken() error {
 	var err error
 	for i := 0; i < 3; i++ {
@@ -139,6 +154,47 @@ func (d *Onedrive) getFiles(path string) ([]File, error) {
 	return res, nil
 }
 
+func (d *Onedrive) searchFiles(path, keyword string) ([]File, error) {
+	var res []File
+	q := url.PathEscape(strings.ReplaceAll(keyword, "'", "''"))
+	nextLink := d.GetMetaUrl(false, path) + fmt.Sprintf("/search(q='%s')", q)
+	for nextLink != "" {
+		var files Files
+		_, err := d.Request(nextLink, http.MethodGet, nil, &files)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, files.Value...)
+		nextLink = files.NextLink
+	}
+	return res, nil
+}
+
+func (d *Onedrive) getRecycleBinItems() ([]RecycleBinItem, error) {
+	var res []RecycleBinItem
+	nextLink := d.GetRecycleBinUrl()
+	for nextLink != "" {
+		var items RecycleBinItems
+		_, err := d.Request(nextLink, http.MethodGet, nil, &items)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, items.Value...)
+		nextLink = items.NextLink
+	}
+	return res, nil
+}
+
+// parentPath get path of parent folder from the parentReference.path,
+// such as /drive/root:/a/b => /a/b
+func parentPath(path string) string {
+	i := strings.Index(path, "root:")
+	if i == -1 {
+		return ""
+	}
+	return utils.StandardizePath(path[i+len("root:"):])
+}
+
 func (d *Onedrive) GetFile(path string) (*File, error) {
 	var file File
 	u := d.GetMetaUrl(false, path)
@@ -147,7 +203,7 @@ func (d *Onedrive) GetFile(path string) (*File, error) {
 }
 
 func (d *Onedrive) upSmall(dstDir model.Obj, stream model.FileStreamer) error {
-	url := d.GetMetaUrl(false, stdpath.Join(dstDir.GetPath(), stream.GetName())) + "/content"
+	url := d.GetMetaUrl(false, stdpath.Join(dstDir.GetPath(), stream.GetName())) + "/content?@microsoft.graph.conflictBehavior=" + d.conflictBehavior()
 	data, err := io.ReadAll(stream)
 	if err != nil {
 		return err
@@ -158,43 +214,85 @@ func (d *Onedrive) upSmall(dstDir model.Obj, stream model.FileStreamer) error {
 	return err
 }
 
-func (d *Onedrive) upBig(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
-	url := d.GetMetaUrl(false, stdpath.Join(dstDir.GetPath(), stream.GetName())) + "/createUploadSession"
-	res, err := d.Request(url, http.MethodPost, nil, nil)
+// copyTo copy the object to the folder of the drive, and wait for the copy to finish,
+// errs.NotSupport is returned if the drive can't be accessed by this account
+func (d *Onedrive) copyTo(ctx context.Context, srcObj model.Obj, driveId, folderId string) error {
+	var e RespErr
+	res, err := base.RestyClient.R().
+		SetContext(ctx).
+		SetHeader("Authorization", "Bearer "+d.AccessToken).
+		SetBody(base.Json{
+			"parentReference": base.Json{
+				"driveId": driveId,
+				"id":      folderId,
+			},
+			"name":                              srcObj.GetName(),
+			"@microsoft.graph.conflictBehavior": "replace",
+		}).
+		SetError(&e).
+		Post(d.GetMetaUrl(false, srcObj.GetPath()) + "/copy")
 	if err != nil {
 		return err
 	}
-	uploadUrl := jsoniter.Get(res, "uploadUrl").ToString()
-	var finish int64 = 0
-	const DEFAULT = 4 * 1024 * 1024
-	for finish < stream.GetSize() {
-		if utils.IsCanceled(ctx) {
-			return ctx.Err()
-		}
-		log.Debugf("upload: %d", finish)
-		var byteSize int64 = DEFAULT
-		left := stream.GetSize() - finish
-		if left < DEFAULT {
-			byteSize = left
+	switch {
+	case res.StatusCode() == http.StatusUnauthorized && e.Error.Code == "InvalidAuthenticationToken":
+		if err = d.refreshToken(); err != nil {
+			return err
 		}
-		byteData := make([]byte, byteSize)
-		n, err := io.ReadFull(stream, byteData)
-		log.Debug(err, n)
+		return d.copyTo(ctx, srcObj, driveId, folderId)
+	case res.StatusCode() == http.StatusForbidden || res.StatusCode() == http.StatusNotFound:
+		return errs.NotSupport
+	case e.Error.Code != "":
+		return errors.New(e.Error.Message)
+	}
+	monitor := res.Header().Get("Location")
+	if monitor == "" {
+		return nil
+	}
+	// the copy is asynchronous, the status can be got from the monitor url without auth
+	for {
+		status, err := copyStatus(ctx, monitor)
 		if err != nil {
 			return err
 		}
-		req, err := http.NewRequest("PUT", uploadUrl, bytes.NewBuffer(byteData))
-		req.Header.Set("Content-Length", strconv.Itoa(int(byteSize)))
-		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", finish, finish+byteSize-1, stream.GetSize()))
-		finish += byteSize
-		res, err := base.HttpClient.Do(req)
-		if res.StatusCode != 201 && res.StatusCode != 202 {
-			data, _ := io.ReadAll(res.Body)
-			res.Body.Close()
-			return errors.New(string(data))
+		switch status.Status {
+		case "completed":
+			return nil
+		case "failed":
+			return fmt.Errorf("failed to copy %s: %s", srcObj.GetName(), status.Error.Message)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
 		}
-		res.Body.Close()
-		up(int(finish * 100 / stream.GetSize()))
 	}
-	return nil
+}
+
+// the monitor url redirects to the new item after the copy completed
+var monitorClient = &http.Client{
+	Timeout: 30 * time.Second,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func copyStatus(ctx context.Context, monitor string) (*CopyStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, monitor, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := monitorClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusSeeOther {
+		return &CopyStatus{Status: "completed"}, nil
+	}
+	var status CopyStatus
+	if err = utils.Json.NewDecoder(res.Body).Decode(&status); err != nil {
+		return nil, err
+	}
+	return &status, nil
 }
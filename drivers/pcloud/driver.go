@@ -0,0 +1,154 @@
+package pcloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type PCloud struct {
+	model.Storage
+	Addition
+	// the auth token got by the username and the password
+	auth string
+}
+
+func (d *PCloud) Config() driver.Config {
+	return config
+}
+
+func (d *PCloud) GetAddition() driver.Additional {
+	return d.Addition
+}
+
+func (d *PCloud) Init(ctx context.Context, storage model.Storage) error {
+	d.Storage = storage
+	err := utils.Json.UnmarshalFromString(d.Storage.Addition, &d.Addition)
+	if err != nil {
+		return err
+	}
+	if d.AccessToken != "" {
+		return nil
+	}
+	if d.Username == "" || d.Password == "" {
+		return errors.New("the access token or the username and the password are required")
+	}
+	return d.login()
+}
+
+func (d *PCloud) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *PCloud) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	files, err := d.getFiles(dir.GetID())
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(files, func(src File) (model.Obj, error) {
+		return fileToObj(src), nil
+	})
+}
+
+func (d *PCloud) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	var resp LinkResp
+	err := d.request("getfilelink", map[string]string{
+		"fileid":        file.GetID(),
+		"forcedownload": "1",
+		"skipfilename":  "1",
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Hosts) == 0 {
+		return nil, fmt.Errorf("pcloud: no host of the link")
+	}
+	exp := time.Hour
+	return &model.Link{
+		URL:        "https://" + resp.Hosts[0] + resp.Path,
+		Expiration: &exp,
+	}, nil
+}
+
+func (d *PCloud) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	return d.request("createfolder", map[string]string{
+		"folderid": parentDir.GetID(),
+		"name":     dirName,
+	}, nil)
+}
+
+func (d *PCloud) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	params := fileParams(srcObj)
+	params["tofolderid"] = dstDir.GetID()
+	return d.request(renameMethod(srcObj), params, nil)
+}
+
+func (d *PCloud) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	params := fileParams(srcObj)
+	params["toname"] = newName
+	return d.request(renameMethod(srcObj), params, nil)
+}
+
+func (d *PCloud) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	params := fileParams(srcObj)
+	params["tofolderid"] = dstDir.GetID()
+	if srcObj.IsDir() {
+		return d.request("copyfolder", params, nil)
+	}
+	var resp MetadataResp
+	if err := d.request("copyfile", params, &resp); err != nil {
+		return err
+	}
+	// check the copy by the checksums of the two files
+	src, err := d.checksum(srcObj.GetID())
+	if err != nil {
+		return err
+	}
+	dst, err := d.checksum(strconv.FormatInt(resp.Metadata.FileID, 10))
+	if err != nil {
+		return err
+	}
+	if src.SHA1 != dst.SHA1 {
+		return fmt.Errorf("pcloud: the sha1 of the copy of %s is %s, not %s", srcObj.GetName(), dst.SHA1, src.SHA1)
+	}
+	return nil
+}
+
+func (d *PCloud) Remove(ctx context.Context, obj model.Obj) error {
+	if obj.IsDir() {
+		return d.request("deletefolderrecursive", fileParams(obj), nil)
+	}
+	return d.request("deletefile", fileParams(obj), nil)
+}
+
+func (d *PCloud) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	return d.upload(ctx, dstDir.GetID(), stream, up)
+}
+
+func (d *PCloud) GetQuota(ctx context.Context) (*model.StorageQuota, error) {
+	var resp UserInfo
+	if err := d.request("userinfo", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &model.StorageQuota{
+		Total: resp.Quota,
+		Used:  resp.UsedQuota,
+		Free:  resp.Quota - resp.UsedQuota,
+	}, nil
+}
+
+func renameMethod(obj model.Obj) string {
+	if obj.IsDir() {
+		return "renamefolder"
+	}
+	return "renamefile"
+}
+
+var _ driver.Driver = (*PCloud)(nil)
+var _ driver.Quota = (*PCloud)(nil)
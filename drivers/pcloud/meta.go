@@ -0,0 +1,31 @@
+package pcloud
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	driver.RootID
+	// the accounts are stored in the data center of the region, us is api.pcloud.com and eu is eapi.pcloud.com
+	Region   string `json:"region" type:"select" options:"us,eu" default:"us" help:"the region of the data center of the account"`
+	Username string `json:"username"`
+	Password string `json:"password" secret:"true"`
+	// the oauth token is used instead of the username and the password if it's set
+	AccessToken string `json:"access_token" secret:"true" help:"the oauth access token, the username and the password are not needed if it's set"`
+}
+
+var config = driver.Config{
+	Name:              "PCloud",
+	LocalSort:         true,
+	DefaultRoot:       "0",
+	SupportsRangeRead: true,
+}
+
+func New() driver.Driver {
+	return &PCloud{}
+}
+
+func init() {
+	op.RegisterDriver(config, New)
+}
@@ -0,0 +1,76 @@
+package pcloud
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+// Resp is the common part of the responses, the result is 0 if it succeeds
+type Resp struct {
+	Result int    `json:"result"`
+	Error  string `json:"error"`
+}
+
+type File struct {
+	Name     string `json:"name"`
+	IsFolder bool   `json:"isfolder"`
+	FolderID int64  `json:"folderid"`
+	FileID   int64  `json:"fileid"`
+	Size     int64  `json:"size"`
+	// such as Thu, 19 Sep 2013 07:31:46 +0000
+	Modified string `json:"modified"`
+	Contents []File `json:"contents"`
+}
+
+type MetadataResp struct {
+	Resp
+	Metadata File `json:"metadata"`
+}
+
+type LinkResp struct {
+	Resp
+	Hosts []string `json:"hosts"`
+	Path  string   `json:"path"`
+}
+
+// Checksums is the checksums of a file, md5 is only given in the us region and sha256 in the eu region
+type Checksums struct {
+	SHA1   string `json:"sha1"`
+	MD5    string `json:"md5"`
+	SHA256 string `json:"sha256"`
+}
+
+type ChecksumResp struct {
+	Resp
+	Checksums
+}
+
+type UploadResp struct {
+	Resp
+	FileIDs   []int64     `json:"fileids"`
+	Checksums []Checksums `json:"checksums"`
+}
+
+type UserInfo struct {
+	Resp
+	Auth      string `json:"auth"`
+	Quota     int64  `json:"quota"`
+	UsedQuota int64  `json:"usedquota"`
+}
+
+func fileToObj(f File) *model.Object {
+	modified, _ := time.Parse(time.RFC1123Z, f.Modified)
+	id := f.FileID
+	if f.IsFolder {
+		id = f.FolderID
+	}
+	return &model.Object{
+		ID:       strconv.FormatInt(id, 10),
+		Name:     f.Name,
+		Size:     f.Size,
+		Modified: modified,
+		IsFolder: f.IsFolder,
+	}
+}
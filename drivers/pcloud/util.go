@@ -0,0 +1,170 @@
+package pcloud
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// do others that not defined in Driver interface
+
+var apiUrls = map[string]string{
+	"us": "https://api.pcloud.com",
+	"eu": "https://eapi.pcloud.com",
+}
+
+// the results of the invalid auth, such as 1000 Log in required and 2000 Log in failed
+var authResults = map[int]bool{
+	1000: true,
+	2000: true,
+	2094: true,
+}
+
+func (d *PCloud) apiUrl() string {
+	if u, ok := apiUrls[d.Region]; ok {
+		return u
+	}
+	return apiUrls["us"]
+}
+
+// login get the auth token by the username and the password
+func (d *PCloud) login() error {
+	var resp UserInfo
+	_, err := base.RestyClient.R().SetResult(&resp).SetQueryParams(map[string]string{
+		"getauth":  "1",
+		"logout":   "1",
+		"username": d.Username,
+		"password": d.Password,
+	}).Get(d.apiUrl() + "/userinfo")
+	if err != nil {
+		return err
+	}
+	if resp.Result != 0 {
+		return fmt.Errorf("pcloud: failed login: %s (%d)", resp.Error, resp.Result)
+	}
+	d.auth = resp.Auth
+	return nil
+}
+
+func (d *PCloud) setAuth(req *http.Request) {
+	if d.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+d.AccessToken)
+	} else {
+		q := req.URL.Query()
+		q.Set("auth", d.auth)
+		req.URL.RawQuery = q.Encode()
+	}
+}
+
+// request call the method of the api with the params, `resp` must embed Resp
+func (d *PCloud) request(method string, params map[string]string, resp interface{}) error {
+	return d.do(context.Background(), http.MethodGet, method, params, nil, -1, resp, true)
+}
+
+func (d *PCloud) do(ctx context.Context, httpMethod, method string, params map[string]string, body io.Reader, size int64, resp interface{}, retry bool) error {
+	q := url.Values{}
+	for k, v := range params {
+		q.Set(k, v)
+	}
+	req, err := http.NewRequestWithContext(ctx, httpMethod, d.apiUrl()+"/"+method+"?"+q.Encode(), body)
+	if err != nil {
+		return err
+	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	d.setAuth(req)
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	var r Resp
+	if err = utils.Json.Unmarshal(data, &r); err != nil {
+		return fmt.Errorf("pcloud: %s %s", res.Status, data)
+	}
+	if r.Result != 0 {
+		// the auth token may be expired, login again, the body can't be sent again
+		if retry && body == nil && authResults[r.Result] && d.AccessToken == "" {
+			if err = d.login(); err != nil {
+				return err
+			}
+			return d.do(ctx, httpMethod, method, params, body, size, resp, false)
+		}
+		return fmt.Errorf("pcloud: %s (%d)", r.Error, r.Result)
+	}
+	if resp != nil {
+		return utils.Json.Unmarshal(data, resp)
+	}
+	return nil
+}
+
+func (d *PCloud) getFiles(folderID string) ([]File, error) {
+	var resp MetadataResp
+	err := d.request("listfolder", map[string]string{"folderid": folderID}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Metadata.Contents, nil
+}
+
+// fileParams get the params of the id of a file or a folder
+func fileParams(obj model.Obj) map[string]string {
+	if obj.IsDir() {
+		return map[string]string{"folderid": obj.GetID()}
+	}
+	return map[string]string{"fileid": obj.GetID()}
+}
+
+func (d *PCloud) checksum(fileID string) (*Checksums, error) {
+	var resp ChecksumResp
+	err := d.request("checksumfile", map[string]string{"fileid": fileID}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Checksums, nil
+}
+
+// upload upload the file and check the sha1 of it, the file with the same name is overwritten
+func (d *PCloud) upload(ctx context.Context, folderID string, stream model.FileStreamer, up driver.UpdateProgress) error {
+	h := sha1.New()
+	var resp UploadResp
+	err := d.do(ctx, http.MethodPut, "uploadfile", map[string]string{
+		"folderid":  folderID,
+		"filename":  stream.GetName(),
+		"nopartial": "1",
+	}, io.TeeReader(stream, h), stream.GetSize(), &resp, true)
+	if err != nil {
+		return err
+	}
+	if len(resp.FileIDs) == 0 {
+		return fmt.Errorf("pcloud: no file uploaded")
+	}
+	fileID := strconv.FormatInt(resp.FileIDs[0], 10)
+	var sum *Checksums
+	if len(resp.Checksums) > 0 {
+		sum = &resp.Checksums[0]
+	} else if sum, err = d.checksum(fileID); err != nil {
+		return err
+	}
+	if local := hex.EncodeToString(h.Sum(nil)); sum.SHA1 != local {
+		_ = d.request("deletefile", map[string]string{"fileid": fileID}, nil)
+		return fmt.Errorf("pcloud: the sha1 of the uploaded file %s is %s, not %s", stream.GetName(), sum.SHA1, local)
+	}
+	up(100)
+	return nil
+}
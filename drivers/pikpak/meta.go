@@ -8,13 +8,16 @@ import (
 type Addition struct {
 	driver.RootID
 	Username string `json:"username" required:"true"`
-	Password string `json:"password" required:"true"`
+	Password string `json:"password" required:"true" secret:"true"`
 }
 
 var config = driver.Config{
-	Name:        "PikPak",
-	LocalSort:   true,
-	DefaultRoot: "",
+	Name:                   "PikPak",
+	LocalSort:              true,
+	DefaultRoot:            "",
+	NeedsSeekableUpload:    true,
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
 }
 
 func New() driver.Driver {
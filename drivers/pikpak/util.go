@@ -45,7 +45,7 @@ func (d *PikPak) refreshToken() error {
 		"refresh_token": d.RefreshToken,
 	}).Post(url)
 	if err != nil {
-		d.Status = err.Error()
+		d.SetStatus(err.Error())
 		op.MustSaveDriverStorage(d)
 		return err
 	}
@@ -54,12 +54,12 @@ func (d *PikPak) refreshToken() error {
 			// refresh_token invalid, re-login
 			return d.login()
 		}
-		d.Status = e.Error
+		d.SetStatus(e.Error)
 		op.MustSaveDriverStorage(d)
 		return errors.New(e.Error)
 	}
 	data := res.Body()
-	d.Status = "work"
+	d.SetStatus(op.WORK)
 	d.RefreshToken = jsoniter.Get(data, "refresh_token").ToString()
 	d.AccessToken = jsoniter.Get(data, "access_token").ToString()
 	op.MustSaveDriverStorage(d)
@@ -5,6 +5,7 @@ import (
 	"crypto/md5"
 	"crypto/sha1"
 	"encoding/hex"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
@@ -14,6 +15,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/cron"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/go-resty/resty/v2"
 	log "github.com/sirupsen/logrus"
@@ -22,10 +24,13 @@ import (
 type Quark struct {
 	model.Storage
 	Addition
+	config driver.Config
+	conf   Conf
+	cron   *cron.Cron
 }
 
 func (d *Quark) Config() driver.Config {
-	return config
+	return d.config
 }
 
 func (d *Quark) GetAddition() driver.Additional {
@@ -39,10 +44,24 @@ func (d *Quark) Init(ctx context.Context, storage model.Storage) error {
 		return err
 	}
 	_, err = d.request("/config", http.MethodGet, nil, nil)
-	return err
+	if err != nil {
+		return err
+	}
+	// the __puus of the cookie expires if it isn't renewed for a while, so keep it renewed when idle
+	d.cron = cron.NewCron(time.Hour * 6)
+	d.cron.Do(func() {
+		_, err := d.request("/config", http.MethodGet, nil, nil)
+		if err != nil {
+			log.Errorf("failed renew the cookie of %s: %+v", d.MountPath, err)
+		}
+	})
+	return nil
 }
 
 func (d *Quark) Drop(ctx context.Context) error {
+	if d.cron != nil {
+		d.cron.Stop()
+	}
 	return nil
 }
 
@@ -72,11 +91,15 @@ func (d *Quark) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (
 	if err != nil {
 		return nil, err
 	}
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("no download url of %s", file.GetName())
+	}
 	return &model.Link{
 		URL: resp.Data[0].DownloadUrl,
 		Header: http.Header{
-			"Cookie":  []string{d.Cookie},
-			"Referer": []string{"https://pan.quark.cn"},
+			"Cookie":     []string{d.Cookie},
+			"Referer":    []string{d.conf.referer},
+			"User-Agent": []string{d.conf.ua},
 		},
 	}, nil
 }
@@ -219,4 +242,65 @@ func (d *Quark) Put(ctx context.Context, dstDir model.Obj, stream model.FileStre
 	return d.upFinish(pre)
 }
 
+func (d *Quark) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
+	switch args.Method {
+	case "video_preview":
+		return d.VideoPreview(ctx, args.Obj)
+	default:
+		return nil, errs.NotSupport
+	}
+}
+
+// VideoPreview get the urls of the m3u8 of the video transcoded by quark, they can be played without the cookie
+func (d *Quark) VideoPreview(ctx context.Context, file model.Obj) (*model.VideoPreview, error) {
+	resp, err := d.play(file.GetID())
+	if err != nil {
+		return nil, err
+	}
+	preview := &model.VideoPreview{}
+	for _, r := range resolutions {
+		for _, v := range resp.Data.VideoList {
+			if v.Resolution == r && v.VideoInfo.URL != "" {
+				preview.Qualities = append(preview.Qualities, model.VideoQuality{
+					Name:   v.Resolution,
+					Width:  v.VideoInfo.Width,
+					Height: v.VideoInfo.Height,
+					URL:    v.VideoInfo.URL,
+				})
+			}
+		}
+	}
+	if len(preview.Qualities) == 0 {
+		return nil, fmt.Errorf("%s isn't transcoded yet", file.GetName())
+	}
+	return preview, nil
+}
+
+// SaveShareFiles save the files of the share to the dir, the share is got by the share id
+// and the stoken of it, the ids of the files saved are returned
+func (d *Quark) SaveShareFiles(ctx context.Context, shareID, stoken string, fids, fidTokens []string, dstDirID string) ([]string, error) {
+	var resp SaveResp
+	_, err := d.request("/share/sharepage/save", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"fid_list":       fids,
+			"fid_token_list": fidTokens,
+			"to_pdir_fid":    dstDirID,
+			"pwd_id":         shareID,
+			"stoken":         stoken,
+			"pdir_fid":       "0",
+			"scene":          "link",
+		})
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	task, err := d.waitTask(ctx, resp.Data.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	return task.Data.SaveAs.SaveAsTopFids, nil
+}
+
 var _ driver.Driver = (*Quark)(nil)
+var _ driver.Other = (*Quark)(nil)
+var _ driver.VideoPreviewer = (*Quark)(nil)
@@ -6,22 +6,61 @@ import (
 )
 
 type Addition struct {
-	Cookie string `json:"cookie" required:"true"`
+	Cookie string `json:"cookie" required:"true" secret:"true" help:"it's renewed automatically, update it if the storage tells it's expired"`
 	driver.RootID
 	OrderBy        string `json:"order_by" type:"select" options:"file_type,file_name,updated_at" default:"file_name"`
 	OrderDirection string `json:"order_direction" type:"select" options:"asc,desc" default:"asc"`
 }
 
+// Conf the differences of the apis of quark and uc, which are the same service
+type Conf struct {
+	ua      string
+	referer string
+	api     string
+	pr      string
+}
+
 var config = driver.Config{
-	Name:        "Quark",
-	OnlyProxy:   true,
-	DefaultRoot: "0",
+	Name:                "Quark",
+	OnlyProxy:           true,
+	DefaultRoot:         "0",
+	NeedsSeekableUpload: true,
+	SupportsRangeRead:   true,
+}
+
+var configUC = driver.Config{
+	Name:                "UC",
+	OnlyProxy:           true,
+	DefaultRoot:         "0",
+	NeedsSeekableUpload: true,
+	SupportsRangeRead:   true,
 }
 
 func New() driver.Driver {
-	return &Quark{}
+	return &Quark{
+		config: config,
+		conf: Conf{
+			ua:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) quark-cloud-drive/2.5.20 Chrome/100.0.4896.160 Electron/18.3.5.4-b478491100 Safari/537.36 Channel/pckk_other_ch",
+			referer: "https://pan.quark.cn",
+			api:     "https://drive.quark.cn/1/clouddrive",
+			pr:      "ucpro",
+		},
+	}
+}
+
+func NewUC() driver.Driver {
+	return &Quark{
+		config: configUC,
+		conf: Conf{
+			ua:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) uc-cloud-drive/2.5.20 Chrome/100.0.4896.160 Electron/18.3.5.4-b478491100 Safari/537.36 Channel/pckk_other_ch",
+			referer: "https://drive.uc.cn",
+			api:     "https://pc-api.uc.cn/1/clouddrive",
+			pr:      "UCBrowser",
+		},
+	}
 }
 
 func init() {
 	op.RegisterDriver(config, New)
+	op.RegisterDriver(configUC, NewUC)
 }
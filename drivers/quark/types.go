@@ -148,3 +148,36 @@ type UpAuthResp struct {
 	Metadata struct {
 	} `json:"metadata"`
 }
+
+type PlayResp struct {
+	Resp
+	Data struct {
+		VideoList []struct {
+			Resolution string `json:"resolution"`
+			VideoInfo  struct {
+				URL      string `json:"url"`
+				Width    int    `json:"width"`
+				Height   int    `json:"height"`
+				Duration int    `json:"duration"`
+			} `json:"video_info"`
+		} `json:"video_list"`
+	} `json:"data"`
+}
+
+type SaveResp struct {
+	Resp
+	Data struct {
+		TaskID string `json:"task_id"`
+	} `json:"data"`
+}
+
+// TaskResp the status of the task is 2 when it's finished
+type TaskResp struct {
+	Resp
+	Data struct {
+		Status int `json:"status"`
+		SaveAs struct {
+			SaveAsTopFids []string `json:"save_as_top_fids"`
+		} `json:"save_as"`
+	} `json:"data"`
+}
@@ -1,6 +1,7 @@
 package quark
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/base64"
 	"errors"
@@ -21,15 +22,18 @@ import (
 
 // do others that not defined in Driver interface
 
+var errCookieExpired = errors.New("the cookie is expired, update it with a new one from the browser")
+
 func (d *Quark) request(pathname string, method string, callback base.ReqCallback, resp interface{}) ([]byte, error) {
-	u := "https://drive.quark.cn/1/clouddrive" + pathname
+	u := d.conf.api + pathname
 	req := base.RestyClient.R()
 	req.SetHeaders(map[string]string{
-		"Cookie":  d.Cookie,
-		"Accept":  "application/json, text/plain, */*",
-		"Referer": "https://pan.quark.cn/",
+		"Cookie":     d.Cookie,
+		"Accept":     "application/json, text/plain, */*",
+		"Referer":    d.conf.referer,
+		"User-Agent": d.conf.ua,
 	})
-	req.SetQueryParam("pr", "ucpro")
+	req.SetQueryParam("pr", d.conf.pr)
 	req.SetQueryParam("fr", "pc")
 	if callback != nil {
 		callback(req)
@@ -43,10 +47,9 @@ func (d *Quark) request(pathname string, method string, callback base.ReqCallbac
 	if err != nil {
 		return nil, err
 	}
-	__puus := cookie.GetCookie(res.Cookies(), "__puus")
-	if __puus != nil {
-		d.Cookie = cookie.SetStr(d.Cookie, "__puus", __puus.Value)
-		op.MustSaveDriverStorage(d)
+	d.renewCookie(res.Cookies())
+	if res.StatusCode() == http.StatusUnauthorized || e.Code == 31001 {
+		return nil, errCookieExpired
 	}
 	if e.Status >= 400 || e.Code != 0 {
 		return nil, errors.New(e.Message)
@@ -54,6 +57,22 @@ func (d *Quark) request(pathname string, method string, callback base.ReqCallbac
 	return res.Body(), nil
 }
 
+// renewCookie update the __puus and __pus of the cookie set by the responses,
+// the storage is saved only if they are changed
+func (d *Quark) renewCookie(cookies []*http.Cookie) {
+	renewed := d.Cookie
+	for _, name := range []string{"__puus", "__pus"} {
+		c := cookie.GetCookie(cookies, name)
+		if c != nil && c.Value != "" && cookie.GetStr(renewed, name) != c.Value {
+			renewed = cookie.SetStr(renewed, name, c.Value)
+		}
+	}
+	if renewed != d.Cookie {
+		d.Cookie = renewed
+		op.MustSaveDriverStorage(d)
+	}
+}
+
 func (d *Quark) GetFiles(parent string) ([]File, error) {
 	files := make([]File, 0)
 	page := 1
@@ -152,7 +171,7 @@ x-oss-user-agent:aliyun-sdk-js/6.6.1 Chrome 98.0.4758.80 on Windows 10 64-bit
 		SetHeaders(map[string]string{
 			"Authorization":    resp.Data.AuthKey,
 			"Content-Type":     mineType,
-			"Referer":          "https://pan.quark.cn/",
+			"Referer":          d.conf.referer + "/",
 			"x-oss-date":       timeStr,
 			"x-oss-user-agent": "aliyun-sdk-js/6.6.1 Chrome 98.0.4758.80 on Windows 10 64-bit",
 		}).
@@ -219,7 +238,7 @@ x-oss-user-agent:aliyun-sdk-js/6.6.1 Chrome 98.0.4758.80 on Windows 10 64-bit
 			"Authorization":    resp.Data.AuthKey,
 			"Content-MD5":      contentMd5,
 			"Content-Type":     "application/xml",
-			"Referer":          "https://pan.quark.cn/",
+			"Referer":          d.conf.referer + "/",
 			"x-oss-callback":   callbackBase64,
 			"x-oss-date":       timeStr,
 			"x-oss-user-agent": "aliyun-sdk-js/6.6.1 Chrome 98.0.4758.80 on Windows 10 64-bit",
@@ -247,3 +266,46 @@ func (d *Quark) upFinish(pre UpPreResp) error {
 	time.Sleep(time.Second)
 	return nil
 }
+
+// resolutions the resolutions of the transcoded videos from the lowest to the highest
+var resolutions = []string{"low", "normal", "high", "super", "2k", "4k"}
+
+func (d *Quark) play(fid string) (*PlayResp, error) {
+	var resp PlayResp
+	_, err := d.request("/file/v2/play", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"fid":         fid,
+			"resolutions": strings.Join(resolutions, ","),
+			"supports":    "fmp4,m3u8",
+		})
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// waitTask wait for the async task such as saving the share to finish
+func (d *Quark) waitTask(ctx context.Context, taskID string) (*TaskResp, error) {
+	for i := 0; i < 60; i++ {
+		var resp TaskResp
+		_, err := d.request("/task", http.MethodGet, func(req *resty.Request) {
+			req.SetQueryParams(map[string]string{
+				"task_id":     taskID,
+				"retry_index": strconv.Itoa(i),
+			})
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Data.Status == 2 {
+			return &resp, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+	return nil, fmt.Errorf("the task %s isn't finished in time", taskID)
+}
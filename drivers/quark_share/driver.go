@@ -0,0 +1,159 @@
+package quark_share
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/drivers/quark"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// QuarkShare mounts a share of quark read only, the files are saved to the temp dir of the account
+// to download them, and they can be saved to a Quark storage by copying them
+type QuarkShare struct {
+	model.Storage
+	Addition
+	stoken string
+	cron   *cron.Cron
+}
+
+func (d *QuarkShare) Config() driver.Config {
+	return config
+}
+
+func (d *QuarkShare) GetAddition() driver.Additional {
+	return d.Addition
+}
+
+func (d *QuarkShare) Init(ctx context.Context, storage model.Storage) error {
+	d.Storage = storage
+	err := utils.Json.UnmarshalFromString(d.Storage.Addition, &d.Addition)
+	if err != nil {
+		return err
+	}
+	if d.TempDirId == "" {
+		d.TempDirId = "0"
+	}
+	err = d.getStoken()
+	if err != nil {
+		return err
+	}
+	// the stoken expires, and the cookie is renewed by the requests
+	d.cron = cron.NewCron(time.Hour * 2)
+	d.cron.Do(func() {
+		err := d.getStoken()
+		if err != nil {
+			log.Errorf("%+v", err)
+		}
+	})
+	return nil
+}
+
+func (d *QuarkShare) Drop(ctx context.Context) error {
+	if d.cron != nil {
+		d.cron.Stop()
+	}
+	return nil
+}
+
+func (d *QuarkShare) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	files, err := d.getFiles(dir.GetID())
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(files, func(src File) (model.Obj, error) {
+		return fileToObj(src), nil
+	})
+}
+
+func (d *QuarkShare) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	f, ok := file.(*ShareObj)
+	if !ok {
+		return nil, errs.NotSupport
+	}
+	// the files of the share can't be downloaded directly, the one saved before is reused
+	fid, err := d.findSaved(f.GetName(), f.GetSize())
+	if err != nil {
+		return nil, err
+	}
+	if fid == "" {
+		fid, err = d.save(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var resp quark.DownResp
+	_, err = d.request("/file/download", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"fids": []string{fid},
+		})
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("no download url of %s", file.GetName())
+	}
+	return &model.Link{
+		URL: resp.Data[0].DownloadUrl,
+		Header: http.Header{
+			"Cookie":     []string{d.Cookie},
+			"Referer":    []string{referer},
+			"User-Agent": []string{userAgent},
+		},
+	}, nil
+}
+
+func (d *QuarkShare) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	return errs.NotSupport
+}
+
+func (d *QuarkShare) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return errs.NotSupport
+}
+
+func (d *QuarkShare) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	return errs.NotSupport
+}
+
+func (d *QuarkShare) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return errs.NotSupport
+}
+
+func (d *QuarkShare) Remove(ctx context.Context, obj model.Obj) error {
+	return errs.NotSupport
+}
+
+func (d *QuarkShare) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	return errs.NotSupport
+}
+
+// CrossCopy save the object in the share to the drive of a Quark storage
+func (d *QuarkShare) CrossCopy(ctx context.Context, srcObj model.Obj, dst driver.Driver, dstDir model.Obj) error {
+	dstDrive, ok := dst.(*quark.Quark)
+	if !ok || dstDrive.Config().Name != "Quark" {
+		return errs.NotSupport
+	}
+	f, ok := srcObj.(*ShareObj)
+	if !ok {
+		return errs.NotSupport
+	}
+	// the saving isn't retried, so make sure the stoken is valid
+	if err := d.getStoken(); err != nil {
+		return err
+	}
+	_, err := dstDrive.SaveShareFiles(ctx, d.ShareId, d.stoken, []string{f.GetID()}, []string{f.FidToken}, dstDir.GetID())
+	return err
+}
+
+var _ driver.Driver = (*QuarkShare)(nil)
+var _ driver.CrossCopy = (*QuarkShare)(nil)
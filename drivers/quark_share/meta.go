@@ -0,0 +1,30 @@
+package quark_share
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	driver.RootID
+	Cookie         string `json:"cookie" required:"true" secret:"true" help:"the account used to get the download links, it's renewed automatically"`
+	ShareId        string `json:"share_id" required:"true" help:"the id in the share link https://pan.quark.cn/s/<share_id>"`
+	SharePwd       string `json:"share_pwd" secret:"true"`
+	TempDirId      string `json:"temp_dir_id" default:"0" help:"the files of the share are saved to the dir of the account to get the download links"`
+	OrderBy        string `json:"order_by" type:"select" options:"file_type,file_name,updated_at" default:"file_name"`
+	OrderDirection string `json:"order_direction" type:"select" options:"asc,desc" default:"asc"`
+}
+
+var config = driver.Config{
+	Name:              "QuarkShare",
+	NoUpload:          true,
+	OnlyProxy:         true,
+	DefaultRoot:       "0",
+	SupportsRangeRead: true,
+}
+
+func init() {
+	op.RegisterDriver(config, func() driver.Driver {
+		return &QuarkShare{}
+	})
+}
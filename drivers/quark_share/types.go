@@ -0,0 +1,53 @@
+package quark_share
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/quark"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+type File struct {
+	Fid           string `json:"fid"`
+	FileName      string `json:"file_name"`
+	Size          int64  `json:"size"`
+	File          bool   `json:"file"`
+	UpdatedAt     int64  `json:"updated_at"`
+	ShareFidToken string `json:"share_fid_token"`
+}
+
+// ShareObj is the object of the share with the token needed to save it
+type ShareObj struct {
+	model.Object
+	FidToken string
+}
+
+func fileToObj(f File) *ShareObj {
+	return &ShareObj{
+		Object: model.Object{
+			ID:       f.Fid,
+			Name:     f.FileName,
+			Size:     f.Size,
+			Modified: time.UnixMilli(f.UpdatedAt),
+			IsFolder: !f.File,
+		},
+		FidToken: f.ShareFidToken,
+	}
+}
+
+type TokenResp struct {
+	quark.Resp
+	Data struct {
+		Stoken string `json:"stoken"`
+	} `json:"data"`
+}
+
+type DetailResp struct {
+	quark.Resp
+	Data struct {
+		List []File `json:"list"`
+	} `json:"data"`
+	Metadata struct {
+		Total int `json:"_total"`
+	} `json:"metadata"`
+}
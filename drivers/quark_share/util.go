@@ -0,0 +1,189 @@
+package quark_share
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/drivers/quark"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/cookie"
+	"github.com/go-resty/resty/v2"
+)
+
+// do others that not defined in Driver interface
+
+const (
+	apiUrl    = "https://drive.quark.cn/1/clouddrive"
+	referer   = "https://pan.quark.cn"
+	userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) quark-cloud-drive/2.5.20 Chrome/100.0.4896.160 Electron/18.3.5.4-b478491100 Safari/537.36 Channel/pckk_other_ch"
+)
+
+var errCookieExpired = errors.New("the cookie is expired, update it with a new one from the browser")
+
+func (d *QuarkShare) request(pathname string, method string, callback base.ReqCallback, resp interface{}) ([]byte, error) {
+	req := base.RestyClient.R()
+	req.SetHeaders(map[string]string{
+		"Cookie":     d.Cookie,
+		"Accept":     "application/json, text/plain, */*",
+		"Referer":    referer,
+		"User-Agent": userAgent,
+	})
+	req.SetQueryParam("pr", "ucpro")
+	req.SetQueryParam("fr", "pc")
+	if callback != nil {
+		callback(req)
+	}
+	if resp != nil {
+		req.SetResult(resp)
+	}
+	var e quark.Resp
+	req.SetError(&e)
+	res, err := req.Execute(method, apiUrl+pathname)
+	if err != nil {
+		return nil, err
+	}
+	d.renewCookie(res.Cookies())
+	if res.StatusCode() == http.StatusUnauthorized || e.Code == 31001 {
+		return nil, errCookieExpired
+	}
+	if e.Status >= 400 || e.Code != 0 {
+		return nil, errors.New(e.Message)
+	}
+	return res.Body(), nil
+}
+
+// renewCookie update the __puus and __pus of the cookie set by the responses
+func (d *QuarkShare) renewCookie(cookies []*http.Cookie) {
+	renewed := d.Cookie
+	for _, name := range []string{"__puus", "__pus"} {
+		c := cookie.GetCookie(cookies, name)
+		if c != nil && c.Value != "" && cookie.GetStr(renewed, name) != c.Value {
+			renewed = cookie.SetStr(renewed, name, c.Value)
+		}
+	}
+	if renewed != d.Cookie {
+		d.Cookie = renewed
+		op.MustSaveDriverStorage(d)
+	}
+}
+
+// getStoken get the token of the share, which is needed to list and save it
+func (d *QuarkShare) getStoken() error {
+	var resp TokenResp
+	_, err := d.request("/share/sharepage/token", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(map[string]string{
+			"pwd_id":   d.ShareId,
+			"passcode": d.SharePwd,
+		})
+	}, &resp)
+	if err != nil {
+		return err
+	}
+	d.stoken = resp.Data.Stoken
+	return nil
+}
+
+func (d *QuarkShare) getFiles(parent string) ([]File, error) {
+	files := make([]File, 0)
+	size := 50
+	query := map[string]string{
+		"pwd_id":       d.ShareId,
+		"stoken":       d.stoken,
+		"pdir_fid":     parent,
+		"force":        "0",
+		"_size":        strconv.Itoa(size),
+		"_fetch_total": "1",
+		"_sort":        "file_type:asc," + d.OrderBy + ":" + d.OrderDirection,
+	}
+	for page := 1; ; page++ {
+		query["_page"] = strconv.Itoa(page)
+		var resp DetailResp
+		_, err := d.request("/share/sharepage/detail", http.MethodGet, func(req *resty.Request) {
+			req.SetQueryParams(query)
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, resp.Data.List...)
+		if len(resp.Data.List) == 0 || page*size >= resp.Metadata.Total {
+			break
+		}
+	}
+	return files, nil
+}
+
+// findSaved find the file of the name and the size in the temp dir, which is saved before
+func (d *QuarkShare) findSaved(name string, size int64) (string, error) {
+	pageSize := 100
+	query := map[string]string{
+		"pdir_fid":     d.TempDirId,
+		"_size":        strconv.Itoa(pageSize),
+		"_fetch_total": "1",
+	}
+	for page := 1; ; page++ {
+		query["_page"] = strconv.Itoa(page)
+		var resp quark.SortResp
+		_, err := d.request("/file/sort", http.MethodGet, func(req *resty.Request) {
+			req.SetQueryParams(query)
+		}, &resp)
+		if err != nil {
+			return "", err
+		}
+		for _, f := range resp.Data.List {
+			if f.File && f.FileName == name && f.Size == size {
+				return f.Fid, nil
+			}
+		}
+		if len(resp.Data.List) == 0 || page*pageSize >= resp.Metadata.Total {
+			return "", nil
+		}
+	}
+}
+
+// save save the file of the share to the temp dir and return the id of the saved one
+func (d *QuarkShare) save(ctx context.Context, file *ShareObj) (string, error) {
+	var resp quark.SaveResp
+	_, err := d.request("/share/sharepage/save", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{
+			"fid_list":       []string{file.GetID()},
+			"fid_token_list": []string{file.FidToken},
+			"to_pdir_fid":    d.TempDirId,
+			"pwd_id":         d.ShareId,
+			"stoken":         d.stoken,
+			"pdir_fid":       "0",
+			"scene":          "link",
+		})
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	for i := 0; i < 60; i++ {
+		var task quark.TaskResp
+		_, err = d.request("/task", http.MethodGet, func(req *resty.Request) {
+			req.SetQueryParams(map[string]string{
+				"task_id":     resp.Data.TaskID,
+				"retry_index": strconv.Itoa(i),
+			})
+		}, &task)
+		if err != nil {
+			return "", err
+		}
+		if task.Data.Status == 2 {
+			if len(task.Data.SaveAs.SaveAsTopFids) == 0 {
+				return "", fmt.Errorf("failed save %s", file.GetName())
+			}
+			return task.Data.SaveAs.SaveAsTopFids[0], nil
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+	return "", fmt.Errorf("saving %s isn't finished in time", file.GetName())
+}
@@ -10,11 +10,13 @@ import (
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -40,13 +42,22 @@ func (d *S3) Init(ctx context.Context, storage model.Storage) error {
 	if err != nil {
 		return err
 	}
-	if d.Region == "" {
-		d.Region = "alist"
+	detect := d.Region == ""
+	if detect {
+		d.Region = defaultRegion
 	}
 	err = d.initSession()
 	if err != nil {
 		return err
 	}
+	if detect {
+		if region := d.detectRegion(ctx); region != d.Region {
+			d.Region = region
+			if err = d.initSession(); err != nil {
+				return err
+			}
+		}
+	}
 	d.client = d.getClient(false)
 	d.linkClient = d.getClient(true)
 	return nil
@@ -68,6 +79,36 @@ func (d *S3) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]mo
 //	return nil, errs.NotImplement
 //}
 
+// the most objects listed to count the usage, it's 10 pages of ListObjectsV2
+const maxQuotaObjects = 10000
+
+// GetQuota s3 has no limit of capacity, so just count the used size in the root folder,
+// it's given up if there are more objects than maxQuotaObjects, since every object is listed
+func (d *S3) GetQuota(ctx context.Context) (*model.StorageQuota, error) {
+	prefix := getKey(d.RootFolderPath, true)
+	input := &s3.ListObjectsV2Input{
+		Bucket: &d.Bucket,
+		Prefix: &prefix,
+	}
+	var used, count int64
+	err := d.client.ListObjectsV2PagesWithContext(ctx, input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			used += *object.Size
+		}
+		count += int64(len(page.Contents))
+		return count <= maxQuotaObjects
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count > maxQuotaObjects {
+		return nil, errors.WithMessagef(errs.NotSupport, "more than %d objects to count the usage", maxQuotaObjects)
+	}
+	return &model.StorageQuota{
+		Used: used,
+	}, nil
+}
+
 func (d *S3) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
 	path := getKey(file.GetPath(), false)
 	disposition := fmt.Sprintf(`attachment;filename="%s"`, url.QueryEscape(stdpath.Base(path)))
@@ -148,8 +189,11 @@ func (d *S3) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreame
 		Key:    &key,
 		Body:   stream,
 	}
+	input.ServerSideEncryption, input.SSEKMSKeyId, input.StorageClass, input.ACL = d.writeOptions()
 	_, err := uploader.Upload(input)
 	return err
 }
 
 var _ driver.Driver = (*S3)(nil)
+var _ driver.Quota = (*S3)(nil)
+var _ driver.DirectUploader = (*S3)(nil)
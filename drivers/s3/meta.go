@@ -9,20 +9,29 @@ type Addition struct {
 	driver.RootPath
 	Bucket            string `json:"bucket" required:"true"`
 	Endpoint          string `json:"endpoint" required:"true"`
-	Region            string `json:"region"`
+	Region            string `json:"region" help:"it's detected by the bucket if it's empty"`
 	AccessKeyID       string `json:"access_key_id" required:"true"`
-	SecretAccessKey   string `json:"secret_access_key" required:"true"`
+	SecretAccessKey   string `json:"secret_access_key" required:"true" secret:"true"`
 	CustomHost        string `json:"custom_host"`
 	SignURLExpire     int    `json:"sign_url_expire" type:"number" default:"4"`
 	Placeholder       string `json:"placeholder"`
-	ForcePathStyle    bool   `json:"force_path_style"`
+	ForcePathStyle    bool   `json:"force_path_style" help:"it's used without setting it if the endpoint is an ip or has a port, such as minio"`
 	ListObjectVersion string `json:"list_object_version" type:"select" options:"v1,v2" default:"v1"`
+	// the clients upload the files to the bucket with the presigned urls, the bucket must allow them by cors
+	DirectUpload       bool   `json:"direct_upload" help:"the clients upload to the bucket directly with the presigned urls, the cors of the bucket must allow them"`
+	MultipartThreshold int    `json:"multipart_threshold" type:"number" default:"100" help:"MB, the files larger than it are uploaded directly by multipart"`
+	Encryption         string `json:"encryption" type:"select" options:"none,AES256,aws:kms" default:"none" help:"the server side encryption of the files uploaded"`
+	KMSKeyID           string `json:"kms_key_id" help:"the id of the kms key if the encryption is aws:kms, the default key of the bucket is used if it's empty"`
+	StorageClass       string `json:"storage_class" type:"select" options:"default,STANDARD,STANDARD_IA,ONEZONE_IA,INTELLIGENT_TIERING,GLACIER_IR" default:"default" help:"the storage class of the files uploaded"`
+	ACL                string `json:"acl" type:"select" options:"default,private,public-read,authenticated-read,bucket-owner-full-control" default:"default" help:"the canned acl of the files uploaded"`
 }
 
 var config = driver.Config{
-	Name:        "S3",
-	LocalSort:   true,
-	CheckStatus: true,
+	Name:                   "S3",
+	LocalSort:              true,
+	CheckStatus:            true,
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
 }
 
 func New() driver.Driver {
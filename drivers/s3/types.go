@@ -1 +1,21 @@
 package s3
+
+import (
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+// etagHash get the hash from the etag, it's the md5 of the content if the object
+// is not uploaded by multipart, or it's like `<md5 of the md5s>-<parts>`
+// that can only be compared with itself
+func etagHash(etag *string) model.HashInfo {
+	if etag == nil {
+		return model.HashInfo{}
+	}
+	value := strings.ToLower(strings.Trim(*etag, `"`))
+	if strings.Contains(value, "-") {
+		return model.HashInfo{Type: "etag", Value: value}
+	}
+	return model.HashInfo{Type: "md5", Value: value}
+}
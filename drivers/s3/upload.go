@@ -0,0 +1,129 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"sort"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	// the size of the parts of the multipart uploads, it's increased for the large files
+	partSize = 16 * 1024 * 1024
+	// the most parts of a multipart upload s3 allows
+	maxParts = 10000
+)
+
+func (d *S3) presignExpire() time.Duration {
+	if d.SignURLExpire <= 0 {
+		return time.Hour
+	}
+	return time.Hour * time.Duration(d.SignURLExpire)
+}
+
+// DirectUpload presign the url to put the file, or create the multipart upload and presign
+// the urls of the parts if the file is larger than the threshold
+func (d *S3) DirectUpload(ctx context.Context, dstDir model.Obj, name string, size int64) (*model.DirectUpload, error) {
+	if !d.Addition.DirectUpload {
+		return nil, errs.NotSupport
+	}
+	key := getKey(stdpath.Join(dstDir.GetPath(), name), false)
+	threshold := int64(d.MultipartThreshold) * 1024 * 1024
+	if d.MultipartThreshold <= 0 || size <= threshold {
+		input := &s3.PutObjectInput{
+			Bucket: &d.Bucket,
+			Key:    &key,
+		}
+		input.ServerSideEncryption, input.SSEKMSKeyId, input.StorageClass, input.ACL = d.writeOptions()
+		req, _ := d.client.PutObjectRequest(input)
+		req.SetContext(ctx)
+		// the headers of the options are signed, the client must send them too
+		u, header, err := req.PresignRequest(d.presignExpire())
+		if err != nil {
+			return nil, err
+		}
+		res := &model.DirectUpload{URL: u}
+		if len(header) > 0 {
+			res.Header = make(map[string]string, len(header))
+			// the keys of the signed headers are in lower case, so they aren't got by header.Get
+			for k, v := range header {
+				if len(v) > 0 {
+					res.Header[k] = v[0]
+				}
+			}
+		}
+		return res, nil
+	}
+	ps := int64(partSize)
+	for (size+ps-1)/ps > maxParts {
+		ps *= 2
+	}
+	count := int((size + ps - 1) / ps)
+	input := &s3.CreateMultipartUploadInput{
+		Bucket: &d.Bucket,
+		Key:    &key,
+	}
+	input.ServerSideEncryption, input.SSEKMSKeyId, input.StorageClass, input.ACL = d.writeOptions()
+	up, err := d.client.CreateMultipartUploadWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	res := &model.DirectUpload{
+		UploadID: *up.UploadId,
+		PartSize: ps,
+		PartURLs: make([]string, count),
+	}
+	for i := 0; i < count; i++ {
+		req, _ := d.client.UploadPartRequest(&s3.UploadPartInput{
+			Bucket:     &d.Bucket,
+			Key:        &key,
+			UploadId:   up.UploadId,
+			PartNumber: aws.Int64(int64(i + 1)),
+		})
+		req.SetContext(ctx)
+		if res.PartURLs[i], err = req.Presign(d.presignExpire()); err != nil {
+			_, _ = d.client.AbortMultipartUploadWithContext(ctx, &s3.AbortMultipartUploadInput{
+				Bucket:   &d.Bucket,
+				Key:      &key,
+				UploadId: up.UploadId,
+			})
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// CompleteDirectUpload complete the multipart upload with the etags of the parts,
+// nothing is needed if the file is put in one request
+func (d *S3) CompleteDirectUpload(ctx context.Context, dstDir model.Obj, name string, uploadID string, parts []model.DirectUploadPart) error {
+	if uploadID == "" {
+		return nil
+	}
+	if len(parts) == 0 {
+		return fmt.Errorf("no part is uploaded")
+	}
+	key := getKey(stdpath.Join(dstDir.GetPath(), name), false)
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].Number < parts[j].Number
+	})
+	completed := make([]*s3.CompletedPart, 0, len(parts))
+	for _, p := range parts {
+		completed = append(completed, &s3.CompletedPart{
+			ETag:       aws.String(p.ETag),
+			PartNumber: aws.Int64(int64(p.Number)),
+		})
+	}
+	_, err := d.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          &d.Bucket,
+		Key:             &key,
+		UploadId:        &uploadID,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: completed},
+	})
+	return err
+}
@@ -3,9 +3,12 @@ package s3
 import (
 	"context"
 	"errors"
+	"net"
 	"net/http"
+	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
@@ -14,23 +17,75 @@ import (
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	log "github.com/sirupsen/logrus"
 )
 
 // do others that not defined in Driver interface
 
+// the region the region of the bucket is detected with
+const defaultRegion = "us-east-1"
+
 func (d *S3) initSession() error {
 	cfg := &aws.Config{
 		Credentials:      credentials.NewStaticCredentials(d.AccessKeyID, d.SecretAccessKey, ""),
 		Region:           &d.Region,
-		Endpoint:         &d.Endpoint,
-		S3ForcePathStyle: aws.Bool(d.ForcePathStyle),
+		Endpoint:         aws.String(strings.TrimSuffix(d.Endpoint, "/")),
+		S3ForcePathStyle: aws.Bool(d.pathStyle()),
 	}
 	var err error
 	d.Session, err = session.NewSession(cfg)
 	return err
 }
 
+// pathStyle check if the bucket should be in the path of the urls instead of the host,
+// the endpoints of the ips and the ones with ports such as minio can't have the bucket as the subdomain
+func (d *S3) pathStyle() bool {
+	if d.ForcePathStyle {
+		return true
+	}
+	endpoint := d.Endpoint
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	return u.Port() != "" || net.ParseIP(host) != nil || host == "localhost"
+}
+
+// detectRegion get the region of the bucket, the region is required by the signatures of aws,
+// and "alist" is used if it can't be detected, which most of the s3 compatible services accept
+func (d *S3) detectRegion(ctx context.Context) string {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	region, err := s3manager.GetBucketRegion(ctx, d.Session, d.Bucket, defaultRegion)
+	if err != nil || region == "" {
+		log.Warnf("failed detect the region of the bucket %s, use alist: %v", d.Bucket, err)
+		return "alist"
+	}
+	return region
+}
+
+// writeOptions get the encryption, the storage class and the acl of the objects written, nil if they are default
+func (d *S3) writeOptions() (sse, kmsKeyID, class, acl *string) {
+	if d.Encryption != "" && d.Encryption != "none" {
+		sse = aws.String(d.Encryption)
+		if d.Encryption == s3.ServerSideEncryptionAwsKms && d.KMSKeyID != "" {
+			kmsKeyID = aws.String(d.KMSKeyID)
+		}
+	}
+	if d.StorageClass != "" && d.StorageClass != "default" {
+		class = aws.String(d.StorageClass)
+	}
+	if d.ACL != "" && d.ACL != "default" {
+		acl = aws.String(d.ACL)
+	}
+	return
+}
+
 func (d *S3) getClient(link bool) *s3.S3 {
 	client := s3.New(d.Session)
 	if link && d.CustomHost != "" {
@@ -97,6 +152,7 @@ func (d *S3) listV1(prefix string) ([]model.Obj, error) {
 				Name:     name,
 				Size:     *object.Size,
 				Modified: *object.LastModified,
+				Hash:     etagHash(object.ETag),
 			}
 			files = append(files, &file)
 		}
@@ -149,6 +205,7 @@ func (d *S3) listV2(prefix string) ([]model.Obj, error) {
 				Name:     name,
 				Size:     *object.Size,
 				Modified: *object.LastModified,
+				Hash:     etagHash(object.ETag),
 			}
 			files = append(files, &file)
 		}
@@ -182,6 +239,7 @@ func (d *S3) copyFile(ctx context.Context, src string, dst string) error {
 		CopySource: aws.String("/" + d.Bucket + "/" + srcKey),
 		Key:        &dstKey,
 	}
+	input.ServerSideEncryption, input.SSEKMSKeyId, input.StorageClass, input.ACL = d.writeOptions()
 	_, err := d.client.CopyObject(input)
 	return err
 }
@@ -2,20 +2,23 @@ package sftp
 
 import (
 	"context"
+	"io"
 	"os"
 	"path"
+	"sync"
 
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/utils"
-	"github.com/pkg/sftp"
 )
 
 type SFTP struct {
 	model.Storage
 	Addition
-	client *sftp.Client
+	mu    sync.Mutex
+	conns []*conn
+	next  int
 }
 
 func (d *SFTP) Config() driver.Config {
@@ -32,18 +35,20 @@ func (d *SFTP) Init(ctx context.Context, storage model.Storage) error {
 	if err != nil {
 		return err
 	}
-	return d.initClient()
+	return d.initPool()
 }
 
 func (d *SFTP) Drop(ctx context.Context) error {
-	if d.client != nil {
-		_ = d.client.Close()
-	}
+	d.closePool()
 	return nil
 }
 
 func (d *SFTP) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
-	files, err := d.client.ReadDir(dir.GetPath())
+	client, err := d.getClient()
+	if err != nil {
+		return nil, err
+	}
+	files, err := client.ReadDir(dir.GetPath())
 	if err != nil {
 		return nil, err
 	}
@@ -58,25 +63,56 @@ func (d *SFTP) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]
 //}
 
 func (d *SFTP) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
-	remoteFile, err := d.client.Open(file.GetPath())
+	client, err := d.getClient()
+	if err != nil {
+		return nil, err
+	}
+	remoteFile, err := client.Open(file.GetPath())
 	if err != nil {
 		return nil, err
 	}
 	return &model.Link{
 		Data: remoteFile,
+		RangeReader: func(offset int64) (io.ReadCloser, error) {
+			client, err := d.getClient()
+			if err != nil {
+				return nil, err
+			}
+			f, err := client.Open(file.GetPath())
+			if err != nil {
+				return nil, err
+			}
+			if _, err = f.Seek(offset, io.SeekStart); err != nil {
+				_ = f.Close()
+				return nil, err
+			}
+			return f, nil
+		},
 	}, nil
 }
 
 func (d *SFTP) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
-	return d.client.MkdirAll(path.Join(parentDir.GetPath(), dirName))
+	client, err := d.getClient()
+	if err != nil {
+		return err
+	}
+	return client.MkdirAll(path.Join(parentDir.GetPath(), dirName))
 }
 
 func (d *SFTP) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
-	return d.client.Rename(srcObj.GetPath(), path.Join(dstDir.GetPath(), srcObj.GetName()))
+	client, err := d.getClient()
+	if err != nil {
+		return err
+	}
+	return rename(client, srcObj.GetPath(), path.Join(dstDir.GetPath(), srcObj.GetName()))
 }
 
 func (d *SFTP) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
-	return d.client.Rename(srcObj.GetPath(), path.Join(path.Dir(srcObj.GetPath()), newName))
+	client, err := d.getClient()
+	if err != nil {
+		return err
+	}
+	return rename(client, srcObj.GetPath(), path.Join(path.Dir(srcObj.GetPath()), newName))
 }
 
 func (d *SFTP) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
@@ -84,11 +120,19 @@ func (d *SFTP) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
 }
 
 func (d *SFTP) Remove(ctx context.Context, obj model.Obj) error {
-	return d.remove(obj.GetPath())
+	client, err := d.getClient()
+	if err != nil {
+		return err
+	}
+	return remove(client, obj.GetPath())
 }
 
 func (d *SFTP) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
-	dstFile, err := d.client.Create(path.Join(dstDir.GetPath(), stream.GetName()))
+	client, err := d.getClient()
+	if err != nil {
+		return err
+	}
+	dstFile, err := client.Create(path.Join(dstDir.GetPath(), stream.GetName()))
 	if err != nil {
 		return err
 	}
@@ -8,17 +8,22 @@ import (
 type Addition struct {
 	Address    string `json:"address" required:"true"`
 	Username   string `json:"username" required:"true"`
-	PrivateKey string `json:"private_key" type:"text"`
-	Password   string `json:"password"`
+	PrivateKey string `json:"private_key" type:"text" secret:"true"`
+	// the passphrase of the private key if it's encrypted
+	PrivateKeyPassphrase string `json:"private_key_passphrase" secret:"true"`
+	Password             string `json:"password" secret:"true"`
+	HostKey              string `json:"host_key" help:"the public key of the server, such as the line of it in known_hosts or the SHA256 fingerprint of it, the host key isn't verified if it's empty"`
+	Connections          int    `json:"connections" type:"number" default:"4" help:"the connections in the pool, the transfers are made over them concurrently"`
 	driver.RootPath
 }
 
 var config = driver.Config{
-	Name:        "SFTP",
-	LocalSort:   true,
-	OnlyLocal:   true,
-	DefaultRoot: "/",
-	CheckStatus: true,
+	Name:              "SFTP",
+	LocalSort:         true,
+	OnlyLocal:         true,
+	DefaultRoot:       "/",
+	CheckStatus:       true,
+	SupportsRangeRead: true,
 }
 
 func New() driver.Driver {
@@ -1,72 +1,196 @@
 package sftp
 
 import (
+	"fmt"
+	"net"
 	"path"
+	"strings"
 
 	"github.com/pkg/sftp"
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/crypto/ssh"
 )
 
 // do others that not defined in Driver interface
 
-func (d *SFTP) initClient() error {
-	var auth ssh.AuthMethod
+// conn is a connection of the pool
+type conn struct {
+	ssh  *ssh.Client
+	sftp *sftp.Client
+}
+
+func (c *conn) close() {
+	_ = c.sftp.Close()
+	_ = c.ssh.Close()
+}
+
+func (d *SFTP) authMethods() ([]ssh.AuthMethod, error) {
+	var auth []ssh.AuthMethod
 	if d.PrivateKey != "" {
-		signer, err := ssh.ParsePrivateKey([]byte(d.PrivateKey))
+		var signer ssh.Signer
+		var err error
+		if d.PrivateKeyPassphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(d.PrivateKey), []byte(d.PrivateKeyPassphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey([]byte(d.PrivateKey))
+		}
 		if err != nil {
-			return err
+			return nil, err
 		}
-		auth = ssh.PublicKeys(signer)
-	} else {
-		auth = ssh.Password(d.Password)
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if d.Password != "" || len(auth) == 0 {
+		auth = append(auth, ssh.Password(d.Password))
+	}
+	return auth, nil
+}
+
+// hostKeyCallback verify the host key by the line of known_hosts, the authorized key or the SHA256 fingerprint of it
+func (d *SFTP) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	hostKey := strings.TrimSpace(d.HostKey)
+	if hostKey == "" {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	if strings.HasPrefix(hostKey, "SHA256:") {
+		return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			if fp := ssh.FingerprintSHA256(key); fp != hostKey {
+				return fmt.Errorf("the host key of %s is %s, which mismatches the one set", hostname, fp)
+			}
+			return nil
+		}, nil
+	}
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey))
+	if err != nil {
+		_, _, key, _, _, err = ssh.ParseKnownHosts([]byte(hostKey))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid host key: %w", err)
+	}
+	return ssh.FixedHostKey(key), nil
+}
+
+func (d *SFTP) dial() (*conn, error) {
+	auth, err := d.authMethods()
+	if err != nil {
+		return nil, err
+	}
+	hostKeyCallback, err := d.hostKeyCallback()
+	if err != nil {
+		return nil, err
 	}
 	config := &ssh.ClientConfig{
 		User:            d.Username,
-		Auth:            []ssh.AuthMethod{auth},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
 	}
-	conn, err := ssh.Dial("tcp", d.Address, config)
+	sshClient, err := ssh.Dial("tcp", d.Address, config)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	sftpClient, err := sftp.NewClient(sshClient)
+	if err != nil {
+		_ = sshClient.Close()
+		return nil, err
 	}
-	d.client, err = sftp.NewClient(conn)
+	return &conn{ssh: sshClient, sftp: sftpClient}, nil
+}
+
+func (d *SFTP) initPool() error {
+	size := d.Connections
+	if size <= 0 {
+		size = 1
+	}
+	d.conns = make([]*conn, size)
+	// the first one is connected to check the config
+	_, err := d.getClient()
 	return err
 }
 
-func (d *SFTP) remove(remotePath string) error {
-	f, err := d.client.Stat(remotePath)
+// getClient get the connections of the pool in turn, so the transfers are made over them concurrently,
+// the broken one is connected again
+func (d *SFTP) getClient() (*sftp.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.conns) == 0 {
+		return nil, fmt.Errorf("the storage is dropped")
+	}
+	i := d.next % len(d.conns)
+	d.next++
+	if c := d.conns[i]; c != nil {
+		return c.sftp, nil
+	}
+	c, err := d.dial()
+	if err != nil {
+		return nil, err
+	}
+	d.conns[i] = c
+	go func() {
+		err := c.sftp.Wait()
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if i < len(d.conns) && d.conns[i] == c {
+			log.Debugf("the connection %d of sftp is closed: %v", i, err)
+			d.conns[i] = nil
+			c.close()
+		}
+	}()
+	return c.sftp, nil
+}
+
+func (d *SFTP) closePool() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	conns := d.conns
+	d.conns = nil
+	for _, c := range conns {
+		if c != nil {
+			c.close()
+		}
+	}
+}
+
+// rename rename the file by the server, the dst is replaced if the server supports posix-rename@openssh.com
+func rename(client *sftp.Client, oldPath, newPath string) error {
+	if _, ok := client.HasExtension("posix-rename@openssh.com"); ok {
+		return client.PosixRename(oldPath, newPath)
+	}
+	return client.Rename(oldPath, newPath)
+}
+
+func remove(client *sftp.Client, remotePath string) error {
+	f, err := client.Stat(remotePath)
 	if err != nil {
 		return nil
 	}
 	if f.IsDir() {
-		return d.removeDirectory(remotePath)
+		return removeDirectory(client, remotePath)
 	} else {
-		return d.removeFile(remotePath)
+		return removeFile(client, remotePath)
 	}
 }
 
-func (d *SFTP) removeDirectory(remotePath string) error {
-	remoteFiles, err := d.client.ReadDir(remotePath)
+func removeDirectory(client *sftp.Client, remotePath string) error {
+	remoteFiles, err := client.ReadDir(remotePath)
 	if err != nil {
 		return err
 	}
 	for _, backupDir := range remoteFiles {
 		remoteFilePath := path.Join(remotePath, backupDir.Name())
 		if backupDir.IsDir() {
-			err := d.removeDirectory(remoteFilePath)
+			err := removeDirectory(client, remoteFilePath)
 			if err != nil {
 				return err
 			}
 		} else {
-			err := d.removeFile(remoteFilePath)
+			err := removeFile(client, remoteFilePath)
 			if err != nil {
 				return err
 			}
 		}
 	}
-	return d.client.RemoveDirectory(remotePath)
+	return client.RemoveDirectory(remotePath)
 }
 
-func (d *SFTP) removeFile(remotePath string) error {
-	return d.client.Remove(path.Join(remotePath))
+func removeFile(client *sftp.Client, remotePath string) error {
+	return client.Remove(path.Join(remotePath))
 }
@@ -0,0 +1,178 @@
+package smb
+
+import (
+	"context"
+	"io"
+	"os"
+	stdpath "path"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/hirochachacha/go-smb2"
+)
+
+type SMB struct {
+	model.Storage
+	Addition
+	mu      sync.Mutex
+	session *smb2.Session
+	share   *smb2.Share
+}
+
+func (d *SMB) Config() driver.Config {
+	return config
+}
+
+func (d *SMB) GetAddition() driver.Additional {
+	return d.Addition
+}
+
+func (d *SMB) Init(ctx context.Context, storage model.Storage) error {
+	d.Storage = storage
+	err := utils.Json.UnmarshalFromString(d.Storage.Addition, &d.Addition)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.connect()
+}
+
+func (d *SMB) Drop(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.close()
+	return nil
+}
+
+func (d *SMB) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	share, err := d.getShare()
+	if err != nil {
+		return nil, err
+	}
+	files, err := share.ReadDir(sharePath(dir.GetPath()))
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(files, func(src os.FileInfo) (model.Obj, error) {
+		return fileToObj(src), nil
+	})
+}
+
+//func (d *SMB) Get(ctx context.Context, path string) (model.Obj, error) {
+//	// this is optional
+//	return nil, errs.NotImplement
+//}
+
+func (d *SMB) GetQuota(ctx context.Context) (*model.StorageQuota, error) {
+	share, err := d.getShare()
+	if err != nil {
+		return nil, err
+	}
+	info, err := share.Statfs(sharePath(d.GetRootPath()))
+	if err != nil {
+		return nil, err
+	}
+	block := int64(info.BlockSize())
+	total := int64(info.TotalBlockCount()) * block
+	free := int64(info.AvailableBlockCount()) * block
+	return &model.StorageQuota{
+		Total: total,
+		Used:  total - free,
+		Free:  free,
+	}, nil
+}
+
+func (d *SMB) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	share, err := d.getShare()
+	if err != nil {
+		return nil, err
+	}
+	remoteFile, err := share.Open(sharePath(file.GetPath()))
+	if err != nil {
+		return nil, err
+	}
+	return &model.Link{
+		Data: remoteFile,
+		RangeReader: func(offset int64) (io.ReadCloser, error) {
+			f, err := share.Open(sharePath(file.GetPath()))
+			if err != nil {
+				return nil, err
+			}
+			if _, err = f.Seek(offset, io.SeekStart); err != nil {
+				_ = f.Close()
+				return nil, err
+			}
+			return f, nil
+		},
+	}, nil
+}
+
+func (d *SMB) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	share, err := d.getShare()
+	if err != nil {
+		return err
+	}
+	return share.MkdirAll(sharePath(stdpath.Join(parentDir.GetPath(), dirName)), 0755)
+}
+
+func (d *SMB) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	share, err := d.getShare()
+	if err != nil {
+		return err
+	}
+	return share.Rename(sharePath(srcObj.GetPath()), sharePath(stdpath.Join(dstDir.GetPath(), srcObj.GetName())))
+}
+
+func (d *SMB) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	share, err := d.getShare()
+	if err != nil {
+		return err
+	}
+	return share.Rename(sharePath(srcObj.GetPath()), sharePath(stdpath.Join(stdpath.Dir(srcObj.GetPath()), newName)))
+}
+
+func (d *SMB) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	share, err := d.getShare()
+	if err != nil {
+		return err
+	}
+	src := sharePath(srcObj.GetPath())
+	dst := sharePath(stdpath.Join(dstDir.GetPath(), srcObj.GetName()))
+	if srcObj.IsDir() {
+		return copyDir(share, src, dst)
+	}
+	return copyFile(share, src, dst)
+}
+
+func (d *SMB) Remove(ctx context.Context, obj model.Obj) error {
+	share, err := d.getShare()
+	if err != nil {
+		return err
+	}
+	if obj.IsDir() {
+		return share.RemoveAll(sharePath(obj.GetPath()))
+	}
+	return share.Remove(sharePath(obj.GetPath()))
+}
+
+func (d *SMB) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	share, err := d.getShare()
+	if err != nil {
+		return err
+	}
+	dstFile, err := share.Create(sharePath(stdpath.Join(dstDir.GetPath(), stream.GetName())))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = dstFile.Close()
+	}()
+	err = utils.CopyWithCtx(ctx, dstFile, stream, stream.GetSize(), up)
+	return err
+}
+
+var _ driver.Driver = (*SMB)(nil)
+var _ driver.Quota = (*SMB)(nil)
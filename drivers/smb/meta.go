@@ -0,0 +1,32 @@
+package smb
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	Address   string `json:"address" required:"true" help:"host:port, the port is 445 if it's omitted"`
+	Username  string `json:"username" required:"true"`
+	Password  string `json:"password" secret:"true"`
+	Domain    string `json:"domain" help:"the domain or the workgroup of the user, it's empty for the most of the nas"`
+	ShareName string `json:"share_name" required:"true"`
+	driver.RootPath
+}
+
+var config = driver.Config{
+	Name:              "SMB",
+	LocalSort:         true,
+	OnlyLocal:         true,
+	DefaultRoot:       "/",
+	CheckStatus:       true,
+	SupportsRangeRead: true,
+}
+
+func New() driver.Driver {
+	return &SMB{}
+}
+
+func init() {
+	op.RegisterDriver(config, New)
+}
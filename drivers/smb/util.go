@@ -0,0 +1,122 @@
+package smb
+
+import (
+	"io"
+	"net"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/hirochachacha/go-smb2"
+)
+
+// do others that not defined in Driver interface
+
+func (d *SMB) address() string {
+	if _, _, err := net.SplitHostPort(d.Address); err != nil {
+		return net.JoinHostPort(d.Address, "445")
+	}
+	return d.Address
+}
+
+// connect dial the server and mount the share, the connection before is closed
+func (d *SMB) connect() error {
+	d.close()
+	conn, err := net.DialTimeout("tcp", d.address(), 10*time.Second)
+	if err != nil {
+		return err
+	}
+	dialer := &smb2.Dialer{
+		Initiator: &smb2.NTLMInitiator{
+			User:     d.Username,
+			Password: d.Password,
+			Domain:   d.Domain,
+		},
+	}
+	session, err := dialer.Dial(conn)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	share, err := session.Mount(d.ShareName)
+	if err != nil {
+		_ = session.Logoff()
+		return err
+	}
+	d.session, d.share = session, share
+	return nil
+}
+
+func (d *SMB) close() {
+	if d.share != nil {
+		_ = d.share.Umount()
+		d.share = nil
+	}
+	if d.session != nil {
+		_ = d.session.Logoff()
+		d.session = nil
+	}
+}
+
+// getShare get the share mounted, it's mounted again if the connection is broken,
+// such as the server restarted or closed the idle connection
+func (d *SMB) getShare() (*smb2.Share, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.share != nil {
+		if _, err := d.share.Stat("."); err == nil {
+			return d.share, nil
+		}
+	}
+	if err := d.connect(); err != nil {
+		return nil, err
+	}
+	return d.share, nil
+}
+
+// sharePath get the path in the share, which is relative to the root of the share
+func sharePath(path string) string {
+	path = strings.TrimPrefix(stdpath.Clean("/"+path), "/")
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+// copyFile copy the file, the server copies it by itself if it supports the server side copy
+func copyFile(share *smb2.Share, src, dst string) error {
+	srcFile, err := share.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+	dstFile, err := share.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+	_, err = io.Copy(dstFile, srcFile)
+	return err
+}
+
+func copyDir(share *smb2.Share, src, dst string) error {
+	if err := share.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	files, err := share.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		s, t := stdpath.Join(src, f.Name()), stdpath.Join(dst, f.Name())
+		if f.IsDir() {
+			err = copyDir(share, s, t)
+		} else {
+			err = copyFile(share, s, t)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -7,7 +7,7 @@ import (
 
 type Addition struct {
 	Region    string `json:"region" type:"select" options:"china,international" required:"true"`
-	Cookie    string `json:"cookie" required:"true"`
+	Cookie    string `json:"cookie" required:"true" secret:"true"`
 	ProjectID string `json:"project_id" required:"true"`
 	driver.RootID
 	OrderBy        string `json:"order_by" type:"select" options:"fileName,fileSize,updated,created" default:"fileName"`
@@ -15,7 +15,9 @@ type Addition struct {
 }
 
 var config = driver.Config{
-	Name: "Teambition",
+	Name:                   "Teambition",
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
 }
 
 func New() driver.Driver {
@@ -0,0 +1,229 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"io"
+	stdpath "path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type Telegram struct {
+	model.Storage
+	Addition
+	// mu guard the index, it's saved to the channel after every change
+	mu      sync.Mutex
+	root    *Node
+	indexID int
+}
+
+func (d *Telegram) Config() driver.Config {
+	return config
+}
+
+func (d *Telegram) GetAddition() driver.Additional {
+	return d.Addition
+}
+
+func (d *Telegram) Init(ctx context.Context, storage model.Storage) error {
+	d.Storage = storage
+	err := utils.Json.UnmarshalFromString(d.Storage.Addition, &d.Addition)
+	if err != nil {
+		return err
+	}
+	if d.APIUrl == "" {
+		d.APIUrl = "https://api.telegram.org"
+	}
+	if d.PartSize <= 0 || d.PartSize > 2000 {
+		d.PartSize = 20
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.loadIndex(ctx)
+}
+
+func (d *Telegram) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *Telegram) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	node, err := d.findDir(dir.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(node.Children, func(src *Node) (model.Obj, error) {
+		return nodeToObj(src), nil
+	})
+}
+
+func (d *Telegram) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	d.mu.Lock()
+	node, err := d.find(file.GetPath())
+	d.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	parts := node.Parts
+	// the link may be read after the request of it, so the downloads don't use its context
+	open := func(offset int64) (io.ReadCloser, error) {
+		return &partsReader{ctx: context.Background(), d: d, parts: parts, offset: offset}, nil
+	}
+	data, _ := open(0)
+	return &model.Link{
+		Data:        data,
+		RangeReader: open,
+	}, nil
+}
+
+func (d *Telegram) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	parent, err := d.findDir(parentDir.GetPath())
+	if err != nil {
+		return err
+	}
+	if parent.child(dirName) != nil {
+		return fmt.Errorf("%s already exists", dirName)
+	}
+	parent.Children = append(parent.Children, &Node{Name: dirName, IsDir: true, Modified: time.Now()})
+	return d.commit(ctx)
+}
+
+func (d *Telegram) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.relocate(ctx, srcObj.GetPath(), dstDir.GetPath(), srcObj.GetName())
+}
+
+func (d *Telegram) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	return d.relocate(ctx, srcObj.GetPath(), stdpath.Dir(srcObj.GetPath()), newName)
+}
+
+func (d *Telegram) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	node, err := d.find(srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	dst, err := d.findDir(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	if dst.child(node.Name) != nil {
+		return fmt.Errorf("%s already exists", node.Name)
+	}
+	n, err := d.copyNode(ctx, node)
+	if err != nil {
+		return err
+	}
+	dst.Children = append(dst.Children, n)
+	if err = d.commit(ctx); err != nil {
+		d.deleteMessages(n.messages())
+		return err
+	}
+	return nil
+}
+
+func (d *Telegram) Remove(ctx context.Context, obj model.Obj) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	parent, err := d.findDir(stdpath.Dir(obj.GetPath()))
+	if err != nil {
+		return err
+	}
+	node := parent.child(obj.GetName())
+	if node == nil {
+		return errs.ObjectNotFound
+	}
+	parent.remove(node.Name)
+	if err = d.commit(ctx); err != nil {
+		return err
+	}
+	d.deleteMessages(node.messages())
+	return nil
+}
+
+func (d *Telegram) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	node, err := d.upload(ctx, stream, up)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	parent, err := d.findDir(dstDir.GetPath())
+	if err == nil {
+		if old := parent.child(node.Name); old != nil && old.IsDir {
+			err = fmt.Errorf("%s is a folder", node.Name)
+		}
+	}
+	if err != nil {
+		d.deleteMessages(node.messages())
+		return err
+	}
+	// the file of the same name is replaced
+	old := parent.child(node.Name)
+	parent.remove(node.Name)
+	parent.Children = append(parent.Children, node)
+	if err = d.commit(ctx); err != nil {
+		d.deleteMessages(node.messages())
+		return err
+	}
+	if old != nil {
+		d.deleteMessages(old.messages())
+	}
+	return nil
+}
+
+// relocate move the node of srcPath into the dir of dstPath with the name
+func (d *Telegram) relocate(ctx context.Context, srcPath, dstPath, name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	parent, err := d.findDir(stdpath.Dir(srcPath))
+	if err != nil {
+		return err
+	}
+	node := parent.child(stdpath.Base(srcPath))
+	if node == nil {
+		return errs.ObjectNotFound
+	}
+	dst, err := d.findDir(dstPath)
+	if err != nil {
+		return err
+	}
+	if dst.child(name) != nil {
+		return fmt.Errorf("%s already exists", name)
+	}
+	// the dir can't be moved into itself
+	if node.IsDir && strings.HasPrefix(dstPath+"/", strings.TrimSuffix(srcPath, "/")+"/") {
+		return fmt.Errorf("can't move %s into itself", srcPath)
+	}
+	parent.remove(node.Name)
+	node.Name = name
+	dst.Children = append(dst.Children, node)
+	return d.commit(ctx)
+}
+
+// commit save the index, it's reloaded from the channel if it fails so the changes are discarded
+func (d *Telegram) commit(ctx context.Context) error {
+	if err := d.saveIndex(ctx); err != nil {
+		_ = d.loadIndex(ctx)
+		return err
+	}
+	return nil
+}
+
+func (d *Telegram) deleteMessages(ids []int) {
+	for _, id := range ids {
+		_ = d.deleteMessage(id)
+	}
+}
+
+var _ driver.Driver = (*Telegram)(nil)
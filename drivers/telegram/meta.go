@@ -0,0 +1,33 @@
+package telegram
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	driver.RootPath
+	BotToken string `json:"bot_token" required:"true" secret:"true" help:"the bot must be an admin of the channel who can post, edit, delete and pin the messages"`
+	// the id such as -1001234567890 or the username such as @channel
+	ChatID string `json:"chat_id" required:"true" help:"the id or the @username of the channel"`
+	// the official server limits the uploads of the bots to 50MB and the downloads to 20MB,
+	// a local bot api server raises both of them to 2000MB
+	APIUrl   string `json:"api_url" default:"https://api.telegram.org" help:"the url of the bot api server, such as a local one that supports the files up to 2000MB"`
+	PartSize int    `json:"part_size" type:"number" default:"20" help:"the files larger than it are split into parts, in MB, at most 20 with the official server and 2000 with a local server"`
+}
+
+var config = driver.Config{
+	Name:              "Telegram",
+	LocalSort:         true,
+	OnlyProxy:         true,
+	DefaultRoot:       "/",
+	SupportsRangeRead: true,
+}
+
+func New() driver.Driver {
+	return &Telegram{}
+}
+
+func init() {
+	op.RegisterDriver(config, New)
+}
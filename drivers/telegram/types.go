@@ -0,0 +1,74 @@
+package telegram
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+type Resp struct {
+	Ok          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+type Document struct {
+	FileID   string `json:"file_id"`
+	FileName string `json:"file_name"`
+	FileSize int64  `json:"file_size"`
+}
+
+type Message struct {
+	MessageID int       `json:"message_id"`
+	Document  *Document `json:"document"`
+}
+
+type Chat struct {
+	ID            int64    `json:"id"`
+	PinnedMessage *Message `json:"pinned_message"`
+}
+
+type TgFile struct {
+	FilePath string `json:"file_path"`
+}
+
+// Part is a document in the channel that holds a part of a file
+type Part struct {
+	MessageID int    `json:"message_id"`
+	FileID    string `json:"file_id"`
+	Size      int64  `json:"size"`
+}
+
+// Node is a file or a dir in the index, the dirs only exist in the index
+type Node struct {
+	Name     string    `json:"name"`
+	IsDir    bool      `json:"is_dir,omitempty"`
+	Size     int64     `json:"size,omitempty"`
+	Modified time.Time `json:"modified"`
+	Children []*Node   `json:"children,omitempty"`
+	Parts    []Part    `json:"parts,omitempty"`
+	// the message of the manifest of the file split into parts
+	ManifestID int `json:"manifest_id,omitempty"`
+}
+
+// Manifest is sent to the channel with the file split into parts,
+// so the file can be reassembled with the channel only
+type Manifest struct {
+	Name  string `json:"name"`
+	Size  int64  `json:"size"`
+	Parts []Part `json:"parts"`
+}
+
+func nodeToObj(n *Node) *model.Object {
+	return &model.Object{
+		Name:     n.Name,
+		Size:     n.Size,
+		Modified: n.Modified,
+		IsFolder: n.IsDir,
+	}
+}
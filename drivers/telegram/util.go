@@ -0,0 +1,410 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// do others that not defined in Driver interface
+
+const (
+	// the name of the document of the index pinned in the channel
+	indexName = "alist_index.json"
+	// the suffix of the documents of the manifests
+	manifestSuffix = ".manifest.json"
+)
+
+func (d *Telegram) botUrl(method string) string {
+	return strings.TrimSuffix(d.APIUrl, "/") + "/bot" + d.BotToken + "/" + method
+}
+
+// call call the method of the bot api with the json params, it waits and retries if it's flood limited
+func (d *Telegram) call(method string, params base.Json, result interface{}) error {
+	for i := 0; ; i++ {
+		res, err := base.RestyClient.R().SetBody(params).Post(d.botUrl(method))
+		if err != nil {
+			return err
+		}
+		var resp Resp
+		if err = utils.Json.Unmarshal(res.Body(), &resp); err != nil {
+			return fmt.Errorf("telegram: %s: %s", method, res.Status())
+		}
+		if resp.ErrorCode == http.StatusTooManyRequests && i < 3 {
+			time.Sleep(time.Duration(resp.Parameters.RetryAfter+1) * time.Second)
+			continue
+		}
+		return parseResp(method, &resp, result)
+	}
+}
+
+func parseResp(method string, resp *Resp, result interface{}) error {
+	if !resp.Ok {
+		return fmt.Errorf("telegram: %s: %s", method, resp.Description)
+	}
+	if result != nil {
+		return utils.Json.Unmarshal(resp.Result, result)
+	}
+	return nil
+}
+
+// send upload the content as the file of `field` by the multipart form, the length of the body
+// is counted before sending so the content is streamed without buffering
+func (d *Telegram) send(ctx context.Context, method string, fields map[string]string, field, name string, content io.Reader, size int64, result interface{}) error {
+	var head, tail bytes.Buffer
+	w := multipart.NewWriter(&head)
+	for k, v := range fields {
+		if err := w.WriteField(k, v); err != nil {
+			return err
+		}
+	}
+	if _, err := w.CreateFormFile(field, name); err != nil {
+		return err
+	}
+	// the closing boundary is written to the tail
+	boundary := w.Boundary()
+	tail.WriteString("\r\n--" + boundary + "--\r\n")
+	body := io.MultiReader(&head, io.LimitReader(content, size), &tail)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.botUrl(method), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(head.Len()) + size + int64(tail.Len())
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	var resp Resp
+	if err = utils.Json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return fmt.Errorf("telegram: %s: %s", method, res.Status)
+	}
+	return parseResp(method, &resp, result)
+}
+
+// sendDocument send the content as a document to the channel
+func (d *Telegram) sendDocument(ctx context.Context, name string, content io.Reader, size int64) (*Message, error) {
+	var msg Message
+	err := d.send(ctx, "sendDocument", map[string]string{
+		"chat_id":              d.ChatID,
+		"disable_notification": "true",
+	}, "document", name, content, size, &msg)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Document == nil {
+		return nil, fmt.Errorf("telegram: the message %d isn't a document", msg.MessageID)
+	}
+	return &msg, nil
+}
+
+func (d *Telegram) deleteMessage(id int) error {
+	err := d.call("deleteMessage", base.Json{"chat_id": d.ChatID, "message_id": id}, nil)
+	if err != nil && strings.Contains(err.Error(), "message to delete not found") {
+		return nil
+	}
+	return err
+}
+
+// fileUrl get the url to download the document
+func (d *Telegram) fileUrl(fileID string) (string, error) {
+	var file TgFile
+	if err := d.call("getFile", base.Json{"file_id": fileID}, &file); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(d.APIUrl, "/") + "/file/bot" + d.BotToken + "/" + file.FilePath, nil
+}
+
+// download download the document from the offset
+func (d *Telegram) download(ctx context.Context, fileID string, offset int64) (io.ReadCloser, error) {
+	u, err := d.fileUrl(fileID)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// the server ignores the range, skip the bytes before the offset
+		if _, err = io.CopyN(io.Discard, res.Body, offset); err != nil {
+			_ = res.Body.Close()
+			return nil, err
+		}
+	default:
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("telegram: failed download: %s", res.Status)
+	}
+	return res.Body, nil
+}
+
+// loadIndex load the index from the document pinned in the channel, a new one is created if it doesn't exist
+func (d *Telegram) loadIndex(ctx context.Context) error {
+	var chat Chat
+	if err := d.call("getChat", base.Json{"chat_id": d.ChatID}, &chat); err != nil {
+		return err
+	}
+	msg := chat.PinnedMessage
+	if msg == nil || msg.Document == nil || msg.Document.FileName != indexName {
+		d.root = &Node{Name: "/", IsDir: true, Modified: time.Now()}
+		d.indexID = 0
+		return d.saveIndex(ctx)
+	}
+	r, err := d.download(ctx, msg.Document.FileID, 0)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	var root Node
+	if err = utils.Json.NewDecoder(r).Decode(&root); err != nil {
+		return fmt.Errorf("telegram: invalid index: %w", err)
+	}
+	d.root, d.indexID = &root, msg.MessageID
+	return nil
+}
+
+// saveIndex replace the document of the pinned index with the current tree, it's sent and pinned if it doesn't exist
+func (d *Telegram) saveIndex(ctx context.Context) error {
+	data, err := utils.Json.Marshal(d.root)
+	if err != nil {
+		return err
+	}
+	if d.indexID != 0 {
+		return d.send(ctx, "editMessageMedia", map[string]string{
+			"chat_id":    d.ChatID,
+			"message_id": fmt.Sprint(d.indexID),
+			"media":      `{"type":"document","media":"attach://index"}`,
+		}, "index", indexName, bytes.NewReader(data), int64(len(data)), nil)
+	}
+	msg, err := d.sendDocument(ctx, indexName, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	d.indexID = msg.MessageID
+	return d.call("pinChatMessage", base.Json{
+		"chat_id":              d.ChatID,
+		"message_id":           msg.MessageID,
+		"disable_notification": true,
+	}, nil)
+}
+
+// find get the node of the path in the index
+func (d *Telegram) find(path string) (*Node, error) {
+	node := d.root
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		if name == "" {
+			continue
+		}
+		if !node.IsDir {
+			return nil, errs.NotFolder
+		}
+		child := node.child(name)
+		if child == nil {
+			return nil, errs.ObjectNotFound
+		}
+		node = child
+	}
+	return node, nil
+}
+
+func (d *Telegram) findDir(path string) (*Node, error) {
+	node, err := d.find(path)
+	if err != nil {
+		return nil, err
+	}
+	if !node.IsDir {
+		return nil, errs.NotFolder
+	}
+	return node, nil
+}
+
+func (n *Node) child(name string) *Node {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (n *Node) remove(name string) {
+	for i, c := range n.Children {
+		if c.Name == name {
+			n.Children = append(n.Children[:i], n.Children[i+1:]...)
+			return
+		}
+	}
+}
+
+// messages get the messages of the parts and the manifests of the node and its children
+func (n *Node) messages() []int {
+	var ids []int
+	for _, p := range n.Parts {
+		ids = append(ids, p.MessageID)
+	}
+	if n.ManifestID != 0 {
+		ids = append(ids, n.ManifestID)
+	}
+	for _, c := range n.Children {
+		ids = append(ids, c.messages()...)
+	}
+	return ids
+}
+
+// upload send the stream to the channel, it's split into the parts of PartSize and a manifest if it's larger
+func (d *Telegram) upload(ctx context.Context, stream model.FileStreamer, up driver.UpdateProgress) (*Node, error) {
+	node := &Node{Name: stream.GetName(), Size: stream.GetSize(), Modified: stream.ModTime()}
+	if node.Modified.IsZero() {
+		node.Modified = time.Now()
+	}
+	partSize := int64(d.PartSize) * 1024 * 1024
+	count := (node.Size + partSize - 1) / partSize
+	for i := int64(0); i < count; i++ {
+		if utils.IsCanceled(ctx) {
+			d.deleteParts(node.Parts)
+			return nil, ctx.Err()
+		}
+		size := partSize
+		if left := node.Size - i*partSize; left < size {
+			size = left
+		}
+		name := node.Name
+		if count > 1 {
+			name = fmt.Sprintf("%s.part%03d", node.Name, i+1)
+		}
+		msg, err := d.sendDocument(ctx, name, stream, size)
+		if err != nil {
+			d.deleteParts(node.Parts)
+			return nil, err
+		}
+		node.Parts = append(node.Parts, Part{MessageID: msg.MessageID, FileID: msg.Document.FileID, Size: size})
+		up(int((i + 1) * 100 / count))
+	}
+	if count > 1 {
+		if err := d.sendManifest(ctx, node); err != nil {
+			d.deleteParts(node.Parts)
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+func (d *Telegram) sendManifest(ctx context.Context, node *Node) error {
+	data, err := utils.Json.Marshal(Manifest{Name: node.Name, Size: node.Size, Parts: node.Parts})
+	if err != nil {
+		return err
+	}
+	msg, err := d.sendDocument(ctx, node.Name+manifestSuffix, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	node.ManifestID = msg.MessageID
+	return nil
+}
+
+func (d *Telegram) deleteParts(parts []Part) {
+	for _, p := range parts {
+		_ = d.deleteMessage(p.MessageID)
+	}
+}
+
+// copyNode copy the messages of the node to new ones, the documents keep the file ids
+func (d *Telegram) copyNode(ctx context.Context, node *Node) (*Node, error) {
+	n := &Node{Name: node.Name, IsDir: node.IsDir, Size: node.Size, Modified: node.Modified}
+	for _, c := range node.Children {
+		child, err := d.copyNode(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		n.Children = append(n.Children, child)
+	}
+	for _, p := range node.Parts {
+		var msg struct {
+			MessageID int `json:"message_id"`
+		}
+		err := d.call("copyMessage", base.Json{
+			"chat_id":              d.ChatID,
+			"from_chat_id":         d.ChatID,
+			"message_id":           p.MessageID,
+			"disable_notification": true,
+		}, &msg)
+		if err != nil {
+			return nil, err
+		}
+		n.Parts = append(n.Parts, Part{MessageID: msg.MessageID, FileID: p.FileID, Size: p.Size})
+	}
+	if node.ManifestID != 0 {
+		if err := d.sendManifest(ctx, n); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// partsReader read the parts of a file one by one from the offset, the parts are downloaded when they're reached
+type partsReader struct {
+	ctx    context.Context
+	d      *Telegram
+	parts  []Part
+	offset int64
+	cur    io.ReadCloser
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.cur == nil {
+			if len(r.parts) == 0 {
+				return 0, io.EOF
+			}
+			// skip the parts before the offset
+			if r.offset >= r.parts[0].Size {
+				r.offset -= r.parts[0].Size
+				r.parts = r.parts[1:]
+				continue
+			}
+			cur, err := r.d.download(r.ctx, r.parts[0].FileID, r.offset)
+			if err != nil {
+				return 0, err
+			}
+			r.cur, r.offset, r.parts = cur, 0, r.parts[1:]
+		}
+		n, err := r.cur.Read(p)
+		if err == io.EOF {
+			_ = r.cur.Close()
+			r.cur = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *partsReader) Close() error {
+	if r.cur != nil {
+		return r.cur.Close()
+	}
+	return nil
+}
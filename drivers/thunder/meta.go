@@ -18,7 +18,7 @@ type ExpertAddition struct {
 
 	// 登录方式1
 	Username string `json:"username" required:"true" help:"login type is user,this is required"`
-	Password string `json:"password" required:"true" help:"login type is user,this is required"`
+	Password string `json:"password" required:"true" help:"login type is user,this is required" secret:"true"`
 	// 登录方式2
 	RefreshToken string `json:"refresh_token" required:"true" help:"login type is refresh_token,this is required"`
 
@@ -29,7 +29,7 @@ type ExpertAddition struct {
 	Timestamp   string `json:"timestamp" required:"true" help:"sign type is captcha_sign,this is required"`
 
 	// 验证码
-	CaptchaToken string `json:"captcha_token"`
+	CaptchaToken string `json:"captcha_token" secret:"true"`
 
 	// 必要且影响登录,由签名决定
 	DeviceID      string `json:"device_id"  required:"true" default:"9aa5c268e7bcfc197a9ad88e2fb330e5"`
@@ -79,9 +79,11 @@ func (i *Addition) GetIdentity() string {
 }
 
 var config = driver.Config{
-	Name:      "Thunder",
-	LocalSort: true,
-	OnlyProxy: true,
+	Name:                   "Thunder",
+	LocalSort:              true,
+	OnlyProxy:              true,
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
 }
 
 var configExpert = driver.Config{
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
 )
 
 type ErrResp struct {
@@ -139,13 +141,14 @@ type Files struct {
 	//Collection interface{} `json:"collection"`
 }
 
-func (c *Files) GetSize() int64     { size, _ := strconv.ParseInt(c.Size, 10, 64); return size }
-func (c *Files) GetName() string    { return c.Name }
-func (c *Files) ModTime() time.Time { return c.ModifiedTime }
-func (c *Files) IsDir() bool        { return c.Kind == FOLDER }
-func (c *Files) GetID() string      { return c.ID }
-func (c *Files) GetPath() string    { return "" }
-func (c *Files) Thumb() string      { return c.ThumbnailLink }
+func (c *Files) GetSize() int64          { size, _ := strconv.ParseInt(c.Size, 10, 64); return size }
+func (c *Files) GetName() string         { return c.Name }
+func (c *Files) ModTime() time.Time      { return c.ModifiedTime }
+func (c *Files) IsDir() bool             { return c.Kind == FOLDER }
+func (c *Files) GetID() string           { return c.ID }
+func (c *Files) GetPath() string         { return "" }
+func (c *Files) GetHash() model.HashInfo { return model.HashInfo{} }
+func (c *Files) Thumb() string           { return c.ThumbnailLink }
 
 /*
 * 上传
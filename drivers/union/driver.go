@@ -0,0 +1,241 @@
+package union
+
+import (
+	"context"
+	"io"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Union overlay the paths of several storages into one, the dirs with the same path are merged,
+// and the new files are put into the branch chosen by the create policy
+type Union struct {
+	model.Storage
+	Addition
+	branches []string
+	// the counter of the round robin policy
+	next uint64
+}
+
+func (d *Union) Config() driver.Config {
+	return config
+}
+
+func (d *Union) GetAddition() driver.Additional {
+	return d.Addition
+}
+
+func (d *Union) Init(ctx context.Context, storage model.Storage) error {
+	d.Storage = storage
+	err := utils.Json.UnmarshalFromString(d.Storage.Addition, &d.Addition)
+	if err != nil {
+		return err
+	}
+	d.branches = parseBranches(d.Paths)
+	if len(d.branches) == 0 {
+		return errors.New("the paths of the branches are required")
+	}
+	for _, branch := range d.branches {
+		if utils.IsSubPath(d.MountPath, branch) {
+			return errors.Errorf("the branch [%s] can't be in the union storage itself", branch)
+		}
+	}
+	return nil
+}
+
+func (d *Union) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *Union) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "root",
+			Path:     "/",
+			Modified: d.Modified,
+			IsFolder: true,
+		}, nil
+	}
+	return d.get(ctx, utils.StandardizePath(path))
+}
+
+func (d *Union) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	var res []model.Obj
+	index := make(map[string]int)
+	found := false
+	var listErr error
+	for _, branch := range d.branches {
+		storage, dirPath, err := resolve(branch, dir.GetPath())
+		if err != nil {
+			continue
+		}
+		objs, err := op.List(ctx, storage, dirPath, model.ListArgs{ReqPath: stdpath.Join(branch, dir.GetPath())})
+		if err != nil {
+			// the dir may be only in some of the branches
+			if !errs.IsObjectNotFound(err) && !errors.Is(err, errs.NotFolder) {
+				log.Warnf("failed list [%s] in the branch [%s]: %+v", dir.GetPath(), branch, err)
+				listErr = err
+			}
+			continue
+		}
+		found = true
+		for _, obj := range objs {
+			o := convert(branch, dir.GetPath(), obj)
+			i, ok := index[o.GetName()]
+			if !ok {
+				index[o.GetName()] = len(res)
+				res = append(res, o)
+			} else if d.prefer(o, res[i]) {
+				res[i] = o
+			}
+		}
+	}
+	if !found && listErr != nil {
+		return nil, listErr
+	}
+	return res, nil
+}
+
+func (d *Union) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	storage, path, err := resolve(file.GetID(), file.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	link, _, err := op.Link(ctx, storage, path, args)
+	if err != nil {
+		return nil, err
+	}
+	// the local files are redirected to the branch, and read directly if proxied
+	if link.URL == "" {
+		res := *link
+		res.URL = common.GetDownUrl(stdpath.Join(file.GetID(), file.GetPath()), args.IP)
+		return &res, nil
+	}
+	return link, nil
+}
+
+func (d *Union) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	branch, err := d.createBranch(ctx)
+	if err != nil {
+		return err
+	}
+	storage, path, err := resolve(branch, stdpath.Join(parentDir.GetPath(), dirName))
+	if err != nil {
+		return err
+	}
+	return op.MakeDir(ctx, storage, path)
+}
+
+// transfer move or copy the object in its branches, the dir is created in the branch if not exists
+func (d *Union) transfer(ctx context.Context, srcObj, dstDir model.Obj, f func(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string) error) error {
+	for _, branch := range d.objBranches(ctx, srcObj) {
+		storage, srcPath, err := resolve(branch, srcObj.GetPath())
+		if err != nil {
+			return err
+		}
+		dstStorage, dstDirPath, err := resolve(branch, dstDir.GetPath())
+		if err != nil {
+			return err
+		}
+		if storage.GetStorage().MountPath != dstStorage.GetStorage().MountPath {
+			return errors.WithStack(errs.NotSupport)
+		}
+		if err = op.MakeDir(ctx, storage, dstDirPath); err != nil {
+			return errors.WithMessagef(err, "failed to make dir [%s] in the branch [%s]", dstDir.GetPath(), branch)
+		}
+		if err = f(ctx, storage, srcPath, dstDirPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Union) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.transfer(ctx, srcObj, dstDir, op.Move)
+}
+
+func (d *Union) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	for _, branch := range d.objBranches(ctx, srcObj) {
+		storage, path, err := resolve(branch, srcObj.GetPath())
+		if err != nil {
+			return err
+		}
+		if err = op.Rename(ctx, storage, path, newName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Union) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.transfer(ctx, srcObj, dstDir, op.Copy)
+}
+
+func (d *Union) Remove(ctx context.Context, obj model.Obj) error {
+	for _, branch := range d.objBranches(ctx, obj) {
+		storage, path, err := resolve(branch, obj.GetPath())
+		if err != nil {
+			return err
+		}
+		if err = op.Remove(ctx, storage, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Union) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	// the existing file is replaced in its branch
+	branch := ""
+	if obj, err := d.get(ctx, stdpath.Join(dstDir.GetPath(), stream.GetName())); err == nil && !obj.IsDir() {
+		branch = obj.GetID()
+	} else if branch, err = d.createBranch(ctx); err != nil {
+		return err
+	}
+	storage, dirPath, err := resolve(branch, dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	// the stream is closed by the caller, so the one put to the branch mustn't close it again
+	return op.Put(ctx, storage, dirPath, &model.FileStream{
+		Obj:        stream,
+		ReadCloser: io.NopCloser(stream),
+		Mimetype:   stream.GetMimetype(),
+		Sha1:       stream.GetSha1(),
+	}, up)
+}
+
+func (d *Union) GetQuota(ctx context.Context) (*model.StorageQuota, error) {
+	res := &model.StorageQuota{}
+	found := false
+	for _, branch := range d.branches {
+		storage, _, err := resolve(branch, "/")
+		if err != nil {
+			continue
+		}
+		quota, err := op.GetStorageQuota(ctx, storage)
+		if err != nil {
+			continue
+		}
+		found = true
+		res.Total += quota.Total
+		res.Used += quota.Used
+		res.Free += quota.Free
+	}
+	if !found {
+		return nil, errors.WithStack(errs.NotSupport)
+	}
+	return res, nil
+}
+
+var _ driver.Driver = (*Union)(nil)
+var _ driver.Getter = (*Union)(nil)
+var _ driver.Quota = (*Union)(nil)
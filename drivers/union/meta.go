@@ -0,0 +1,27 @@
+package union
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	// the paths in alist of the branches, the earlier one has priority
+	Paths          string `json:"paths" type:"text" required:"true" help:"the paths in alist combined into the storage, one per line"`
+	CreatePolicy   string `json:"create_policy" type:"select" options:"first,most_free,round_robin" default:"first" help:"the branch receiving the new files and dirs"`
+	ConflictPolicy string `json:"conflict_policy" type:"select" options:"first,newest,largest" default:"first" help:"the file shown if the files with the same name are in several branches"`
+}
+
+var config = driver.Config{
+	Name:                   "Union",
+	LocalSort:              true,
+	NoCache:                true,
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
+}
+
+func init() {
+	op.RegisterDriver(config, func() driver.Driver {
+		return &Union{}
+	})
+}
@@ -0,0 +1,151 @@
+package union
+
+import (
+	"context"
+	stdpath "path"
+	"strings"
+	"sync/atomic"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// resolve get the storage and the actual path of the path in the branch
+func resolve(branch, path string) (driver.Driver, string, error) {
+	return op.GetStorageAndActualPath(stdpath.Join(branch, path))
+}
+
+// convert the object in the branch to the one in the union, the ID of the file is the branch
+// it's in, the dirs are merged so their ID is empty
+func convert(branch, dirPath string, obj model.Obj) model.Obj {
+	o := model.Object{
+		Name:     obj.GetName(),
+		Path:     stdpath.Join(dirPath, obj.GetName()),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		IsFolder: obj.IsDir(),
+	}
+	if obj.IsDir() {
+		return &o
+	}
+	o.ID = branch
+	if t, ok := obj.(model.Thumb); ok && t.Thumb() != "" {
+		return &model.ObjThumb{Object: o, Thumbnail: model.Thumbnail{Thumbnail: t.Thumb()}}
+	}
+	return &o
+}
+
+// prefer check if the object `a` should be shown instead of `b` with the same name
+func (d *Union) prefer(a, b model.Obj) bool {
+	// the dirs are merged, so they hide the files
+	if a.IsDir() != b.IsDir() {
+		return a.IsDir()
+	}
+	switch d.ConflictPolicy {
+	case "newest":
+		return a.ModTime().After(b.ModTime())
+	case "largest":
+		return a.GetSize() > b.GetSize()
+	}
+	return false
+}
+
+// get the object in the path from the branches, by the conflict policy if it's in several branches
+func (d *Union) get(ctx context.Context, path string) (model.Obj, error) {
+	var res model.Obj
+	for _, branch := range d.branches {
+		storage, actualPath, err := resolve(branch, path)
+		if err != nil {
+			continue
+		}
+		obj, err := op.Get(ctx, storage, actualPath)
+		if err != nil {
+			if !errs.IsObjectNotFound(err) {
+				log.Warnf("failed get [%s] in the branch [%s]: %+v", path, branch, err)
+			}
+			continue
+		}
+		o := convert(branch, stdpath.Dir(path), obj)
+		if res == nil || d.prefer(o, res) {
+			res = o
+		}
+	}
+	if res == nil {
+		return nil, errors.WithStack(errs.ObjectNotFound)
+	}
+	return res, nil
+}
+
+// dirBranches get the branches where the dir in the path exists
+func (d *Union) dirBranches(ctx context.Context, path string) []string {
+	var res []string
+	for _, branch := range d.branches {
+		storage, actualPath, err := resolve(branch, path)
+		if err != nil {
+			continue
+		}
+		if obj, err := op.Get(ctx, storage, actualPath); err == nil && obj.IsDir() {
+			res = append(res, branch)
+		}
+	}
+	return res
+}
+
+// branches of the objects, the file is in the branch of its ID, the dir may be in several branches
+func (d *Union) objBranches(ctx context.Context, obj model.Obj) []string {
+	if obj.IsDir() {
+		return d.dirBranches(ctx, obj.GetPath())
+	}
+	return []string{obj.GetID()}
+}
+
+// createBranch choose the branch for the new object by the create policy
+func (d *Union) createBranch(ctx context.Context) (string, error) {
+	var writable []string
+	for _, branch := range d.branches {
+		storage, _, err := resolve(branch, "/")
+		if err != nil || storage.Config().NoUpload {
+			continue
+		}
+		writable = append(writable, branch)
+	}
+	if len(writable) == 0 {
+		return "", errors.New("no branch can be written")
+	}
+	switch d.CreatePolicy {
+	case "round_robin":
+		i := atomic.AddUint64(&d.next, 1) - 1
+		return writable[i%uint64(len(writable))], nil
+	case "most_free":
+		res, free := writable[0], int64(-1)
+		for _, branch := range writable {
+			storage, _, _ := resolve(branch, "/")
+			quota, err := op.GetStorageQuota(ctx, storage)
+			if err != nil {
+				continue
+			}
+			if quota.Free > free {
+				res, free = branch, quota.Free
+			}
+		}
+		return res, nil
+	}
+	return writable[0], nil
+}
+
+// parseBranches get the paths of the branches, one per line
+func parseBranches(paths string) []string {
+	var res []string
+	for _, path := range strings.Split(paths, "\n") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		res = append(res, utils.StandardizePath(path))
+	}
+	return res
+}
@@ -10,14 +10,16 @@ type Addition struct {
 	Bucket           string `json:"bucket" required:"true"`
 	Endpoint         string `json:"endpoint" required:"true"`
 	OperatorName     string `json:"operator_name" required:"true"`
-	OperatorPassword string `json:"operator_password" required:"true"`
+	OperatorPassword string `json:"operator_password" required:"true" secret:"true"`
 	CustomHost       string `json:"custom_host"`
 	SignURLExpire    int    `json:"sign_url_expire" type:"number" default:"4"`
 }
 
 var config = driver.Config{
-	Name:      "USS",
-	LocalSort: true,
+	Name:                   "USS",
+	LocalSort:              true,
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
 }
 
 func New() driver.Driver {
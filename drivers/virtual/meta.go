@@ -19,6 +19,7 @@ var config = driver.Config{
 	LocalSort: true,
 	NeedMs:    true,
 	//NoCache:   true,
+	SupportsServerSideCopy: true,
 }
 
 func New() driver.Driver {
@@ -2,6 +2,7 @@ package webdav
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"os"
 	"path"
@@ -73,24 +74,16 @@ func (d *WebDav) List(ctx context.Context, dir model.Obj, args model.ListArgs) (
 //}
 
 func (d *WebDav) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
-	callback := func(r *http.Request) {
-		if args.Header.Get("Range") != "" {
-			r.Header.Set("Range", args.Header.Get("Range"))
-		}
-		if args.Header.Get("If-Range") != "" {
-			r.Header.Set("If-Range", args.Header.Get("If-Range"))
-		}
-	}
-	reader, header, err := d.client.ReadStream(file.GetPath(), callback)
+	reader, _, err := d.client.ReadStream(file.GetPath(), nil)
 	if err != nil {
 		return nil, err
 	}
-	link := &model.Link{Data: reader}
-	if header.Get("Content-Range") != "" {
-		link.Status = 206
-		link.Header = header
-	}
-	return link, nil
+	return &model.Link{
+		Data: reader,
+		RangeReader: func(offset int64) (io.ReadCloser, error) {
+			return d.client.ReadStreamRange(file.GetPath(), offset, file.GetSize()-offset)
+		},
+	}, nil
 }
 
 func (d *WebDav) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
@@ -98,7 +91,12 @@ func (d *WebDav) MakeDir(ctx context.Context, parentDir model.Obj, dirName strin
 }
 
 func (d *WebDav) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
-	return d.client.Rename(srcObj.GetPath(), path.Join(dstDir.GetPath(), srcObj.GetName()), true)
+	dst := path.Join(dstDir.GetPath(), srcObj.GetName())
+	err := d.client.Rename(srcObj.GetPath(), dst, true)
+	if err != nil && srcObj.IsDir() && depthRejected(err) {
+		return d.moveByChildren(ctx, srcObj.GetPath(), dst)
+	}
+	return err
 }
 
 func (d *WebDav) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
@@ -106,7 +104,12 @@ func (d *WebDav) Rename(ctx context.Context, srcObj model.Obj, newName string) e
 }
 
 func (d *WebDav) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
-	return d.client.Copy(srcObj.GetPath(), path.Join(dstDir.GetPath(), srcObj.GetName()), true)
+	dst := path.Join(dstDir.GetPath(), srcObj.GetName())
+	err := d.client.Copy(srcObj.GetPath(), dst, true)
+	if err != nil && srcObj.IsDir() && depthRejected(err) {
+		return d.copyByChildren(ctx, srcObj.GetPath(), dst)
+	}
+	return err
 }
 
 func (d *WebDav) Remove(ctx context.Context, obj model.Obj) error {
@@ -114,11 +117,16 @@ func (d *WebDav) Remove(ctx context.Context, obj model.Obj) error {
 }
 
 func (d *WebDav) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	dst := path.Join(dstDir.GetPath(), stream.GetName())
+	chunkSize := int64(d.ChunkSize) * 1024 * 1024
+	if chunkSize > 0 && stream.GetSize() > chunkSize {
+		return d.putByRanges(ctx, dst, stream, chunkSize, up)
+	}
 	callback := func(r *http.Request) {
 		r.Header.Set("Content-Type", stream.GetMimetype())
 		r.ContentLength = stream.GetSize()
 	}
-	err := d.client.WriteStream(path.Join(dstDir.GetPath(), stream.GetName()), stream, 0644, callback)
+	err := d.client.WriteStream(dst, stream, 0644, callback)
 	return err
 }
 
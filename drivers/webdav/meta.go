@@ -9,15 +9,20 @@ type Addition struct {
 	Vendor   string `json:"vendor" type:"select" options:"sharepoint,other" default:"other"`
 	Address  string `json:"address" required:"true"`
 	Username string `json:"username" required:"true"`
-	Password string `json:"password" required:"true"`
+	Password string `json:"password" required:"true" secret:"true"`
 	driver.RootPath
+	// the servers such as nginx limit the size of the bodies, the files larger than it are put by the ranges
+	ChunkSize     int  `json:"chunk_size" type:"number" default:"0" help:"MB, the files larger than it are uploaded by the ranged PUT, for the servers limiting the body size, 0 to disable, the server must support the Content-Range of PUT, such as apache and sabre/dav"`
+	TLSSkipVerify bool `json:"tls_skip_verify" help:"skip verifying the certificate of the server, such as it's self signed"`
 }
 
 var config = driver.Config{
-	Name:        "WebDav",
-	LocalSort:   true,
-	OnlyLocal:   true,
-	DefaultRoot: "/",
+	Name:                   "WebDav",
+	LocalSort:              true,
+	OnlyLocal:              true,
+	DefaultRoot:            "/",
+	SupportsRangeRead:      true,
+	SupportsServerSideCopy: true,
 }
 
 func New() driver.Driver {
@@ -1,10 +1,19 @@
 package webdav
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
 	"net/http"
+	"path"
 
 	"github.com/alist-org/alist/v3/drivers/webdav/odrvcookie"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/gowebdav"
+	log "github.com/sirupsen/logrus"
 )
 
 // do others that not defined in Driver interface
@@ -15,6 +24,12 @@ func (d *WebDav) isSharepoint() bool {
 
 func (d *WebDav) setClient() error {
 	c := gowebdav.NewClient(d.Address, d.Username, d.Password)
+	if d.TLSSkipVerify {
+		c.SetTransport(&http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		})
+	}
 	if d.isSharepoint() {
 		cookie, err := odrvcookie.GetCookie(d.Username, d.Password, d.Address)
 		if err == nil {
@@ -29,3 +44,106 @@ func (d *WebDav) setClient() error {
 	d.client = c
 	return nil
 }
+
+// depthRejected check if the server rejected the COPY or MOVE of the dir, which is Depth: infinity,
+// some servers forbid it for the load, then the children are copied or moved one by one
+func depthRejected(err error) bool {
+	for _, code := range []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotImplemented, http.StatusBadGateway} {
+		if gowebdav.IsErrCode(err, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// copyByChildren copy the dir by creating it and copying the children one by one
+func (d *WebDav) copyByChildren(ctx context.Context, src, dst string) error {
+	log.Debugf("the server rejected copying the dir %s with Depth: infinity, copy the children one by one", src)
+	if err := d.client.MkdirAll(dst, 0644); err != nil {
+		return err
+	}
+	files, err := d.client.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s, t := path.Join(src, f.Name()), path.Join(dst, f.Name())
+		if f.IsDir() {
+			err = d.copyByChildren(ctx, s, t)
+		} else {
+			err = d.client.Copy(s, t, true)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveByChildren move the dir by copying the children one by one and removing it after all of them are copied
+func (d *WebDav) moveByChildren(ctx context.Context, src, dst string) error {
+	if err := d.copyByChildren(ctx, src, dst); err != nil {
+		return err
+	}
+	return d.client.RemoveAll(src)
+}
+
+// putByRanges put the file by the chunks, the first one is put as a new file,
+// and the others are put by the Content-Range, the size is checked after each chunk
+// as the servers not supporting it may overwrite the file with the chunk
+func (d *WebDav) putByRanges(ctx context.Context, dst string, stream model.FileStreamer, chunkSize int64, up driver.UpdateProgress) (err error) {
+	size := stream.GetSize()
+	defer func() {
+		if err != nil {
+			_ = d.client.Remove(dst)
+		}
+	}()
+	buf := make([]byte, chunkSize)
+	for offset := int64(0); offset < size; {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		n := chunkSize
+		if left := size - offset; left < n {
+			n = left
+		}
+		if _, err = io.ReadFull(stream, buf[:n]); err != nil {
+			return err
+		}
+		if offset == 0 {
+			err = d.client.WriteStream(dst, bytes.NewReader(buf[:n]), 0644, func(r *http.Request) {
+				r.Header.Set("Content-Type", stream.GetMimetype())
+				r.ContentLength = n
+			})
+		} else {
+			err = d.client.WriteStreamRange(dst, bytes.NewReader(buf[:n]), offset, n, size)
+			if err == nil {
+				err = d.checkSize(dst, offset+n)
+			}
+			if err != nil {
+				return fmt.Errorf("failed put the range at %d, the server may not support the ranged PUT, set the chunk size to 0: %w", offset, err)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		offset += n
+		up(int(offset * 100 / size))
+	}
+	return nil
+}
+
+// checkSize check if the size of the file on the server is `size`
+func (d *WebDav) checkSize(file string, size int64) error {
+	info, err := d.client.Stat(file)
+	if err != nil {
+		return err
+	}
+	if info.Size() != size {
+		return fmt.Errorf("the size of the file is %d instead of %d", info.Size(), size)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+// Package antileech reject the downloads by the referer and the user agent, such as the hotlinks
+// of the other websites and the known scrapers
+package antileech
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type rule struct {
+	model.AntiLeech
+	referers []string
+	agents   []*regexp.Regexp
+}
+
+// the rules are compiled and loaded in memory since they are checked with every download
+var (
+	mu    sync.RWMutex
+	rules []rule
+)
+
+func compile(r model.AntiLeech) (*rule, error) {
+	res := &rule{AntiLeech: r}
+	for _, host := range strings.Split(r.AllowedReferers, "\n") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			res.referers = append(res.referers, host)
+		}
+	}
+	for _, s := range strings.Split(r.BlockedUserAgents, "\n") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid user agent regexp %s", s)
+		}
+		res.agents = append(res.agents, re)
+	}
+	return res, nil
+}
+
+// Load load the rules from the database, the ones failed to compile are skipped
+func Load() error {
+	res, err := db.GetAntiLeeches()
+	if err != nil {
+		return err
+	}
+	loaded := make([]rule, 0, len(res))
+	for _, r := range res {
+		c, err := compile(r)
+		if err != nil {
+			log.Errorf("failed compile the anti leech rule %d: %+v", r.ID, err)
+			continue
+		}
+		loaded = append(loaded, *c)
+	}
+	mu.Lock()
+	rules = loaded
+	mu.Unlock()
+	return nil
+}
+
+func check(r *model.AntiLeech) error {
+	switch r.Scope {
+	case model.AntiLeechGlobal:
+		r.TargetID = 0
+	case model.AntiLeechStorage:
+	default:
+		return errors.Errorf("invalid scope: %s", r.Scope)
+	}
+	if r.RedirectURL != "" {
+		if u, err := url.Parse(r.RedirectURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return errors.Errorf("invalid redirect url: %s", r.RedirectURL)
+		}
+	}
+	_, err := compile(*r)
+	return err
+}
+
+func CreateAntiLeech(r *model.AntiLeech) error {
+	if err := check(r); err != nil {
+		return err
+	}
+	if err := db.CreateAntiLeech(r); err != nil {
+		return err
+	}
+	return Load()
+}
+
+func UpdateAntiLeech(r *model.AntiLeech) error {
+	if _, err := db.GetAntiLeechById(r.ID); err != nil {
+		return err
+	}
+	if err := check(r); err != nil {
+		return err
+	}
+	if err := db.UpdateAntiLeech(r); err != nil {
+		return err
+	}
+	return Load()
+}
+
+func DeleteAntiLeechById(id uint) error {
+	if err := db.DeleteAntiLeechById(id); err != nil {
+		return err
+	}
+	return Load()
+}
+
+// Request is the request of the download checked
+type Request struct {
+	// the id of the storage of the file, 0 if it's unknown
+	StorageID uint
+	Referer   string
+	UserAgent string
+	// the host of alist requested, the referers of it are always allowed
+	Host string
+}
+
+// allowReferer check the host of the referer is allowed
+func (r *rule) allowReferer(req Request) bool {
+	if len(r.referers) == 0 {
+		return true
+	}
+	if req.Referer == "" {
+		return r.AllowEmptyReferer
+	}
+	u, err := url.Parse(req.Referer)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if u.Host == req.Host {
+		return true
+	}
+	for _, h := range r.referers {
+		if h == host || (strings.HasPrefix(h, "*.") && strings.HasSuffix(host, h[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *rule) allowUserAgent(req Request) bool {
+	for _, re := range r.agents {
+		if re.MatchString(req.UserAgent) {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrRejected is returned if the download is rejected by the rules
+var ErrRejected = errors.New("the download is rejected by the anti leech rules")
+
+// Check check the download by the global rule and the rule of the storage, the url the rejected
+// is redirected to is returned with ErrRejected, it's empty if it should be responded with 403
+func Check(req Request) (string, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	for i := range rules {
+		r := &rules[i]
+		if r.Scope == model.AntiLeechStorage && r.TargetID != req.StorageID {
+			continue
+		}
+		if !r.allowReferer(req) || !r.allowUserAgent(req) {
+			return r.RedirectURL, ErrRejected
+		}
+	}
+	return "", nil
+}
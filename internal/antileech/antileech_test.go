@@ -0,0 +1,46 @@
+package antileech
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestCheck(t *testing.T) {
+	global, err := compile(model.AntiLeech{Scope: model.AntiLeechGlobal, BlockedUserAgents: "(?i)scrapy\n"})
+	if err != nil {
+		t.Fatalf("failed compile: %+v", err)
+	}
+	storage, err := compile(model.AntiLeech{
+		Scope:           model.AntiLeechStorage,
+		TargetID:        1,
+		AllowedReferers: "example.com\n*.example.org",
+		RedirectURL:     "https://example.com/leech.png",
+	})
+	if err != nil {
+		t.Fatalf("failed compile: %+v", err)
+	}
+	rules = []rule{*global, *storage}
+	tests := []struct {
+		req      Request
+		rejected bool
+		redirect string
+	}{
+		{Request{StorageID: 1, Referer: "https://example.com/a"}, false, ""},
+		{Request{StorageID: 1, Referer: "https://a.example.org/"}, false, ""},
+		{Request{StorageID: 1, Referer: "https://alist.local:5244/b", Host: "alist.local:5244"}, false, ""},
+		{Request{StorageID: 1, Referer: "https://other.com/"}, true, "https://example.com/leech.png"},
+		{Request{StorageID: 1}, true, "https://example.com/leech.png"},
+		{Request{StorageID: 2, Referer: "https://other.com/"}, false, ""},
+		{Request{StorageID: 2, UserAgent: "Scrapy/2.11"}, true, ""},
+	}
+	for _, tt := range tests {
+		redirect, err := Check(tt.req)
+		if (err != nil) != tt.rejected || redirect != tt.redirect {
+			t.Errorf("Check(%+v) = %s, %v, want rejected %v and redirect %s", tt.req, redirect, err, tt.rejected, tt.redirect)
+		}
+	}
+	if _, err = compile(model.AntiLeech{BlockedUserAgents: "("}); err == nil {
+		t.Errorf("expected the error of the invalid regexp")
+	}
+}
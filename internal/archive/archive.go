@@ -0,0 +1,227 @@
+// Package archive browse the entries of the archives stored on any storage, the archives are
+// read with the ranged requests to the provider, so only the parts needed are downloaded, such
+// as the central directory of the zip. the formats are read by the tools registered by the exts,
+// the formats without a tool, such as 7z and rar, can't be browsed
+package archive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	stdpath "path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+type Entry struct {
+	// the path in the archive, starts with /
+	Path     string
+	Size     int64
+	Modified time.Time
+	IsDir    bool
+}
+
+// Tool read an archive format
+type Tool interface {
+	// List get all the entries in the archive
+	List(r *io.SectionReader) ([]Entry, error)
+	// Open the file of the path in the archive, the password is used for the encrypted files
+	Open(r *io.SectionReader, path, password string) (io.ReadCloser, error)
+	// Walk read the entries in the order of the archive, the reader is nil for the dirs
+	Walk(r *io.SectionReader, password string, fn func(e Entry, r io.Reader) error) error
+}
+
+var tools = map[string]Tool{}
+
+// RegisterTool register the tool for the exts, the exts are lower case with the dot
+func RegisterTool(tool Tool, exts ...string) {
+	for _, ext := range exts {
+		tools[ext] = tool
+	}
+}
+
+// getTool get the tool by the longest ext matched, such as .tar.gz before .gz
+func getTool(name string) (Tool, bool) {
+	name = strings.ToLower(name)
+	var tool Tool
+	matched := ""
+	for ext, t := range tools {
+		if strings.HasSuffix(name, ext) && len(ext) > len(matched) {
+			tool, matched = t, ext
+		}
+	}
+	return tool, tool != nil
+}
+
+// Supported check if the file can be browsed by the name
+func Supported(name string) bool {
+	_, ok := getTool(name)
+	return ok
+}
+
+// the entries of the archives, the key contains the size and the modified time, so the changed
+// archives are read again
+var entriesCache = cache.NewMemCache(cache.WithShards[[]Entry](16))
+
+const entriesCacheTTL = 30 * time.Minute
+
+// open the archive with the tool, the returned func closes the reader
+func open(ctx context.Context, path string) (Tool, *io.SectionReader, func(), error) {
+	tool, ok := getTool(stdpath.Base(path))
+	if !ok {
+		return nil, nil, nil, errors.WithStack(errs.ArchiveNotSupported)
+	}
+	if !fs.RangeReadable(path) {
+		return nil, nil, nil, errors.WithMessage(errs.NotSupport, "the storage can't read the archive at any offset")
+	}
+	link, file, err := fs.Link(ctx, path, model.LinkArgs{})
+	if err != nil {
+		return nil, nil, nil, errors.WithMessage(err, "failed get link")
+	}
+	r, err := newLinkReader(link, file.GetSize())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return tool, io.NewSectionReader(r, 0, file.GetSize()), func() { _ = r.Close() }, nil
+}
+
+func entries(ctx context.Context, path string) ([]Entry, error) {
+	file, err := fs.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if file.IsDir() {
+		return nil, errors.WithStack(errs.NotFile)
+	}
+	key := fmt.Sprintf("%s|%d|%d", path, file.GetSize(), file.ModTime().Unix())
+	if es, ok := entriesCache.Get(key); ok {
+		return es, nil
+	}
+	tool, r, closer, err := open(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer closer()
+	es, err := tool.List(r)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to read the archive [%s]", path)
+	}
+	for i := range es {
+		es[i].Path = cleanPath(es[i].Path)
+	}
+	entriesCache.Set(key, es, cache.WithEx[[]Entry](entriesCacheTTL))
+	return es, nil
+}
+
+// cleanPath standardize the path in the archive, such as ./a/b/ to /a/b
+func cleanPath(path string) string {
+	return utils.StandardizePath(stdpath.Clean("/" + strings.ReplaceAll(path, "\\", "/")))
+}
+
+// List the objects in the dir of the archive, the dirs without their own entries are listed too
+func List(ctx context.Context, path, innerPath string) ([]model.Obj, error) {
+	es, err := entries(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return children(es, cleanPath(innerPath))
+}
+
+func children(es []Entry, innerPath string) ([]model.Obj, error) {
+	objs := make(map[string]*model.Object)
+	found := innerPath == "/"
+	for _, e := range es {
+		if e.Path == innerPath {
+			if !e.IsDir {
+				return nil, errors.WithStack(errs.NotFolder)
+			}
+			found = true
+			continue
+		}
+		if !utils.IsSubPath(innerPath, e.Path) {
+			continue
+		}
+		found = true
+		rel := strings.TrimPrefix(strings.TrimPrefix(e.Path, innerPath), "/")
+		name, rest, isSub := strings.Cut(rel, "/")
+		obj, ok := objs[name]
+		if !ok {
+			obj = &model.Object{Name: name, Path: stdpath.Join(innerPath, name), IsFolder: true}
+			objs[name] = obj
+		}
+		// the entry of the object itself
+		if !isSub {
+			obj.IsFolder = e.IsDir
+			obj.Size = e.Size
+			obj.Modified = e.Modified
+		} else if rest != "" && obj.Modified.Before(e.Modified) {
+			obj.Modified = e.Modified
+		}
+	}
+	if !found {
+		return nil, errors.WithStack(errs.ObjectNotFound)
+	}
+	res := make([]model.Obj, 0, len(objs))
+	for _, obj := range objs {
+		res = append(res, obj)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].IsDir() != res[j].IsDir() {
+			return res[i].IsDir()
+		}
+		return res[i].GetName() < res[j].GetName()
+	})
+	return res, nil
+}
+
+// Open the file in the archive, the entry is returned with the reader
+func Open(ctx context.Context, path, innerPath, password string) (*Entry, io.ReadCloser, error) {
+	es, err := entries(ctx, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	innerPath = cleanPath(innerPath)
+	var entry *Entry
+	for i := range es {
+		if es[i].Path == innerPath {
+			entry = &es[i]
+			break
+		}
+	}
+	if entry == nil {
+		return nil, nil, errors.WithStack(errs.ObjectNotFound)
+	}
+	if entry.IsDir {
+		return nil, nil, errors.WithStack(errs.NotFile)
+	}
+	tool, r, closer, err := open(ctx, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	rc, err := tool.Open(r, innerPath, password)
+	if err != nil {
+		closer()
+		return nil, nil, errors.WithMessagef(err, "failed to open [%s] in [%s]", innerPath, path)
+	}
+	return entry, &readCloser{Reader: rc, close: func() error {
+		defer closer()
+		return rc.Close()
+	}}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r *readCloser) Close() error {
+	return r.close()
+}
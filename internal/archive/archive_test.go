@@ -0,0 +1,113 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+var files = map[string]string{
+	"a.txt":     "a",
+	"dir/b.txt": "bb",
+	"dir/c/d":   "ddd",
+}
+
+func testTool(t *testing.T, tool Tool, data []byte) {
+	r := io.NewSectionReader(bytes.NewReader(data), 0, int64(len(data)))
+	es, err := tool.List(r)
+	if err != nil {
+		t.Fatalf("failed to list: %+v", err)
+	}
+	for i := range es {
+		es[i].Path = cleanPath(es[i].Path)
+	}
+	objs, err := children(es, "/dir")
+	if err != nil {
+		t.Fatalf("failed to get the children: %+v", err)
+	}
+	// the dir c hasn't its own entry
+	if len(objs) != 2 || objs[0].GetName() != "c" || !objs[0].IsDir() || objs[1].GetName() != "b.txt" || objs[1].GetSize() != 2 {
+		t.Errorf("the children of /dir are wrong: %+v", objs)
+	}
+	if _, err := children(es, "/a.txt"); err == nil {
+		t.Errorf("expect error listing a file")
+	}
+	rc, err := tool.Open(r, "/dir/c/d", "")
+	if err != nil {
+		t.Fatalf("failed to open: %+v", err)
+	}
+	defer rc.Close()
+	if content, _ := io.ReadAll(rc); string(content) != files["dir/c/d"] {
+		t.Errorf("the content is wrong: %s", content)
+	}
+}
+
+func TestZip(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, _ := w.Create(name)
+		_, _ = f.Write([]byte(content))
+	}
+	_ = w.Close()
+	testTool(t, Zip{}, buf.Bytes())
+}
+
+func TestTar(t *testing.T) {
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	for name, content := range files {
+		_ = w.WriteHeader(&tar.Header{Name: "./" + name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		_, _ = w.Write([]byte(content))
+	}
+	_ = w.Close()
+	testTool(t, Tar{}, buf.Bytes())
+}
+
+func TestLinkReader(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, _ := w.CreateHeader(&zip.FileHeader{Name: "big.bin", Method: zip.Store})
+	_, _ = f.Write(bytes.Repeat([]byte{1}, 1024*1024))
+	f, _ = w.Create("a.txt")
+	_, _ = f.Write([]byte("a"))
+	_ = w.Close()
+	var read int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &countWriter{ResponseWriter: w, n: &read}
+		http.ServeContent(rw, r, "", time.Time{}, bytes.NewReader(buf.Bytes()))
+	}))
+	defer srv.Close()
+	r, err := newLinkReader(&model.Link{URL: srv.URL}, int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("failed to create the reader: %+v", err)
+	}
+	defer r.Close()
+	es, err := Zip{}.List(io.NewSectionReader(r, 0, int64(buf.Len())))
+	if err != nil || len(es) != 2 {
+		t.Fatalf("failed to list: %+v", err)
+	}
+	// the content of big.bin isn't downloaded, though the requests may be read partly
+	if read > int64(buf.Len())/2 {
+		t.Errorf("too many bytes are sent: %d", read)
+	}
+}
+
+type countWriter struct {
+	http.ResponseWriter
+	n *int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	atomic.AddInt64(w.n, int64(n))
+	return n, err
+}
@@ -0,0 +1,255 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	stdpath "path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+var CompressTaskManager = task.NewTaskManager(3, func(tid *uint64) {
+	atomic.AddUint64(tid, 1)
+})
+
+// archiveWriter write the entries to an archive format
+type archiveWriter interface {
+	AddDir(path string, modified time.Time) error
+	AddFile(path string, size int64, modified time.Time, r io.Reader) error
+	Close() error
+}
+
+// newArchiveWriter get the writer by the ext of the name, nil if the format can't be written
+func newArchiveWriter(name string, w io.Writer) archiveWriter {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return &zipWriter{w: zip.NewWriter(w)}
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		gw := gzip.NewWriter(w)
+		return &tarWriter{w: tar.NewWriter(gw), gw: gw}
+	case strings.HasSuffix(name, ".tar"):
+		return &tarWriter{w: tar.NewWriter(w)}
+	}
+	return nil
+}
+
+type zipWriter struct {
+	w *zip.Writer
+}
+
+func (z *zipWriter) AddDir(path string, modified time.Time) error {
+	_, err := z.w.CreateHeader(&zip.FileHeader{Name: path + "/", Modified: modified})
+	return errors.WithStack(err)
+}
+
+func (z *zipWriter) AddFile(path string, size int64, modified time.Time, r io.Reader) error {
+	w, err := z.w.CreateHeader(&zip.FileHeader{Name: path, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = io.Copy(w, r)
+	return errors.WithStack(err)
+}
+
+func (z *zipWriter) Close() error {
+	return errors.WithStack(z.w.Close())
+}
+
+type tarWriter struct {
+	w  *tar.Writer
+	gw *gzip.Writer
+}
+
+func (t *tarWriter) AddDir(path string, modified time.Time) error {
+	return errors.WithStack(t.w.WriteHeader(&tar.Header{
+		Name:     path + "/",
+		Mode:     0755,
+		ModTime:  modified,
+		Typeflag: tar.TypeDir,
+	}))
+}
+
+func (t *tarWriter) AddFile(path string, size int64, modified time.Time, r io.Reader) error {
+	err := t.w.WriteHeader(&tar.Header{
+		Name:     path,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  modified,
+		Typeflag: tar.TypeReg,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// the size in the header must be matched
+	n, err := io.Copy(t.w, io.LimitReader(r, size))
+	if err == nil && n != size {
+		err = errors.Errorf("expect %d bytes, got %d", size, n)
+	}
+	return errors.WithStack(err)
+}
+
+func (t *tarWriter) Close() error {
+	if err := t.w.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	if t.gw != nil {
+		return errors.WithStack(t.gw.Close())
+	}
+	return nil
+}
+
+// Compress add the task packing the objects named `names` in the src dir to the archive of the
+// dst path, the format is decided by the ext of the dst path, such as .zip, .tar and .tar.gz
+func Compress(ctx context.Context, srcDirPath string, names []string, dstPath string) error {
+	for _, name := range names {
+		if err := fs.CheckAcl(ctx, stdpath.Join(srcDirPath, name), model.AclRead); err != nil {
+			return err
+		}
+	}
+	if err := fs.CheckAcl(ctx, dstPath, model.AclWrite); err != nil {
+		return err
+	}
+	if newArchiveWriter(stdpath.Base(dstPath), io.Discard) == nil {
+		return errors.WithStack(errs.ArchiveNotSupported)
+	}
+	// the task runs after the request, so the user is passed to it
+	user, _ := ctx.Value("user").(*model.User)
+	CompressTaskManager.Submit(task.WithCancelCtx(&task.Task[uint64]{
+		Name: fmt.Sprintf("compress %s in [%s] to [%s]", strings.Join(names, ", "), srcDirPath, dstPath),
+		Func: func(t *task.Task[uint64]) error {
+			return compress(t, context.WithValue(t.Ctx, "user", user), srcDirPath, names, dstPath)
+		},
+	}))
+	return nil
+}
+
+type compressEntry struct {
+	path string
+	obj  model.Obj
+}
+
+// collect the objects to compress, the path is relative to the src dir
+func collect(ctx context.Context, srcDirPath, path string, obj model.Obj, res []compressEntry) ([]compressEntry, error) {
+	res = append(res, compressEntry{path: path, obj: obj})
+	if !obj.IsDir() {
+		return res, nil
+	}
+	// the objects are hidden by the meta of the dir
+	dir := stdpath.Join(srcDirPath, path)
+	meta, err := db.GetNearestMeta(dir)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return nil, err
+	}
+	objs, err := fs.List(context.WithValue(ctx, "meta", meta), dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range objs {
+		if res, err = collect(ctx, srcDirPath, stdpath.Join(path, o.GetName()), o, res); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func compress(t *task.Task[uint64], ctx context.Context, srcDirPath string, names []string, dstPath string) error {
+	t.SetStatus("listing the objects")
+	var es []compressEntry
+	for _, name := range names {
+		obj, err := fs.Get(ctx, stdpath.Join(srcDirPath, name))
+		if err != nil {
+			return err
+		}
+		if es, err = collect(ctx, srcDirPath, name, obj, es); err != nil {
+			return err
+		}
+	}
+	var total, done int64
+	for _, e := range es {
+		total += e.obj.GetSize()
+	}
+	// the size of the archive is needed by most of the drivers, so it's written to a temp file first
+	tmp, err := os.CreateTemp(conf.Conf.TempDir, "archive-*")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	w := newArchiveWriter(stdpath.Base(dstPath), tmp)
+	for _, e := range es {
+		if utils.IsCanceled(ctx) {
+			err = ctx.Err()
+			break
+		}
+		t.SetStatus(fmt.Sprintf("compressing [%s]", e.path))
+		if e.obj.IsDir() {
+			err = w.AddDir(e.path, e.obj.ModTime())
+		} else {
+			err = addFile(ctx, w, stdpath.Join(srcDirPath, e.path), e)
+		}
+		if err != nil {
+			break
+		}
+		done += e.obj.GetSize()
+		if total > 0 {
+			// the rest is for the uploading
+			t.SetProgress(int(done * 90 / total))
+		}
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return errors.WithStack(err)
+	}
+	t.SetStatus(fmt.Sprintf("uploading [%s]", dstPath))
+	// the temp file is removed by op.Put
+	err = fs.PutDirectly(ctx, stdpath.Dir(dstPath), &model.FileStream{
+		Obj: &model.Object{
+			Name:     stdpath.Base(dstPath),
+			Size:     info.Size(),
+			Modified: time.Now(),
+		},
+		ReadCloser: tmp,
+		Mimetype:   "application/octet-stream",
+	})
+	if err != nil {
+		return errors.WithMessagef(err, "failed to put [%s]", dstPath)
+	}
+	t.SetProgress(100)
+	return nil
+}
+
+func addFile(ctx context.Context, w archiveWriter, path string, e compressEntry) error {
+	file, err := fs.Open(ctx, path)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to open [%s]", path)
+	}
+	defer file.Close()
+	return w.AddFile(e.path, e.obj.GetSize(), e.obj.ModTime(), file)
+}
@@ -0,0 +1,102 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	stdpath "path"
+	"strings"
+	"sync/atomic"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+var ExtractTaskManager = task.NewTaskManager(3, func(tid *uint64) {
+	atomic.AddUint64(tid, 1)
+})
+
+// Extract add the task extracting the `innerPath` in the archive to the dst dir, the entries are
+// read from the archive and put to the storage one by one without the temp files
+func Extract(ctx context.Context, path, innerPath, dstDirPath, password string) error {
+	if err := fs.CheckAcl(ctx, path, model.AclRead); err != nil {
+		return err
+	}
+	if err := fs.CheckAcl(ctx, dstDirPath, model.AclWrite); err != nil {
+		return err
+	}
+	if !Supported(stdpath.Base(path)) {
+		return errors.WithStack(errs.ArchiveNotSupported)
+	}
+	// the task runs after the request, so the user is passed to it
+	user, _ := ctx.Value("user").(*model.User)
+	innerPath = cleanPath(innerPath)
+	ExtractTaskManager.Submit(task.WithCancelCtx(&task.Task[uint64]{
+		Name: fmt.Sprintf("extract [%s](%s) to [%s]", path, innerPath, dstDirPath),
+		Func: func(t *task.Task[uint64]) error {
+			return extract(t, context.WithValue(t.Ctx, "user", user), path, innerPath, dstDirPath, password)
+		},
+	}))
+	return nil
+}
+
+func extract(t *task.Task[uint64], ctx context.Context, path, innerPath, dstDirPath, password string) error {
+	t.SetStatus("reading the entries")
+	es, err := entries(ctx, path)
+	if err != nil {
+		return err
+	}
+	var total, done int64
+	for _, e := range es {
+		if !e.IsDir && utils.IsSubPath(innerPath, e.Path) {
+			total += e.Size
+		}
+	}
+	tool, r, closer, err := open(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer closer()
+	return tool.Walk(r, password, func(e Entry, r io.Reader) error {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		p := cleanPath(e.Path)
+		if !utils.IsSubPath(innerPath, p) {
+			return nil
+		}
+		// the inner path is extracted into the dst dir with its name
+		dst := stdpath.Join(dstDirPath, strings.TrimPrefix(p, stdpath.Dir(innerPath)))
+		t.SetStatus(fmt.Sprintf("extracting [%s]", p))
+		if e.IsDir {
+			return fs.MakeDir(ctx, dst)
+		}
+		name := stdpath.Base(dst)
+		mimetype := mime.TypeByExtension(stdpath.Ext(name))
+		if mimetype == "" {
+			mimetype = "application/octet-stream"
+		}
+		err := fs.PutDirectly(ctx, stdpath.Dir(dst), &model.FileStream{
+			Obj: &model.Object{
+				Name:     name,
+				Size:     e.Size,
+				Modified: e.Modified,
+			},
+			ReadCloser: io.NopCloser(r),
+			Mimetype:   mimetype,
+		})
+		if err != nil {
+			return errors.WithMessagef(err, "failed to put [%s]", dst)
+		}
+		done += e.Size
+		if total > 0 {
+			t.SetProgress(int(done * 100 / total))
+		}
+		return nil
+	})
+}
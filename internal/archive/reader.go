@@ -0,0 +1,164 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// the max bytes skipped by reading instead of requesting again
+const maxSkip = 64 * 1024
+
+// linkReader read the content of the link at any offset, the reader is kept for the sequential
+// reads, so the entries are read with one request, and the jumps request from the new offset
+type linkReader struct {
+	mu   sync.Mutex
+	size int64
+	// the local file is read at the offset directly
+	file *os.File
+	open func(offset int64) (io.ReadCloser, error)
+	// the reader at pos, nil if it should be reopened
+	rc  io.ReadCloser
+	pos int64
+}
+
+func newLinkReader(link *model.Link, size int64) (*linkReader, error) {
+	r := &linkReader{size: size}
+	switch {
+	case link.FilePath != nil && *link.FilePath != "":
+		f, err := os.Open(*link.FilePath)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		r.file = f
+	case link.Data != nil:
+		if link.RangeReader == nil {
+			_ = link.Data.Close()
+			return nil, errors.New("the archive can't be read at any offset")
+		}
+		r.rc = link.Data
+		r.open = link.RangeReader
+	case link.URL != "":
+		r.open = func(offset int64) (io.ReadCloser, error) {
+			return requestLink(link, offset)
+		}
+	default:
+		return nil, errors.New("the link of the archive is empty")
+	}
+	return r, nil
+}
+
+func requestLink(link *model.Link, offset int64) (io.ReadCloser, error) {
+	res, err := request(link, offset)
+	if err != nil {
+		return nil, err
+	}
+	// the url may be expired
+	if res.StatusCode == http.StatusForbidden && link.Refresh != nil {
+		_ = res.Body.Close()
+		if link, err = link.Refresh(context.Background()); err != nil {
+			return nil, err
+		}
+		if res, err = request(link, offset); err != nil {
+			return nil, err
+		}
+	}
+	status := http.StatusOK
+	if offset > 0 {
+		status = http.StatusPartialContent
+	}
+	if res.StatusCode != status {
+		_ = res.Body.Close()
+		return nil, errors.Errorf("failed to read from %d: unexpected status %s", offset, res.Status)
+	}
+	return res.Body, nil
+}
+
+func request(link *model.Link, offset int64) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, link.URL, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for h, val := range link.Header {
+		req.Header[h] = val
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	res, err := http.DefaultClient.Do(req)
+	return res, errors.WithStack(err)
+}
+
+func (r *linkReader) ReadAt(p []byte, off int64) (int, error) {
+	if r.file != nil {
+		return r.file.ReadAt(p, off)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	if err := r.seek(off); err != nil {
+		return 0, err
+	}
+	want := len(p)
+	if int64(want) > r.size-off {
+		want = int(r.size - off)
+	}
+	n, err := io.ReadFull(r.rc, p[:want])
+	r.pos += int64(n)
+	if err != nil {
+		_ = r.rc.Close()
+		r.rc = nil
+		return n, errors.WithStack(err)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// seek make rc read at the offset
+func (r *linkReader) seek(off int64) error {
+	if r.rc != nil && r.pos == off {
+		return nil
+	}
+	if r.rc != nil && off > r.pos && off-r.pos <= maxSkip {
+		n, err := io.CopyN(ioutil.Discard, r.rc, off-r.pos)
+		r.pos += n
+		if err == nil {
+			return nil
+		}
+	}
+	if r.rc != nil {
+		_ = r.rc.Close()
+	}
+	rc, err := r.open(off)
+	if err != nil {
+		r.rc = nil
+		return err
+	}
+	r.rc, r.pos = rc, off
+	return nil
+}
+
+func (r *linkReader) Close() error {
+	if r.file != nil {
+		return r.file.Close()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.rc == nil {
+		return nil
+	}
+	err := r.rc.Close()
+	r.rc = nil
+	return err
+}
@@ -0,0 +1,115 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/pkg/errors"
+)
+
+// Tar read the headers one by one, the content of the entries is skipped by seeking if
+// the tar isn't compressed, or else the whole archive before the entry is downloaded
+type Tar struct {
+	Gzip bool
+}
+
+func (t Tar) reader(r *io.SectionReader) (*tar.Reader, func(), error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	if !t.Gzip {
+		// the section reader can seek, so the content is skipped without reading
+		return tar.NewReader(r), func() {}, nil
+	}
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return tar.NewReader(gr), func() { _ = gr.Close() }, nil
+}
+
+func (t Tar) List(r *io.SectionReader) ([]Entry, error) {
+	tr, closer, err := t.reader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer closer()
+	var es []Entry
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return es, nil
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if h.Typeflag != tar.TypeReg && h.Typeflag != tar.TypeDir {
+			continue
+		}
+		es = append(es, Entry{
+			Path:     h.Name,
+			Size:     h.Size,
+			Modified: h.ModTime,
+			IsDir:    h.Typeflag == tar.TypeDir,
+		})
+	}
+}
+
+func (t Tar) Open(r *io.SectionReader, path, password string) (io.ReadCloser, error) {
+	tr, closer, err := t.reader(r)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			closer()
+			return nil, errors.WithStack(errs.ObjectNotFound)
+		}
+		if err != nil {
+			closer()
+			return nil, errors.WithStack(err)
+		}
+		if h.Typeflag == tar.TypeReg && cleanPath(h.Name) == path {
+			return &readCloser{Reader: tr, close: func() error {
+				closer()
+				return nil
+			}}, nil
+		}
+	}
+}
+
+func (t Tar) Walk(r *io.SectionReader, password string, fn func(e Entry, r io.Reader) error) error {
+	tr, closer, err := t.reader(r)
+	if err != nil {
+		return err
+	}
+	defer closer()
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		e := Entry{Path: h.Name, Size: h.Size, Modified: h.ModTime}
+		switch h.Typeflag {
+		case tar.TypeDir:
+			e.IsDir = true
+			err = fn(e, nil)
+		case tar.TypeReg:
+			err = fn(e, tr)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func init() {
+	RegisterTool(Tar{}, ".tar")
+	RegisterTool(Tar{Gzip: true}, ".tar.gz", ".tgz")
+}
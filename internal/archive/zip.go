@@ -0,0 +1,75 @@
+package archive
+
+import (
+	"archive/zip"
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/pkg/errors"
+)
+
+// Zip read the central directory at the end of the archive, so only it and the entries
+// opened are downloaded
+type Zip struct{}
+
+func (Zip) List(r *io.SectionReader) ([]Entry, error) {
+	zr, err := zip.NewReader(r, r.Size())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	es := make([]Entry, 0, len(zr.File))
+	for _, f := range zr.File {
+		info := f.FileInfo()
+		es = append(es, Entry{
+			Path:     f.Name,
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+			IsDir:    info.IsDir(),
+		})
+	}
+	return es, nil
+}
+
+func (Zip) Open(r *io.SectionReader, path, password string) (io.ReadCloser, error) {
+	zr, err := zip.NewReader(r, r.Size())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, f := range zr.File {
+		if cleanPath(f.Name) == path {
+			return openZipFile(f, password)
+		}
+	}
+	return nil, errors.WithStack(errs.ObjectNotFound)
+}
+
+func (Zip) Walk(r *io.SectionReader, password string, fn func(e Entry, r io.Reader) error) error {
+	zr, err := zip.NewReader(r, r.Size())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, f := range zr.File {
+		info := f.FileInfo()
+		e := Entry{Path: f.Name, Size: info.Size(), Modified: info.ModTime(), IsDir: info.IsDir()}
+		if e.IsDir {
+			if err := fn(e, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		rc, err := openZipFile(f, password)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to open [%s]", f.Name)
+		}
+		err = fn(e, rc)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	RegisterTool(Zip{}, ".zip")
+}
@@ -0,0 +1,211 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	flagEncrypted  = 0x1
+	flagDescriptor = 0x8
+	// the method of the entries encrypted with aes, the real method is in the extra field
+	methodAES = 99
+	extraAES  = 0x9901
+)
+
+// openZipFile open the file in the zip, the encrypted files are decrypted with the password
+// by the traditional PKWARE encryption or the WinZip AES encryption
+func openZipFile(f *zip.File, password string) (io.ReadCloser, error) {
+	if f.Flags&flagEncrypted == 0 {
+		rc, err := f.Open()
+		return rc, errors.WithStack(err)
+	}
+	if password == "" {
+		return nil, errors.WithStack(errs.ArchivePasswordRequired)
+	}
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	method := f.Method
+	var r io.Reader
+	if f.Method == methodAES {
+		if r, method, err = decryptAES(f, raw, password); err != nil {
+			return nil, err
+		}
+	} else if r, err = decryptZipCrypto(f, raw, password); err != nil {
+		return nil, err
+	}
+	switch method {
+	case zip.Store:
+		return io.NopCloser(r), nil
+	case zip.Deflate:
+		return flate.NewReader(r), nil
+	default:
+		return nil, errors.WithStack(zip.ErrAlgorithm)
+	}
+}
+
+// zipCrypto is the traditional PKWARE encryption
+type zipCrypto struct {
+	keys [3]uint32
+	r    io.Reader
+}
+
+func newZipCrypto(password string, r io.Reader) *zipCrypto {
+	z := &zipCrypto{keys: [3]uint32{0x12345678, 0x23456789, 0x34567890}, r: r}
+	for i := 0; i < len(password); i++ {
+		z.update(password[i])
+	}
+	return z
+}
+
+func crc32Update(crc uint32, b byte) uint32 {
+	return crc32.IEEETable[byte(crc)^b] ^ (crc >> 8)
+}
+
+func (z *zipCrypto) update(b byte) {
+	z.keys[0] = crc32Update(z.keys[0], b)
+	z.keys[1] = (z.keys[1]+z.keys[0]&0xff)*134775813 + 1
+	z.keys[2] = crc32Update(z.keys[2], byte(z.keys[1]>>24))
+}
+
+func (z *zipCrypto) Read(p []byte) (int, error) {
+	n, err := z.r.Read(p)
+	for i := 0; i < n; i++ {
+		t := z.keys[2] | 2
+		p[i] ^= byte((t * (t ^ 1)) >> 8)
+		z.update(p[i])
+	}
+	return n, err
+}
+
+func decryptZipCrypto(f *zip.File, raw io.Reader, password string) (io.Reader, error) {
+	z := newZipCrypto(password, raw)
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(z, header); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// the last byte of the header is the high byte of the crc, or the time if the crc is unknown
+	check := byte(f.CRC32 >> 24)
+	if f.Flags&flagDescriptor != 0 {
+		check = byte(f.ModifiedTime >> 8)
+	}
+	if header[11] != check {
+		return nil, errors.WithStack(errs.WrongArchivePassword)
+	}
+	return z, nil
+}
+
+func decryptAES(f *zip.File, raw io.Reader, password string) (io.Reader, uint16, error) {
+	strength, method, ok := aesExtra(f.Extra)
+	if !ok {
+		return nil, 0, errors.New("the aes extra field is invalid")
+	}
+	keyLen := 8 * (int(strength) + 1)
+	salt := make([]byte, keyLen/2)
+	verify := make([]byte, 2)
+	if _, err := io.ReadFull(raw, salt); err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	if _, err := io.ReadFull(raw, verify); err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	key := pbkdf2.Key([]byte(password), salt, 1000, 2*keyLen+2, sha1.New)
+	if !bytes.Equal(key[2*keyLen:], verify) {
+		return nil, 0, errors.WithStack(errs.WrongArchivePassword)
+	}
+	block, err := aes.NewCipher(key[:keyLen])
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	// the salt, the verification and the authentication code aren't the data
+	size := int64(f.CompressedSize64) - int64(len(salt)) - 2 - 10
+	if size < 0 {
+		return nil, 0, errors.New("the size of the aes data is invalid")
+	}
+	mac := hmac.New(sha1.New, key[keyLen:2*keyLen])
+	return &aesReader{
+		data:  io.LimitReader(raw, size),
+		raw:   raw,
+		block: block,
+		mac:   mac,
+	}, method, nil
+}
+
+// aesExtra get the strength and the real method in the extra field of the aes encryption
+func aesExtra(extra []byte) (byte, uint16, bool) {
+	for len(extra) >= 4 {
+		tag := binary.LittleEndian.Uint16(extra)
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		extra = extra[4:]
+		if size > len(extra) {
+			return 0, 0, false
+		}
+		if tag == extraAES && size >= 7 && extra[4] >= 1 && extra[4] <= 3 {
+			return extra[4], binary.LittleEndian.Uint16(extra[5:]), true
+		}
+		extra = extra[size:]
+	}
+	return 0, 0, false
+}
+
+// aesReader decrypt with aes in the ctr mode of WinZip, the counter is little endian and starts
+// from 1. the data is authenticated with the code at the end
+type aesReader struct {
+	data, raw io.Reader
+	block     cipher.Block
+	mac       hash.Hash
+	counter   [aes.BlockSize]byte
+	stream    [aes.BlockSize]byte
+	// the bytes of the stream used
+	used int
+	init bool
+}
+
+func (r *aesReader) Read(p []byte) (int, error) {
+	n, err := r.data.Read(p)
+	r.mac.Write(p[:n])
+	for i := 0; i < n; i++ {
+		if !r.init || r.used == aes.BlockSize {
+			r.next()
+		}
+		p[i] ^= r.stream[r.used]
+		r.used++
+	}
+	if err == io.EOF {
+		code := make([]byte, 10)
+		if _, cerr := io.ReadFull(r.raw, code); cerr != nil {
+			return n, errors.WithStack(cerr)
+		}
+		if !hmac.Equal(r.mac.Sum(nil)[:10], code) {
+			return n, errors.New("the authentication of the aes data failed")
+		}
+	}
+	return n, err
+}
+
+func (r *aesReader) next() {
+	r.init = true
+	for i := range r.counter {
+		r.counter[i]++
+		if r.counter[i] != 0 {
+			break
+		}
+	}
+	r.block.Encrypt(r.stream[:], r.counter[:])
+	r.used = 0
+}
@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	log "github.com/sirupsen/logrus"
+)
+
+// the access logs waiting to be written, the logs are dropped if it's full
+var accessLogs = make(chan model.AccessLog, 4096)
+
+// RecordAccess record the download by the link if the access log is enabled
+func RecordAccess(l model.AccessLog) {
+	if !setting.GetBool(conf.AccessLogEnabled) {
+		return
+	}
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = time.Now()
+	}
+	l.Day = l.CreatedAt.Format("2006-01-02")
+	select {
+	case accessLogs <- l:
+	default:
+		log.Warnf("too many access logs, the log of [%s] is dropped", l.Path)
+	}
+}
+
+func flushAccess(batch []model.AccessLog) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := db.CreateAccessLogs(batch); err != nil {
+		log.Errorf("failed write %d access logs: %+v", len(batch), err)
+	}
+}
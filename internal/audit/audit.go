@@ -0,0 +1,151 @@
+// Package audit records who did the operations on the files and the downloads by the links,
+// the logs are written to the database in batches and the ones older than
+// conf.AuditRetentionDays are deleted
+package audit
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	log "github.com/sirupsen/logrus"
+)
+
+// the logs waiting to be written, the logs are dropped if it's full
+var logs = make(chan model.AuditLog, 4096)
+
+const batchSize = 100
+
+// Record record the operation on the path by the user in ctx, the user is empty for the signed
+// downloads. only the operations requested by the clients are recorded, not the ones by the
+// scheduler or the tasks without the ip
+func Record(ctx context.Context, op, path string, err error) {
+	RecordWith(ctx, op, path, "", 0, err)
+}
+
+// RecordWith record the operation with the target, such as the dst dir of moving,
+// and the bytes transferred
+func RecordWith(ctx context.Context, op, path, target string, bytes int64, err error) {
+	if !setting.GetBool(conf.AuditEnabled) {
+		return
+	}
+	ip, _ := ctx.Value("ip").(string)
+	if ip == "" {
+		return
+	}
+	l := model.AuditLog{
+		CreatedAt: time.Now(),
+		IP:        ip,
+		Op:        op,
+		Path:      path,
+		Target:    target,
+		Success:   err == nil,
+		Bytes:     bytes,
+	}
+	if user, ok := ctx.Value("user").(*model.User); ok && user != nil {
+		l.UserID, l.Username = user.ID, user.Username
+	}
+	if err != nil {
+		l.Message = err.Error()
+	}
+	select {
+	case logs <- l:
+	default:
+		log.Warnf("too many audit logs, the log of %s [%s] is dropped", op, path)
+	}
+}
+
+// RecordDownload record the download of the file, the size of it is recorded if succeeded
+func RecordDownload(ctx context.Context, path string, file model.Obj, err error) {
+	var size int64
+	if err == nil && file != nil {
+		size = file.GetSize()
+	}
+	RecordWith(ctx, model.AuditDownload, path, "", size, err)
+}
+
+// WithIP set the ip of the client to ctx, it's recorded with the operations
+func WithIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, "ip", ip)
+}
+
+// RemoteIP get the ip of the remote address of the connection
+func RemoteIP(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+func flush(batch []model.AuditLog) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := db.CreateAuditLogs(batch); err != nil {
+		log.Errorf("failed write %d audit logs: %+v", len(batch), err)
+	}
+}
+
+func clean() {
+	days := setting.GetInt(conf.AuditRetentionDays, 0)
+	if days <= 0 {
+		return
+	}
+	before := time.Now().AddDate(0, 0, -days)
+	n, err := db.DeleteAuditLogsBefore(before)
+	if err != nil {
+		log.Errorf("failed delete the expired audit logs: %+v", err)
+	} else if n > 0 {
+		log.Infof("deleted %d audit logs older than %d days", n, days)
+	}
+	n, err = db.DeleteAccessLogsBefore(before)
+	if err != nil {
+		log.Errorf("failed delete the expired access logs: %+v", err)
+	} else if n > 0 {
+		log.Infof("deleted %d access logs older than %d days", n, days)
+	}
+}
+
+// Run write the logs every second and delete the expired ones every hour,
+// it returns when ctx is done
+func Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	cleaner := time.NewTicker(time.Hour)
+	defer cleaner.Stop()
+	clean()
+	batch := make([]model.AuditLog, 0, batchSize)
+	accessBatch := make([]model.AccessLog, 0, batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			flush(batch)
+			flushAccess(accessBatch)
+			return
+		case l := <-logs:
+			batch = append(batch, l)
+			if len(batch) >= batchSize {
+				flush(batch)
+				batch = batch[:0]
+			}
+		case l := <-accessLogs:
+			accessBatch = append(accessBatch, l)
+			if len(accessBatch) >= batchSize {
+				flushAccess(accessBatch)
+				accessBatch = accessBatch[:0]
+			}
+		case <-ticker.C:
+			flush(batch)
+			batch = batch[:0]
+			flushAccess(accessBatch)
+			accessBatch = accessBatch[:0]
+		case <-cleaner.C:
+			clean()
+		}
+	}
+}
@@ -0,0 +1,399 @@
+// Package backup export the settings, the users with their groups and acl rules, the storages
+// and the metas to a bundle,
+// and restore them from it, so an instance can be migrated or recovered
+package backup
+
+import (
+	"encoding/base64"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// Version is the version of the format of the bundle, the groups and the acl rules are added in 2
+const Version = 2
+
+// Bundle is the backup of an instance, the 2FA of the users isn't included,
+// since it's encrypted by the master key of the instance
+type Bundle struct {
+	Version      int       `json:"version"`
+	AlistVersion string    `json:"alist_version"`
+	CreatedAt    time.Time `json:"created_at"`
+	// the salt of the key derived from the password, empty if the secrets are plain
+	Salt     string              `json:"salt,omitempty"`
+	Settings []model.SettingItem `json:"settings"`
+	Users    []model.User        `json:"users"`
+	Groups   []model.Group       `json:"groups"`
+	// the acl rules and the names of the groups of the users by the username,
+	// since the ids differ between the instances
+	UserAcls   map[string][]model.AclRule `json:"user_acls"`
+	UserGroups map[string][]string        `json:"user_groups"`
+	Storages   []model.Storage            `json:"storages"`
+	Metas      []model.Meta               `json:"metas"`
+}
+
+// Export get the bundle of the instance, the secrets such as the passwords, the private settings
+// and the secrets of the storages are encrypted if the password is set
+func Export(password string) (*Bundle, error) {
+	b := &Bundle{Version: Version, AlistVersion: conf.Version, CreatedAt: time.Now()}
+	var err error
+	if b.Settings, err = db.GetSettingItems(); err != nil {
+		return nil, errors.WithMessage(err, "failed get settings")
+	}
+	if b.Users, err = db.GetAllUsers(); err != nil {
+		return nil, errors.WithMessage(err, "failed get users")
+	}
+	if err = b.exportAcls(); err != nil {
+		return nil, err
+	}
+	if b.Storages, err = db.GetAllStorages(); err != nil {
+		return nil, errors.WithMessage(err, "failed get storages")
+	}
+	if b.Metas, err = db.GetAllMetas(); err != nil {
+		return nil, errors.WithMessage(err, "failed get metas")
+	}
+	if password == "" {
+		return b, nil
+	}
+	salt, err := newSalt()
+	if err != nil {
+		return nil, err
+	}
+	s, err := newSealer(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	b.Salt = base64.StdEncoding.EncodeToString(salt)
+	return b, b.mapSecrets(s.encrypt)
+}
+
+// exportAcls add the groups, and the acl rules and the groups of the users by the username
+func (b *Bundle) exportAcls() error {
+	var err error
+	if b.Groups, err = db.GetGroups(); err != nil {
+		return errors.WithMessage(err, "failed get groups")
+	}
+	usernames := make(map[uint]string, len(b.Users))
+	for _, u := range b.Users {
+		usernames[u.ID] = u.Username
+	}
+	groupNames := make(map[uint]string, len(b.Groups))
+	for _, g := range b.Groups {
+		groupNames[g.ID] = g.Name
+	}
+	rules, err := db.GetAllAclRules()
+	if err != nil {
+		return errors.WithMessage(err, "failed get acl rules")
+	}
+	b.UserAcls = make(map[string][]model.AclRule)
+	for _, r := range rules {
+		username, ok := usernames[r.UserID]
+		if !ok {
+			continue
+		}
+		r.ID, r.UserID = 0, 0
+		b.UserAcls[username] = append(b.UserAcls[username], r)
+	}
+	ugs, err := db.GetAllUserGroups()
+	if err != nil {
+		return errors.WithMessage(err, "failed get groups of users")
+	}
+	b.UserGroups = make(map[string][]string)
+	for _, ug := range ugs {
+		username, ok := usernames[ug.UserID]
+		name, ok2 := groupNames[ug.GroupID]
+		if !ok || !ok2 {
+			continue
+		}
+		b.UserGroups[username] = append(b.UserGroups[username], name)
+	}
+	return nil
+}
+
+// mapSecrets replace the secrets in the bundle with the values returned by fn
+func (b *Bundle) mapSecrets(fn func(string) (string, error)) error {
+	var err error
+	mapValue := func(v *string) {
+		if err == nil {
+			*v, err = fn(*v)
+		}
+	}
+	for i := range b.Settings {
+		if b.Settings[i].Flag == model.PRIVATE {
+			mapValue(&b.Settings[i].Value)
+		}
+	}
+	for i := range b.Users {
+		mapValue(&b.Users[i].Password)
+	}
+	for i := range b.Metas {
+		mapValue(&b.Metas[i].Password)
+	}
+	for i := range b.Storages {
+		b.Storages[i].MapSecrets(func(name, value string) string {
+			mapValue(&value)
+			return value
+		})
+	}
+	return err
+}
+
+type ImportResult struct {
+	Settings int `json:"settings"`
+	Users    int `json:"users"`
+	Groups   int `json:"groups"`
+	Storages int `json:"storages"`
+	Metas    int `json:"metas"`
+	// the items failed to import by the name, such as the username or the mount path
+	Failed map[string]string `json:"failed"`
+}
+
+// Import restore the bundle, the existing items are updated by the key of the setting,
+// the username, the mount path and the path of the meta, the others are created.
+// the storages are saved in the database only, op.ReloadStorages should be called
+// if the server is running
+func Import(b *Bundle, password string) (*ImportResult, error) {
+	if b.Version == 0 || b.Version > Version {
+		return nil, errors.Errorf("unsupported version of the backup: %d", b.Version)
+	}
+	if b.Salt != "" {
+		if password == "" {
+			return nil, errors.New("the backup is encrypted, the password is required")
+		}
+		salt, err := base64.StdEncoding.DecodeString(b.Salt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid salt of the backup")
+		}
+		s, err := newSealer(password, salt)
+		if err != nil {
+			return nil, err
+		}
+		// all the secrets are decrypted first, so nothing is imported with the wrong password
+		if err = b.mapSecrets(s.decrypt); err != nil {
+			return nil, err
+		}
+	}
+	res := &ImportResult{Failed: make(map[string]string)}
+	if err := importSettings(b.Settings, res); err != nil {
+		return nil, err
+	}
+	if err := importGroups(b.Groups, res); err != nil {
+		return nil, err
+	}
+	if err := importUsers(b, res); err != nil {
+		return nil, err
+	}
+	if err := importStorages(b.Storages, res); err != nil {
+		return nil, err
+	}
+	if err := importMetas(b.Metas, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// importSettings update the values of the settings, the ones unknown by the instance,
+// readonly or deprecated are skipped
+func importSettings(settings []model.SettingItem, res *ImportResult) error {
+	items, err := db.GetSettingItems()
+	if err != nil {
+		return errors.WithMessage(err, "failed get settings")
+	}
+	existing := make(map[string]model.SettingItem, len(items))
+	for _, item := range items {
+		existing[item.Key] = item
+	}
+	var changed []model.SettingItem
+	for _, item := range settings {
+		cur, ok := existing[item.Key]
+		if !ok || cur.Flag == model.READONLY || cur.IsDeprecated() {
+			continue
+		}
+		cur.Value = item.Value
+		changed = append(changed, cur)
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	if err = db.SaveSettingItems(changed); err != nil {
+		return errors.WithMessage(err, "failed save settings")
+	}
+	res.Settings = len(changed)
+	return nil
+}
+
+// importGroups update the groups by the name, the others are created
+func importGroups(groups []model.Group, res *ImportResult) error {
+	items, err := db.GetGroups()
+	if err != nil {
+		return errors.WithMessage(err, "failed get groups")
+	}
+	existing := make(map[string]uint, len(items))
+	for _, g := range items {
+		existing[g.Name] = g.ID
+	}
+	for _, g := range groups {
+		if id, ok := existing[g.Name]; ok {
+			g.ID = id
+			err = db.UpdateGroup(&g)
+		} else {
+			g.ID = 0
+			err = db.CreateGroup(&g)
+		}
+		if err != nil {
+			res.Failed["group "+g.Name] = err.Error()
+			continue
+		}
+		res.Groups++
+	}
+	return nil
+}
+
+// importUsers update the admin and the guest by the role, the others by the username,
+// the 2FA of the existing users is kept, the acl rules and the groups of the users are
+// replaced by the ones in the bundle
+func importUsers(b *Bundle, res *ImportResult) error {
+	groups, err := db.GetGroups()
+	if err != nil {
+		return errors.WithMessage(err, "failed get groups")
+	}
+	groupIds := make(map[string]uint, len(groups))
+	for _, g := range groups {
+		groupIds[g.Name] = g.ID
+	}
+	items, err := db.GetAllUsers()
+	if err != nil {
+		return errors.WithMessage(err, "failed get users")
+	}
+	byName := make(map[string]model.User, len(items))
+	byRole := make(map[int]model.User)
+	for _, u := range items {
+		byName[u.Username] = u
+		if !u.IsAdmin() && !u.IsGuest() {
+			continue
+		}
+		byRole[u.Role] = u
+	}
+	for _, u := range b.Users {
+		cur, ok := byName[u.Username]
+		if u.IsAdmin() || u.IsGuest() {
+			if ok && cur.Role != u.Role {
+				res.Failed["user "+u.Username] = "the username is used by another user"
+				continue
+			}
+			cur, ok = byRole[u.Role]
+		} else if ok && cur.Role != u.Role {
+			res.Failed["user "+u.Username] = "the username is used by the admin or the guest"
+			continue
+		}
+		if ok {
+			u.ID = cur.ID
+			u.OtpSecret = cur.OtpSecret
+			u.OtpRecoveryCodes = cur.OtpRecoveryCodes
+			err = db.UpdateUser(&u)
+		} else {
+			u.ID = 0
+			err = db.CreateUser(&u)
+		}
+		if err != nil {
+			res.Failed["user "+u.Username] = err.Error()
+			continue
+		}
+		// the bundles before version 2 have no acl rules and groups, the existing ones are kept
+		if b.Version >= 2 {
+			err = importUserAcls(u.ID, b.UserAcls[u.Username], b.UserGroups[u.Username], groupIds)
+		}
+		if err != nil {
+			// the user without the rules may access more than it should, so it's removed if just created
+			if !ok {
+				_ = db.DeleteUserById(u.ID)
+			}
+			res.Failed["user "+u.Username] = err.Error()
+			continue
+		}
+		res.Users++
+	}
+	return nil
+}
+
+// importUserAcls replace the acl rules and the groups of the user, the groups are mapped by the name
+func importUserAcls(userId uint, rules []model.AclRule, groupNames []string, groupIds map[string]uint) error {
+	ids := make([]uint, 0, len(groupNames))
+	for _, name := range groupNames {
+		id, ok := groupIds[name]
+		if !ok {
+			return errors.Errorf("the group %s isn't imported", name)
+		}
+		ids = append(ids, id)
+	}
+	if err := db.SetAclRules(userId, rules); err != nil {
+		return errors.WithMessage(err, "failed set acl rules")
+	}
+	return errors.WithMessage(db.SetUserGroups(userId, ids), "failed set groups")
+}
+
+func importStorages(storages []model.Storage, res *ImportResult) error {
+	items, err := db.GetAllStorages()
+	if err != nil {
+		return errors.WithMessage(err, "failed get storages")
+	}
+	existing := make(map[string]uint, len(items))
+	for _, s := range items {
+		existing[utils.StandardizePath(s.MountPath)] = s.ID
+	}
+	for _, s := range storages {
+		s.MountPath = utils.StandardizePath(s.MountPath)
+		if _, err := op.GetDriverNew(s.Driver); err != nil {
+			res.Failed["storage "+s.MountPath] = err.Error()
+			continue
+		}
+		// the storages modified are initialized again by op.ReloadStorages
+		s.Modified = time.Now()
+		s.Status = ""
+		if id, ok := existing[s.MountPath]; ok {
+			s.ID = id
+			err = db.UpdateStorage(&s)
+		} else {
+			s.ID = 0
+			err = db.CreateStorage(&s)
+		}
+		if err != nil {
+			res.Failed["storage "+s.MountPath] = err.Error()
+			continue
+		}
+		res.Storages++
+	}
+	return nil
+}
+
+func importMetas(metas []model.Meta, res *ImportResult) error {
+	items, err := db.GetAllMetas()
+	if err != nil {
+		return errors.WithMessage(err, "failed get metas")
+	}
+	existing := make(map[string]uint, len(items))
+	for _, m := range items {
+		existing[m.Path] = m.ID
+	}
+	for _, m := range metas {
+		m.Path = utils.StandardizePath(m.Path)
+		if id, ok := existing[m.Path]; ok {
+			m.ID = id
+			err = db.UpdateMeta(&m)
+		} else {
+			m.ID = 0
+			err = db.CreateMeta(&m)
+		}
+		if err != nil {
+			res.Failed["meta "+m.Path] = err.Error()
+			continue
+		}
+		res.Metas++
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+// the prefix of the values encrypted by the password of the bundle
+const encryptedPrefix = "bak:"
+
+var ErrWrongPassword = errors.New("wrong password of the backup")
+
+// sealer encrypt and decrypt the secrets in the bundle with the key derived from the password
+type sealer struct {
+	gcm cipher.AEAD
+}
+
+func newSealer(password string, salt []byte) (*sealer, error) {
+	key, err := scrypt.Key([]byte(password), salt, 32768, 8, 1, 32)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &sealer{gcm: gcm}, nil
+}
+
+func newSalt() ([]byte, error) {
+	salt := make([]byte, 16)
+	_, err := rand.Read(salt)
+	return salt, errors.WithStack(err)
+}
+
+// encrypt the value, the empty value is kept
+func (s *sealer) encrypt(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	nonce := make([]byte, s.gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.WithStack(err)
+	}
+	data := s.gcm.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// decrypt the value encrypted by encrypt, the plain value is returned as it is
+func (s *sealer) decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if len(data) < s.gcm.NonceSize() {
+		return "", errors.WithStack(ErrWrongPassword)
+	}
+	plain, err := s.gcm.Open(nil, data[:s.gcm.NonceSize()], data[s.gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.WithStack(ErrWrongPassword)
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,12 @@
+package bootstrap
+
+import (
+	"github.com/alist-org/alist/v3/internal/antileech"
+	log "github.com/sirupsen/logrus"
+)
+
+func LoadAntiLeeches() {
+	if err := antileech.Load(); err != nil {
+		log.Errorf("failed load anti leech rules: %+v", err)
+	}
+}
@@ -0,0 +1,10 @@
+package bootstrap
+
+import (
+	"github.com/alist-org/alist/v3/internal/audit"
+)
+
+// InitAudit start writing the audit logs and deleting the expired ones
+func InitAudit() {
+	goWorker(audit.Run)
+}
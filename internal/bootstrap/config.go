@@ -4,11 +4,14 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 
 	"github.com/alist-org/alist/v3/cmd/flags"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/caarlos0/env/v6"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -63,18 +66,88 @@ func InitConfig() {
 	if err != nil {
 		log.Fatalf("create temp dir error: %+v", err)
 	}
+	if !filepath.IsAbs(conf.Conf.UploadDir) {
+		absPath, err := filepath.Abs(conf.Conf.UploadDir)
+		if err != nil {
+			log.Fatalf("get abs path error: %+v", err)
+		}
+		conf.Conf.UploadDir = absPath
+	}
+	err = os.MkdirAll(conf.Conf.UploadDir, 0700)
+	if err != nil {
+		log.Fatalf("create upload dir error: %+v", err)
+	}
+	if !filepath.IsAbs(conf.Conf.ThumbDir) {
+		absPath, err := filepath.Abs(conf.Conf.ThumbDir)
+		if err != nil {
+			log.Fatalf("get abs path error: %+v", err)
+		}
+		conf.Conf.ThumbDir = absPath
+	}
+	err = os.MkdirAll(conf.Conf.ThumbDir, 0700)
+	if err != nil {
+		log.Fatalf("create thumb dir error: %+v", err)
+	}
 	log.Debugf("config: %+v", conf.Conf)
 }
 
 func confFromEnv() {
+	if err := loadEnv(conf.Conf); err != nil {
+		log.Fatalf("load config from env error: %+v", err)
+	}
+}
+
+func loadEnv(c *conf.Config) error {
 	prefix := "ALIST_"
 	if flags.NoPrefix {
 		prefix = ""
 	}
 	log.Infof("load config from env with prefix: %s", prefix)
-	if err := env.Parse(conf.Conf, env.Options{
+	return env.Parse(c, env.Options{
 		Prefix: prefix,
-	}); err != nil {
-		log.Fatalf("load config from env error: %+v", err)
+	})
+}
+
+// the fields of the config applied by reloading, the others need restarting
+var reloadableConfig = map[string]bool{
+	"force":            true,
+	"jwt_secret":       true,
+	"cdn":              true,
+	"shutdown_timeout": true,
+}
+
+// ReloadConfig read the config file again and apply the fields which can be changed without restarting,
+// the names of the other fields changed are returned
+func ReloadConfig() ([]string, error) {
+	data, err := ioutil.ReadFile(flags.Config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed read config file")
+	}
+	c := conf.DefaultConfig()
+	if err = utils.Json.Unmarshal(data, c); err != nil {
+		return nil, errors.Wrap(err, "invalid config file")
+	}
+	if !c.Force {
+		if err = loadEnv(c); err != nil {
+			return nil, errors.Wrap(err, "failed load config from env")
+		}
+	}
+	for _, dir := range []*string{&c.TempDir, &c.UploadDir, &c.ThumbDir} {
+		if abs, err := filepath.Abs(*dir); err == nil {
+			*dir = abs
+		}
+	}
+	applied := *conf.Conf
+	var restart []string
+	cur, next := reflect.ValueOf(&applied).Elem(), reflect.ValueOf(c).Elem()
+	for i := 0; i < cur.NumField(); i++ {
+		name := strings.Split(cur.Type().Field(i).Tag.Get("json"), ",")[0]
+		if reloadableConfig[name] {
+			cur.Field(i).Set(next.Field(i))
+		} else if !reflect.DeepEqual(cur.Field(i).Interface(), next.Field(i).Interface()) {
+			restart = append(restart, name)
+		}
 	}
+	conf.Conf = &applied
+	return restart, nil
 }
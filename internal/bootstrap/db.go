@@ -18,7 +18,26 @@ import (
 	"gorm.io/gorm/schema"
 )
 
+// InitDB open the database and apply the migrations if auto_migrate is enabled
 func InitDB() {
+	OpenDB()
+	if conf.Conf.Database.AutoMigrate || flags.Dev {
+		if err := db.Migrate(); err != nil {
+			log.Fatalf("failed migrate database: %+v", err)
+		}
+		return
+	}
+	pending, err := db.GetPendingMigrations()
+	if err != nil {
+		log.Fatalf("failed get migrations: %+v", err)
+	}
+	if len(pending) > 0 {
+		log.Fatalf("the migrations %v are not applied, run `alist migrate` first", pending)
+	}
+}
+
+// OpenDB open the database without applying the migrations
+func OpenDB() {
 	newLogger := logger.New(
 		stdlog.New(log.StandardLogger().Out, "\r\n", stdlog.LstdFlags),
 		logger.Config{
@@ -50,14 +69,20 @@ func InitDB() {
 			}
 		case "mysql":
 			{
-				dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local&tls=%s",
-					database.User, database.Password, database.Host, database.Port, database.Name, database.SSLMode)
+				dsn := database.DSN
+				if dsn == "" {
+					dsn = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local&tls=%s",
+						database.User, database.Password, database.Host, database.Port, database.Name, database.SSLMode)
+				}
 				dB, err = gorm.Open(mysql.Open(dsn), gormConfig)
 			}
 		case "postgres":
 			{
-				dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=Asia/Shanghai",
-					database.Host, database.User, database.Password, database.Name, database.Port, database.SSLMode)
+				dsn := database.DSN
+				if dsn == "" {
+					dsn = fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=Asia/Shanghai",
+						database.Host, database.User, database.Password, database.Name, database.Port, database.SSLMode)
+				}
 				dB, err = gorm.Open(postgres.Open(dsn), gormConfig)
 			}
 		default:
@@ -67,5 +92,23 @@ func InitDB() {
 	if err != nil {
 		log.Fatalf("failed to connect database:%s", err.Error())
 	}
-	db.Init(dB)
+	setPool(dB)
+	db.SetDB(dB)
+}
+
+func setPool(dB *gorm.DB) {
+	sqlDB, err := dB.DB()
+	if err != nil {
+		log.Fatalf("failed get the pool of database: %+v", err)
+	}
+	database := conf.Conf.Database
+	if database.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(database.MaxOpenConns)
+	}
+	if database.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(database.MaxIdleConns)
+	}
+	if database.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(time.Duration(database.ConnMaxLifetime) * time.Second)
+	}
 }
@@ -0,0 +1,58 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/event"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/task"
+	log "github.com/sirupsen/logrus"
+)
+
+// InitEvent publish the events of the uploads, the tasks and the storages, and send them to the webhooks
+func InitEvent() {
+	if err := event.LoadWebhooks(); err != nil {
+		log.Errorf("failed load webhooks: %+v", err)
+	}
+	event.Subscribe(event.Notify)
+	op.RegisterEventHook(func(e op.Event) {
+		if e.Type == op.EventPut {
+			event.Publish(event.Event{Type: event.FileUploaded, Path: e.Path, Message: "the file is uploaded"})
+		}
+	})
+	task.OnFinished = func(name, state, errMsg string) {
+		e := event.Event{
+			Type:    event.TaskFinished,
+			Message: fmt.Sprintf("the task [%s] is %s", name, state),
+			Data:    map[string]string{"name": name, "state": state},
+		}
+		if errMsg != "" {
+			e.Message += ": " + errMsg
+			e.Data["error"] = errMsg
+		}
+		event.Publish(e)
+	}
+	model.OnStatusChanged = func(s *model.Storage, old string) {
+		if s.Status == op.WORK {
+			return
+		}
+		event.Publish(event.Event{
+			Type:    event.StorageOffline,
+			Path:    s.MountPath,
+			Message: fmt.Sprintf("the storage [%s] is offline: %s", s.MountPath, s.Status),
+			Data:    map[string]string{"driver": s.Driver, "status": s.Status},
+		})
+	}
+	op.OnTokenExpired = func(s *model.Storage, err error) {
+		event.Publish(event.Event{
+			Type:    event.TokenExpired,
+			Path:    s.MountPath,
+			Message: fmt.Sprintf("the token of the storage [%s] is expired, a new refresh token is required: %s", s.MountPath, err),
+			Data:    map[string]string{"id": strconv.Itoa(int(s.ID)), "driver": s.Driver, "error": err.Error()},
+		})
+	}
+	go event.Run(context.Background())
+}
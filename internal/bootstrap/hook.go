@@ -0,0 +1,12 @@
+package bootstrap
+
+import (
+	"github.com/alist-org/alist/v3/internal/hook"
+	log "github.com/sirupsen/logrus"
+)
+
+func LoadHooks() {
+	if err := hook.LoadHooks(); err != nil {
+		log.Errorf("failed load hooks: %+v", err)
+	}
+}
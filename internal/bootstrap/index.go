@@ -0,0 +1,20 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/search"
+	"github.com/alist-org/alist/v3/internal/setting"
+)
+
+// InitIndex keep the search index and the list caches updated with the changes of the objects
+func InitIndex() {
+	op.RegisterEventHook(search.OnEvent)
+	go search.Run(context.Background())
+	go op.PollChanges(context.Background(), func() time.Duration {
+		return time.Duration(setting.GetInt(conf.ChangePollInterval, 0)) * time.Second
+	})
+}
@@ -0,0 +1,17 @@
+package bootstrap
+
+import (
+	"github.com/alist-org/alist/v3/internal/op"
+	log "github.com/sirupsen/logrus"
+)
+
+// InitSecrets encrypt the secrets in the database with the master key in the config,
+// the ones saved in plain or encrypted by the jwt secret before are upgraded
+func InitSecrets() {
+	if err := op.RewrapDataKeys(); err != nil {
+		log.Errorf("failed rewrap data keys: %+v", err)
+	}
+	if err := op.EncryptStorageSecrets(); err != nil {
+		log.Errorf("failed encrypt the secrets of storages: %+v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package bootstrap
+
+import (
+	_ "github.com/alist-org/alist/v3/internal/offline_download"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+func InitOfflineDownloadTools() {
+	for _, t := range tool.All() {
+		t := t
+		go func() {
+			_, err := t.Init()
+			if err != nil {
+				utils.Log.Errorf("failed to init %s: %+v", t.Name(), err)
+			}
+		}()
+	}
+}
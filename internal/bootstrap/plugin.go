@@ -0,0 +1,14 @@
+package bootstrap
+
+import (
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/plugin"
+)
+
+// LoadPlugins start the plugins and register their drivers, it's called before the storages are loaded
+func LoadPlugins() {
+	if conf.Conf.PluginDir == "" {
+		return
+	}
+	plugin.Load(conf.Conf.PluginDir)
+}
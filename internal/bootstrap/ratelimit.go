@@ -0,0 +1,13 @@
+package bootstrap
+
+import (
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+func LoadRateLimits() {
+	if err := ratelimit.Load(); err != nil {
+		utils.Log.Fatalf("failed load rate limits: %+v", err)
+	}
+	goWorker(ratelimit.SaveUsages)
+}
@@ -0,0 +1,32 @@
+package bootstrap
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/hook"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type ReloadResult struct {
+	// the fields of the config changed but not applied until restarting
+	RestartRequired []string         `json:"restart_required"`
+	Storages        *op.ReloadResult `json:"storages"`
+}
+
+// Reload apply the changes of the config file, and the storages changed in the database
+// are initialized again, so they take effect without restarting
+func Reload(ctx context.Context) (*ReloadResult, error) {
+	restart, err := ReloadConfig()
+	if err != nil {
+		return nil, err
+	}
+	storages, err := op.ReloadStorages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// the hooks may be changed in the database too
+	if err = hook.LoadHooks(); err != nil {
+		return nil, err
+	}
+	return &ReloadResult{RestartRequired: restart, Storages: storages}, nil
+}
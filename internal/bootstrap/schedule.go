@@ -0,0 +1,12 @@
+package bootstrap
+
+import (
+	"github.com/alist-org/alist/v3/internal/schedule"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+func StartScheduler() {
+	if err := schedule.Start(); err != nil {
+		utils.Log.Fatalf("failed start scheduler: %+v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package bootstrap
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/archive"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/fssync"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/plugin"
+	"github.com/alist-org/alist/v3/internal/schedule"
+	"github.com/alist-org/alist/v3/pkg/task"
+	log "github.com/sirupsen/logrus"
+)
+
+var taskManagers = []struct {
+	typ string
+	tm  *task.Manager[uint64]
+}{
+	{"upload", fs.UploadTaskManager},
+	{"copy", fs.CopyTaskManager},
+	{"move", fs.MoveTaskManager},
+	{"job", schedule.JobTaskManager},
+	{"extract", archive.ExtractTaskManager},
+	{"compress", archive.CompressTaskManager},
+	{"download", tool.DownTaskManager},
+	{"post", tool.PostTaskManager},
+	{"transfer", tool.TransferTaskManager},
+	{"sync", fssync.SyncTaskManager},
+}
+
+// DrainTasks wait for the running tasks until ctx is done, and save the interrupted ones,
+// the pending tasks aren't started any more
+func DrainTasks(ctx context.Context) {
+	var (
+		mu          sync.Mutex
+		interrupted []model.InterruptedTask
+		wg          sync.WaitGroup
+	)
+	for _, m := range taskManagers {
+		wg.Add(1)
+		go func(typ string, tm *task.Manager[uint64]) {
+			defer wg.Done()
+			tasks := tm.Shutdown(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			for _, t := range tasks {
+				interrupted = append(interrupted, model.InterruptedTask{
+					Type:     typ,
+					TaskID:   strconv.FormatUint(t.ID, 10),
+					Name:     t.Name,
+					State:    t.GetState(),
+					Status:   t.GetStatus(),
+					Progress: t.GetProgress(),
+					Error:    t.GetErrMsg(),
+				})
+			}
+		}(m.typ, m.tm)
+	}
+	wg.Wait()
+	if len(interrupted) == 0 {
+		return
+	}
+	log.Warnf("%d tasks are interrupted by shutdown", len(interrupted))
+	if err := db.CreateInterruptedTasks(interrupted); err != nil {
+		log.Errorf("failed save interrupted tasks: %+v", err)
+	}
+}
+
+// the workers in background flushing the data on shutdown, such as the audit logs and the traffic usages
+var (
+	workerCtx, stopWorkers = context.WithCancel(context.Background())
+	workers                sync.WaitGroup
+)
+
+// goWorker run the worker in background, its ctx is done on shutdown and it's waited to return
+func goWorker(f func(ctx context.Context)) {
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		f(workerCtx)
+	}()
+}
+
+// Shutdown stop the workers, the storages and the plugins after the requests and the tasks are done
+func Shutdown(ctx context.Context) {
+	stopWorkers()
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Warnf("timeout waiting for the workers to stop")
+	}
+	op.DropAllStorages(ctx)
+	plugin.StopAll()
+}
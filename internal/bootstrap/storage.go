@@ -2,11 +2,13 @@ package bootstrap
 
 import (
 	"context"
+	"time"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/pkg/utils"
 )
 
@@ -27,4 +29,7 @@ func LoadStorages() {
 		}
 		conf.StoragesLoaded = true
 	}(storages)
+	go op.RunHealthCheck(context.Background(), func() time.Duration {
+		return time.Duration(setting.GetInt(conf.StorageHealthInterval, 0)) * time.Second
+	})
 }
@@ -0,0 +1,137 @@
+// Package checksum build the manifest of the hashes of the files in a dir,
+// and verify the files with it later, such as after migrating to another storage
+package checksum
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"hash"
+	"io"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// the hash types that can be computed by reading the content
+var hashers = map[string]func() hash.Hash{
+	"sha1": sha1.New,
+	"md5":  md5.New,
+}
+
+type Entry struct {
+	// the path relative to the dir of the manifest
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	HashType string `json:"hash_type"`
+	Hash     string `json:"hash"`
+	// the hash is computed by reading the content, not given by the provider
+	Computed bool `json:"computed"`
+}
+
+type Manifest struct {
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+	Entries   []Entry   `json:"entries"`
+}
+
+type Args struct {
+	Path string `json:"path" binding:"required"`
+	// the hash type of the entries, the hash given by the provider is used if it's the type,
+	// or it's computed by reading the content. If empty, the hash given by the provider
+	// of any type is used, and the sha1 is computed for the files without.
+	HashType string `json:"hash_type"`
+}
+
+// Build walk the dir and get the hashes of the files,
+// the user in ctx must be able to see all the objects
+func Build(ctx context.Context, args Args) (*Manifest, error) {
+	if args.HashType != "" && hashers[args.HashType] == nil {
+		return nil, errors.Errorf("unsupported hash type [%s]", args.HashType)
+	}
+	ctx = context.WithValue(ctx, "meta", (*model.Meta)(nil))
+	manifest := &Manifest{
+		Path:      args.Path,
+		CreatedAt: time.Now(),
+		Entries:   []Entry{},
+	}
+	err := walk(ctx, args.Path, "/", func(rel string, obj model.Obj) error {
+		hashType, h, computed, err := getHash(ctx, stdpath.Join(args.Path, rel), obj, args.HashType)
+		if err != nil {
+			return err
+		}
+		manifest.Entries = append(manifest.Entries, Entry{
+			Path:     rel,
+			Size:     obj.GetSize(),
+			HashType: hashType,
+			Hash:     h,
+			Computed: computed,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// walk call fn with the files in the dir recursively
+func walk(ctx context.Context, root, rel string, fn func(rel string, obj model.Obj) error) error {
+	objs, err := fs.List(ctx, stdpath.Join(root, rel), true)
+	if err != nil {
+		return errors.WithMessagef(err, "failed list [%s]", rel)
+	}
+	for _, obj := range objs {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		p := stdpath.Join(rel, obj.GetName())
+		if obj.IsDir() {
+			err = walk(ctx, root, p, fn)
+		} else {
+			err = fn(p, obj)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getHash get the hash of the type given by the provider, or compute it.
+// If hashType is empty, any hash given by the provider is ok, or sha1 is computed.
+func getHash(ctx context.Context, path string, obj model.Obj, hashType string) (string, string, bool, error) {
+	if h := obj.GetHash(); !h.Empty() && (hashType == "" || hashType == h.Type) {
+		return h.Type, h.Value, false, nil
+	}
+	if hashType == "" {
+		hashType = "sha1"
+	}
+	h, err := compute(ctx, path, hashType)
+	if err != nil {
+		return "", "", false, err
+	}
+	return hashType, h, true, nil
+}
+
+func compute(ctx context.Context, path string, hashType string) (string, error) {
+	newHash, ok := hashers[hashType]
+	if !ok {
+		return "", errors.Errorf("can't compute the hash of type [%s]", hashType)
+	}
+	stream, err := fs.Open(ctx, path)
+	if err != nil {
+		return "", errors.WithMessagef(err, "failed open [%s]", path)
+	}
+	defer stream.Close()
+	h := newHash()
+	if _, err = io.Copy(h, stream); err != nil {
+		return "", errors.Wrapf(err, "failed read [%s]", path)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
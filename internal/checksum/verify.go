@@ -0,0 +1,95 @@
+package checksum
+
+import (
+	"context"
+	stdpath "path"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+type Mismatch struct {
+	Path     string `json:"path"`
+	Reason   string `json:"reason"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+type Report struct {
+	Path    string `json:"path"`
+	Checked int    `json:"checked"`
+	Passed  int    `json:"passed"`
+	// the files that are different from the manifest
+	Mismatched []Mismatch `json:"mismatched"`
+	// the files in the manifest but not in the dir
+	Missing []string `json:"missing"`
+	// the files in the dir but not in the manifest
+	Extra []string `json:"extra"`
+}
+
+// Verify check the files in the dir with the manifest, the dir is the path of the manifest
+// if path is empty, or another one such as where the files are migrated to
+func Verify(ctx context.Context, manifest *Manifest, path string) (*Report, error) {
+	if path == "" {
+		path = manifest.Path
+	}
+	ctx = context.WithValue(ctx, "meta", (*model.Meta)(nil))
+	entries := make(map[string]Entry, len(manifest.Entries))
+	for _, entry := range manifest.Entries {
+		entries[entry.Path] = entry
+	}
+	report := &Report{
+		Path:       path,
+		Mismatched: []Mismatch{},
+		Missing:    []string{},
+		Extra:      []string{},
+	}
+	err := walk(ctx, path, "/", func(rel string, obj model.Obj) error {
+		entry, ok := entries[rel]
+		if !ok {
+			report.Extra = append(report.Extra, rel)
+			return nil
+		}
+		delete(entries, rel)
+		report.Checked++
+		if mismatch := check(ctx, stdpath.Join(path, rel), obj, entry); mismatch != nil {
+			report.Mismatched = append(report.Mismatched, *mismatch)
+			return nil
+		}
+		report.Passed++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// keep the order of the manifest
+	for _, entry := range manifest.Entries {
+		if _, ok := entries[entry.Path]; ok {
+			report.Missing = append(report.Missing, entry.Path)
+		}
+	}
+	return report, nil
+}
+
+// check the file with the entry, return nil if it's the same
+func check(ctx context.Context, path string, obj model.Obj, entry Entry) *Mismatch {
+	if obj.GetSize() != entry.Size {
+		return &Mismatch{
+			Path:     entry.Path,
+			Reason:   "size changed",
+			Expected: strconv.FormatInt(entry.Size, 10),
+			Actual:   strconv.FormatInt(obj.GetSize(), 10),
+		}
+	}
+	if entry.Hash == "" {
+		return nil
+	}
+	_, h, _, err := getHash(ctx, path, obj, entry.HashType)
+	if err != nil {
+		return &Mismatch{Path: entry.Path, Reason: err.Error(), Expected: entry.Hash}
+	}
+	if h != entry.Hash {
+		return &Mismatch{Path: entry.Path, Reason: entry.HashType + " changed", Expected: entry.Hash, Actual: h}
+	}
+	return nil
+}
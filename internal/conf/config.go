@@ -14,6 +14,16 @@ type Database struct {
 	DBFile      string `json:"db_file" env:"DB_FILE"`
 	TablePrefix string `json:"table_prefix" env:"DB_TABLE_PREFIX"`
 	SSLMode     string `json:"ssl_mode" env:"DB_SSL_MODE"`
+	// the dsn of mysql or postgres, the fields of the connection above are ignored if it's set
+	DSN string `json:"dsn" env:"DB_DSN"`
+	// the pool of the connections, the defaults of database/sql are used if they're 0
+	MaxOpenConns int `json:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns int `json:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	// the seconds a connection is reused at most
+	ConnMaxLifetime int `json:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	// apply the migrations on startup, or they're applied by `alist migrate`
+	// and the server refuses to start with the pending ones
+	AutoMigrate bool `json:"auto_migrate" env:"DB_AUTO_MIGRATE"`
 }
 
 type Scheme struct {
@@ -31,31 +41,74 @@ type LogConfig struct {
 	Compress   bool   `json:"compress" env:"COMPRESS"`
 }
 
+type S3 struct {
+	Enable bool `json:"enable" env:"S3_ENABLE"`
+	Port   int  `json:"port" env:"S3_PORT"`
+	SSL    bool `json:"ssl" env:"S3_SSL"`
+}
+
+type SFTP struct {
+	Enable      bool   `json:"enable" env:"SFTP_ENABLE"`
+	Port        int    `json:"port" env:"SFTP_PORT"`
+	HostKeyFile string `json:"host_key_file" env:"SFTP_HOST_KEY_FILE"`
+}
+
+type FTP struct {
+	Enable bool `json:"enable" env:"FTP_ENABLE"`
+	Port   int  `json:"port" env:"FTP_PORT"`
+	// the ip replied for PASV, the ip of the control connection is used if empty
+	PublicHost    string `json:"public_host" env:"FTP_PUBLIC_HOST"`
+	PasvPortStart int    `json:"pasv_port_start" env:"FTP_PASV_PORT_START"`
+	PasvPortEnd   int    `json:"pasv_port_end" env:"FTP_PASV_PORT_END"`
+	// allow the clients upgrade to FTPS with AUTH TLS, the cert of scheme is used
+	TLS bool `json:"tls" env:"FTP_TLS"`
+}
+
 type Config struct {
 	Force     bool   `json:"force"`
 	Address   string `json:"address" env:"ADDR"`
 	Port      int    `json:"port" env:"PORT"`
 	JwtSecret string `json:"jwt_secret" env:"JWT_SECRET"`
+	// the key encrypting the secrets saved in the database, such as the data keys and the tokens,
+	// they can't be decrypted if it's lost, the jwt secret is used if it's empty
+	MasterKey string `json:"master_key" env:"MASTER_KEY"`
 	// CaCheExpiration int       `json:"cache_expiration" env:"CACHE_EXPIRATION"`
-	Cdn      string    `json:"cdn" env:"CDN"`
-	Database Database  `json:"database"`
-	Scheme   Scheme    `json:"scheme"`
-	TempDir  string    `json:"temp_dir" env:"TEMP_DIR"`
-	Log      LogConfig `json:"log"`
+	Cdn      string   `json:"cdn" env:"CDN"`
+	Database Database `json:"database"`
+	Scheme   Scheme   `json:"scheme"`
+	TempDir  string   `json:"temp_dir" env:"TEMP_DIR"`
+	// the dir to keep the chunks of resumable uploads, it's not cleared on startup like temp dir
+	UploadDir string `json:"upload_dir" env:"UPLOAD_DIR"`
+	// the dir of the generated thumbnails, they're kept after restarting
+	ThumbDir string `json:"thumb_dir" env:"THUMB_DIR"`
+	// the dir of the executables serving the drivers out of the tree, they're started on startup
+	PluginDir string    `json:"plugin_dir" env:"PLUGIN_DIR"`
+	Log       LogConfig `json:"log"`
+	S3        S3        `json:"s3"`
+	SFTP      SFTP      `json:"sftp"`
+	FTP       FTP       `json:"ftp"`
+	// the seconds to wait for the requests and the tasks in flight on shutdown,
+	// the tasks still running are canceled then
+	ShutdownTimeout int `json:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
 }
 
 func DefaultConfig() *Config {
 	return &Config{
 		Address:   "0.0.0.0",
 		Port:      5244,
-		JwtSecret: random.String(16),
+		JwtSecret: random.SecureString(16),
+		MasterKey: random.SecureString(32),
 		Cdn:       "",
 		TempDir:   "data/temp",
+		UploadDir: "data/upload",
+		ThumbDir:  "data/thumb",
+		PluginDir: "data/plugins",
 		Database: Database{
 			Type:        "sqlite3",
 			Port:        0,
 			TablePrefix: "x_",
 			DBFile:      "data/data.db",
+			AutoMigrate: true,
 		},
 		// CaCheExpiration: 30,
 		Log: LogConfig{
@@ -65,5 +118,21 @@ func DefaultConfig() *Config {
 			MaxBackups: 5,
 			MaxAge:     28,
 		},
+		S3: S3{
+			Enable: false,
+			Port:   5246,
+			SSL:    false,
+		},
+		SFTP: SFTP{
+			Enable:      false,
+			Port:        5222,
+			HostKeyFile: "data/ssh_host_key",
+		},
+		FTP: FTP{
+			Enable: false,
+			Port:   5221,
+			TLS:    false,
+		},
+		ShutdownTimeout: 30,
 	}
 }
@@ -31,6 +31,18 @@ const (
 	PdfViewers    = "pdf_viewers"
 	AudioAutoplay = "audio_autoplay"
 	VideoAutoplay = "video_autoplay"
+	// the thumbnails generated for the files without the ones of the provider
+	ThumbnailEnabled      = "thumbnail_enabled"
+	ThumbnailSize         = "thumbnail_size"
+	ThumbnailCacheSize    = "thumbnail_cache_size"
+	ThumbnailMaxImageSize = "thumbnail_max_image_size"
+	FfmpegPath            = "ffmpeg_path"
+	// the videos transcoded to hls by ffmpeg for the browsers can't play them
+	TranscodeEnabled     = "transcode_enabled"
+	TranscodePresets     = "transcode_presets"
+	TranscodeHwaccel     = "transcode_hwaccel"
+	TranscodeMaxSessions = "transcode_max_sessions"
+	TranscodeIdleTimeout = "transcode_idle_timeout"
 
 	// global
 	HideFiles      = "hide_files"
@@ -40,13 +52,71 @@ const (
 	LinkExpiration = "link_expiration"
 	PrivacyRegs    = "privacy_regs"
 	OcrApi         = "ocr_api"
+	// the signs of the links are bound to the ip of the client
+	LinkBindIP = "link_bind_ip"
+	// the links of the mounts in it are signed, one in a line, the others are signed only with the password
+	SignPaths = "sign_paths"
+	// the users with 2FA must use the app passwords for webdav, ftp and sftp
+	AppPasswordRequired = "app_password_required"
+	AdminRequire2FA     = "admin_require_2fa"
+	// the content of the proxied files is cached on the disk
+	ContentCacheSize        = "content_cache_size"
+	ContentCacheMaxFileSize = "content_cache_max_file_size"
+	ContentCachePrefixSize  = "content_cache_prefix_size"
+	// the metrics in the prometheus format at /metrics
+	MetricsEnabled = "metrics_enabled"
+	MetricsToken   = "metrics_token"
+	// the operations on the files are recorded in the audit log
+	AuditEnabled       = "audit_enabled"
+	AuditRetentionDays = "audit_retention_days"
+	AccessLogEnabled   = "access_log_enabled"
+	// the day of the month the monthly traffic quotas are reset
+	TrafficQuotaResetDay = "traffic_quota_reset_day"
+	// the seconds between the health probes of the storages
+	StorageHealthInterval = "storage_health_interval"
+	// the requests of the ips are throttled, and the ips are banned for a while if they exceed the limits
+	ThrottleLoginAttempts = "throttle_login_attempts"
+	ThrottleApiRate       = "throttle_api_rate"
+	ThrottleLinkRate      = "throttle_link_rate"
+	ThrottleBanDuration   = "throttle_ban_duration"
+	ThrottleWhitelist     = "throttle_whitelist"
 
 	// aria2
 	Aria2Uri    = "aria2_uri"
 	Aria2Secret = "aria2_secret"
 
+	// qbittorrent
+	QbittorrentUrl = "qbittorrent_url"
+
+	// offline download
+	OfflineDownloadPathPattern  = "offline_download_path_pattern"
+	OfflineDownloadMinFileSize  = "offline_download_min_file_size"
+	OfflineDownloadDeleteSource = "offline_download_delete_source"
+	OfflineDownloadWebhook      = "offline_download_webhook"
+
 	// single
 	Token = "token"
+
+	// index
+	SearchIndex          = "search_index"
+	SearchIndexContent   = "search_index_content"
+	SearchContentMaxSize = "search_content_max_size"
+	IgnorePaths          = "ignore_paths"
+	MaxIndexDepth        = "max_index_depth"
+	IndexUpdateInterval  = "index_update_interval"
+	ChangePollInterval   = "change_poll_interval"
+	MeilisearchUrl       = "meilisearch_url"
+	MeilisearchKey       = "meilisearch_key"
+	ElasticsearchUrl     = "elasticsearch_url"
+
+	// sso
+	SSOLoginEnabled  = "sso_login_enabled"
+	SSOIssuer        = "sso_issuer"
+	SSOClientId      = "sso_client_id"
+	SSOClientSecret  = "sso_client_secret"
+	SSOUsernameClaim = "sso_username_claim"
+	SSOAutoRegister  = "sso_auto_register"
+	SSODefaultGroup  = "sso_default_group"
 )
 
 const (
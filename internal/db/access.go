@@ -0,0 +1,42 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateAccessLogs(logs []model.AccessLog) error {
+	return errors.WithStack(db.CreateInBatches(logs, 100).Error)
+}
+
+// GetAccessStats group the access logs by the `link` created in [start, end) by the column, such as
+// path, ip or day, all the links if it's empty. the groups are sorted by the bytes if `limit` > 0
+// and only the top ones are returned, otherwise all the groups are returned in the order of the column
+func GetAccessStats(column, link string, start, end time.Time, limit int) ([]model.AccessStat, error) {
+	q := db.Model(&model.AccessLog{}).
+		Select(column+" AS name, COUNT(*) AS requests, "+
+			"SUM(CASE WHEN partial THEN 0 ELSE 1 END) AS downloads, SUM(bytes) AS bytes").
+		Where("created_at >= ? AND created_at < ?", start, end)
+	if link != "" {
+		q = q.Where("link = ?", link)
+	}
+	q = q.Group(column)
+	if limit > 0 {
+		q = q.Order("bytes desc").Order("downloads desc").Limit(limit)
+	} else {
+		q = q.Order(column)
+	}
+	var stats []model.AccessStat
+	if err := q.Scan(&stats).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get access stats by %s", column)
+	}
+	return stats, nil
+}
+
+// DeleteAccessLogsBefore delete the access logs created before `t`, the count of the deleted is returned
+func DeleteAccessLogsBefore(t time.Time) (int64, error) {
+	res := db.Where("created_at < ?", t).Delete(&model.AccessLog{})
+	return res.RowsAffected, errors.WithStack(res.Error)
+}
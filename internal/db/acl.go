@@ -0,0 +1,92 @@
+package db
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var aclCache = cache.NewMemCache(cache.WithShards[[]model.AclRule](2))
+
+func aclCacheKey(userId uint) string {
+	return strconv.FormatUint(uint64(userId), 10)
+}
+
+// GetAclRulesByUserId get the rules of the user, they are cached since checked by each operation
+func GetAclRulesByUserId(userId uint) ([]model.AclRule, error) {
+	if rules, ok := aclCache.Get(aclCacheKey(userId)); ok {
+		return rules, nil
+	}
+	var rules []model.AclRule
+	if err := db.Where("user_id = ?", userId).Find(&rules).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get acl rules")
+	}
+	aclCache.Set(aclCacheKey(userId), rules, cache.WithEx[[]model.AclRule](time.Hour))
+	return rules, nil
+}
+
+func GetAclRuleById(id uint) (*model.AclRule, error) {
+	var r model.AclRule
+	if err := db.First(&r, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get acl rule")
+	}
+	return &r, nil
+}
+
+func CreateAclRule(r *model.AclRule) error {
+	aclCache.Del(aclCacheKey(r.UserID))
+	return errors.WithStack(db.Create(r).Error)
+}
+
+func UpdateAclRule(r *model.AclRule) error {
+	old, err := GetAclRuleById(r.ID)
+	if err != nil {
+		return err
+	}
+	aclCache.Del(aclCacheKey(old.UserID))
+	aclCache.Del(aclCacheKey(r.UserID))
+	return errors.WithStack(db.Save(r).Error)
+}
+
+func DeleteAclRuleById(id uint) error {
+	old, err := GetAclRuleById(id)
+	if err != nil {
+		return err
+	}
+	aclCache.Del(aclCacheKey(old.UserID))
+	return errors.WithStack(db.Delete(&model.AclRule{}, id).Error)
+}
+
+func deleteAclRulesByUserId(userId uint) error {
+	aclCache.Del(aclCacheKey(userId))
+	return errors.WithStack(db.Where("user_id = ?", userId).Delete(&model.AclRule{}).Error)
+}
+
+func GetAllAclRules() ([]model.AclRule, error) {
+	var rules []model.AclRule
+	if err := db.Find(&rules).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return rules, nil
+}
+
+// SetAclRules replace the rules of the user
+func SetAclRules(userId uint, rules []model.AclRule) error {
+	aclCache.Del(aclCacheKey(userId))
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&model.AclRule{}).Error; err != nil {
+			return err
+		}
+		for _, r := range rules {
+			r.ID, r.UserID = 0, userId
+			if err := tx.Create(&r).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
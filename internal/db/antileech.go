@@ -0,0 +1,34 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetAntiLeeches() ([]model.AntiLeech, error) {
+	var rules []model.AntiLeech
+	if err := db.Find(&rules).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get anti leech rules")
+	}
+	return rules, nil
+}
+
+func GetAntiLeechById(id uint) (*model.AntiLeech, error) {
+	var r model.AntiLeech
+	if err := db.First(&r, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get anti leech rule")
+	}
+	return &r, nil
+}
+
+func CreateAntiLeech(r *model.AntiLeech) error {
+	return errors.WithStack(db.Create(r).Error)
+}
+
+func UpdateAntiLeech(r *model.AntiLeech) error {
+	return errors.WithStack(db.Save(r).Error)
+}
+
+func DeleteAntiLeechById(id uint) error {
+	return errors.WithStack(db.Delete(&model.AntiLeech{}, id).Error)
+}
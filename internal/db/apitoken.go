@@ -0,0 +1,44 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+func GetApiTokensByUserId(userId uint) ([]model.ApiToken, error) {
+	var tokens []model.ApiToken
+	if err := db.Where("user_id = ?", userId).Find(&tokens).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get api tokens")
+	}
+	return tokens, nil
+}
+
+// GetApiTokenByToken get the api token by the sha256 of the token
+func GetApiTokenByToken(token string) (*model.ApiToken, error) {
+	var t model.ApiToken
+	if err := db.Where("hash = ?", utils.GetSHA256Encode(token)).First(&t).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get api token")
+	}
+	return &t, nil
+}
+
+func CreateApiToken(t *model.ApiToken) error {
+	return errors.WithStack(db.Create(t).Error)
+}
+
+// DeleteApiToken delete the api token of the user
+func DeleteApiToken(userId, id uint) error {
+	res := db.Where("user_id = ?", userId).Delete(&model.ApiToken{}, id)
+	if res.Error != nil {
+		return errors.WithStack(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("api token not found")
+	}
+	return nil
+}
+
+func deleteApiTokensByUserId(userId uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userId).Delete(&model.ApiToken{}).Error)
+}
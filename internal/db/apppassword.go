@@ -0,0 +1,74 @@
+package db
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+func GetAppPasswordsByUserId(userId uint) ([]model.AppPassword, error) {
+	var ps []model.AppPassword
+	if err := db.Where("user_id = ?", userId).Find(&ps).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get app passwords")
+	}
+	return ps, nil
+}
+
+func CreateAppPassword(p *model.AppPassword) error {
+	return errors.WithStack(db.Create(p).Error)
+}
+
+// DeleteAppPassword delete the app password of the user
+func DeleteAppPassword(userId, id uint) error {
+	res := db.Where("user_id = ?", userId).Delete(&model.AppPassword{}, id)
+	if res.Error != nil {
+		return errors.WithStack(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("app password not found")
+	}
+	return nil
+}
+
+// ValidateAppPassword check whether the password is one of the app passwords of the user
+func ValidateAppPassword(userId uint, password string) bool {
+	if password == "" {
+		return false
+	}
+	ps, err := GetAppPasswordsByUserId(userId)
+	if err != nil {
+		return false
+	}
+	hash := utils.GetSHA256Encode(password)
+	for _, p := range ps {
+		if subtle.ConstantTimeCompare([]byte(p.Hash), []byte(hash)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func deleteAppPasswordsByUserId(userId uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userId).Delete(&model.AppPassword{}).Error)
+}
+
+// UseRecoveryCode remove the recovery code of 2FA from the user if it's valid
+func UseRecoveryCode(userId uint, code string) (bool, error) {
+	user, err := GetUserById(userId)
+	if err != nil {
+		return false, err
+	}
+	hash := utils.GetSHA256Encode(strings.TrimSpace(code))
+	hashes := strings.Split(user.OtpRecoveryCodes, ",")
+	for i, h := range hashes {
+		if h == "" || subtle.ConstantTimeCompare([]byte(h), []byte(hash)) != 1 {
+			continue
+		}
+		user.OtpRecoveryCodes = strings.Join(append(hashes[:i], hashes[i+1:]...), ",")
+		return true, UpdateUser(user)
+	}
+	return false, nil
+}
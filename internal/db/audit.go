@@ -0,0 +1,73 @@
+package db
+
+import (
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateAuditLogs(logs []model.AuditLog) error {
+	return errors.WithStack(db.CreateInBatches(logs, 100).Error)
+}
+
+// GetAuditLogs get the audit logs matching the query, the latest first
+func GetAuditLogs(q model.AuditQuery, pageIndex, pageSize int) ([]model.AuditLog, int64, error) {
+	logDB := db.Model(&model.AuditLog{})
+	if q.Username != "" {
+		logDB = logDB.Where("username = ?", q.Username)
+	}
+	if q.IP != "" {
+		logDB = logDB.Where("ip = ?", q.IP)
+	}
+	if q.Op != "" {
+		logDB = logDB.Where("op = ?", q.Op)
+	}
+	if q.Path != "" && q.Path != "/" {
+		path := strings.TrimSuffix(q.Path, "/")
+		logDB = logDB.Where("path = ? OR path LIKE ? ESCAPE '!'", path, escapeLike(path)+"/%")
+	}
+	if q.Start != nil {
+		logDB = logDB.Where("created_at >= ?", *q.Start)
+	}
+	if q.End != nil {
+		logDB = logDB.Where("created_at < ?", *q.End)
+	}
+	var count int64
+	if err := logDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get audit logs count")
+	}
+	var logs []model.AuditLog
+	if err := logDB.Order("id desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&logs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find audit logs")
+	}
+	return logs, count, nil
+}
+
+// DeleteAuditLogsBefore delete the audit logs created before `t`, the count of the deleted is returned
+func DeleteAuditLogsBefore(t time.Time) (int64, error) {
+	res := db.Where("created_at < ?", t).Delete(&model.AuditLog{})
+	return res.RowsAffected, errors.WithStack(res.Error)
+}
+
+// GetUploaders get the users uploaded the files directly in the dir by the names,
+// the latest upload is taken if a file is uploaded again
+func GetUploaders(dir string) (map[string]string, error) {
+	dir = strings.TrimSuffix(dir, "/")
+	var logs []model.AuditLog
+	err := db.Select("path", "username").
+		Where("op = ? AND success = ? AND path LIKE ? ESCAPE '!'", model.AuditUpload, true, escapeLike(dir)+"/%").
+		Order("id").Find(&logs).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed get uploaders")
+	}
+	res := make(map[string]string)
+	for _, l := range logs {
+		name := strings.TrimPrefix(l.Path, dir+"/")
+		if !strings.Contains(name, "/") {
+			res[name] = l.Username
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetDataKey(scope string) (*model.DataKey, error) {
+	var k model.DataKey
+	if err := db.Where("scope = ?", scope).First(&k).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get data key")
+	}
+	return &k, nil
+}
+
+func GetDataKeys() ([]model.DataKey, error) {
+	var keys []model.DataKey
+	if err := db.Find(&keys).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get data keys")
+	}
+	return keys, nil
+}
+
+func CreateDataKey(k *model.DataKey) error {
+	return errors.WithStack(db.Create(k).Error)
+}
+
+func UpdateDataKey(k *model.DataKey) error {
+	return errors.WithStack(db.Save(k).Error)
+}
+
+func DeleteDataKey(scope string) error {
+	return errors.WithStack(db.Where("scope = ?", scope).Delete(&model.DataKey{}).Error)
+}
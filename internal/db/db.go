@@ -3,16 +3,20 @@ package db
 import (
 	"log"
 
-	"github.com/alist-org/alist/v3/internal/model"
 	"gorm.io/gorm"
 )
 
 var db gorm.DB
 
+// Init set the database and apply the migrations not applied
 func Init(d *gorm.DB) {
-	db = *d
-	err := db.AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem))
-	if err != nil {
-		log.Fatalf("failed migrate database: %s", err.Error())
+	SetDB(d)
+	if err := Migrate(); err != nil {
+		log.Fatalf("failed migrate database: %+v", err)
 	}
 }
+
+// SetDB set the database without applying the migrations
+func SetDB(d *gorm.DB) {
+	db = *d
+}
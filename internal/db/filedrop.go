@@ -0,0 +1,49 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetFileDrops(pageIndex, pageSize int) ([]model.FileDrop, int64, error) {
+	dropDB := db.Model(&model.FileDrop{})
+	var count int64
+	if err := dropDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get file drops count")
+	}
+	var drops []model.FileDrop
+	if err := dropDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&drops).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get find file drops")
+	}
+	return drops, count, nil
+}
+
+func GetFileDropById(id uint) (*model.FileDrop, error) {
+	var d model.FileDrop
+	if err := db.First(&d, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get file drop")
+	}
+	return &d, nil
+}
+
+func GetFileDropByKey(key string) (*model.FileDrop, error) {
+	var d model.FileDrop
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("key")), key).First(&d).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get file drop")
+	}
+	return &d, nil
+}
+
+func CreateFileDrop(d *model.FileDrop) error {
+	return errors.WithStack(db.Create(d).Error)
+}
+
+func UpdateFileDrop(d *model.FileDrop) error {
+	return errors.WithStack(db.Save(d).Error)
+}
+
+func DeleteFileDropById(id uint) error {
+	return errors.WithStack(db.Delete(&model.FileDrop{}, id).Error)
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetGroups() ([]model.Group, error) {
+	var groups []model.Group
+	if err := db.Find(&groups).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get groups")
+	}
+	return groups, nil
+}
+
+func GetGroupById(id uint) (*model.Group, error) {
+	var g model.Group
+	if err := db.First(&g, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get group")
+	}
+	return &g, nil
+}
+
+func GetGroupByName(name string) (*model.Group, error) {
+	var g model.Group
+	if err := db.Where("name = ?", name).First(&g).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get group %s", name)
+	}
+	return &g, nil
+}
+
+func CreateGroup(g *model.Group) error {
+	return errors.WithStack(db.Create(g).Error)
+}
+
+// the effective users in the cache are built with the groups, so clear them after the groups changed
+
+func UpdateGroup(g *model.Group) error {
+	if _, err := GetGroupById(g.ID); err != nil {
+		return err
+	}
+	userCache.Clear()
+	return errors.WithStack(db.Save(g).Error)
+}
+
+func DeleteGroupById(id uint) error {
+	userCache.Clear()
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", id).Delete(&model.UserGroup{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Group{}, id).Error
+	}))
+}
+
+// GetGroupsByUserId get the groups of the user, ordered by id
+func GetGroupsByUserId(userId uint) ([]model.Group, error) {
+	var ids []uint
+	if err := db.Model(&model.UserGroup{}).Where("user_id = ?", userId).Pluck("group_id", &ids).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get group ids of user")
+	}
+	groups := make([]model.Group, 0, len(ids))
+	if len(ids) == 0 {
+		return groups, nil
+	}
+	if err := db.Where("id in ?", ids).Order("id").Find(&groups).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get groups of user")
+	}
+	return groups, nil
+}
+
+// GetUserIdsByGroupId get the ids of the users in the group
+func GetUserIdsByGroupId(groupId uint) ([]uint, error) {
+	var ids []uint
+	if err := db.Model(&model.UserGroup{}).Where("group_id = ?", groupId).Pluck("user_id", &ids).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get users of group")
+	}
+	return ids, nil
+}
+
+// SetUserGroups replace the groups of the user, the admin and the guest can't join groups,
+// since they are got without the permissions of the groups by GetAdmin and GetGuest
+func SetUserGroups(userId uint, groupIds []uint) error {
+	if len(groupIds) > 0 {
+		user, err := GetUserById(userId)
+		if err != nil {
+			return err
+		}
+		if user.IsAdmin() || user.IsGuest() {
+			return errors.WithStack(errs.GroupAdminOrGuest)
+		}
+	}
+	userCache.Clear()
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&model.UserGroup{}).Error; err != nil {
+			return err
+		}
+		for _, id := range groupIds {
+			if err := tx.Create(&model.UserGroup{UserID: userId, GroupID: id}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func GetAllUserGroups() ([]model.UserGroup, error) {
+	var ugs []model.UserGroup
+	if err := db.Find(&ugs).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return ugs, nil
+}
+
+func deleteUserGroupsByUserId(userId uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userId).Delete(&model.UserGroup{}).Error)
+}
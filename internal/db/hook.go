@@ -0,0 +1,34 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetHooks() ([]model.Hook, error) {
+	var hooks []model.Hook
+	if err := db.Order(columnName("order")).Find(&hooks).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get hooks")
+	}
+	return hooks, nil
+}
+
+func GetHookById(id uint) (*model.Hook, error) {
+	var h model.Hook
+	if err := db.First(&h, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get hook")
+	}
+	return &h, nil
+}
+
+func CreateHook(h *model.Hook) error {
+	return errors.WithStack(db.Create(h).Error)
+}
+
+func UpdateHook(h *model.Hook) error {
+	return errors.WithStack(db.Save(h).Error)
+}
+
+func DeleteHookById(id uint) error {
+	return errors.WithStack(db.Delete(&model.Hook{}, id).Error)
+}
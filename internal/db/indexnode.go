@@ -0,0 +1,92 @@
+package db
+
+import (
+	"fmt"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// escapeLike escape the wildcards of like, `!` is used as the escape char
+// since the backslash in the literal is treated differently by the databases
+func escapeLike(s string) string {
+	return strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`).Replace(s)
+}
+
+// BatchCreateIndexNodes add the nodes to the index, the old nodes of the same paths are replaced
+func BatchCreateIndexNodes(nodes []model.IndexNode) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	tx := db.Begin()
+	for _, node := range nodes {
+		err := tx.Where("parent = ? AND name = ?", node.Parent, node.Name).Delete(&model.IndexNode{}).Error
+		if err != nil {
+			tx.Rollback()
+			return errors.WithStack(err)
+		}
+	}
+	if err := tx.CreateInBatches(nodes, 100).Error; err != nil {
+		tx.Rollback()
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(tx.Commit().Error)
+}
+
+// DeleteIndexNodes delete the node of the path and the nodes under it
+func DeleteIndexNodes(path string) error {
+	dir, name := stdpath.Split(path)
+	q := db.Where("parent = ? OR parent LIKE ? ESCAPE '!'", path, escapeLike(strings.TrimSuffix(path, "/"))+"/%")
+	if name != "" {
+		q = q.Or("parent = ? AND name = ?", stdpath.Clean(dir), name)
+	}
+	return errors.WithStack(q.Delete(&model.IndexNode{}).Error)
+}
+
+// SearchIndexNodes get at most `limit` nodes under the parent whose name or content
+// contain all the keywords and match the filters, the keywords are separated by spaces
+func SearchIndexNodes(parent, keywords string, filters model.SearchFilters, limit int) ([]model.IndexNode, error) {
+	q := db.Model(&model.IndexNode{})
+	if parent != "/" {
+		q = q.Where("parent = ? OR parent LIKE ? ESCAPE '!'", parent, escapeLike(parent)+"/%")
+	}
+	for _, keyword := range strings.Fields(keywords) {
+		like := "%" + escapeLike(keyword) + "%"
+		q = q.Where(fmt.Sprintf("%s LIKE ? ESCAPE '!' OR content LIKE ? ESCAPE '!'", columnName("name")), like, like)
+	}
+	if filters.Type != 0 {
+		q = q.Where(columnName("type")+" = ?", filters.Type)
+	}
+	if exts := filters.LowerExts(); len(exts) > 0 {
+		q = q.Where("ext IN ?", exts)
+	}
+	if filters.MinSize > 0 {
+		q = q.Where(columnName("size")+" >= ?", filters.MinSize)
+	}
+	if filters.MaxSize > 0 {
+		q = q.Where(columnName("size")+" <= ?", filters.MaxSize)
+	}
+	if filters.ModifiedAfter != nil {
+		q = q.Where("modified >= ?", *filters.ModifiedAfter)
+	}
+	if filters.ModifiedBefore != nil {
+		q = q.Where("modified <= ?", *filters.ModifiedBefore)
+	}
+	var nodes []model.IndexNode
+	err := q.Select("parent", columnName("name"), "is_dir", columnName("size")).Limit(limit).Find(&nodes).Error
+	return nodes, errors.WithStack(err)
+}
+
+// GetIndexNodesByParent get the nodes directly in the parent
+func GetIndexNodesByParent(parent string) ([]model.IndexNode, error) {
+	var nodes []model.IndexNode
+	err := db.Select("parent", columnName("name"), "is_dir", columnName("size")).
+		Where("parent = ?", parent).Find(&nodes).Error
+	return nodes, errors.WithStack(err)
+}
+
+func ClearIndexNodes() error {
+	return errors.WithStack(db.Where("1 = 1").Delete(&model.IndexNode{}).Error)
+}
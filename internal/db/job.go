@@ -0,0 +1,66 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetJobs() ([]model.Job, error) {
+	var jobs []model.Job
+	if err := db.Find(&jobs).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get jobs")
+	}
+	return jobs, nil
+}
+
+func GetJobById(id uint) (*model.Job, error) {
+	var j model.Job
+	if err := db.First(&j, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get job")
+	}
+	return &j, nil
+}
+
+func CreateJob(j *model.Job) error {
+	return errors.WithStack(db.Create(j).Error)
+}
+
+func UpdateJob(j *model.Job) error {
+	return errors.WithStack(db.Save(j).Error)
+}
+
+// DeleteJobById delete the job and its run history
+func DeleteJobById(id uint) error {
+	if err := db.Where("job_id = ?", id).Delete(&model.JobRun{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(db.Delete(&model.Job{}, id).Error)
+}
+
+func CreateJobRun(r *model.JobRun) error {
+	return errors.WithStack(db.Create(r).Error)
+}
+
+func UpdateJobRun(r *model.JobRun) error {
+	return errors.WithStack(db.Save(r).Error)
+}
+
+// GetJobRuns get the run history of the job, the latest first
+func GetJobRuns(jobId uint, pageIndex, pageSize int) ([]model.JobRun, int64, error) {
+	runDB := db.Model(&model.JobRun{}).Where("job_id = ?", jobId)
+	var count int64
+	if err := runDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get job runs count")
+	}
+	var runs []model.JobRun
+	if err := runDB.Order("id desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&runs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find job runs")
+	}
+	return runs, count, nil
+}
+
+// MarkRunningJobRunsErrored mark the runs interrupted by the restart as errored
+func MarkRunningJobRunsErrored(message string) error {
+	return errors.WithStack(db.Model(&model.JobRun{}).Where("state = ?", model.JobRunRunning).
+		Updates(map[string]interface{}{"state": model.JobRunErrored, "message": message}).Error)
+}
@@ -91,3 +91,11 @@ func DeleteMetaById(id uint) error {
 	metaCache.Del(old.Path)
 	return errors.WithStack(db.Delete(&model.Meta{}, id).Error)
 }
+
+func GetAllMetas() ([]model.Meta, error) {
+	var metas []model.Meta
+	if err := db.Find(&metas).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return metas, nil
+}
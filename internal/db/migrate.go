@@ -0,0 +1,170 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type migration struct {
+	id      string
+	migrate func(tx *gorm.DB) error
+}
+
+// the migrations in order, the applied ones mustn't be changed, add a new one to change the schema.
+// the ddl of mysql isn't transactional, so the migrations should be safe to apply again
+var migrations = []migration{
+	{
+		// the schema before the migrations are versioned, it's created or updated by AutoMigrate
+		id: "20261017_init",
+		migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.S3Key), new(model.UploadSession), new(model.RateLimit), new(model.Job), new(model.JobRun), new(model.AclRule), new(model.Group), new(model.UserGroup), new(model.AppPassword), new(model.ApiToken), new(model.FileDrop), new(model.Share), new(model.IndexNode), new(model.DataKey), new(model.AuditLog), new(model.AccessLog), new(model.TrafficQuota), new(model.TrafficUsage), new(model.Webhook), new(model.InterruptedTask))
+		},
+	},
+	{
+		id: "20261017_hooks",
+		migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(new(model.Hook))
+		},
+	},
+	{
+		id: "20261017_meta_filter",
+		migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(new(model.Meta))
+		},
+	},
+	{
+		id: "20261017_meta_header_footer",
+		migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(new(model.Meta))
+		},
+	},
+	{
+		id: "20261017_anti_leech",
+		migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(new(model.AntiLeech))
+		},
+	},
+	{
+		id: "20261017_ip_acl",
+		migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(new(model.User), new(model.Storage))
+		},
+	},
+}
+
+// the name of the lock held by the instance applying the migrations
+const migrationLock = "alist_migrations"
+
+type MigrationStatus struct {
+	ID        string    `json:"id"`
+	Applied   bool      `json:"applied"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+func appliedMigrations(d *gorm.DB) (map[string]time.Time, error) {
+	if err := d.AutoMigrate(new(model.SchemaMigration)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var applied []model.SchemaMigration
+	if err := d.Find(&applied).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make(map[string]time.Time, len(applied))
+	for _, m := range applied {
+		res[m.ID] = m.AppliedAt
+	}
+	return res, nil
+}
+
+// GetMigrationStatus get the migrations in order and if they're applied
+func GetMigrationStatus() ([]MigrationStatus, error) {
+	applied, err := appliedMigrations(&db)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		t, ok := applied[m.id]
+		res = append(res, MigrationStatus{ID: m.id, Applied: ok, AppliedAt: t})
+	}
+	return res, nil
+}
+
+// GetPendingMigrations get the ids of the migrations not applied
+func GetPendingMigrations() ([]string, error) {
+	status, err := GetMigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, s := range status {
+		if !s.Applied {
+			res = append(res, s.ID)
+		}
+	}
+	return res, nil
+}
+
+// Migrate apply the migrations not applied in order, the instances sharing the database
+// hold the lock of the database, so the migrations are applied by only one of them
+func Migrate() error {
+	return db.Connection(func(conn *gorm.DB) error {
+		// the statement of the connection is shared by the chained calls without a new session
+		conn = conn.Session(&gorm.Session{})
+		unlock, err := lockMigrations(conn)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			if _, ok := applied[m.id]; ok {
+				continue
+			}
+			err = conn.Transaction(func(tx *gorm.DB) error {
+				if err := m.migrate(tx); err != nil {
+					return err
+				}
+				return tx.Create(&model.SchemaMigration{ID: m.id, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed apply migration [%s]", m.id)
+			}
+			log.Infof("applied migration [%s]", m.id)
+		}
+		return nil
+	})
+}
+
+// lockMigrations hold the lock of the database on the connection, sqlite is used by only one instance
+func lockMigrations(conn *gorm.DB) (func(), error) {
+	switch conn.Dialector.Name() {
+	case "postgres":
+		// the key of the advisory lock is a number
+		if err := conn.Exec("SELECT pg_advisory_lock(hashtext(?))", migrationLock).Error; err != nil {
+			return nil, errors.Wrapf(err, "failed lock migrations")
+		}
+		return func() {
+			conn.Exec("SELECT pg_advisory_unlock(hashtext(?))", migrationLock)
+		}, nil
+	case "mysql":
+		var locked int
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", migrationLock, 300).Scan(&locked).Error; err != nil {
+			return nil, errors.Wrapf(err, "failed lock migrations")
+		}
+		if locked != 1 {
+			return nil, errors.New("timeout to lock migrations, another instance may be applying them")
+		}
+		return func() {
+			conn.Exec("SELECT RELEASE_LOCK(?)", migrationLock)
+		}, nil
+	}
+	return func() {}, nil
+}
@@ -0,0 +1,24 @@
+package db
+
+import "testing"
+
+func TestMigrate(t *testing.T) {
+	// the migrations are applied by Init already, applying them again does nothing
+	if err := Migrate(); err != nil {
+		t.Fatalf("failed to migrate again: %+v", err)
+	}
+	pending, err := GetPendingMigrations()
+	if err != nil {
+		t.Fatalf("failed to get pending migrations: %+v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("expected no pending migrations, got %v", pending)
+	}
+	status, err := GetMigrationStatus()
+	if err != nil {
+		t.Fatalf("failed to get migrations: %+v", err)
+	}
+	if len(status) != len(migrations) {
+		t.Errorf("expected %d migrations, got %d", len(migrations), len(status))
+	}
+}
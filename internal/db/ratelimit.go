@@ -0,0 +1,34 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetRateLimits() ([]model.RateLimit, error) {
+	var limits []model.RateLimit
+	if err := db.Find(&limits).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get rate limits")
+	}
+	return limits, nil
+}
+
+func GetRateLimitById(id uint) (*model.RateLimit, error) {
+	var l model.RateLimit
+	if err := db.First(&l, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get rate limit")
+	}
+	return &l, nil
+}
+
+func CreateRateLimit(l *model.RateLimit) error {
+	return errors.WithStack(db.Create(l).Error)
+}
+
+func UpdateRateLimit(l *model.RateLimit) error {
+	return errors.WithStack(db.Save(l).Error)
+}
+
+func DeleteRateLimitById(id uint) error {
+	return errors.WithStack(db.Delete(&model.RateLimit{}, id).Error)
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/pkg/errors"
+)
+
+var s3KeyCache = cache.NewMemCache(cache.WithShards[*model.S3Key](2))
+var s3KeyG singleflight.Group[*model.S3Key]
+
+func GetS3KeyByAccessKey(accessKey string) (*model.S3Key, error) {
+	key, ok := s3KeyCache.Get(accessKey)
+	if ok {
+		return key, nil
+	}
+	key, err, _ := s3KeyG.Do(accessKey, func() (*model.S3Key, error) {
+		key := model.S3Key{AccessKey: accessKey}
+		if err := db.Where(key).First(&key).Error; err != nil {
+			return nil, errors.Wrapf(err, "failed find s3 key")
+		}
+		s3KeyCache.Set(accessKey, &key, cache.WithEx[*model.S3Key](time.Hour))
+		return &key, nil
+	})
+	return key, err
+}
+
+func GetS3KeyById(id uint) (*model.S3Key, error) {
+	var k model.S3Key
+	if err := db.First(&k, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get s3 key")
+	}
+	return &k, nil
+}
+
+func CreateS3Key(k *model.S3Key) error {
+	return errors.WithStack(db.Create(k).Error)
+}
+
+func GetS3Keys(pageIndex, pageSize int) ([]model.S3Key, int64, error) {
+	keyDB := db.Model(&model.S3Key{})
+	var count int64
+	if err := keyDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get s3 keys count")
+	}
+	var keys []model.S3Key
+	if err := keyDB.Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&keys).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find s3 keys")
+	}
+	return keys, count, nil
+}
+
+func DeleteS3KeyById(id uint) error {
+	old, err := GetS3KeyById(id)
+	if err != nil {
+		return err
+	}
+	s3KeyCache.Del(old.AccessKey)
+	return errors.WithStack(db.Delete(&model.S3Key{}, id).Error)
+}
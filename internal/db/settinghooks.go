@@ -65,6 +65,22 @@ var SettingItemHooks = map[string]SettingItemHook{
 			return nil
 		},
 	},
+	conf.AdminRequire2FA: {
+		Hook: func(item *model.SettingItem) error {
+			if item.Value != "true" {
+				return nil
+			}
+			// don't lock the admin out
+			admin, err := GetAdmin()
+			if err != nil {
+				return err
+			}
+			if admin.OtpSecret == "" {
+				return errors.New("the admin must enable 2FA first")
+			}
+			return nil
+		},
+	},
 }
 
 func HandleSettingItem(item *model.SettingItem) (bool, error) {
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// GetShares get the shares of the user, or all the shares if userId is 0
+func GetShares(userId uint, pageIndex, pageSize int) ([]model.Share, int64, error) {
+	shareDB := db.Model(&model.Share{})
+	if userId != 0 {
+		shareDB = shareDB.Where("user_id = ?", userId)
+	}
+	var count int64
+	if err := shareDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get shares count")
+	}
+	var shares []model.Share
+	if err := shareDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&shares).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get find shares")
+	}
+	return shares, count, nil
+}
+
+func GetShareById(id uint) (*model.Share, error) {
+	var s model.Share
+	if err := db.First(&s, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get share")
+	}
+	return &s, nil
+}
+
+func GetShareByKey(key string) (*model.Share, error) {
+	var s model.Share
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("key")), key).First(&s).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get share")
+	}
+	return &s, nil
+}
+
+func CreateShare(s *model.Share) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+func UpdateShare(s *model.Share) error {
+	return errors.WithStack(db.Save(s).Error)
+}
+
+func DeleteShareById(id uint) error {
+	return errors.WithStack(db.Delete(&model.Share{}, id).Error)
+}
+
+// IncreaseShareDownloads count a download of the share, false if it reaches the max downloads
+func IncreaseShareDownloads(id uint) (bool, error) {
+	res := db.Model(&model.Share{}).
+		Where("id = ? AND (max_downloads = 0 OR downloads < max_downloads)", id).
+		UpdateColumn("downloads", gorm.Expr("downloads + 1"))
+	if res.Error != nil {
+		return false, errors.WithStack(res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func deleteSharesByUserId(userId uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userId).Delete(&model.Share{}).Error)
+}
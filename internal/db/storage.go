@@ -51,6 +51,23 @@ func GetStorageById(id uint) (*model.Storage, error) {
 	return &storage, nil
 }
 
+// GetStorageByMountPath Get Storage by the mount path, the path should be standardized
+func GetStorageByMountPath(mountPath string) (*model.Storage, error) {
+	var storage model.Storage
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("mount_path")), mountPath).First(&storage).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &storage, nil
+}
+
+func GetAllStorages() ([]model.Storage, error) {
+	var storages []model.Storage
+	if err := db.Find(&storages).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return storages, nil
+}
+
 func GetEnabledStorages() ([]model.Storage, error) {
 	var storages []model.Storage
 	if err := db.Where(fmt.Sprintf("%s = ?", columnName("disabled")), false).Find(&storages).Error; err != nil {
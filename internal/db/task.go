@@ -0,0 +1,25 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateInterruptedTasks(tasks []model.InterruptedTask) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	return errors.WithStack(db.CreateInBatches(tasks, 100).Error)
+}
+
+func GetInterruptedTasks() ([]model.InterruptedTask, error) {
+	var tasks []model.InterruptedTask
+	if err := db.Order("id desc").Find(&tasks).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get interrupted tasks")
+	}
+	return tasks, nil
+}
+
+func DeleteInterruptedTasks() error {
+	return errors.WithStack(db.Where("1 = 1").Delete(&model.InterruptedTask{}).Error)
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetTrafficQuotas() ([]model.TrafficQuota, error) {
+	var quotas []model.TrafficQuota
+	if err := db.Find(&quotas).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get traffic quotas")
+	}
+	return quotas, nil
+}
+
+func GetTrafficQuotaById(id uint) (*model.TrafficQuota, error) {
+	var q model.TrafficQuota
+	if err := db.First(&q, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get traffic quota")
+	}
+	return &q, nil
+}
+
+func CreateTrafficQuota(q *model.TrafficQuota) error {
+	return errors.WithStack(db.Create(q).Error)
+}
+
+func UpdateTrafficQuota(q *model.TrafficQuota) error {
+	return errors.WithStack(db.Save(q).Error)
+}
+
+func DeleteTrafficQuotaById(id uint) error {
+	return errors.WithStack(db.Delete(&model.TrafficQuota{}, id).Error)
+}
+
+// GetTrafficUsage get the usage of the user in the period, it's empty if not found
+func GetTrafficUsage(userId uint, period, start string) (*model.TrafficUsage, error) {
+	u := model.TrafficUsage{UserID: userId, Period: period, Start: start}
+	if err := db.Where(&u).Find(&u).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get traffic usage")
+	}
+	return &u, nil
+}
+
+// AddTrafficUsage add the bytes to the usage of the user in the period
+func AddTrafficUsage(u model.TrafficUsage) error {
+	res := db.Model(&model.TrafficUsage{}).
+		Where("user_id = ? AND period = ? AND start = ?", u.UserID, u.Period, u.Start).
+		Updates(map[string]interface{}{
+			"download": gorm.Expr("download + ?", u.Download),
+			"upload":   gorm.Expr("upload + ?", u.Upload),
+		})
+	if res.Error != nil {
+		return errors.Wrapf(res.Error, "failed add traffic usage")
+	}
+	if res.RowsAffected > 0 {
+		return nil
+	}
+	return errors.WithStack(db.Create(&u).Error)
+}
+
+// DeleteTrafficUsages delete the usages of the user in the periods started at `starts`
+func DeleteTrafficUsages(userId uint, starts []string) error {
+	return errors.WithStack(db.Where("user_id = ? AND start IN ?", userId, starts).Delete(&model.TrafficUsage{}).Error)
+}
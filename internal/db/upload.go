@@ -0,0 +1,37 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateUploadSession(s *model.UploadSession) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+func GetUploadSessionById(id string) (*model.UploadSession, error) {
+	var s model.UploadSession
+	if err := db.Where("id = ?", id).First(&s).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get upload session")
+	}
+	return &s, nil
+}
+
+func UpdateUploadSession(s *model.UploadSession) error {
+	return errors.WithStack(db.Save(s).Error)
+}
+
+func DeleteUploadSessionById(id string) error {
+	return errors.WithStack(db.Delete(&model.UploadSession{}, "id = ?", id).Error)
+}
+
+// GetUploadSessionsBefore get the sessions that are not updated since t
+func GetUploadSessionsBefore(t time.Time) ([]model.UploadSession, error) {
+	var sessions []model.UploadSession
+	if err := db.Where("updated_at < ?", t).Find(&sessions).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find upload sessions")
+	}
+	return sessions, nil
+}
@@ -39,6 +39,8 @@ func GetGuest() (*model.User, error) {
 	return &user, nil
 }
 
+// GetUserByName get the effective user with the permissions of its groups,
+// use GetUserById to get the stored one before updating
 func GetUserByName(username string) (*model.User, error) {
 	if username == "" {
 		return nil, errors.WithStack(errs.EmptyUsername)
@@ -52,8 +54,13 @@ func GetUserByName(username string) (*model.User, error) {
 		if err := db.Where(user).First(&user).Error; err != nil {
 			return nil, errors.Wrapf(err, "failed find user")
 		}
-		userCache.Set(username, &user, cache.WithEx[*model.User](time.Hour))
-		return &user, nil
+		groups, err := GetGroupsByUserId(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		effective := user.Effective(groups)
+		userCache.Set(username, effective, cache.WithEx[*model.User](time.Hour))
+		return effective, nil
 	})
 	return user, err
 }
@@ -66,6 +73,15 @@ func GetUserById(id uint) (*model.User, error) {
 	return &u, nil
 }
 
+// GetUserBySsoId get the stored user linked to the subject of the oidc provider
+func GetUserBySsoId(sub string) (*model.User, error) {
+	var u model.User
+	if err := db.Where("sso_id = ?", sub).First(&u).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get sso user")
+	}
+	return &u, nil
+}
+
 func CreateUser(u *model.User) error {
 	return errors.WithStack(db.Create(u).Error)
 }
@@ -87,6 +103,7 @@ func UpdateUser(u *model.User) error {
 
 func Cancel2FAByUser(u *model.User) error {
 	u.OtpSecret = ""
+	u.OtpRecoveryCodes = ""
 	return errors.WithStack(UpdateUser(u))
 }
 
@@ -120,5 +137,28 @@ func DeleteUserById(id uint) error {
 		return errors.WithStack(errs.DeleteAdminOrGuest)
 	}
 	userCache.Del(old.Username)
+	if err := deleteAclRulesByUserId(id); err != nil {
+		return err
+	}
+	if err := deleteUserGroupsByUserId(id); err != nil {
+		return err
+	}
+	if err := deleteAppPasswordsByUserId(id); err != nil {
+		return err
+	}
+	if err := deleteApiTokensByUserId(id); err != nil {
+		return err
+	}
+	if err := deleteSharesByUserId(id); err != nil {
+		return err
+	}
 	return errors.WithStack(db.Delete(&model.User{}, id).Error)
 }
+
+func GetAllUsers() ([]model.User, error) {
+	var users []model.User
+	if err := db.Find(&users).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return users, nil
+}
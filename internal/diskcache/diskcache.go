@@ -0,0 +1,92 @@
+// Package diskcache caches the content of the proxied files on the disk, the small files
+// are cached entirely and only the start of the large media files is cached for seeking
+// and playing quickly. the least recently used files are evicted when the cache is full
+package diskcache
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/metrics"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/disklru"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+var c = disklru.New(func() string { return filepath.Join(conf.Conf.TempDir, "cache") })
+
+// endFill add the written file to the cache if ok, then evict the least recently used
+// files until the size of the cache is under `capacity`
+func endFill(name string, size int64, ok bool, capacity int64) {
+	if !ok {
+		c.Cancel(name)
+		return
+	}
+	if err := c.Add(name, size, capacity); err != nil {
+		log.Errorf("failed to save the cache: %+v", err)
+	}
+}
+
+// limit get the count of the bytes to cache of the file, 0 if it shouldn't be cached
+func limit(file model.Obj) int64 {
+	maxFileSize := int64(setting.GetInt(conf.ContentCacheMaxFileSize, 0)) * 1024 * 1024
+	if file.GetSize() <= maxFileSize {
+		return file.GetSize()
+	}
+	t := utils.GetFileType(file.GetName())
+	if t != conf.VIDEO && t != conf.AUDIO {
+		return 0
+	}
+	prefix := int64(setting.GetInt(conf.ContentCachePrefixSize, 0)) * 1024 * 1024
+	if prefix > file.GetSize() {
+		return file.GetSize()
+	}
+	return prefix
+}
+
+// Wrap return the link reading the content through the cache, `key` identifies the file
+// with its size and modified time, such as the path. the link is returned as is if the file
+// shouldn't be cached, or the content can't be read from any offset, such as the local files
+func Wrap(key string, link *model.Link, file model.Obj) *model.Link {
+	capacity := int64(setting.GetInt(conf.ContentCacheSize, 0)) * 1024 * 1024
+	if capacity <= 0 || file.IsDir() || file.GetSize() <= 0 || link.Status != 0 {
+		return link
+	}
+	n := limit(file)
+	if n <= 0 || n > capacity {
+		return link
+	}
+	open := opener(link)
+	if open == nil {
+		return link
+	}
+	// the reader of the data link isn't used, the content is read with RangeReader
+	if link.Data != nil {
+		_ = link.Data.Close()
+	}
+	name := utils.GetSHA1Encode(fmt.Sprintf("%s|%d|%d", key, file.GetSize(), file.ModTime().Unix()))
+	f, cached, ok := c.Open(name)
+	metrics.CacheLookup("content", ok)
+	if ok {
+		if cached >= file.GetSize() {
+			return &model.Link{Data: f}
+		}
+		return &model.Link{Data: &prefixReader{f: f, cached: cached, size: file.GetSize(), open: open}}
+	}
+	// the content is cached while reading from the start
+	openAndFill := func(offset int64) (io.ReadCloser, error) {
+		rc, err := open(offset)
+		if err != nil || offset != 0 || !c.StartFill(name) {
+			return rc, err
+		}
+		return newFiller(rc, name, n, capacity), nil
+	}
+	return &model.Link{
+		Data:        &lazyReader{open: func() (io.ReadCloser, error) { return openAndFill(0) }},
+		RangeReader: openAndFill,
+	}
+}
@@ -0,0 +1,128 @@
+package diskcache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/disklru"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	conf.Conf = conf.DefaultConfig()
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	db.Init(dB)
+}
+
+func setup(t *testing.T, content []byte) (*httptest.Server, *int32) {
+	conf.Conf.TempDir = t.TempDir()
+	// the cache is loaded again from the new temp dir
+	c = disklru.New(func() string { return filepath.Join(conf.Conf.TempDir, "cache") })
+	err := db.SaveSettingItems([]model.SettingItem{
+		{Key: conf.VideoTypes, Value: "mp4"},
+		{Key: conf.ContentCacheSize, Value: "4"},
+		{Key: conf.ContentCacheMaxFileSize, Value: "1"},
+		{Key: conf.ContentCachePrefixSize, Value: "1"},
+	})
+	if err != nil {
+		t.Fatalf("failed to save settings: %+v", err)
+	}
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func read(t *testing.T, link *model.Link, offset int64) []byte {
+	var rc io.ReadCloser = link.Data
+	if rs, ok := link.Data.(io.ReadSeekCloser); ok {
+		if _, err := rs.Seek(offset, io.SeekStart); err != nil {
+			t.Fatalf("failed to seek: %+v", err)
+		}
+	} else {
+		_ = link.Data.Close()
+		var err error
+		if rc, err = link.RangeReader(offset); err != nil {
+			t.Fatalf("failed to open: %+v", err)
+		}
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("failed to read: %+v", err)
+	}
+	return data
+}
+
+func TestWrap(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	srv, requests := setup(t, content)
+	file := &model.Object{Name: "a.txt", Size: int64(len(content)), Modified: time.Unix(1, 0)}
+	if data := read(t, Wrap("/a.txt", &model.Link{URL: srv.URL}, file), 0); !bytes.Equal(data, content) {
+		t.Fatalf("the content read is wrong")
+	}
+	link := Wrap("/a.txt", &model.Link{URL: srv.URL}, file)
+	if _, ok := link.Data.(*os.File); !ok {
+		t.Fatalf("the file isn't cached")
+	}
+	if data := read(t, link, 5); !bytes.Equal(data, content[5:]) {
+		t.Fatalf("the cached content is wrong")
+	}
+	if *requests != 1 {
+		t.Errorf("expect 1 request, got %d", *requests)
+	}
+}
+
+func TestWrapPrefix(t *testing.T) {
+	content := bytes.Repeat([]byte("abcdefghij"), 300*1024)
+	srv, requests := setup(t, content)
+	file := &model.Object{Name: "a.mp4", Size: int64(len(content)), Modified: time.Unix(1, 0)}
+	if data := read(t, Wrap("/a.mp4", &model.Link{URL: srv.URL}, file), 0); !bytes.Equal(data, content) {
+		t.Fatalf("the content read is wrong")
+	}
+	link := Wrap("/a.mp4", &model.Link{URL: srv.URL}, file)
+	if _, ok := link.Data.(*prefixReader); !ok {
+		t.Fatalf("the start of the file isn't cached")
+	}
+	if data := read(t, link, 1000); !bytes.Equal(data, content[1000:]) {
+		t.Fatalf("the content read through the cache is wrong")
+	}
+	// the request after the cached part
+	if *requests != 2 {
+		t.Errorf("expect 2 requests, got %d", *requests)
+	}
+}
+
+func TestWrapRefresh(t *testing.T) {
+	content := []byte("0123456789")
+	srv, _ := setup(t, content)
+	file := &model.Object{Name: "b.txt", Size: int64(len(content)), Modified: time.Unix(1, 0)}
+	expired := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer expired.Close()
+	link := &model.Link{URL: expired.URL, Refresh: func(ctx context.Context) (*model.Link, error) {
+		return &model.Link{URL: srv.URL}, nil
+	}}
+	if data := read(t, Wrap("/b.txt", link, file), 0); !bytes.Equal(data, content) {
+		t.Fatalf("the content read with the refreshed link is wrong")
+	}
+}
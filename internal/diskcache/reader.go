@@ -0,0 +1,216 @@
+package diskcache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// opener get the function to read the content of the link from an offset, nil if it can't
+func opener(link *model.Link) func(offset int64) (io.ReadCloser, error) {
+	if link.FilePath != nil {
+		// the local files needn't be cached
+		return nil
+	}
+	if link.Data != nil {
+		return link.RangeReader
+	}
+	if link.URL == "" {
+		return nil
+	}
+	return func(offset int64) (io.ReadCloser, error) {
+		res, err := request(link, offset)
+		if err != nil {
+			return nil, err
+		}
+		// the url may be expired while playing for a long time
+		if res.StatusCode == http.StatusForbidden && link.Refresh != nil {
+			_ = res.Body.Close()
+			if link, err = link.Refresh(context.Background()); err != nil {
+				return nil, err
+			}
+			if res, err = request(link, offset); err != nil {
+				return nil, err
+			}
+		}
+		status := http.StatusOK
+		if offset > 0 {
+			status = http.StatusPartialContent
+		}
+		if res.StatusCode != status {
+			_ = res.Body.Close()
+			return nil, errors.Errorf("failed to read from %d: unexpected status %s", offset, res.Status)
+		}
+		return res.Body, nil
+	}
+}
+
+func request(link *model.Link, offset int64) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, link.URL, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for h, val := range link.Header {
+		req.Header[h] = val
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	res, err := http.DefaultClient.Do(req)
+	return res, errors.WithStack(err)
+}
+
+// lazyReader open the reader on the first read, it isn't opened if only seeking and closing
+type lazyReader struct {
+	open func() (io.ReadCloser, error)
+	rc   io.ReadCloser
+}
+
+func (r *lazyReader) Read(p []byte) (int, error) {
+	if r.rc == nil {
+		rc, err := r.open()
+		if err != nil {
+			return 0, err
+		}
+		r.rc = rc
+	}
+	return r.rc.Read(p)
+}
+
+func (r *lazyReader) Close() error {
+	if r.rc == nil {
+		return nil
+	}
+	return r.rc.Close()
+}
+
+// filler write the content read to the cache until `limit` bytes are read
+type filler struct {
+	io.ReadCloser
+	name     string
+	limit    int64
+	capacity int64
+	tmp      *os.File
+	written  int64
+	// the cache is ended, either saved or given up
+	done bool
+}
+
+func newFiller(rc io.ReadCloser, name string, limit, capacity int64) io.ReadCloser {
+	tmp, err := os.Create(c.TmpPath(name))
+	if err != nil {
+		log.Warnf("failed to create the cache: %+v", err)
+		endFill(name, 0, false, capacity)
+		return rc
+	}
+	return &filler{ReadCloser: rc, name: name, limit: limit, capacity: capacity, tmp: tmp}
+}
+
+func (f *filler) Read(p []byte) (int, error) {
+	n, err := f.ReadCloser.Read(p)
+	if n > 0 && !f.done {
+		w := int64(n)
+		if w > f.limit-f.written {
+			w = f.limit - f.written
+		}
+		if _, werr := f.tmp.Write(p[:w]); werr != nil {
+			log.Warnf("failed to write the cache: %+v", werr)
+			f.end(false)
+		} else {
+			f.written += w
+			if f.written >= f.limit {
+				f.end(true)
+			}
+		}
+	}
+	return n, err
+}
+
+func (f *filler) end(ok bool) {
+	if f.done {
+		return
+	}
+	f.done = true
+	if err := f.tmp.Close(); err != nil {
+		ok = false
+	}
+	endFill(f.name, f.written, ok, f.capacity)
+}
+
+func (f *filler) Close() error {
+	// the reading is stopped before the limit
+	f.end(false)
+	return f.ReadCloser.Close()
+}
+
+// prefixReader read the cached start of the file from the disk, and the rest with `open`
+type prefixReader struct {
+	f      *os.File
+	cached int64
+	size   int64
+	offset int64
+	open   func(offset int64) (io.ReadCloser, error)
+	// the reader at the offset after the cached part, nil if it should be reopened
+	rc io.ReadCloser
+}
+
+func (r *prefixReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.offset < r.cached {
+		if int64(len(p)) > r.cached-r.offset {
+			p = p[:r.cached-r.offset]
+		}
+		n, err := r.f.ReadAt(p, r.offset)
+		r.offset += int64(n)
+		if err == io.EOF && n > 0 {
+			err = nil
+		}
+		return n, err
+	}
+	if r.rc == nil {
+		rc, err := r.open(r.offset)
+		if err != nil {
+			return 0, err
+		}
+		r.rc = rc
+	}
+	n, err := r.rc.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *prefixReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	}
+	if offset < 0 {
+		return 0, errors.New("seek: negative position")
+	}
+	if offset != r.offset && r.rc != nil {
+		_ = r.rc.Close()
+		r.rc = nil
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+func (r *prefixReader) Close() error {
+	err := r.f.Close()
+	if r.rc != nil {
+		if cerr := r.rc.Close(); cerr != nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -10,6 +10,15 @@ type Config struct {
 	NeedMs      bool   `json:"need_ms"` // if need get message from user, such as validate code
 	DefaultRoot string `json:"default_root"`
 	CheckStatus bool
+	// the Put of the driver need to seek or re-read the stream, such as to hash it before uploading,
+	// so the stream is spooled to a temp file before calling it
+	NeedsSeekableUpload bool `json:"needs_seekable_upload"`
+	// the links of the driver can be read from any offset, by the Range header of the url or the RangeReader,
+	// so the features reading part of the file such as the archives and the media info can be used
+	SupportsRangeRead bool `json:"supports_range_read"`
+	// the Copy of the driver copies in the provider, or the objects in the same storage
+	// are copied by downloading and uploading them like between the storages
+	SupportsServerSideCopy bool `json:"supports_server_side_copy"`
 }
 
 func (c Config) MustProxy() bool {
@@ -38,10 +38,42 @@ type Reader interface {
 	Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error)
 }
 
+// ListPager list the objects of the dir in pages, so the large dirs needn't be listed at once
+type ListPager interface {
+	// ListPage list at most `limit` objects of `dir` after `marker`, the marker of the first page is empty,
+	// the marker of the next page is returned, empty if it's the last page
+	ListPage(ctx context.Context, dir model.Obj, marker string, limit int) ([]model.Obj, string, error)
+}
+
 type Getter interface {
 	Get(ctx context.Context, path string) (model.Obj, error)
 }
 
+type Searcher interface {
+	// Search objects whose name contains `keyword` in `dir` recursively with the provider api,
+	// the Parent of result should be the actual path in the storage, like the path used in Get
+	Search(ctx context.Context, keyword string, dir model.Obj) ([]model.SearchNode, error)
+}
+
+type VideoPreviewer interface {
+	// VideoPreview get the streams of the video transcoded by the provider
+	VideoPreview(ctx context.Context, file model.Obj) (*model.VideoPreview, error)
+}
+
+type Quota interface {
+	// GetQuota get the total/used/free bytes of the storage
+	GetQuota(ctx context.Context) (*model.StorageQuota, error)
+}
+
+type Trash interface {
+	// ListTrash list the objects in the recycle bin of the provider
+	ListTrash(ctx context.Context) ([]model.Obj, error)
+	// Restore restore `obj` in the recycle bin to where it was
+	Restore(ctx context.Context, obj model.Obj) error
+	// Purge delete `obj` in the recycle bin permanently
+	Purge(ctx context.Context, obj model.Obj) error
+}
+
 type Writer interface {
 	// MakeDir make a folder named `dirName` in `parentDir`
 	MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error
@@ -57,4 +89,53 @@ type Writer interface {
 	Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up UpdateProgress) error
 }
 
+// BatchMove move a group of objects that in the same dir to `dstDir` in one call,
+// the driver should handle the limit of items count of the provider by itself
+type BatchMove interface {
+	BatchMove(ctx context.Context, srcObjs []model.Obj, dstDir model.Obj) error
+}
+
+// BatchCopy copy a group of objects that in the same dir to `dstDir` in one call
+type BatchCopy interface {
+	BatchCopy(ctx context.Context, srcObjs []model.Obj, dstDir model.Obj) error
+}
+
+// CrossCopy copy `srcObj` to `dstDir` of `dst`, another storage usually of the same driver, with the provider api,
+// errs.NotSupport should be returned if `dst` isn't supported or it can't be done without transferring the content through alist
+type CrossCopy interface {
+	CrossCopy(ctx context.Context, srcObj model.Obj, dst Driver, dstDir model.Obj) error
+}
+
+// BatchRemove remove a group of objects that in the same dir in one call
+type BatchRemove interface {
+	BatchRemove(ctx context.Context, objs []model.Obj) error
+}
+
+// OfflineDownload download the url to `dstDir` with the provider itself, the content doesn't pass through alist
+type OfflineDownload interface {
+	// OfflineDownload submit the url to the provider, return the id of the task in the provider
+	OfflineDownload(ctx context.Context, url string, dstDir model.Obj) (string, error)
+	// OfflineDownloadStatus get the status of the task with `id`
+	OfflineDownloadStatus(ctx context.Context, id string) (*model.OfflineDownloadStatus, error)
+}
+
+// DirectUploader let the clients upload the files to the provider directly with the urls signed by the driver,
+// the content doesn't pass through alist
+type DirectUploader interface {
+	// DirectUpload get the urls to upload the file named `name` of `size` to `dstDir`,
+	// errs.NotSupport should be returned if it should be uploaded through alist, such as it's disabled
+	DirectUpload(ctx context.Context, dstDir model.Obj, name string, size int64) (*model.DirectUpload, error)
+	// CompleteDirectUpload complete the upload after the client uploaded all the parts,
+	// the parts are empty if it isn't a multipart upload
+	CompleteDirectUpload(ctx context.Context, dstDir model.Obj, name string, uploadID string, parts []model.DirectUploadPart) error
+}
+
+// ChangeNotifier get the changes made outside alist with the delta api of the provider
+type ChangeNotifier interface {
+	// Changes get the dirs whose objects are changed since `cursor` and the cursor of the next call,
+	// the dirs are actual paths like the path used in Get. the first call is with an empty cursor,
+	// only the current cursor should be returned then
+	Changes(ctx context.Context, cursor string) (dirs []string, next string, err error)
+}
+
 type UpdateProgress func(percentage int)
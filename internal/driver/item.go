@@ -11,6 +11,8 @@ type Item struct {
 	Options  string `json:"options"`
 	Required bool   `json:"required"`
 	Help     string `json:"help"`
+	// the value is encrypted in the database and redacted in the responses
+	Secret bool `json:"secret"`
 }
 
 type Info struct {
@@ -9,8 +9,19 @@ var (
 	NotSupport   = errors.New("not support")
 	RelativePath = errors.New("access using relative path is not allowed")
 
-	MoveBetweenTwoStorages = errors.New("can't move files between two storages, try to copy")
-	UploadNotSupported     = errors.New("upload not supported")
+	UploadNotSupported      = errors.New("upload not supported")
+	SearchNotSupported      = errors.New("search not supported")
+	TrashNotSupported       = errors.New("trash not supported")
+	ArchiveNotSupported     = errors.New("archive format not supported")
+	ArchivePasswordRequired = errors.New("the password of the archive is required")
+	WrongArchivePassword    = errors.New("the password of the archive is wrong")
+	UploadOffsetMismatch    = errors.New("upload offset mismatch")
+	UploadSizeExceeded      = errors.New("upload size exceeded")
+	UploadNotCompleted      = errors.New("upload not completed")
+	UploadSessionBusy       = errors.New("upload session is busy")
+	TrafficQuotaExceeded    = errors.New("the traffic quota is exceeded")
+	StorageUnavailable      = errors.New("the storage is unavailable, it will be retried later")
+	RefreshTokenRejected    = errors.New("the refresh token is rejected by the provider")
 
 	MetaNotFound = errors.New("meta not found")
 )
@@ -3,5 +3,7 @@ package errs
 import "errors"
 
 var (
-	PermissionDenied = errors.New("permission denied")
+	PermissionDenied  = errors.New("permission denied")
+	StorageReadOnly   = errors.New("storage is read only")
+	StorageUploadOnly = errors.New("storage is upload only")
 )
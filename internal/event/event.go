@@ -0,0 +1,83 @@
+// Package event is the bus of the events happened in alist, such as the files uploaded
+// and the storages going offline, the events are delivered to the subscribers in order
+// asynchronously, and sent to the webhooks configured by the admin
+package event
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// the types of the events
+const (
+	FileUploaded   = "file.uploaded"
+	TaskFinished   = "task.finished"
+	StorageOffline = "storage.offline"
+	// the refresh token of the storage is rejected or failed to refresh in a row
+	TokenExpired = "storage.token_expired"
+	LoginFailed  = "login.failed"
+)
+
+// Types is all the types of the events
+var Types = []string{FileUploaded, TaskFinished, StorageOffline, TokenExpired, LoginFailed}
+
+type Event struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	// the path of the file, or the mount path of the storage, empty if the event isn't about a path
+	Path    string            `json:"path,omitempty"`
+	Message string            `json:"message"`
+	Data    map[string]string `json:"data,omitempty"`
+}
+
+type Handler func(e Event)
+
+var (
+	// the events waiting to be delivered, the events are dropped if it's full
+	events   = make(chan Event, 1024)
+	handlers []Handler
+)
+
+// Subscribe add a handler of all the events, the handlers are expected to be added on startup
+func Subscribe(h Handler) {
+	handlers = append(handlers, h)
+}
+
+// Publish add the event to the bus and return immediately
+func Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	select {
+	case events <- e:
+	default:
+		log.Warnf("too many events, the event %s [%s] is dropped", e.Type, e.Path)
+	}
+}
+
+// Run deliver the events to the handlers, it returns when ctx is done
+func Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-events:
+			for _, h := range handlers {
+				h(e)
+			}
+		}
+	}
+}
+
+// PublishLoginFailed publish the failed login of the user from the ip, via is where it's signed in,
+// such as web or webdav
+func PublishLoginFailed(username, ip, via string) {
+	Publish(Event{
+		Type:    LoginFailed,
+		Message: fmt.Sprintf("failed login of the user [%s] from %s via %s", username, ip, via),
+		Data:    map[string]string{"username": username, "ip": ip, "via": via},
+	})
+}
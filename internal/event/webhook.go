@@ -0,0 +1,222 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the webhooks are loaded in memory since they are matched with every event
+var (
+	hooksMu sync.RWMutex
+	hooks   []model.Webhook
+)
+
+// LoadWebhooks load the webhooks from the database
+func LoadWebhooks() error {
+	res, err := db.GetWebhooks()
+	if err != nil {
+		return err
+	}
+	hooksMu.Lock()
+	hooks = res
+	hooksMu.Unlock()
+	return nil
+}
+
+func check(h *model.Webhook) error {
+	h.Events = strings.ReplaceAll(h.Events, " ", "")
+	for _, typ := range strings.Split(h.Events, ",") {
+		if typ != "" && !utils.SliceContains(Types, typ) {
+			return errors.Errorf("invalid event type: %s", typ)
+		}
+	}
+	if h.PathPrefix != "" {
+		h.PathPrefix = utils.StandardizePath(h.PathPrefix)
+	}
+	switch h.Kind {
+	case model.WebhookJSON:
+		if h.URL == "" {
+			return errors.New("the url is required")
+		}
+	case model.WebhookTelegram:
+		if h.Token == "" || h.ChatID == "" {
+			return errors.New("the token and the chat id of the telegram bot are required")
+		}
+	case model.WebhookBark, model.WebhookServerChan:
+		if h.Token == "" {
+			return errors.New("the key is required")
+		}
+	default:
+		return errors.Errorf("invalid webhook kind: %s", h.Kind)
+	}
+	if h.URL != "" {
+		if u, err := url.Parse(h.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return errors.Errorf("invalid webhook url: %s", h.URL)
+		}
+	}
+	return nil
+}
+
+func CreateWebhook(h *model.Webhook) error {
+	if err := check(h); err != nil {
+		return err
+	}
+	if err := db.CreateWebhook(h); err != nil {
+		return err
+	}
+	return LoadWebhooks()
+}
+
+func UpdateWebhook(h *model.Webhook) error {
+	if _, err := db.GetWebhookById(h.ID); err != nil {
+		return err
+	}
+	if err := check(h); err != nil {
+		return err
+	}
+	if err := db.UpdateWebhook(h); err != nil {
+		return err
+	}
+	return LoadWebhooks()
+}
+
+func DeleteWebhookById(id uint) error {
+	if err := db.DeleteWebhookById(id); err != nil {
+		return err
+	}
+	return LoadWebhooks()
+}
+
+// TestWebhook send a test event to the webhook, it may not be saved yet
+func TestWebhook(ctx context.Context, h *model.Webhook) error {
+	if err := check(h); err != nil {
+		return err
+	}
+	return send(ctx, h, Event{
+		Type:    "test",
+		Time:    time.Now(),
+		Message: "this is a test event from alist",
+	})
+}
+
+// match check if the event should be sent to the webhook by the filters
+func match(h *model.Webhook, e Event) bool {
+	if h.Disabled {
+		return false
+	}
+	if h.Events != "" && !utils.SliceContains(strings.Split(h.Events, ","), e.Type) {
+		return false
+	}
+	if h.PathPrefix != "" && e.Path != "" && !utils.IsSubPath(h.PathPrefix, e.Path) {
+		return false
+	}
+	return true
+}
+
+// Notify send the event to the matched webhooks in background
+func Notify(e Event) {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	for i := range hooks {
+		if !match(&hooks[i], e) {
+			continue
+		}
+		h := hooks[i]
+		go func() {
+			if err := send(context.Background(), &h, e); err != nil {
+				log.Warnf("failed send the event %s to the webhook [%s]: %+v", e.Type, h.Name, err)
+			}
+		}()
+	}
+}
+
+func text(e Event) (string, string) {
+	title := "alist: " + e.Type
+	body := e.Message
+	if e.Path != "" {
+		body = fmt.Sprintf("%s\npath: %s", body, e.Path)
+	}
+	return title, fmt.Sprintf("%s\ntime: %s", body, e.Time.Format(time.RFC3339))
+}
+
+func send(ctx context.Context, h *model.Webhook, e Event) error {
+	var (
+		api  string
+		body []byte
+		err  error
+	)
+	title, content := text(e)
+	switch h.Kind {
+	case model.WebhookJSON:
+		api = h.URL
+		body, err = utils.Json.Marshal(e)
+	case model.WebhookTelegram:
+		api = fmt.Sprintf("%s/bot%s/sendMessage", serverOf(h, "https://api.telegram.org"), h.Token)
+		body, err = utils.Json.Marshal(map[string]string{
+			"chat_id": h.ChatID,
+			"text":    title + "\n" + content,
+		})
+	case model.WebhookBark:
+		api = fmt.Sprintf("%s/%s", serverOf(h, "https://api.day.app"), url.PathEscape(h.Token))
+		body, err = utils.Json.Marshal(map[string]string{
+			"title": title,
+			"body":  content,
+			"group": "alist",
+		})
+	case model.WebhookServerChan:
+		api = fmt.Sprintf("%s/%s.send", serverOf(h, "https://sctapi.ftqq.com"), url.PathEscape(h.Token))
+		body, err = utils.Json.Marshal(map[string]string{
+			"title": title,
+			"desp":  strings.ReplaceAll(content, "\n", "\n\n"),
+		})
+	default:
+		return errors.Errorf("invalid webhook kind: %s", h.Kind)
+	}
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, api, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "invalid webhook url")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.Kind == model.WebhookJSON && h.Token != "" {
+		mac := hmac.New(sha256.New, []byte(h.Token))
+		mac.Write(body)
+		req.Header.Set("X-Alist-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to call the webhook")
+	}
+	_ = res.Body.Close()
+	if res.StatusCode >= 400 {
+		return errors.Errorf("failed to call the webhook, status: %s", res.Status)
+	}
+	return nil
+}
+
+// serverOf get the server of the notification service, it may be a self-hosted one or a proxy
+func serverOf(h *model.Webhook, def string) string {
+	if h.URL == "" {
+		return def
+	}
+	return strings.TrimSuffix(h.URL, "/")
+}
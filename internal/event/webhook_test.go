@@ -0,0 +1,33 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestMatch(t *testing.T) {
+	h := &model.Webhook{Events: "file.uploaded,storage.offline", PathPrefix: "/local"}
+	tests := []struct {
+		e    Event
+		want bool
+	}{
+		{Event{Type: FileUploaded, Path: "/local/a.txt"}, true},
+		{Event{Type: FileUploaded, Path: "/local2/a.txt"}, false},
+		{Event{Type: StorageOffline, Path: "/local"}, true},
+		{Event{Type: LoginFailed}, false},
+	}
+	for _, tt := range tests {
+		if got := match(h, tt.e); got != tt.want {
+			t.Errorf("match(%+v) = %v, want %v", tt.e, got, tt.want)
+		}
+	}
+	h = &model.Webhook{PathPrefix: "/local"}
+	if !match(h, Event{Type: LoginFailed}) {
+		t.Errorf("the event without the path should not be filtered by the path prefix")
+	}
+	h.Disabled = true
+	if match(h, Event{Type: FileUploaded, Path: "/local/a.txt"}) {
+		t.Errorf("the disabled webhook should not match")
+	}
+}
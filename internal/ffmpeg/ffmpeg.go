@@ -0,0 +1,72 @@
+// Package ffmpeg locate ffmpeg and ffprobe, and make the inputs of it from the links of the files
+package ffmpeg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/pkg/errors"
+)
+
+// Path get the path of ffmpeg, empty if it's not available
+func Path() string {
+	if p := setting.GetStr(conf.FfmpegPath); p != "" {
+		return p
+	}
+	p, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return ""
+	}
+	return p
+}
+
+// the demuxers of the media files, the playlists such as hls and concat aren't allowed,
+// since they can make ffmpeg read the other files or urls referred in the uploaded files
+const formatWhitelist = "mov,mp4,m4a,3gp,3g2,mj2,matroska,webm,avi,flv,mpegts,mpeg,mpegvideo,asf,ogg," +
+	"wav,w64,aiff,caf,mp3,aac,flac,ape,wv,tta,dsf,amr,ac3,eac3,dts,truehd,h264,hevc,m4v,rm,dv,mxf"
+
+// Input get the arguments of ffmpeg to read the link, and the reader to be the stdin of
+// ffmpeg if the data of the link is piped. only the protocol of the link and the demuxers
+// of the media files are allowed
+func Input(link *model.Link) ([]string, io.Reader, error) {
+	args := []string{"-format_whitelist", formatWhitelist}
+	switch {
+	case link.FilePath != nil && *link.FilePath != "":
+		return append(args, "-protocol_whitelist", "file", "-i", "file:"+*link.FilePath), nil, nil
+	case link.Data != nil:
+		// ffmpeg reads to the time from the start
+		return append(args, "-protocol_whitelist", "pipe", "-i", "pipe:0"), link.Data, nil
+	case link.URL != "":
+		if len(link.Header) > 0 {
+			var headers strings.Builder
+			for h := range link.Header {
+				headers.WriteString(fmt.Sprintf("%s: %s\r\n", h, link.Header.Get(h)))
+			}
+			args = append(args, "-headers", headers.String())
+		}
+		return append(args, "-protocol_whitelist", "http,https,tls,tcp", "-i", link.URL), nil, nil
+	}
+	return nil, nil, errors.New("the link of the file is empty")
+}
+
+// ProbePath get the path of ffprobe, it's looked up beside ffmpeg first. empty if it's not available
+func ProbePath() string {
+	if p := setting.GetStr(conf.FfmpegPath); p != "" {
+		probe := filepath.Join(filepath.Dir(p), strings.Replace(filepath.Base(p), "ffmpeg", "ffprobe", 1))
+		if _, err := os.Stat(probe); err == nil && probe != p {
+			return probe
+		}
+	}
+	p, err := exec.LookPath("ffprobe")
+	if err != nil {
+		return ""
+	}
+	return p
+}
@@ -0,0 +1,131 @@
+package fs
+
+import (
+	"context"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/pkg/errors"
+)
+
+// aclRuleSets the action is allowed only if all the sets allow it
+type aclRuleSets [][]model.AclRule
+
+func (s aclRuleSets) allowed(path, action string) bool {
+	for _, rules := range s {
+		if !model.AclAllowed(rules, path, action) {
+			return false
+		}
+	}
+	return true
+}
+
+// aclRules get the acl rules of the user in ctx, the admin has no rules,
+// and the restrictions of the api token in ctx are applied to any user
+func aclRules(ctx context.Context) (aclRuleSets, error) {
+	user, _ := ctx.Value("user").(*model.User)
+	if user == nil {
+		return nil, nil
+	}
+	var sets aclRuleSets
+	if !user.IsAdmin() {
+		rules, err := db.GetAclRulesByUserId(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(rules) > 0 {
+			sets = append(sets, rules)
+		}
+	}
+	if token, ok := ctx.Value("api_token").(*model.ApiToken); ok && token != nil {
+		sets = append(sets, token.AclRules(user.BasePath))
+	}
+	return sets, nil
+}
+
+// allowIP check if the storage of the path can be accessed from the ip of the client in ctx,
+// the paths not in any storage, such as the virtual dirs, are always allowed
+func allowIP(ip, path string) bool {
+	if ip == "" {
+		return true
+	}
+	storage, _, err := op.GetStorageAndActualPath(path)
+	return err != nil || storage.GetStorage().AllowIP(ip)
+}
+
+// checkAcl return an error if the action on the paths is denied by the acl rules of the user in ctx,
+// or the storages of the paths can't be accessed from the ip of the client
+func checkAcl(ctx context.Context, action string, paths ...string) error {
+	rules, err := aclRules(ctx)
+	if err != nil {
+		return err
+	}
+	ip, _ := ctx.Value("ip").(string)
+	for _, path := range paths {
+		path = stdpath.Clean(path)
+		if !rules.allowed(path, action) {
+			return errors.WithMessagef(errs.PermissionDenied, "%s [%s] is denied", action, path)
+		}
+		if !allowIP(ip, path) {
+			return errors.WithMessagef(errs.PermissionDenied, "[%s] can't be accessed from %s", path, ip)
+		}
+	}
+	return nil
+}
+
+// CheckAcl is used by the handlers that submit the operations without ctx, e.g. put as task
+func CheckAcl(ctx context.Context, path string, action string) error {
+	return checkAcl(ctx, action, path)
+}
+
+// checkAclNames check the action on the objects named `names` in the dir
+func checkAclNames(ctx context.Context, action string, dirPath string, names []string) error {
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = stdpath.Join(dirPath, name)
+	}
+	return checkAcl(ctx, action, paths...)
+}
+
+// filterAcl remove the objects in the dir that the user in ctx can't read,
+// and the storages can't be accessed from the ip of the client
+func filterAcl(ctx context.Context, dirPath string, objs []model.Obj) ([]model.Obj, error) {
+	rules, err := aclRules(ctx)
+	ip, _ := ctx.Value("ip").(string)
+	if err != nil || (len(rules) == 0 && ip == "") {
+		return objs, err
+	}
+	res := make([]model.Obj, 0, len(objs))
+	for _, obj := range objs {
+		path := stdpath.Join(dirPath, obj.GetName())
+		if rules.allowed(path, model.AclRead) && allowIP(ip, path) {
+			res = append(res, obj)
+		}
+	}
+	return res, nil
+}
+
+// FilterAclNodes is used by the search index, the results of which aren't got by the fs functions
+func FilterAclNodes(ctx context.Context, nodes []model.SearchNode) ([]model.SearchNode, error) {
+	return filterAclNodes(ctx, nodes)
+}
+
+// filterAclNodes remove the search results that the user in ctx can't read
+func filterAclNodes(ctx context.Context, nodes []model.SearchNode) ([]model.SearchNode, error) {
+	rules, err := aclRules(ctx)
+	ip, _ := ctx.Value("ip").(string)
+	if err != nil || (len(rules) == 0 && ip == "") {
+		return nodes, err
+	}
+	res := make([]model.SearchNode, 0, len(nodes))
+	for _, node := range nodes {
+		path := stdpath.Join(node.Parent, node.Name)
+		if rules.allowed(path, model.AclRead) && allowIP(ip, path) {
+			res = append(res, node)
+		}
+	}
+	return res, nil
+}
@@ -7,6 +7,8 @@ import (
 	"sync/atomic"
 
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/hook"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/task"
@@ -18,9 +20,16 @@ var CopyTaskManager = task.NewTaskManager(3, func(tid *uint64) {
 	atomic.AddUint64(tid, 1)
 })
 
-// Copy if in the same storage, call move method
+// Copy if in the same storage which supports server side copy, call copy method
 // if not, add copy task
 func _copy(ctx context.Context, srcObjPath, dstDirPath string) (bool, error) {
+	srcObjPath, dstDirPath = hook.RealPath(ctx, srcObjPath), hook.RealPath(ctx, dstDirPath)
+	if err := checkAcl(ctx, model.AclRead, srcObjPath); err != nil {
+		return false, err
+	}
+	if err := checkAcl(ctx, model.AclWrite, dstDirPath); err != nil {
+		return false, err
+	}
 	srcStorage, srcObjActualPath, err := op.GetStorageAndActualPath(srcObjPath)
 	if err != nil {
 		return false, errors.WithMessage(err, "failed get src storage")
@@ -29,8 +38,11 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string) (bool, error) {
 	if err != nil {
 		return false, errors.WithMessage(err, "failed get dst storage")
 	}
+	if err := op.CheckTransfer(srcStorage, dstStorage, false); err != nil {
+		return false, err
+	}
 	// copy if in the same storage, just call driver.Copy
-	if srcStorage.GetStorage() == dstStorage.GetStorage() {
+	if sameStorageCopy(srcStorage, dstStorage) {
 		return false, op.Copy(ctx, srcStorage, srcObjActualPath, dstDirActualPath)
 	}
 	// not in the same storage
@@ -43,6 +55,49 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string) (bool, error) {
 	return true, nil
 }
 
+// batchCopy copy objects named `names` in `srcDirPath` to `dstDirPath`,
+// return the count of added tasks if not copied by the driver
+func batchCopy(ctx context.Context, srcDirPath string, names []string, dstDirPath string) (int, error) {
+	srcDirPath, names = realNames(ctx, srcDirPath, names)
+	dstDirPath = hook.RealPath(ctx, dstDirPath)
+	if err := checkAclNames(ctx, model.AclRead, srcDirPath, names); err != nil {
+		return 0, err
+	}
+	if err := checkAcl(ctx, model.AclWrite, dstDirPath); err != nil {
+		return 0, err
+	}
+	srcStorage, srcDirActualPath, err := op.GetStorageAndActualPath(srcDirPath)
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed get dst storage")
+	}
+	if err := op.CheckTransfer(srcStorage, dstStorage, false); err != nil {
+		return 0, err
+	}
+	if sameStorageCopy(srcStorage, dstStorage) {
+		return 0, op.BatchCopy(ctx, srcStorage, srcDirActualPath, names, dstDirActualPath)
+	}
+	for _, name := range names {
+		srcObjActualPath := stdpath.Join(srcDirActualPath, name)
+		CopyTaskManager.Submit(task.WithCancelCtx(&task.Task[uint64]{
+			Name: fmt.Sprintf("copy [%s](%s) to [%s](%s)", srcStorage.GetStorage().MountPath, srcObjActualPath, dstStorage.GetStorage().MountPath, dstDirActualPath),
+			Func: func(task *task.Task[uint64]) error {
+				return copyBetween2Storages(task, srcStorage, dstStorage, srcObjActualPath, dstDirActualPath)
+			},
+		}))
+	}
+	return len(names), nil
+}
+
+// sameStorageCopy check if the objects can be copied by the driver, the storages without server side copy
+// transfer the content with the copy tasks even in the same storage
+func sameStorageCopy(srcStorage, dstStorage driver.Driver) bool {
+	return srcStorage.GetStorage() == dstStorage.GetStorage() && srcStorage.Config().SupportsServerSideCopy
+}
+
 func copyBetween2Storages(t *task.Task[uint64], srcStorage, dstStorage driver.Driver, srcObjPath, dstDirPath string) error {
 	t.SetStatus("getting src object")
 	srcObj, err := op.Get(t.Ctx, srcStorage, srcObjPath)
@@ -50,6 +105,14 @@ func copyBetween2Storages(t *task.Task[uint64], srcStorage, dstStorage driver.Dr
 		return errors.WithMessagef(err, "failed get src [%s] file", srcObjPath)
 	}
 	if srcObj.IsDir() {
+		if ok, err := crossCopy(t, srcStorage, dstStorage, srcObjPath, dstDirPath); ok || err != nil {
+			return err
+		}
+		// the objects in the dir are copied to the dir with the same name in dst
+		dstObjPath := stdpath.Join(dstDirPath, srcObj.GetName())
+		if err := op.MakeDir(t.Ctx, dstStorage, dstObjPath); err != nil {
+			return errors.WithMessagef(err, "failed make dst dir [%s]", dstObjPath)
+		}
 		t.SetStatus("src object is dir, listing objs")
 		objs, err := op.List(t.Ctx, srcStorage, srcObjPath, model.ListArgs{})
 		if err != nil {
@@ -60,7 +123,6 @@ func copyBetween2Storages(t *task.Task[uint64], srcStorage, dstStorage driver.Dr
 				return nil
 			}
 			srcObjPath := stdpath.Join(srcObjPath, obj.GetName())
-			dstObjPath := stdpath.Join(dstDirPath, obj.GetName())
 			CopyTaskManager.Submit(task.WithCancelCtx(&task.Task[uint64]{
 				Name: fmt.Sprintf("copy [%s](%s) to [%s](%s)", srcStorage.GetStorage().MountPath, srcObjPath, dstStorage.GetStorage().MountPath, dstObjPath),
 				Func: func(t *task.Task[uint64]) error {
@@ -80,6 +142,10 @@ func copyBetween2Storages(t *task.Task[uint64], srcStorage, dstStorage driver.Dr
 }
 
 func copyFileBetween2Storages(tsk *task.Task[uint64], srcStorage, dstStorage driver.Driver, srcFilePath, dstDirPath string) error {
+	if ok, err := crossCopy(tsk, srcStorage, dstStorage, srcFilePath, dstDirPath); ok || err != nil {
+		return err
+	}
+	tsk.SetStatus(fmt.Sprintf("copying [%s]", srcFilePath))
 	srcFile, err := op.Get(tsk.Ctx, srcStorage, srcFilePath)
 	if err != nil {
 		return errors.WithMessagef(err, "failed get src [%s] file", srcFilePath)
@@ -92,5 +158,21 @@ func copyFileBetween2Storages(tsk *task.Task[uint64], srcStorage, dstStorage dri
 	if err != nil {
 		return errors.WithMessagef(err, "failed get [%s] stream", srcFilePath)
 	}
-	return op.Put(tsk.Ctx, dstStorage, dstDirPath, stream, tsk.SetProgress)
+	limiters := transferLimiters(srcStorage.GetStorage(), dstStorage.GetStorage())
+	return op.Put(tsk.Ctx, dstStorage, dstDirPath, limitStream(tsk.Ctx, stream, limiters), tsk.SetProgress)
+}
+
+// crossCopy copy the object with the provider api if the driver supports copying to the dst storage,
+// return false if it's not supported, then the content should be transferred through alist
+func crossCopy(t *task.Task[uint64], srcStorage, dstStorage driver.Driver, srcObjPath, dstDirPath string) (bool, error) {
+	t.SetStatus(fmt.Sprintf("copying [%s] with the provider api", srcObjPath))
+	err := op.CrossCopy(t.Ctx, srcStorage, dstStorage, srcObjPath, dstDirPath)
+	if errors.Is(err, errs.NotSupport) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithMessagef(err, "failed cross copy [%s]", srcObjPath)
+	}
+	t.SetProgress(100)
+	return true, nil
 }
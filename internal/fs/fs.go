@@ -2,10 +2,15 @@ package fs
 
 import (
 	"context"
+	"io"
+	stdpath "path"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -15,6 +20,7 @@ import (
 
 func List(ctx context.Context, path string, refresh ...bool) ([]model.Obj, error) {
 	res, err := list(ctx, path, refresh...)
+	audit.Record(ctx, model.AuditList, path, err)
 	if err != nil {
 		log.Errorf("failed list %s: %+v", path, err)
 		return nil, err
@@ -22,6 +28,27 @@ func List(ctx context.Context, path string, refresh ...bool) ([]model.Obj, error
 	return res, nil
 }
 
+// ListPage list at most `limit` objects in the path after `marker`, the marker of the next page
+// is returned, empty if it's the last page
+func ListPage(ctx context.Context, path, marker string, limit int, refresh bool) ([]model.Obj, string, error) {
+	res, next, err := listPage(ctx, path, marker, limit, refresh)
+	audit.Record(ctx, model.AuditList, path, err)
+	if err != nil {
+		log.Errorf("failed list page of %s after [%s]: %+v", path, marker, err)
+		return nil, "", err
+	}
+	return res, next, nil
+}
+
+func Search(ctx context.Context, path, keyword string) ([]model.SearchNode, error) {
+	res, err := search(ctx, path, keyword)
+	if err != nil {
+		log.Errorf("failed search %s in %s: %+v", keyword, path, err)
+		return nil, err
+	}
+	return res, nil
+}
+
 func Get(ctx context.Context, path string) (model.Obj, error) {
 	res, err := get(ctx, path)
 	if err != nil {
@@ -31,6 +58,20 @@ func Get(ctx context.Context, path string) (model.Obj, error) {
 	return res, nil
 }
 
+// Lookup get the object in the path, it's nil without the error if not found,
+// so the objects may not exist are got without the errors logged
+func Lookup(ctx context.Context, path string) (model.Obj, error) {
+	res, err := get(ctx, path)
+	if errs.IsObjectNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Errorf("failed get %s: %+v", path, err)
+		return nil, err
+	}
+	return res, nil
+}
+
 func Link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
 	res, file, err := link(ctx, path, args)
 	if err != nil {
@@ -40,32 +81,79 @@ func Link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, m
 	return res, file, nil
 }
 
+// RangeReadable check if the file in `path` can be read from any offset,
+// depending on the capability of the driver of the storage
+func RangeReadable(path string) bool {
+	storage, _, err := op.GetStorageAndActualPath(path)
+	return err == nil && storage.Config().SupportsRangeRead
+}
+
+// Open get the stream of the file, the caller should close it
+func Open(ctx context.Context, path string) (model.FileStreamer, error) {
+	res, err := open(ctx, path)
+	if err != nil {
+		log.Errorf("failed open %s: %+v", path, err)
+		return nil, err
+	}
+	return res, nil
+}
+
 func MakeDir(ctx context.Context, path string) error {
 	err := makeDir(ctx, path)
+	audit.Record(ctx, model.AuditMakeDir, path, err)
 	if err != nil {
 		log.Errorf("failed make dir %s: %+v", path, err)
 	}
 	return err
 }
 
-func Move(ctx context.Context, srcPath, dstDirPath string) error {
-	err := move(ctx, srcPath, dstDirPath)
+// Move return true if a move task is added, that is the objects are in different storages
+func Move(ctx context.Context, srcPath, dstDirPath string) (bool, error) {
+	res, err := move(ctx, srcPath, dstDirPath)
+	audit.RecordWith(ctx, model.AuditMove, srcPath, dstDirPath, 0, err)
 	if err != nil {
 		log.Errorf("failed move %s to %s: %+v", srcPath, dstDirPath, err)
 	}
-	return err
+	return res, err
 }
 
 func Copy(ctx context.Context, srcObjPath, dstDirPath string) (bool, error) {
 	res, err := _copy(ctx, srcObjPath, dstDirPath)
+	audit.RecordWith(ctx, model.AuditCopy, srcObjPath, dstDirPath, 0, err)
 	if err != nil {
 		log.Errorf("failed copy %s to %s: %+v", srcObjPath, dstDirPath, err)
 	}
 	return res, err
 }
 
+// BatchMove move objects named `names` in `srcDirPath` to `dstDirPath`,
+// return the count of added tasks if not in the same storage
+func BatchMove(ctx context.Context, srcDirPath string, names []string, dstDirPath string) (int, error) {
+	res, err := batchMove(ctx, srcDirPath, names, dstDirPath)
+	for _, name := range names {
+		audit.RecordWith(ctx, model.AuditMove, stdpath.Join(srcDirPath, name), dstDirPath, 0, err)
+	}
+	if err != nil {
+		log.Errorf("failed batch move %v in %s to %s: %+v", names, srcDirPath, dstDirPath, err)
+	}
+	return res, err
+}
+
+// BatchCopy return the count of added tasks
+func BatchCopy(ctx context.Context, srcDirPath string, names []string, dstDirPath string) (int, error) {
+	res, err := batchCopy(ctx, srcDirPath, names, dstDirPath)
+	for _, name := range names {
+		audit.RecordWith(ctx, model.AuditCopy, stdpath.Join(srcDirPath, name), dstDirPath, 0, err)
+	}
+	if err != nil {
+		log.Errorf("failed batch copy %v in %s to %s: %+v", names, srcDirPath, dstDirPath, err)
+	}
+	return res, err
+}
+
 func Rename(ctx context.Context, srcPath, dstName string) error {
 	err := rename(ctx, srcPath, dstName)
+	audit.RecordWith(ctx, model.AuditRename, srcPath, dstName, 0, err)
 	if err != nil {
 		log.Errorf("failed rename %s to %s: %+v", srcPath, dstName, err)
 	}
@@ -74,28 +162,87 @@ func Rename(ctx context.Context, srcPath, dstName string) error {
 
 func Remove(ctx context.Context, path string) error {
 	err := remove(ctx, path)
+	audit.Record(ctx, model.AuditRemove, path, err)
 	if err != nil {
 		log.Errorf("failed remove %s: %+v", path, err)
 	}
 	return err
 }
 
+func BatchRemove(ctx context.Context, dirPath string, names []string) error {
+	err := batchRemove(ctx, dirPath, names)
+	for _, name := range names {
+		audit.Record(ctx, model.AuditRemove, stdpath.Join(dirPath, name), err)
+	}
+	if err != nil {
+		log.Errorf("failed batch remove %v in %s: %+v", names, dirPath, err)
+	}
+	return err
+}
+
+// ListTrash list the recycle bin of the storage that `path` belongs to
+func ListTrash(ctx context.Context, path string) ([]model.Obj, error) {
+	res, err := listTrash(ctx, path)
+	if err != nil {
+		log.Errorf("failed list trash of %s: %+v", path, err)
+		return nil, err
+	}
+	return res, nil
+}
+
+func RestoreTrash(ctx context.Context, path string, ids []string) error {
+	err := restoreTrash(ctx, path, ids)
+	if err != nil {
+		log.Errorf("failed restore %v in trash of %s: %+v", ids, path, err)
+	}
+	return err
+}
+
+func PurgeTrash(ctx context.Context, path string, ids []string) error {
+	err := purgeTrash(ctx, path, ids)
+	if err != nil {
+		log.Errorf("failed purge %v in trash of %s: %+v", ids, path, err)
+	}
+	return err
+}
+
 func PutDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer) error {
 	err := putDirectly(ctx, dstDirPath, file)
+	audit.RecordWith(ctx, model.AuditUpload, stdpath.Join(dstDirPath, file.GetName()), "", file.GetSize(), err)
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
 	return err
 }
 
-func PutAsTask(dstDirPath string, file model.FileStreamer) error {
-	err := putAsTask(dstDirPath, file)
+func PutAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) error {
+	err := putAsTask(ctx, dstDirPath, file)
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
 	return err
 }
 
+// DirectUpload get the urls the client uploads the file in `path` of `size` to the storage with directly,
+// errs.NotSupport is returned if the storage can't, then it should be uploaded through alist
+func DirectUpload(ctx context.Context, path string, size int64) (*model.DirectUpload, error) {
+	res, err := directUpload(ctx, path, size)
+	if err != nil && !errors.Is(err, errs.NotSupport) {
+		log.Errorf("failed get the direct upload of %s: %+v", path, err)
+	}
+	return res, err
+}
+
+// CompleteDirectUpload complete the upload of the file in `path` after the client uploaded it directly
+func CompleteDirectUpload(ctx context.Context, path, uploadID string, parts []model.DirectUploadPart) error {
+	err := completeDirectUpload(ctx, path, uploadID, parts)
+	audit.Record(ctx, model.AuditUpload, path, err)
+	if err != nil {
+		log.Errorf("failed complete the direct upload of %s: %+v", path, err)
+	}
+	return err
+}
+
 func GetStorage(path string) (driver.Driver, error) {
 	storageDriver, _, err := op.GetStorageAndActualPath(path)
 	if err != nil {
@@ -111,3 +258,47 @@ func Other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
 	}
 	return res, err
 }
+
+// VideoPreview get the streams of the video transcoded by the provider, errs.NotImplement
+// if the provider can't transcode
+func VideoPreview(ctx context.Context, path string) (*model.VideoPreview, error) {
+	res, err := videoPreview(ctx, path)
+	if err != nil && !errors.Is(err, errs.NotImplement) {
+		log.Errorf("failed get video preview %s: %+v", path, err)
+	}
+	return res, err
+}
+
+// CreateUpload create a resumable upload session, the ID and Offset of it are set
+func CreateUpload(session *model.UploadSession) error {
+	err := createUpload(session)
+	if err != nil {
+		log.Errorf("failed create upload session of %s: %+v", session.Path, err)
+	}
+	return err
+}
+
+func UploadChunk(session *model.UploadSession, offset int64, r io.Reader) error {
+	err := uploadChunk(session, offset, r)
+	if err != nil {
+		log.Errorf("failed upload chunk of %s at %d: %+v", session.Path, offset, err)
+	}
+	return err
+}
+
+func CompleteUpload(ctx context.Context, session *model.UploadSession) error {
+	err := completeUpload(ctx, session)
+	audit.RecordWith(ctx, model.AuditUpload, session.Path, "", session.Size, err)
+	if err != nil {
+		log.Errorf("failed complete upload of %s: %+v", session.Path, err)
+	}
+	return err
+}
+
+func CancelUpload(session *model.UploadSession) error {
+	err := cancelUpload(session)
+	if err != nil {
+		log.Errorf("failed cancel upload of %s: %+v", session.Path, err)
+	}
+	return err
+}
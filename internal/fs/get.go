@@ -5,6 +5,8 @@ import (
 	stdpath "path"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/hook"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -12,7 +14,10 @@ import (
 )
 
 func get(ctx context.Context, path string) (model.Obj, error) {
-	path = utils.StandardizePath(path)
+	path = utils.StandardizePath(hook.RealPath(ctx, path))
+	if err := checkAcl(ctx, model.AclRead, path); err != nil {
+		return nil, err
+	}
 	// maybe a virtual file
 	if path != "/" {
 		virtualFiles := op.GetStorageVirtualFilesByPath(stdpath.Dir(path))
@@ -35,5 +40,13 @@ func get(ctx context.Context, path string) (model.Obj, error) {
 		}
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
-	return op.Get(ctx, storage, actualPath)
+	// only the root dir of the upload only storage can be got
+	if storage.GetStorage().UploadOnly && actualPath != "/" {
+		return nil, errors.WithStack(errs.ObjectNotFound)
+	}
+	obj, err := op.Get(ctx, storage, actualPath)
+	if err != nil {
+		return nil, err
+	}
+	return hook.Display(ctx, path, obj), nil
 }
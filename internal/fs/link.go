@@ -3,15 +3,50 @@ package fs
 import (
 	"context"
 
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/hook"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 func link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
-	storage, actualPath, err := op.GetStorageAndActualPath(path)
-	if err != nil {
-		return nil, nil, errors.WithMessage(err, "failed get storage")
+	path = hook.RealPath(ctx, path)
+	if err := checkAcl(ctx, model.AclRead, path); err != nil {
+		return nil, nil, err
 	}
-	return op.Link(ctx, storage, actualPath, args)
+	storages := op.GetBalancedStorages(path)
+	if len(storages) == 0 {
+		return nil, nil, errors.Errorf("failed get storage: can't find storage with rawPath: %s", path)
+	}
+	// the mirrored storages are tried in turn if the link fails
+	var err error
+	for _, storage := range storages {
+		if err := op.CheckRead(storage); err != nil {
+			return nil, nil, err
+		}
+		var link *model.Link
+		var file model.Obj
+		link, file, err = op.Link(ctx, storage, op.StorageActualPath(storage, path), args)
+		if err == nil {
+			if err = hook.CheckLinkByMeta(ctx, path, file); err != nil {
+				return nil, nil, err
+			}
+			if link, err = hook.Link(ctx, path, file, link); err != nil {
+				return nil, nil, err
+			}
+			return link, hook.Display(ctx, path, file), nil
+		}
+		if len(storages) == 1 || utils.IsCanceled(ctx) {
+			return nil, nil, err
+		}
+		// the mirror may be incomplete, it isn't failed
+		if !errs.IsObjectNotFound(err) {
+			op.MarkBalancedStorageFailed(storage)
+		}
+		log.Warnf("failed link [%s] in [%s], try the next balanced storage: %+v", path, storage.GetStorage().MountPath, err)
+	}
+	return nil, nil, err
 }
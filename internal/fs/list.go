@@ -2,9 +2,13 @@ package fs
 
 import (
 	"context"
+	stdpath "path"
 	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/hook"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -14,23 +18,31 @@ import (
 
 // List files
 func list(ctx context.Context, path string, refresh ...bool) ([]model.Obj, error) {
+	path = hook.RealPath(ctx, path)
+	if err := checkAcl(ctx, model.AclRead, path); err != nil {
+		return nil, err
+	}
 	meta := ctx.Value("meta").(*model.Meta)
 	user := ctx.Value("user").(*model.User)
 	storage, actualPath, err := op.GetStorageAndActualPath(path)
 	virtualFiles := op.GetStorageVirtualFilesByPath(path)
 	if err != nil {
 		if len(virtualFiles) != 0 {
-			return virtualFiles, nil
+			return filterAcl(ctx, path, virtualFiles)
 		}
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
+	// the objects of the upload only storage are hidden
+	if storage.GetStorage().UploadOnly {
+		return filterAcl(ctx, path, append([]model.Obj{}, virtualFiles...))
+	}
 	objs, err := op.List(ctx, storage, actualPath, model.ListArgs{
 		ReqPath: path,
 	}, refresh...)
 	if err != nil {
 		log.Errorf("%+v", err)
 		if len(virtualFiles) != 0 {
-			return virtualFiles, nil
+			return filterAcl(ctx, path, virtualFiles)
 		}
 		return nil, errors.WithMessage(err, "failed get objs")
 	}
@@ -42,12 +54,108 @@ func list(ctx context.Context, path string, refresh ...bool) ([]model.Obj, error
 	if whetherHide(user, meta, path) {
 		objs = hide(objs, meta)
 	}
+	objs = hook.FilterByMeta(ctx, meta, path, objs)
+	objs = hook.Filter(ctx, path, objs)
 	// sort objs
 	if storage.Config().LocalSort {
 		model.SortFiles(objs, storage.GetStorage().OrderBy, storage.GetStorage().OrderDirection)
 	}
 	model.ExtractFolder(objs, storage.GetStorage().ExtractFolder)
-	return objs, nil
+	return filterAcl(ctx, path, objs)
+}
+
+// listPage list a page of the objects in the path, the pages are listed by the driver if it supports,
+// the objects are in the order of the provider then. or the page is sliced from the whole list,
+// and the marker is the offset of the next page
+func listPage(ctx context.Context, path, marker string, limit int, refresh bool) ([]model.Obj, string, error) {
+	if limit <= 0 {
+		return nil, "", errors.New("the limit of the page must be positive")
+	}
+	path = hook.RealPath(ctx, path)
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if _, ok := storage.(driver.ListPager); err != nil || !ok || storage.GetStorage().UploadOnly {
+		objs, err := list(ctx, path, refresh)
+		if err != nil {
+			return nil, "", err
+		}
+		return offsetPage(objs, marker, limit)
+	}
+	if err := checkAcl(ctx, model.AclRead, path); err != nil {
+		return nil, "", err
+	}
+	meta := ctx.Value("meta").(*model.Meta)
+	user := ctx.Value("user").(*model.User)
+	if refresh {
+		op.ClearCache(storage, actualPath)
+	}
+	objs, next, err := op.ListPage(ctx, storage, actualPath, marker, limit)
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "failed get objs")
+	}
+	// the mount points are in the first page
+	if marker == "" {
+		for _, storageFile := range op.GetStorageVirtualFilesByPath(path) {
+			if !containsByName(objs, storageFile) {
+				objs = append(objs, storageFile)
+			}
+		}
+	}
+	if whetherHide(user, meta, path) {
+		objs = hide(objs, meta)
+	}
+	objs = hook.FilterByMeta(ctx, meta, path, objs)
+	objs = hook.Filter(ctx, path, objs)
+	model.ExtractFolder(objs, storage.GetStorage().ExtractFolder)
+	objs, err = filterAcl(ctx, path, objs)
+	return objs, next, err
+}
+
+// offsetPage slice the page after the offset `marker` from all the objects
+func offsetPage(objs []model.Obj, marker string, limit int) ([]model.Obj, string, error) {
+	start := 0
+	if marker != "" {
+		offset, err := strconv.Atoi(marker)
+		if err != nil || offset < 0 {
+			return nil, "", errors.Errorf("invalid marker [%s]", marker)
+		}
+		start = offset
+	}
+	if start >= len(objs) {
+		return []model.Obj{}, "", nil
+	}
+	end := start + limit
+	if end >= len(objs) {
+		return objs[start:], "", nil
+	}
+	return objs[start:end], strconv.Itoa(end), nil
+}
+
+// search objects in the storage that `path` belongs to, the parent of result is mount path
+func search(ctx context.Context, path, keyword string) ([]model.SearchNode, error) {
+	if err := checkAcl(ctx, model.AclRead, path); err != nil {
+		return nil, err
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	if err := op.CheckRead(storage); err != nil {
+		return nil, err
+	}
+	nodes, err := op.Search(ctx, storage, actualPath, keyword)
+	if err != nil {
+		return nil, err
+	}
+	rootPath := "/"
+	if r, ok := storage.GetAddition().(driver.IRootPath); ok {
+		rootPath = utils.StandardizePath(r.GetRootPath())
+	}
+	mountPath := utils.GetActualVirtualPath(storage.GetStorage().MountPath)
+	for i := range nodes {
+		parent := strings.TrimPrefix(nodes[i].Parent, rootPath)
+		nodes[i].Parent = stdpath.Join(mountPath, parent)
+	}
+	return filterAclNodes(ctx, nodes)
 }
 
 func whetherHide(user *model.User, meta *model.Meta, path string) bool {
@@ -0,0 +1,192 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"sync/atomic"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/hook"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+var MoveTaskManager = task.NewTaskManager(3, func(tid *uint64) {
+	atomic.AddUint64(tid, 1)
+})
+
+// Move if in the same storage, call move method
+// if not, add move task
+func move(ctx context.Context, srcPath, dstDirPath string) (bool, error) {
+	srcPath, dstDirPath = hook.RealPath(ctx, srcPath), hook.RealPath(ctx, dstDirPath)
+	if err := checkAcl(ctx, model.AclDelete, srcPath); err != nil {
+		return false, err
+	}
+	if err := checkAcl(ctx, model.AclWrite, dstDirPath); err != nil {
+		return false, err
+	}
+	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
+	if err != nil {
+		return false, errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return false, errors.WithMessage(err, "failed get dst storage")
+	}
+	if err := op.CheckTransfer(srcStorage, dstStorage, true); err != nil {
+		return false, err
+	}
+	if srcStorage.GetStorage() == dstStorage.GetStorage() {
+		return false, op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath)
+	}
+	submitMoveTask(srcStorage, dstStorage, srcActualPath, dstDirActualPath)
+	return true, nil
+}
+
+// batchMove move objects named `names` in `srcDirPath` to `dstDirPath`,
+// return the count of added tasks if not in the same storage
+func batchMove(ctx context.Context, srcDirPath string, names []string, dstDirPath string) (int, error) {
+	srcDirPath, names = realNames(ctx, srcDirPath, names)
+	dstDirPath = hook.RealPath(ctx, dstDirPath)
+	if err := checkAclNames(ctx, model.AclDelete, srcDirPath, names); err != nil {
+		return 0, err
+	}
+	if err := checkAcl(ctx, model.AclWrite, dstDirPath); err != nil {
+		return 0, err
+	}
+	srcStorage, srcDirActualPath, err := op.GetStorageAndActualPath(srcDirPath)
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed get dst storage")
+	}
+	if err := op.CheckTransfer(srcStorage, dstStorage, true); err != nil {
+		return 0, err
+	}
+	if srcStorage.GetStorage() == dstStorage.GetStorage() {
+		return 0, op.BatchMove(ctx, srcStorage, srcDirActualPath, names, dstDirActualPath)
+	}
+	for _, name := range names {
+		submitMoveTask(srcStorage, dstStorage, stdpath.Join(srcDirActualPath, name), dstDirActualPath)
+	}
+	return len(names), nil
+}
+
+func submitMoveTask(srcStorage, dstStorage driver.Driver, srcObjPath, dstDirPath string) {
+	MoveTaskManager.Submit(task.WithCancelCtx(&task.Task[uint64]{
+		Name: fmt.Sprintf("move [%s](%s) to [%s](%s)", srcStorage.GetStorage().MountPath, srcObjPath, dstStorage.GetStorage().MountPath, dstDirPath),
+		Func: func(t *task.Task[uint64]) error {
+			return moveBetween2Storages(t, srcStorage, dstStorage, srcObjPath, dstDirPath)
+		},
+	}))
+}
+
+// moveBetween2Storages copy the object to the dst storage, and remove the src object
+// only after the copied one is verified. The objects that have been moved are not
+// in the src storage anymore, so a retry of the task continues with the rest.
+func moveBetween2Storages(t *task.Task[uint64], srcStorage, dstStorage driver.Driver, srcObjPath, dstDirPath string) error {
+	t.SetStatus("getting src object")
+	srcObj, err := op.Get(t.Ctx, srcStorage, srcObjPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get src [%s] object", srcObjPath)
+	}
+	if srcObj.IsDir() {
+		return moveDirBetween2Storages(t, srcStorage, dstStorage, srcObjPath, dstDirPath, t.SetProgress)
+	}
+	return moveFileBetween2Storages(t, srcStorage, dstStorage, srcObj, srcObjPath, dstDirPath, t.SetProgress)
+}
+
+func moveDirBetween2Storages(t *task.Task[uint64], srcStorage, dstStorage driver.Driver, srcDirPath, dstDirPath string, up driver.UpdateProgress) error {
+	dstPath := stdpath.Join(dstDirPath, stdpath.Base(srcDirPath))
+	t.SetStatus(fmt.Sprintf("making dir [%s]", dstPath))
+	if err := op.MakeDir(t.Ctx, dstStorage, dstPath); err != nil {
+		return errors.WithMessagef(err, "failed make dst dir [%s]", dstPath)
+	}
+	t.SetStatus(fmt.Sprintf("listing [%s]", srcDirPath))
+	objs, err := op.List(t.Ctx, srcStorage, srcDirPath, model.ListArgs{}, true)
+	if err != nil {
+		return errors.WithMessagef(err, "failed list src [%s] objs", srcDirPath)
+	}
+	for i, obj := range objs {
+		if utils.IsCanceled(t.Ctx) {
+			return nil
+		}
+		srcObjPath := stdpath.Join(srcDirPath, obj.GetName())
+		if obj.IsDir() {
+			err = moveDirBetween2Storages(t, srcStorage, dstStorage, srcObjPath, dstPath, nil)
+		} else {
+			err = moveFileBetween2Storages(t, srcStorage, dstStorage, obj, srcObjPath, dstPath, nil)
+		}
+		if err != nil {
+			return err
+		}
+		if up != nil {
+			up((i + 1) * 100 / len(objs))
+		}
+	}
+	if utils.IsCanceled(t.Ctx) {
+		return nil
+	}
+	// the objects may be added to the src dir during the move
+	objs, err = op.List(t.Ctx, srcStorage, srcDirPath, model.ListArgs{}, true)
+	if err != nil {
+		return errors.WithMessagef(err, "failed list src [%s] objs", srcDirPath)
+	}
+	if len(objs) > 0 {
+		return errors.Errorf("src dir [%s] is not empty after moving, %d objects left", srcDirPath, len(objs))
+	}
+	t.SetStatus(fmt.Sprintf("removing src dir [%s]", srcDirPath))
+	if err = op.Remove(t.Ctx, srcStorage, srcDirPath); err != nil {
+		return errors.WithMessagef(err, "failed remove src dir [%s]", srcDirPath)
+	}
+	if up != nil {
+		up(100)
+	}
+	return nil
+}
+
+func moveFileBetween2Storages(t *task.Task[uint64], srcStorage, dstStorage driver.Driver, srcFile model.Obj, srcFilePath, dstDirPath string, up driver.UpdateProgress) error {
+	copied, err := crossCopy(t, srcStorage, dstStorage, srcFilePath, dstDirPath)
+	if err != nil {
+		return err
+	}
+	if !copied {
+		t.SetStatus(fmt.Sprintf("copying [%s]", srcFilePath))
+		link, _, err := op.Link(t.Ctx, srcStorage, srcFilePath, model.LinkArgs{})
+		if err != nil {
+			return errors.WithMessagef(err, "failed get [%s] link", srcFilePath)
+		}
+		stream, err := getFileStreamFromLink(srcFile, link)
+		if err != nil {
+			return errors.WithMessagef(err, "failed get [%s] stream", srcFilePath)
+		}
+		limiters := transferLimiters(srcStorage.GetStorage(), dstStorage.GetStorage())
+		if err = op.Put(t.Ctx, dstStorage, dstDirPath, limitStream(t.Ctx, stream, limiters), up); err != nil {
+			return errors.WithMessagef(err, "failed put [%s]", srcFilePath)
+		}
+	}
+	if utils.IsCanceled(t.Ctx) {
+		return nil
+	}
+	t.SetStatus(fmt.Sprintf("verifying [%s]", srcFilePath))
+	dstFilePath := stdpath.Join(dstDirPath, srcFile.GetName())
+	op.ClearCache(dstStorage, dstDirPath)
+	dstFile, err := op.Get(t.Ctx, dstStorage, dstFilePath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get dst [%s] file", dstFilePath)
+	}
+	if srcFile.GetSize() >= 0 && dstFile.GetSize() != srcFile.GetSize() {
+		return errors.Errorf("size of dst [%s] is %d, but the src is %d", dstFilePath, dstFile.GetSize(), srcFile.GetSize())
+	}
+	t.SetStatus(fmt.Sprintf("removing src [%s]", srcFilePath))
+	if err = op.Remove(t.Ctx, srcStorage, srcFilePath); err != nil {
+		return errors.WithMessagef(err, "failed remove src [%s]", srcFilePath)
+	}
+	return nil
+}
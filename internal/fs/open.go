@@ -0,0 +1,22 @@
+package fs
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// open get the stream of the file to read the content in the server,
+// the stream is limited by the download rate limits of the storage and the user
+func open(ctx context.Context, path string) (model.FileStreamer, error) {
+	link, file, err := link(ctx, path, model.LinkArgs{})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get link")
+	}
+	stream, err := getFileStreamFromLink(file, link)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get stream")
+	}
+	return limitStream(ctx, stream, DownloadLimiters(ctx, path)), nil
+}
@@ -3,56 +3,96 @@ package fs
 import (
 	"context"
 
-	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/hook"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/pkg/errors"
 )
 
 func makeDir(ctx context.Context, path string) error {
+	if err := checkAcl(ctx, model.AclWrite, path); err != nil {
+		return err
+	}
 	storage, actualPath, err := op.GetStorageAndActualPath(path)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
-	return op.MakeDir(ctx, storage, actualPath)
-}
-
-func move(ctx context.Context, srcPath, dstDirPath string) error {
-	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
-	if err != nil {
-		return errors.WithMessage(err, "failed get src storage")
-	}
-	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
-	if err != nil {
-		return errors.WithMessage(err, "failed get dst storage")
-	}
-	if srcStorage.GetStorage() != dstStorage.GetStorage() {
-		return errors.WithStack(errs.MoveBetweenTwoStorages)
+	if err := op.CheckWrite(storage); err != nil {
+		return err
 	}
-	return op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath)
+	return op.MakeDir(ctx, storage, actualPath)
 }
 
 func rename(ctx context.Context, srcPath, dstName string) error {
+	srcPath = hook.RealPath(ctx, srcPath)
+	if err := checkAcl(ctx, model.AclWrite, srcPath); err != nil {
+		return err
+	}
 	storage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
+	if err := op.CheckModify(storage); err != nil {
+		return err
+	}
 	return op.Rename(ctx, storage, srcActualPath, dstName)
 }
 
 func remove(ctx context.Context, path string) error {
+	path = hook.RealPath(ctx, path)
+	if err := checkAcl(ctx, model.AclDelete, path); err != nil {
+		return err
+	}
 	storage, actualPath, err := op.GetStorageAndActualPath(path)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
+	if err := op.CheckModify(storage); err != nil {
+		return err
+	}
 	return op.Remove(ctx, storage, actualPath)
 }
 
+func batchRemove(ctx context.Context, dirPath string, names []string) error {
+	dirPath, names = realNames(ctx, dirPath, names)
+	if err := checkAclNames(ctx, model.AclDelete, dirPath, names); err != nil {
+		return err
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(dirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if err := op.CheckModify(storage); err != nil {
+		return err
+	}
+	return op.BatchRemove(ctx, storage, actualPath, names)
+}
+
 func other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
+	if err := checkAcl(ctx, model.AclRead, args.Path); err != nil {
+		return nil, err
+	}
 	storage, actualPath, err := op.GetStorageAndActualPath(args.Path)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
+	if err := op.CheckRead(storage); err != nil {
+		return nil, err
+	}
 	args.Path = actualPath
 	return op.Other(ctx, storage, args)
 }
+
+func videoPreview(ctx context.Context, path string) (*model.VideoPreview, error) {
+	if err := checkAcl(ctx, model.AclRead, path); err != nil {
+		return nil, err
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	if err := op.CheckRead(storage); err != nil {
+		return nil, err
+	}
+	return op.VideoPreview(ctx, storage, actualPath)
+}
@@ -3,11 +3,13 @@ package fs
 import (
 	"context"
 	"fmt"
+	stdpath "path"
 	"sync/atomic"
 
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
 	"github.com/alist-org/alist/v3/pkg/task"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
@@ -18,7 +20,7 @@ var UploadTaskManager = task.NewTaskManager(3, func(tid *uint64) {
 })
 
 // putAsTask add as a put task and return immediately
-func putAsTask(dstDirPath string, file model.FileStreamer) error {
+func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) error {
 	storage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
@@ -26,6 +28,13 @@ func putAsTask(dstDirPath string, file model.FileStreamer) error {
 	if storage.Config().NoUpload {
 		return errors.WithStack(errs.UploadNotSupported)
 	}
+	if err := op.CheckWrite(storage); err != nil {
+		return err
+	}
+	user, _ := ctx.Value("user").(*model.User)
+	if err := ratelimit.CheckUpload(user); err != nil {
+		return err
+	}
 	if file.NeedStore() {
 		tempFile, err := utils.CreateTempFile(file)
 		if err != nil {
@@ -33,10 +42,12 @@ func putAsTask(dstDirPath string, file model.FileStreamer) error {
 		}
 		file.SetReadCloser(tempFile)
 	}
+	// the task outlives the request, so only the user is kept for the limits and the traffic quota
+	limiters := ratelimit.Upload(storage.GetStorage(), user)
 	UploadTaskManager.Submit(task.WithCancelCtx(&task.Task[uint64]{
 		Name: fmt.Sprintf("upload %s to [%s](%s)", file.GetName(), storage.GetStorage().MountPath, dstDirActualPath),
 		Func: func(task *task.Task[uint64]) error {
-			return op.Put(task.Ctx, storage, dstDirActualPath, file, nil)
+			return op.Put(task.Ctx, storage, dstDirActualPath, limitStream(task.Ctx, file, limiters), nil)
 		},
 	}))
 	return nil
@@ -44,6 +55,9 @@ func putAsTask(dstDirPath string, file model.FileStreamer) error {
 
 // putDirect put the file and return after finish
 func putDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer) error {
+	if err := checkAcl(ctx, model.AclWrite, dstDirPath); err != nil {
+		return err
+	}
 	storage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
@@ -51,5 +65,47 @@ func putDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 	if storage.Config().NoUpload {
 		return errors.WithStack(errs.UploadNotSupported)
 	}
-	return op.Put(ctx, storage, dstDirActualPath, file, nil)
+	if err := op.CheckWrite(storage); err != nil {
+		return err
+	}
+	user, _ := ctx.Value("user").(*model.User)
+	if err := ratelimit.CheckUpload(user); err != nil {
+		return err
+	}
+	limiters := ratelimit.Upload(storage.GetStorage(), user)
+	return op.Put(ctx, storage, dstDirActualPath, limitStream(ctx, file, limiters), nil)
+}
+
+// directUpload get the urls the client uploads the file to the storage with directly
+func directUpload(ctx context.Context, path string, size int64) (*model.DirectUpload, error) {
+	dstDirPath, name := stdpath.Split(path)
+	if err := checkAcl(ctx, model.AclWrite, dstDirPath); err != nil {
+		return nil, err
+	}
+	storage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	if storage.Config().NoUpload {
+		return nil, errors.WithStack(errs.UploadNotSupported)
+	}
+	if err := op.CheckWrite(storage); err != nil {
+		return nil, err
+	}
+	return op.DirectUpload(ctx, storage, dstDirActualPath, name, size)
+}
+
+func completeDirectUpload(ctx context.Context, path, uploadID string, parts []model.DirectUploadPart) error {
+	dstDirPath, name := stdpath.Split(path)
+	if err := checkAcl(ctx, model.AclWrite, dstDirPath); err != nil {
+		return err
+	}
+	storage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if err := op.CheckWrite(storage); err != nil {
+		return err
+	}
+	return op.CompleteDirectUpload(ctx, storage, dstDirActualPath, name, uploadID, parts)
 }
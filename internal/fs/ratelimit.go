@@ -0,0 +1,56 @@
+package fs
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+)
+
+func storageAndUser(ctx context.Context, path string) (*model.Storage, *model.User) {
+	user, _ := ctx.Value("user").(*model.User)
+	storage, _, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, user
+	}
+	return storage.GetStorage(), user
+}
+
+// DownloadLimiters get the limiters of downloading the file in `path` by the user in ctx
+func DownloadLimiters(ctx context.Context, path string) []*ratelimit.Limiter {
+	return ratelimit.Download(storageAndUser(ctx, path))
+}
+
+// CheckDownloadQuota check if the user in ctx can download more, errs.TrafficQuotaExceeded if not
+func CheckDownloadQuota(ctx context.Context) error {
+	user, _ := ctx.Value("user").(*model.User)
+	return ratelimit.CheckDownload(user)
+}
+
+// limitedStream limit the speed of reading the stream, the ReadCloser
+// got by GetReadCloser is not limited, so that the drivers can check its type
+type limitedStream struct {
+	model.FileStreamer
+	ctx      context.Context
+	limiters []*ratelimit.Limiter
+}
+
+func (s *limitedStream) Read(p []byte) (int, error) {
+	n, err := s.FileStreamer.Read(p)
+	if n > 0 {
+		if e := ratelimit.WaitN(s.ctx, n, s.limiters...); e != nil {
+			return n, e
+		}
+	}
+	return n, err
+}
+
+func limitStream(ctx context.Context, file model.FileStreamer, limiters []*ratelimit.Limiter) model.FileStreamer {
+	return &limitedStream{FileStreamer: file, ctx: ctx, limiters: limiters}
+}
+
+// transferLimiters get the limiters of copying the files between two storages by a task
+func transferLimiters(srcStorage, dstStorage *model.Storage) []*ratelimit.Limiter {
+	return append(ratelimit.Download(srcStorage, nil), ratelimit.Upload(dstStorage, nil)...)
+}
@@ -0,0 +1,53 @@
+package fs
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/pkg/errors"
+)
+
+// the trash belongs to the whole storage, so the path is only used to find the storage
+
+func listTrash(ctx context.Context, path string) ([]model.Obj, error) {
+	if err := checkAcl(ctx, model.AclRead, path); err != nil {
+		return nil, err
+	}
+	storage, _, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	if err := op.CheckRead(storage); err != nil {
+		return nil, err
+	}
+	return op.ListTrash(ctx, storage)
+}
+
+func restoreTrash(ctx context.Context, path string, ids []string) error {
+	if err := checkAcl(ctx, model.AclWrite, path); err != nil {
+		return err
+	}
+	storage, _, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if err := op.CheckModify(storage); err != nil {
+		return err
+	}
+	return op.RestoreTrash(ctx, storage, ids)
+}
+
+func purgeTrash(ctx context.Context, path string, ids []string) error {
+	if err := checkAcl(ctx, model.AclDelete, path); err != nil {
+		return err
+	}
+	storage, _, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if err := op.CheckModify(storage); err != nil {
+		return err
+	}
+	return op.PurgeTrash(ctx, storage, ids)
+}
@@ -0,0 +1,179 @@
+package fs
+
+import (
+	"context"
+	"io"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the upload sessions that no chunk received for this duration are cleared
+const uploadExpiration = 24 * time.Hour
+
+// the sessions being written or completed, to prevent the concurrent requests of the same session
+var uploadLocks sync.Map
+
+func lockUpload(id string) (func(), error) {
+	l, _ := uploadLocks.LoadOrStore(id, &sync.Mutex{})
+	mu := l.(*sync.Mutex)
+	if !mu.TryLock() {
+		return nil, errors.WithStack(errs.UploadSessionBusy)
+	}
+	return mu.Unlock, nil
+}
+
+func uploadFilePath(id string) string {
+	return filepath.Join(conf.Conf.UploadDir, id)
+}
+
+// createUpload create an upload session of the file at `path`
+func createUpload(session *model.UploadSession) error {
+	clearExpiredUploads()
+	storage, _, err := op.GetStorageAndActualPath(stdpath.Dir(session.Path))
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if err := op.CheckWrite(storage); err != nil {
+		return err
+	}
+	session.ID = uuid.NewString()
+	session.Offset = 0
+	f, err := os.Create(uploadFilePath(session.ID))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_ = f.Close()
+	if err := db.CreateUploadSession(session); err != nil {
+		_ = os.Remove(uploadFilePath(session.ID))
+		return err
+	}
+	return nil
+}
+
+// uploadChunk append the chunk to the session, offset must be the count of the bytes received,
+// the bytes received are kept even if the reading of the chunk is broken, so the client can resume
+func uploadChunk(session *model.UploadSession, offset int64, r io.Reader) error {
+	unlock, err := lockUpload(session.ID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if offset != session.Offset {
+		return errors.WithStack(errs.UploadOffsetMismatch)
+	}
+	f, err := os.OpenFile(uploadFilePath(session.ID), os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	// drop the bytes written by the broken request that are not recorded
+	if err = f.Truncate(offset); err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return errors.WithStack(err)
+	}
+	remain := session.Size - offset
+	n, err := io.Copy(f, io.LimitReader(r, remain+1))
+	if n > remain {
+		n = remain
+		if e := f.Truncate(session.Size); e != nil {
+			return errors.WithStack(e)
+		}
+		err = errs.UploadSizeExceeded
+	}
+	if n > 0 {
+		session.Offset += n
+		if e := db.UpdateUploadSession(session); e != nil {
+			return e
+		}
+	}
+	return errors.WithStack(err)
+}
+
+// completeUpload put the file of the session to the storage,
+// the session is kept if it fails to put directly, so the client can retry
+func completeUpload(ctx context.Context, session *model.UploadSession) error {
+	unlock, err := lockUpload(session.ID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if session.Offset != session.Size {
+		return errors.WithStack(errs.UploadNotCompleted)
+	}
+	f, err := os.Open(uploadFilePath(session.ID))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	stream := &model.FileStream{
+		Obj: &model.Object{
+			Name:     stdpath.Base(session.Path),
+			Size:     session.Size,
+			Modified: time.Now(),
+		},
+		Mimetype: session.Mimetype,
+		Sha1:     session.Sha1,
+	}
+	dir := stdpath.Dir(session.Path)
+	if session.AsTask {
+		// the file is already stored, and it's removed after the task finished
+		stream.ReadCloser = f
+		err = putAsTask(ctx, dir, stream)
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
+		uploadLocks.Delete(session.ID)
+		return db.DeleteUploadSessionById(session.ID)
+	}
+	// the file isn't removed by put if it's not *os.File
+	stream.ReadCloser = struct{ *os.File }{f}
+	err = putDirectly(ctx, dir, stream)
+	if err != nil {
+		return err
+	}
+	return removeUpload(session.ID)
+}
+
+func cancelUpload(session *model.UploadSession) error {
+	unlock, err := lockUpload(session.ID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return removeUpload(session.ID)
+}
+
+func removeUpload(id string) error {
+	uploadLocks.Delete(id)
+	if err := os.Remove(uploadFilePath(id)); err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	return db.DeleteUploadSessionById(id)
+}
+
+func clearExpiredUploads() {
+	sessions, err := db.GetUploadSessionsBefore(time.Now().Add(-uploadExpiration))
+	if err != nil {
+		log.Errorf("failed get expired upload sessions: %+v", err)
+		return
+	}
+	for _, s := range sessions {
+		if err := removeUpload(s.ID); err != nil {
+			log.Errorf("failed remove expired upload session %s: %+v", s.ID, err)
+		}
+	}
+}
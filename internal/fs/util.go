@@ -1,6 +1,7 @@
 package fs
 
 import (
+	"context"
 	"io"
 	"mime"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	stdpath "path"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/hook"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/pkg/errors"
@@ -21,6 +23,16 @@ func ClearCache(path string) {
 	op.ClearCache(storage, actualPath)
 }
 
+// realNames map the dir and the names in it renamed by the hooks back to the real ones
+func realNames(ctx context.Context, dir string, names []string) (string, []string) {
+	dir = hook.RealPath(ctx, dir)
+	res := make([]string, 0, len(names))
+	for _, name := range names {
+		res = append(res, stdpath.Base(hook.RealPath(ctx, stdpath.Join(dir, name))))
+	}
+	return dir, res
+}
+
 func containsByName(files []model.Obj, file model.Obj) bool {
 	for _, f := range files {
 		if f.GetName() == file.GetName() {
@@ -42,7 +54,8 @@ func getFileStreamFromLink(file model.Obj, link *model.Link) (model.FileStreamer
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to open file %s", *link.FilePath)
 		}
-		rc = f
+		// wrap it so that the source file isn't removed by op.Put
+		rc = struct{ *os.File }{f}
 	} else {
 		req, err := http.NewRequest(http.MethodGet, link.URL, nil)
 		if err != nil {
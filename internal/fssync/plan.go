@@ -0,0 +1,119 @@
+// Package fssync sync the objects in a dir to another dir, which can be in another storage,
+// the differences are found by comparing the trees, and transferred by the tasks
+package fssync
+
+import (
+	"context"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ActionCopy copy the src object that not in dst
+	ActionCopy = "copy"
+	// ActionUpdate replace the dst file that different from the src one
+	ActionUpdate = "update"
+	// ActionDelete delete the dst object that not in src
+	ActionDelete = "delete"
+)
+
+// the mtime of some providers are only accurate to the second
+const mtimeTolerance = 2 * time.Second
+
+type Action struct {
+	Type string `json:"type"`
+	// the path relative to the src and dst dir
+	Path   string `json:"path"`
+	IsDir  bool   `json:"is_dir"`
+	Size   int64  `json:"size"`
+	Reason string `json:"reason"`
+}
+
+type Args struct {
+	SrcPath string `json:"src_path" binding:"required"`
+	DstPath string `json:"dst_path" binding:"required"`
+	// delete the objects in dst that not in src, that is mirror the src
+	Delete bool `json:"delete"`
+}
+
+// Plan compare the src and dst dir recursively, and get the actions to make dst same as src,
+// the user in ctx must be able to see all the objects
+func Plan(ctx context.Context, args Args) ([]Action, error) {
+	ctx = context.WithValue(ctx, "meta", (*model.Meta)(nil))
+	var actions []Action
+	err := compareDir(ctx, args, "/", &actions)
+	if err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
+func compareDir(ctx context.Context, args Args, rel string, actions *[]Action) error {
+	srcObjs, err := fs.List(ctx, stdpath.Join(args.SrcPath, rel), true)
+	if err != nil {
+		return errors.WithMessagef(err, "failed list src [%s]", rel)
+	}
+	dstObjs, err := fs.List(ctx, stdpath.Join(args.DstPath, rel), true)
+	if err != nil && !errs.IsObjectNotFound(err) {
+		return errors.WithMessagef(err, "failed list dst [%s]", rel)
+	}
+	dstMap := make(map[string]model.Obj, len(dstObjs))
+	for _, obj := range dstObjs {
+		dstMap[obj.GetName()] = obj
+	}
+	for _, src := range srcObjs {
+		p := stdpath.Join(rel, src.GetName())
+		dst, ok := dstMap[src.GetName()]
+		delete(dstMap, src.GetName())
+		if !ok {
+			*actions = append(*actions, Action{Type: ActionCopy, Path: p, IsDir: src.IsDir(), Size: src.GetSize(), Reason: "not in dst"})
+			continue
+		}
+		if src.IsDir() != dst.IsDir() {
+			// replace the dst object of the other type
+			*actions = append(*actions, Action{Type: ActionDelete, Path: p, IsDir: dst.IsDir(), Size: dst.GetSize(), Reason: "type changed"})
+			*actions = append(*actions, Action{Type: ActionCopy, Path: p, IsDir: src.IsDir(), Size: src.GetSize(), Reason: "type changed"})
+			continue
+		}
+		if src.IsDir() {
+			if err := compareDir(ctx, args, p, actions); err != nil {
+				return err
+			}
+			continue
+		}
+		if reason := diff(src, dst); reason != "" {
+			*actions = append(*actions, Action{Type: ActionUpdate, Path: p, Size: src.GetSize(), Reason: reason})
+		}
+	}
+	if args.Delete {
+		for _, dst := range dstObjs {
+			if _, ok := dstMap[dst.GetName()]; ok {
+				*actions = append(*actions, Action{Type: ActionDelete, Path: stdpath.Join(rel, dst.GetName()), IsDir: dst.IsDir(), Size: dst.GetSize(), Reason: "not in src"})
+			}
+		}
+	}
+	return nil
+}
+
+// diff get the reason why the dst file should be updated, empty if they are the same
+func diff(src, dst model.Obj) string {
+	srcHash, dstHash := src.GetHash(), dst.GetHash()
+	if !srcHash.Empty() && srcHash.Type == dstHash.Type && !dstHash.Empty() {
+		if srcHash.Value != dstHash.Value {
+			return "hash changed"
+		}
+		return ""
+	}
+	if src.GetSize() != dst.GetSize() {
+		return "size changed"
+	}
+	if src.ModTime().Sub(dst.ModTime()) > mtimeTolerance {
+		return "src is newer"
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package fssync
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"sync/atomic"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/pkg/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+var SyncTaskManager = task.NewTaskManager(1, func(tid *uint64) {
+	atomic.AddUint64(tid, 1)
+})
+
+// Submit add a task to plan and execute the sync
+func Submit(args Args) uint64 {
+	return SyncTaskManager.Submit(task.WithCancelCtx(&task.Task[uint64]{
+		Name: fmt.Sprintf("sync [%s] to [%s]", args.SrcPath, args.DstPath),
+		Func: func(t *task.Task[uint64]) error {
+			return run(t, args)
+		},
+	}))
+}
+
+func run(t *task.Task[uint64], args Args) error {
+	admin, err := db.GetAdmin()
+	if err != nil {
+		return errors.WithMessage(err, "failed get admin")
+	}
+	ctx := context.WithValue(t.Ctx, "user", admin)
+	t.SetStatus("comparing")
+	actions, err := Plan(ctx, args)
+	if err != nil {
+		return err
+	}
+	// delete first to free the space, and the dst of the type changed objects
+	var ordered []Action
+	for _, a := range actions {
+		if a.Type == ActionDelete {
+			ordered = append(ordered, a)
+		}
+	}
+	for _, a := range actions {
+		if a.Type != ActionDelete {
+			ordered = append(ordered, a)
+		}
+	}
+	tasks := 0
+	for i, a := range ordered {
+		if utils.IsCanceled(t.Ctx) {
+			return nil
+		}
+		t.SetStatus(fmt.Sprintf("%s [%s]", a.Type, a.Path))
+		added, err := execute(ctx, args, a)
+		if err != nil {
+			return errors.WithMessagef(err, "failed %s [%s]", a.Type, a.Path)
+		}
+		if added {
+			tasks++
+		}
+		t.SetProgress((i + 1) * 100 / len(ordered))
+	}
+	fs.ClearCache(args.DstPath)
+	t.SetStatus(fmt.Sprintf("done %d actions, added %d copy tasks", len(ordered), tasks))
+	return nil
+}
+
+// execute the action, return true if a copy task is added
+func execute(ctx context.Context, args Args, a Action) (bool, error) {
+	src := stdpath.Join(args.SrcPath, a.Path)
+	dst := stdpath.Join(args.DstPath, a.Path)
+	switch a.Type {
+	case ActionDelete:
+		return false, fs.Remove(ctx, dst)
+	case ActionUpdate:
+		// not all the drivers overwrite the existing file
+		if err := fs.Remove(ctx, dst); err != nil {
+			return false, err
+		}
+		fallthrough
+	case ActionCopy:
+		dstDir := stdpath.Dir(dst)
+		if err := fs.MakeDir(ctx, dstDir); err != nil {
+			return false, err
+		}
+		return fs.Copy(ctx, src, dstDir)
+	}
+	return false, errors.Errorf("invalid action: %s", a.Type)
+}
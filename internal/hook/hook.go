@@ -0,0 +1,307 @@
+// Package hook run the scripts of the admins on the objects listed and the links got,
+// to hide or rename the objects, block the files or add the headers per path
+package hook
+
+import (
+	"context"
+	"net/http"
+	stdpath "path"
+	"strings"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type compiled struct {
+	model.Hook
+	script *Script
+}
+
+// the hooks are compiled and loaded in memory since they are run with every list
+var (
+	hooksMu sync.RWMutex
+	hooks   []compiled
+)
+
+// LoadHooks load the enabled hooks from the database, the ones failed to compile are skipped
+func LoadHooks() error {
+	res, err := db.GetHooks()
+	if err != nil {
+		return err
+	}
+	var loaded []compiled
+	for _, h := range res {
+		if h.Disabled {
+			continue
+		}
+		s, err := Parse(h.Script)
+		if err != nil {
+			log.Errorf("failed compile the script of hook [%s]: %+v", h.Name, err)
+			continue
+		}
+		loaded = append(loaded, compiled{Hook: h, script: s})
+	}
+	hooksMu.Lock()
+	hooks = loaded
+	hooksMu.Unlock()
+	return nil
+}
+
+func check(h *model.Hook) error {
+	h.Path = utils.StandardizePath(h.Path)
+	_, err := Parse(h.Script)
+	return errors.WithMessage(err, "invalid script")
+}
+
+func CreateHook(h *model.Hook) error {
+	if err := check(h); err != nil {
+		return err
+	}
+	if err := db.CreateHook(h); err != nil {
+		return err
+	}
+	return LoadHooks()
+}
+
+func UpdateHook(h *model.Hook) error {
+	if _, err := db.GetHookById(h.ID); err != nil {
+		return err
+	}
+	if err := check(h); err != nil {
+		return err
+	}
+	if err := db.UpdateHook(h); err != nil {
+		return err
+	}
+	return LoadHooks()
+}
+
+func DeleteHookById(id uint) error {
+	if err := db.DeleteHookById(id); err != nil {
+		return err
+	}
+	return LoadHooks()
+}
+
+// result is the result of the rules run on an object
+type result struct {
+	name    string
+	hidden  bool
+	blocked bool
+	header  http.Header
+}
+
+// env is the env the rules run in, the user requesting and the uploaders of the objects in the dir
+type env struct {
+	dir       string
+	user      *model.User
+	uploaders map[string]string
+	loaded    bool
+}
+
+func newEnv(ctx context.Context, dir string) *env {
+	user, _ := ctx.Value("user").(*model.User)
+	return &env{dir: dir, user: user}
+}
+
+// uploader get the uploader of the object in the dir, the uploaders are loaded once
+func (e *env) uploader(name string) string {
+	if !e.loaded {
+		e.loaded = true
+		uploaders, err := db.GetUploaders(e.dir)
+		if err != nil {
+			log.Warnf("failed get the uploaders in [%s]: %+v", e.dir, err)
+		}
+		e.uploaders = uploaders
+	}
+	return e.uploaders[name]
+}
+
+// eval run the rules of the hooks on the object in the dir of the env in order,
+// the object is renamed by the rules before, so the rules after see the new name
+func eval(hs []compiled, e *env, obj model.Obj) result {
+	dir := e.dir
+	res := result{name: obj.GetName()}
+	t := target{
+		name:  obj.GetName(),
+		path:  stdpath.Join(dir, obj.GetName()),
+		size:  obj.GetSize(),
+		isDir: obj.IsDir(),
+		user:  e.user,
+		uploader: func() string {
+			return e.uploader(obj.GetName())
+		},
+	}
+	for i := range hs {
+		if !utils.IsSubPath(hs[i].Path, t.path) {
+			continue
+		}
+		for j := range hs[i].script.rules {
+			r := &hs[i].script.rules[j]
+			if !r.match(t) {
+				continue
+			}
+			switch r.action {
+			case actionHide:
+				res.hidden = true
+			case actionBlock:
+				res.blocked = true
+			case actionRename:
+				// the object can't be renamed to empty
+				if name := r.re.ReplaceAllString(t.name, r.repl); name != "" && !strings.Contains(name, "/") {
+					t.name = name
+					t.path = stdpath.Join(dir, name)
+				}
+			case actionHeader:
+				if res.header == nil {
+					res.header = http.Header{}
+				}
+				res.header.Add(r.key, r.value)
+			}
+		}
+	}
+	res.name = t.name
+	return res
+}
+
+func loaded() []compiled {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	return hooks
+}
+
+// renamedObj is the object renamed by the hooks, it's only shown to the users,
+// the paths to it are mapped back to the real ones by RealPath
+type renamedObj struct {
+	model.Obj
+	name string
+}
+
+func (o *renamedObj) GetName() string {
+	return o.name
+}
+
+func (o *renamedObj) Thumb() string {
+	if t, ok := o.Obj.(model.Thumb); ok {
+		return t.Thumb()
+	}
+	return ""
+}
+
+func display(obj model.Obj, name string) model.Obj {
+	if name == obj.GetName() {
+		return obj
+	}
+	return &renamedObj{Obj: obj, name: name}
+}
+
+// Filter run the hooks on the objects listed in the dir, the hidden ones are removed
+// unless the user in ctx can see the hides, and the renamed ones are wrapped with the new names
+func Filter(ctx context.Context, dir string, objs []model.Obj) []model.Obj {
+	hs := loaded()
+	if len(hs) == 0 {
+		return objs
+	}
+	e := newEnv(ctx, dir)
+	canSeeHides := e.user != nil && e.user.CanSeeHides()
+	res := make([]model.Obj, 0, len(objs))
+	for _, obj := range objs {
+		r := eval(hs, e, obj)
+		if r.hidden && !canSeeHides {
+			continue
+		}
+		res = append(res, display(obj, r.name))
+	}
+	return res
+}
+
+// Display get the object shown to the users, it's renamed if the hooks rename it
+func Display(ctx context.Context, path string, obj model.Obj) model.Obj {
+	hs := loaded()
+	if len(hs) == 0 || utils.PathEqual(path, "/") {
+		return obj
+	}
+	return display(obj, eval(hs, newEnv(ctx, stdpath.Dir(path)), obj).name)
+}
+
+// Link run the hooks on the file linked, the link is refused if the file is blocked,
+// the headers of the hooks are added to a copy of the link since the link may be cached
+func Link(ctx context.Context, path string, file model.Obj, link *model.Link) (*model.Link, error) {
+	hs := loaded()
+	if len(hs) == 0 {
+		return link, nil
+	}
+	r := eval(hs, newEnv(ctx, stdpath.Dir(path)), file)
+	if r.blocked {
+		return nil, errors.WithMessagef(errs.PermissionDenied, "[%s] is blocked by the hooks", path)
+	}
+	if len(r.header) == 0 {
+		return link, nil
+	}
+	l := *link
+	l.Header = link.Header.Clone()
+	if l.Header == nil {
+		l.Header = http.Header{}
+	}
+	for k, v := range r.header {
+		l.Header[k] = v
+	}
+	return &l, nil
+}
+
+// renames check if the objects in the dir may be renamed by the hooks
+func renames(hs []compiled, dir string) bool {
+	for i := range hs {
+		if !utils.IsSubPath(hs[i].Path, dir) && stdpath.Dir(hs[i].Path) != dir {
+			continue
+		}
+		for _, r := range hs[i].script.rules {
+			if r.action == actionRename {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RealPath map the path with the names renamed by the hooks back to the real path,
+// the dirs with the renamed objects are listed to find the real names
+func RealPath(ctx context.Context, path string) string {
+	hs := loaded()
+	if len(hs) == 0 || utils.PathEqual(path, "/") {
+		return path
+	}
+	cur := "/"
+	for _, name := range strings.Split(strings.TrimPrefix(utils.StandardizePath(path), "/"), "/") {
+		if renames(hs, cur) {
+			name = realName(ctx, hs, newEnv(ctx, cur), name)
+		}
+		cur = stdpath.Join(cur, name)
+	}
+	return cur
+}
+
+// realName find the real name of the object shown as the name in the dir, it's the name if not found
+func realName(ctx context.Context, hs []compiled, e *env, name string) string {
+	dir := e.dir
+	storage, actualPath, err := op.GetStorageAndActualPath(dir)
+	if err != nil {
+		return name
+	}
+	objs, err := op.List(ctx, storage, actualPath, model.ListArgs{ReqPath: dir})
+	if err != nil {
+		return name
+	}
+	for _, obj := range objs {
+		if eval(hs, e, obj).name == name {
+			return obj.GetName()
+		}
+	}
+	return name
+}
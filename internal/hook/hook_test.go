@@ -0,0 +1,104 @@
+package hook
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestParse(t *testing.T) {
+	invalid := []string{
+		`hide if`,
+		`hide when is_dir`,
+		`rename "(" ""`,
+		`rename "a"`,
+		`header "X-A" "b`,
+		`unknown`,
+		`hide if size > 1XB`,
+		`hide if ext in (a, b`,
+		`hide if name == "a"`,
+	}
+	for _, s := range invalid {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("expected the error of %s", s)
+		}
+	}
+	s, err := Parse("# comment\n\nhide if ext in (nfo, .TXT) and size < 1KB # hide the small\nblock if is_file and path =~ \"^/mnt/private/\"")
+	if err != nil {
+		t.Fatalf("failed parse: %+v", err)
+	}
+	if len(s.rules) != 2 || len(s.rules[0].conds) != 2 {
+		t.Errorf("unexpected rules: %+v", s.rules)
+	}
+}
+
+func TestEval(t *testing.T) {
+	s, err := Parse(`hide if ext in (nfo) and size < 1KB
+rename "^\d+\. " ""
+rename "\.mkv$" ".video.mkv" if is_file
+block if name =~ "^secret" and size >= 1MB
+header "x-token" "abc" if ext not in (nfo)
+hide if is_dir and name !~ "^[A-Z]"`)
+	if err != nil {
+		t.Fatalf("failed parse: %+v", err)
+	}
+	hs := []compiled{{Hook: model.Hook{Path: "/mnt"}, script: s}}
+	tests := []struct {
+		dir  string
+		obj  model.Obj
+		want result
+	}{
+		{"/mnt", &model.Object{Name: "a.nfo", Size: 10}, result{name: "a.nfo", hidden: true}},
+		{"/mnt", &model.Object{Name: "a.nfo", Size: 2048}, result{name: "a.nfo"}},
+		{"/mnt/sub", &model.Object{Name: "01. movie.mkv"}, result{name: "movie.video.mkv"}},
+		{"/mnt", &model.Object{Name: "secret.zip", Size: 1 << 20}, result{name: "secret.zip", blocked: true}},
+		{"/mnt", &model.Object{Name: "dir", IsFolder: true}, result{name: "dir", hidden: true}},
+		{"/other", &model.Object{Name: "a.nfo", Size: 10}, result{name: "a.nfo"}},
+	}
+	for _, tt := range tests {
+		got := eval(hs, &env{dir: tt.dir}, tt.obj)
+		header := got.header
+		if got.name != tt.want.name || got.hidden != tt.want.hidden || got.blocked != tt.want.blocked {
+			t.Errorf("eval(%s, %s) = %+v, want %+v", tt.dir, tt.obj.GetName(), got, tt.want)
+		}
+		if h := header.Get("X-Token"); (h == "abc") != (tt.dir != "/other" && tt.obj.GetName() != "a.nfo") {
+			t.Errorf("unexpected header of %s: %v", tt.obj.GetName(), header)
+		}
+	}
+}
+
+func TestMetaFilter(t *testing.T) {
+	if err := CheckMetaFilter(`rename "a" "b"`); err == nil {
+		t.Errorf("expected the error of rename in the filter of the meta")
+	}
+	meta := &model.Meta{Path: "/mnt", Filter: `hide if uploader in (bob) and role not in (admin)
+block if ext in (zip) and user not in (alice)`}
+	if err := CheckMetaFilter(meta.Filter); err != nil {
+		t.Fatalf("failed check: %+v", err)
+	}
+	if hs := metaHooks(meta, "/mnt/sub"); len(hs) != 0 {
+		t.Errorf("the filter shouldn't be applied to the sub dirs without f_sub")
+	}
+	hs := metaHooks(meta, "/mnt")
+	guest := &model.User{Username: "guest", Role: model.GUEST}
+	admin := &model.User{Username: "Alice", Role: model.ADMIN}
+	tests := []struct {
+		user *model.User
+		obj  model.Obj
+		want result
+	}{
+		{guest, &model.Object{Name: "a.txt"}, result{name: "a.txt", hidden: true}},
+		{admin, &model.Object{Name: "a.txt"}, result{name: "a.txt"}},
+		{guest, &model.Object{Name: "b.zip"}, result{name: "b.zip", blocked: true}},
+		{admin, &model.Object{Name: "b.zip"}, result{name: "b.zip"}},
+		{nil, &model.Object{Name: "c.txt"}, result{name: "c.txt"}},
+		{nil, &model.Object{Name: "c.zip"}, result{name: "c.zip", blocked: true}},
+	}
+	for _, tt := range tests {
+		e := &env{dir: "/mnt", user: tt.user, loaded: true, uploaders: map[string]string{"a.txt": "bob"}}
+		got := eval(hs, e, tt.obj)
+		if got.name != tt.want.name || got.hidden != tt.want.hidden || got.blocked != tt.want.blocked {
+			t.Errorf("eval(%v, %s) = %+v, want %+v", tt.user, tt.obj.GetName(), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package hook
+
+import (
+	"context"
+	stdpath "path"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the compiled filters of the metas by the scripts, the metas are cached by the db package
+var metaFilters sync.Map
+
+// CheckMetaFilter check the filter of the meta, only hide and block are allowed
+func CheckMetaFilter(src string) error {
+	s, err := Parse(src)
+	if err == nil {
+		err = s.only(actionHide, actionBlock)
+	}
+	return errors.WithMessage(err, "invalid filter")
+}
+
+// metaHooks get the filter of the meta applied to the objects in the dir as a hook
+func metaHooks(meta *model.Meta, dir string) []compiled {
+	if meta == nil || meta.Filter == "" || (!utils.PathEqual(meta.Path, dir) && !meta.FSub) {
+		return nil
+	}
+	var s *Script
+	if v, ok := metaFilters.Load(meta.Filter); ok {
+		s = v.(*Script)
+	} else {
+		var err error
+		if s, err = Parse(meta.Filter); err != nil {
+			log.Errorf("failed compile the filter of meta [%s]: %+v", meta.Path, err)
+			return nil
+		}
+		metaFilters.Store(meta.Filter, s)
+	}
+	return []compiled{{Hook: model.Hook{Path: meta.Path}, script: s}}
+}
+
+// FilterByMeta remove the objects in the dir hidden by the filter of the meta, there is no exemption
+// for the admins, the roles exempted are set by the conditions, such as `hide if ... and role not in (admin)`
+func FilterByMeta(ctx context.Context, meta *model.Meta, dir string, objs []model.Obj) []model.Obj {
+	hs := metaHooks(meta, dir)
+	if len(hs) == 0 {
+		return objs
+	}
+	e := newEnv(ctx, dir)
+	res := make([]model.Obj, 0, len(objs))
+	for _, obj := range objs {
+		if !eval(hs, e, obj).hidden {
+			res = append(res, obj)
+		}
+	}
+	return res
+}
+
+// CheckLinkByMeta return an error if the file is blocked by the filter of the nearest meta
+func CheckLinkByMeta(ctx context.Context, path string, file model.Obj) error {
+	dir := stdpath.Dir(path)
+	meta, err := db.GetNearestMeta(dir)
+	if err != nil {
+		if errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return nil
+		}
+		return err
+	}
+	hs := metaHooks(meta, dir)
+	if len(hs) > 0 && eval(hs, newEnv(ctx, dir), file).blocked {
+		return errors.WithMessagef(errs.PermissionDenied, "[%s] is blocked by the filter of the meta", path)
+	}
+	return nil
+}
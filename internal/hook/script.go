@@ -0,0 +1,431 @@
+package hook
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// the script is made of the rules, one in a line, the lines starting with # are comments:
+//
+//	<action> [args...] [if <cond> [and <cond>...]]
+//
+// the actions:
+//
+//	hide                          hide the object in the list
+//	block                         refuse to link the file
+//	rename "regexp" "replacement" rename the object in the list, such as rename "^\d+\. " ""
+//	header "Name" "value"         add the header to the request to the link of the file
+//
+// the conditions:
+//
+//	name =~ "regexp", name !~ "regexp"   match the name of the object
+//	path =~ "regexp", path !~ "regexp"   match the path of the object, such as /mnt/movies/a.mkv
+//	ext in (mkv, mp4), ext not in (...)  the extension of the file in lower case, without the dot
+//	size > 100MB, size < 1024, >= and <= the size of the file, the units are KB, MB, GB and TB
+//	is_dir, is_file
+//	role in (guest, general, admin)      the role of the user, the unknown user is the guest, and `not in`
+//	user in (alice, bob)                 the name of the user, and `not in`
+//	uploader in (alice, bob)             the user uploaded the file, it's known from the audit log
+
+const (
+	actionHide   = "hide"
+	actionBlock  = "block"
+	actionRename = "rename"
+	actionHeader = "header"
+)
+
+// the object the conditions are checked with
+type target struct {
+	name  string
+	path  string
+	size  int64
+	isDir bool
+	// the user requesting, it's nil if unknown, such as the downloads by /d, it's taken as the guest then
+	user *model.User
+	// get the uploader of the object, it's looked up only if the rules need it
+	uploader func() string
+}
+
+var roles = map[int]string{
+	model.GUEST:   "guest",
+	model.GENERAL: "general",
+	model.ADMIN:   "admin",
+}
+
+// value get the value of the field compared with the list
+func (t target) value(field string) string {
+	switch field {
+	case "ext":
+		return t.ext()
+	case "role":
+		if t.user == nil {
+			return roles[model.GUEST]
+		}
+		return roles[t.user.Role]
+	case "user":
+		if t.user != nil {
+			return strings.ToLower(t.user.Username)
+		}
+	case "uploader":
+		if t.uploader != nil {
+			return strings.ToLower(t.uploader())
+		}
+	}
+	return ""
+}
+
+func (t target) ext() string {
+	i := strings.LastIndex(t.name, ".")
+	if i < 0 || t.isDir {
+		return ""
+	}
+	return strings.ToLower(t.name[i+1:])
+}
+
+type cond func(t target) bool
+
+type rule struct {
+	action string
+	// the regexp and the replacement of rename
+	re   *regexp.Regexp
+	repl string
+	// the header to add
+	key, value string
+	conds      []cond
+}
+
+func (r *rule) match(t target) bool {
+	for _, c := range r.conds {
+		if !c(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Script is the compiled rules of a hook
+type Script struct {
+	rules []rule
+}
+
+// only check the script has only the actions
+func (s *Script) only(actions ...string) error {
+	for _, r := range s.rules {
+		if !utils.SliceContains(actions, r.action) {
+			return errors.Errorf("the action %s isn't allowed, only %s", r.action, strings.Join(actions, ", "))
+		}
+	}
+	return nil
+}
+
+// Parse compile the script, the line of the error is in the message
+func Parse(src string) (*Script, error) {
+	s := &Script{}
+	for i, line := range strings.Split(src, "\n") {
+		toks, err := tokenize(line)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "line %d", i+1)
+		}
+		if len(toks) == 0 {
+			continue
+		}
+		p := &parser{toks: toks}
+		r, err := p.rule()
+		if err != nil {
+			return nil, errors.WithMessagef(err, "line %d", i+1)
+		}
+		s.rules = append(s.rules, *r)
+	}
+	return s, nil
+}
+
+type token struct {
+	text string
+	// it's a quoted string
+	str bool
+}
+
+// tokenize split the line to the words, the quoted strings, the operators and the punctuations
+func tokenize(line string) ([]token, error) {
+	var toks []token
+	rs := []rune(line)
+	for i := 0; i < len(rs); {
+		c := rs[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '#':
+			return toks, nil
+		case c == '"':
+			j := i + 1
+			for ; j < len(rs) && rs[j] != '"'; j++ {
+				if rs[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(rs) {
+				return nil, errors.New("the string isn't closed")
+			}
+			// only the quote is escaped, the other backslashes are kept for the regexps, such as "\d"
+			s := strings.ReplaceAll(string(rs[i+1:j]), `\"`, `"`)
+			toks = append(toks, token{text: s, str: true})
+			i = j + 1
+		case c == '(' || c == ')' || c == ',':
+			toks = append(toks, token{text: string(c)})
+			i++
+		case strings.ContainsRune("=!<>~", c):
+			j := i
+			for j < len(rs) && strings.ContainsRune("=!<>~", rs[j]) {
+				j++
+			}
+			toks = append(toks, token{text: string(rs[i:j])})
+			i = j
+		default:
+			j := i
+			for j < len(rs) && !unicode.IsSpace(rs[j]) && !strings.ContainsRune(`"(),=!<>~#`, rs[j]) {
+				j++
+			}
+			toks = append(toks, token{text: string(rs[i:j])})
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) next() (token, bool) {
+	if p.pos >= len(p.toks) {
+		return token{}, false
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	return t, true
+}
+
+func (p *parser) word() (string, error) {
+	t, ok := p.next()
+	if !ok {
+		return "", errors.New("unexpected end of the rule")
+	}
+	if t.str {
+		return "", errors.Errorf("unexpected string %q", t.text)
+	}
+	return t.text, nil
+}
+
+func (p *parser) str() (string, error) {
+	t, ok := p.next()
+	if !ok {
+		return "", errors.New("a string is expected at the end of the rule")
+	}
+	if !t.str {
+		return "", errors.Errorf("a quoted string is expected, got %s", t.text)
+	}
+	return t.text, nil
+}
+
+func (p *parser) expect(s string) error {
+	w, err := p.word()
+	if err != nil {
+		return err
+	}
+	if w != s {
+		return errors.Errorf("%s is expected, got %s", s, w)
+	}
+	return nil
+}
+
+func (p *parser) rule() (*rule, error) {
+	action, err := p.word()
+	if err != nil {
+		return nil, err
+	}
+	r := &rule{action: action}
+	switch action {
+	case actionHide, actionBlock:
+	case actionRename:
+		expr, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		if r.re, err = regexp.Compile(expr); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if r.repl, err = p.str(); err != nil {
+			return nil, err
+		}
+	case actionHeader:
+		if r.key, err = p.str(); err != nil {
+			return nil, err
+		}
+		if r.value, err = p.str(); err != nil {
+			return nil, err
+		}
+		r.key = http.CanonicalHeaderKey(r.key)
+	default:
+		return nil, errors.Errorf("unknown action %s", action)
+	}
+	if p.pos >= len(p.toks) {
+		return r, nil
+	}
+	if err = p.expect("if"); err != nil {
+		return nil, err
+	}
+	for {
+		c, err := p.cond()
+		if err != nil {
+			return nil, err
+		}
+		r.conds = append(r.conds, c)
+		if p.pos >= len(p.toks) {
+			return r, nil
+		}
+		if err = p.expect("and"); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) cond() (cond, error) {
+	field, err := p.word()
+	if err != nil {
+		return nil, err
+	}
+	switch field {
+	case "is_dir":
+		return func(t target) bool { return t.isDir }, nil
+	case "is_file":
+		return func(t target) bool { return !t.isDir }, nil
+	case "name", "path":
+		op, err := p.word()
+		if err != nil {
+			return nil, err
+		}
+		if op != "=~" && op != "!~" {
+			return nil, errors.Errorf("=~ or !~ is expected after %s, got %s", field, op)
+		}
+		expr, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return func(t target) bool {
+			s := t.name
+			if field == "path" {
+				s = t.path
+			}
+			return re.MatchString(s) == (op == "=~")
+		}, nil
+	case "ext", "role", "user", "uploader":
+		not := false
+		w, err := p.word()
+		if err != nil {
+			return nil, err
+		}
+		if w == "not" {
+			not = true
+			if w, err = p.word(); err != nil {
+				return nil, err
+			}
+		}
+		if w != "in" {
+			return nil, errors.Errorf("in is expected after %s, got %s", field, w)
+		}
+		values, err := p.list()
+		if err != nil {
+			return nil, err
+		}
+		return func(t target) bool {
+			_, ok := values[t.value(field)]
+			return ok != not
+		}, nil
+	case "size":
+		op, err := p.word()
+		if err != nil {
+			return nil, err
+		}
+		w, err := p.word()
+		if err != nil {
+			return nil, err
+		}
+		n, err := parseSize(w)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case ">":
+			return func(t target) bool { return !t.isDir && t.size > n }, nil
+		case ">=":
+			return func(t target) bool { return !t.isDir && t.size >= n }, nil
+		case "<":
+			return func(t target) bool { return !t.isDir && t.size < n }, nil
+		case "<=":
+			return func(t target) bool { return !t.isDir && t.size <= n }, nil
+		}
+		return nil, errors.Errorf("invalid operator %s of size", op)
+	}
+	return nil, errors.Errorf("unknown condition %s", field)
+}
+
+// list parse the list like (mkv, mp4), the items are in lower case
+func (p *parser) list() (map[string]struct{}, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	res := make(map[string]struct{})
+	for {
+		t, ok := p.next()
+		if !ok {
+			return nil, errors.New("the list isn't closed")
+		}
+		if !t.str && t.text == ")" {
+			return res, nil
+		}
+		if !t.str && t.text == "," {
+			continue
+		}
+		res[strings.ToLower(strings.TrimPrefix(t.text, "."))] = struct{}{}
+	}
+}
+
+var sizeUnits = []struct {
+	suffix string
+	n      int64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+func parseSize(s string) (int64, error) {
+	unit := int64(1)
+	upper := strings.ToUpper(s)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(upper, u.suffix) {
+			unit = u.n
+			upper = strings.TrimSuffix(upper, u.suffix)
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(upper, 64)
+	if err != nil || n < 0 {
+		return 0, errors.Errorf("invalid size %s", s)
+	}
+	return int64(n * float64(unit)), nil
+}
@@ -0,0 +1,119 @@
+// Package kms manage the keys encrypting the secrets at rest. the master key is set in the config,
+// the secrets such as the data keys of the storages and the tokens of the drivers are encrypted by
+// it before saved to the database, so the database leaked alone doesn't leak the secrets
+package kms
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/pkg/errors"
+)
+
+// the prefix of the encrypted values, the values without it are plain
+const encryptedPrefix = "enc:"
+
+var ErrDecrypt = errors.New("failed to decrypt, the master key may be changed")
+
+func newCipher(secret string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	return gcm, errors.WithStack(err)
+}
+
+// masterKey get the secret of the master key, the jwt secret is used if it's not set
+func masterKey() (string, error) {
+	if conf.Conf == nil {
+		return "", errors.New("config is not loaded")
+	}
+	if conf.Conf.MasterKey != "" {
+		return conf.Conf.MasterKey, nil
+	}
+	return conf.Conf.JwtSecret, nil
+}
+
+// IsEncrypted check if the value is encrypted by Encrypt
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, encryptedPrefix)
+}
+
+// Seal encrypt the data with the master key
+func Seal(data []byte) ([]byte, error) {
+	secret, err := masterKey()
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// Open decrypt the data sealed by Seal, the data sealed by the jwt secret before the master
+// key is added can be decrypted too
+func Open(data []byte) ([]byte, error) {
+	secret, err := masterKey()
+	if err != nil {
+		return nil, err
+	}
+	secrets := []string{secret}
+	if secret != conf.Conf.JwtSecret {
+		secrets = append(secrets, conf.Conf.JwtSecret)
+	}
+	for _, secret := range secrets {
+		gcm, err := newCipher(secret)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < gcm.NonceSize() {
+			return nil, errors.WithStack(ErrDecrypt)
+		}
+		plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+		if err == nil {
+			return plain, nil
+		}
+	}
+	return nil, errors.WithStack(ErrDecrypt)
+}
+
+// Encrypt the string with the master key, the empty string is kept
+func Encrypt(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	data, err := Seal([]byte(s))
+	if err != nil {
+		return "", err
+	}
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Decrypt the string encrypted by Encrypt, the plain string is returned as it is
+func Decrypt(s string) (string, error) {
+	if !IsEncrypted(s) {
+		return s, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, encryptedPrefix))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	plain, err := Open(data)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,38 @@
+package kms
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+)
+
+func TestEncrypt(t *testing.T) {
+	conf.Conf = &conf.Config{JwtSecret: "jwt", MasterKey: "master"}
+	enc, err := Encrypt("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(enc) {
+		t.Fatalf("%s isn't encrypted", enc)
+	}
+	if plain, err := Decrypt(enc); err != nil || plain != "token" {
+		t.Fatalf("decrypt %s: %s, %v", enc, plain, err)
+	}
+	if plain, err := Decrypt("plain"); err != nil || plain != "plain" {
+		t.Fatalf("the plain string is changed: %s, %v", plain, err)
+	}
+	// the values encrypted by the jwt secret before the master key is added
+	conf.Conf.MasterKey = ""
+	legacy, err := Encrypt("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.Conf.MasterKey = "master"
+	if plain, err := Decrypt(legacy); err != nil || plain != "token" {
+		t.Fatalf("decrypt the legacy value: %s, %v", plain, err)
+	}
+	conf.Conf.MasterKey = "changed"
+	if _, err := Decrypt(enc); err == nil {
+		t.Fatal("decrypted with the wrong master key")
+	}
+}
@@ -0,0 +1,311 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+var errNoExif = errors.New("no exif")
+
+// jpegExif find the tiff data of exif in the app1 segment of the jpeg header
+func jpegExif(header []byte) ([]byte, error) {
+	if len(header) < 4 || header[0] != 0xFF || header[1] != 0xD8 {
+		return nil, errors.New("not a jpeg")
+	}
+	for i := 2; i+4 <= len(header); {
+		if header[i] != 0xFF {
+			return nil, errors.New("invalid jpeg marker")
+		}
+		marker := header[i+1]
+		if marker == 0xFF {
+			// the fill bytes
+			i++
+			continue
+		}
+		// the start of the scan, no more metadata
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(header[i+2:]))
+		end := i + 2 + length
+		if end > len(header) {
+			end = len(header)
+		}
+		data := header[i+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(data, []byte("Exif\x00\x00")) {
+			return data[6:], nil
+		}
+		i = i + 2 + length
+	}
+	return nil, errors.WithStack(errNoExif)
+}
+
+// the tags of exif used
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagExposureTime     = 0x829A
+	tagFNumber          = 0x829D
+	tagISO              = 0x8827
+	tagDateTimeOriginal = 0x9003
+	tagOffsetOriginal   = 0x9011
+	tagFocalLength      = 0x920A
+	tagPixelX           = 0xA002
+	tagPixelY           = 0xA003
+	tagLensModel        = 0xA434
+
+	tagGPSLatitudeRef  = 1
+	tagGPSLatitude     = 2
+	tagGPSLongitudeRef = 3
+	tagGPSLongitude    = 4
+	tagGPSAltitudeRef  = 5
+	tagGPSAltitude     = 6
+)
+
+// the sizes of the types of the values
+var typeSizes = map[uint16]uint32{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 7: 1, 9: 4, 10: 8}
+
+// the max count of the entries in an ifd, the larger ones are corrupted
+const maxEntries = 1000
+
+type ifdEntry struct {
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+type tiffReader struct {
+	r     io.ReaderAt
+	order binary.ByteOrder
+}
+
+func (t *tiffReader) readIFD(offset uint32) (map[uint16]ifdEntry, error) {
+	buf := make([]byte, 2)
+	if _, err := t.r.ReadAt(buf, int64(offset)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	n := t.order.Uint16(buf)
+	if n > maxEntries {
+		return nil, errors.New("too many entries in the ifd")
+	}
+	buf = make([]byte, int(n)*12)
+	if _, err := t.r.ReadAt(buf, int64(offset)+2); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	entries := make(map[uint16]ifdEntry, n)
+	for i := 0; i < int(n); i++ {
+		e := buf[i*12 : i*12+12]
+		tag, typ, count := t.order.Uint16(e), t.order.Uint16(e[2:]), t.order.Uint32(e[4:])
+		size, ok := typeSizes[typ]
+		if !ok || count > 1<<16 {
+			continue
+		}
+		value := e[8:12]
+		if total := size * count; total > 4 {
+			value = make([]byte, total)
+			if _, err := t.r.ReadAt(value, int64(t.order.Uint32(e[8:]))); err != nil {
+				continue
+			}
+		} else {
+			value = value[:total]
+		}
+		entries[tag] = ifdEntry{typ: typ, count: count, value: value}
+	}
+	return entries, nil
+}
+
+func (t *tiffReader) str(e ifdEntry) string {
+	return strings.TrimSpace(strings.TrimRight(string(e.value), "\x00"))
+}
+
+func (t *tiffReader) uint(e ifdEntry) uint32 {
+	switch e.typ {
+	case 1, 7:
+		if len(e.value) > 0 {
+			return uint32(e.value[0])
+		}
+	case 3:
+		if len(e.value) >= 2 {
+			return uint32(t.order.Uint16(e.value))
+		}
+	case 4, 9:
+		if len(e.value) >= 4 {
+			return t.order.Uint32(e.value)
+		}
+	}
+	return 0
+}
+
+// rational get the i-th rational of the entry
+func (t *tiffReader) rational(e ifdEntry, i int) (uint32, uint32, bool) {
+	if (e.typ != 5 && e.typ != 10) || len(e.value) < (i+1)*8 {
+		return 0, 0, false
+	}
+	return t.order.Uint32(e.value[i*8:]), t.order.Uint32(e.value[i*8+4:]), true
+}
+
+func (t *tiffReader) float(e ifdEntry, i int) (float64, bool) {
+	num, den, ok := t.rational(e, i)
+	if !ok || den == 0 {
+		return 0, false
+	}
+	if e.typ == 10 {
+		return float64(int32(num)) / float64(int32(den)), true
+	}
+	return float64(num) / float64(den), true
+}
+
+// parseExif parse the exif in the tiff format, the size of the image is returned if it's recorded
+func parseExif(r io.ReaderAt) (*model.Exif, int, int, error) {
+	header := make([]byte, 8)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, 0, 0, errors.WithStack(err)
+	}
+	t := &tiffReader{r: r}
+	switch string(header[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return nil, 0, 0, errors.New("invalid tiff header")
+	}
+	ifd0, err := t.readIFD(t.order.Uint32(header[4:]))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	exif := &model.Exif{}
+	if e, ok := ifd0[tagMake]; ok {
+		exif.Make = t.str(e)
+	}
+	if e, ok := ifd0[tagModel]; ok {
+		exif.Model = t.str(e)
+	}
+	if e, ok := ifd0[tagOrientation]; ok {
+		exif.Orientation = int(t.uint(e))
+	}
+	dateTime := ""
+	if e, ok := ifd0[tagDateTime]; ok {
+		dateTime = t.str(e)
+	}
+	var width, height int
+	if e, ok := ifd0[tagExifIFD]; ok {
+		if sub, err := t.readIFD(t.uint(e)); err == nil {
+			if e, ok := sub[tagDateTimeOriginal]; ok {
+				dateTime = t.str(e)
+			}
+			offset := ""
+			if e, ok := sub[tagOffsetOriginal]; ok {
+				offset = t.str(e)
+			}
+			if tm, ok := parseExifTime(dateTime, offset); ok {
+				exif.CaptureTime = &tm
+			}
+			if e, ok := sub[tagExposureTime]; ok {
+				if num, den, ok := t.rational(e, 0); ok && den != 0 {
+					if num >= den {
+						exif.ExposureTime = formatRational(num, den)
+					} else {
+						exif.ExposureTime = fmt.Sprintf("1/%d", int(math.Round(float64(den)/float64(num))))
+					}
+				}
+			}
+			if e, ok := sub[tagFNumber]; ok {
+				exif.FNumber, _ = t.float(e, 0)
+			}
+			if e, ok := sub[tagISO]; ok {
+				exif.ISO = int(t.uint(e))
+			}
+			if e, ok := sub[tagFocalLength]; ok {
+				exif.FocalLength, _ = t.float(e, 0)
+			}
+			if e, ok := sub[tagLensModel]; ok {
+				exif.LensModel = t.str(e)
+			}
+			if e, ok := sub[tagPixelX]; ok {
+				width = int(t.uint(e))
+			}
+			if e, ok := sub[tagPixelY]; ok {
+				height = int(t.uint(e))
+			}
+		}
+	}
+	if exif.CaptureTime == nil {
+		if tm, ok := parseExifTime(dateTime, ""); ok {
+			exif.CaptureTime = &tm
+		}
+	}
+	if e, ok := ifd0[tagGPSIFD]; ok {
+		if gps, err := t.readIFD(t.uint(e)); err == nil {
+			exif.Latitude = t.coordinate(gps, tagGPSLatitude, tagGPSLatitudeRef, "S")
+			exif.Longitude = t.coordinate(gps, tagGPSLongitude, tagGPSLongitudeRef, "W")
+			if e, ok := gps[tagGPSAltitude]; ok {
+				if alt, ok := t.float(e, 0); ok {
+					// 1 is below the sea level
+					if ref, ok := gps[tagGPSAltitudeRef]; ok && t.uint(ref) == 1 {
+						alt = -alt
+					}
+					exif.Altitude = &alt
+				}
+			}
+		}
+	}
+	return exif, width, height, nil
+}
+
+// coordinate get the coordinate in degrees from the degrees, the minutes and the seconds
+func (t *tiffReader) coordinate(gps map[uint16]ifdEntry, tag, refTag uint16, negative string) *float64 {
+	e, ok := gps[tag]
+	if !ok {
+		return nil
+	}
+	var parts [3]float64
+	for i := range parts {
+		v, ok := t.float(e, i)
+		if !ok {
+			return nil
+		}
+		parts[i] = v
+	}
+	v := parts[0] + parts[1]/60 + parts[2]/3600
+	if ref, ok := gps[refTag]; ok && t.str(ref) == negative {
+		v = -v
+	}
+	return &v
+}
+
+func formatRational(num, den uint32) string {
+	if den == 1 {
+		return fmt.Sprintf("%d", num)
+	}
+	return fmt.Sprintf("%g", float64(num)/float64(den))
+}
+
+// parseExifTime parse the time in exif like 2006:01:02 15:04:05, with the offset like +08:00 if it's recorded
+func parseExifTime(s, offset string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	if offset != "" {
+		if t, err := time.Parse("2006:01:02 15:04:05-07:00", s+offset); err == nil {
+			return t, true
+		}
+	}
+	t, err := time.Parse("2006:01:02 15:04:05", s)
+	if err != nil || t.Year() < 1900 {
+		return time.Time{}, false
+	}
+	return t, true
+}
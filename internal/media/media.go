@@ -0,0 +1,196 @@
+// Package media parse the metadata of the images, the audios and the videos, only the
+// headers are read from the provider. ffprobe is used for the audios and the videos if
+// it's available, or only mp4 and mov are parsed natively
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/ffmpeg"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+
+	// the decoders of the sizes of the images
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+var ErrNotSupported = errors.New("the metadata of the file can't be parsed")
+
+// the size of the header of the images read, the exif of jpeg is in the first 64KB usually
+const headerSize = 256 * 1024
+
+// the max time to run ffprobe
+const probeTimeout = 30 * time.Second
+
+var (
+	tiffExts = []string{"tif", "tiff", "dng", "cr2", "nef", "arw"}
+	mp4Exts  = []string{"mp4", "m4v", "mov", "m4a", "3gp"}
+)
+
+var infoCache = cache.NewMemCache(cache.WithShards[*model.MediaInfo](16))
+var g singleflight.Group[*model.MediaInfo]
+
+// cacheKey the info is cached by the hash of the file, or the path and the version of it
+func cacheKey(path string, file model.Obj) string {
+	if h := file.GetHash(); !h.Empty() {
+		return fmt.Sprintf("%s:%s", h.Type, h.Value)
+	}
+	return fmt.Sprintf("%s|%d|%d", path, file.GetSize(), file.ModTime().Unix())
+}
+
+// Get the metadata of the image, the audio or the video
+func Get(ctx context.Context, path string) (*model.MediaInfo, error) {
+	file, err := fs.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if file.IsDir() {
+		return nil, errors.WithStack(errs.NotFile)
+	}
+	typ := utils.GetFileType(file.GetName())
+	if typ != conf.IMAGE && typ != conf.VIDEO && typ != conf.AUDIO {
+		return nil, errors.WithStack(ErrNotSupported)
+	}
+	// the boxes of the videos are scattered, it's pointless if the whole file is downloaded to find them
+	if typ != conf.IMAGE && !fs.RangeReadable(path) {
+		return nil, errors.WithStack(ErrNotSupported)
+	}
+	key := cacheKey(path, file)
+	if info, ok := infoCache.Get(key); ok {
+		return info, nil
+	}
+	info, err, _ := g.Do(key, func() (*model.MediaInfo, error) {
+		link, _, err := fs.Link(ctx, path, model.LinkArgs{})
+		if err != nil {
+			return nil, err
+		}
+		r := &linkReader{ctx: ctx, link: link}
+		defer r.Close()
+		var info *model.MediaInfo
+		if typ == conf.IMAGE {
+			info, err = parseImage(r, file.GetName())
+		} else {
+			info, err = parseMedia(ctx, r, file)
+		}
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to parse the metadata of [%s]", path)
+		}
+		infoCache.Set(key, info, cache.WithEx[*model.MediaInfo](time.Hour*24))
+		return info, nil
+	})
+	return info, err
+}
+
+func parseImage(r io.ReaderAt, name string) (*model.MediaInfo, error) {
+	info := &model.MediaInfo{}
+	ext := strings.ToLower(utils.Ext(name))
+	if utils.SliceContains(tiffExts, ext) {
+		exif, width, height, err := parseExif(r)
+		if err != nil {
+			return nil, err
+		}
+		info.Exif, info.Width, info.Height = exif, width, height
+		return info, nil
+	}
+	header := make([]byte, headerSize)
+	n, err := r.ReadAt(header, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	header = header[:n]
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(header)); err == nil {
+		info.Width, info.Height = cfg.Width, cfg.Height
+	}
+	if tiff, err := jpegExif(header); err == nil {
+		exif, width, height, err := parseExif(bytes.NewReader(tiff))
+		if err == nil {
+			info.Exif = exif
+			if info.Width == 0 {
+				info.Width, info.Height = width, height
+			}
+		}
+	}
+	if info.Width == 0 && info.Exif == nil {
+		return nil, errors.WithStack(ErrNotSupported)
+	}
+	return info, nil
+}
+
+func parseMedia(ctx context.Context, r *linkReader, file model.Obj) (*model.MediaInfo, error) {
+	if p := ffmpeg.ProbePath(); p != "" {
+		return probe(ctx, p, r.link)
+	}
+	if utils.SliceContains(mp4Exts, strings.ToLower(utils.Ext(file.GetName()))) {
+		return parseMP4(r, file.GetSize())
+	}
+	return nil, errors.WithStack(ErrNotSupported)
+}
+
+type probeResult struct {
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		CodecName string `json:"codec_name"`
+		Width     int    `json:"width"`
+		Height    int    `json:"height"`
+	} `json:"streams"`
+	Format struct {
+		Duration string `json:"duration"`
+		BitRate  string `json:"bit_rate"`
+	} `json:"format"`
+}
+
+// probe get the info by ffprobe, it reads the headers in ranges by itself
+func probe(ctx context.Context, ffprobe string, link *model.Link) (*model.MediaInfo, error) {
+	input, stdin, err := ffmpeg.Input(link)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	args := append([]string{"-v", "error", "-print_format", "json", "-show_format", "-show_streams"}, input...)
+	cmd := exec.CommandContext(ctx, ffprobe, args...)
+	cmd.Stdin = stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to run ffprobe: %s", strings.TrimSpace(stderr.String()))
+	}
+	var res probeResult
+	if err := json.Unmarshal(out, &res); err != nil {
+		return nil, errors.Wrap(err, "invalid output of ffprobe")
+	}
+	info := &model.MediaInfo{}
+	info.Duration, _ = strconv.ParseFloat(res.Format.Duration, 64)
+	info.Bitrate, _ = strconv.ParseInt(res.Format.BitRate, 10, 64)
+	for _, s := range res.Streams {
+		switch s.CodecType {
+		case "video":
+			if info.VideoCodec == "" {
+				info.VideoCodec, info.Width, info.Height = s.CodecName, s.Width, s.Height
+			}
+		case "audio":
+			if info.AudioCodec == "" {
+				info.AudioCodec = s.CodecName
+			}
+		}
+	}
+	return info, nil
+}
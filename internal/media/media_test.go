@@ -0,0 +1,158 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"math"
+	"testing"
+	"time"
+)
+
+// ifdBuilder build the ifds of tiff in little endian, the values larger than 4 bytes
+// are appended after the ifd
+type ifdBuilder struct {
+	entries [][]byte
+	data    []byte
+}
+
+func (b *ifdBuilder) add(tag, typ uint16, count uint32, value []byte) {
+	e := make([]byte, 12)
+	binary.LittleEndian.PutUint16(e, tag)
+	binary.LittleEndian.PutUint16(e[2:], typ)
+	binary.LittleEndian.PutUint32(e[4:], count)
+	b.entries = append(b.entries, append(e[:8:8], value...))
+}
+
+// build the ifd at the offset, the values are placed after it
+func (b *ifdBuilder) build(offset uint32) []byte {
+	dataOffset := offset + 2 + uint32(len(b.entries))*12 + 4
+	var ifd, data []byte
+	ifd = u16(uint16(len(b.entries)))
+	for _, e := range b.entries {
+		value := e[8:]
+		if len(value) > 4 {
+			ifd = append(ifd, e[:8]...)
+			ifd = append(ifd, u32(dataOffset+uint32(len(data)))...)
+			data = append(data, value...)
+		} else {
+			ifd = append(ifd, e[:8]...)
+			ifd = append(ifd, append(value, make([]byte, 4-len(value))...)...)
+		}
+	}
+	ifd = append(ifd, u32(0)...)
+	return append(ifd, data...)
+}
+
+func u16(v uint16) []byte {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, v)
+	return b
+}
+
+func u32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+func rationals(v ...uint32) []byte {
+	var b []byte
+	for _, x := range v {
+		b = append(b, u32(x)...)
+	}
+	return b
+}
+
+func buildTiff() []byte {
+	// the ifds are placed at the fixed offsets, far enough from each other
+	const ifd0At, exifAt, gpsAt = 8, 200, 400
+	var ifd0, exif, gps ifdBuilder
+	ifd0.add(tagMake, 2, 6, []byte("Canon\x00"))
+	ifd0.add(tagOrientation, 3, 1, u16(6))
+	ifd0.add(tagExifIFD, 4, 1, u32(exifAt))
+	ifd0.add(tagGPSIFD, 4, 1, u32(gpsAt))
+	exif.add(tagDateTimeOriginal, 2, 20, []byte("2021:05:06 07:08:09\x00"))
+	exif.add(tagOffsetOriginal, 2, 7, []byte("+08:00\x00"))
+	exif.add(tagExposureTime, 5, 1, rationals(1, 250))
+	exif.add(tagFNumber, 5, 1, rationals(28, 10))
+	exif.add(tagISO, 3, 1, u16(400))
+	gps.add(tagGPSLatitudeRef, 2, 2, []byte("N\x00"))
+	gps.add(tagGPSLatitude, 5, 3, rationals(31, 1, 30, 1, 0, 1))
+	gps.add(tagGPSLongitudeRef, 2, 2, []byte("W\x00"))
+	gps.add(tagGPSLongitude, 5, 3, rationals(121, 1, 15, 1, 36, 1))
+	tiff := make([]byte, 600)
+	copy(tiff, "II*\x00")
+	binary.LittleEndian.PutUint32(tiff[4:], ifd0At)
+	copy(tiff[ifd0At:], ifd0.build(ifd0At))
+	copy(tiff[exifAt:], exif.build(exifAt))
+	copy(tiff[gpsAt:], gps.build(gpsAt))
+	return tiff
+}
+
+func TestParseImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 40, 30)), nil); err != nil {
+		t.Fatal(err)
+	}
+	app1 := append([]byte("Exif\x00\x00"), buildTiff()...)
+	segment := append([]byte{0xFF, 0xE1, 0, 0}, app1...)
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(app1)+2))
+	data := append(append([]byte{0xFF, 0xD8}, segment...), buf.Bytes()[2:]...)
+
+	info, err := parseImage(bytes.NewReader(data), "a.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Width != 40 || info.Height != 30 {
+		t.Errorf("unexpected size %dx%d", info.Width, info.Height)
+	}
+	e := info.Exif
+	if e == nil {
+		t.Fatal("no exif")
+	}
+	if e.Make != "Canon" || e.Orientation != 6 || e.ISO != 400 || e.FNumber != 2.8 || e.ExposureTime != "1/250" {
+		t.Errorf("unexpected exif %+v", e)
+	}
+	want := time.Date(2021, 5, 6, 7, 8, 9, 0, time.FixedZone("", 8*3600))
+	if e.CaptureTime == nil || !e.CaptureTime.Equal(want) {
+		t.Errorf("unexpected capture time %v", e.CaptureTime)
+	}
+	if e.Latitude == nil || math.Abs(*e.Latitude-31.5) > 1e-9 {
+		t.Errorf("unexpected latitude %v", e.Latitude)
+	}
+	if e.Longitude == nil || math.Abs(*e.Longitude+121.26) > 1e-9 {
+		t.Errorf("unexpected longitude %v", e.Longitude)
+	}
+}
+
+func box(typ string, body ...[]byte) []byte {
+	b := bytes.Join(body, nil)
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(len(b)+8))
+	copy(header[4:], typ)
+	return append(header, b...)
+}
+
+func TestParseMP4(t *testing.T) {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:], 12500)
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:], 1920<<16)
+	binary.BigEndian.PutUint32(tkhd[80:], 1080<<16)
+	hdlr := append(make([]byte, 8), []byte("vide")...)
+	stsd := append(make([]byte, 8), box("hvc1", make([]byte, 8))...)
+	trak := box("trak", box("tkhd", tkhd), box("mdia", box("hdlr", hdlr), box("minf", box("stbl", box("stsd", stsd)))))
+	data := append(box("ftyp", []byte("isom")), box("mdat", make([]byte, 1000))...)
+	data = append(data, box("moov", box("mvhd", mvhd), trak)...)
+
+	info, err := parseMP4(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Duration != 12.5 || info.VideoCodec != "hevc" || info.Width != 1920 || info.Height != 1080 {
+		t.Errorf("unexpected info %+v", info)
+	}
+}
@@ -0,0 +1,183 @@
+package media
+
+import (
+	"encoding/binary"
+	"io"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// the max size of the moov box read, the larger ones are of the long videos
+// with many samples and ffprobe is needed
+const maxMoovSize = 16 * 1024 * 1024
+
+// the count of the top level boxes walked to find moov
+const maxBoxes = 64
+
+// findBox find the top level box by the type, only the headers of the boxes are read
+func findBox(r io.ReaderAt, size int64, typ string) (int64, int64, error) {
+	header := make([]byte, 16)
+	var offset int64
+	for i := 0; i < maxBoxes && offset+8 <= size; i++ {
+		n, err := r.ReadAt(header, offset)
+		if n < 8 {
+			return 0, 0, errors.Wrap(err, "failed to read the box header")
+		}
+		boxSize := int64(binary.BigEndian.Uint32(header))
+		headerSize := int64(8)
+		switch boxSize {
+		case 0:
+			// to the end of the file
+			boxSize = size - offset
+		case 1:
+			if n < 16 {
+				return 0, 0, errors.New("invalid box size")
+			}
+			boxSize = int64(binary.BigEndian.Uint64(header[8:]))
+			headerSize = 16
+		}
+		if boxSize < headerSize {
+			return 0, 0, errors.New("invalid box size")
+		}
+		if string(header[4:8]) == typ {
+			return offset + headerSize, boxSize - headerSize, nil
+		}
+		offset += boxSize
+	}
+	return 0, 0, errors.Errorf("no %s box", typ)
+}
+
+// children iterate the child boxes in the data of the box
+func children(data []byte, fn func(typ string, body []byte)) {
+	for len(data) >= 8 {
+		size := int(binary.BigEndian.Uint32(data))
+		if size < 8 || size > len(data) {
+			return
+		}
+		fn(string(data[4:8]), data[8:size])
+		data = data[size:]
+	}
+}
+
+// parseMP4 parse the info of the mp4, mov or m4a by the moov box
+func parseMP4(r io.ReaderAt, size int64) (*model.MediaInfo, error) {
+	offset, moovSize, err := findBox(r, size, "moov")
+	if err != nil {
+		return nil, err
+	}
+	if moovSize > maxMoovSize {
+		return nil, errors.New("the moov box is too large")
+	}
+	moov := make([]byte, moovSize)
+	if _, err := r.ReadAt(moov, offset); err != nil {
+		return nil, errors.Wrap(err, "failed to read the moov box")
+	}
+	info := &model.MediaInfo{}
+	children(moov, func(typ string, body []byte) {
+		switch typ {
+		case "mvhd":
+			info.Duration = mvhdDuration(body)
+		case "trak":
+			parseTrak(body, info)
+		}
+	})
+	if info.Duration > 0 && size > 0 {
+		info.Bitrate = int64(float64(size*8) / info.Duration)
+	}
+	return info, nil
+}
+
+func mvhdDuration(body []byte) float64 {
+	if len(body) < 20 {
+		return 0
+	}
+	var timescale uint32
+	var duration uint64
+	if body[0] == 1 {
+		if len(body) < 32 {
+			return 0
+		}
+		timescale = binary.BigEndian.Uint32(body[20:])
+		duration = binary.BigEndian.Uint64(body[24:])
+	} else {
+		timescale = binary.BigEndian.Uint32(body[12:])
+		duration = uint64(binary.BigEndian.Uint32(body[16:]))
+	}
+	if timescale == 0 {
+		return 0
+	}
+	return float64(duration) / float64(timescale)
+}
+
+func parseTrak(trak []byte, info *model.MediaInfo) {
+	var width, height int
+	var handler, codec string
+	children(trak, func(typ string, body []byte) {
+		switch typ {
+		case "tkhd":
+			// the width and the height in 16.16 fixed point are the last
+			if len(body) >= 8 {
+				width = int(binary.BigEndian.Uint32(body[len(body)-8:]) >> 16)
+				height = int(binary.BigEndian.Uint32(body[len(body)-4:]) >> 16)
+			}
+		case "mdia":
+			children(body, func(typ string, body []byte) {
+				switch typ {
+				case "hdlr":
+					if len(body) >= 12 {
+						handler = string(body[8:12])
+					}
+				case "minf":
+					children(body, func(typ string, body []byte) {
+						if typ != "stbl" {
+							return
+						}
+						children(body, func(typ string, body []byte) {
+							// the format of the first sample entry
+							if typ == "stsd" && len(body) >= 16 {
+								codec = strings.TrimSpace(string(body[12:16]))
+							}
+						})
+					})
+				}
+			})
+		}
+	})
+	switch handler {
+	case "vide":
+		if info.VideoCodec == "" {
+			info.VideoCodec = codecName(codec)
+			info.Width, info.Height = width, height
+		}
+	case "soun":
+		if info.AudioCodec == "" {
+			info.AudioCodec = codecName(codec)
+		}
+	}
+}
+
+// the names of the codecs like ffprobe
+var codecNames = map[string]string{
+	"avc1": "h264",
+	"avc3": "h264",
+	"hvc1": "hevc",
+	"hev1": "hevc",
+	"av01": "av1",
+	"vp09": "vp9",
+	"mp4v": "mpeg4",
+	"mp4a": "aac",
+	"ac-3": "ac3",
+	"ec-3": "eac3",
+	"Opus": "opus",
+	"fLaC": "flac",
+	"alac": "alac",
+}
+
+func codecName(fourcc string) string {
+	if name, ok := codecNames[fourcc]; ok {
+		return name
+	}
+	return fourcc
+}
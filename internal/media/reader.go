@@ -0,0 +1,113 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// linkReader read the file of the link in ranges, so only the headers are fetched from the provider
+type linkReader struct {
+	ctx  context.Context
+	link *model.Link
+	file *os.File
+	// the offset of the Data, it's only read forward if it can't seek
+	pos int64
+}
+
+func (l *linkReader) ReadAt(p []byte, off int64) (int, error) {
+	n, err := l.readAt(p, off)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (l *linkReader) readAt(p []byte, off int64) (int, error) {
+	link := l.link
+	switch {
+	case link.FilePath != nil && *link.FilePath != "":
+		if l.file == nil {
+			f, err := os.Open(*link.FilePath)
+			if err != nil {
+				return 0, errors.WithStack(err)
+			}
+			l.file = f
+		}
+		return l.file.ReadAt(p, off)
+	case link.RangeReader != nil:
+		rc, err := link.RangeReader(off)
+		if err != nil {
+			return 0, err
+		}
+		defer rc.Close()
+		return io.ReadFull(rc, p)
+	case link.Data != nil:
+		if ra, ok := link.Data.(io.ReaderAt); ok {
+			return ra.ReadAt(p, off)
+		}
+		if s, ok := link.Data.(io.Seeker); ok {
+			if _, err := s.Seek(off, io.SeekStart); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			l.pos = off
+		}
+		if off < l.pos {
+			return 0, errors.New("the data of the link can't be read backward")
+		}
+		if _, err := io.CopyN(io.Discard, link.Data, off-l.pos); err != nil {
+			return 0, err
+		}
+		n, err := io.ReadFull(link.Data, p)
+		l.pos = off + int64(n)
+		return n, err
+	case link.URL != "":
+		return l.readURL(p, off)
+	}
+	return 0, errors.New("the link of the file is empty")
+}
+
+func (l *linkReader) readURL(p []byte, off int64) (int, error) {
+	req, err := http.NewRequestWithContext(l.ctx, http.MethodGet, l.link.URL, nil)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	for h, v := range l.link.Header {
+		req.Header[h] = v
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// the range isn't supported, the bytes before are discarded
+		if _, err := io.CopyN(io.Discard, res.Body, off); err != nil {
+			return 0, err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		return 0, io.EOF
+	default:
+		return 0, errors.Errorf("failed to read the file: %s", res.Status)
+	}
+	return io.ReadFull(res.Body, p)
+}
+
+func (l *linkReader) Close() error {
+	if l.file != nil {
+		_ = l.file.Close()
+	}
+	if l.link.Data != nil {
+		return l.link.Data.Close()
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the max count of the images in a timeline, the others are ignored
+const maxTimelineImages = 100000
+
+// the images with the capture times parsed in the background concurrently
+const warmConcurrency = 4
+
+type TimelineItem struct {
+	Path string
+	Obj  model.Obj
+	// the capture time in the exif, or the modified time
+	Time time.Time
+	// the time is the capture time
+	Captured bool
+}
+
+// the timelines by the user and the dir, they are kept for paging
+var timelineCache = cache.NewMemCache(cache.WithShards[[]TimelineItem](16))
+var timelineG singleflight.Group[[]TimelineItem]
+var warmG singleflight.Group[struct{}]
+
+func timelineKey(ctx context.Context, dir string) string {
+	user, _ := ctx.Value("user").(*model.User)
+	if user == nil {
+		return dir
+	}
+	// the images can be seen are different by the users
+	return fmt.Sprintf("%d|%s", user.ID, dir)
+}
+
+// Timeline get the images under the dir recursively, they are sorted by the time from the
+// latest. the capture times are got from the metadata cache, and the ones not cached are
+// parsed in the background, so the timeline is more accurate next time
+func Timeline(ctx context.Context, dir string, refresh bool) ([]TimelineItem, error) {
+	key := timelineKey(ctx, dir)
+	if !refresh {
+		if items, ok := timelineCache.Get(key); ok {
+			return items, nil
+		}
+	}
+	items, err, _ := timelineG.Do(key, func() ([]TimelineItem, error) {
+		var items []TimelineItem
+		err := walkImages(ctx, dir, refresh, func(path string, obj model.Obj) bool {
+			item := TimelineItem{Path: path, Obj: obj, Time: obj.ModTime()}
+			if info, ok := infoCache.Get(cacheKey(path, obj)); ok && info.Exif != nil && info.Exif.CaptureTime != nil {
+				item.Time, item.Captured = *info.Exif.CaptureTime, true
+			}
+			items = append(items, item)
+			return len(items) < maxTimelineImages
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Time.After(items[j].Time)
+		})
+		timelineCache.Set(key, items, cache.WithEx[[]TimelineItem](time.Minute*10))
+		go warm(utils.Detach(ctx), key, items)
+		return items, nil
+	})
+	return items, err
+}
+
+// walkImages call fn with the images under the dir recursively until it returns false
+func walkImages(ctx context.Context, dir string, refresh bool, fn func(path string, obj model.Obj) bool) error {
+	objs, err := fs.List(ctx, dir, refresh)
+	if err != nil {
+		return errors.WithMessagef(err, "failed list [%s]", dir)
+	}
+	for _, obj := range objs {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		p := stdpath.Join(dir, obj.GetName())
+		if obj.IsDir() {
+			if err := walkImages(ctx, p, refresh, fn); err != nil {
+				// the dirs can't be listed are skipped
+				log.Warnf("failed to walk [%s] for the timeline: %+v", p, err)
+			}
+			continue
+		}
+		if utils.GetFileType(obj.GetName()) == conf.IMAGE && !fn(p, obj) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// warm parse the capture times of the images not cached, the timeline is dropped after
+// that, so it's sorted again with the capture times
+func warm(ctx context.Context, key string, items []TimelineItem) {
+	_, _, _ = warmG.Do(key, func() (struct{}, error) {
+		sem := make(chan struct{}, warmConcurrency)
+		parsed := 0
+		for _, item := range items {
+			if item.Captured || !hasExif(item.Obj.GetName()) {
+				continue
+			}
+			if _, ok := infoCache.Get(cacheKey(item.Path, item.Obj)); ok {
+				continue
+			}
+			sem <- struct{}{}
+			parsed++
+			go func(path string) {
+				defer func() { <-sem }()
+				if _, err := Get(ctx, path); err != nil {
+					log.Debugf("failed to parse the capture time of [%s]: %+v", path, err)
+				}
+			}(item.Path)
+		}
+		for i := 0; i < cap(sem); i++ {
+			sem <- struct{}{}
+		}
+		if parsed > 0 {
+			timelineCache.Del(key)
+		}
+		return struct{}{}, nil
+	})
+}
+
+// hasExif check if the capture time may be in the image by the name
+func hasExif(name string) bool {
+	ext := strings.ToLower(utils.Ext(name))
+	return ext == "jpg" || ext == "jpeg" || utils.SliceContains(tiffExts, ext)
+}
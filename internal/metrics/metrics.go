@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+)
+
+var (
+	httpRequests = NewCounter("alist_http_requests_total",
+		"the count of the http requests by the route", "route", "method", "status")
+	httpDuration = NewHistogram("alist_http_request_duration_seconds",
+		"the duration of the http requests by the route", DefBuckets, "route", "method")
+	driverCalls = NewCounter("alist_driver_calls_total",
+		"the count of the calls of the drivers", "driver", "op")
+	driverErrors = NewCounter("alist_driver_errors_total",
+		"the count of the failed calls of the drivers", "driver", "op")
+	proxyStreams = NewGauge("alist_proxy_streams",
+		"the count of the files being proxied")
+	transferBytes = NewCounter("alist_transfer_bytes_total",
+		"the bytes proxied to the clients and uploaded to the storages", "direction")
+	cacheRequests = NewCounter("alist_cache_requests_total",
+		"the count of the lookups of the caches by the result", "cache", "result")
+)
+
+// ObserveRequest record the http request to the route, the route is the pattern such as /d/*path
+func ObserveRequest(route, method string, status int, d time.Duration) {
+	httpRequests.Inc(route, method, strconv.Itoa(status))
+	httpDuration.Observe(d.Seconds(), route, method)
+}
+
+// DriverCall record the call of the driver, it's failed if err isn't nil
+func DriverCall(driver, op string, err error) {
+	driverCalls.Inc(driver, op)
+	if err != nil {
+		driverErrors.Inc(driver, op)
+	}
+}
+
+// ProxyStream record a file is being proxied, the returned func should be called when it's done
+func ProxyStream() func() {
+	proxyStreams.Add(1)
+	return func() {
+		proxyStreams.Add(-1)
+	}
+}
+
+// DownloadBytes record the bytes proxied to the clients
+func DownloadBytes(n int64) {
+	transferBytes.Add(float64(n), "download")
+}
+
+// UploadBytes record the bytes uploaded to the storages
+func UploadBytes(n int64) {
+	transferBytes.Add(float64(n), "upload")
+}
+
+// CacheLookup record the lookup of the cache
+func CacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheRequests.Inc(cache, result)
+}
@@ -0,0 +1,287 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// the metrics in the text format of prometheus, only the counters, the gauges and
+// the histograms with labels are supported
+
+type metric interface {
+	write(w *bufio.Writer)
+}
+
+var (
+	mu      sync.Mutex
+	metrics []metric
+)
+
+func register(m metric) {
+	mu.Lock()
+	defer mu.Unlock()
+	metrics = append(metrics, m)
+}
+
+// Write write all the metrics in the text format
+func Write(w io.Writer) error {
+	mu.Lock()
+	ms := append([]metric{}, metrics...)
+	mu.Unlock()
+	bw := bufio.NewWriter(w)
+	for _, m := range ms {
+		m.write(bw)
+	}
+	return bw.Flush()
+}
+
+// desc is the name and the labels of a metric
+type desc struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+}
+
+func (d *desc) header(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, d.help, d.name, d.typ)
+}
+
+// series get the name of the series with the label values, extra labels such as le are appended
+func (d *desc) series(suffix string, values []string, extra ...string) string {
+	var b strings.Builder
+	b.WriteString(d.name)
+	b.WriteString(suffix)
+	if len(d.labels)+len(extra) == 0 {
+		return b.String()
+	}
+	b.WriteByte('{')
+	pairs := append(append([]string{}, zip(d.labels, values)...), extra...)
+	for i := 0; i < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(escape(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func zip(labels, values []string) []string {
+	res := make([]string, 0, len(labels)*2)
+	for i, label := range labels {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		res = append(res, label, value)
+	}
+	return res
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func key(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// values is the values of the series of a counter or a gauge
+type values struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*sample
+}
+
+type sample struct {
+	labels []string
+	value  float64
+}
+
+func newValues(typ, name, help string, labels []string) *values {
+	return &values{desc: desc{name: name, help: help, typ: typ, labels: labels}, series: make(map[string]*sample)}
+}
+
+func (v *values) add(delta float64, labels []string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	k := key(labels)
+	s, ok := v.series[k]
+	if !ok {
+		s = &sample{labels: append([]string{}, labels...)}
+		v.series[k] = s
+	}
+	s.value += delta
+}
+
+func (v *values) set(value float64, labels []string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	k := key(labels)
+	s, ok := v.series[k]
+	if !ok {
+		s = &sample{labels: append([]string{}, labels...)}
+		v.series[k] = s
+	}
+	s.value = value
+}
+
+func (v *values) write(w *bufio.Writer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.header(w)
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := v.series[k]
+		fmt.Fprintf(w, "%s %s\n", v.desc.series("", s.labels), formatFloat(s.value))
+	}
+}
+
+// Counter is a counter with labels, the values of the labels are in the order of the names
+type Counter struct {
+	v *values
+}
+
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{v: newValues("counter", name, help, labels)}
+	register(c.v)
+	return c
+}
+
+func (c *Counter) Add(delta float64, labels ...string) {
+	c.v.add(delta, labels)
+}
+
+func (c *Counter) Inc(labels ...string) {
+	c.v.add(1, labels)
+}
+
+// Gauge is a gauge with labels
+type Gauge struct {
+	v *values
+}
+
+func NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{v: newValues("gauge", name, help, labels)}
+	register(g.v)
+	return g
+}
+
+func (g *Gauge) Add(delta float64, labels ...string) {
+	g.v.add(delta, labels)
+}
+
+func (g *Gauge) Set(value float64, labels ...string) {
+	g.v.set(value, labels)
+}
+
+// GaugeFunc is a gauge collected when the metrics are written, such as the count of the tasks
+type GaugeFunc struct {
+	desc
+	collect func(set func(value float64, labels ...string))
+}
+
+func NewGaugeFunc(name, help string, labels []string, collect func(set func(value float64, labels ...string))) *GaugeFunc {
+	g := &GaugeFunc{desc: desc{name: name, help: help, typ: "gauge", labels: labels}, collect: collect}
+	register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w *bufio.Writer) {
+	g.header(w)
+	g.collect(func(value float64, labels ...string) {
+		fmt.Fprintf(w, "%s %s\n", g.series("", labels), formatFloat(value))
+	})
+}
+
+// Histogram count the observed values in the buckets with labels
+type Histogram struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSample
+}
+
+type histogramSample struct {
+	labels []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// DefBuckets is the default buckets of the durations in seconds
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{
+		desc:    desc{name: name, help: help, typ: "histogram", labels: labels},
+		buckets: buckets,
+		series:  make(map[string]*histogramSample),
+	}
+	register(h)
+	return h
+}
+
+func (h *Histogram) Observe(value float64, labels ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	k := key(labels)
+	s, ok := h.series[k]
+	if !ok {
+		s = &histogramSample{labels: append([]string{}, labels...), counts: make([]uint64, len(h.buckets))}
+		h.series[k] = s
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.header(w)
+	keys := make([]string, 0, len(h.series))
+	for k := range h.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := h.series[k]
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%s %d\n", h.desc.series("_bucket", s.labels, "le", formatFloat(bound)), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s %d\n", h.desc.series("_bucket", s.labels, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s %s\n", h.desc.series("_sum", s.labels), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s %d\n", h.desc.series("_count", s.labels), s.count)
+	}
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	c := NewCounter("test_requests_total", "the test requests", "route")
+	c.Inc("/a")
+	c.Add(2, `/b"`)
+	h := NewHistogram("test_duration_seconds", "the test durations", []float64{0.1, 1})
+	h.Observe(0.5)
+	var buf bytes.Buffer
+	if err := Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"# TYPE test_requests_total counter",
+		`test_requests_total{route="/a"} 1`,
+		`test_requests_total{route="/b\""} 2`,
+		`test_duration_seconds_bucket{le="0.1"} 0`,
+		`test_duration_seconds_bucket{le="1"} 1`,
+		`test_duration_seconds_bucket{le="+Inf"} 1`,
+		"test_duration_seconds_sum 0.5",
+		"test_duration_seconds_count 1",
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing line %q in:\n%s", line, out)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// the kinds of the links the files are downloaded by
+const (
+	AccessDirect = "direct"
+	AccessShare  = "share"
+)
+
+// AccessLog is a record of a request downloading the file by the direct link or the share link
+type AccessLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+	// the local date of the request, such as 2006-01-02, so the traffic is grouped by the day
+	Day     string `json:"day" gorm:"index"`
+	Link    string `json:"link"`
+	ShareID uint   `json:"share_id" gorm:"index"`
+	Path    string `json:"path" gorm:"index"`
+	IP      string `json:"ip"`
+	// the user the file is downloaded as, that is the creator of the share
+	UserID     uint `json:"user_id" gorm:"index"`
+	Status     int  `json:"status"`
+	Redirected bool `json:"redirected"`
+	// the request continues a download, such as the range requests of the players
+	Partial bool  `json:"partial"`
+	Bytes   int64 `json:"bytes"` // the bytes sent by alist, 0 if it's redirected
+}
+
+// AccessStat is the count of the downloads and the bytes of the file, the client or the day
+type AccessStat struct {
+	Name      string `json:"name"`
+	Requests  int64  `json:"requests"`
+	Downloads int64  `json:"downloads"`
+	Bytes     int64  `json:"bytes"`
+}
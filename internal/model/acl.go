@@ -0,0 +1,62 @@
+package model
+
+import (
+	stdpath "path"
+	"strings"
+)
+
+const (
+	AclRead   = "read"
+	AclWrite  = "write"
+	AclDelete = "delete"
+	// AclShare is checked when creating the share links
+	AclShare = "share"
+)
+
+// AclRule allow or deny an action of the user on the objects in the path,
+// the rules only restrict the permissions of the user, or allow them again
+// in the sub path of a denied path, they can't grant a permission the user hasn't
+type AclRule struct {
+	ID     uint `json:"id" gorm:"primaryKey"`
+	UserID uint `json:"user_id" gorm:"index" binding:"required"`
+	// the path in alist, not relative to the base path of the user,
+	// it matches the sub paths too, and can be a glob like /team/*/private
+	Path   string `json:"path" binding:"required"`
+	Action string `json:"action" binding:"required"` // read, write, delete or share
+	Allow  bool   `json:"allow"`
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// Match check whether the rule applies to the path, that is the path or one of its parents matches
+func (r AclRule) Match(path string) bool {
+	if !isGlob(r.Path) {
+		return r.Path == "/" || path == r.Path || strings.HasPrefix(path, r.Path+"/")
+	}
+	for p := path; ; p = stdpath.Dir(p) {
+		if ok, _ := stdpath.Match(r.Path, p); ok {
+			return true
+		}
+		if p == "/" {
+			return false
+		}
+	}
+}
+
+// AclAllowed check whether the action on the path is allowed by the rules,
+// the most specific rule that matches the path wins, and the deny rule wins
+// if they are the same specific. It's allowed if no rule matches.
+func AclAllowed(rules []AclRule, path, action string) bool {
+	allowed, length := true, -1
+	for _, r := range rules {
+		if r.Action != action || !r.Match(path) {
+			continue
+		}
+		if len(r.Path) > length || (len(r.Path) == length && !r.Allow) {
+			allowed, length = r.Allow, len(r.Path)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,33 @@
+package model
+
+import "testing"
+
+func TestAclAllowed(t *testing.T) {
+	rules := []AclRule{
+		{Path: "/team", Action: AclWrite, Allow: false},
+		{Path: "/team/a", Action: AclWrite, Allow: true},
+		{Path: "/team/*/private", Action: AclRead, Allow: false},
+		{Path: "/pub", Action: AclDelete, Allow: true},
+		{Path: "/pub", Action: AclDelete, Allow: false},
+	}
+	tests := []struct {
+		path   string
+		action string
+		want   bool
+	}{
+		{"/team", AclWrite, false},
+		{"/team/b/x.txt", AclWrite, false},
+		{"/team/a", AclWrite, true},
+		{"/team/a/x.txt", AclWrite, true},
+		{"/team/ab", AclWrite, false},
+		{"/team/b/x.txt", AclRead, true},
+		{"/team/b/private/x.txt", AclRead, false},
+		{"/pub/x.txt", AclDelete, false},
+		{"/other", AclWrite, true},
+	}
+	for _, tt := range tests {
+		if got := AclAllowed(rules, tt.path, tt.action); got != tt.want {
+			t.Errorf("AclAllowed(%s, %s) = %v, want %v", tt.path, tt.action, got, tt.want)
+		}
+	}
+}
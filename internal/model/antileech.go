@@ -0,0 +1,24 @@
+package model
+
+const (
+	AntiLeechGlobal  = "global"
+	AntiLeechStorage = "storage"
+)
+
+// AntiLeech reject the downloads by the referer and the user agent, the rules of the
+// storage are applied with the global ones together
+type AntiLeech struct {
+	ID    uint   `json:"id" gorm:"primaryKey"`
+	Scope string `json:"scope" gorm:"uniqueIndex:idx_anti_leech_target" binding:"required"` // global or storage
+	// the id of the storage, ignored if the scope is global
+	TargetID uint `json:"target_id" gorm:"uniqueIndex:idx_anti_leech_target"`
+	// the hosts of the referers allowed one in a line, such as example.com or *.example.com,
+	// the referers aren't checked if it's empty, and alist itself is always allowed
+	AllowedReferers string `json:"allowed_referers"`
+	// the downloads without the referer are allowed, such as opened in the players
+	AllowEmptyReferer bool `json:"allow_empty_referer"`
+	// the regexps of the user agents rejected one in a line
+	BlockedUserAgents string `json:"blocked_user_agents"`
+	// the rejected are redirected to it, or 403 is responded if it's empty
+	RedirectURL string `json:"redirect_url"`
+}
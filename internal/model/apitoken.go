@@ -0,0 +1,78 @@
+package model
+
+import (
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+const (
+	ApiTokenFull   = "full"
+	ApiTokenRead   = "read"
+	ApiTokenUpload = "upload"
+)
+
+// ApiToken is a long-lived token of the user for the scripts, restricted to
+// the paths and the operations of the scope, only the sha256 of it is stored
+type ApiToken struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	UserID uint   `json:"user_id" gorm:"index"`
+	Name   string `json:"name" binding:"required"`
+	Hash   string `json:"-" gorm:"unique"`
+	// the paths relative to the base path of the user, separated by newline, empty means all
+	Paths     string     `json:"paths"`
+	Scope     string     `json:"scope"` // full, read or upload
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (t ApiToken) Expired() bool {
+	return t.ExpiresAt != nil && t.ExpiresAt.Before(time.Now())
+}
+
+func (t ApiToken) actions() []string {
+	switch t.Scope {
+	case ApiTokenRead:
+		return []string{AclRead}
+	case ApiTokenUpload:
+		return []string{AclWrite}
+	default:
+		return []string{AclRead, AclWrite, AclDelete, AclShare}
+	}
+}
+
+// AclRules convert the restrictions of the token to acl rules,
+// the actions out of the scope are denied, and the actions in the scope are only allowed in the paths
+func (t ApiToken) AclRules(basePath string) []AclRule {
+	var paths []string
+	for _, p := range strings.Split(t.Paths, "\n") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		p = stdpath.Join(utils.StandardizePath(basePath), stdpath.Join("/", p))
+		if p == "/" {
+			// all the paths are allowed
+			paths = nil
+			break
+		}
+		paths = append(paths, p)
+	}
+	allowed := t.actions()
+	var rules []AclRule
+	for _, action := range []string{AclRead, AclWrite, AclDelete, AclShare} {
+		if !utils.SliceContains(allowed, action) {
+			rules = append(rules, AclRule{Path: "/", Action: action, Allow: false})
+			continue
+		}
+		if len(paths) == 0 {
+			continue
+		}
+		rules = append(rules, AclRule{Path: "/", Action: action, Allow: false})
+		for _, p := range paths {
+			rules = append(rules, AclRule{Path: p, Action: action, Allow: true})
+		}
+	}
+	return rules
+}
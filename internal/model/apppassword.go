@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// AppPassword is used by the clients like webdav instead of the password of the user,
+// only the sha256 of it is stored
+type AppPassword struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"index"`
+	Name      string    `json:"name" binding:"required"`
+	Hash      string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
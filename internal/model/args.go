@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"time"
@@ -23,6 +24,15 @@ type Link struct {
 	Status     int            // status maybe 200 or 206, etc
 	FilePath   *string        // local file, return the filepath
 	Expiration *time.Duration // url expiration time
+	// RangeReader read the file from the offset to the end, it's optional for the Data
+	// links, and used to serve the range requests without reading from the start
+	RangeReader func(offset int64) (io.ReadCloser, error)
+	// Refresh get a new link when the url is rejected by the provider, such as it's expired.
+	// it's set by op.Link for the url links
+	Refresh func(ctx context.Context) (*Link, error)
+	// Concurrency is the count of the ranged requests to fetch the url concurrently when
+	// proxying, it's set by op.Link with the config of the storage if the driver doesn't
+	Concurrency int
 }
 
 type OtherArgs struct {
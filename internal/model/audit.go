@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// the operations recorded in the audit log
+const (
+	AuditList     = "list"
+	AuditDownload = "download"
+	AuditUpload   = "upload"
+	AuditMakeDir  = "mkdir"
+	AuditRename   = "rename"
+	AuditMove     = "move"
+	AuditCopy     = "copy"
+	AuditRemove   = "remove"
+	AuditShare    = "share"
+)
+
+// AuditLog is a record of who did the operation on the path, and the result of it
+type AuditLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+	UserID    uint      `json:"user_id"`
+	Username  string    `json:"username" gorm:"index"`
+	IP        string    `json:"ip"`
+	Op        string    `json:"op" gorm:"index"`
+	Path      string    `json:"path"`
+	// the dst dir of moving and copying, or the new name of renaming
+	Target  string `json:"target"`
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// AuditQuery filter the audit logs, the empty fields are ignored
+type AuditQuery struct {
+	Username string     `json:"username" form:"username"`
+	IP       string     `json:"ip" form:"ip"`
+	Op       string     `json:"op" form:"op"`
+	Path     string     `json:"path" form:"path"` // the logs of the path and the objects in it
+	Start    *time.Time `json:"start" form:"start"`
+	End      *time.Time `json:"end" form:"end"`
+}
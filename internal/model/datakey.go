@@ -0,0 +1,22 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// DataKey is the key encrypting the data of a scope, such as the files in a path,
+// it's encrypted by the master key in the database
+type DataKey struct {
+	ID    uint   `json:"id" gorm:"primaryKey"`
+	Scope string `json:"scope" gorm:"unique"`
+	// the base64 of the key
+	Key       EncryptedString `json:"-"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// PathKeyScope is the scope of the data key of the files in the path, the key is kept
+// after the storage using it is deleted, so the files can be read by a new one
+func PathKeyScope(path string) string {
+	return fmt.Sprintf("path:%s", path)
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// FileDrop allow the anonymous users to upload into the dir without listing it
+type FileDrop struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	Key       string `json:"key" gorm:"unique"` // used in the link
+	Path      string `json:"path" binding:"required"`
+	Remark    string `json:"remark"`
+	Password  string `json:"password"`
+	CreatorID uint   `json:"creator_id"` // the uploads are done as the creator
+	// the max size of each file, 0 means no limit
+	MaxFileSize int64      `json:"max_file_size"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	Disabled    bool       `json:"disabled"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (d FileDrop) Available() bool {
+	return !d.Disabled && (d.ExpiresAt == nil || d.ExpiresAt.After(time.Now()))
+}
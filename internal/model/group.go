@@ -0,0 +1,28 @@
+package model
+
+// Group bundles the permissions and the base path for its users,
+// a user can belong to multiple groups
+type Group struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	Name       string `json:"name" gorm:"unique" binding:"required"`
+	BasePath   string `json:"base_path"`
+	Permission int32  `json:"permission"` // same bits as User.Permission
+}
+
+type UserGroup struct {
+	UserID  uint `json:"user_id" gorm:"primaryKey"`
+	GroupID uint `json:"group_id" gorm:"primaryKey;index"`
+}
+
+// Effective return the user with the permissions of the groups added,
+// the base path of the user is kept if set, or the first group's base path is used
+func (u User) Effective(groups []Group) *User {
+	u.Groups = groups
+	for _, g := range groups {
+		u.Permission |= g.Permission
+		if u.BasePath == "" {
+			u.BasePath = g.BasePath
+		}
+	}
+	return &u
+}
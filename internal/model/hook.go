@@ -0,0 +1,14 @@
+package model
+
+// Hook is a script run on the objects listed and the links got under the path,
+// one rule per line, such as `hide if ext in (nfo, txt)`, see the package hook for the syntax
+type Hook struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" binding:"required"`
+	// the rules are applied to the objects under the path
+	Path   string `json:"path" binding:"required"`
+	Script string `json:"script" gorm:"type:text"`
+	// the hooks are run in the order
+	Order    int  `json:"order"`
+	Disabled bool `json:"disabled"`
+}
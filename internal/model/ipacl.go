@@ -0,0 +1,28 @@
+package model
+
+import "github.com/alist-org/alist/v3/pkg/utils"
+
+// IPAcl restrict the ips of the clients, the lists are the ips or the cidrs, one in a line
+type IPAcl struct {
+	AllowedIPs string `json:"allowed_ips" gorm:"type:text"` // only the ips in it are allowed if it isn't empty
+	DeniedIPs  string `json:"denied_ips" gorm:"type:text"`  // the ips in it are denied, it's checked first
+}
+
+// AllowIP check if the ip of the client is allowed, the empty ip is the requests not from the
+// clients, such as the tasks, they are always allowed
+func (a IPAcl) AllowIP(ip string) bool {
+	if ip == "" {
+		return true
+	}
+	if utils.IPInList(ip, a.DeniedIPs) {
+		return false
+	}
+	return a.AllowedIPs == "" || utils.IPInList(ip, a.AllowedIPs)
+}
+
+func (a IPAcl) Validate() error {
+	if err := utils.CheckIPList(a.AllowedIPs); err != nil {
+		return err
+	}
+	return utils.CheckIPList(a.DeniedIPs)
+}
@@ -0,0 +1,29 @@
+package model
+
+import "testing"
+
+func TestIPAcl_AllowIP(t *testing.T) {
+	acl := IPAcl{
+		AllowedIPs: "192.168.1.0/24\n10.8.0.2",
+		DeniedIPs:  "192.168.1.100",
+	}
+	tests := map[string]bool{
+		"":              true,
+		"192.168.1.5":   true,
+		"10.8.0.2":      true,
+		"192.168.1.100": false,
+		"192.168.2.5":   false,
+		"10.8.0.3":      false,
+	}
+	for ip, want := range tests {
+		if got := acl.AllowIP(ip); got != want {
+			t.Errorf("AllowIP(%s) = %v, want %v", ip, got, want)
+		}
+	}
+	if !(IPAcl{DeniedIPs: "fd00::/8"}).AllowIP("1.1.1.1") || (IPAcl{DeniedIPs: "fd00::/8"}).AllowIP("fd00::1") {
+		t.Errorf("the deny list isn't checked")
+	}
+	if err := (IPAcl{AllowedIPs: "10.0.0.0/33"}).Validate(); err == nil {
+		t.Errorf("the invalid cidr is accepted")
+	}
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+const (
+	JobCopy  = "copy"
+	JobMove  = "move"
+	JobClean = "clean"
+)
+
+// Job is run periodically by the scheduler, the objects in SrcPath are copied or moved to DstPath,
+// or removed if they are not modified in the last MaxAge days for the clean job
+type Job struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	Name     string `json:"name" binding:"required"`
+	Cron     string `json:"cron" binding:"required"` // the cron spec, such as `0 3 * * *`
+	Type     string `json:"type" binding:"required"` // copy, move or clean
+	SrcPath  string `json:"src_path" binding:"required"`
+	DstPath  string `json:"dst_path"`
+	MaxAge   int    `json:"max_age"` // days
+	Disabled bool   `json:"disabled"`
+}
+
+const (
+	JobRunRunning   = "running"
+	JobRunSucceeded = "succeeded"
+	JobRunErrored   = "errored"
+)
+
+// JobRun is a record of running the job
+type JobRun struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	JobID      uint       `json:"job_id" gorm:"index"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	State      string     `json:"state"`
+	Message    string     `json:"message"`
+}
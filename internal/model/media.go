@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// MediaInfo the metadata parsed from the headers of the image, the audio or the video
+type MediaInfo struct {
+	Exif   *Exif `json:"exif,omitempty"`
+	Width  int   `json:"width,omitempty"`
+	Height int   `json:"height,omitempty"`
+	// the duration in seconds
+	Duration   float64 `json:"duration,omitempty"`
+	VideoCodec string  `json:"video_codec,omitempty"`
+	AudioCodec string  `json:"audio_codec,omitempty"`
+	// the bitrate in bits per second
+	Bitrate int64 `json:"bitrate,omitempty"`
+}
+
+type Exif struct {
+	Make      string `json:"make,omitempty"`
+	Model     string `json:"model,omitempty"`
+	LensModel string `json:"lens_model,omitempty"`
+	// the time the photo was taken, it's in the local time of the camera if
+	// the offset isn't recorded
+	CaptureTime  *time.Time `json:"capture_time,omitempty"`
+	ExposureTime string     `json:"exposure_time,omitempty"`
+	FNumber      float64    `json:"f_number,omitempty"`
+	ISO          int        `json:"iso,omitempty"`
+	FocalLength  float64    `json:"focal_length,omitempty"`
+	Orientation  int        `json:"orientation,omitempty"`
+	Latitude     *float64   `json:"latitude,omitempty"`
+	Longitude    *float64   `json:"longitude,omitempty"`
+	Altitude     *float64   `json:"altitude,omitempty"`
+}
@@ -11,4 +11,17 @@ type Meta struct {
 	HSub     bool   `json:"h_sub"`
 	Readme   string `json:"readme"`
 	RSub     bool   `json:"r_sub"`
+
+	// the rules to hide or block the objects, such as `block if ext in (zip) and role not in (admin)`,
+	// see the package hook for the syntax, only hide and block are allowed
+	Filter string `json:"filter" gorm:"type:text"`
+	FSub   bool   `json:"f_sub"`
+
+	// the header shown above the listing and the footer below it in markdown, the readme is
+	// the same. the content is read from the first existing file in the dir listed if it's like
+	// `@README.md,top.md`
+	Header    string `json:"header" gorm:"type:text"`
+	HeaderSub bool   `json:"header_sub"`
+	Footer    string `json:"footer" gorm:"type:text"`
+	FooterSub bool   `json:"footer_sub"`
 }
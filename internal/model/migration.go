@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+// SchemaMigration is a migration of the database applied, by the id of the migration
+type SchemaMigration struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	AppliedAt time.Time `json:"applied_at"`
+}
@@ -14,6 +14,7 @@ type Obj interface {
 	IsDir() bool
 	GetID() string
 	GetPath() string
+	GetHash() HashInfo
 }
 
 type FileStreamer interface {
@@ -23,6 +24,20 @@ type FileStreamer interface {
 	SetReadCloser(io.ReadCloser)
 	NeedStore() bool
 	GetReadCloser() io.ReadCloser
+	GetSha1() string
+	SetSize(int64)
+}
+
+// HashInfo is the hash of the content given by the provider
+type HashInfo struct {
+	// the name of the algorithm such as sha1, md5, empty if unknown
+	Type string `json:"type"`
+	// the hash in lower case hex
+	Value string `json:"value"`
+}
+
+func (h HashInfo) Empty() bool {
+	return h.Type == "" || h.Value == ""
 }
 
 type URL interface {
@@ -9,6 +9,7 @@ type Object struct {
 	Size     int64
 	Modified time.Time
 	IsFolder bool
+	Hash     HashInfo
 }
 
 func (o *Object) GetName() string {
@@ -35,6 +36,10 @@ func (o *Object) GetPath() string {
 	return o.Path
 }
 
+func (o *Object) GetHash() HashInfo {
+	return o.Hash
+}
+
 func (o *Object) SetPath(id string) {
 	o.Path = id
 }
@@ -0,0 +1,10 @@
+package model
+
+// OfflineDownloadStatus is the status of the offline download task in the provider,
+// Error is the reason if the task is failed
+type OfflineDownloadStatus struct {
+	Progress  float64 `json:"progress"` // 0 to 100
+	Status    string  `json:"status"`
+	Completed bool    `json:"completed"`
+	Error     string  `json:"error"`
+}
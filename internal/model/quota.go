@@ -0,0 +1,9 @@
+package model
+
+// StorageQuota is the capacity of a storage in bytes,
+// Total is 0 if the provider doesn't limit the capacity
+type StorageQuota struct {
+	Total int64 `json:"total"`
+	Used  int64 `json:"used"`
+	Free  int64 `json:"free"`
+}
@@ -0,0 +1,19 @@
+package model
+
+const (
+	RateLimitGlobal  = "global"
+	RateLimitStorage = "storage"
+	RateLimitUser    = "user"
+)
+
+// RateLimit limit the bytes per second of downloading and uploading,
+// the limits of the storage or user are applied with the global limit together
+type RateLimit struct {
+	ID    uint   `json:"id" gorm:"primaryKey"`
+	Scope string `json:"scope" gorm:"uniqueIndex:idx_rate_limit_target" binding:"required"` // global, storage or user
+	// the id of the storage or user, ignored if the scope is global
+	TargetID uint `json:"target_id" gorm:"uniqueIndex:idx_rate_limit_target"`
+	// bytes per second, 0 means unlimited
+	Download int64 `json:"download"`
+	Upload   int64 `json:"upload"`
+}
@@ -0,0 +1,11 @@
+package model
+
+// S3Key is the credential used to access the s3 gateway,
+// requests signed with it act as the user it belongs to
+type S3Key struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	UserId    uint   `json:"user_id" binding:"required"`
+	AccessKey string `json:"access_key" gorm:"unique"`
+	SecretKey string `json:"secret_key"`
+	Remark    string `json:"remark"`
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// SearchNode is a search result, Parent is the path of the folder
+// that contains the object
+type SearchNode struct {
+	Parent string `json:"parent"`
+	Name   string `json:"name"`
+	IsDir  bool   `json:"is_dir"`
+	Size   int64  `json:"size"`
+}
+
+// IndexNode is an object in the search index, Content is
+// the text extracted from the file if it's enabled
+type IndexNode struct {
+	ID     uint   `json:"-" gorm:"primaryKey"`
+	Parent string `json:"parent" gorm:"index"`
+	Name   string `json:"name"`
+	IsDir  bool   `json:"is_dir"`
+	Size   int64  `json:"size"`
+	// Type is the file type such as conf.VIDEO, it's conf.FOLDER for the dirs
+	Type     int       `json:"type"`
+	Ext      string    `json:"ext"`
+	Modified time.Time `json:"modified"`
+	Content  string    `json:"-"`
+}
+
+func (n IndexNode) SearchNode() SearchNode {
+	return SearchNode{
+		Parent: n.Parent,
+		Name:   n.Name,
+		IsDir:  n.IsDir,
+		Size:   n.Size,
+	}
+}
+
+// NodeType get the type of the object used in the search index
+func NodeType(name string, isDir bool) int {
+	if isDir {
+		return conf.FOLDER
+	}
+	return utils.GetFileType(name)
+}
+
+// NodeExt get the lower case extension of the file used in the search index, it's empty for the dirs
+func NodeExt(name string, isDir bool) string {
+	if isDir {
+		return ""
+	}
+	return strings.ToLower(utils.Ext(name))
+}
+
+// SearchFilters narrow the search results, the zero values mean no filter
+type SearchFilters struct {
+	// Type is the file type such as conf.VIDEO, conf.FOLDER for the dirs
+	Type int `json:"type" form:"type"`
+	// Exts are the extensions without the dot, the files matching any of them are kept
+	Exts           []string   `json:"exts" form:"exts"`
+	MinSize        int64      `json:"min_size" form:"min_size"`
+	MaxSize        int64      `json:"max_size" form:"max_size"`
+	ModifiedAfter  *time.Time `json:"modified_after" form:"modified_after"`
+	ModifiedBefore *time.Time `json:"modified_before" form:"modified_before"`
+}
+
+func (f SearchFilters) IsEmpty() bool {
+	return f.Type == 0 && len(f.Exts) == 0 && f.MinSize == 0 && f.MaxSize == 0 && !f.HasDate()
+}
+
+func (f SearchFilters) HasDate() bool {
+	return f.ModifiedAfter != nil || f.ModifiedBefore != nil
+}
+
+// LowerExts get the extensions in lower case without the leading dot
+func (f SearchFilters) LowerExts() []string {
+	exts := make([]string, 0, len(f.Exts))
+	for _, ext := range f.Exts {
+		if ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), ".")); ext != "" {
+			exts = append(exts, ext)
+		}
+	}
+	return exts
+}
+
+// Match check the node with the filters except the dates, the node has no modified time
+func (f SearchFilters) Match(node SearchNode) bool {
+	if f.Type != 0 && NodeType(node.Name, node.IsDir) != f.Type {
+		return false
+	}
+	if exts := f.LowerExts(); len(exts) > 0 && !utils.SliceContains(exts, NodeExt(node.Name, node.IsDir)) {
+		return false
+	}
+	if f.MinSize > 0 && node.Size < f.MinSize {
+		return false
+	}
+	if f.MaxSize > 0 && node.Size > f.MaxSize {
+		return false
+	}
+	return true
+}
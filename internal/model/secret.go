@@ -0,0 +1,38 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/kms"
+)
+
+// EncryptedString is encrypted by the master key when saved to the database,
+// if the master key changed, the value can't be decrypted and is kept as it is
+type EncryptedString string
+
+func (s EncryptedString) Value() (driver.Value, error) {
+	return kms.Encrypt(string(s))
+}
+
+func (s *EncryptedString) Scan(value interface{}) error {
+	var str string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		str = v
+	case []byte:
+		str = string(v)
+	default:
+		return fmt.Errorf("can't scan %T into EncryptedString", value)
+	}
+	// the values saved before the encryption is added are plain
+	plain, err := kms.Decrypt(str)
+	if err != nil {
+		// don't fail the query, the secret just doesn't work
+		*s = EncryptedString(str)
+		return nil
+	}
+	*s = EncryptedString(plain)
+	return nil
+}
@@ -7,6 +7,10 @@ const (
 	GLOBAL
 	SINGLE
 	ARIA2
+	SSO
+	QBITTORRENT
+	OFFLINE_DOWNLOAD
+	INDEX
 )
 
 const (
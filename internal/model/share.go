@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Share is a short link to a file or folder, the objects are accessed as the creator
+type Share struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	Key      string `json:"key" gorm:"unique"` // used in the link
+	UserID   uint   `json:"user_id" gorm:"index"`
+	Path     string `json:"path"` // the path in alist, not relative to the base path of the user
+	Remark   string `json:"remark"`
+	Password string `json:"password"`
+	// the count of downloads, 0 means no limit
+	MaxDownloads int64 `json:"max_downloads"`
+	Downloads    int64 `json:"downloads"`
+	// bytes per second of all the downloads of the share, 0 means unlimited
+	RateLimit int64      `json:"rate_limit"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (s Share) Expired() bool {
+	return s.ExpiresAt != nil && s.ExpiresAt.Before(time.Now())
+}
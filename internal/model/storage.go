@@ -8,13 +8,18 @@ type Storage struct {
 	Order           int       `json:"order"`                                       // use to sort
 	Driver          string    `json:"driver"`                                      // driver used
 	CacheExpiration int       `json:"cache_expiration"`                            // cache expire time
+	CacheMaxEntries int       `json:"cache_max_entries"`                           // the max count of the dirs cached, 0 if unlimited
 	Status          string    `json:"status"`
 	Addition        string    `json:"addition" gorm:"type:text"` // Additional information, defined in the corresponding driver
 	Remark          string    `json:"remark"`
 	Modified        time.Time `json:"modified"`
-	Disabled        bool      `json:"disabled"` // if disabled
+	Disabled        bool      `json:"disabled"`    // if disabled
+	ReadOnly        bool      `json:"read_only"`   // the objects can't be changed
+	UploadOnly      bool      `json:"upload_only"` // the objects are hidden, only uploading is allowed
 	Sort
 	Proxy
+	// the ips the storage can be accessed from
+	IPAcl
 }
 
 type Sort struct {
@@ -27,14 +32,27 @@ type Proxy struct {
 	WebProxy     bool   `json:"web_proxy"`
 	WebdavPolicy string `json:"webdav_policy"`
 	DownProxyUrl string `json:"down_proxy_url"`
+	// the count of the ranged requests to fetch the file concurrently when proxying,
+	// for the providers throttling the single connection, 0 or 1 if disabled
+	ProxyConcurrency int `json:"proxy_concurrency"`
+	// the thumbnails of the provider are fetched and cached by alist, for the ones
+	// expiring soon or rejecting the requests from the browsers
+	ThumbProxy bool `json:"thumb_proxy"`
 }
 
 func (s *Storage) GetStorage() *Storage {
 	return s
 }
 
+// OnStatusChanged is called when the status of a storage is changed by SetStatus, it's set on startup
+var OnStatusChanged func(s *Storage, old string)
+
 func (s *Storage) SetStatus(status string) {
+	old := s.Status
 	s.Status = status
+	if old != status && OnStatusChanged != nil {
+		OnStatusChanged(s, old)
+	}
 }
 
 func (p Proxy) Webdav302() bool {
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"encoding/json"
+
+	"github.com/alist-org/alist/v3/internal/kms"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// SecretPlaceholder replace the secrets in the responses, the old value is kept if it's submitted
+const SecretPlaceholder = "******"
+
+// the json names of the secret fields in the addition, by the name of the driver
+var secretFields = map[string][]string{}
+
+// RegisterSecretFields set the secret fields of the driver, it's called when the driver is registered
+func RegisterSecretFields(driver string, fields []string) {
+	secretFields[driver] = fields
+}
+
+// mapSecrets replace the non-empty secrets in the addition with the values returned by fn
+func (s *Storage) mapSecrets(fn func(name, value string) string) {
+	fields := secretFields[s.Driver]
+	if len(fields) == 0 || s.Addition == "" {
+		return
+	}
+	// the invalid addition is reported when the storage is initialized
+	var addition map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(s.Addition), &addition); err != nil {
+		return
+	}
+	changed := false
+	for _, name := range fields {
+		var value string
+		if raw, ok := addition[name]; !ok || json.Unmarshal(raw, &value) != nil || value == "" {
+			continue
+		}
+		if v := fn(name, value); v != value {
+			raw, _ := json.Marshal(v)
+			addition[name] = raw
+			changed = true
+		}
+	}
+	if changed {
+		data, _ := json.Marshal(addition)
+		s.Addition = string(data)
+	}
+}
+
+func (s *Storage) encryptSecrets() error {
+	var err error
+	s.mapSecrets(func(name, value string) string {
+		if err != nil || kms.IsEncrypted(value) {
+			return value
+		}
+		var v string
+		if v, err = kms.Encrypt(value); err != nil {
+			return value
+		}
+		return v
+	})
+	return err
+}
+
+func (s *Storage) decryptSecrets() {
+	s.mapSecrets(func(name, value string) string {
+		v, err := kms.Decrypt(value)
+		if err != nil {
+			// keep the value, so it isn't lost if the old master key is restored
+			log.Warnf("failed to decrypt [%s] of the storage [%s]: %s", name, s.MountPath, err)
+			return value
+		}
+		return v
+	})
+}
+
+func (s *Storage) BeforeSave(tx *gorm.DB) error {
+	return s.encryptSecrets()
+}
+
+// AfterSave keep the storage saved usable, it's loaded with the addition after saved usually
+func (s *Storage) AfterSave(tx *gorm.DB) error {
+	s.decryptSecrets()
+	return nil
+}
+
+func (s *Storage) AfterFind(tx *gorm.DB) error {
+	s.decryptSecrets()
+	return nil
+}
+
+// RedactSecrets replace the secrets with the placeholder, for the responses
+func (s *Storage) RedactSecrets() {
+	s.mapSecrets(func(name, value string) string {
+		return SecretPlaceholder
+	})
+}
+
+// MapSecrets replace the non-empty secrets in the addition with the values returned by fn,
+// such as encrypting them with the password of a backup
+func (s *Storage) MapSecrets(fn func(name, value string) string) {
+	s.mapSecrets(fn)
+}
+
+// RestoreSecrets replace the placeholders submitted with the secrets in the old storage
+func (s *Storage) RestoreSecrets(old Storage) {
+	var oldAddition map[string]json.RawMessage
+	_ = json.Unmarshal([]byte(old.Addition), &oldAddition)
+	s.mapSecrets(func(name, value string) string {
+		if value != SecretPlaceholder {
+			return value
+		}
+		var v string
+		_ = json.Unmarshal(oldAddition[name], &v)
+		return v
+	})
+}
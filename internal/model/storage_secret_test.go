@@ -0,0 +1,33 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+)
+
+func TestStorageSecrets(t *testing.T) {
+	conf.Conf = &conf.Config{MasterKey: "master"}
+	RegisterSecretFields("Test", []string{"token"})
+	s := Storage{Driver: "Test", Addition: `{"token":"abc","root":"/"}`}
+	if err := s.encryptSecrets(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(s.Addition, "abc") || !strings.Contains(s.Addition, `"root":"/"`) {
+		t.Fatalf("the secret isn't encrypted: %s", s.Addition)
+	}
+	s.decryptSecrets()
+	if s.Addition != `{"root":"/","token":"abc"}` {
+		t.Fatalf("the secret isn't decrypted: %s", s.Addition)
+	}
+	old := s
+	s.RedactSecrets()
+	if strings.Contains(s.Addition, "abc") {
+		t.Fatalf("the secret isn't redacted: %s", s.Addition)
+	}
+	s.RestoreSecrets(old)
+	if s.Addition != old.Addition {
+		t.Fatalf("the secret isn't restored: %s", s.Addition)
+	}
+}
@@ -9,6 +9,8 @@ type FileStream struct {
 	io.ReadCloser
 	Mimetype     string
 	WebPutAsTask bool
+	// the sha1 of the content, empty if it's unknown
+	Sha1 string
 }
 
 func (f *FileStream) GetMimetype() string {
@@ -26,3 +28,21 @@ func (f *FileStream) GetReadCloser() io.ReadCloser {
 func (f *FileStream) SetReadCloser(rc io.ReadCloser) {
 	f.ReadCloser = rc
 }
+
+func (f *FileStream) GetSha1() string {
+	return f.Sha1
+}
+
+// SetSize set the size of the stream, used when it's unknown until the stream is read
+func (f *FileStream) SetSize(size int64) {
+	f.Obj = &sizedObj{Obj: f.Obj, size: size}
+}
+
+type sizedObj struct {
+	Obj
+	size int64
+}
+
+func (o *sizedObj) GetSize() int64 {
+	return o.size
+}
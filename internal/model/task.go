@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// InterruptedTask is the task not finished when the server was shut down, it's kept for the admin
+// to know what should be done again after restarting
+type InterruptedTask struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	Type      string    `json:"type"`
+	TaskID    string    `json:"task_id"`
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	Status    string    `json:"status"`
+	Progress  int       `json:"progress"`
+	Error     string    `json:"error"`
+}
@@ -0,0 +1,31 @@
+package model
+
+const (
+	TrafficQuotaUser  = "user"
+	TrafficQuotaGroup = "group"
+
+	TrafficDaily   = "daily"
+	TrafficMonthly = "monthly"
+)
+
+// TrafficQuota limit the bytes downloaded and uploaded through alist by the user in the period,
+// the quota of a group is applied to each user of it. the quota of the user is used if set,
+// otherwise the largest one of the groups is used
+type TrafficQuota struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	Scope    string `json:"scope" gorm:"uniqueIndex:idx_traffic_quota_target" binding:"required"` // user or group
+	TargetID uint   `json:"target_id" gorm:"uniqueIndex:idx_traffic_quota_target"`
+	Period   string `json:"period" gorm:"uniqueIndex:idx_traffic_quota_target" binding:"required"` // daily or monthly
+	// bytes in the period, 0 means unlimited
+	Download int64 `json:"download"`
+	Upload   int64 `json:"upload"`
+}
+
+// TrafficUsage is the bytes transferred by the user in the period started at Start
+type TrafficUsage struct {
+	UserID   uint   `json:"user_id" gorm:"primaryKey"`
+	Period   string `json:"period" gorm:"primaryKey"`
+	Start    string `json:"start" gorm:"primaryKey"` // the date the period starts, such as 2006-01-02
+	Download int64  `json:"download"`
+	Upload   int64  `json:"upload"`
+}
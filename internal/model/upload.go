@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// UploadSession is a resumable upload, the chunks are appended to a file in the upload dir,
+// and it's put to the storage when all the bytes are received
+type UploadSession struct {
+	ID       string `json:"id" gorm:"primaryKey;size:64"`
+	UserId   uint   `json:"user_id" gorm:"index"`
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	Offset   int64  `json:"offset"`
+	Mimetype string `json:"mimetype"`
+	Sha1     string `json:"sha1"`
+	AsTask   bool   `json:"as_task"`
+	// the session expires if no chunk is received for a while
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DirectUpload is the urls the client uploads the file to the provider with directly,
+// the file is put to the url in one request, or the parts of it are put to the part urls in order
+type DirectUpload struct {
+	URL string `json:"url,omitempty"`
+	// the headers the requests to the urls must have
+	Header map[string]string `json:"header,omitempty"`
+	// the id of the multipart upload, empty if it isn't multipart
+	UploadID string   `json:"upload_id,omitempty"`
+	PartSize int64    `json:"part_size,omitempty"`
+	PartURLs []string `json:"part_urls,omitempty"`
+}
+
+// DirectUploadPart is a part uploaded, the etag is in the header of the response of the part url
+type DirectUploadPart struct {
+	Number int    `json:"number"`
+	ETag   string `json:"etag"`
+}
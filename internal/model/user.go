@@ -28,8 +28,15 @@ type User struct {
 	//  7: can remove
 	//  8: webdav read
 	//  9: webdav write
-	Permission int32  `json:"permission"`
-	OtpSecret  string `json:"-"`
+	Permission int32           `json:"permission"`
+	OtpSecret  EncryptedString `json:"-"`
+	// the sha256 of the unused recovery codes of 2FA, separated by comma
+	OtpRecoveryCodes string `json:"-"`
+	SsoID            string `json:"sso_id" gorm:"index"` // the subject of the oidc provider, the user can sso login if set
+	// the ips the user can sign in from
+	IPAcl
+	// the groups of the user, only set for the effective user
+	Groups []Group `json:"groups,omitempty" gorm:"-"`
 }
 
 func (u User) IsGuest() bool {
@@ -0,0 +1,21 @@
+package model
+
+// VideoPreview the streams transcoded of the video for the players, the qualities
+// are ordered from the lowest to the highest
+type VideoPreview struct {
+	Qualities []VideoQuality  `json:"qualities"`
+	Subtitles []VideoSubtitle `json:"subtitles"`
+}
+
+type VideoQuality struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	// the url of the hls playlist
+	URL string `json:"url"`
+}
+
+type VideoSubtitle struct {
+	Language string `json:"language"`
+	URL      string `json:"url"`
+}
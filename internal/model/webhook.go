@@ -0,0 +1,26 @@
+package model
+
+const (
+	WebhookJSON       = "json"
+	WebhookTelegram   = "telegram"
+	WebhookBark       = "bark"
+	WebhookServerChan = "serverchan"
+)
+
+// Webhook send the events to the url in json, or notify by the telegram bot, bark or server酱
+type Webhook struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" binding:"required"`
+	Kind string `json:"kind" binding:"required"` // json, telegram, bark or serverchan
+	// the url of json, or the server of the others, the official one is used if it's empty
+	URL string `json:"url"`
+	// the secret signing the body of json, the token of the telegram bot,
+	// the device key of bark or the send key of server酱
+	Token  string `json:"token"`
+	ChatID string `json:"chat_id"` // the chat of the telegram bot
+	// the types of the events separated by commas, all the events if it's empty
+	Events string `json:"events"`
+	// only the events of the paths in it are sent if it's set, the events without the path aren't filtered
+	PathPrefix string `json:"path_prefix"`
+	Disabled   bool   `json:"disabled"`
+}
@@ -0,0 +1,9 @@
+// Package offline_download registers the tools of the offline download,
+// the common logic is in the tool package
+package offline_download
+
+import (
+	_ "github.com/alist-org/alist/v3/internal/offline_download/aria2"
+	_ "github.com/alist-org/alist/v3/internal/offline_download/qbit"
+	_ "github.com/alist-org/alist/v3/internal/offline_download/storage"
+)
@@ -0,0 +1,102 @@
+package aria2
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/aria2/rpc"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type Aria2 struct {
+	client rpc.Client
+}
+
+func (a *Aria2) Name() string {
+	return "aria2"
+}
+
+func (a *Aria2) Init() (string, error) {
+	a.client = nil
+	uri := setting.GetStr(conf.Aria2Uri)
+	secret := setting.GetStr(conf.Aria2Secret)
+	c, err := rpc.New(context.Background(), uri, secret, 2*time.Second, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to init aria2 client")
+	}
+	version, err := c.GetVersion()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed get aria2 version")
+	}
+	a.client = c
+	log.Infof("using aria2 version: %s", version.Version)
+	return version.Version, nil
+}
+
+func (a *Aria2) IsReady() bool {
+	return a.client != nil
+}
+
+func (a *Aria2) AddURL(args *tool.AddUrlArgs) (string, error) {
+	options := map[string]interface{}{
+		"dir": args.TempDir,
+	}
+	return a.client.AddURI([]string{args.Url}, options)
+}
+
+func (a *Aria2) Remove(gid string) error {
+	// the stopped downloads can't be removed, but their results can be
+	if _, err := a.client.Remove(gid); err != nil {
+		_, err = a.client.RemoveDownloadResult(gid)
+		return err
+	}
+	return nil
+}
+
+func (a *Aria2) Status(gid string) (*tool.Status, error) {
+	info, err := a.client.TellStatus(gid)
+	if err != nil {
+		return nil, err
+	}
+	s := &tool.Status{
+		Status: info.Status,
+	}
+	if len(info.FollowedBy) != 0 {
+		s.NewGID = info.FollowedBy[0]
+		return s, nil
+	}
+	total, err := strconv.ParseUint(info.TotalLength, 10, 64)
+	if err != nil {
+		total = 0
+	}
+	downloaded, err := strconv.ParseUint(info.CompletedLength, 10, 64)
+	if err != nil {
+		downloaded = 0
+	}
+	if total > 0 {
+		s.Progress = float64(downloaded) / float64(total) * 100
+	}
+	switch info.Status {
+	case "complete":
+		s.Completed = true
+	case "error":
+		s.Err = errors.Errorf("failed to download %s, error: %s", gid, info.ErrorMessage)
+	case "active", "waiting", "paused":
+	case "removed":
+		s.Err = errors.Errorf("failed to download %s, removed", gid)
+	default:
+		s.Err = errors.Errorf("failed to download %s, unknown status %s", gid, info.Status)
+	}
+	return s, nil
+}
+
+var _ tool.Tool = (*Aria2)(nil)
+
+func init() {
+	tool.RegisterTool(&Aria2{})
+}
@@ -10,6 +10,7 @@ import (
 	conf2 "github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/task"
 	"gorm.io/driver/sqlite"
@@ -30,8 +31,17 @@ func init() {
 	db.Init(dB)
 }
 
+var aria2 = &Aria2{}
+
 func TestConnect(t *testing.T) {
-	_, err := InitAria2Client("http://localhost:16800/jsonrpc", "secret", 3)
+	err := db.SaveSettingItems([]model.SettingItem{
+		{Key: conf2.Aria2Uri, Value: "http://localhost:16800/jsonrpc"},
+		{Key: conf2.Aria2Secret, Value: "secret"},
+	})
+	if err != nil {
+		t.Fatalf("failed to save settings: %+v", err)
+	}
+	_, err = aria2.Init()
 	if err != nil {
 		t.Errorf("failed to init aria2: %+v", err)
 	}
@@ -51,11 +61,15 @@ func TestDown(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create storage: %+v", err)
 	}
-	err = AddURI(context.Background(), "https://nodejs.org/dist/index.json", "/test")
+	err = tool.AddURL(context.Background(), &tool.AddURLArgs{
+		URL:        "https://nodejs.org/dist/index.json",
+		DstDirPath: "/test",
+		Tool:       "aria2",
+	})
 	if err != nil {
 		t.Errorf("failed to add uri: %+v", err)
 	}
-	tasks := DownTaskManager.GetAll()
+	tasks := tool.DownTaskManager.GetAll()
 	if len(tasks) != 1 {
 		t.Errorf("failed to get tasks: %+v", tasks)
 	}
@@ -71,10 +85,10 @@ func TestDown(t *testing.T) {
 		time.Sleep(time.Second)
 	}
 	for {
-		if len(TransferTaskManager.GetAll()) == 0 {
+		if len(tool.TransferTaskManager.GetAll()) == 0 {
 			continue
 		}
-		tsk := TransferTaskManager.GetAll()[0]
+		tsk := tool.TransferTaskManager.GetAll()[0]
 		t.Logf("task: %+v", tsk)
 		if tsk.GetState() == task.SUCCEEDED {
 			break
@@ -0,0 +1,66 @@
+package qbit
+
+import (
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/qbittorrent"
+	"github.com/pkg/errors"
+)
+
+type QBittorrent struct {
+	client qbittorrent.Client
+}
+
+func (q *QBittorrent) Name() string {
+	return "qbittorrent"
+}
+
+func (q *QBittorrent) Init() (string, error) {
+	q.client = nil
+	c, err := qbittorrent.New(setting.GetStr(conf.QbittorrentUrl))
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to init qbittorrent client")
+	}
+	q.client = c
+	return "ok", nil
+}
+
+func (q *QBittorrent) IsReady() bool {
+	return q.client != nil
+}
+
+// AddURL the download is identified by the category named with the uid of the task
+func (q *QBittorrent) AddURL(args *tool.AddUrlArgs) (string, error) {
+	err := q.client.AddFromLink(args.Url, args.TempDir, args.UID)
+	if err != nil {
+		return "", err
+	}
+	return args.UID, nil
+}
+
+func (q *QBittorrent) Remove(gid string) error {
+	return q.client.Delete(gid, true)
+}
+
+func (q *QBittorrent) Status(gid string) (*tool.Status, error) {
+	info, err := q.client.GetInfo(gid)
+	if err != nil {
+		return nil, err
+	}
+	s := &tool.Status{
+		Progress:  info.Progress * 100,
+		Status:    info.State,
+		Completed: info.Completed(),
+	}
+	if info.Errored() {
+		s.Err = errors.Errorf("failed to download %s, state: %s", info.Name, info.State)
+	}
+	return s, nil
+}
+
+var _ tool.Tool = (*QBittorrent)(nil)
+
+func init() {
+	tool.RegisterTool(&QBittorrent{})
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/pkg/errors"
+)
+
+// Storage downloads the url with the provider of the dst storage, such as
+// the cloud download of aliyundrive, so the content never touches alist
+type Storage struct {
+	tasks generic_sync.MapOf[string, *remoteTask]
+}
+
+type remoteTask struct {
+	storage    driver.Driver
+	dstDirPath string // the actual path in the storage
+	id         string // the id of the task in the provider
+}
+
+func (s *Storage) Name() string {
+	return "storage"
+}
+
+func (s *Storage) Init() (string, error) {
+	return "ok", nil
+}
+
+func (s *Storage) IsReady() bool {
+	return true
+}
+
+func (s *Storage) AddURL(args *tool.AddUrlArgs) (string, error) {
+	ctx := context.Background()
+	storage, dstDirActualPath, err := op.GetStorageAndActualPath(args.DstDirPath)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get storage")
+	}
+	downloader, ok := storage.(driver.OfflineDownload)
+	if !ok {
+		return "", errors.Errorf("storage [%s] doesn't support offline download", storage.GetStorage().MountPath)
+	}
+	if err = op.MakeDir(ctx, storage, dstDirActualPath); err != nil {
+		return "", errors.WithMessagef(err, "failed to make dir [%s]", dstDirActualPath)
+	}
+	dstDir, err := op.Get(ctx, storage, dstDirActualPath)
+	if err != nil {
+		return "", errors.WithMessagef(err, "failed to get dir [%s]", dstDirActualPath)
+	}
+	id, err := downloader.OfflineDownload(ctx, args.Url, dstDir)
+	if err != nil {
+		return "", err
+	}
+	s.tasks.Store(args.UID, &remoteTask{
+		storage:    storage,
+		dstDirPath: dstDirActualPath,
+		id:         id,
+	})
+	return args.UID, nil
+}
+
+// Remove the task is forgotten, but it can't be canceled in the provider
+func (s *Storage) Remove(gid string) error {
+	s.tasks.Delete(gid)
+	return nil
+}
+
+func (s *Storage) Status(gid string) (*tool.Status, error) {
+	t, ok := s.tasks.Load(gid)
+	if !ok {
+		return nil, errors.Errorf("task %s not found", gid)
+	}
+	status, err := t.storage.(driver.OfflineDownload).OfflineDownloadStatus(context.Background(), t.id)
+	if err != nil {
+		return nil, err
+	}
+	res := &tool.Status{
+		Progress:  status.Progress,
+		Status:    status.Status,
+		Completed: status.Completed,
+	}
+	if status.Error != "" {
+		res.Err = errors.New(status.Error)
+	}
+	if status.Completed {
+		op.ClearCache(t.storage, t.dstDirPath)
+	}
+	return res, nil
+}
+
+// Remote the files are downloaded to the storage directly
+func (s *Storage) Remote() bool {
+	return true
+}
+
+var _ tool.Tool = (*Storage)(nil)
+var _ tool.Remote = (*Storage)(nil)
+
+func init() {
+	tool.RegisterTool(&Storage{})
+}
@@ -0,0 +1,75 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/task"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type AddURLArgs struct {
+	URL        string
+	DstDirPath string
+	Tool       string
+}
+
+// AddURL download the url with the tool, and transfer the files to the dst dir when it's completed
+func AddURL(ctx context.Context, args *AddURLArgs) error {
+	tool, err := Get(args.Tool)
+	if err != nil {
+		return err
+	}
+	if !tool.IsReady() {
+		return errors.Errorf("%s not ready", args.Tool)
+	}
+	// check storage
+	storage, dstDirActualPath, err := op.GetStorageAndActualPath(args.DstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	// check is it could upload
+	if storage.Config().NoUpload {
+		return errors.WithStack(errs.UploadNotSupported)
+	}
+	// check path is valid
+	obj, err := op.Get(ctx, storage, dstDirActualPath)
+	if err != nil {
+		if !errs.IsObjectNotFound(err) {
+			return errors.WithMessage(err, "failed get object")
+		}
+	} else {
+		if !obj.IsDir() {
+			// can't add to a file
+			return errors.WithStack(errs.NotFolder)
+		}
+	}
+	uid := uuid.NewString()
+	tempDir := filepath.Join(conf.Conf.TempDir, args.Tool, uid)
+	gid, err := tool.AddURL(&AddUrlArgs{
+		Url:        args.URL,
+		UID:        uid,
+		TempDir:    tempDir,
+		DstDirPath: args.DstDirPath,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to add url %s", args.URL)
+	}
+	t := &DownloadTask{
+		Url:        args.URL,
+		DstDirPath: args.DstDirPath,
+		TempDir:    tempDir,
+		GID:        gid,
+		tool:       tool,
+	}
+	DownTaskManager.Submit(task.WithCancelCtx(&task.Task[uint64]{
+		Name: fmt.Sprintf("download %s to [%s](%s) with %s", args.URL, storage.GetStorage().MountPath, dstDirActualPath, args.Tool),
+		Func: t.Run,
+	}))
+	return nil
+}
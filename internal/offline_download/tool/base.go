@@ -0,0 +1,39 @@
+package tool
+
+type AddUrlArgs struct {
+	Url string
+	// UID is the unique id of the task, the tools use it to identify the download if they need
+	UID     string
+	TempDir string
+	// DstDirPath is the path of the dst dir in alist
+	DstDirPath string
+}
+
+type Status struct {
+	Progress  float64
+	NewGID    string
+	Completed bool
+	Status    string
+	Err       error
+}
+
+// Tool is the backend of the offline download, it downloads the url to
+// the temp dir, and the files are transferred to the storage by alist
+type Tool interface {
+	Name() string
+	// Init initialize the tool with the settings, return the info of it such as the version
+	Init() (string, error)
+	IsReady() bool
+	// AddURL add the url to download to args.TempDir, return the gid of the download in the tool
+	AddURL(args *AddUrlArgs) (string, error)
+	// Remove the download from the tool, it's called when the task is canceled or the files are transferred
+	Remove(gid string) error
+	// Status return the status of the download
+	Status(gid string) (*Status, error)
+}
+
+// Remote is implemented by the tools that download the files to the dst storage
+// directly, so the files needn't be transferred by alist
+type Remote interface {
+	Remote() bool
+}
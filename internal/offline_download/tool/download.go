@@ -0,0 +1,91 @@
+package tool
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/pkg/task"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var DownTaskManager = task.NewTaskManager(3, func(k *uint64) {
+	atomic.AddUint64(k, 1)
+})
+
+type DownloadTask struct {
+	Url        string
+	DstDirPath string
+	TempDir    string
+	// GID is the id of the download in the tool, it may be changed by the tool,
+	// such as the torrent downloaded by aria2 is followed by the download of its files
+	GID     string
+	tool    Tool
+	tsk     *task.Task[uint64]
+	retried int
+}
+
+func (t *DownloadTask) Run(tsk *task.Task[uint64]) error {
+	t.tsk = tsk
+	for {
+		select {
+		case <-tsk.Ctx.Done():
+			err := t.tool.Remove(t.GID)
+			t.clearTempDir()
+			return err
+		case <-time.After(time.Second * 2):
+			completed, err := t.update()
+			if err != nil {
+				if err := t.tool.Remove(t.GID); err != nil {
+					log.Errorf("failed to remove the download %s of %s: %+v", t.GID, t.tool.Name(), err)
+				}
+				t.clearTempDir()
+				return err
+			}
+			if completed && isRemote(t.tool) {
+				_ = t.tool.Remove(t.GID)
+				tsk.SetStatus("completed")
+				return nil
+			}
+			if completed {
+				tsk.SetStatus(fmt.Sprintf("%s download completed, transferring", t.tool.Name()))
+				return t.transfer()
+			}
+		}
+	}
+}
+
+// update the progress and status of the task, return true if the download is completed
+func (t *DownloadTask) update() (bool, error) {
+	info, err := t.tool.Status(t.GID)
+	if err != nil {
+		t.retried++
+		if t.retried > 5 {
+			return false, errors.WithMessagef(err, "failed to get status of %s, retried %d times", t.GID, t.retried)
+		}
+		return false, nil
+	}
+	t.retried = 0
+	if info.NewGID != "" {
+		t.GID = info.NewGID
+	}
+	t.tsk.SetProgress(int(info.Progress))
+	t.tsk.SetStatus(fmt.Sprintf("%s: %s", t.tool.Name(), info.Status))
+	if info.Err != nil {
+		return false, errors.WithMessagef(info.Err, "failed to download %s", t.Url)
+	}
+	return info.Completed, nil
+}
+
+func (t *DownloadTask) clearTempDir() {
+	if err := os.RemoveAll(t.TempDir); err != nil {
+		log.Errorf("failed to remove the temp dir of %s: %+v", t.tool.Name(), err)
+	}
+}
+
+func isRemote(tool Tool) bool {
+	r, ok := tool.(Remote)
+	return ok && r.Remote()
+}
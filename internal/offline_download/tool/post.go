@@ -0,0 +1,109 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var PostTaskManager = task.NewTaskManager(3, func(k *uint64) {
+	atomic.AddUint64(k, 1)
+})
+
+type transfer struct {
+	tsk  *task.Task[uint64]
+	path string
+	size int64
+}
+
+// PostProcess is the last step of the offline download after the files are transferred,
+// the source files are deleted and the webhook is called if they are configured
+type PostProcess struct {
+	Download *DownloadTask
+	// Skipped are the files smaller than the min size, relative to the temp dir
+	Skipped   []string
+	transfers []transfer
+}
+
+type WebhookFile struct {
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+type WebhookPayload struct {
+	Url     string        `json:"url"`
+	Tool    string        `json:"tool"`
+	DstDir  string        `json:"dst_dir"`
+	Files   []WebhookFile `json:"files"`
+	Skipped []string      `json:"skipped"`
+}
+
+func (p *PostProcess) Run(tsk *task.Task[uint64]) error {
+	d := p.Download
+	if setting.GetBool(conf.OfflineDownloadDeleteSource) {
+		tsk.SetStatus("deleting the source files")
+		if err := d.tool.Remove(d.GID); err != nil {
+			log.Errorf("failed to remove the download %s of %s: %+v", d.GID, d.tool.Name(), err)
+		}
+		d.clearTempDir()
+	}
+	tsk.SetProgress(50)
+	if url := setting.GetStr(conf.OfflineDownloadWebhook); url != "" {
+		tsk.SetStatus("calling the webhook")
+		if err := p.callWebhook(tsk.Ctx, url); err != nil {
+			return err
+		}
+	}
+	tsk.SetProgress(100)
+	tsk.SetStatus("completed")
+	return nil
+}
+
+func (p *PostProcess) callWebhook(ctx context.Context, url string) error {
+	payload := WebhookPayload{
+		Url:     p.Download.Url,
+		Tool:    p.Download.tool.Name(),
+		DstDir:  p.Download.DstDirPath,
+		Files:   make([]WebhookFile, 0, len(p.transfers)),
+		Skipped: p.Skipped,
+	}
+	for _, tr := range p.transfers {
+		payload.Files = append(payload.Files, WebhookFile{
+			Path:  tr.path,
+			Size:  tr.size,
+			State: tr.tsk.GetState(),
+			Error: tr.tsk.GetErrMsg(),
+		})
+	}
+	body, err := utils.Json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "invalid webhook url")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to call the webhook")
+	}
+	_ = res.Body.Close()
+	if res.StatusCode >= 400 {
+		return errors.Errorf("failed to call the webhook, status: %s", res.Status)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package tool
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+var tools = make(map[string]Tool)
+
+func RegisterTool(tool Tool) {
+	tools[tool.Name()] = tool
+}
+
+func Get(name string) (Tool, error) {
+	if tool, ok := tools[name]; ok {
+		return tool, nil
+	}
+	return nil, errors.Errorf("offline download tool %s not found", name)
+}
+
+func Names() []string {
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// All return all the registered tools
+func All() []Tool {
+	res := make([]Tool, 0, len(tools))
+	for _, name := range Names() {
+		res = append(res, tools[name])
+	}
+	return res
+}
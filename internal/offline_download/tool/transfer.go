@@ -0,0 +1,151 @@
+package tool
+
+import (
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+var TransferTaskManager = task.NewTaskManager(3, func(k *uint64) {
+	atomic.AddUint64(k, 1)
+})
+
+// transfer the downloaded files in the temp dir to the dst dir, the files are put into the dir
+// of the path pattern keeping the structure of the dirs, the small files are skipped,
+// then the post process task is submitted after all the transfer tasks are done
+func (t *DownloadTask) transfer() error {
+	storage, dstDirActualPath, err := op.GetStorageAndActualPath(t.DstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	var files []string
+	err = filepath.WalkDir(t.TempDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to walk the temp dir %s", t.TempDir)
+	}
+	minSize := int64(setting.GetInt(conf.OfflineDownloadMinFileSize, 0)) * 1024 * 1024
+	pattern := setting.GetStr(conf.OfflineDownloadPathPattern)
+	now := time.Now()
+	post := &PostProcess{Download: t, Skipped: []string{}}
+	for _, file := range files {
+		file := file
+		rel, err := filepath.Rel(t.TempDir, file)
+		if err != nil {
+			return err
+		}
+		stat, err := os.Stat(file)
+		if err != nil {
+			return errors.Wrapf(err, "failed to stat file %s", file)
+		}
+		if stat.Size() < minSize {
+			post.Skipped = append(post.Skipped, filepath.ToSlash(rel))
+			continue
+		}
+		relDir := stdpath.Join(patternDir(pattern, t.tool.Name(), file, now), filepath.ToSlash(filepath.Dir(rel)))
+		dstDir := stdpath.Join(dstDirActualPath, relDir)
+		tsk := task.WithCancelCtx(&task.Task[uint64]{
+			Name: fmt.Sprintf("transfer %s to [%s](%s)", file, storage.GetStorage().MountPath, dstDir),
+			Func: func(tsk *task.Task[uint64]) error {
+				return transferFile(tsk, storage, file, dstDir)
+			},
+		})
+		TransferTaskManager.Submit(tsk)
+		post.transfers = append(post.transfers, transfer{
+			tsk:  tsk,
+			path: stdpath.Join(t.DstDirPath, relDir, stat.Name()),
+			size: stat.Size(),
+		})
+	}
+	// the canceled pending tasks are never run, so wait for them by the state or ctx
+	for _, tr := range post.transfers {
+		for !tr.tsk.Done() && tr.tsk.Ctx.Err() == nil {
+			time.Sleep(time.Second)
+		}
+	}
+	PostTaskManager.Submit(task.WithCancelCtx(&task.Task[uint64]{
+		Name: fmt.Sprintf("post process the download of %s", t.Url),
+		Func: post.Run,
+	}))
+	t.tsk.SetStatus("completed")
+	return nil
+}
+
+// patternDir return the dir of the file by the path pattern, it's relative to the dst dir,
+// {tool}, {category} and {date} in the pattern are replaced
+func patternDir(pattern, tool, file string, now time.Time) string {
+	if pattern == "" {
+		return ""
+	}
+	dir := strings.NewReplacer(
+		"{tool}", tool,
+		"{category}", fileCategory(file),
+		"{date}", now.Format("2006-01-02"),
+	).Replace(pattern)
+	// the pattern can't escape from the dst dir
+	return stdpath.Join("/", dir)
+}
+
+func fileCategory(file string) string {
+	switch utils.GetFileType(file) {
+	case conf.VIDEO:
+		return "video"
+	case conf.AUDIO:
+		return "audio"
+	case conf.IMAGE:
+		return "image"
+	case conf.TEXT:
+		return "text"
+	default:
+		return "other"
+	}
+}
+
+func transferFile(tsk *task.Task[uint64], storage driver.Driver, file, dstDir string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open file %s", file)
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return errors.Wrapf(err, "failed to stat file %s", file)
+	}
+	mimetype := mime.TypeByExtension(filepath.Ext(file))
+	if mimetype == "" {
+		mimetype = "application/octet-stream"
+	}
+	stream := &model.FileStream{
+		Obj: &model.Object{
+			Name:     filepath.Base(file),
+			Size:     stat.Size(),
+			Modified: time.Now(),
+			IsFolder: false,
+		},
+		ReadCloser: f,
+		Mimetype:   mimetype,
+	}
+	return op.Put(tsk.Ctx, storage, dstDir, stream, tsk.SetProgress)
+}
@@ -0,0 +1,131 @@
+package op
+
+import (
+	"container/list"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+var listCache = cache.NewMemCache(cache.WithShards[[]model.Obj](64))
+
+// the keys of the list cache of each storage, by the mount path.
+// go-cache can't limit the count of the items or delete them by prefix, so they are tracked here
+var listKeys generic_sync.MapOf[string, *cacheKeys]
+
+type cacheKeys struct {
+	mu sync.Mutex
+	// the front is the most recently used
+	lru   *list.List
+	elems map[string]*list.Element
+}
+
+func storageKeys(storage driver.Driver) *cacheKeys {
+	keys, _ := listKeys.LoadOrStore(storage.GetStorage().MountPath, &cacheKeys{
+		lru:   list.New(),
+		elems: make(map[string]*list.Element),
+	})
+	return keys
+}
+
+func getListCache(storage driver.Driver, key string) ([]model.Obj, bool) {
+	files, ok := listCache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	keys := storageKeys(storage)
+	keys.mu.Lock()
+	if elem, ok := keys.elems[key]; ok {
+		keys.lru.MoveToFront(elem)
+	}
+	keys.mu.Unlock()
+	return files, true
+}
+
+// setListCache cache the objects in the dir for CacheExpiration minutes, the least
+// recently used dirs of the storage are dropped if there are more than CacheMaxEntries
+func setListCache(storage driver.Driver, key string, files []model.Obj) {
+	s := storage.GetStorage()
+	listCache.Set(key, files, cache.WithEx[[]model.Obj](time.Minute*time.Duration(s.CacheExpiration)))
+	keys := storageKeys(storage)
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+	if elem, ok := keys.elems[key]; ok {
+		keys.lru.MoveToFront(elem)
+	} else {
+		keys.elems[key] = keys.lru.PushFront(key)
+	}
+	for s.CacheMaxEntries > 0 && keys.lru.Len() > s.CacheMaxEntries {
+		keys.remove(keys.lru.Back())
+	}
+}
+
+func (k *cacheKeys) remove(elem *list.Element) {
+	key := k.lru.Remove(elem).(string)
+	delete(k.elems, key)
+	listCache.Del(key)
+}
+
+func ClearCache(storage driver.Driver, path string) {
+	key := stdpath.Join(storage.GetStorage().MountPath, path)
+	keys := storageKeys(storage)
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+	if elem, ok := keys.elems[key]; ok {
+		keys.remove(elem)
+		return
+	}
+	listCache.Del(key)
+}
+
+// ClearCacheUnder clear the list cache of the virtual path and all the dirs under it,
+// return the count of the dirs cleared
+func ClearCacheUnder(path string) int {
+	path = utils.StandardizePath(path)
+	count := 0
+	listKeys.Range(func(mountPath string, keys *cacheKeys) bool {
+		// the storage is mounted under the path or the path is in the storage
+		if !utils.IsSubPath(path, mountPath) && !utils.IsSubPath(mountPath, path) {
+			return true
+		}
+		keys.mu.Lock()
+		defer keys.mu.Unlock()
+		for key, elem := range keys.elems {
+			if utils.IsSubPath(path, key) {
+				keys.remove(elem)
+				count++
+			}
+		}
+		return true
+	})
+	return count
+}
+
+// clearStorageCache clear all the list cache of the storage mounted at the path
+func clearStorageCache(mountPath string) {
+	keys, ok := listKeys.Load(mountPath)
+	if !ok {
+		return
+	}
+	listKeys.Delete(mountPath)
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+	for _, elem := range keys.elems {
+		keys.remove(elem)
+	}
+}
+
+func clearAllCache() {
+	listCache.Clear()
+	listKeys.Clear()
+}
+
+func Key(storage driver.Driver, path string) string {
+	return stdpath.Join(storage.GetStorage().MountPath, utils.StandardizePath(path))
+}
@@ -0,0 +1,62 @@
+package op_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func names(t *testing.T, ctx context.Context, storage driver.Driver, path string) string {
+	objs, err := op.List(ctx, storage, path, model.ListArgs{})
+	if err != nil {
+		t.Fatalf("failed to list [%s]: %+v", path, err)
+	}
+	var s string
+	for _, obj := range objs {
+		s += obj.GetName() + ","
+	}
+	return s
+}
+
+func TestListCache(t *testing.T) {
+	ctx := context.Background()
+	// the virtual driver lists the random objects, so the same result means it's cached
+	err := op.CreateStorage(ctx, model.Storage{Driver: "Virtual", MountPath: "/virtual", CacheExpiration: 30, CacheMaxEntries: 2,
+		Addition: `{"num_file":1,"num_folder":2,"max_file_size":1,"min_file_size":1}`})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByVirtualPath("/virtual")
+	if err != nil {
+		t.Fatalf("failed to get storage: %+v", err)
+	}
+	root := names(t, ctx, storage, "/")
+	if names(t, ctx, storage, "/") != root {
+		t.Fatalf("the root isn't cached")
+	}
+	objs, _ := op.List(ctx, storage, "/", model.ListArgs{})
+	var dirs []string
+	for _, obj := range objs {
+		if obj.IsDir() {
+			dirs = append(dirs, "/"+obj.GetName())
+		}
+	}
+	a := names(t, ctx, storage, dirs[0])
+	// the root is used to get the dir, so the first dir is the least recently used one
+	names(t, ctx, storage, dirs[1])
+	if names(t, ctx, storage, dirs[0]) == a {
+		t.Errorf("the least recently used dir isn't dropped")
+	}
+	if names(t, ctx, storage, "/") != root {
+		t.Errorf("the root is dropped")
+	}
+	if count := op.ClearCacheUnder("/virtual"); count != 2 {
+		t.Errorf("expect 2 dirs cleared, got %d", count)
+	}
+	if names(t, ctx, storage, "/") == root {
+		t.Errorf("the root isn't cleared")
+	}
+}
@@ -0,0 +1,84 @@
+package op
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/kms"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const dataKeySize = 32
+
+// the decrypted data keys by the scope
+var dataKeys generic_sync.MapOf[string, []byte]
+
+// avoid creating two keys of the same scope
+var dataKeyMu sync.Mutex
+
+// GetDataKey get the data key of the scope, it's created if not exists
+func GetDataKey(scope string) ([]byte, error) {
+	if key, ok := dataKeys.Load(scope); ok {
+		return key, nil
+	}
+	dataKeyMu.Lock()
+	defer dataKeyMu.Unlock()
+	if key, ok := dataKeys.Load(scope); ok {
+		return key, nil
+	}
+	k, err := db.GetDataKey(scope)
+	if err != nil && !errors.Is(errors.Cause(err), gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if k == nil {
+		key := make([]byte, dataKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		k = &model.DataKey{Scope: scope, Key: model.EncryptedString(base64.StdEncoding.EncodeToString(key))}
+		if err := db.CreateDataKey(k); err != nil {
+			return nil, errors.WithMessage(err, "failed create data key")
+		}
+	}
+	// the key that can't be decrypted is kept as it is when scanned
+	key, err := base64.StdEncoding.DecodeString(string(k.Key))
+	if err != nil || len(key) != dataKeySize {
+		return nil, errors.Errorf("failed to decrypt the data key of [%s], the master key may be changed", scope)
+	}
+	dataKeys.Store(scope, key)
+	return key, nil
+}
+
+// DeleteDataKey delete the data key of the scope, the data encrypted by it can't be decrypted any more
+func DeleteDataKey(scope string) error {
+	dataKeyMu.Lock()
+	defer dataKeyMu.Unlock()
+	dataKeys.Delete(scope)
+	return db.DeleteDataKey(scope)
+}
+
+// RewrapDataKeys encrypt the data keys with the current master key again, the keys encrypted by
+// the jwt secret before the master key is added are upgraded
+func RewrapDataKeys() error {
+	keys, err := db.GetDataKeys()
+	if err != nil {
+		return err
+	}
+	for i := range keys {
+		// the key can't be decrypted, keep it for the old master key
+		if kms.IsEncrypted(string(keys[i].Key)) {
+			log.Warnf("failed to decrypt the data key of [%s], the master key may be changed", keys[i].Scope)
+			continue
+		}
+		if err := db.UpdateDataKey(&keys[i]); err != nil {
+			return errors.WithMessagef(err, "failed update data key of [%s]", keys[i].Scope)
+		}
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/pkg/errors"
 )
 
@@ -21,6 +22,28 @@ func RegisterDriver(config driver.Config, driver New) {
 	driverNewMap[config.Name] = driver
 }
 
+// RegisterPluginDriver register the driver served by a plugin, the additional items are declared
+// by the plugin instead of the fields of the addition
+func RegisterPluginDriver(config driver.Config, additional []driver.Item, newDriver New) error {
+	if _, ok := driverNewMap[config.Name]; ok {
+		return errors.Errorf("driver [%s] is already registered", config.Name)
+	}
+	var secrets []string
+	for _, item := range additional {
+		if item.Secret {
+			secrets = append(secrets, item.Name)
+		}
+	}
+	model.RegisterSecretFields(config.Name, secrets)
+	driverInfoMap[config.Name] = driver.Info{
+		Common:     getMainItems(config),
+		Additional: additional,
+		Config:     config,
+	}
+	driverNewMap[config.Name] = newDriver
+	return nil
+}
+
 func GetDriverNew(name string) (New, error) {
 	n, ok := driverNewMap[name]
 	if !ok {
@@ -46,6 +69,13 @@ func registerDriverItems(config driver.Config, addition driver.Additional) {
 	tAddition := reflect.TypeOf(addition)
 	mainItems := getMainItems(config)
 	additionalItems := getAdditionalItems(tAddition, config.DefaultRoot)
+	var secrets []string
+	for _, item := range additionalItems {
+		if item.Secret {
+			secrets = append(secrets, item.Name)
+		}
+	}
+	model.RegisterSecretFields(config.Name, secrets)
 	driverInfoMap[config.Name] = driver.Info{
 		Common:     mainItems,
 		Additional: additionalItems,
@@ -100,6 +130,10 @@ func getMainItems(config driver.Config) []driver.Item {
 	items = append(items, driver.Item{
 		Name: "down_proxy_url",
 		Type: conf.TypeText,
+	}, driver.Item{
+		Name: "thumb_proxy",
+		Type: conf.TypeBool,
+		Help: "fetch and cache the thumbnails of the provider by alist",
 	})
 	if config.LocalSort {
 		items = append(items, []driver.Item{{
@@ -141,6 +175,7 @@ func getAdditionalItems(t reflect.Type, defaultRoot string) []driver.Item {
 			Options:  tag.Get("options"),
 			Required: tag.Get("required") == "true",
 			Help:     tag.Get("help"),
+			Secret:   tag.Get("secret") == "true",
 		}
 		if tag.Get("type") != "" {
 			item.Type = tag.Get("type")
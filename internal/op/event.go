@@ -0,0 +1,69 @@
+package op
+
+import (
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type EventType int
+
+const (
+	EventMakeDir EventType = iota
+	EventPut
+	EventMove
+	EventRename
+	EventCopy
+	EventRemove
+	// EventChange is emitted when the objects in the dir of Path are changed outside alist
+	EventChange
+)
+
+// Event is emitted after an object is changed through alist, the paths are virtual paths
+type Event struct {
+	Type EventType
+	// Path is the object changed, it's the src object of move, rename and copy
+	Path string
+	// DstPath is the new object of move, rename and copy
+	DstPath string
+}
+
+type EventHook func(e Event)
+
+var eventHooks []EventHook
+
+// RegisterEventHook add a hook called after the objects are changed, it should return quickly
+// since it's called in the operation, hooks are expected to be registered on startup
+func RegisterEventHook(hook EventHook) {
+	eventHooks = append(eventHooks, hook)
+}
+
+func emit(e Event) {
+	for _, hook := range eventHooks {
+		hook(e)
+	}
+}
+
+// virtualPath get the virtual path of the actual path in the storage, it's the reverse of ActualPath
+func virtualPath(storage driver.Driver, path string) string {
+	path = utils.StandardizePath(path)
+	if i, ok := storage.GetAddition().(driver.IRootPath); ok {
+		if root := utils.StandardizePath(i.GetRootPath()); root != "/" {
+			path = utils.StandardizePath(strings.TrimPrefix(path, root))
+		}
+	}
+	return stdpath.Join(utils.GetActualVirtualPath(storage.GetStorage().MountPath), path)
+}
+
+// emitMoved emit the event of move, rename or copy in a storage
+func emitMoved(storage driver.Driver, typ EventType, srcPath, dstPath string) {
+	emit(Event{Type: typ, Path: virtualPath(storage, srcPath), DstPath: virtualPath(storage, dstPath)})
+}
+
+func emitBatchMoved(storage driver.Driver, typ EventType, srcDirPath string, names []string, dstDirPath string) {
+	for _, name := range names {
+		emitMoved(storage, typ, stdpath.Join(srcDirPath, name), stdpath.Join(dstDirPath, name))
+	}
+}
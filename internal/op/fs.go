@@ -10,6 +10,7 @@ import (
 	"github.com/Xhofe/go-cache"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/singleflight"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -19,23 +20,25 @@ import (
 
 // In order to facilitate adding some other things before and after file op
 
-var listCache = cache.NewMemCache(cache.WithShards[[]model.Obj](64))
-var listG singleflight.Group[[]model.Obj]
-
-func ClearCache(storage driver.Driver, path string) {
-	key := stdpath.Join(storage.GetStorage().MountPath, path)
-	listCache.Del(key)
+// observe record the call of the driver of the storage in the metrics and the health, the missing object isn't a failure
+func observe(storage driver.Driver, op string, err error) {
+	if errs.IsObjectNotFound(err) {
+		err = nil
+	}
+	metrics.DriverCall(storage.Config().Name, op, err)
+	recordHealth(storage, err)
 }
 
-func Key(storage driver.Driver, path string) string {
-	return stdpath.Join(storage.GetStorage().MountPath, utils.StandardizePath(path))
-}
+var listG singleflight.Group[[]model.Obj]
 
 // List files in storage, not contains virtual file
 func List(ctx context.Context, storage driver.Driver, path string, args model.ListArgs, refresh ...bool) ([]model.Obj, error) {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
+	if err := checkHealth(storage); err != nil {
+		return nil, err
+	}
 	path = utils.StandardizePath(path)
 	log.Debugf("op.List %s", path)
 	dir, err := Get(ctx, storage, path)
@@ -48,25 +51,58 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 	}
 	if storage.Config().NoCache {
 		objs, err := storage.List(ctx, dir, args)
+		observe(storage, "list", err)
 		return objs, errors.WithStack(err)
 	}
 	key := Key(storage, path)
 	if len(refresh) == 0 || !refresh[0] {
-		if files, ok := listCache.Get(key); ok {
+		files, ok := getListCache(storage, key)
+		metrics.CacheLookup("list", ok)
+		if ok {
 			return files, nil
 		}
 	}
 	objs, err, _ := listG.Do(key, func() ([]model.Obj, error) {
 		files, err := storage.List(ctx, dir, args)
+		observe(storage, "list", err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to list objs")
 		}
-		listCache.Set(key, files, cache.WithEx[[]model.Obj](time.Minute*time.Duration(storage.GetStorage().CacheExpiration)))
+		setListCache(storage, key, files)
 		return files, nil
 	})
 	return objs, err
 }
 
+// ListPage list a page of the objects in the path with the driver, errs.NotSupport is returned if it can't page.
+// the pages aren't cached, so the large dirs are listed without keeping all the objects
+func ListPage(ctx context.Context, storage driver.Driver, path, marker string, limit int) ([]model.Obj, string, error) {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return nil, "", errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	if err := checkHealth(storage); err != nil {
+		return nil, "", err
+	}
+	pager, ok := storage.(driver.ListPager)
+	if !ok {
+		return nil, "", errors.WithStack(errs.NotSupport)
+	}
+	path = utils.StandardizePath(path)
+	dir, err := Get(ctx, storage, path)
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "failed get dir")
+	}
+	if !dir.IsDir() {
+		return nil, "", errors.WithStack(errs.NotFolder)
+	}
+	objs, next, err := pager.ListPage(ctx, dir, marker, limit)
+	observe(storage, "list", err)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to list the page of objs")
+	}
+	return objs, next, nil
+}
+
 func isRoot(path, rootFolderPath string) bool {
 	if utils.PathEqual(path, rootFolderPath) {
 		return true
@@ -82,10 +118,14 @@ func isRoot(path, rootFolderPath string) bool {
 
 // Get object from list of files
 func Get(ctx context.Context, storage driver.Driver, path string) (model.Obj, error) {
+	if err := checkHealth(storage); err != nil {
+		return nil, err
+	}
 	path = utils.StandardizePath(path)
 	log.Debugf("op.Get %s", path)
 	if g, ok := storage.(driver.Getter); ok {
 		obj, err := g.Get(ctx, path)
+		observe(storage, "get", err)
 		if err == nil {
 			return obj, nil
 		}
@@ -134,11 +174,27 @@ func Get(ctx context.Context, storage driver.Driver, path string) (model.Obj, er
 var linkCache = cache.NewMemCache(cache.WithShards[*model.Link](16))
 var linkG singleflight.Group[*model.Link]
 
+// the max time before the expiration that the link is dropped from the cache,
+// so the link isn't expired while being downloaded
+const maxLinkExpiryMargin = 5 * time.Minute
+
+// linkCacheTTL get the time to cache the link, it's shortly before the expiration
+func linkCacheTTL(expiration time.Duration) time.Duration {
+	margin := expiration / 10
+	if margin > maxLinkExpiryMargin {
+		margin = maxLinkExpiryMargin
+	}
+	return expiration - margin
+}
+
 // Link get link, if is an url. should have an expiry time
 func Link(ctx context.Context, storage driver.Driver, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return nil, nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
+	if err := CheckRead(storage); err != nil {
+		return nil, nil, err
+	}
 	file, err := Get(ctx, storage, path)
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "failed to get file")
@@ -147,25 +203,45 @@ func Link(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return nil, nil, errors.WithStack(errs.NotFile)
 	}
 	key := stdpath.Join(storage.GetStorage().MountPath, path) + ":" + args.IP
-	if link, ok := linkCache.Get(key); ok {
+	link, ok := linkCache.Get(key)
+	metrics.CacheLookup("link", ok)
+	if ok {
 		return link, file, nil
 	}
-	fn := func() (*model.Link, error) {
-		link, err := storage.Link(ctx, file, args)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed get link")
-		}
-		if link.Expiration != nil {
-			linkCache.Set(key, link, cache.WithEx[*model.Link](*link.Expiration))
-		}
-		return link, nil
+	var fetch func(ctx context.Context) (*model.Link, error)
+	fetch = func(ctx context.Context) (*model.Link, error) {
+		link, err, _ := linkG.Do(key, func() (*model.Link, error) {
+			link, err := storage.Link(ctx, file, args)
+			observe(storage, "link", err)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed get link")
+			}
+			if link.URL != "" {
+				if link.Concurrency == 0 {
+					link.Concurrency = storage.GetStorage().ProxyConcurrency
+				}
+				link.Refresh = func(ctx context.Context) (*model.Link, error) {
+					log.Debugf("refresh the link of [%s]", key)
+					linkCache.Del(key)
+					return fetch(ctx)
+				}
+			}
+			if link.Expiration != nil {
+				linkCache.Set(key, link, cache.WithEx[*model.Link](linkCacheTTL(*link.Expiration)))
+			}
+			return link, nil
+		})
+		return link, err
 	}
-	link, err, _ := linkG.Do(key, fn)
+	link, err = fetch(ctx)
 	return link, file, err
 }
 
 // Other api
 func Other(ctx context.Context, storage driver.Driver, args model.FsOtherArgs) (interface{}, error) {
+	if err := CheckRead(storage); err != nil {
+		return nil, err
+	}
 	obj, err := Get(ctx, storage, args.Path)
 	if err != nil {
 		return nil, errors.WithMessagef(err, "failed to get obj")
@@ -181,10 +257,32 @@ func Other(ctx context.Context, storage driver.Driver, args model.FsOtherArgs) (
 	}
 }
 
+// VideoPreview get the streams of the video transcoded by the provider
+func VideoPreview(ctx context.Context, storage driver.Driver, path string) (*model.VideoPreview, error) {
+	v, ok := storage.(driver.VideoPreviewer)
+	if !ok {
+		return nil, errs.NotImplement
+	}
+	if err := CheckRead(storage); err != nil {
+		return nil, err
+	}
+	obj, err := Get(ctx, storage, path)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to get obj")
+	}
+	if obj.IsDir() {
+		return nil, errors.WithStack(errs.NotFile)
+	}
+	return v.VideoPreview(ctx, obj)
+}
+
 func MakeDir(ctx context.Context, storage driver.Driver, path string) error {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
+	if err := CheckWrite(storage); err != nil {
+		return err
+	}
 	path = utils.StandardizePath(path)
 	// check if dir exists
 	f, err := Get(ctx, storage, path)
@@ -201,8 +299,10 @@ func MakeDir(ctx context.Context, storage driver.Driver, path string) error {
 				return errors.WithMessagef(err, "failed to get parent dir [%s]", parentPath)
 			}
 			err = storage.MakeDir(ctx, parentDir, dirName)
+			observe(storage, "make_dir", err)
 			if err == nil {
 				ClearCache(storage, parentPath)
+				emit(Event{Type: EventMakeDir, Path: virtualPath(storage, path)})
 			}
 			return errors.WithStack(err)
 		} else {
@@ -223,6 +323,9 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
+	if err := CheckModify(storage); err != nil {
+		return err
+	}
 	srcObj, err := Get(ctx, storage, srcPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed to get src object")
@@ -231,18 +334,31 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	if err != nil {
 		return errors.WithMessage(err, "failed to get dst dir")
 	}
-	return errors.WithStack(storage.Move(ctx, srcObj, dstDir))
+	err = storage.Move(ctx, srcObj, dstDir)
+	observe(storage, "move", err)
+	if err == nil {
+		emitMoved(storage, EventMove, srcPath, stdpath.Join(dstDirPath, srcObj.GetName()))
+	}
+	return errors.WithStack(err)
 }
 
 func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string) error {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
+	if err := CheckModify(storage); err != nil {
+		return err
+	}
 	srcObj, err := Get(ctx, storage, srcPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed to get src object")
 	}
-	return errors.WithStack(storage.Rename(ctx, srcObj, dstName))
+	err = storage.Rename(ctx, srcObj, dstName)
+	observe(storage, "rename", err)
+	if err == nil {
+		emitMoved(storage, EventRename, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName))
+	}
+	return errors.WithStack(err)
 }
 
 // Copy Just copy file[s] in a storage
@@ -250,18 +366,75 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
+	if err := CheckTransfer(storage, storage, false); err != nil {
+		return err
+	}
 	srcObj, err := Get(ctx, storage, srcPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed to get src object")
 	}
 	dstDir, err := Get(ctx, storage, dstDirPath)
-	return errors.WithStack(storage.Copy(ctx, srcObj, dstDir))
+	err = storage.Copy(ctx, srcObj, dstDir)
+	observe(storage, "copy", err)
+	if err == nil {
+		emitMoved(storage, EventCopy, srcPath, stdpath.Join(dstDirPath, srcObj.GetName()))
+	}
+	return errors.WithStack(err)
+}
+
+// CrossCopy copy the object in `srcStorage` to `dstStorage` with the provider api if the driver of `srcStorage` supports it,
+// errs.NotSupport is returned if it can't, then the content should be transferred through alist
+func CrossCopy(ctx context.Context, srcStorage, dstStorage driver.Driver, srcPath, dstDirPath string) error {
+	if srcStorage.Config().CheckStatus && srcStorage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", srcStorage.GetStorage().Status)
+	}
+	if dstStorage.Config().CheckStatus && dstStorage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", dstStorage.GetStorage().Status)
+	}
+	if err := CheckTransfer(srcStorage, dstStorage, false); err != nil {
+		return err
+	}
+	crossCopier, ok := srcStorage.(driver.CrossCopy)
+	if !ok {
+		return errors.WithStack(errs.NotSupport)
+	}
+	srcObj, err := Get(ctx, srcStorage, srcPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get src object")
+	}
+	err = MakeDir(ctx, dstStorage, dstDirPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to make dir [%s]", dstDirPath)
+	}
+	dstDir, err := Get(ctx, dstStorage, dstDirPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get dir [%s]", dstDirPath)
+	}
+	err = crossCopier.CrossCopy(ctx, srcObj, dstStorage, dstDir)
+	observe(srcStorage, "cross_copy", err)
+	if err == nil {
+		ClearCache(dstStorage, dstDirPath)
+		emit(Event{
+			Type:    EventCopy,
+			Path:    virtualPath(srcStorage, srcPath),
+			DstPath: virtualPath(dstStorage, stdpath.Join(dstDirPath, srcObj.GetName())),
+		})
+	}
+	return errors.WithStack(err)
 }
 
 func Remove(ctx context.Context, storage driver.Driver, path string) error {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
+	if err := CheckModify(storage); err != nil {
+		return err
+	}
+	return remove(ctx, storage, path)
+}
+
+// remove the object without checking the mode of the storage
+func remove(ctx context.Context, storage driver.Driver, path string) error {
 	obj, err := Get(ctx, storage, path)
 	if err != nil {
 		// if object not found, it's ok
@@ -271,7 +444,9 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 		return errors.WithMessage(err, "failed to get object")
 	}
 	err = storage.Remove(ctx, obj)
+	observe(storage, "remove", err)
 	if err == nil {
+		emit(Event{Type: EventRemove, Path: virtualPath(storage, path)})
 		key := Key(storage, stdpath.Dir(path))
 		if objs, ok := listCache.Get(key); ok {
 			j := -1
@@ -283,7 +458,7 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 			}
 			if j >= 0 && j < len(objs) {
 				objs = append(objs[:j], objs[j+1:]...)
-				listCache.Set(key, objs)
+				setListCache(storage, key, objs)
 			} else {
 				log.Debugf("not found obj")
 			}
@@ -311,12 +486,18 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 			log.Errorf("failed to close file streamer, %v", err)
 		}
 	}()
-	// if file exist and size = 0, delete it
+	if err := CheckWrite(storage); err != nil {
+		return err
+	}
+	if err := checkHealth(storage); err != nil {
+		return err
+	}
+	// if file exist and size = 0, delete it, it's allowed for the upload only storage too
 	dstPath := stdpath.Join(dstDirPath, file.GetName())
 	fi, err := Get(ctx, storage, dstPath)
 	if err == nil {
 		if fi.GetSize() == 0 {
-			err = Remove(ctx, storage, dstPath)
+			err = remove(ctx, storage, dstPath)
 			if err != nil {
 				return errors.WithMessagef(err, "failed remove file that exist and have size 0")
 			}
@@ -336,14 +517,256 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 	if up == nil {
 		up = func(p int) {}
 	}
+	err = spool(storage, file)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to spool file [%s]", file.GetName())
+	}
 	err = storage.Put(ctx, parentDir, file, up)
+	observe(storage, "put", err)
 	log.Debugf("put file [%s] done", file.GetName())
 	if err == nil {
 		// set as complete
 		up(100)
+		metrics.UploadBytes(file.GetSize())
+		emit(Event{Type: EventPut, Path: virtualPath(storage, dstPath)})
 		// clear cache
 		//key := stdpath.Join(storage.GetStorage().MountPath, dstDirPath)
 		//listCache.Del(key)
 	}
 	return errors.WithStack(err)
 }
+
+// DirectUpload get the urls the client uploads the file named `name` of `size` to the dir with directly,
+// errs.NotSupport is returned if the driver can't, then it should be uploaded through alist
+func DirectUpload(ctx context.Context, storage driver.Driver, dstDirPath, name string, size int64) (*model.DirectUpload, error) {
+	uploader, ok := storage.(driver.DirectUploader)
+	if !ok {
+		return nil, errs.NotSupport
+	}
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	if err := CheckWrite(storage); err != nil {
+		return nil, err
+	}
+	if err := checkHealth(storage); err != nil {
+		return nil, err
+	}
+	if err := MakeDir(ctx, storage, dstDirPath); err != nil {
+		return nil, errors.WithMessagef(err, "failed to make dir [%s]", dstDirPath)
+	}
+	parentDir, err := Get(ctx, storage, dstDirPath)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to get dir [%s]", dstDirPath)
+	}
+	res, err := uploader.DirectUpload(ctx, parentDir, name, size)
+	return res, errors.WithStack(err)
+}
+
+// CompleteDirectUpload complete the upload after the client uploaded the file directly
+func CompleteDirectUpload(ctx context.Context, storage driver.Driver, dstDirPath, name, uploadID string, parts []model.DirectUploadPart) error {
+	uploader, ok := storage.(driver.DirectUploader)
+	if !ok {
+		return errs.NotSupport
+	}
+	if err := CheckWrite(storage); err != nil {
+		return err
+	}
+	parentDir, err := Get(ctx, storage, dstDirPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get dir [%s]", dstDirPath)
+	}
+	err = uploader.CompleteDirectUpload(ctx, parentDir, name, uploadID, parts)
+	observe(storage, "put", err)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// the file isn't uploaded through alist, so the cached objects of the dir don't have it
+	ClearCache(storage, dstDirPath)
+	emit(Event{Type: EventPut, Path: virtualPath(storage, stdpath.Join(dstDirPath, name))})
+	return nil
+}
+
+// spool the stream to a temp file if the driver needs a seekable one, or the size of it is unknown,
+// otherwise the stream is passed to the driver directly
+func spool(storage driver.Driver, file model.FileStreamer) error {
+	if !storage.Config().NeedsSeekableUpload && (file.GetSize() >= 0 || storage.Config().OnlyLocal) {
+		return nil
+	}
+	// copy the raw read closer, the wrappers of the stream such as the rate limit apply when the driver reads the temp file
+	tempFile, err := utils.CreateTempFile(file.GetReadCloser())
+	if err != nil {
+		return err
+	}
+	file.SetReadCloser(tempFile)
+	if file.GetSize() < 0 {
+		info, err := tempFile.Stat()
+		if err != nil {
+			return err
+		}
+		file.SetSize(info.Size())
+	}
+	return nil
+}
+
+// getObjs get objects named `names` in `dirPath`, used by batch operations
+func getObjs(ctx context.Context, storage driver.Driver, dirPath string, names []string) ([]model.Obj, error) {
+	objs := make([]model.Obj, 0, len(names))
+	for _, name := range names {
+		obj, err := Get(ctx, storage, stdpath.Join(dirPath, name))
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to get object [%s]", name)
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// BatchMove move objects named `names` in `srcDirPath` to `dstDirPath`,
+// use the BatchMove of driver if implemented, or call Move one by one
+func BatchMove(ctx context.Context, storage driver.Driver, srcDirPath string, names []string, dstDirPath string) error {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	if err := CheckModify(storage); err != nil {
+		return err
+	}
+	srcObjs, err := getObjs(ctx, storage, srcDirPath, names)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get src objects")
+	}
+	dstDir, err := Get(ctx, storage, dstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get dst dir")
+	}
+	if b, ok := storage.(driver.BatchMove); ok {
+		err = b.BatchMove(ctx, srcObjs, dstDir)
+		observe(storage, "batch_move", err)
+		if err == nil {
+			emitBatchMoved(storage, EventMove, srcDirPath, names, dstDirPath)
+		}
+		return errors.WithStack(err)
+	}
+	for _, srcObj := range srcObjs {
+		err := storage.Move(ctx, srcObj, dstDir)
+		observe(storage, "move", err)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to move [%s]", srcObj.GetName())
+		}
+		emitMoved(storage, EventMove, stdpath.Join(srcDirPath, srcObj.GetName()), stdpath.Join(dstDirPath, srcObj.GetName()))
+	}
+	return nil
+}
+
+// BatchCopy copy objects named `names` in `srcDirPath` to `dstDirPath` in a storage
+func BatchCopy(ctx context.Context, storage driver.Driver, srcDirPath string, names []string, dstDirPath string) error {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	if err := CheckTransfer(storage, storage, false); err != nil {
+		return err
+	}
+	srcObjs, err := getObjs(ctx, storage, srcDirPath, names)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get src objects")
+	}
+	dstDir, err := Get(ctx, storage, dstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get dst dir")
+	}
+	if b, ok := storage.(driver.BatchCopy); ok {
+		err = b.BatchCopy(ctx, srcObjs, dstDir)
+		observe(storage, "batch_copy", err)
+		if err == nil {
+			emitBatchMoved(storage, EventCopy, srcDirPath, names, dstDirPath)
+		}
+		return errors.WithStack(err)
+	}
+	for _, srcObj := range srcObjs {
+		err := storage.Copy(ctx, srcObj, dstDir)
+		observe(storage, "copy", err)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to copy [%s]", srcObj.GetName())
+		}
+		emitMoved(storage, EventCopy, stdpath.Join(srcDirPath, srcObj.GetName()), stdpath.Join(dstDirPath, srcObj.GetName()))
+	}
+	return nil
+}
+
+// BatchRemove remove objects named `names` in `dirPath`
+func BatchRemove(ctx context.Context, storage driver.Driver, dirPath string, names []string) error {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	if err := CheckModify(storage); err != nil {
+		return err
+	}
+	b, ok := storage.(driver.BatchRemove)
+	if !ok {
+		for _, name := range names {
+			if err := Remove(ctx, storage, stdpath.Join(dirPath, name)); err != nil {
+				return errors.WithMessagef(err, "failed to remove [%s]", name)
+			}
+		}
+		return nil
+	}
+	objs := make([]model.Obj, 0, len(names))
+	for _, name := range names {
+		obj, err := Get(ctx, storage, stdpath.Join(dirPath, name))
+		if err != nil {
+			// if object not found, it's ok
+			if errs.IsObjectNotFound(err) {
+				continue
+			}
+			return errors.WithMessagef(err, "failed to get object [%s]", name)
+		}
+		objs = append(objs, obj)
+	}
+	if len(objs) == 0 {
+		return nil
+	}
+	err := b.BatchRemove(ctx, objs)
+	observe(storage, "batch_remove", err)
+	if err == nil {
+		ClearCache(storage, dirPath)
+		for _, obj := range objs {
+			emit(Event{Type: EventRemove, Path: virtualPath(storage, stdpath.Join(dirPath, obj.GetName()))})
+		}
+	}
+	return errors.WithStack(err)
+}
+
+// Search objects in storage with the search api of provider
+func Search(ctx context.Context, storage driver.Driver, dirPath, keyword string) ([]model.SearchNode, error) {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	if err := CheckRead(storage); err != nil {
+		return nil, err
+	}
+	s, ok := storage.(driver.Searcher)
+	if !ok {
+		return nil, errors.WithStack(errs.SearchNotSupported)
+	}
+	dirPath = utils.StandardizePath(dirPath)
+	dir, err := Get(ctx, storage, dirPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get dir")
+	}
+	if !dir.IsDir() {
+		return nil, errors.WithStack(errs.NotFolder)
+	}
+	nodes, err := s.Search(ctx, keyword, dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to search")
+	}
+	// make sure all results are in the dir
+	res := make([]model.SearchNode, 0, len(nodes))
+	for _, node := range nodes {
+		node.Parent = utils.StandardizePath(node.Parent)
+		if utils.IsSubPath(dirPath, node.Parent) {
+			res = append(res, node)
+		}
+	}
+	return res, nil
+}
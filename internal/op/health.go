@@ -0,0 +1,299 @@
+package op
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the states of the health of the storages
+const (
+	HealthOnline = "online"
+	// some calls failed recently, but the storage is still used
+	HealthDegraded = "degraded"
+	// the provider rejected the token, the storage isn't called until the retry
+	HealthTokenExpired = "token_expired"
+	// the storage failed to init or the probes failed, the storage isn't called until the retry
+	HealthOffline = "offline"
+)
+
+const (
+	// the probes failed in a row before the storage is offline
+	healthMaxProbeFailures = 3
+	// the refreshes of the token failed in a row before the token is expired
+	healthMaxRefreshFailures = 3
+	healthMinBackoff         = 30 * time.Second
+	healthMaxBackoff         = 30 * time.Minute
+)
+
+type StorageHealth struct {
+	State   string `json:"state"`
+	Message string `json:"message"`
+	// the count of the failures in a row
+	Failures int `json:"failures"`
+	// the count of the refreshes of the token failed in a row
+	RefreshFailures int       `json:"refresh_failures"`
+	LastCheck       time.Time `json:"last_check"`
+	// the storage isn't called until it, zero if it's not backing off
+	RetryAt time.Time `json:"retry_at"`
+}
+
+var (
+	healthMu sync.Mutex
+	healths  = make(map[string]*StorageHealth)
+)
+
+// OnTokenExpired is called when the token of a storage is expired, so the admin can be notified
+// to supply a new refresh token, it's set on startup
+var OnTokenExpired func(storage *model.Storage, err error)
+
+// GetStorageHealth get the health of the storage, it's online if it works and no call failed
+func GetStorageHealth(storage driver.Driver) StorageHealth {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	if h, ok := healths[storage.GetStorage().MountPath]; ok {
+		return *h
+	}
+	if status := storage.GetStorage().Status; status != WORK {
+		return StorageHealth{State: HealthOffline, Message: status}
+	}
+	return StorageHealth{State: HealthOnline}
+}
+
+func resetHealth(mountPath string) {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	delete(healths, mountPath)
+}
+
+// backoff get the delay before the next retry, it's doubled with each failure in a row
+func backoff(failures int) time.Duration {
+	d := healthMinBackoff
+	for i := 1; i < failures && d < healthMaxBackoff; i++ {
+		d *= 2
+	}
+	if d > healthMaxBackoff {
+		d = healthMaxBackoff
+	}
+	return d
+}
+
+// isAuthError check if the provider rejected the token, the drivers return various errors of it
+func isAuthError(err error) bool {
+	if errors.Is(err, errs.RefreshTokenRejected) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "401") || strings.Contains(msg, "unauthorized") {
+		return true
+	}
+	return strings.Contains(msg, "token") &&
+		(strings.Contains(msg, "expired") || strings.Contains(msg, "invalid"))
+}
+
+// ignoredError check if the error is caused by the request instead of the storage
+func ignoredError(err error) bool {
+	return errs.IsObjectNotFound(err) || errors.Is(err, errs.NotSupport) ||
+		errors.Is(err, errs.NotImplement) || errors.Is(err, context.Canceled)
+}
+
+// recordHealth update the health of the storage by the result of a call to the driver,
+// the storage backs off if the provider rejected the token, the other failures only degrade it
+func recordHealth(storage driver.Driver, err error) {
+	if err != nil && ignoredError(err) {
+		return
+	}
+	mountPath := storage.GetStorage().MountPath
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	h, ok := healths[mountPath]
+	if err == nil {
+		if ok && h.State == HealthOnline {
+			h.LastCheck = time.Now()
+			return
+		}
+		if ok {
+			log.Infof("storage [%s] is online again", mountPath)
+		}
+		healths[mountPath] = &StorageHealth{State: HealthOnline, LastCheck: time.Now()}
+		return
+	}
+	if !ok {
+		h = &StorageHealth{State: HealthOnline}
+		healths[mountPath] = h
+	}
+	h.Message = err.Error()
+	h.LastCheck = time.Now()
+	if !isAuthError(err) {
+		if h.State == HealthOnline {
+			h.State = HealthDegraded
+		}
+		return
+	}
+	expire(storage, h, err)
+}
+
+// expire mark the token of the storage expired and back off, OnTokenExpired is called if it wasn't expired,
+// healthMu must be held
+func expire(storage driver.Driver, h *StorageHealth, err error) {
+	expired := h.State == HealthTokenExpired
+	h.State = HealthTokenExpired
+	h.Failures++
+	h.RetryAt = time.Now().Add(backoff(h.Failures))
+	log.Warnf("storage [%s] is %s, retry after %s: %s", storage.GetStorage().MountPath, h.State, h.RetryAt.Format(time.RFC3339), h.Message)
+	if !expired && OnTokenExpired != nil {
+		// the hook shouldn't block the call of the driver
+		go OnTokenExpired(storage.GetStorage(), err)
+	}
+}
+
+// ReportTokenRefresh update the health of the storage by the result of refreshing the token in background,
+// the token is expired if the provider rejected the refresh token or it failed in a row,
+// the other failures only degrade the storage
+func ReportTokenRefresh(storage driver.Driver, err error) {
+	if err == nil {
+		recordHealth(storage, nil)
+		return
+	}
+	mountPath := storage.GetStorage().MountPath
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	h, ok := healths[mountPath]
+	if !ok {
+		h = &StorageHealth{State: HealthOnline}
+		healths[mountPath] = h
+	}
+	h.Message = err.Error()
+	h.LastCheck = time.Now()
+	h.RefreshFailures++
+	if errors.Is(err, errs.RefreshTokenRejected) || h.RefreshFailures >= healthMaxRefreshFailures {
+		expire(storage, h, err)
+	} else if h.State == HealthOnline {
+		h.State = HealthDegraded
+	}
+}
+
+// probeFailed update the health of the storage by the failed probe, the storage is offline
+// and backs off if it failed to init or the probes failed in a row
+func probeFailed(storage driver.Driver, err error) {
+	mountPath := storage.GetStorage().MountPath
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	h, ok := healths[mountPath]
+	if !ok {
+		h = &StorageHealth{}
+		healths[mountPath] = h
+	}
+	h.Message = err.Error()
+	h.LastCheck = time.Now()
+	h.Failures++
+	if h.State == HealthTokenExpired {
+		// the token is still expired until a probe succeeds
+		h.RetryAt = time.Now().Add(backoff(h.Failures))
+		return
+	}
+	if storage.GetStorage().Status == WORK && h.Failures < healthMaxProbeFailures {
+		h.State = HealthDegraded
+		h.RetryAt = time.Time{}
+		return
+	}
+	h.State = HealthOffline
+	h.RetryAt = time.Now().Add(backoff(h.Failures - healthMaxProbeFailures + 1))
+	log.Warnf("storage [%s] is %s, retry after %s: %s", mountPath, h.State, h.RetryAt.Format(time.RFC3339), h.Message)
+}
+
+// checkHealth return errs.StorageUnavailable if the storage is backing off, so the provider
+// rejecting the token isn't called by every request
+func checkHealth(storage driver.Driver) error {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	h, ok := healths[storage.GetStorage().MountPath]
+	if !ok || h.RetryAt.IsZero() || time.Now().After(h.RetryAt) {
+		return nil
+	}
+	return errors.WithMessagef(errs.StorageUnavailable, "storage [%s] is %s: %s",
+		storage.GetStorage().MountPath, h.State, h.Message)
+}
+
+// CheckStorageHealth probe the storage with a cheap call, such as getting the quota or listing the root,
+// the storage failed to init is initialized again
+func CheckStorageHealth(ctx context.Context, storage driver.Driver) StorageHealth {
+	var err error
+	if storage.GetStorage().Status != WORK {
+		err = reinitStorage(ctx, storage)
+		if err != nil && isAuthError(err) {
+			recordHealth(storage, err)
+		}
+	} else {
+		// the calls are recorded by observe
+		if q, ok := storage.(driver.Quota); ok {
+			_, err = q.GetQuota(ctx)
+			observe(storage, "quota", err)
+		}
+		if err == nil || ignoredError(err) {
+			_, err = List(ctx, storage, "/", model.ListArgs{}, true)
+		}
+	}
+	switch {
+	case err == nil:
+		recordHealth(storage, nil)
+	case !isAuthError(err) && !ignoredError(err):
+		probeFailed(storage, err)
+	}
+	return GetStorageHealth(storage)
+}
+
+// reinitStorage drop the storage failed to init and initialize it again
+func reinitStorage(ctx context.Context, storage driver.Driver) error {
+	s := *storage.GetStorage()
+	if err := storage.Drop(ctx); err != nil {
+		log.Warnf("failed drop storage [%s]: %+v", s.MountPath, err)
+	}
+	err := storage.Init(ctx, s)
+	if err != nil {
+		storage.GetStorage().SetStatus(fmt.Sprintf("%+v", err.Error()))
+		MustSaveDriverStorage(storage)
+		return errors.Wrapf(err, "failed init storage")
+	}
+	storage.GetStorage().SetStatus(WORK)
+	MustSaveDriverStorage(storage)
+	return nil
+}
+
+// RunHealthCheck probe the storages every interval, the ones backing off are probed after the retry time,
+// the probes are paused if the interval is 0
+func RunHealthCheck(ctx context.Context, interval func() time.Duration) {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		d := interval()
+		if d <= 0 {
+			continue
+		}
+		due := time.Since(last) >= d
+		if due {
+			last = time.Now()
+		}
+		for _, storage := range storagesMap.Values() {
+			h := GetStorageHealth(storage)
+			retry := !h.RetryAt.IsZero() && time.Now().After(h.RetryAt)
+			if retry || (due && h.RetryAt.IsZero()) {
+				CheckStorageHealth(ctx, storage)
+			}
+		}
+	}
+}
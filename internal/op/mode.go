@@ -0,0 +1,47 @@
+package op
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/pkg/errors"
+)
+
+// the modes of the storages are checked in op, so they apply to the drivers
+// calling op directly too, such as the alias, the union and the offline download
+
+// CheckWrite return an error if the objects in the storage can't be changed
+func CheckWrite(storage driver.Driver) error {
+	if storage.GetStorage().ReadOnly {
+		return errors.WithStack(errs.StorageReadOnly)
+	}
+	return nil
+}
+
+// CheckRead return an error if the objects in the storage are hidden,
+// the objects of the upload only storage can't be read or changed
+func CheckRead(storage driver.Driver) error {
+	if storage.GetStorage().UploadOnly {
+		return errors.WithStack(errs.StorageUploadOnly)
+	}
+	return nil
+}
+
+// CheckModify return an error if the existing objects in the storage can't be changed
+func CheckModify(storage driver.Driver) error {
+	if err := CheckRead(storage); err != nil {
+		return err
+	}
+	return CheckWrite(storage)
+}
+
+// CheckTransfer return an error if the objects can't be copied or moved from src to dst
+func CheckTransfer(srcStorage, dstStorage driver.Driver, move bool) error {
+	check := CheckRead
+	if move {
+		check = CheckModify
+	}
+	if err := check(srcStorage); err != nil {
+		return err
+	}
+	return CheckWrite(dstStorage)
+}
@@ -0,0 +1,81 @@
+package op
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// PollChanges get the changes of the storages that implement driver.ChangeNotifier every `interval`,
+// the list caches of the changed dirs are cleared and EventChange is emitted for them.
+// the polling is paused while `interval` returns 0, it returns when ctx is done
+func PollChanges(ctx context.Context, interval func() time.Duration) {
+	// the cursors of the storages by id, they are kept in memory only,
+	// so the changes while alist isn't running are found by the reconciliation of the index
+	cursors := make(map[uint]string)
+	for {
+		d := interval()
+		wait := d
+		if wait <= 0 {
+			wait = time.Minute
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		if d <= 0 {
+			continue
+		}
+		polled := make(map[uint]string)
+		for _, storage := range storagesMap.Values() {
+			notifier, ok := storage.(driver.ChangeNotifier)
+			if !ok || storage.GetStorage().Status != WORK {
+				continue
+			}
+			id := storage.GetStorage().ID
+			next, err := pollChanges(ctx, storage, notifier, cursors[id])
+			if err != nil {
+				log.Warnf("failed to get the changes of [%s]: %+v", storage.GetStorage().MountPath, err)
+			}
+			polled[id] = next
+		}
+		// the cursors of the removed storages are dropped
+		cursors = polled
+	}
+}
+
+// pollChanges return the cursor of the next poll, it's reset after failing since the cursor may expire,
+// then the changes in the meantime are missed and found by the reconciliation of the index
+func pollChanges(ctx context.Context, storage driver.Driver, notifier driver.ChangeNotifier, cursor string) (string, error) {
+	dirs, next, err := notifier.Changes(ctx, cursor)
+	if err != nil {
+		return "", err
+	}
+	if cursor == "" {
+		return next, nil
+	}
+	root := "/"
+	if i, ok := storage.GetAddition().(driver.IRootPath); ok {
+		root = utils.StandardizePath(i.GetRootPath())
+	}
+	changed := make(map[string]struct{}, len(dirs))
+	for _, dir := range dirs {
+		dir = utils.StandardizePath(dir)
+		if _, ok := changed[dir]; ok {
+			continue
+		}
+		changed[dir] = struct{}{}
+		// the changes out of the root folder of the storage are ignored
+		if root != "/" && dir != root && !strings.HasPrefix(dir, root+"/") {
+			continue
+		}
+		ClearCache(storage, dir)
+		emit(Event{Type: EventChange, Path: virtualPath(storage, dir)})
+	}
+	return next, nil
+}
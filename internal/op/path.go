@@ -32,8 +32,13 @@ func GetStorageAndActualPath(rawPath string) (driver.Driver, string, error) {
 		return nil, "", errors.Errorf("can't find storage with rawPath: %s", rawPath)
 	}
 	log.Debugln("use storage: ", storage.GetStorage().MountPath)
+	return storage, StorageActualPath(storage, rawPath), nil
+}
+
+// StorageActualPath get the actual path of the raw path in the storage, used for the balanced storages,
+// the raw path must be in the storage
+func StorageActualPath(storage driver.Driver, rawPath string) string {
 	virtualPath := utils.GetActualVirtualPath(storage.GetStorage().MountPath)
-	actualPath := strings.TrimPrefix(rawPath, virtualPath)
-	actualPath = ActualPath(storage.GetAddition(), actualPath)
-	return storage, actualPath, nil
+	actualPath := strings.TrimPrefix(utils.StandardizePath(rawPath), virtualPath)
+	return ActualPath(storage.GetAddition(), actualPath)
 }
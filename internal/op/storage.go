@@ -2,15 +2,19 @@ package op
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/Xhofe/go-cache"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -32,6 +36,9 @@ func GetStorageByVirtualPath(virtualPath string) (driver.Driver, error) {
 // CreateStorage Save the storage to database so storage can get an id
 // then instantiate corresponding driver and save it in memory
 func CreateStorage(ctx context.Context, storage model.Storage) error {
+	if err := storage.IPAcl.Validate(); err != nil {
+		return errors.WithStack(err)
+	}
 	storage.Modified = time.Now()
 	storage.MountPath = utils.StandardizePath(storage.MountPath)
 	var err error
@@ -48,6 +55,7 @@ func CreateStorage(ctx context.Context, storage model.Storage) error {
 		return errors.WithMessage(err, "failed create storage in database")
 	}
 	// already has an id
+	resetHealth(storage.MountPath)
 	err = storageDriver.Init(ctx, storage)
 	storagesMap.Store(storage.MountPath, storageDriver)
 	if err != nil {
@@ -72,6 +80,7 @@ func LoadStorage(ctx context.Context, storage model.Storage) error {
 		return errors.WithMessage(err, "failed get driver new")
 	}
 	storageDriver := driverNew()
+	resetHealth(storage.MountPath)
 	err = storageDriver.Init(ctx, storage)
 	storagesMap.Store(storage.MountPath, storageDriver)
 	if err != nil {
@@ -129,6 +138,8 @@ func DisableStorage(ctx context.Context, id uint) error {
 		return errors.WithMessage(err, "failed update storage in db")
 	}
 	storagesMap.Delete(storage.MountPath)
+	clearStorageCache(storage.MountPath)
+	resetHealth(storage.MountPath)
 	return nil
 }
 
@@ -143,12 +154,20 @@ func UpdateStorage(ctx context.Context, storage model.Storage) error {
 	if oldStorage.Driver != storage.Driver {
 		return errors.Errorf("driver cannot be changed")
 	}
+	if err := storage.IPAcl.Validate(); err != nil {
+		return errors.WithStack(err)
+	}
+	// the secrets are redacted in the responses
+	storage.RestoreSecrets(*oldStorage)
 	storage.Modified = time.Now()
 	storage.MountPath = utils.StandardizePath(storage.MountPath)
 	err = db.UpdateStorage(&storage)
 	if err != nil {
 		return errors.WithMessage(err, "failed update storage in database")
 	}
+	// the cache may be outdated with the new addition or mount path
+	clearStorageCache(oldStorage.MountPath)
+	resetHealth(oldStorage.MountPath)
 	if storage.Disabled {
 		return nil
 	}
@@ -177,6 +196,29 @@ func UpdateStorage(ctx context.Context, storage model.Storage) error {
 	return nil
 }
 
+// UpdateStorageToken replace the refresh token in the addition of the storage and initialize it again,
+// so the storage with the expired token can be fixed without being recreated
+func UpdateStorageToken(ctx context.Context, id uint, refreshToken string) error {
+	storage, err := db.GetStorageById(id)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	var addition map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(storage.Addition), &addition); err != nil {
+		return errors.Wrapf(err, "invalid addition of the storage")
+	}
+	if _, ok := addition["refresh_token"]; !ok {
+		return errors.Errorf("the driver [%s] has no refresh token", storage.Driver)
+	}
+	addition["refresh_token"], _ = json.Marshal(refreshToken)
+	data, err := json.Marshal(addition)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	storage.Addition = string(data)
+	return UpdateStorage(ctx, *storage)
+}
+
 func DeleteStorageById(ctx context.Context, id uint) error {
 	storage, err := db.GetStorageById(id)
 	if err != nil {
@@ -196,9 +238,78 @@ func DeleteStorageById(ctx context.Context, id uint) error {
 	}
 	// delete the storage in the memory
 	storagesMap.Delete(storage.MountPath)
+	clearStorageCache(storage.MountPath)
+	resetHealth(storage.MountPath)
 	return nil
 }
 
+type ReloadResult struct {
+	Loaded   []string          `json:"loaded"`
+	Reloaded []string          `json:"reloaded"`
+	Dropped  []string          `json:"dropped"`
+	Failed   map[string]string `json:"failed"`
+}
+
+// dropStorage drop the storage in memory and its caches
+func dropStorage(ctx context.Context, storageDriver driver.Driver) {
+	mountPath := storageDriver.GetStorage().MountPath
+	if err := storageDriver.Drop(ctx); err != nil {
+		log.Warnf("failed drop storage [%s]: %+v", mountPath, err)
+	}
+	storagesMap.Delete(mountPath)
+	clearStorageCache(mountPath)
+	resetHealth(mountPath)
+}
+
+// ReloadStorages load the enabled storages in the database again, only the ones added, modified
+// or removed since they were loaded are initialized or dropped, the others keep working
+func ReloadStorages(ctx context.Context) (*ReloadResult, error) {
+	storages, err := db.GetEnabledStorages()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get enabled storages")
+	}
+	loaded := make(map[uint]driver.Driver)
+	for _, storageDriver := range storagesMap.Values() {
+		loaded[storageDriver.GetStorage().ID] = storageDriver
+	}
+	res := &ReloadResult{Failed: make(map[string]string)}
+	enabled := make(map[uint]bool)
+	for _, storage := range storages {
+		enabled[storage.ID] = true
+		storageDriver, ok := loaded[storage.ID]
+		if ok {
+			cur := storageDriver.GetStorage()
+			if cur.Modified.Unix() == storage.Modified.Unix() && cur.MountPath == utils.StandardizePath(storage.MountPath) {
+				continue
+			}
+			dropStorage(ctx, storageDriver)
+			res.Reloaded = append(res.Reloaded, storage.MountPath)
+		} else {
+			res.Loaded = append(res.Loaded, storage.MountPath)
+		}
+		if err := LoadStorage(ctx, storage); err != nil {
+			res.Failed[storage.MountPath] = err.Error()
+		}
+	}
+	for id, storageDriver := range loaded {
+		if !enabled[id] {
+			res.Dropped = append(res.Dropped, storageDriver.GetStorage().MountPath)
+			dropStorage(ctx, storageDriver)
+		}
+	}
+	return res, nil
+}
+
+// DropAllStorages drop the storages in memory on shutdown, so the crons of the drivers are stopped
+func DropAllStorages(ctx context.Context) {
+	for _, storage := range storagesMap.Values() {
+		if err := storage.Drop(ctx); err != nil {
+			log.Warnf("failed drop storage [%s]: %+v", storage.GetStorage().MountPath, err)
+		}
+	}
+	storagesMap.Clear()
+}
+
 // MustSaveDriverStorage call from specific driver
 func MustSaveDriverStorage(driver driver.Driver) {
 	err := saveDriverStorage(driver)
@@ -304,27 +415,119 @@ func GetStorageVirtualFilesByPath(prefix string) []model.Obj {
 
 var balanceMap generic_sync.MapOf[string, int]
 
-// GetBalancedStorage get storage by path
-func GetBalancedStorage(path string) driver.Driver {
+// the balanced storage failed is tried after the others for a while,
+// so the requests go to the working ones, such as when an account is rate limited
+const balanceCooldown = time.Minute
+
+var balanceFailed generic_sync.MapOf[string, time.Time]
+
+// GetBalancedStorages get the balanced storages of the path in the order of trying, the next one
+// of the rotation is the first, and the not working or failed recently ones are the last
+func GetBalancedStorages(path string) []driver.Driver {
 	path = utils.StandardizePath(path)
 	storages := getStoragesByPath(path)
 	storageNum := len(storages)
-	switch storageNum {
-	case 0:
-		return nil
-	case 1:
-		return storages[0]
-	default:
-		virtualPath := utils.GetActualVirtualPath(storages[0].GetStorage().MountPath)
-		cur, ok := balanceMap.Load(virtualPath)
-		i := 0
-		if ok {
-			i = cur
-			i = (i + 1) % storageNum
-			balanceMap.Store(virtualPath, i)
+	if storageNum <= 1 {
+		return storages
+	}
+	virtualPath := utils.GetActualVirtualPath(storages[0].GetStorage().MountPath)
+	i := 0
+	if cur, ok := balanceMap.Load(virtualPath); ok {
+		i = (cur + 1) % storageNum
+	}
+	balanceMap.Store(virtualPath, i)
+	res := make([]driver.Driver, 0, storageNum)
+	var unavailable []driver.Driver
+	for j := 0; j < storageNum; j++ {
+		storage := storages[(i+j)%storageNum]
+		if balanceAvailable(storage) {
+			res = append(res, storage)
 		} else {
-			balanceMap.Store(virtualPath, i)
+			unavailable = append(unavailable, storage)
+		}
+	}
+	return append(res, unavailable...)
+}
+
+func balanceAvailable(storage driver.Driver) bool {
+	if storage.GetStorage().Status != WORK {
+		return false
+	}
+	until, ok := balanceFailed.Load(storage.GetStorage().MountPath)
+	return !ok || time.Now().After(until)
+}
+
+// MarkBalancedStorageFailed mark the storage failed, it's tried after the other balanced storages for a while
+func MarkBalancedStorageFailed(storage driver.Driver) {
+	balanceFailed.Store(storage.GetStorage().MountPath, time.Now().Add(balanceCooldown))
+}
+
+// GetBalancedStorage get storage by path
+func GetBalancedStorage(path string) driver.Driver {
+	storages := GetBalancedStorages(path)
+	if len(storages) == 0 {
+		return nil
+	}
+	return storages[0]
+}
+
+var quotaCache = cache.NewMemCache(cache.WithShards[*model.StorageQuota](2))
+var quotaG singleflight.Group[*model.StorageQuota]
+
+// GetStorageQuota get quota of storage, the result will be cached for some minutes
+func GetStorageQuota(ctx context.Context, storage driver.Driver) (*model.StorageQuota, error) {
+	q, ok := storage.(driver.Quota)
+	if !ok {
+		return nil, errors.WithStack(errs.NotImplement)
+	}
+	key := storage.GetStorage().MountPath
+	if quota, ok := quotaCache.Get(key); ok {
+		return quota, nil
+	}
+	quota, err, _ := quotaG.Do(key, func() (*model.StorageQuota, error) {
+		quota, err := q.GetQuota(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed get quota")
+		}
+		if quota.Free == 0 && quota.Total > quota.Used {
+			quota.Free = quota.Total - quota.Used
 		}
-		return storages[i]
+		quotaCache.Set(key, quota, cache.WithEx[*model.StorageQuota](time.Minute*5))
+		return quota, nil
+	})
+	return quota, err
+}
+
+// GetCachedStorageQuota get quota of storage in the cache without requesting the provider, nil if not cached
+func GetCachedStorageQuota(storage driver.Driver) *model.StorageQuota {
+	quota, _ := quotaCache.Get(storage.GetStorage().MountPath)
+	return quota
+}
+
+// GetStorageQuotaById get quota of the enabled storage with id
+func GetStorageQuotaById(ctx context.Context, id uint) (*model.StorageQuota, error) {
+	storage, err := db.GetStorageById(id)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
 	}
+	storageDriver, err := GetStorageByVirtualPath(storage.MountPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage driver")
+	}
+	return GetStorageQuota(ctx, storageDriver)
+}
+
+// EncryptStorageSecrets save the storages again, so the secrets saved in plain before,
+// or encrypted by the jwt secret, are encrypted by the master key
+func EncryptStorageSecrets() error {
+	storages, err := db.GetAllStorages()
+	if err != nil {
+		return errors.WithMessage(err, "failed get storages")
+	}
+	for i := range storages {
+		if err := db.UpdateStorage(&storages[i]); err != nil {
+			return errors.WithMessagef(err, "failed save storage [%s]", storages[i].MountPath)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,94 @@
+package op
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func getTrash(storage driver.Driver) (driver.Trash, error) {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	t, ok := storage.(driver.Trash)
+	if !ok {
+		return nil, errors.WithStack(errs.TrashNotSupported)
+	}
+	return t, nil
+}
+
+// ListTrash list the objects in the recycle bin of storage
+func ListTrash(ctx context.Context, storage driver.Driver) ([]model.Obj, error) {
+	t, err := getTrash(storage)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := t.ListTrash(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to list trash")
+	}
+	return objs, nil
+}
+
+// getTrashObjs find the objects in the recycle bin by ids
+func getTrashObjs(ctx context.Context, t driver.Trash, ids []string) ([]model.Obj, error) {
+	objs, err := t.ListTrash(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to list trash")
+	}
+	objMap := make(map[string]model.Obj, len(objs))
+	for _, obj := range objs {
+		objMap[obj.GetID()] = obj
+	}
+	res := make([]model.Obj, 0, len(ids))
+	for _, id := range ids {
+		obj, ok := objMap[id]
+		if !ok {
+			return nil, errors.WithMessagef(errs.ObjectNotFound, "id: %s", id)
+		}
+		res = append(res, obj)
+	}
+	return res, nil
+}
+
+// RestoreTrash restore the objects with ids in the recycle bin of storage
+func RestoreTrash(ctx context.Context, storage driver.Driver, ids []string) error {
+	t, err := getTrash(storage)
+	if err != nil {
+		return err
+	}
+	objs, err := getTrashObjs(ctx, t, ids)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		if err := t.Restore(ctx, obj); err != nil {
+			return errors.WithMessagef(err, "failed to restore [%s]", obj.GetName())
+		}
+	}
+	// the objects may be restored to any dir of the storage,
+	// so the list cache has to be cleared entirely
+	clearAllCache()
+	return nil
+}
+
+// PurgeTrash delete the objects with ids in the recycle bin of storage permanently
+func PurgeTrash(ctx context.Context, storage driver.Driver, ids []string) error {
+	t, err := getTrash(storage)
+	if err != nil {
+		return err
+	}
+	objs, err := getTrashObjs(ctx, t, ids)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		if err := t.Purge(ctx, obj); err != nil {
+			return errors.WithMessagef(err, "failed to purge [%s]", obj.GetName())
+		}
+	}
+	return nil
+}
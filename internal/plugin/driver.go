@@ -0,0 +1,206 @@
+package plugin
+
+import (
+	"context"
+	"net/rpc"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/plugin"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// Driver is a storage of the driver served by the plugin, it's identified by the id in the plugin
+type Driver struct {
+	model.Storage
+	plugin   *Plugin
+	addition map[string]interface{}
+
+	mu sync.Mutex
+	id uint64
+	// the generation of the process of the plugin the storage is initialized in
+	gen uint64
+}
+
+func (d *Driver) Config() driver.Config {
+	return d.plugin.config()
+}
+
+func (d *Driver) GetAddition() driver.Additional {
+	return d.addition
+}
+
+func (d *Driver) Init(ctx context.Context, storage model.Storage) error {
+	d.Storage = storage
+	d.addition = make(map[string]interface{})
+	if err := utils.Json.UnmarshalFromString(d.Storage.Addition, &d.addition); err != nil {
+		return err
+	}
+	client, gen, err := d.plugin.conn()
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.init(ctx, client, gen)
+}
+
+// init the storage in the process of the plugin, d.mu is held
+func (d *Driver) init(ctx context.Context, client *rpc.Client, gen uint64) error {
+	var reply plugin.Reply
+	if err := d.plugin.invoke(ctx, client, "Init", &plugin.Args{Addition: d.Storage.Addition}, &reply); err != nil {
+		return err
+	}
+	d.id, d.gen = reply.ID, gen
+	return nil
+}
+
+func (d *Driver) Drop(ctx context.Context) error {
+	client, gen, err := d.plugin.conn()
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	// the storage isn't in the restarted plugin
+	if d.id == 0 || d.gen != gen {
+		return nil
+	}
+	return d.plugin.invoke(ctx, client, "Drop", &plugin.Args{ID: d.id}, &plugin.Reply{})
+}
+
+// call the method of the storage, it's initialized again if the plugin is restarted
+func (d *Driver) call(ctx context.Context, method string, args *plugin.Args, reply *plugin.Reply) error {
+	client, gen, err := d.plugin.conn()
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	if d.gen != gen {
+		if err = d.init(ctx, client, gen); err != nil {
+			d.mu.Unlock()
+			return errors.WithMessage(err, "failed init the storage in the restarted plugin")
+		}
+	}
+	args.ID = d.id
+	d.mu.Unlock()
+	return d.plugin.invoke(ctx, client, method, args, reply)
+}
+
+// Get get the root, the other objects are got by listing their parents
+func (d *Driver) Get(ctx context.Context, path string) (model.Obj, error) {
+	if !utils.PathEqual(path, "/") {
+		return nil, errors.WithStack(errs.NotImplement)
+	}
+	return &model.Object{
+		ID:       d.Config().DefaultRoot,
+		Name:     "root",
+		Path:     "/",
+		Modified: d.Modified,
+		IsFolder: true,
+	}, nil
+}
+
+func toPluginObj(obj model.Obj) plugin.Obj {
+	if obj == nil {
+		return plugin.Obj{}
+	}
+	return plugin.Obj{
+		ID:       obj.GetID(),
+		Name:     obj.GetName(),
+		Path:     obj.GetPath(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		IsFolder: obj.IsDir(),
+	}
+}
+
+func fromPluginObj(obj plugin.Obj) model.Obj {
+	return &model.ObjThumb{
+		Object: model.Object{
+			ID:       obj.ID,
+			Name:     obj.Name,
+			Path:     obj.Path,
+			Size:     obj.Size,
+			Modified: obj.Modified,
+			IsFolder: obj.IsFolder,
+		},
+		Thumbnail: model.Thumbnail{Thumbnail: obj.Thumb},
+	}
+}
+
+func (d *Driver) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	var reply plugin.Reply
+	if err := d.call(ctx, "List", &plugin.Args{Obj: toPluginObj(dir)}, &reply); err != nil {
+		return nil, err
+	}
+	objs := make([]model.Obj, 0, len(reply.Objs))
+	for _, obj := range reply.Objs {
+		objs = append(objs, fromPluginObj(obj))
+	}
+	return objs, nil
+}
+
+func (d *Driver) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	var reply plugin.Reply
+	if err := d.call(ctx, "Link", &plugin.Args{Obj: toPluginObj(file)}, &reply); err != nil {
+		return nil, err
+	}
+	if reply.Link == nil {
+		return nil, errors.Errorf("plugin returned no link of [%s]", file.GetPath())
+	}
+	link := &model.Link{URL: reply.Link.URL, Header: reply.Link.Header}
+	if reply.Link.FilePath != "" {
+		link.FilePath = &reply.Link.FilePath
+	}
+	if reply.Link.Expiration > 0 {
+		exp := time.Duration(reply.Link.Expiration) * time.Second
+		link.Expiration = &exp
+	}
+	return link, nil
+}
+
+func (d *Driver) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	return d.call(ctx, "MakeDir", &plugin.Args{Obj: toPluginObj(parentDir), Name: dirName}, &plugin.Reply{})
+}
+
+func (d *Driver) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.call(ctx, "Move", &plugin.Args{Obj: toPluginObj(srcObj), Dst: toPluginObj(dstDir)}, &plugin.Reply{})
+}
+
+func (d *Driver) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	return d.call(ctx, "Rename", &plugin.Args{Obj: toPluginObj(srcObj), Name: newName}, &plugin.Reply{})
+}
+
+func (d *Driver) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.call(ctx, "Copy", &plugin.Args{Obj: toPluginObj(srcObj), Dst: toPluginObj(dstDir)}, &plugin.Reply{})
+}
+
+func (d *Driver) Remove(ctx context.Context, obj model.Obj) error {
+	return d.call(ctx, "Remove", &plugin.Args{Obj: toPluginObj(obj)}, &plugin.Reply{})
+}
+
+// Put spool the stream to a temp file, the plugin reads the file since the stream can't be passed to it
+func (d *Driver) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	f, err := utils.CreateTempFile(stream)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	err = d.call(ctx, "Put", &plugin.Args{Dst: toPluginObj(dstDir), Name: stream.GetName(), Path: f.Name()}, &plugin.Reply{})
+	if err == nil {
+		up(100)
+	}
+	return err
+}
+
+var _ driver.Driver = (*Driver)(nil)
+var _ driver.Getter = (*Driver)(nil)
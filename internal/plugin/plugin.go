@@ -0,0 +1,315 @@
+// Package plugin load the drivers served by the plugins out of the tree, see pkg/plugin for the protocol
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/plugin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the time the plugin should answer the info after started
+const infoTimeout = 10 * time.Second
+
+// the plugin exited isn't restarted again within the interval, so a plugin crashing on start
+// isn't started on every call
+var restartInterval = 30 * time.Second
+
+// Plugin is a started plugin serving a driver
+type Plugin struct {
+	Path string      `json:"path"`
+	Info plugin.Info `json:"info"`
+	// false if the process of the plugin exited, it's restarted on the next call
+	Available bool `json:"available"`
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	client *rpc.Client
+	// closed when the process exits
+	done chan struct{}
+	// increased when the plugin is restarted, the storages are initialized again in the new process
+	gen     uint64
+	started time.Time
+	stopped bool
+}
+
+var (
+	mu      sync.Mutex
+	plugins []*Plugin
+)
+
+// Load start the executables in the dir and register their drivers, the ones failed are skipped
+func Load(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Errorf("failed read plugins dir [%s]: %+v", dir, err)
+		}
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		p, err := Start(path)
+		if err != nil {
+			log.Errorf("failed load plugin [%s]: %+v", path, err)
+			continue
+		}
+		if err = op.RegisterPluginDriver(p.config(), p.additional(), func() driver.Driver {
+			return &Driver{plugin: p}
+		}); err != nil {
+			log.Errorf("failed register the driver of plugin [%s]: %+v", path, err)
+			p.stop()
+			continue
+		}
+		mu.Lock()
+		plugins = append(plugins, p)
+		mu.Unlock()
+		log.Infof("loaded plugin [%s] serving driver [%s]", path, p.Info.Name)
+	}
+}
+
+type conn struct {
+	io.ReadCloser
+	io.WriteCloser
+}
+
+func (c conn) Close() error {
+	err := c.WriteCloser.Close()
+	if e := c.ReadCloser.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// Start start the plugin and get the info of its driver
+func Start(path string) (*Plugin, error) {
+	p := &Plugin{Path: path}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.start(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// start the process of the plugin and check its info, p.mu is held
+func (p *Plugin) start() error {
+	cmd := exec.Command(p.Path)
+	cmd.Env = append(os.Environ(), plugin.MagicEnv+"="+plugin.MagicValue)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	p.started = time.Now()
+	if err = cmd.Start(); err != nil {
+		return errors.WithStack(err)
+	}
+	client := jsonrpc.NewClient(conn{ReadCloser: stdout, WriteCloser: stdin})
+	done := make(chan struct{})
+	go func() {
+		// the logs are read before Wait, which closes the stderr
+		forwardLogs(filepath.Base(p.Path), stderr)
+		p.exited(cmd, cmd.Wait())
+		close(done)
+	}()
+	p.cmd, p.client, p.done = cmd, client, done
+	p.gen++
+	var info plugin.Info
+	ctx, cancel := context.WithTimeout(context.Background(), infoTimeout)
+	defer cancel()
+	if err = p.invoke(ctx, client, "Info", plugin.ProtocolVersion, &info); err != nil {
+		p.kill()
+		return errors.WithMessage(err, "failed get info")
+	}
+	if info.ProtocolVersion != plugin.ProtocolVersion {
+		p.kill()
+		return errors.Errorf("the protocol version %d isn't supported, expected %d", info.ProtocolVersion, plugin.ProtocolVersion)
+	}
+	if info.Name == "" {
+		p.kill()
+		return errors.New("the name of the driver is empty")
+	}
+	// the driver is registered with the info got first, it mustn't be changed by the restarted plugin
+	if p.Info.Name != "" && info.Name != p.Info.Name {
+		p.kill()
+		return errors.Errorf("the name of the driver is changed from [%s] to [%s]", p.Info.Name, info.Name)
+	}
+	if p.Info.Name == "" {
+		p.Info = info
+	}
+	p.Available = true
+	return nil
+}
+
+// exited mark the plugin unavailable when its process exits, the calls waiting for it fail
+func (p *Plugin) exited(cmd *exec.Cmd, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// the plugin failed to start is killed and marked already
+	if p.cmd != cmd || p.client == nil {
+		return
+	}
+	_ = p.client.Close()
+	p.client = nil
+	p.Available = false
+	if !p.stopped {
+		log.Errorf("plugin [%s] exited: %v, it will be restarted on the next call", p.Path, err)
+	}
+}
+
+// kill the process of the plugin failed to start, p.mu is held
+func (p *Plugin) kill() {
+	_ = p.client.Close()
+	_ = p.cmd.Process.Kill()
+	p.client = nil
+	p.Available = false
+}
+
+// get the client of the plugin and the generation of its process, the plugin exited is
+// restarted if it's not restarted recently
+func (p *Plugin) conn() (*rpc.Client, uint64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		return p.client, p.gen, nil
+	}
+	if p.stopped {
+		return nil, 0, errors.Errorf("plugin [%s] is stopped", p.Path)
+	}
+	if time.Since(p.started) < restartInterval {
+		return nil, 0, errors.Errorf("plugin [%s] exited and is unavailable", p.Path)
+	}
+	log.Infof("restarting plugin [%s]", p.Path)
+	if err := p.start(); err != nil {
+		return nil, 0, errors.WithMessagef(err, "failed restart plugin [%s]", p.Path)
+	}
+	return p.client, p.gen, nil
+}
+
+// forwardLogs write the stderr of the plugin to the log
+func forwardLogs(name string, r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		log.Infof("[plugin %s] %s", name, scanner.Text())
+	}
+}
+
+// invoke the method of the plugin with the client, it returns when ctx is done though the plugin is still working
+func (p *Plugin) invoke(ctx context.Context, client *rpc.Client, method string, args interface{}, reply interface{}) error {
+	c := client.Go("Plugin."+method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	case <-c.Done:
+	}
+	if c.Error == nil {
+		return nil
+	}
+	// the errors of the plugin are strings, they're converted to the errors of alist by the messages
+	switch c.Error.Error() {
+	case plugin.ErrNotSupport.Error():
+		return errors.WithStack(errs.NotSupport)
+	case plugin.ErrObjectNotFound.Error():
+		return errors.WithStack(errs.ObjectNotFound)
+	}
+	if c.Error == rpc.ErrShutdown || errors.Is(c.Error, io.ErrUnexpectedEOF) {
+		return errors.Errorf("plugin [%s] exited", p.Path)
+	}
+	return errors.WithStack(c.Error)
+}
+
+func (p *Plugin) config() driver.Config {
+	return driver.Config{
+		Name:        p.Info.Name,
+		LocalSort:   p.Info.LocalSort,
+		OnlyProxy:   p.Info.OnlyProxy,
+		NoCache:     p.Info.NoCache,
+		NoUpload:    p.Info.NoUpload,
+		DefaultRoot: p.Info.DefaultRoot,
+	}
+}
+
+func (p *Plugin) additional() []driver.Item {
+	items := make([]driver.Item, 0, len(p.Info.Additional))
+	for _, item := range p.Info.Additional {
+		if item.Type == "" {
+			item.Type = "string"
+		}
+		items = append(items, driver.Item{
+			Name:     item.Name,
+			Type:     item.Type,
+			Default:  item.Default,
+			Options:  item.Options,
+			Required: item.Required,
+			Help:     item.Help,
+			Secret:   item.Secret,
+		})
+	}
+	return items
+}
+
+func (p *Plugin) stop() {
+	p.mu.Lock()
+	p.stopped = true
+	client, cmd, done := p.client, p.cmd, p.done
+	p.mu.Unlock()
+	if client == nil {
+		return
+	}
+	// the plugin should exit when its stdin is closed
+	_ = client.Close()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		_ = cmd.Process.Kill()
+		<-done
+	}
+}
+
+// StopAll stop the plugins on shutdown, the storages should be dropped before
+func StopAll() {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, p := range plugins {
+		p.stop()
+	}
+	plugins = nil
+}
+
+// GetPlugins get the plugins loaded
+func GetPlugins() []Plugin {
+	mu.Lock()
+	defer mu.Unlock()
+	res := make([]Plugin, 0, len(plugins))
+	for _, p := range plugins {
+		p.mu.Lock()
+		res = append(res, Plugin{Path: p.Path, Info: p.Info, Available: p.Available})
+		p.mu.Unlock()
+	}
+	return res
+}
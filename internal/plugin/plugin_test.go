@@ -0,0 +1,148 @@
+package plugin
+
+import (
+	"context"
+	"net"
+	"net/rpc/jsonrpc"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/plugin"
+	"github.com/pkg/errors"
+)
+
+type memStorage struct {
+	root string
+}
+
+func (s *memStorage) Init(ctx context.Context, addition string) error {
+	s.root = addition
+	return nil
+}
+
+func (s *memStorage) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (s *memStorage) List(ctx context.Context, dir plugin.Obj) ([]plugin.Obj, error) {
+	if dir.Path != "/" {
+		return nil, plugin.ErrObjectNotFound
+	}
+	return []plugin.Obj{{Name: "a.txt", Path: "/a.txt", Size: 1}}, nil
+}
+
+func (s *memStorage) Link(ctx context.Context, file plugin.Obj) (*plugin.Link, error) {
+	return &plugin.Link{URL: "https://example.com" + file.Path, Expiration: 60}, nil
+}
+
+func (s *memStorage) MakeDir(ctx context.Context, parentDir plugin.Obj, dirName string) error {
+	return plugin.ErrNotSupport
+}
+
+func (s *memStorage) Move(ctx context.Context, srcObj, dstDir plugin.Obj) error {
+	return plugin.ErrNotSupport
+}
+
+func (s *memStorage) Rename(ctx context.Context, srcObj plugin.Obj, newName string) error {
+	return plugin.ErrNotSupport
+}
+
+func (s *memStorage) Copy(ctx context.Context, srcObj, dstDir plugin.Obj) error {
+	return plugin.ErrNotSupport
+}
+
+func (s *memStorage) Remove(ctx context.Context, obj plugin.Obj) error {
+	return plugin.ErrNotSupport
+}
+
+func (s *memStorage) Put(ctx context.Context, dstDir plugin.Obj, name string, path string) error {
+	return plugin.ErrNotSupport
+}
+
+func TestMain(m *testing.M) {
+	// the test binary serves the storages when it's started as the plugin
+	if os.Getenv(plugin.MagicEnv) == plugin.MagicValue {
+		plugin.Serve(plugin.Info{Name: "Mem"}, func() plugin.Storage {
+			return &memStorage{}
+		})
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestRestart(t *testing.T) {
+	defer func(interval time.Duration) { restartInterval = interval }(restartInterval)
+	p, err := Start(os.Args[0])
+	if err != nil {
+		t.Fatalf("failed start: %+v", err)
+	}
+	defer p.stop()
+	ctx := context.Background()
+	d := &Driver{plugin: p}
+	if err = d.Init(ctx, model.Storage{Addition: `{"root":"/"}`}); err != nil {
+		t.Fatalf("failed init: %+v", err)
+	}
+	root := &model.Object{Path: "/", IsFolder: true}
+	_ = p.cmd.Process.Kill()
+	<-p.done
+	if p.Available {
+		t.Fatal("expected the exited plugin unavailable")
+	}
+	restartInterval = time.Hour
+	if _, err = d.List(ctx, root, model.ListArgs{}); err == nil {
+		t.Fatal("expected the plugin exited recently not restarted")
+	}
+	restartInterval = 0
+	objs, err := d.List(ctx, root, model.ListArgs{})
+	if err != nil {
+		t.Fatalf("failed list after restart: %+v", err)
+	}
+	if len(objs) != 1 || !p.Available || d.gen != p.gen {
+		t.Errorf("unexpected objs %+v, available %v, gen %d of %d", objs, p.Available, d.gen, p.gen)
+	}
+}
+
+func TestDriver(t *testing.T) {
+	server, client := net.Pipe()
+	go func() {
+		_ = plugin.ServeConn(server, plugin.Info{Name: "Mem"}, func() plugin.Storage {
+			return &memStorage{}
+		})
+	}()
+	p := &Plugin{client: jsonrpc.NewClient(client)}
+	defer p.client.Close()
+	ctx := context.Background()
+	if err := p.invoke(ctx, p.client, "Info", plugin.ProtocolVersion, &p.Info); err != nil {
+		t.Fatalf("failed get info: %+v", err)
+	}
+	if p.Info.Name != "Mem" || p.Info.ProtocolVersion != plugin.ProtocolVersion {
+		t.Fatalf("unexpected info: %+v", p.Info)
+	}
+	d := &Driver{plugin: p}
+	if err := d.Init(ctx, model.Storage{Addition: `{"root":"/"}`}); err != nil {
+		t.Fatalf("failed init: %+v", err)
+	}
+	objs, err := d.List(ctx, &model.Object{Path: "/", IsFolder: true}, model.ListArgs{})
+	if err != nil {
+		t.Fatalf("failed list: %+v", err)
+	}
+	if len(objs) != 1 || objs[0].GetName() != "a.txt" {
+		t.Errorf("unexpected objs: %+v", objs)
+	}
+	if _, err = d.List(ctx, &model.Object{Path: "/b"}, model.ListArgs{}); !errs.IsObjectNotFound(err) {
+		t.Errorf("expected object not found, got %v", err)
+	}
+	link, err := d.Link(ctx, objs[0], model.LinkArgs{})
+	if err != nil || link.URL != "https://example.com/a.txt" || link.Expiration == nil {
+		t.Errorf("unexpected link: %+v, %v", link, err)
+	}
+	if err = d.MakeDir(ctx, objs[0], "c"); !errors.Is(err, errs.NotSupport) {
+		t.Errorf("expected not support, got %v", err)
+	}
+	if err = d.Drop(ctx); err != nil {
+		t.Errorf("failed drop: %+v", err)
+	}
+}
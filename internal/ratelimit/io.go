@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+type reader struct {
+	ctx      context.Context
+	r        io.Reader
+	limiters []*Limiter
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if e := WaitN(r.ctx, n, r.limiters...); e != nil {
+			return n, e
+		}
+	}
+	return n, err
+}
+
+// NewReader limit the speed of reading r
+func NewReader(ctx context.Context, r io.Reader, limiters ...*Limiter) io.Reader {
+	if len(limiters) == 0 {
+		return r
+	}
+	return &reader{ctx: ctx, r: r, limiters: limiters}
+}
+
+// NewReadCloser is like NewReader, and the Close is passed to rc
+func NewReadCloser(ctx context.Context, rc io.ReadCloser, limiters ...*Limiter) io.ReadCloser {
+	if len(limiters) == 0 {
+		return rc
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{Reader: NewReader(ctx, rc, limiters...), Closer: rc}
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	ctx      context.Context
+	limiters []*Limiter
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	if n > 0 {
+		if e := WaitN(w.ctx, n, w.limiters...); e != nil {
+			return n, e
+		}
+	}
+	return n, err
+}
+
+// NewResponseWriter limit the speed of writing the response body
+func NewResponseWriter(ctx context.Context, w http.ResponseWriter, limiters ...*Limiter) http.ResponseWriter {
+	if len(limiters) == 0 {
+		return w
+	}
+	return &responseWriter{ResponseWriter: w, ctx: ctx, limiters: limiters}
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket of bytes, which can hold the tokens of one second at most.
+// The tokens can be owed by a large read or write, then the following ones wait until it's paid off.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   int64
+	tokens float64
+	last   time.Time
+	// the traffic quota of the user, nil for the other limiters
+	meter  *meter
+	upload bool
+}
+
+// SetRate set the bytes per second, it's unlimited if rate <= 0
+func (l *Limiter) SetRate(rate int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = rate
+	if l.tokens > float64(rate) {
+		l.tokens = float64(rate)
+	}
+}
+
+func (l *Limiter) Rate() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// reserve take n tokens, and return the duration to wait before using them
+func (l *Limiter) reserve(n int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate <= 0 {
+		return 0
+	}
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * float64(l.rate)
+	if l.tokens > float64(l.rate) {
+		l.tokens = float64(l.rate)
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / float64(l.rate) * float64(time.Second))
+}
+
+// WaitN take n tokens from all the limiters, and wait until the slowest one allows,
+// errs.TrafficQuotaExceeded is returned if the traffic quota of the user is reached
+func WaitN(ctx context.Context, n int, limiters ...*Limiter) error {
+	for _, l := range limiters {
+		if l.meter != nil {
+			if err := l.meter.use(n, l.upload); err != nil {
+				return err
+			}
+		}
+	}
+	var d time.Duration
+	for _, l := range limiters {
+		if w := l.reserve(n); w > d {
+			d = w
+		}
+	}
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Read(t *testing.T) {
+	l := &Limiter{}
+	l.SetRate(1 << 20)
+	start := time.Now()
+	// the first second is allowed by the burst
+	n, err := io.Copy(io.Discard, NewReader(context.Background(), bytes.NewReader(make([]byte, 5<<19)), l))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5<<19 {
+		t.Fatalf("read %d bytes, but expected %d", n, 5<<19)
+	}
+	if elapsed := time.Since(start); elapsed < 1400*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("read 2.5MB at 1MB/s took %s", elapsed)
+	}
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	l := &Limiter{}
+	start := time.Now()
+	if err := WaitN(context.Background(), 100<<20, l); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Errorf("unlimited limiter waited %s", elapsed)
+	}
+}
+
+func TestLimiter_Cancel(t *testing.T) {
+	l := &Limiter{}
+	l.SetRate(1024)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := WaitN(ctx, 10<<10, l); err == nil {
+		t.Error("expected the error of canceled context")
+	}
+}
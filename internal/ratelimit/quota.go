@@ -0,0 +1,378 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type quotaTarget struct {
+	scope  string
+	id     uint
+	period string
+}
+
+var (
+	quotaMu sync.RWMutex
+	quotas  = make(map[quotaTarget]model.TrafficQuota)
+)
+
+// quotaOf get the bytes the user can transfer in the period, 0 means unlimited
+func quotaOf(userId uint, groups []uint, period string, upload bool) int64 {
+	value := func(q model.TrafficQuota) int64 {
+		if upload {
+			return q.Upload
+		}
+		return q.Download
+	}
+	quotaMu.RLock()
+	defer quotaMu.RUnlock()
+	if q, ok := quotas[quotaTarget{scope: model.TrafficQuotaUser, id: userId, period: period}]; ok {
+		return value(q)
+	}
+	var res int64
+	for _, id := range groups {
+		q, ok := quotas[quotaTarget{scope: model.TrafficQuotaGroup, id: id, period: period}]
+		if !ok {
+			continue
+		}
+		v := value(q)
+		if v == 0 {
+			return 0
+		}
+		if v > res {
+			res = v
+		}
+	}
+	return res
+}
+
+// periodStart get the date the period including `now` starts, the monthly
+// period starts at the day of conf.TrafficQuotaResetDay
+func periodStart(period string, now time.Time) string {
+	if period == model.TrafficDaily {
+		return now.Format("2006-01-02")
+	}
+	day := setting.GetInt(conf.TrafficQuotaResetDay, 1)
+	if day < 1 || day > 28 {
+		day = 1
+	}
+	start := time.Date(now.Year(), now.Month(), day, 0, 0, 0, 0, now.Location())
+	if now.Before(start) {
+		start = start.AddDate(0, -1, 0)
+	}
+	return start.Format("2006-01-02")
+}
+
+var periods = []string{model.TrafficDaily, model.TrafficMonthly}
+
+// usage is the bytes transferred in a period, the pending bytes aren't saved yet
+type usage struct {
+	start                          string
+	download, upload               int64
+	pendingDownload, pendingUpload int64
+}
+
+func (u *usage) used(upload bool) int64 {
+	if upload {
+		return u.upload
+	}
+	return u.download
+}
+
+// meter count the bytes transferred by the user in the current periods
+type meter struct {
+	mu     sync.Mutex
+	userId uint
+	groups []uint
+	usages map[string]*usage
+	// the usages of the ended periods with the pending bytes
+	ended []model.TrafficUsage
+}
+
+func newMeter(userId uint) *meter {
+	return &meter{userId: userId, usages: make(map[string]*usage)}
+}
+
+func (m *meter) setGroups(groups []model.Group) {
+	ids := make([]uint, 0, len(groups))
+	for _, g := range groups {
+		ids = append(ids, g.ID)
+	}
+	m.mu.Lock()
+	m.groups = ids
+	m.mu.Unlock()
+}
+
+// refresh start the new periods and load their usages, m.mu must be held
+func (m *meter) refresh() {
+	now := time.Now()
+	for _, period := range periods {
+		start := periodStart(period, now)
+		u, ok := m.usages[period]
+		if ok && u.start == start {
+			continue
+		}
+		if ok && (u.pendingDownload > 0 || u.pendingUpload > 0) {
+			m.ended = append(m.ended, model.TrafficUsage{UserID: m.userId, Period: period, Start: u.start,
+				Download: u.pendingDownload, Upload: u.pendingUpload})
+		}
+		u = &usage{start: start}
+		saved, err := db.GetTrafficUsage(m.userId, period, start)
+		if err != nil {
+			log.Errorf("failed load the traffic usage of the user [%d]: %+v", m.userId, err)
+		} else {
+			u.download, u.upload = saved.Download, saved.Upload
+		}
+		m.usages[period] = u
+	}
+}
+
+// exceeded check if the usage of any period reaches the quota, m.mu must be held
+func (m *meter) exceeded(upload bool) bool {
+	for _, period := range periods {
+		limit := quotaOf(m.userId, m.groups, period, upload)
+		if limit > 0 && m.usages[period].used(upload) >= limit {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *meter) check(upload bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refresh()
+	if m.exceeded(upload) {
+		return errors.WithStack(errs.TrafficQuotaExceeded)
+	}
+	return nil
+}
+
+// use count the n bytes, errs.TrafficQuotaExceeded is returned if the quota is reached
+func (m *meter) use(n int, upload bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refresh()
+	for _, u := range m.usages {
+		if upload {
+			u.upload += int64(n)
+			u.pendingUpload += int64(n)
+		} else {
+			u.download += int64(n)
+			u.pendingDownload += int64(n)
+		}
+	}
+	if m.exceeded(upload) {
+		return errors.WithStack(errs.TrafficQuotaExceeded)
+	}
+	return nil
+}
+
+// pending take the bytes not saved yet
+func (m *meter) pending() []model.TrafficUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := m.ended
+	m.ended = nil
+	for period, u := range m.usages {
+		if u.pendingDownload == 0 && u.pendingUpload == 0 {
+			continue
+		}
+		res = append(res, model.TrafficUsage{UserID: m.userId, Period: period, Start: u.start,
+			Download: u.pendingDownload, Upload: u.pendingUpload})
+		u.pendingDownload, u.pendingUpload = 0, 0
+	}
+	return res
+}
+
+// reset clear the usages of the current periods
+func (m *meter) reset() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refresh()
+	starts := make([]string, 0, len(m.usages))
+	for _, u := range m.usages {
+		starts = append(starts, u.start)
+	}
+	if err := db.DeleteTrafficUsages(m.userId, starts); err != nil {
+		return err
+	}
+	for _, u := range m.usages {
+		*u = usage{start: u.start}
+	}
+	return nil
+}
+
+func userMeter(userId uint) *meter {
+	return getPair(model.RateLimitUser, userId).download.meter
+}
+
+// CheckDownload check if the user can download more, errs.TrafficQuotaExceeded is returned if not
+func CheckDownload(user *model.User) error {
+	if user == nil {
+		return nil
+	}
+	m := userMeter(user.ID)
+	m.setGroups(user.Groups)
+	return m.check(false)
+}
+
+// CheckUpload check if the user can upload more
+func CheckUpload(user *model.User) error {
+	if user == nil {
+		return nil
+	}
+	m := userMeter(user.ID)
+	m.setGroups(user.Groups)
+	return m.check(true)
+}
+
+// GetTrafficUsages get the usages of the user in the current periods
+func GetTrafficUsages(userId uint) []model.TrafficUsage {
+	m := userMeter(userId)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refresh()
+	res := make([]model.TrafficUsage, 0, len(periods))
+	for _, period := range periods {
+		u := m.usages[period]
+		res = append(res, model.TrafficUsage{UserID: userId, Period: period, Start: u.start,
+			Download: u.download, Upload: u.upload})
+	}
+	return res
+}
+
+// ResetTrafficUsages clear the usages of the user in the current periods
+func ResetTrafficUsages(userId uint) error {
+	m := userMeter(userId)
+	// save the pending bytes first, so they aren't added back after the reset
+	save(m)
+	return m.reset()
+}
+
+func save(m *meter) {
+	for _, u := range m.pending() {
+		if err := db.AddTrafficUsage(u); err != nil {
+			log.Errorf("failed save the traffic usage of the user [%d]: %+v", u.UserID, err)
+		}
+	}
+}
+
+func saveAll() {
+	mu.Lock()
+	var meters []*meter
+	for t, p := range limiters {
+		if t.scope == model.RateLimitUser {
+			meters = append(meters, p.download.meter)
+		}
+	}
+	mu.Unlock()
+	for _, m := range meters {
+		save(m)
+	}
+}
+
+// SaveUsages save the traffic usages periodically, it returns when ctx is done
+func SaveUsages(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			saveAll()
+			return
+		case <-ticker.C:
+			saveAll()
+		}
+	}
+}
+
+func loadQuotas() error {
+	list, err := db.GetTrafficQuotas()
+	if err != nil {
+		return err
+	}
+	quotaMu.Lock()
+	defer quotaMu.Unlock()
+	for _, q := range list {
+		quotas[quotaTarget{scope: q.Scope, id: q.TargetID, period: q.Period}] = q
+	}
+	return nil
+}
+
+func setQuota(q model.TrafficQuota) {
+	quotaMu.Lock()
+	defer quotaMu.Unlock()
+	quotas[quotaTarget{scope: q.Scope, id: q.TargetID, period: q.Period}] = q
+}
+
+func deleteQuota(q model.TrafficQuota) {
+	quotaMu.Lock()
+	defer quotaMu.Unlock()
+	delete(quotas, quotaTarget{scope: q.Scope, id: q.TargetID, period: q.Period})
+}
+
+func checkQuota(q *model.TrafficQuota) error {
+	switch q.Scope {
+	case model.TrafficQuotaUser, model.TrafficQuotaGroup:
+	default:
+		return errors.Errorf("invalid scope: %s", q.Scope)
+	}
+	switch q.Period {
+	case model.TrafficDaily, model.TrafficMonthly:
+	default:
+		return errors.Errorf("invalid period: %s", q.Period)
+	}
+	if q.Download < 0 || q.Upload < 0 {
+		return errors.New("the quota can't be negative")
+	}
+	return nil
+}
+
+func CreateTrafficQuota(q *model.TrafficQuota) error {
+	if err := checkQuota(q); err != nil {
+		return err
+	}
+	if err := db.CreateTrafficQuota(q); err != nil {
+		return err
+	}
+	setQuota(*q)
+	return nil
+}
+
+func UpdateTrafficQuota(q *model.TrafficQuota) error {
+	if err := checkQuota(q); err != nil {
+		return err
+	}
+	old, err := db.GetTrafficQuotaById(q.ID)
+	if err != nil {
+		return err
+	}
+	if err = db.UpdateTrafficQuota(q); err != nil {
+		return err
+	}
+	deleteQuota(*old)
+	setQuota(*q)
+	return nil
+}
+
+func DeleteTrafficQuotaById(id uint) error {
+	old, err := db.GetTrafficQuotaById(id)
+	if err != nil {
+		return err
+	}
+	if err = db.DeleteTrafficQuotaById(id); err != nil {
+		return err
+	}
+	deleteQuota(*old)
+	return nil
+}
@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestQuotaOf(t *testing.T) {
+	for _, q := range []model.TrafficQuota{
+		{Scope: model.TrafficQuotaGroup, TargetID: 1, Period: model.TrafficDaily, Download: 100, Upload: 10},
+		{Scope: model.TrafficQuotaGroup, TargetID: 2, Period: model.TrafficDaily, Download: 200},
+		{Scope: model.TrafficQuotaUser, TargetID: 3, Period: model.TrafficDaily, Download: 50},
+	} {
+		setQuota(q)
+	}
+	defer func() {
+		quotas = make(map[quotaTarget]model.TrafficQuota)
+	}()
+	tests := []struct {
+		user   uint
+		groups []uint
+		upload bool
+		want   int64
+	}{
+		{user: 1, groups: []uint{1}, want: 100},
+		// the largest quota of the groups is used
+		{user: 1, groups: []uint{1, 2}, want: 200},
+		// the upload is unlimited in the group 2
+		{user: 1, groups: []uint{1, 2}, upload: true, want: 0},
+		{user: 1, groups: []uint{1}, upload: true, want: 10},
+		// the quota of the user takes precedence over the groups
+		{user: 3, groups: []uint{2}, want: 50},
+		{user: 4, want: 0},
+	}
+	for _, tt := range tests {
+		if got := quotaOf(tt.user, tt.groups, model.TrafficDaily, tt.upload); got != tt.want {
+			t.Errorf("quotaOf(%d, %v, upload=%v) = %d, want %d", tt.user, tt.groups, tt.upload, got, tt.want)
+		}
+	}
+	if got := quotaOf(1, []uint{1}, model.TrafficMonthly, false); got != 0 {
+		t.Errorf("the monthly quota = %d, want unlimited", got)
+	}
+}
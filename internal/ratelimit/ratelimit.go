@@ -0,0 +1,168 @@
+// Package ratelimit limit the bandwidth of downloading and uploading through alist,
+// the limits of the whole site, the storages and the users are applied together.
+// the traffic of the users is also counted and limited by the quotas in the periods
+package ratelimit
+
+import (
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+type target struct {
+	scope string
+	id    uint
+}
+
+type limiterPair struct {
+	download Limiter
+	upload   Limiter
+}
+
+var (
+	mu sync.Mutex
+	// the limiters are created when they are needed, and never deleted,
+	// so that the streams in progress are affected by changing the limits
+	limiters = make(map[target]*limiterPair)
+)
+
+func getPair(scope string, id uint) *limiterPair {
+	if scope == model.RateLimitGlobal {
+		id = 0
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	t := target{scope: scope, id: id}
+	p, ok := limiters[t]
+	if !ok {
+		p = &limiterPair{}
+		if scope == model.RateLimitUser {
+			m := newMeter(id)
+			p.download.meter, p.upload.meter, p.upload.upload = m, m, true
+		}
+		limiters[t] = p
+	}
+	return p
+}
+
+func pairs(storage *model.Storage, user *model.User) []*limiterPair {
+	res := []*limiterPair{getPair(model.RateLimitGlobal, 0)}
+	if storage != nil {
+		res = append(res, getPair(model.RateLimitStorage, storage.ID))
+	}
+	if user != nil {
+		p := getPair(model.RateLimitUser, user.ID)
+		p.download.meter.setGroups(user.Groups)
+		res = append(res, p)
+	}
+	return res
+}
+
+// Download get the limiters of downloading the files of the storage by the user,
+// the storage and user can be nil if they are unknown
+func Download(storage *model.Storage, user *model.User) []*Limiter {
+	var res []*Limiter
+	for _, p := range pairs(storage, user) {
+		res = append(res, &p.download)
+	}
+	return res
+}
+
+// Upload get the limiters of uploading the files to the storage by the user
+func Upload(storage *model.Storage, user *model.User) []*Limiter {
+	var res []*Limiter
+	for _, p := range pairs(storage, user) {
+		res = append(res, &p.upload)
+	}
+	return res
+}
+
+// shareScope is not a scope of model.RateLimit, the rate is set by the share
+const shareScope = "share"
+
+// Share get the limiter of all the downloads of the share, and set its rate
+func Share(id uint, rate int64) *Limiter {
+	l := &getPair(shareScope, id).download
+	if l.Rate() != rate {
+		l.SetRate(rate)
+	}
+	return l
+}
+
+func apply(l model.RateLimit) {
+	p := getPair(l.Scope, l.TargetID)
+	p.download.SetRate(l.Download)
+	p.upload.SetRate(l.Upload)
+}
+
+func reset(l model.RateLimit) {
+	l.Download, l.Upload = 0, 0
+	apply(l)
+}
+
+// Load apply the limits and the traffic quotas saved in the database
+func Load() error {
+	limits, err := db.GetRateLimits()
+	if err != nil {
+		return err
+	}
+	for _, l := range limits {
+		apply(l)
+	}
+	return loadQuotas()
+}
+
+func check(l *model.RateLimit) error {
+	switch l.Scope {
+	case model.RateLimitGlobal:
+		l.TargetID = 0
+	case model.RateLimitStorage, model.RateLimitUser:
+	default:
+		return errors.Errorf("invalid scope: %s", l.Scope)
+	}
+	if l.Download < 0 || l.Upload < 0 {
+		return errors.New("the limit can't be negative")
+	}
+	return nil
+}
+
+func CreateRateLimit(l *model.RateLimit) error {
+	if err := check(l); err != nil {
+		return err
+	}
+	if err := db.CreateRateLimit(l); err != nil {
+		return err
+	}
+	apply(*l)
+	return nil
+}
+
+func UpdateRateLimit(l *model.RateLimit) error {
+	if err := check(l); err != nil {
+		return err
+	}
+	old, err := db.GetRateLimitById(l.ID)
+	if err != nil {
+		return err
+	}
+	if err = db.UpdateRateLimit(l); err != nil {
+		return err
+	}
+	reset(*old)
+	apply(*l)
+	return nil
+}
+
+func DeleteRateLimitById(id uint) error {
+	old, err := db.GetRateLimitById(id)
+	if err != nil {
+		return err
+	}
+	if err = db.DeleteRateLimitById(id); err != nil {
+		return err
+	}
+	reset(*old)
+	return nil
+}
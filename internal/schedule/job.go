@@ -0,0 +1,72 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/task"
+	"github.com/pkg/errors"
+)
+
+// execute the job with the permission of the admin, return the message of the result
+func execute(t *task.Task[uint64], job model.Job) (string, error) {
+	admin, err := db.GetAdmin()
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get admin")
+	}
+	ctx := context.WithValue(t.Ctx, "user", admin)
+	ctx = context.WithValue(ctx, "meta", (*model.Meta)(nil))
+	t.SetStatus(fmt.Sprintf("listing [%s]", job.SrcPath))
+	objs, err := fs.List(ctx, job.SrcPath, true)
+	if err != nil {
+		return "", errors.WithMessagef(err, "failed list [%s]", job.SrcPath)
+	}
+	var names []string
+	deadline := time.Now().AddDate(0, 0, -job.MaxAge)
+	for _, obj := range objs {
+		if job.Type == model.JobClean && !obj.ModTime().Before(deadline) {
+			continue
+		}
+		names = append(names, obj.GetName())
+	}
+	if len(names) == 0 {
+		return "nothing to do", nil
+	}
+	switch job.Type {
+	case model.JobCopy:
+		t.SetStatus(fmt.Sprintf("copying %d objects", len(names)))
+		n, err := fs.BatchCopy(ctx, job.SrcPath, names, job.DstPath)
+		if err != nil {
+			return "", err
+		}
+		fs.ClearCache(job.DstPath)
+		if n > 0 {
+			return fmt.Sprintf("added %d copy tasks", n), nil
+		}
+		return fmt.Sprintf("copied %d objects", len(names)), nil
+	case model.JobMove:
+		t.SetStatus(fmt.Sprintf("moving %d objects", len(names)))
+		n, err := fs.BatchMove(ctx, job.SrcPath, names, job.DstPath)
+		if err != nil {
+			return "", err
+		}
+		fs.ClearCache(job.SrcPath)
+		fs.ClearCache(job.DstPath)
+		if n > 0 {
+			return fmt.Sprintf("added %d move tasks", n), nil
+		}
+		return fmt.Sprintf("moved %d objects", len(names)), nil
+	case model.JobClean:
+		t.SetStatus(fmt.Sprintf("removing %d objects", len(names)))
+		if err := fs.BatchRemove(ctx, job.SrcPath, names); err != nil {
+			return "", err
+		}
+		fs.ClearCache(job.SrcPath)
+		return fmt.Sprintf("removed %d objects", len(names)), nil
+	}
+	return "", errors.Errorf("invalid job type: %s", job.Type)
+}
@@ -0,0 +1,208 @@
+// Package schedule run the jobs defined by the admin periodically with the task manager
+package schedule
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var JobTaskManager = task.NewTaskManager(2, func(tid *uint64) {
+	atomic.AddUint64(tid, 1)
+})
+
+type entry struct {
+	job      model.Job
+	schedule *cron.Schedule
+	// the job is skipped if the last run is not finished
+	running bool
+}
+
+var (
+	mu      sync.Mutex
+	entries = make(map[uint]*entry)
+)
+
+// Start load the jobs and check them every minute
+func Start() error {
+	if err := db.MarkRunningJobRunsErrored("interrupted by restart"); err != nil {
+		return err
+	}
+	jobs, err := db.GetJobs()
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if err := load(job); err != nil {
+			log.Errorf("failed load job [%s]: %+v", job.Name, err)
+		}
+	}
+	go loop()
+	return nil
+}
+
+func loop() {
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		time.Sleep(next.Sub(now))
+		mu.Lock()
+		for _, e := range entries {
+			if !e.job.Disabled && e.schedule.Match(next) {
+				submit(e)
+			}
+		}
+		mu.Unlock()
+	}
+}
+
+func load(job model.Job) error {
+	s, err := cron.Parse(job.Cron)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if e, ok := entries[job.ID]; ok {
+		e.job, e.schedule = job, s
+	} else {
+		entries[job.ID] = &entry{job: job, schedule: s}
+	}
+	return nil
+}
+
+// submit add a task to run the job, mu must be held
+func submit(e *entry) bool {
+	if e.running {
+		log.Warnf("skip job [%s] since the last run is not finished", e.job.Name)
+		return false
+	}
+	e.running = true
+	job := e.job
+	JobTaskManager.Submit(task.WithCancelCtx(&task.Task[uint64]{
+		Name: fmt.Sprintf("run job [%s]", job.Name),
+		Func: func(t *task.Task[uint64]) error {
+			defer func() {
+				mu.Lock()
+				e.running = false
+				mu.Unlock()
+			}()
+			return run(t, job)
+		},
+	}))
+	return true
+}
+
+func run(t *task.Task[uint64], job model.Job) error {
+	r := &model.JobRun{
+		JobID:     job.ID,
+		StartedAt: time.Now(),
+		State:     model.JobRunRunning,
+	}
+	if err := db.CreateJobRun(r); err != nil {
+		return err
+	}
+	msg, err := execute(t, job)
+	now := time.Now()
+	r.FinishedAt = &now
+	if err != nil {
+		r.State = model.JobRunErrored
+		r.Message = err.Error()
+	} else {
+		r.State = model.JobRunSucceeded
+		r.Message = msg
+	}
+	t.SetStatus(r.Message)
+	if e := db.UpdateJobRun(r); e != nil {
+		log.Errorf("failed update run of job [%s]: %+v", job.Name, e)
+	}
+	return err
+}
+
+func check(job *model.Job) error {
+	if _, err := cron.Parse(job.Cron); err != nil {
+		return errors.WithMessage(err, "invalid cron")
+	}
+	job.SrcPath = utils.StandardizePath(job.SrcPath)
+	switch job.Type {
+	case model.JobCopy, model.JobMove:
+		if job.DstPath == "" {
+			return errors.New("the dst path is required")
+		}
+		job.DstPath = utils.StandardizePath(job.DstPath)
+	case model.JobClean:
+		if job.MaxAge <= 0 {
+			return errors.New("the max age must be greater than 0")
+		}
+	default:
+		return errors.Errorf("invalid job type: %s", job.Type)
+	}
+	return nil
+}
+
+func CreateJob(job *model.Job) error {
+	if err := check(job); err != nil {
+		return err
+	}
+	if err := db.CreateJob(job); err != nil {
+		return err
+	}
+	return load(*job)
+}
+
+func UpdateJob(job *model.Job) error {
+	if err := check(job); err != nil {
+		return err
+	}
+	if _, err := db.GetJobById(job.ID); err != nil {
+		return err
+	}
+	if err := db.UpdateJob(job); err != nil {
+		return err
+	}
+	return load(*job)
+}
+
+func DeleteJobById(id uint) error {
+	if err := db.DeleteJobById(id); err != nil {
+		return err
+	}
+	mu.Lock()
+	delete(entries, id)
+	mu.Unlock()
+	return nil
+}
+
+// RunJob run the job now, even if it's disabled
+func RunJob(id uint) error {
+	mu.Lock()
+	defer mu.Unlock()
+	e, ok := entries[id]
+	if !ok {
+		return errors.Errorf("job [%d] not found", id)
+	}
+	if !submit(e) {
+		return errors.New("the last run of the job is not finished")
+	}
+	return nil
+}
+
+// NextRun get the next time to run the job, the zero time if the job is disabled or not found
+func NextRun(id uint) time.Time {
+	mu.Lock()
+	defer mu.Unlock()
+	e, ok := entries[id]
+	if !ok || e.job.Disabled {
+		return time.Time{}
+	}
+	return e.schedule.Next(time.Now())
+}
@@ -0,0 +1,444 @@
+package search
+
+import (
+	"context"
+	stdpath "path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/search/extract"
+	"github.com/alist-org/alist/v3/internal/search/searcher"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the count of the nodes added to the index at once
+const batchSize = 100
+
+const (
+	// the count of the objects listed at once, the pages are indexed while listing
+	listPageSize = 1000
+	// the max count of the dirs listed concurrently
+	listConcurrency = 4
+)
+
+type Progress struct {
+	ObjCount     uint64     `json:"obj_count"`
+	IsDone       bool       `json:"is_done"`
+	LastDoneTime *time.Time `json:"last_done_time"`
+	Error        string     `json:"error"`
+}
+
+var (
+	buildMu  sync.Mutex
+	progress = Progress{IsDone: true}
+	cancel   context.CancelFunc
+)
+
+func GetProgress() Progress {
+	buildMu.Lock()
+	defer buildMu.Unlock()
+	return progress
+}
+
+// BuildIndex walk the paths and replace their nodes in the index, it runs in background
+func BuildIndex(paths []string) error {
+	return start(func(ctx context.Context, b *builder) error {
+		for _, path := range paths {
+			if err := b.build(ctx, utils.StandardizePath(path)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateIndex walk the paths and update the nodes that are changed, it runs in background.
+// unlike BuildIndex the nodes aren't deleted first, so the search works while updating
+func UpdateIndex(paths []string) error {
+	return start(func(ctx context.Context, b *builder) error {
+		for _, path := range paths {
+			if err := b.update(ctx, utils.StandardizePath(path)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// start run the walk in background, only one walk is running at a time
+func start(walk func(ctx context.Context, b *builder) error) error {
+	s := get()
+	if s == nil {
+		return errors.WithStack(errs.SearchNotSupported)
+	}
+	buildMu.Lock()
+	defer buildMu.Unlock()
+	if !progress.IsDone {
+		return errors.New("the index is building")
+	}
+	ctx, c := context.WithCancel(context.Background())
+	ctx, err := adminCtx(ctx)
+	if err != nil {
+		c()
+		return err
+	}
+	cancel = c
+	progress = Progress{LastDoneTime: progress.LastDoneTime}
+	go func() {
+		defer c()
+		b := newBuilder(s)
+		err := walk(ctx, b)
+		if err == nil {
+			err = b.flush(ctx)
+		}
+		buildMu.Lock()
+		defer buildMu.Unlock()
+		progress.IsDone = true
+		if err != nil {
+			if utils.IsCanceled(ctx) {
+				progress.Error = "stopped"
+			} else {
+				log.Errorf("failed to build index: %+v", err)
+				progress.Error = err.Error()
+			}
+			return
+		}
+		now := time.Now()
+		progress.LastDoneTime = &now
+	}()
+	return nil
+}
+
+// adminCtx add the admin to ctx, the index is built with all the objects
+func adminCtx(ctx context.Context) (context.Context, error) {
+	admin, err := db.GetAdmin()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get admin")
+	}
+	ctx = context.WithValue(ctx, "user", admin)
+	return context.WithValue(ctx, "meta", (*model.Meta)(nil)), nil
+}
+
+// StopIndex cancel the building, the nodes indexed are kept
+func StopIndex() {
+	buildMu.Lock()
+	defer buildMu.Unlock()
+	if !progress.IsDone && cancel != nil {
+		cancel()
+	}
+}
+
+// Clear delete all the nodes in the index
+func Clear(ctx context.Context) error {
+	s := get()
+	if s == nil {
+		return errors.WithStack(errs.SearchNotSupported)
+	}
+	buildMu.Lock()
+	defer buildMu.Unlock()
+	if !progress.IsDone {
+		return errors.New("the index is building")
+	}
+	if err := s.Clear(ctx); err != nil {
+		return err
+	}
+	progress = Progress{IsDone: true}
+	return nil
+}
+
+type builder struct {
+	s            searcher.Searcher
+	ignorePaths  []string
+	maxDepth     int
+	indexContent bool
+	maxSize      int64
+	// the slots of walking the dirs concurrently
+	sem   chan struct{}
+	mu    sync.Mutex
+	nodes []model.IndexNode
+}
+
+func newBuilder(s searcher.Searcher) *builder {
+	b := &builder{
+		s:            s,
+		maxDepth:     setting.GetInt(conf.MaxIndexDepth, 20),
+		indexContent: setting.GetBool(conf.SearchIndexContent),
+		maxSize:      int64(setting.GetInt(conf.SearchContentMaxSize, 10)) * 1024 * 1024,
+		sem:          make(chan struct{}, listConcurrency),
+	}
+	for _, path := range strings.Split(setting.GetStr(conf.IgnorePaths), "\n") {
+		if path = strings.TrimSpace(path); path != "" {
+			b.ignorePaths = append(b.ignorePaths, utils.StandardizePath(path))
+		}
+	}
+	return b
+}
+
+func (b *builder) ignored(path string) bool {
+	for _, ignore := range b.ignorePaths {
+		if utils.IsSubPath(ignore, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// build replace the nodes of the path and the objects under it
+func (b *builder) build(ctx context.Context, path string) error {
+	if err := b.s.Del(ctx, path); err != nil {
+		return errors.WithMessagef(err, "failed to delete the index of [%s]", path)
+	}
+	if b.ignored(path) {
+		return nil
+	}
+	if path != "/" {
+		obj, err := fs.Get(ctx, path)
+		if err != nil {
+			return errors.WithMessagef(err, "failed get [%s]", path)
+		}
+		if err = b.add(ctx, stdpath.Dir(path), obj); err != nil {
+			return err
+		}
+		if !obj.IsDir() {
+			return nil
+		}
+	}
+	return b.walk(ctx, path, 0)
+}
+
+func (b *builder) walk(ctx context.Context, dir string, depth int) error {
+	if depth >= b.maxDepth {
+		return nil
+	}
+	var dirs []string
+	marker := ""
+	for {
+		objs, next, err := fs.ListPage(ctx, dir, marker, listPageSize, false)
+		if err != nil {
+			if utils.IsCanceled(ctx) {
+				return ctx.Err()
+			}
+			// the broken storages shouldn't stop the building
+			log.Warnf("failed list [%s] while building index: %+v", dir, err)
+			break
+		}
+		for _, obj := range objs {
+			path := stdpath.Join(dir, obj.GetName())
+			if b.ignored(path) {
+				continue
+			}
+			if err := b.add(ctx, dir, obj); err != nil {
+				return err
+			}
+			if obj.IsDir() {
+				dirs = append(dirs, path)
+			}
+		}
+		if next == "" {
+			break
+		}
+		marker = next
+	}
+	return b.walkDirs(ctx, dirs, depth+1)
+}
+
+// walkDirs walk the dirs concurrently if there are free slots, or walk them one by one
+func (b *builder) walkDirs(ctx context.Context, dirs []string, depth int) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var walkErr error
+	setErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if walkErr == nil {
+			walkErr = err
+		}
+	}
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return walkErr != nil
+	}
+	for _, dir := range dirs {
+		if failed() {
+			break
+		}
+		select {
+		case b.sem <- struct{}{}:
+			wg.Add(1)
+			go func(dir string) {
+				defer wg.Done()
+				defer func() { <-b.sem }()
+				if err := b.walk(ctx, dir, depth); err != nil {
+					setErr(err)
+				}
+			}(dir)
+		default:
+			if err := b.walk(ctx, dir, depth); err != nil {
+				setErr(err)
+			}
+		}
+	}
+	wg.Wait()
+	return walkErr
+}
+
+// update check the path and update the nodes if it's changed
+func (b *builder) update(ctx context.Context, path string) error {
+	if b.ignored(path) {
+		return b.s.Del(ctx, path)
+	}
+	if path != "/" {
+		obj, err := fs.Get(ctx, path)
+		if err != nil {
+			if errs.IsObjectNotFound(err) {
+				return b.s.Del(ctx, path)
+			}
+			return errors.WithMessagef(err, "failed get [%s]", path)
+		}
+		if !obj.IsDir() {
+			return b.build(ctx, path)
+		}
+	}
+	return b.reconcile(ctx, path, 0, true)
+}
+
+// reconcile compare the objects in the dir with the nodes in the index, the new and
+// changed objects are added, the nodes of the objects that don't exist are deleted.
+// the objects are compared by the type and the size, so the content isn't extracted again if unchanged.
+// the existing sub dirs are reconciled too if recursive, the new sub dirs are always walked
+func (b *builder) reconcile(ctx context.Context, dir string, depth int, recursive bool) error {
+	if depth >= b.maxDepth {
+		return nil
+	}
+	// refresh to find the changes not made through alist
+	objs, err := fs.List(ctx, dir, true)
+	if err != nil {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		log.Warnf("failed list [%s] while updating index: %+v", dir, err)
+		return nil
+	}
+	nodes, err := b.s.List(ctx, dir)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get the nodes in [%s]", dir)
+	}
+	indexed := make(map[string]model.SearchNode, len(nodes))
+	for _, node := range nodes {
+		indexed[node.Name] = node
+	}
+	for _, obj := range objs {
+		path := stdpath.Join(dir, obj.GetName())
+		if b.ignored(path) {
+			continue
+		}
+		node, ok := indexed[obj.GetName()]
+		delete(indexed, obj.GetName())
+		if ok && node.IsDir == obj.IsDir() {
+			if obj.IsDir() {
+				if !recursive {
+					continue
+				}
+				if err := b.reconcile(ctx, path, depth+1, true); err != nil {
+					return err
+				}
+				continue
+			}
+			if node.Size == obj.GetSize() {
+				continue
+			}
+		}
+		if ok && node.IsDir != obj.IsDir() {
+			if err := b.s.Del(ctx, path); err != nil {
+				return errors.WithMessagef(err, "failed to delete the index of [%s]", path)
+			}
+		}
+		if err := b.add(ctx, dir, obj); err != nil {
+			return err
+		}
+		if obj.IsDir() {
+			if err := b.walk(ctx, path, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	// the objects are removed
+	for name := range indexed {
+		path := stdpath.Join(dir, name)
+		if err := b.s.Del(ctx, path); err != nil {
+			return errors.WithMessagef(err, "failed to delete the index of [%s]", path)
+		}
+	}
+	return nil
+}
+
+func (b *builder) add(ctx context.Context, parent string, obj model.Obj) error {
+	if utils.IsCanceled(ctx) {
+		return ctx.Err()
+	}
+	node := model.IndexNode{
+		Parent:   parent,
+		Name:     obj.GetName(),
+		IsDir:    obj.IsDir(),
+		Size:     obj.GetSize(),
+		Type:     model.NodeType(obj.GetName(), obj.IsDir()),
+		Ext:      model.NodeExt(obj.GetName(), obj.IsDir()),
+		Modified: obj.ModTime(),
+	}
+	if b.indexContent && !obj.IsDir() && obj.GetSize() <= b.maxSize && extract.Supported(node.Name) {
+		node.Content = b.content(ctx, stdpath.Join(parent, node.Name))
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nodes = append(b.nodes, node)
+	buildMu.Lock()
+	progress.ObjCount++
+	buildMu.Unlock()
+	if len(b.nodes) >= batchSize {
+		return b.flushLocked(ctx)
+	}
+	return nil
+}
+
+// content extract the text of the file, the file is still indexed by the name if it fails
+func (b *builder) content(ctx context.Context, path string) string {
+	file, err := fs.Open(ctx, path)
+	if err != nil {
+		log.Warnf("failed open [%s] to index the content: %+v", path, err)
+		return ""
+	}
+	defer file.Close()
+	text, err := extract.Extract(path, file, b.maxSize)
+	if err != nil {
+		log.Warnf("failed extract the content of [%s]: %+v", path, err)
+		return ""
+	}
+	return text
+}
+
+func (b *builder) flush(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.flushLocked(ctx)
+}
+
+func (b *builder) flushLocked(ctx context.Context) error {
+	if len(b.nodes) == 0 {
+		return nil
+	}
+	if err := b.s.BatchIndex(ctx, b.nodes); err != nil {
+		return errors.WithMessage(err, "failed to add the nodes to the index")
+	}
+	b.nodes = b.nodes[:0]
+	return nil
+}
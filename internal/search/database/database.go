@@ -0,0 +1,62 @@
+// Package database is the built-in search backend using the database of alist,
+// the keywords are matched by like, so it's only suitable for small indexes
+package database
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/search/searcher"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type DB struct{}
+
+func (d DB) Config() searcher.Config {
+	return searcher.Config{Name: "database"}
+}
+
+func (d DB) Search(ctx context.Context, parent, keywords string, filters model.SearchFilters, limit int) ([]model.SearchNode, error) {
+	nodes, err := db.SearchIndexNodes(parent, keywords, filters, limit)
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(nodes, func(n model.IndexNode) (model.SearchNode, error) {
+		return n.SearchNode(), nil
+	})
+}
+
+func (d DB) List(ctx context.Context, parent string) ([]model.SearchNode, error) {
+	nodes, err := db.GetIndexNodesByParent(parent)
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(nodes, func(n model.IndexNode) (model.SearchNode, error) {
+		return n.SearchNode(), nil
+	})
+}
+
+func (d DB) BatchIndex(ctx context.Context, nodes []model.IndexNode) error {
+	return db.BatchCreateIndexNodes(nodes)
+}
+
+func (d DB) Del(ctx context.Context, path string) error {
+	return db.DeleteIndexNodes(path)
+}
+
+func (d DB) Clear(ctx context.Context) error {
+	return db.ClearIndexNodes()
+}
+
+func (d DB) Release(ctx context.Context) error {
+	return nil
+}
+
+var _ searcher.Searcher = DB{}
+
+func init() {
+	searcher.RegisterSearcher("database", func() (searcher.Searcher, error) {
+		return DB{}, nil
+	})
+}
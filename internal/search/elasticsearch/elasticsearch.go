@@ -0,0 +1,267 @@
+// Package elasticsearch is the search backend of the elasticsearch server,
+// the documents are in the index `alist`
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/search/searcher"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+const index = "alist"
+
+type Elasticsearch struct {
+	client *resty.Client
+}
+
+type document struct {
+	Path     string    `json:"path"`
+	Parent   string    `json:"parent"`
+	Parents  []string  `json:"parents"`
+	Name     string    `json:"name"`
+	IsDir    bool      `json:"is_dir"`
+	Size     int64     `json:"size"`
+	Type     int       `json:"type"`
+	Ext      string    `json:"ext"`
+	Modified time.Time `json:"modified"`
+	Content  string    `json:"content"`
+}
+
+func (e *Elasticsearch) Config() searcher.Config {
+	return searcher.Config{Name: "elasticsearch"}
+}
+
+func (e *Elasticsearch) request(ctx context.Context, method, url string, body interface{}, result interface{}) error {
+	req := e.client.R().SetContext(ctx)
+	if body != nil {
+		req.SetBody(body)
+	}
+	if result != nil {
+		req.SetResult(result)
+	}
+	res, err := req.Execute(method, url)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if res.IsError() {
+		return errors.Errorf("elasticsearch %s %s: %s", method, url, res.String())
+	}
+	return nil
+}
+
+// search get the nodes matching the query
+func (e *Elasticsearch) search(ctx context.Context, query interface{}, size int) ([]model.SearchNode, error) {
+	var resp struct {
+		Hits struct {
+			Hits []struct {
+				Source model.SearchNode `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	err := e.request(ctx, http.MethodPost, "/"+index+"/_search", map[string]interface{}{
+		"size":    size,
+		"_source": []string{"parent", "name", "is_dir", "size"},
+		"query":   query,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]model.SearchNode, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		nodes = append(nodes, hit.Source)
+	}
+	return nodes, nil
+}
+
+// filter build the filter clauses of the search
+func filter(parent string, filters model.SearchFilters) []interface{} {
+	clauses := []interface{}{}
+	if parent != "/" {
+		clauses = append(clauses, map[string]interface{}{"term": map[string]string{"parents": parent}})
+	}
+	if filters.Type != 0 {
+		clauses = append(clauses, map[string]interface{}{"term": map[string]int{"type": filters.Type}})
+	}
+	if exts := filters.LowerExts(); len(exts) > 0 {
+		clauses = append(clauses, map[string]interface{}{"terms": map[string][]string{"ext": exts}})
+	}
+	size := map[string]int64{}
+	if filters.MinSize > 0 {
+		size["gte"] = filters.MinSize
+	}
+	if filters.MaxSize > 0 {
+		size["lte"] = filters.MaxSize
+	}
+	if len(size) > 0 {
+		clauses = append(clauses, map[string]interface{}{"range": map[string]interface{}{"size": size}})
+	}
+	modified := map[string]time.Time{}
+	if filters.ModifiedAfter != nil {
+		modified["gte"] = *filters.ModifiedAfter
+	}
+	if filters.ModifiedBefore != nil {
+		modified["lte"] = *filters.ModifiedBefore
+	}
+	if len(modified) > 0 {
+		clauses = append(clauses, map[string]interface{}{"range": map[string]interface{}{"modified": modified}})
+	}
+	return clauses
+}
+
+func (e *Elasticsearch) Search(ctx context.Context, parent, keywords string, filters model.SearchFilters, limit int) ([]model.SearchNode, error) {
+	boolQuery := map[string]interface{}{
+		"filter": filter(parent, filters),
+	}
+	if strings.TrimSpace(keywords) != "" {
+		boolQuery["must"] = []interface{}{
+			map[string]interface{}{
+				"multi_match": map[string]interface{}{
+					"query":    keywords,
+					"fields":   []string{"name", "content"},
+					"operator": "and",
+				},
+			},
+		}
+	}
+	return e.search(ctx, map[string]interface{}{"bool": boolQuery}, limit)
+}
+
+// the max count of the documents got at once by List, it's the default max result window
+const maxChildren = 10000
+
+func (e *Elasticsearch) List(ctx context.Context, parent string) ([]model.SearchNode, error) {
+	return e.search(ctx, map[string]interface{}{"term": map[string]string{"parent": parent}}, maxChildren)
+}
+
+func (e *Elasticsearch) BatchIndex(ctx context.Context, nodes []model.IndexNode) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, node := range nodes {
+		path := stdpath.Join(node.Parent, node.Name)
+		action, err := utils.Json.Marshal(map[string]interface{}{
+			"index": map[string]string{"_index": index, "_id": utils.GetSHA1Encode(path)},
+		})
+		if err != nil {
+			return err
+		}
+		doc, err := utils.Json.Marshal(document{
+			Path:     path,
+			Parent:   node.Parent,
+			Parents:  searcher.Parents(node.Parent),
+			Name:     node.Name,
+			IsDir:    node.IsDir,
+			Size:     node.Size,
+			Type:     node.Type,
+			Ext:      node.Ext,
+			Modified: node.Modified,
+			Content:  node.Content,
+		})
+		if err != nil {
+			return err
+		}
+		buf.Write(action)
+		buf.WriteByte('\n')
+		buf.Write(doc)
+		buf.WriteByte('\n')
+	}
+	var resp struct {
+		Errors bool `json:"errors"`
+	}
+	res, err := e.client.R().SetContext(ctx).
+		SetHeader("Content-Type", "application/x-ndjson").
+		SetBody(buf.Bytes()).
+		SetResult(&resp).
+		Post("/_bulk")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if res.IsError() || resp.Errors {
+		return errors.New("elasticsearch failed to index some documents")
+	}
+	return nil
+}
+
+func (e *Elasticsearch) deleteByQuery(ctx context.Context, query interface{}) error {
+	return e.request(ctx, http.MethodPost, "/"+index+"/_delete_by_query?conflicts=proceed", map[string]interface{}{
+		"query": query,
+	}, nil)
+}
+
+func (e *Elasticsearch) Del(ctx context.Context, path string) error {
+	return e.deleteByQuery(ctx, map[string]interface{}{
+		"bool": map[string]interface{}{
+			"should": []interface{}{
+				map[string]interface{}{"term": map[string]string{"path": path}},
+				map[string]interface{}{"term": map[string]string{"parents": path}},
+			},
+		},
+	})
+}
+
+func (e *Elasticsearch) Clear(ctx context.Context) error {
+	return e.deleteByQuery(ctx, map[string]interface{}{"match_all": map[string]interface{}{}})
+}
+
+func (e *Elasticsearch) Release(ctx context.Context) error {
+	return nil
+}
+
+var _ searcher.Searcher = (*Elasticsearch)(nil)
+
+func init() {
+	searcher.RegisterSearcher("elasticsearch", func() (searcher.Searcher, error) {
+		u, err := url.Parse(setting.GetStr(conf.ElasticsearchUrl))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		client := resty.New().SetHeader("Content-Type", "application/json")
+		// the username and password can be in the url
+		if u.User != nil {
+			password, _ := u.User.Password()
+			client.SetBasicAuth(u.User.Username(), password)
+			u.User = nil
+		}
+		client.SetBaseURL(strings.TrimSuffix(u.String(), "/"))
+		e := &Elasticsearch{client: client}
+		ctx := context.Background()
+		// the index may exist already, the new fields are added to the mapping then
+		if e.request(ctx, http.MethodHead, "/"+index, nil, nil) != nil {
+			err = e.request(ctx, http.MethodPut, "/"+index, nil, nil)
+			if err != nil {
+				return nil, errors.WithMessage(err, "failed to init elasticsearch")
+			}
+		}
+		err = e.request(ctx, http.MethodPut, "/"+index+"/_mapping", map[string]interface{}{
+			"properties": map[string]interface{}{
+				"path":     map[string]string{"type": "keyword"},
+				"parent":   map[string]string{"type": "keyword"},
+				"parents":  map[string]string{"type": "keyword"},
+				"name":     map[string]string{"type": "text"},
+				"is_dir":   map[string]string{"type": "boolean"},
+				"size":     map[string]string{"type": "long"},
+				"type":     map[string]string{"type": "integer"},
+				"ext":      map[string]string{"type": "keyword"},
+				"modified": map[string]string{"type": "date"},
+				"content":  map[string]string{"type": "text"},
+			},
+		}, nil)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to init elasticsearch")
+		}
+		return e, nil
+	})
+}
@@ -0,0 +1,60 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// docxText get the text in word/document.xml, each paragraph is a line
+func docxText(content []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return "", errors.Wrap(err, "invalid docx")
+	}
+	for _, f := range zr.File {
+		if f.Name != "word/document.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		defer rc.Close()
+		return documentXmlText(rc)
+	}
+	return "", errors.New("invalid docx: word/document.xml not found")
+}
+
+func documentXmlText(r io.Reader) (string, error) {
+	var sb strings.Builder
+	decoder := xml.NewDecoder(r)
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			inText = t.Name.Local == "t"
+		case xml.EndElement:
+			inText = false
+			if t.Name.Local == "p" {
+				sb.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+	return sb.String(), nil
+}
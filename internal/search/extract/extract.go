@@ -0,0 +1,48 @@
+// Package extract extracts the text of the documents to index their content
+package extract
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// Extractor extracts the text from the content of the file, the content is read fully into memory
+type Extractor func(content []byte) (string, error)
+
+var extractors = map[string]Extractor{
+	"pdf":  pdfText,
+	"docx": docxText,
+}
+
+// Supported return whether the content of the file can be extracted
+func Supported(name string) bool {
+	if _, ok := extractors[strings.ToLower(utils.Ext(name))]; ok {
+		return true
+	}
+	return utils.GetFileType(name) == conf.TEXT
+}
+
+// Extract read at most maxSize bytes from r and extract the text of the file named `name`
+func Extract(name string, r io.Reader, maxSize int64) (string, error) {
+	content, err := io.ReadAll(io.LimitReader(r, maxSize))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if extractor, ok := extractors[strings.ToLower(utils.Ext(name))]; ok {
+		return extractor(content)
+	}
+	return plainText(content), nil
+}
+
+// plainText drop the invalid utf-8 bytes, such as the char cut by the size limit
+func plainText(content []byte) string {
+	if utf8.Valid(content) {
+		return string(content)
+	}
+	return strings.ToValidUTF8(string(content), "")
+}
@@ -0,0 +1,95 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var streamReg = regexp.MustCompile(`(?s)stream\r?\n(.*?)\r?\nendstream`)
+
+// pdfText get the text shown by the Tj and TJ operators in the content streams,
+// it's the best effort, the text in the fonts with custom encodings can't be read
+func pdfText(content []byte) (string, error) {
+	var sb strings.Builder
+	for _, m := range streamReg.FindAllSubmatch(content, -1) {
+		data := m[1]
+		if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+			if inflated, err := io.ReadAll(zr); err == nil || len(inflated) > 0 {
+				data = inflated
+			}
+			_ = zr.Close()
+		}
+		pdfStreamText(data, &sb)
+	}
+	return plainText([]byte(sb.String())), nil
+}
+
+// pdfStreamText write the literal strings of the text operators in the stream to sb
+func pdfStreamText(data []byte, sb *strings.Builder) {
+	inText := false
+	for i := 0; i < len(data); i++ {
+		switch {
+		case bytes.HasPrefix(data[i:], []byte("BT")) && isDelimiter(data, i+2):
+			inText = true
+			i++
+		case bytes.HasPrefix(data[i:], []byte("ET")) && isDelimiter(data, i+2):
+			inText = false
+			sb.WriteByte('\n')
+			i++
+		case data[i] == '(' && inText:
+			var s []byte
+			s, i = readLiteral(data, i+1)
+			sb.Write(s)
+		}
+	}
+}
+
+func isDelimiter(data []byte, i int) bool {
+	if i >= len(data) {
+		return true
+	}
+	switch data[i] {
+	case ' ', '\n', '\r', '\t', '[', '(', '/':
+		return true
+	}
+	return false
+}
+
+// readLiteral read the literal string starting from i, return it and the index of the closing paren
+func readLiteral(data []byte, i int) ([]byte, int) {
+	var s []byte
+	depth := 1
+	for ; i < len(data); i++ {
+		c := data[i]
+		switch c {
+		case '\\':
+			i++
+			if i >= len(data) {
+				return s, i
+			}
+			switch data[i] {
+			case 'n':
+				s = append(s, '\n')
+			case 'r', 't', 'b', 'f':
+				s = append(s, ' ')
+			default:
+				s = append(s, data[i])
+			}
+		case '(':
+			depth++
+			s = append(s, c)
+		case ')':
+			depth--
+			if depth == 0 {
+				return s, i
+			}
+			s = append(s, c)
+		default:
+			s = append(s, c)
+		}
+	}
+	return s, i
+}
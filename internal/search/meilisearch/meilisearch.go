@@ -0,0 +1,193 @@
+// Package meilisearch is the search backend of the meilisearch server,
+// the documents are in the index `alist`
+package meilisearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/search/searcher"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+const index = "alist"
+
+type Meilisearch struct {
+	client *resty.Client
+}
+
+type document struct {
+	ID      string   `json:"id"`
+	Path    string   `json:"path"`
+	Parent  string   `json:"parent"`
+	Parents []string `json:"parents"`
+	Name    string   `json:"name"`
+	IsDir   bool     `json:"is_dir"`
+	Size    int64    `json:"size"`
+	Type    int      `json:"type"`
+	Ext     string   `json:"ext"`
+	// Modified is the unix time since only the numbers can be compared in the filters
+	Modified int64  `json:"modified"`
+	Content  string `json:"content"`
+}
+
+func (m *Meilisearch) Config() searcher.Config {
+	return searcher.Config{Name: "meilisearch"}
+}
+
+func (m *Meilisearch) request(ctx context.Context, method, url string, body interface{}, result interface{}) error {
+	req := m.client.R().SetContext(ctx)
+	if body != nil {
+		req.SetBody(body)
+	}
+	if result != nil {
+		req.SetResult(result)
+	}
+	res, err := req.Execute(method, url)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if res.IsError() {
+		return errors.Errorf("meilisearch %s %s: %s", method, url, res.String())
+	}
+	return nil
+}
+
+// filterValue quote the value in the filter expression
+func filterValue(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// filter build the filter expression of the search
+func filter(parent string, filters model.SearchFilters) string {
+	var conds []string
+	if parent != "/" {
+		conds = append(conds, "parents = "+filterValue(parent))
+	}
+	if filters.Type != 0 {
+		conds = append(conds, fmt.Sprintf("type = %d", filters.Type))
+	}
+	if exts := filters.LowerExts(); len(exts) > 0 {
+		values := make([]string, 0, len(exts))
+		for _, ext := range exts {
+			values = append(values, filterValue(ext))
+		}
+		conds = append(conds, fmt.Sprintf("ext IN [%s]", strings.Join(values, ", ")))
+	}
+	if filters.MinSize > 0 {
+		conds = append(conds, fmt.Sprintf("size >= %d", filters.MinSize))
+	}
+	if filters.MaxSize > 0 {
+		conds = append(conds, fmt.Sprintf("size <= %d", filters.MaxSize))
+	}
+	if filters.ModifiedAfter != nil {
+		conds = append(conds, fmt.Sprintf("modified >= %d", filters.ModifiedAfter.Unix()))
+	}
+	if filters.ModifiedBefore != nil {
+		conds = append(conds, fmt.Sprintf("modified <= %d", filters.ModifiedBefore.Unix()))
+	}
+	return strings.Join(conds, " AND ")
+}
+
+func (m *Meilisearch) Search(ctx context.Context, parent, keywords string, filters model.SearchFilters, limit int) ([]model.SearchNode, error) {
+	body := map[string]interface{}{
+		"q":                    keywords,
+		"limit":                limit,
+		"attributesToRetrieve": []string{"parent", "name", "is_dir", "size"},
+	}
+	if f := filter(parent, filters); f != "" {
+		body["filter"] = f
+	}
+	var resp struct {
+		Hits []model.SearchNode `json:"hits"`
+	}
+	err := m.request(ctx, http.MethodPost, fmt.Sprintf("/indexes/%s/search", index), body, &resp)
+	return resp.Hits, err
+}
+
+// the max count of the documents fetched at once by List
+const maxChildren = 10000
+
+func (m *Meilisearch) List(ctx context.Context, parent string) ([]model.SearchNode, error) {
+	var resp struct {
+		Results []model.SearchNode `json:"results"`
+	}
+	err := m.request(ctx, http.MethodPost, fmt.Sprintf("/indexes/%s/documents/fetch", index), map[string]interface{}{
+		"filter": "parent = " + filterValue(parent),
+		"limit":  maxChildren,
+		"fields": []string{"parent", "name", "is_dir", "size"},
+	}, &resp)
+	return resp.Results, err
+}
+
+func (m *Meilisearch) BatchIndex(ctx context.Context, nodes []model.IndexNode) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	docs := make([]document, 0, len(nodes))
+	for _, node := range nodes {
+		path := stdpath.Join(node.Parent, node.Name)
+		docs = append(docs, document{
+			ID:       utils.GetSHA1Encode(path),
+			Path:     path,
+			Parent:   node.Parent,
+			Parents:  searcher.Parents(node.Parent),
+			Name:     node.Name,
+			IsDir:    node.IsDir,
+			Size:     node.Size,
+			Type:     node.Type,
+			Ext:      node.Ext,
+			Modified: node.Modified.Unix(),
+			Content:  node.Content,
+		})
+	}
+	return m.request(ctx, http.MethodPost, fmt.Sprintf("/indexes/%s/documents", index), docs, nil)
+}
+
+func (m *Meilisearch) Del(ctx context.Context, path string) error {
+	return m.request(ctx, http.MethodPost, fmt.Sprintf("/indexes/%s/documents/delete", index), map[string]interface{}{
+		"filter": fmt.Sprintf("path = %s OR parents = %s", filterValue(path), filterValue(path)),
+	}, nil)
+}
+
+func (m *Meilisearch) Clear(ctx context.Context) error {
+	return m.request(ctx, http.MethodDelete, fmt.Sprintf("/indexes/%s/documents", index), nil, nil)
+}
+
+func (m *Meilisearch) Release(ctx context.Context) error {
+	return nil
+}
+
+var _ searcher.Searcher = (*Meilisearch)(nil)
+
+func init() {
+	searcher.RegisterSearcher("meilisearch", func() (searcher.Searcher, error) {
+		m := &Meilisearch{
+			client: resty.New().
+				SetBaseURL(strings.TrimSuffix(setting.GetStr(conf.MeilisearchUrl), "/")).
+				SetHeader("Content-Type", "application/json"),
+		}
+		if key := setting.GetStr(conf.MeilisearchKey); key != "" {
+			m.client.SetAuthToken(key)
+		}
+		ctx := context.Background()
+		// the index may exist already, it's created by the first document otherwise
+		_ = m.request(ctx, http.MethodPost, "/indexes", map[string]string{"uid": index, "primaryKey": "id"}, nil)
+		err := m.request(ctx, http.MethodPatch, fmt.Sprintf("/indexes/%s/settings", index), map[string]interface{}{
+			"filterableAttributes": []string{"path", "parent", "parents", "type", "ext", "size", "modified"},
+			"searchableAttributes": []string{"name", "content"},
+		}, nil)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to init meilisearch")
+		}
+		return m, nil
+	})
+}
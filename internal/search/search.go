@@ -0,0 +1,97 @@
+// Package search manages the search index, the objects of the storages are indexed
+// by walking them, then they can be searched by the name and the content
+package search
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	_ "github.com/alist-org/alist/v3/internal/search/database"
+	_ "github.com/alist-org/alist/v3/internal/search/elasticsearch"
+	_ "github.com/alist-org/alist/v3/internal/search/meilisearch"
+	"github.com/alist-org/alist/v3/internal/search/searcher"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the max count of the results of a search
+const maxResults = 1000
+
+var (
+	mu       sync.Mutex
+	instance searcher.Searcher
+	// the settings used to init the instance, it's inited again if they are changed
+	settings string
+	// the time of the last failed init, it's retried after a while
+	failedAt time.Time
+)
+
+func currentSettings() string {
+	return strings.Join([]string{
+		setting.GetStr(conf.SearchIndex),
+		setting.GetStr(conf.MeilisearchUrl),
+		setting.GetStr(conf.MeilisearchKey),
+		setting.GetStr(conf.ElasticsearchUrl),
+	}, "\n")
+}
+
+// get the searcher of the current settings, nil is returned if the index is disabled
+func get() searcher.Searcher {
+	mu.Lock()
+	defer mu.Unlock()
+	cur := currentSettings()
+	if cur == settings && (instance != nil || time.Since(failedAt) < time.Minute) {
+		return instance
+	}
+	if instance != nil {
+		if err := instance.Release(context.Background()); err != nil {
+			log.Errorf("failed to release searcher %s: %+v", instance.Config().Name, err)
+		}
+		instance = nil
+	}
+	settings = cur
+	s, err := newSearcher(setting.GetStr(conf.SearchIndex))
+	if err != nil {
+		failedAt = time.Now()
+		log.Errorf("failed to init search index: %+v", err)
+		return nil
+	}
+	instance = s
+	return instance
+}
+
+func newSearcher(mode string) (searcher.Searcher, error) {
+	if mode == "" || mode == "none" {
+		return nil, nil
+	}
+	n, ok := searcher.Get(mode)
+	if !ok {
+		return nil, errors.Errorf("unknown search index: %s", mode)
+	}
+	return n()
+}
+
+// Enabled return whether the search uses the index
+func Enabled() bool {
+	return get() != nil
+}
+
+// Search the index under the parent, the results that the user in ctx can't read are removed
+func Search(ctx context.Context, parent, keywords string, filters model.SearchFilters) ([]model.SearchNode, error) {
+	s := get()
+	if s == nil {
+		return nil, errors.WithStack(errs.SearchNotSupported)
+	}
+	nodes, err := s.Search(ctx, parent, keywords, filters, maxResults)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to search the index")
+	}
+	return fs.FilterAclNodes(ctx, nodes)
+}
@@ -0,0 +1,58 @@
+package searcher
+
+import (
+	"context"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+type Config struct {
+	Name string
+}
+
+// Searcher is the backend of the search index
+type Searcher interface {
+	Config() Config
+	// Search get at most `limit` nodes under `parent` whose name or content match the keywords
+	// and match the filters, all the nodes matching the filters are got if the keywords are empty
+	Search(ctx context.Context, parent, keywords string, filters model.SearchFilters, limit int) ([]model.SearchNode, error)
+	// List get the nodes directly in the parent, it's used to find the changes of the storages
+	List(ctx context.Context, parent string) ([]model.SearchNode, error)
+	// BatchIndex add the nodes to the index, the old nodes of the same paths are replaced
+	BatchIndex(ctx context.Context, nodes []model.IndexNode) error
+	// Del delete the node of `path` and the nodes under it
+	Del(ctx context.Context, path string) error
+	// Clear delete all the nodes
+	Clear(ctx context.Context) error
+	// Release the resources, it's called before switching to another searcher
+	Release(ctx context.Context) error
+}
+
+type New func() (Searcher, error)
+
+var searchers = make(map[string]New)
+
+func RegisterSearcher(name string, new New) {
+	searchers[name] = new
+}
+
+func Get(name string) (New, bool) {
+	n, ok := searchers[name]
+	return n, ok
+}
+
+// Parents return the dir and all its ancestors, they are indexed
+// by the external backends to search the nodes under a dir
+func Parents(dir string) []string {
+	res := []string{"/"}
+	cur := ""
+	for _, name := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if name == "" {
+			continue
+		}
+		cur += "/" + name
+		res = append(res, cur)
+	}
+	return res
+}
@@ -0,0 +1,152 @@
+package search
+
+import (
+	"context"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the events waiting to be applied to the index, the events are dropped if it's full
+// and the changes are found by the next reconciliation
+var events = make(chan op.Event, 1024)
+
+// OnEvent is the hook of the object events, the index is updated in background by Run
+func OnEvent(e op.Event) {
+	if get() == nil {
+		return
+	}
+	select {
+	case events <- e:
+	default:
+		log.Warnf("too many events, the index of [%s] is updated by the next reconciliation", e.Path)
+	}
+}
+
+// Run apply the events to the index and reconcile the whole index every
+// conf.IndexUpdateInterval hours, it returns when ctx is done
+func Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-events:
+			applyEvents(ctx, e)
+		case <-ticker.C:
+			interval := setting.GetInt(conf.IndexUpdateInterval, 0)
+			if interval <= 0 || time.Since(last) < time.Duration(interval)*time.Hour {
+				continue
+			}
+			if p := GetProgress(); p.LastDoneTime != nil && time.Since(*p.LastDoneTime) < time.Duration(interval)*time.Hour {
+				last = *p.LastDoneTime
+				continue
+			}
+			last = time.Now()
+			if err := UpdateIndex([]string{"/"}); err != nil {
+				log.Warnf("failed to start the index reconciliation: %+v", err)
+			}
+		}
+	}
+}
+
+// applyEvents apply e and the other events queued, the dirs are listed once for them
+func applyEvents(ctx context.Context, e op.Event) {
+	s := get()
+	if s == nil {
+		return
+	}
+	ctx, err := adminCtx(ctx)
+	if err != nil {
+		log.Errorf("failed to update index: %+v", err)
+		return
+	}
+	u := &updater{builder: newBuilder(s), dirs: make(map[string][]model.Obj)}
+	for {
+		if err := u.apply(ctx, e); err != nil {
+			log.Errorf("failed to update the index of [%s]: %+v", e.Path, err)
+		}
+		select {
+		case e = <-events:
+			continue
+		default:
+		}
+		break
+	}
+	if err := u.flush(ctx); err != nil {
+		log.Errorf("failed to update index: %+v", err)
+	}
+}
+
+type updater struct {
+	*builder
+	// the dirs listed while applying the events
+	dirs map[string][]model.Obj
+}
+
+func (u *updater) apply(ctx context.Context, e op.Event) error {
+	switch e.Type {
+	case op.EventRemove:
+		return u.s.Del(ctx, e.Path)
+	case op.EventMove, op.EventRename:
+		if err := u.s.Del(ctx, e.Path); err != nil {
+			return err
+		}
+		return u.index(ctx, e.DstPath)
+	case op.EventCopy:
+		return u.index(ctx, e.DstPath)
+	case op.EventChange:
+		if u.ignored(e.Path) {
+			return nil
+		}
+		// the list cache is cleared already, and the deeper changes are notified with their own dirs
+		return u.reconcile(ctx, e.Path, 0, false)
+	default:
+		return u.index(ctx, e.Path)
+	}
+}
+
+// index replace the nodes of the path with the object in the storage
+func (u *updater) index(ctx context.Context, path string) error {
+	if u.ignored(path) {
+		return nil
+	}
+	dir, name := stdpath.Split(path)
+	dir = stdpath.Clean(dir)
+	objs, ok := u.dirs[dir]
+	if !ok {
+		var err error
+		// the cache may not be cleared yet by the caller of the operation
+		objs, err = fs.List(ctx, dir, true)
+		if err != nil {
+			return errors.WithMessagef(err, "failed list [%s]", dir)
+		}
+		u.dirs[dir] = objs
+	}
+	for _, obj := range objs {
+		if obj.GetName() != name {
+			continue
+		}
+		if err := u.s.Del(ctx, path); err != nil {
+			return err
+		}
+		if err := u.add(ctx, dir, obj); err != nil {
+			return err
+		}
+		if obj.IsDir() {
+			return u.walk(ctx, path, 0)
+		}
+		return nil
+	}
+	// the object is changed again by the later events
+	return nil
+}
@@ -1,18 +1,24 @@
 package sign
 
 import (
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/pkg/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
 )
 
 var once sync.Once
 var instance sign.Sign
 
-func Sign(data string) string {
+// Sign sign the path to download, it expires after the link expiration if it's set, and it's bound
+// to the ip of the client if the setting is on. the ip is empty for the links not downloaded by the
+// client directly, such as the ones verified by the down proxies, they aren't bound
+func Sign(path, ip string) string {
+	data := signData(path, ip)
 	expire := setting.GetInt(conf.LinkExpiration, 0)
 	if expire == 0 {
 		return NotExpired(data)
@@ -31,9 +37,28 @@ func NotExpired(data string) string {
 	return instance.Sign(data, 0)
 }
 
-func Verify(data string, sign string) error {
+// Verify verify the sign of the path downloaded by the client of the ip
+func Verify(path, ip, sign string) error {
 	once.Do(Instance)
-	return instance.Verify(data, sign)
+	return instance.Verify(signData(path, ip), sign)
+}
+
+func signData(path, ip string) string {
+	path = utils.StandardizePath(path)
+	if ip != "" && setting.GetBool(conf.LinkBindIP) {
+		return path + "\n" + ip
+	}
+	return path
+}
+
+// Required check if the path is in the mounts requiring the signs by the setting
+func Required(path string) bool {
+	for _, p := range strings.Split(setting.GetStr(conf.SignPaths), "\n") {
+		if p = strings.TrimSpace(p); p != "" && utils.IsSubPath(p, path) {
+			return true
+		}
+	}
+	return false
 }
 
 func Instance() {
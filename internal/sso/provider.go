@@ -0,0 +1,170 @@
+package sso
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/pkg/errors"
+)
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// provider is the discovered configuration of the oidc provider
+type provider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+
+	mu      sync.Mutex
+	keys    map[string]interface{}
+	fetched time.Time
+}
+
+var (
+	providerMu     sync.Mutex
+	cachedProvider *provider
+)
+
+// getProvider discover the provider from the issuer in settings, it's cached until the issuer changed
+func getProvider(ctx context.Context) (*provider, error) {
+	issuer := strings.TrimSuffix(setting.GetStr(conf.SSOIssuer), "/")
+	if issuer == "" {
+		return nil, errors.New("sso issuer is not set")
+	}
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	if cachedProvider != nil && cachedProvider.Issuer == issuer {
+		return cachedProvider, nil
+	}
+	var p provider
+	if err := getJSON(ctx, issuer+"/.well-known/openid-configuration", &p); err != nil {
+		return nil, errors.WithMessage(err, "failed discover oidc provider")
+	}
+	if strings.TrimSuffix(p.Issuer, "/") != issuer {
+		return nil, errors.Errorf("issuer mismatch: %s", p.Issuer)
+	}
+	cachedProvider = &p
+	return cachedProvider, nil
+}
+
+func getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("failed get %s: %s", url, res.Status)
+	}
+	return errors.WithStack(json.NewDecoder(res.Body).Decode(v))
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// key get the public key by kid, the keys are fetched again if the kid is unknown,
+// since the provider may rotate the keys
+func (p *provider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+	// don't fetch too frequently with the unknown kid
+	if time.Since(p.fetched) < time.Minute {
+		return nil, errors.Errorf("unknown key id: %s", kid)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := getJSON(ctx, p.JwksUri, &set); err != nil {
+		return nil, errors.WithMessage(err, "failed get jwks")
+	}
+	p.fetched = time.Now()
+	p.keys = make(map[string]interface{})
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		p.keys[k.Kid] = pub
+	}
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+	return nil, errors.Errorf("unknown key id: %s", kid)
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,164 @@
+package sso
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func Enabled() bool {
+	return setting.GetBool(conf.SSOLoginEnabled)
+}
+
+// AuthURL return the url of the provider to redirect the user to
+func AuthURL(ctx context.Context, redirectURI, state, nonce string) (string, error) {
+	p, err := getProvider(ctx)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {setting.GetStr(conf.SSOClientId)},
+		"redirect_uri":  {redirectURI},
+		"scope":         {"openid profile email"},
+		"state":         {state},
+		"nonce":         {nonce},
+	}
+	sep := "?"
+	if strings.Contains(p.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.AuthorizationEndpoint + sep + query.Encode(), nil
+}
+
+// Exchange exchange the code for the id token
+func Exchange(ctx context.Context, redirectURI, code string) (string, error) {
+	p, err := getProvider(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {redirectURI},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(setting.GetStr(conf.SSOClientId)), url.QueryEscape(setting.GetStr(conf.SSOClientSecret)))
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer res.Body.Close()
+	var resp struct {
+		IdToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return "", errors.Wrapf(err, "failed decode token response: %s", res.Status)
+	}
+	if resp.Error != "" {
+		return "", errors.Errorf("failed exchange code: %s %s", resp.Error, resp.ErrorDescription)
+	}
+	if resp.IdToken == "" {
+		return "", errors.New("no id_token in the token response")
+	}
+	return resp.IdToken, nil
+}
+
+// Verify check the signature, issuer, audience and expiration of the id token,
+// and the nonce if it's not empty
+func Verify(ctx context.Context, idToken, nonce string) (jwt.MapClaims, error) {
+	p, err := getProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}))
+	_, err = parser.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid id token")
+	}
+	if !claims.VerifyIssuer(p.Issuer, true) {
+		return nil, errors.New("invalid issuer of id token")
+	}
+	if !claims.VerifyAudience(setting.GetStr(conf.SSOClientId), true) {
+		return nil, errors.New("invalid audience of id token")
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("no exp in id token")
+	}
+	if nonce != "" && claims["nonce"] != nonce {
+		return nil, errors.New("invalid nonce of id token")
+	}
+	return claims, nil
+}
+
+// GetUser get the effective user linked to the subject of the claims,
+// the user is created if not exists and auto register is enabled
+func GetUser(claims jwt.MapClaims) (*model.User, error) {
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, errors.New("no sub in id token")
+	}
+	user, err := db.GetUserBySsoId(sub)
+	if err == nil {
+		return db.GetUserByName(user.Username)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if !setting.GetBool(conf.SSOAutoRegister) {
+		return nil, errors.New("the user is not registered")
+	}
+	username, _ := claims[setting.GetStr(conf.SSOUsernameClaim, "preferred_username")].(string)
+	if username == "" {
+		return nil, errors.New("no username in id token")
+	}
+	if err := register(username, sub); err != nil {
+		return nil, err
+	}
+	return db.GetUserByName(username)
+}
+
+// register create the user linked to the subject, and join the default group
+func register(username, sub string) error {
+	if _, err := db.GetUserByName(username); err == nil {
+		return errors.Errorf("the username %s is taken", username)
+	}
+	user := &model.User{
+		Username: username,
+		Role:     model.GENERAL,
+		SsoID:    sub,
+	}
+	if err := db.CreateUser(user); err != nil {
+		return err
+	}
+	groupName := setting.GetStr(conf.SSODefaultGroup)
+	if groupName == "" {
+		return nil
+	}
+	group, err := db.GetGroupByName(groupName)
+	if err != nil {
+		return err
+	}
+	return db.SetUserGroups(user.ID, []uint{group.ID})
+}
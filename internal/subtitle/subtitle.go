@@ -0,0 +1,70 @@
+// Package subtitle find the external subtitles of the videos and convert them for the browsers
+package subtitle
+
+import (
+	"bytes"
+	stdpath "path"
+	"regexp"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// the formats of the subtitles, srt is converted to vtt for the browsers
+var exts = []string{"vtt", "srt", "ass", "ssa"}
+
+// MaxSize is the max size of the subtitles served
+const MaxSize = 10 * 1024 * 1024
+
+type Subtitle struct {
+	Obj model.Obj
+	// the language in the name, such as en of video.en.srt, empty if it isn't in the name
+	Language string
+}
+
+// Supported check if the file is a subtitle by the name
+func Supported(name string) bool {
+	return utils.SliceContains(exts, strings.ToLower(utils.Ext(name)))
+}
+
+// Find the subtitles of the video in the objects of the same dir, they are named
+// like video.srt or video.en.srt for the video.mkv
+func Find(objs []model.Obj, video string) []Subtitle {
+	base := strings.TrimSuffix(video, stdpath.Ext(video))
+	var subtitles []Subtitle
+	for _, obj := range objs {
+		name := obj.GetName()
+		if obj.IsDir() || !Supported(name) || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		lang := strings.TrimPrefix(strings.TrimSuffix(strings.TrimPrefix(name, base), stdpath.Ext(name)), ".")
+		subtitles = append(subtitles, Subtitle{Obj: obj, Language: lang})
+	}
+	return subtitles
+}
+
+var timingReg = regexp.MustCompile(`(\d{2}:\d{2}:\d{2}),(\d{3})`)
+
+// ToVTT convert the subtitle in srt to webvtt, the cues are kept and the commas
+// in the timings are replaced
+func ToVTT(srt []byte) []byte {
+	srt = bytes.TrimPrefix(srt, []byte("\xef\xbb\xbf"))
+	srt = bytes.ReplaceAll(srt, []byte("\r\n"), []byte("\n"))
+	lines := bytes.Split(srt, []byte("\n"))
+	for i, line := range lines {
+		if bytes.Contains(line, []byte("-->")) {
+			lines[i] = timingReg.ReplaceAll(line, []byte("$1.$2"))
+		}
+	}
+	return append([]byte("WEBVTT\n\n"), bytes.Join(lines, []byte("\n"))...)
+}
+
+// ContentType get the content type of the subtitle served, srt is served as vtt
+func ContentType(name string) string {
+	switch strings.ToLower(utils.Ext(name)) {
+	case "ass", "ssa":
+		return "text/x-ssa; charset=utf-8"
+	}
+	return "text/vtt; charset=utf-8"
+}
@@ -0,0 +1,32 @@
+package subtitle
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestToVTT(t *testing.T) {
+	srt := "\xef\xbb\xbf1\r\n00:00:01,000 --> 00:00:02,500\r\nHello, world\r\n\r\n2\r\n00:01:00,100 --> 00:01:02,000\r\nBye\r\n"
+	want := "WEBVTT\n\n1\n00:00:01.000 --> 00:00:02.500\nHello, world\n\n2\n00:01:00.100 --> 00:01:02.000\nBye\n"
+	if got := string(ToVTT([]byte(srt))); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFind(t *testing.T) {
+	var objs []model.Obj
+	for _, name := range []string{"movie.mkv", "movie.srt", "movie.en.ass", "movie2.srt", "movie.txt"} {
+		objs = append(objs, &model.Object{Name: name})
+	}
+	subtitles := Find(objs, "movie.mkv")
+	if len(subtitles) != 2 {
+		t.Fatalf("got %d subtitles, want 2", len(subtitles))
+	}
+	if subtitles[0].Obj.GetName() != "movie.srt" || subtitles[0].Language != "" {
+		t.Errorf("unexpected %s [%s]", subtitles[0].Obj.GetName(), subtitles[0].Language)
+	}
+	if subtitles[1].Obj.GetName() != "movie.en.ass" || subtitles[1].Language != "en" {
+		t.Errorf("unexpected %s [%s]", subtitles[1].Obj.GetName(), subtitles[1].Language)
+	}
+}
@@ -0,0 +1,222 @@
+// Package throttle limit the requests of the ips, such as the attempts of the passwords, the calls of
+// the api and the links got, the ips exceeding the limits are banned for a while
+package throttle
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// KindLogin is the failed attempts of the login and the passwords of the shares and the file drops
+	KindLogin = "login"
+	// KindApi is the calls of the api
+	KindApi = "api"
+	// KindLink is the links got and the downloads
+	KindLink = "link"
+)
+
+// the window the requests are counted in
+const window = time.Minute
+
+// ErrThrottled is returned if the ip is banned
+var ErrThrottled = errors.New("too many requests, try again later")
+
+type entry struct {
+	count       int
+	start       time.Time
+	bannedUntil time.Time
+}
+
+var (
+	mu      sync.Mutex
+	entries = make(map[string]*entry)
+	// the time the expired entries are removed last
+	pruned time.Time
+	// for the tests
+	now = time.Now
+)
+
+func key(kind, ip string) string {
+	return kind + "|" + ip
+}
+
+// policy get the limit of the kind and the duration of the bans by the settings, the limit is 0 if it's disabled
+var policy = func(kind string) (int, time.Duration) {
+	ban := time.Duration(setting.GetInt(conf.ThrottleBanDuration, 5)) * time.Minute
+	switch kind {
+	case KindLogin:
+		return setting.GetInt(conf.ThrottleLoginAttempts, 5), ban
+	case KindApi:
+		return setting.GetInt(conf.ThrottleApiRate, 0), ban
+	case KindLink:
+		return setting.GetInt(conf.ThrottleLinkRate, 0), ban
+	}
+	return 0, ban
+}
+
+// windowOf get the window the requests of the kind are counted in, the failed logins are counted in the ban duration
+func windowOf(kind string, ban time.Duration) time.Duration {
+	if kind == KindLogin {
+		return ban
+	}
+	return window
+}
+
+// whitelisted check if the ip is in the whitelist of the setting
+var whitelisted = func(ip string) bool {
+	return utils.IPInList(ip, setting.GetStr(conf.ThrottleWhitelist))
+}
+
+// prune remove the entries expired, it's run at most once a window, mu must be held
+func prune(t time.Time) {
+	if t.Sub(pruned) < window {
+		return
+	}
+	pruned = t
+	for k, e := range entries {
+		kind, _, _ := strings.Cut(k, "|")
+		_, ban := policy(kind)
+		if !t.Before(e.bannedUntil) && t.Sub(e.start) >= windowOf(kind, ban) {
+			delete(entries, k)
+		}
+	}
+}
+
+// get get the entry of the ip, the count is reset if the window is passed, mu must be held
+func get(kind, ip string, ban time.Duration, t time.Time) *entry {
+	prune(t)
+	e, ok := entries[key(kind, ip)]
+	if !ok {
+		e = &entry{start: t}
+		entries[key(kind, ip)] = e
+	}
+	if t.Sub(e.start) >= windowOf(kind, ban) && !t.Before(e.bannedUntil) {
+		e.count = 0
+		e.start = t
+	}
+	return e
+}
+
+// hit count a request of the ip, it's banned if the count exceeds the limit, mu must be held
+func hit(kind, ip string, e *entry, l int, ban time.Duration, t time.Time) {
+	e.count++
+	// the requests more than the limit are banned, and so are the failed logins reaching it
+	if e.count > l || (kind == KindLogin && e.count >= l) {
+		e.bannedUntil = t.Add(ban)
+		log.Warnf("the ip %s is banned for the %s until %s", ip, kind, e.bannedUntil.Format(time.RFC3339))
+	}
+}
+
+// Banned get the time left of the ban of the ip, it's 0 if the ip isn't banned
+func Banned(kind, ip string) time.Duration {
+	mu.Lock()
+	defer mu.Unlock()
+	e, ok := entries[key(kind, ip)]
+	if !ok {
+		return 0
+	}
+	if left := e.bannedUntil.Sub(now()); left > 0 {
+		return left
+	}
+	return 0
+}
+
+// Allow count a request of the ip, ErrThrottled is returned with the time left if the ip is banned
+func Allow(kind, ip string) (time.Duration, error) {
+	l, ban := policy(kind)
+	if l <= 0 || whitelisted(ip) {
+		return 0, nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	t := now()
+	e := get(kind, ip, ban, t)
+	if left := e.bannedUntil.Sub(t); left > 0 {
+		return left, ErrThrottled
+	}
+	hit(kind, ip, e, l, ban, t)
+	if left := e.bannedUntil.Sub(t); left > 0 {
+		return left, ErrThrottled
+	}
+	return 0, nil
+}
+
+// Fail count a failed attempt of the ip, it's banned if it fails too many times
+func Fail(kind, ip string) {
+	l, ban := policy(kind)
+	if l <= 0 || whitelisted(ip) {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	t := now()
+	e := get(kind, ip, ban, t)
+	if t.Before(e.bannedUntil) {
+		return
+	}
+	hit(kind, ip, e, l, ban, t)
+}
+
+// Reset clear the count of the ip, such as after it logs in successfully
+func Reset(kind, ip string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(entries, key(kind, ip))
+}
+
+// Client is the ip counted
+type Client struct {
+	IP          string     `json:"ip"`
+	Kind        string     `json:"kind"`
+	Count       int        `json:"count"`
+	Start       time.Time  `json:"start"`
+	BannedUntil *time.Time `json:"banned_until"`
+}
+
+// List list the ips throttled, the banned ones first
+func List() []Client {
+	mu.Lock()
+	defer mu.Unlock()
+	t := now()
+	prune(t)
+	res := make([]Client, 0, len(entries))
+	for k, e := range entries {
+		kind, ip, _ := strings.Cut(k, "|")
+		c := Client{IP: ip, Kind: kind, Count: e.count, Start: e.start}
+		if e.bannedUntil.After(t) {
+			until := e.bannedUntil
+			c.BannedUntil = &until
+		}
+		res = append(res, c)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if (res[i].BannedUntil != nil) != (res[j].BannedUntil != nil) {
+			return res[i].BannedUntil != nil
+		}
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+		return key(res[i].Kind, res[i].IP) < key(res[j].Kind, res[j].IP)
+	})
+	return res
+}
+
+// Unban remove the ban and the count of the ip, of all the kinds if kind is empty
+func Unban(ip, kind string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, k := range []string{KindLogin, KindApi, KindLink} {
+		if kind == "" || kind == k {
+			delete(entries, key(k, ip))
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+)
+
+func setup(t *testing.T) *time.Time {
+	cur := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return cur }
+	policy = func(kind string) (int, time.Duration) {
+		if kind == KindLogin {
+			return 3, 5 * time.Minute
+		}
+		return 2, 5 * time.Minute
+	}
+	whitelisted = func(ip string) bool { return ip == "10.0.0.1" }
+	entries = make(map[string]*entry)
+	pruned = time.Time{}
+	t.Cleanup(func() { now = time.Now })
+	return &cur
+}
+
+func TestAllow(t *testing.T) {
+	cur := setup(t)
+	for i := 0; i < 2; i++ {
+		if _, err := Allow(KindApi, "1.1.1.1"); err != nil {
+			t.Fatalf("the request %d is throttled: %v", i, err)
+		}
+	}
+	// the window is passed, the count is reset
+	*cur = cur.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		if _, err := Allow(KindApi, "1.1.1.1"); err != nil {
+			t.Fatalf("the request %d in the next window is throttled: %v", i, err)
+		}
+	}
+	left, err := Allow(KindApi, "1.1.1.1")
+	if err != ErrThrottled || left != 5*time.Minute {
+		t.Fatalf("the request exceeding the limit got %s, %v", left, err)
+	}
+	if _, err = Allow(KindLink, "1.1.1.1"); err != nil {
+		t.Errorf("the other kind is throttled: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err = Allow(KindApi, "10.0.0.1"); err != nil {
+			t.Fatalf("the ip in the whitelist is throttled: %v", err)
+		}
+	}
+	*cur = cur.Add(5 * time.Minute)
+	if _, err = Allow(KindApi, "1.1.1.1"); err != nil {
+		t.Errorf("the ban isn't lifted: %v", err)
+	}
+}
+
+func TestFail(t *testing.T) {
+	cur := setup(t)
+	Fail(KindLogin, "1.1.1.1")
+	Fail(KindLogin, "1.1.1.1")
+	if Banned(KindLogin, "1.1.1.1") > 0 {
+		t.Fatalf("banned before reaching the limit")
+	}
+	Fail(KindLogin, "1.1.1.1")
+	if Banned(KindLogin, "1.1.1.1") != 5*time.Minute {
+		t.Fatalf("not banned after reaching the limit")
+	}
+	clients := List()
+	if len(clients) != 1 || clients[0].BannedUntil == nil || clients[0].Count != 3 {
+		t.Fatalf("unexpected clients: %+v", clients)
+	}
+	Unban("1.1.1.1", "")
+	if Banned(KindLogin, "1.1.1.1") > 0 || len(List()) != 0 {
+		t.Fatalf("the ban isn't removed")
+	}
+	// the failures out of the window are forgotten
+	Fail(KindLogin, "2.2.2.2")
+	Fail(KindLogin, "2.2.2.2")
+	*cur = cur.Add(5 * time.Minute)
+	Fail(KindLogin, "2.2.2.2")
+	if Banned(KindLogin, "2.2.2.2") > 0 {
+		t.Errorf("banned by the failures out of the window")
+	}
+}
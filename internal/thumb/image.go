@@ -0,0 +1,46 @@
+package thumb
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"io"
+	"os"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/disintegration/imaging"
+	"github.com/pkg/errors"
+)
+
+func generateImage(ctx context.Context, path string, file model.Obj, dst string, s int) error {
+	maxSize := int64(setting.GetInt(conf.ThumbnailMaxImageSize, 20)) * 1024 * 1024
+	if file.GetSize() > maxSize {
+		return errors.WithStack(ErrNotSupported)
+	}
+	rc, err := fs.Open(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	img, err := imaging.Decode(io.LimitReader(rc, maxSize), imaging.AutoOrientation(true))
+	if err != nil {
+		return errors.Wrap(err, "failed to decode the image")
+	}
+	img = imaging.Fit(img, s, s, imaging.Lanczos)
+	// the transparent parts are white in jpeg
+	bounds := img.Bounds()
+	bg := imaging.New(bounds.Dx(), bounds.Dy(), color.White)
+	img = imaging.Overlay(bg, img, image.Pt(0, 0), 1)
+	out, err := os.Create(dst)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := imaging.Encode(out, img, imaging.JPEG, imaging.JPEGQuality(80)); err != nil {
+		_ = out.Close()
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(out.Close())
+}
@@ -0,0 +1,106 @@
+package thumb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// the max size of the thumbnails fetched from the providers
+const maxProviderSize = 10 * 1024 * 1024
+
+// providerThumb get the thumbnail of the provider of the file, empty if it hasn't
+func providerThumb(file model.Obj) string {
+	if t, ok := file.(model.Thumb); ok {
+		return t.Thumb()
+	}
+	return ""
+}
+
+// ProxyProvider check if the thumbnails of the provider are proxied for the storage
+func ProxyProvider(storage driver.Driver) bool {
+	return storage != nil && storage.GetStorage().ThumbProxy
+}
+
+// getProvider get the path of the cached thumbnail of the provider, it's fetched if not cached.
+// the urls of the thumbnails expire for some providers, the file is got again with
+// the refreshed list to re-sign the url if it's rejected
+func getProvider(ctx context.Context, storage driver.Driver, actualPath, path string, file model.Obj) (string, error) {
+	// the thumbnails of the provider are kept as they are, the type is detected when served
+	name := utils.GetSHA1Encode(fmt.Sprintf("provider|%s|%d|%d", path, file.GetSize(), file.ModTime().Unix()))
+	if p, ok := c.Get(name); ok {
+		return p, nil
+	}
+	p, err, _ := g.Do(name, func() (string, error) {
+		if p, ok := c.Get(name); ok {
+			return p, nil
+		}
+		tmp := c.TmpPath(name)
+		err := fetch(ctx, providerThumb(file), tmp)
+		if errors.Is(err, errExpired) {
+			if _, err = op.List(ctx, storage, stdpath.Dir(actualPath), model.ListArgs{}, true); err == nil {
+				file, err = op.Get(ctx, storage, actualPath)
+			}
+			if err == nil {
+				err = fetch(ctx, providerThumb(file), tmp)
+			}
+		}
+		if err != nil {
+			_ = os.Remove(tmp)
+			return "", errors.WithMessagef(err, "failed to fetch the thumbnail of [%s]", path)
+		}
+		info, err := os.Stat(tmp)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		if err := c.Add(name, info.Size(), capacity()); err != nil {
+			return "", errors.WithStack(err)
+		}
+		return c.Path(name), nil
+	})
+	return p, err
+}
+
+var errExpired = errors.New("the url of the thumbnail is expired")
+
+func fetch(ctx context.Context, url, dst string) error {
+	if url == "" {
+		return errors.WithStack(ErrNotSupported)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req.Header.Set("User-Agent", base.UserAgent)
+	res, err := base.HttpClient.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusGone:
+		return errors.WithStack(errExpired)
+	default:
+		return errors.Errorf("failed to fetch the thumbnail: %s", res.Status)
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = io.Copy(out, io.LimitReader(res.Body, maxProviderSize))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return errors.WithStack(err)
+}
@@ -0,0 +1,125 @@
+// Package thumb generate the thumbnails of the images and the videos lazily for the files
+// without the ones of the provider, or proxy the ones of the provider if it's enabled for the storage. the images are decoded natively and the poster frames of
+// the videos are taken by ffmpeg if it's available. the thumbnails are cached in the thumb dir
+package thumb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/ffmpeg"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/disklru"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+var ErrNotSupported = errors.New("the thumbnail of the file can't be generated")
+
+// the images decoded natively
+var imageExts = []string{"jpg", "jpeg", "png", "gif", "bmp", "tif", "tiff"}
+
+// the count of the thumbnails generated concurrently, decoding the images and running
+// ffmpeg take much memory and cpu
+var sem = make(chan struct{}, 2)
+
+var g singleflight.Group[string]
+
+// c keep the thumbnails in the thumb dir, the least recently used ones are removed
+// when the size of them is over the capacity
+var c = disklru.New(func() string { return conf.Conf.ThumbDir })
+
+// Supported check if the thumbnail of the file can be generated by the name
+func Supported(name string) bool {
+	if !setting.GetBool(conf.ThumbnailEnabled) {
+		return false
+	}
+	switch utils.GetFileType(name) {
+	case conf.IMAGE:
+		return utils.SliceContains(imageExts, strings.ToLower(utils.Ext(name)))
+	case conf.VIDEO:
+		return ffmpeg.Path() != ""
+	}
+	return false
+}
+
+func size() int {
+	if s := setting.GetInt(conf.ThumbnailSize, 256); s > 0 {
+		return s
+	}
+	return 256
+}
+
+func capacity() int64 {
+	return int64(setting.GetInt(conf.ThumbnailCacheSize, 256)) * 1024 * 1024
+}
+
+// Get the path of the thumbnail of the file, it's generated if not cached.
+// the thumbnail of the provider is fetched instead if it's proxied for the storage
+func Get(ctx context.Context, path string) (string, error) {
+	file, err := fs.Get(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if file.IsDir() {
+		return "", errors.WithStack(errs.NotFile)
+	}
+	if providerThumb(file) != "" {
+		storage, actualPath, err := op.GetStorageAndActualPath(path)
+		if err == nil && ProxyProvider(storage) {
+			return getProvider(ctx, storage, actualPath, path, file)
+		}
+	}
+	if !Supported(path) {
+		return "", errors.WithStack(ErrNotSupported)
+	}
+	s := size()
+	// the changed files and the other sizes have new thumbnails
+	name := utils.GetSHA1Encode(fmt.Sprintf("%s|%d|%d|%d", path, file.GetSize(), file.ModTime().Unix(), s)) + ".jpg"
+	if p, ok := c.Get(name); ok {
+		return p, nil
+	}
+	p, err, _ := g.Do(name, func() (string, error) {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		defer func() { <-sem }()
+		return generate(ctx, path, file, name, s)
+	})
+	return p, err
+}
+
+func generate(ctx context.Context, path string, file model.Obj, name string, s int) (string, error) {
+	if p, ok := c.Get(name); ok {
+		return p, nil
+	}
+	tmp := c.TmpPath(name)
+	var err error
+	if utils.GetFileType(file.GetName()) == conf.VIDEO {
+		err = generateVideo(ctx, path, tmp, s)
+	} else {
+		err = generateImage(ctx, path, file, tmp, s)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return "", errors.WithMessagef(err, "failed to generate the thumbnail of [%s]", path)
+	}
+	info, err := os.Stat(tmp)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if err := c.Add(name, info.Size(), capacity()); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return c.Path(name), nil
+}
@@ -0,0 +1,61 @@
+package thumb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/ffmpeg"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// the max time to take a frame
+const ffmpegTimeout = time.Minute
+
+// generateVideo take the frame at 3s as the poster by ffmpeg, the first frame is taken
+// if the video is shorter
+func generateVideo(ctx context.Context, path, dst string, s int) error {
+	var err error
+	for _, at := range []string{"3", "0"} {
+		if err = takeFrame(ctx, path, dst, s, at); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func takeFrame(ctx context.Context, path, dst string, s int, at string) error {
+	link, _, err := fs.Link(ctx, path, model.LinkArgs{})
+	if err != nil {
+		return err
+	}
+	if link.Data != nil {
+		defer link.Data.Close()
+	}
+	input, stdin, err := ffmpeg.Input(link)
+	if err != nil {
+		return err
+	}
+	args := append([]string{"-hide_banner", "-loglevel", "error", "-y", "-ss", at}, input...)
+	args = append(args,
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease", s, s),
+		"-f", "image2", "-c:v", "mjpeg", dst)
+	ctx, cancel := context.WithTimeout(ctx, ffmpegTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, ffmpeg.Path(), args...)
+	cmd.Stdin = stdin
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "failed to run ffmpeg: %s", strings.TrimSpace(string(out)))
+	}
+	// no frame at the time
+	if info, err := os.Stat(dst); err != nil || info.Size() == 0 {
+		return errors.Errorf("no frame at %ss", at)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package transcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/pkg/errors"
+)
+
+type Preset struct {
+	// the height of the video scaled to, 0 keeps the original one
+	Height       int    `json:"height"`
+	VideoBitrate string `json:"video_bitrate"`
+	AudioBitrate string `json:"audio_bitrate"`
+}
+
+// Presets get the presets by the name
+func Presets() (map[string]Preset, error) {
+	var presets map[string]Preset
+	if err := json.Unmarshal([]byte(setting.GetStr(conf.TranscodePresets)), &presets); err != nil {
+		return nil, errors.Wrap(err, "invalid transcode presets")
+	}
+	if len(presets) == 0 {
+		return nil, errors.New("no transcode presets")
+	}
+	return presets, nil
+}
+
+// getPreset get the preset by the name, the first one by the name if it's empty
+func getPreset(name string) (string, Preset, error) {
+	presets, err := Presets()
+	if err != nil {
+		return "", Preset{}, err
+	}
+	if name == "" {
+		names := make([]string, 0, len(presets))
+		for n := range presets {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		name = names[0]
+	}
+	preset, ok := presets[name]
+	if !ok {
+		return "", Preset{}, errors.Errorf("the transcode preset [%s] doesn't exist", name)
+	}
+	return name, preset, nil
+}
+
+// the arguments of ffmpeg for the hardware, the frames decoded are in the memory
+// except vaapi, so they can be scaled by the software
+type hwaccel struct {
+	input   []string
+	encoder []string
+	// the filter after scaling to upload the frames
+	upload string
+}
+
+var hwaccels = map[string]hwaccel{
+	"none":         {encoder: []string{"-c:v", "libx264", "-preset", "veryfast"}},
+	"nvenc":        {input: []string{"-hwaccel", "cuda"}, encoder: []string{"-c:v", "h264_nvenc", "-preset", "p4"}},
+	"qsv":          {input: []string{"-hwaccel", "qsv"}, encoder: []string{"-c:v", "h264_qsv"}},
+	"vaapi":        {input: []string{"-vaapi_device", "/dev/dri/renderD128"}, encoder: []string{"-c:v", "h264_vaapi"}, upload: "format=nv12,hwupload"},
+	"videotoolbox": {input: []string{"-hwaccel", "videotoolbox"}, encoder: []string{"-c:v", "h264_videotoolbox"}},
+}
+
+// segmentTime is the duration in seconds of the segments of hls
+const segmentTime = 4
+
+// args get the arguments of ffmpeg to transcode the input to hls in the dir
+func (p Preset) args(input []string, dir string) []string {
+	hw, ok := hwaccels[setting.GetStr(conf.TranscodeHwaccel)]
+	if !ok {
+		hw = hwaccels["none"]
+	}
+	args := append([]string{"-hide_banner", "-loglevel", "error"}, hw.input...)
+	args = append(args, input...)
+	args = append(args, "-map", "0:v:0", "-map", "0:a:0?", "-sn")
+	var filters []string
+	if p.Height > 0 {
+		filters = append(filters, fmt.Sprintf("scale=-2:'min(%d,ih)'", p.Height))
+	}
+	if hw.upload != "" {
+		filters = append(filters, hw.upload)
+	}
+	if len(filters) > 0 {
+		args = append(args, "-vf", strings.Join(filters, ","))
+	}
+	args = append(args, hw.encoder...)
+	if p.VideoBitrate != "" {
+		args = append(args, "-b:v", p.VideoBitrate, "-maxrate", p.VideoBitrate, "-bufsize", p.VideoBitrate)
+	}
+	args = append(args, "-force_key_frames", "expr:gte(t,n_forced*"+strconv.Itoa(segmentTime)+")",
+		"-c:a", "aac", "-ac", "2")
+	if p.AudioBitrate != "" {
+		args = append(args, "-b:a", p.AudioBitrate)
+	}
+	return append(args,
+		"-f", "hls",
+		"-hls_time", strconv.Itoa(segmentTime),
+		"-hls_list_size", "0",
+		"-hls_playlist_type", "event",
+		"-hls_flags", "temp_file+independent_segments",
+		"-hls_segment_filename", filepath.Join(dir, "%d.ts"),
+		filepath.Join(dir, playlist))
+}
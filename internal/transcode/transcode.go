@@ -0,0 +1,270 @@
+// Package transcode transcode the videos the browsers can't play to hls by ffmpeg.
+// a session is started for the video and the preset, and it's shared by the players
+// until it isn't played for a while, then ffmpeg is stopped and the segments are removed
+package transcode
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/ffmpeg"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ErrDisabled        = errors.New("transcoding is disabled or ffmpeg isn't available")
+	ErrTooManySessions = errors.New("too many videos are transcoding")
+	ErrSessionNotFound = errors.New("the transcoding session doesn't exist or is expired")
+)
+
+const playlist = "index.m3u8"
+
+// the files of the session can be got
+var fileReg = regexp.MustCompile(`^(index\.m3u8|\d+\.ts)$`)
+
+// the max time to wait for the file to be written by ffmpeg
+const waitTimeout = 30 * time.Second
+
+type session struct {
+	id     string
+	key    string
+	dir    string
+	cancel context.CancelFunc
+	// closed when ffmpeg exited
+	done chan struct{}
+	err  error
+
+	mu         sync.Mutex
+	lastAccess time.Time
+}
+
+func (s *session) touch() {
+	s.mu.Lock()
+	s.lastAccess = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *session) idle() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Since(s.lastAccess)
+}
+
+func (s *session) exited() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *session) stop() {
+	s.cancel()
+	<-s.done
+	if err := os.RemoveAll(s.dir); err != nil {
+		log.Warnf("failed to remove the transcoding dir: %+v", err)
+	}
+}
+
+var (
+	mu sync.Mutex
+	// the sessions by the id, and by the key of the video and the preset
+	sessions = map[string]*session{}
+	keys     = map[string]*session{}
+	once     sync.Once
+)
+
+func Enabled() bool {
+	return setting.GetBool(conf.TranscodeEnabled) && ffmpeg.Path() != ""
+}
+
+func idleTimeout() time.Duration {
+	if m := setting.GetInt(conf.TranscodeIdleTimeout, 5); m > 0 {
+		return time.Duration(m) * time.Minute
+	}
+	return 5 * time.Minute
+}
+
+// Start the session transcoding the video with the preset, the session started before
+// is returned if it's still running. the preset is the first one if it's empty
+func Start(ctx context.Context, path, presetName string) (string, error) {
+	if !Enabled() {
+		return "", errors.WithStack(ErrDisabled)
+	}
+	name, preset, err := getPreset(presetName)
+	if err != nil {
+		return "", err
+	}
+	file, err := fs.Get(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if file.IsDir() {
+		return "", errors.WithStack(errs.NotFile)
+	}
+	once.Do(func() { go clean() })
+	key := fmt.Sprintf("%s|%d|%d|%s", path, file.GetSize(), file.ModTime().Unix(), name)
+	mu.Lock()
+	defer mu.Unlock()
+	if s, ok := keys[key]; ok && (!s.exited() || s.err == nil) {
+		s.touch()
+		return s.id, nil
+	}
+	// the finished sessions only keep the segments
+	running := 0
+	for _, s := range sessions {
+		if !s.exited() {
+			running++
+		}
+	}
+	if running >= setting.GetInt(conf.TranscodeMaxSessions, 2) {
+		return "", errors.WithStack(ErrTooManySessions)
+	}
+	s, err := start(ctx, path, key, preset)
+	if err != nil {
+		return "", err
+	}
+	if old, ok := keys[key]; ok {
+		delete(sessions, old.id)
+		go old.stop()
+	}
+	sessions[s.id] = s
+	keys[key] = s
+	return s.id, nil
+}
+
+func start(ctx context.Context, path, key string, preset Preset) (*session, error) {
+	// the session lasts after the request starting it
+	sctx, cancel := context.WithCancel(utils.Detach(ctx))
+	link, _, err := fs.Link(sctx, path, model.LinkArgs{})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	input, stdin, err := ffmpeg.Input(link)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	s := &session{
+		id:         random.SecureString(32),
+		key:        key,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+		lastAccess: time.Now(),
+	}
+	s.dir = filepath.Join(conf.Conf.TempDir, "transcode", s.id)
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		cancel()
+		return nil, errors.WithStack(err)
+	}
+	cmd := exec.CommandContext(sctx, ffmpeg.Path(), preset.args(input, s.dir)...)
+	cmd.Stdin = stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		cancel()
+		_ = os.RemoveAll(s.dir)
+		return nil, errors.Wrap(err, "failed to start ffmpeg")
+	}
+	go func() {
+		err := cmd.Wait()
+		if c, ok := stdin.(io.Closer); ok {
+			_ = c.Close()
+		}
+		if err != nil && sctx.Err() == nil {
+			s.err = errors.Wrapf(err, "failed to transcode: %s", strings.TrimSpace(stderr.String()))
+			log.Errorf("failed to transcode [%s]: %+v", path, s.err)
+		}
+		close(s.done)
+	}()
+	return s, nil
+}
+
+// File get the path of the file of the session, it waits for the file to be written
+func File(ctx context.Context, id, name string) (string, error) {
+	if !fileReg.MatchString(name) {
+		return "", errors.WithStack(errs.ObjectNotFound)
+	}
+	mu.Lock()
+	s, ok := sessions[id]
+	mu.Unlock()
+	if !ok {
+		return "", errors.WithStack(ErrSessionNotFound)
+	}
+	s.touch()
+	p := filepath.Join(s.dir, name)
+	ctx, cancel := context.WithTimeout(ctx, waitTimeout)
+	defer cancel()
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		// the files are renamed after written
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+		if s.exited() {
+			if s.err != nil {
+				return "", s.err
+			}
+			return "", errors.WithStack(errs.ObjectNotFound)
+		}
+		select {
+		case <-ctx.Done():
+			return "", errors.Errorf("timeout to wait for [%s] transcoded", name)
+		case <-ticker.C:
+		}
+	}
+}
+
+// clean stop the sessions not played for the idle timeout
+func clean() {
+	for range time.Tick(time.Minute) {
+		timeout := idleTimeout()
+		var idle []*session
+		mu.Lock()
+		for id, s := range sessions {
+			if s.idle() > timeout {
+				delete(sessions, id)
+				if keys[s.key] == s {
+					delete(keys, s.key)
+				}
+				idle = append(idle, s)
+			}
+		}
+		mu.Unlock()
+		for _, s := range idle {
+			s.stop()
+		}
+	}
+}
+
+// StopAll stop all the sessions, it's called when alist exits
+func StopAll() {
+	mu.Lock()
+	all := sessions
+	sessions = map[string]*session{}
+	keys = map[string]*session{}
+	mu.Unlock()
+	for _, s := range all {
+		s.stop()
+	}
+}
@@ -0,0 +1,42 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	// 2022-09-01 is thursday
+	now := time.Date(2022, 9, 1, 10, 30, 20, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2022, 9, 1, 10, 31, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2022, 9, 2, 3, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2022, 9, 1, 10, 45, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2022, 9, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2022, 9, 4, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 9-17/4 1,15 * *", time.Date(2022, 9, 1, 13, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("failed parse %s: %+v", tt.spec, err)
+		}
+		if got := s.Next(now); !got.Equal(tt.want) {
+			t.Errorf("next of %s is %s, but expected %s", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expected error of parsing %q", spec)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Package disklru keep the files in a dir up to a size, the least recently used ones
+// are removed when the size of them is over the capacity
+package disklru
+
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const tmpSuffix = ".tmp"
+
+type entry struct {
+	name string
+	size int64
+}
+
+// Store is the files in the dir, they are written to TmpPath first, then added by Add
+type Store struct {
+	once sync.Once
+	// get the dir when the store is used first, since it's set by the config
+	dirFn func() string
+	mu    sync.Mutex
+	dir   string
+	// the front is the most recently used
+	lru     *list.List
+	entries map[string]*list.Element
+	used    int64
+	// the files being written
+	filling map[string]struct{}
+}
+
+func New(dir func() string) *Store {
+	return &Store{
+		dirFn:   dir,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+		filling: make(map[string]struct{}),
+	}
+}
+
+// load the files kept before, the unfinished files are removed
+func (s *Store) load() {
+	s.dir = s.dirFn()
+	if err := os.MkdirAll(s.dir, 0777); err != nil {
+		log.Errorf("failed to create the dir [%s]: %+v", s.dir, err)
+		return
+	}
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		log.Errorf("failed to read the dir [%s]: %+v", s.dir, err)
+		return
+	}
+	var infos []os.FileInfo
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(f.Name(), tmpSuffix) {
+			_ = os.Remove(filepath.Join(s.dir, f.Name()))
+			continue
+		}
+		if info, err := f.Info(); err == nil {
+			infos = append(infos, info)
+		}
+	}
+	// the order of use is lost, the recently written ones are kept longer
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime().After(infos[j].ModTime())
+	})
+	for _, info := range infos {
+		s.entries[info.Name()] = s.lru.PushBack(&entry{name: info.Name(), size: info.Size()})
+		s.used += info.Size()
+	}
+}
+
+// Path get the path of the file in the store
+func (s *Store) Path(name string) string {
+	s.once.Do(s.load)
+	return filepath.Join(s.dir, name)
+}
+
+// TmpPath get the path the file is written to before it's added
+func (s *Store) TmpPath(name string) string {
+	return s.Path(name) + tmpSuffix
+}
+
+// Get get the path of the file, it's marked as the most recently used
+func (s *Store) Get(name string) (string, bool) {
+	s.once.Do(s.load)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[name]
+	if !ok {
+		return "", false
+	}
+	if _, err := os.Stat(s.Path(name)); err != nil {
+		s.remove(elem)
+		return "", false
+	}
+	s.lru.MoveToFront(elem)
+	return s.Path(name), true
+}
+
+// Open open the file and get its size, it's marked as the most recently used
+func (s *Store) Open(name string) (*os.File, int64, bool) {
+	s.once.Do(s.load)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[name]
+	if !ok {
+		return nil, 0, false
+	}
+	f, err := os.Open(s.Path(name))
+	if err != nil {
+		s.remove(elem)
+		return nil, 0, false
+	}
+	s.lru.MoveToFront(elem)
+	return f, elem.Value.(*entry).size, true
+}
+
+// StartFill mark the file as being written, return false if it's kept or being written
+func (s *Store) StartFill(name string) bool {
+	s.once.Do(s.load)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[name]; ok {
+		return false
+	}
+	if _, ok := s.filling[name]; ok {
+		return false
+	}
+	s.filling[name] = struct{}{}
+	return true
+}
+
+// Cancel give up the file being written, the tmp file is removed
+func (s *Store) Cancel(name string) {
+	s.once.Do(s.load)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.filling, name)
+	_ = os.Remove(s.TmpPath(name))
+}
+
+// Add add the file written to the tmp path, then remove the least recently used
+// files until the size of the store is under `capacity`
+func (s *Store) Add(name string, size, capacity int64) error {
+	s.once.Do(s.load)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.filling, name)
+	if err := os.Rename(s.TmpPath(name), s.Path(name)); err != nil {
+		_ = os.Remove(s.TmpPath(name))
+		return err
+	}
+	if elem, ok := s.entries[name]; ok {
+		s.used -= elem.Value.(*entry).size
+		s.lru.Remove(elem)
+	}
+	s.entries[name] = s.lru.PushFront(&entry{name: name, size: size})
+	s.used += size
+	for s.used > capacity && s.lru.Len() > 1 {
+		s.remove(s.lru.Back())
+	}
+	return nil
+}
+
+func (s *Store) remove(elem *list.Element) {
+	e := elem.Value.(*entry)
+	s.lru.Remove(elem)
+	delete(s.entries, e.name)
+	s.used -= e.size
+	// the opened file can still be read on unix
+	if err := os.Remove(s.Path(e.name)); err != nil && !os.IsNotExist(err) {
+		log.Warnf("failed to remove [%s]: %+v", e.name, err)
+	}
+}
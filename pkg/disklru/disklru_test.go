@@ -0,0 +1,46 @@
+package disklru
+
+import (
+	"os"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	s := New(func() string { return dir })
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.WriteFile(s.TmpPath(name), make([]byte, 10), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if name == "c" {
+			// a is used recently, so b is removed
+			if _, ok := s.Get("a"); !ok {
+				t.Fatal("a isn't kept")
+			}
+		}
+		if err := s.Add(name, 10, 20); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := s.Get(name); ok != want {
+			t.Errorf("kept %s: %v, want %v", name, ok, want)
+		}
+		if _, err := os.Stat(s.Path(name)); (err == nil) != want {
+			t.Errorf("the file of %s exists: %v, want %v", name, err == nil, want)
+		}
+	}
+	// an unfinished file left before restarting
+	if err := os.WriteFile(s.TmpPath("d"), make([]byte, 10), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// the files are loaded and the unfinished one is removed after restarting
+	s = New(func() string { return dir })
+	s.once.Do(s.load)
+	if s.used != 20 || len(s.entries) != 2 {
+		t.Errorf("loaded %d entries of %d bytes", len(s.entries), s.used)
+	}
+	if _, err := os.Stat(s.TmpPath("d")); !os.IsNotExist(err) {
+		t.Errorf("the tmp file isn't removed: %v", err)
+	}
+}
@@ -445,3 +445,24 @@ func (c *Client) WriteStream(path string, stream io.Reader, _ os.FileMode, callb
 		return newPathError("WriteStream", path, s)
 	}
 }
+
+// WriteStreamRange writes the `length` bytes of the stream at `offset` of the file of `size`
+// by the PUT with Content-Range, the servers such as apache and sabre/dav support it,
+// but the others may reject it or overwrite the whole file with the bytes
+func (c *Client) WriteStreamRange(path string, stream io.Reader, offset, length, size int64) (err error) {
+	s, err := c.put(path, stream, func(r *http.Request) {
+		r.ContentLength = length
+		r.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, size))
+	})
+	if err != nil {
+		return err
+	}
+
+	switch s {
+	case 200, 201, 204:
+		return nil
+
+	default:
+		return newPathError("WriteStreamRange", path, s)
+	}
+}
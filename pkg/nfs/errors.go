@@ -0,0 +1,72 @@
+package nfs
+
+import (
+	"fmt"
+	"os"
+)
+
+// the status of nfs v3 (rfc 1813) and the mount protocol
+const (
+	statusOK          = 0
+	statusPerm        = 1
+	statusNoEnt       = 2
+	statusIO          = 5
+	statusAcces       = 13
+	statusExist       = 17
+	statusNotDir      = 20
+	statusIsDir       = 21
+	statusInval       = 22
+	statusNoSpc       = 28
+	statusROFS        = 30
+	statusNameTooLong = 63
+	statusNotEmpty    = 66
+	statusDQuot       = 69
+	statusStale       = 70
+	statusNotSupp     = 10004
+)
+
+var statusText = map[uint32]string{
+	statusPerm:        "operation not permitted",
+	statusNoEnt:       "no such file or directory",
+	statusIO:          "i/o error",
+	statusAcces:       "permission denied",
+	statusExist:       "file exists",
+	statusNotDir:      "not a directory",
+	statusIsDir:       "is a directory",
+	statusInval:       "invalid argument",
+	statusNoSpc:       "no space left on device",
+	statusROFS:        "read-only file system",
+	statusNameTooLong: "file name too long",
+	statusNotEmpty:    "directory not empty",
+	statusDQuot:       "disk quota exceeded",
+	statusStale:       "stale file handle",
+	statusNotSupp:     "operation not supported",
+}
+
+// Error is the status the server replied besides OK
+type Error struct {
+	Op     string
+	Path   string
+	Status uint32
+}
+
+func (e *Error) Error() string {
+	text, ok := statusText[e.Status]
+	if !ok {
+		text = fmt.Sprintf("status %d", e.Status)
+	}
+	return fmt.Sprintf("nfs: %s %s: %s", e.Op, e.Path, text)
+}
+
+// Is let errors.Is(err, os.ErrNotExist) and so on work with the errors of nfs
+func (e *Error) Is(target error) bool {
+	switch target {
+	case os.ErrNotExist:
+		return e.Status == statusNoEnt
+	case os.ErrExist:
+		return e.Status == statusExist
+	case os.ErrPermission:
+		return e.Status == statusPerm || e.Status == statusAcces
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package nfs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	stdpath "path"
+)
+
+// the stabilities of WRITE
+const (
+	unstable = 0
+	fileSync = 2
+)
+
+// File is a file opened for reading or created for writing
+type File struct {
+	t      *Target
+	fh     []byte
+	path   string
+	size   int64
+	offset int64
+	// the verifier of the unstable writes, the data must be written again if it's changed by the restart of the server
+	verf    []byte
+	written bool
+}
+
+// Open open the file for reading
+func (t *Target) Open(path string) (*File, error) {
+	fh, attr, err := t.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	if attr.Type == typeDir {
+		return nil, &Error{Op: "OPEN", Path: path, Status: statusIsDir}
+	}
+	return &File{t: t, fh: fh, path: path, size: int64(attr.Size)}, nil
+}
+
+// Create create the file for writing, it's truncated if it exists
+func (t *Target) Create(path string, mode uint32) (*File, error) {
+	dir, name, err := t.lookupParent(path)
+	if err != nil {
+		return nil, err
+	}
+	var w xdrWriter
+	w.opaque(dir)
+	w.string(name)
+	// UNCHECKED, the file is truncated by the size of the attributes if it exists
+	w.uint32(0)
+	writeSattr(&w, mode, true)
+	r, err := t.call(procCreate, w.buf)
+	if err != nil {
+		return nil, err
+	}
+	fh, err := readCreated(r, "CREATE", path)
+	if err != nil {
+		return nil, err
+	}
+	if fh == nil {
+		if fh, _, err = t.lookupIn(dir, name, path); err != nil {
+			return nil, err
+		}
+	}
+	return &File{t: t, fh: fh, path: path}, nil
+}
+
+func (f *File) Name() string {
+	return stdpath.Base("/" + f.path)
+}
+
+// ReadAt read the file at the offset, io.EOF is returned if it reaches the end
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		count := len(p) - n
+		if count > int(f.t.rsize) {
+			count = int(f.t.rsize)
+		}
+		var w xdrWriter
+		w.opaque(f.fh)
+		w.uint64(uint64(off + int64(n)))
+		w.uint32(uint32(count))
+		r, err := f.t.call(procRead, w.buf)
+		if err != nil {
+			return n, err
+		}
+		if stat := r.uint32(); stat != statusOK {
+			return n, &Error{Op: "READ", Path: f.path, Status: stat}
+		}
+		readPostOpAttr(r)
+		r.uint32()
+		eof := r.bool()
+		data := r.opaque()
+		if r.err != nil {
+			return n, r.err
+		}
+		n += copy(p[n:], data)
+		if eof || len(data) == 0 {
+			return n, io.EOF
+		}
+	}
+	return n, nil
+}
+
+func (f *File) Read(p []byte) (int, error) {
+	if len(p) > int(f.t.rsize) {
+		p = p[:f.t.rsize]
+	}
+	n, err := f.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.size
+	default:
+		return 0, errors.New("nfs: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("nfs: negative position")
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// Write write the data unstably, it's committed by Close
+func (f *File) Write(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		count := len(p) - n
+		if count > int(f.t.wsize) {
+			count = int(f.t.wsize)
+		}
+		var w xdrWriter
+		w.opaque(f.fh)
+		w.uint64(uint64(f.offset))
+		w.uint32(uint32(count))
+		w.uint32(unstable)
+		w.opaque(p[n : n+count])
+		r, err := f.t.call(procWrite, w.buf)
+		if err != nil {
+			return n, err
+		}
+		if stat := r.uint32(); stat != statusOK {
+			return n, &Error{Op: "WRITE", Path: f.path, Status: stat}
+		}
+		readWcc(r)
+		written := r.uint32()
+		committed := r.uint32()
+		verf := r.fixed(8)
+		if r.err != nil {
+			return n, r.err
+		}
+		if written == 0 {
+			return n, io.ErrShortWrite
+		}
+		if err = f.checkVerf(verf); err != nil {
+			return n, err
+		}
+		f.written = f.written || committed != fileSync
+		n += int(written)
+		f.offset += int64(written)
+	}
+	return n, nil
+}
+
+var errRestarted = errors.New("nfs: the server is restarted, the data written may be lost")
+
+func (f *File) checkVerf(verf []byte) error {
+	if f.verf == nil {
+		f.verf = append([]byte(nil), verf...)
+		return nil
+	}
+	if !bytes.Equal(f.verf, verf) {
+		return errRestarted
+	}
+	return nil
+}
+
+// Close commit the data written to the disk of the server
+func (f *File) Close() error {
+	if !f.written {
+		return nil
+	}
+	f.written = false
+	var w xdrWriter
+	w.opaque(f.fh)
+	w.uint64(0)
+	w.uint32(0)
+	r, err := f.t.call(procCommit, w.buf)
+	if err != nil {
+		return err
+	}
+	if stat := r.uint32(); stat != statusOK {
+		return &Error{Op: "COMMIT", Path: f.path, Status: stat}
+	}
+	readWcc(r)
+	verf := r.fixed(8)
+	if r.err != nil {
+		return r.err
+	}
+	return f.checkVerf(verf)
+}
@@ -0,0 +1,89 @@
+package nfs
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	progPortmap = 100000
+	progMount   = 100005
+	progNFS     = 100003
+
+	portmapGetPort = 3
+
+	mountMnt  = 1
+	mountUmnt = 3
+
+	protoTCP = 6
+)
+
+// getPort get the port of the program by the portmapper of the host
+func getPort(host string, prog, vers uint32, timeout time.Duration) (int, error) {
+	c, err := dialRPC(net.JoinHostPort(host, "111"), nil, timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer c.close()
+	var w xdrWriter
+	w.uint32(prog)
+	w.uint32(vers)
+	w.uint32(protoTCP)
+	w.uint32(0)
+	r, err := c.call(progPortmap, 2, portmapGetPort, w.buf)
+	if err != nil {
+		return 0, err
+	}
+	port := r.uint32()
+	if r.err != nil {
+		return 0, r.err
+	}
+	if port == 0 {
+		return 0, fmt.Errorf("nfs: the program %d version %d isn't registered on %s", prog, vers, host)
+	}
+	return int(port), nil
+}
+
+// mount get the file handle of the root of the export by the mount protocol
+func mount(host, export string, port int, auth *Auth, timeout time.Duration) ([]byte, error) {
+	var err error
+	if port == 0 {
+		if port, err = getPort(host, progMount, 3, timeout); err != nil {
+			return nil, err
+		}
+	}
+	c, err := dialRPC(net.JoinHostPort(host, strconv.Itoa(port)), auth, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+	var w xdrWriter
+	w.string(export)
+	r, err := c.call(progMount, 3, mountMnt, w.buf)
+	if err != nil {
+		return nil, err
+	}
+	if stat := r.uint32(); stat != 0 {
+		return nil, &Error{Op: "MNT", Path: export, Status: stat}
+	}
+	fh := r.opaque()
+	if r.err != nil {
+		return nil, r.err
+	}
+	return fh, nil
+}
+
+// unmount tell the server the export isn't used any more, it only removes the record of the server
+func unmount(host, export string, port int, auth *Auth, timeout time.Duration) error {
+	c, err := dialRPC(net.JoinHostPort(host, strconv.Itoa(port)), auth, timeout)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	var w xdrWriter
+	w.string(export)
+	_, err = c.call(progMount, 3, mountUmnt, w.buf)
+	return err
+}
@@ -0,0 +1,607 @@
+// Package nfs is a client of nfs v3 (rfc 1813) over tcp in pure go, the exports are mounted
+// without the mount of the os, the credential is AUTH_UNIX
+package nfs
+
+import (
+	"errors"
+	"net"
+	"os"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the procedures of nfs v3
+const (
+	procGetAttr     = 1
+	procLookup      = 3
+	procRead        = 6
+	procWrite       = 7
+	procCreate      = 8
+	procMkdir       = 9
+	procRemove      = 12
+	procRmdir       = 13
+	procRename      = 14
+	procReadDir     = 16
+	procReadDirPlus = 17
+	procFSStat      = 18
+	procFSInfo      = 19
+	procCommit      = 21
+)
+
+// the types of the files
+const (
+	typeReg = 1
+	typeDir = 2
+	typeLnk = 5
+)
+
+// the default sizes of reading and writing if the server doesn't tell them by FSINFO
+const defaultIOSize = 64 * 1024
+
+// Options is the options of mounting the export
+type Options struct {
+	Auth *Auth
+	// the ports of the mount and nfs, they are got from the portmapper of the host if they are 0
+	MountPort int
+	NFSPort   int
+	// the timeout of a call
+	Timeout time.Duration
+}
+
+// Target is the export mounted
+type Target struct {
+	host   string
+	export string
+	opts   Options
+	rpc    *rpcClient
+	root   []byte
+	rsize  uint32
+	wsize  uint32
+}
+
+// Mount mount the export of the host, such as Mount("192.168.1.2", "/volume1/share", opts)
+func Mount(host, export string, opts Options) (*Target, error) {
+	var err error
+	if opts.Timeout <= 0 {
+		opts.Timeout = time.Minute
+	}
+	if opts.MountPort == 0 {
+		if opts.MountPort, err = getPort(host, progMount, 3, opts.Timeout); err != nil {
+			return nil, err
+		}
+	}
+	if opts.NFSPort == 0 {
+		if opts.NFSPort, err = getPort(host, progNFS, 3, opts.Timeout); err != nil {
+			// most of the servers listen on 2049, even if the portmapper doesn't register it
+			opts.NFSPort = 2049
+		}
+	}
+	root, err := mount(host, export, opts.MountPort, opts.Auth, opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	c, err := dialRPC(net.JoinHostPort(host, strconv.Itoa(opts.NFSPort)), opts.Auth, opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	t := &Target{host: host, export: export, opts: opts, rpc: c, root: root, rsize: defaultIOSize, wsize: defaultIOSize}
+	t.fsInfo()
+	return t, nil
+}
+
+// Close close the connection and unmount the export
+func (t *Target) Close() error {
+	err := t.rpc.close()
+	_ = unmount(t.host, t.export, t.opts.MountPort, t.opts.Auth, t.opts.Timeout)
+	return err
+}
+
+func (t *Target) call(proc uint32, args []byte) (*xdrReader, error) {
+	return t.rpc.call(progNFS, 3, proc, args)
+}
+
+// fsInfo get the preferred sizes of reading and writing of the server
+func (t *Target) fsInfo() {
+	var w xdrWriter
+	w.opaque(t.root)
+	r, err := t.call(procFSInfo, w.buf)
+	if err != nil || r.uint32() != statusOK {
+		return
+	}
+	readPostOpAttr(r)
+	rtmax, rtpref := r.uint32(), r.uint32()
+	r.uint32()
+	wtmax, wtpref := r.uint32(), r.uint32()
+	if r.err != nil {
+		return
+	}
+	t.rsize = ioSize(rtpref, rtmax)
+	t.wsize = ioSize(wtpref, wtmax)
+}
+
+func ioSize(pref, max uint32) uint32 {
+	size := pref
+	if size == 0 || (max > 0 && size > max) {
+		size = max
+	}
+	if size == 0 {
+		return defaultIOSize
+	}
+	// the replies must not exceed the records accepted
+	if size > 16<<20 {
+		size = 16 << 20
+	}
+	return size
+}
+
+// Attr is the attributes of a file
+type Attr struct {
+	Type   uint32
+	Mode   uint32
+	UID    uint32
+	GID    uint32
+	Size   uint64
+	FileID uint64
+	Mtime  time.Time
+}
+
+func readTime(r *xdrReader) time.Time {
+	sec, nsec := r.uint32(), r.uint32()
+	return time.Unix(int64(sec), int64(nsec))
+}
+
+func readAttr(r *xdrReader) *Attr {
+	a := &Attr{}
+	a.Type = r.uint32()
+	a.Mode = r.uint32()
+	// nlink
+	r.uint32()
+	a.UID = r.uint32()
+	a.GID = r.uint32()
+	a.Size = r.uint64()
+	// used, rdev and fsid
+	r.uint64()
+	r.uint64()
+	r.uint64()
+	a.FileID = r.uint64()
+	// atime
+	readTime(r)
+	a.Mtime = readTime(r)
+	// ctime
+	readTime(r)
+	return a
+}
+
+func readPostOpAttr(r *xdrReader) *Attr {
+	if !r.bool() {
+		return nil
+	}
+	return readAttr(r)
+}
+
+// readWcc skip the attributes of the dir before and after the operation
+func readWcc(r *xdrReader) {
+	if r.bool() {
+		// size, mtime and ctime
+		r.uint64()
+		r.uint64()
+		r.uint64()
+	}
+	readPostOpAttr(r)
+}
+
+// fileInfo implement os.FileInfo by the attributes
+type fileInfo struct {
+	name string
+	attr *Attr
+}
+
+func (f *fileInfo) Name() string {
+	return f.name
+}
+
+func (f *fileInfo) Size() int64 {
+	return int64(f.attr.Size)
+}
+
+func (f *fileInfo) Mode() os.FileMode {
+	mode := os.FileMode(f.attr.Mode & 0777)
+	switch f.attr.Type {
+	case typeDir:
+		mode |= os.ModeDir
+	case typeLnk:
+		mode |= os.ModeSymlink
+	}
+	return mode
+}
+
+func (f *fileInfo) ModTime() time.Time {
+	return f.attr.Mtime
+}
+
+func (f *fileInfo) IsDir() bool {
+	return f.attr.Type == typeDir
+}
+
+func (f *fileInfo) Sys() interface{} {
+	return f.attr
+}
+
+// split split the path relative to the export into the names
+func split(path string) []string {
+	path = strings.Trim(stdpath.Clean("/"+path), "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func (t *Target) lookupIn(dir []byte, name, path string) ([]byte, *Attr, error) {
+	var w xdrWriter
+	w.opaque(dir)
+	w.string(name)
+	r, err := t.call(procLookup, w.buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stat := r.uint32(); stat != statusOK {
+		return nil, nil, &Error{Op: "LOOKUP", Path: path, Status: stat}
+	}
+	fh := r.opaque()
+	attr := readPostOpAttr(r)
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	return fh, attr, nil
+}
+
+func (t *Target) getAttr(fh []byte, path string) (*Attr, error) {
+	var w xdrWriter
+	w.opaque(fh)
+	r, err := t.call(procGetAttr, w.buf)
+	if err != nil {
+		return nil, err
+	}
+	if stat := r.uint32(); stat != statusOK {
+		return nil, &Error{Op: "GETATTR", Path: path, Status: stat}
+	}
+	attr := readAttr(r)
+	return attr, r.err
+}
+
+// lookup get the file handle and the attributes of the path relative to the export
+func (t *Target) lookup(path string) ([]byte, *Attr, error) {
+	fh := t.root
+	var attr *Attr
+	var err error
+	for _, name := range split(path) {
+		if fh, attr, err = t.lookupIn(fh, name, path); err != nil {
+			return nil, nil, err
+		}
+	}
+	if attr == nil {
+		if attr, err = t.getAttr(fh, path); err != nil {
+			return nil, nil, err
+		}
+	}
+	return fh, attr, nil
+}
+
+// lookupParent get the file handle of the parent dir of the path and the name of it
+func (t *Target) lookupParent(path string) ([]byte, string, error) {
+	names := split(path)
+	if len(names) == 0 {
+		return nil, "", &Error{Op: "LOOKUP", Path: path, Status: statusInval}
+	}
+	dir, attr, err := t.lookup(strings.Join(names[:len(names)-1], "/"))
+	if err != nil {
+		return nil, "", err
+	}
+	if attr.Type != typeDir {
+		return nil, "", &Error{Op: "LOOKUP", Path: path, Status: statusNotDir}
+	}
+	return dir, names[len(names)-1], nil
+}
+
+// Stat get the info of the path relative to the export
+func (t *Target) Stat(path string) (os.FileInfo, error) {
+	_, attr, err := t.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	return &fileInfo{name: stdpath.Base("/" + path), attr: attr}, nil
+}
+
+// ReadDir list the dir, the entries "." and ".." are excluded
+func (t *Target) ReadDir(path string) ([]os.FileInfo, error) {
+	fh, attr, err := t.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	if attr.Type != typeDir {
+		return nil, &Error{Op: "READDIR", Path: path, Status: statusNotDir}
+	}
+	res, err := t.readDirPlus(fh, path)
+	if e, ok := err.(*Error); ok && e.Status == statusNotSupp {
+		return t.readDir(fh, path)
+	}
+	return res, err
+}
+
+func (t *Target) readDirPlus(fh []byte, path string) ([]os.FileInfo, error) {
+	var res []os.FileInfo
+	var cookie uint64
+	verf := make([]byte, 8)
+	for {
+		var w xdrWriter
+		w.opaque(fh)
+		w.uint64(cookie)
+		w.fixed(verf)
+		w.uint32(t.rsize)
+		w.uint32(t.rsize)
+		r, err := t.call(procReadDirPlus, w.buf)
+		if err != nil {
+			return nil, err
+		}
+		if stat := r.uint32(); stat != statusOK {
+			return nil, &Error{Op: "READDIRPLUS", Path: path, Status: stat}
+		}
+		readPostOpAttr(r)
+		verf = append([]byte(nil), r.fixed(8)...)
+		for r.bool() {
+			// fileid
+			r.uint64()
+			name := r.string()
+			cookie = r.uint64()
+			attr := readPostOpAttr(r)
+			var child []byte
+			if r.bool() {
+				child = r.opaque()
+			}
+			if r.err != nil || name == "." || name == ".." {
+				continue
+			}
+			if attr == nil {
+				if child == nil {
+					_, attr, err = t.lookupIn(fh, name, stdpath.Join(path, name))
+				} else {
+					attr, err = t.getAttr(child, stdpath.Join(path, name))
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+			res = append(res, &fileInfo{name: name, attr: attr})
+		}
+		eof := r.bool()
+		if r.err != nil {
+			return nil, r.err
+		}
+		if eof {
+			return res, nil
+		}
+	}
+}
+
+// readDir list the dir by READDIR for the servers not supporting READDIRPLUS,
+// the attributes are got by LOOKUP one by one
+func (t *Target) readDir(fh []byte, path string) ([]os.FileInfo, error) {
+	var names []string
+	var cookie uint64
+	verf := make([]byte, 8)
+	for {
+		var w xdrWriter
+		w.opaque(fh)
+		w.uint64(cookie)
+		w.fixed(verf)
+		w.uint32(t.rsize)
+		r, err := t.call(procReadDir, w.buf)
+		if err != nil {
+			return nil, err
+		}
+		if stat := r.uint32(); stat != statusOK {
+			return nil, &Error{Op: "READDIR", Path: path, Status: stat}
+		}
+		readPostOpAttr(r)
+		verf = append([]byte(nil), r.fixed(8)...)
+		for r.bool() {
+			r.uint64()
+			name := r.string()
+			cookie = r.uint64()
+			if name != "." && name != ".." {
+				names = append(names, name)
+			}
+		}
+		eof := r.bool()
+		if r.err != nil {
+			return nil, r.err
+		}
+		if eof {
+			break
+		}
+	}
+	res := make([]os.FileInfo, 0, len(names))
+	for _, name := range names {
+		_, attr, err := t.lookupIn(fh, name, stdpath.Join(path, name))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, &fileInfo{name: name, attr: attr})
+	}
+	return res, nil
+}
+
+// writeSattr write the attributes set of a new file or dir, the times are set to the time of the server
+func writeSattr(w *xdrWriter, mode uint32, truncate bool) {
+	w.bool(true)
+	w.uint32(mode)
+	// uid and gid
+	w.bool(false)
+	w.bool(false)
+	w.bool(truncate)
+	if truncate {
+		w.uint64(0)
+	}
+	// atime and mtime
+	w.uint32(1)
+	w.uint32(1)
+}
+
+// readCreated read the result of CREATE and MKDIR, the file handle of the new one is returned if it's in the result
+func readCreated(r *xdrReader, op, path string) ([]byte, error) {
+	if stat := r.uint32(); stat != statusOK {
+		return nil, &Error{Op: op, Path: path, Status: stat}
+	}
+	var fh []byte
+	if r.bool() {
+		fh = r.opaque()
+	}
+	readPostOpAttr(r)
+	readWcc(r)
+	return fh, r.err
+}
+
+// Mkdir create the dir, the parent of it must exist
+func (t *Target) Mkdir(path string, mode uint32) error {
+	dir, name, err := t.lookupParent(path)
+	if err != nil {
+		return err
+	}
+	var w xdrWriter
+	w.opaque(dir)
+	w.string(name)
+	writeSattr(&w, mode, false)
+	r, err := t.call(procMkdir, w.buf)
+	if err != nil {
+		return err
+	}
+	_, err = readCreated(r, "MKDIR", path)
+	return err
+}
+
+// MkdirAll create the dir and the parents of it not existing
+func (t *Target) MkdirAll(path string, mode uint32) error {
+	names := split(path)
+	for i := range names {
+		p := strings.Join(names[:i+1], "/")
+		_, attr, err := t.lookup(p)
+		if err == nil {
+			if attr.Type != typeDir {
+				return &Error{Op: "MKDIR", Path: p, Status: statusNotDir}
+			}
+			continue
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err = t.Mkdir(p, mode); err != nil && !errors.Is(err, os.ErrExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove remove the file or the empty dir
+func (t *Target) Remove(path string) error {
+	_, attr, err := t.lookup(path)
+	if err != nil {
+		return err
+	}
+	dir, name, err := t.lookupParent(path)
+	if err != nil {
+		return err
+	}
+	proc, op := uint32(procRemove), "REMOVE"
+	if attr.Type == typeDir {
+		proc, op = procRmdir, "RMDIR"
+	}
+	var w xdrWriter
+	w.opaque(dir)
+	w.string(name)
+	r, err := t.call(proc, w.buf)
+	if err != nil {
+		return err
+	}
+	if stat := r.uint32(); stat != statusOK {
+		return &Error{Op: op, Path: path, Status: stat}
+	}
+	return nil
+}
+
+// RemoveAll remove the path and the children of it
+func (t *Target) RemoveAll(path string) error {
+	info, err := t.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if info.IsDir() {
+		children, err := t.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err = t.RemoveAll(stdpath.Join(path, child.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return t.Remove(path)
+}
+
+// Rename move the file or the dir, the dst is replaced if it exists
+func (t *Target) Rename(oldPath, newPath string) error {
+	fromDir, fromName, err := t.lookupParent(oldPath)
+	if err != nil {
+		return err
+	}
+	toDir, toName, err := t.lookupParent(newPath)
+	if err != nil {
+		return err
+	}
+	var w xdrWriter
+	w.opaque(fromDir)
+	w.string(fromName)
+	w.opaque(toDir)
+	w.string(toName)
+	r, err := t.call(procRename, w.buf)
+	if err != nil {
+		return err
+	}
+	if stat := r.uint32(); stat != statusOK {
+		return &Error{Op: "RENAME", Path: oldPath, Status: stat}
+	}
+	return nil
+}
+
+// FSStat is the usage of the file system
+type FSStat struct {
+	TotalBytes uint64
+	FreeBytes  uint64
+	// the free bytes the user can use
+	AvailBytes uint64
+}
+
+// FSStat get the usage of the file system the path is in
+func (t *Target) FSStat(path string) (*FSStat, error) {
+	fh, _, err := t.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	var w xdrWriter
+	w.opaque(fh)
+	r, err := t.call(procFSStat, w.buf)
+	if err != nil {
+		return nil, err
+	}
+	if stat := r.uint32(); stat != statusOK {
+		return nil, &Error{Op: "FSSTAT", Path: path, Status: stat}
+	}
+	readPostOpAttr(r)
+	s := &FSStat{TotalBytes: r.uint64(), FreeBytes: r.uint64(), AvailBytes: r.uint64()}
+	return s, r.err
+}
@@ -0,0 +1,298 @@
+package nfs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	stdpath "path"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeServer is an in-memory server of the mount protocol and nfs v3, the file handles are the paths
+type fakeServer struct {
+	files map[string][]byte
+	dirs  map[string]bool
+}
+
+func (s *fakeServer) attr(w *xdrWriter, path string) {
+	typ, size := uint32(typeReg), uint64(len(s.files[path]))
+	if s.dirs[path] {
+		typ, size = typeDir, 4096
+	}
+	w.uint32(typ)
+	w.uint32(0644)
+	w.uint32(1)
+	w.uint32(0)
+	w.uint32(0)
+	w.uint64(size)
+	w.uint64(size)
+	w.uint64(0)
+	w.uint64(0)
+	w.uint64(uint64(len(path)))
+	for i := 0; i < 3; i++ {
+		w.uint32(1700000000)
+		w.uint32(0)
+	}
+}
+
+func (s *fakeServer) exists(path string) bool {
+	_, ok := s.files[path]
+	return ok || s.dirs[path]
+}
+
+func (s *fakeServer) handle(prog, proc uint32, r *xdrReader) []byte {
+	var w xdrWriter
+	if prog == progMount {
+		if proc == mountMnt {
+			w.uint32(statusOK)
+			w.opaque([]byte("/"))
+			w.uint32(0)
+		}
+		return w.buf
+	}
+	switch proc {
+	case procGetAttr:
+		fh := string(r.opaque())
+		w.uint32(statusOK)
+		s.attr(&w, fh)
+	case procLookup, procRemove, procRmdir:
+		p := stdpath.Join(string(r.opaque()), r.string())
+		if !s.exists(p) {
+			w.uint32(statusNoEnt)
+			w.bool(false)
+			break
+		}
+		w.uint32(statusOK)
+		if proc == procLookup {
+			w.opaque([]byte(p))
+			w.bool(true)
+			s.attr(&w, p)
+			w.bool(false)
+		} else {
+			delete(s.files, p)
+			delete(s.dirs, p)
+			w.bool(false)
+			w.bool(false)
+		}
+	case procReadDirPlus:
+		dir := string(r.opaque())
+		var names []string
+		for p := range s.files {
+			if stdpath.Dir(p) == dir {
+				names = append(names, stdpath.Base(p))
+			}
+		}
+		for p := range s.dirs {
+			if p != "/" && stdpath.Dir(p) == dir {
+				names = append(names, stdpath.Base(p))
+			}
+		}
+		sort.Strings(names)
+		w.uint32(statusOK)
+		w.bool(false)
+		w.fixed(make([]byte, 8))
+		for i, name := range append([]string{".", ".."}, names...) {
+			w.bool(true)
+			w.uint64(uint64(i))
+			w.string(name)
+			w.uint64(uint64(i + 1))
+			w.bool(false)
+			w.bool(false)
+		}
+		w.bool(false)
+		w.bool(true)
+	case procRead:
+		data := s.files[string(r.opaque())]
+		off, count := int(r.uint64()), int(r.uint32())
+		// the server may return less than the count
+		if count > 3 {
+			count = 3
+		}
+		if off > len(data) {
+			off = len(data)
+		}
+		end := off + count
+		if end > len(data) {
+			end = len(data)
+		}
+		w.uint32(statusOK)
+		w.bool(false)
+		w.uint32(uint32(end - off))
+		w.bool(end == len(data))
+		w.opaque(data[off:end])
+	case procWrite:
+		p := string(r.opaque())
+		off := int(r.uint64())
+		r.uint32()
+		r.uint32()
+		data := r.opaque()
+		buf := s.files[p]
+		if len(buf) < off+len(data) {
+			buf = append(buf, make([]byte, off+len(data)-len(buf))...)
+		}
+		copy(buf[off:], data)
+		s.files[p] = buf
+		w.uint32(statusOK)
+		w.bool(false)
+		w.bool(false)
+		w.uint32(uint32(len(data)))
+		w.uint32(unstable)
+		w.fixed([]byte("verifier"))
+	case procCommit:
+		w.uint32(statusOK)
+		w.bool(false)
+		w.bool(false)
+		w.fixed([]byte("verifier"))
+	case procCreate, procMkdir:
+		p := stdpath.Join(string(r.opaque()), r.string())
+		if proc == procCreate {
+			s.files[p] = nil
+		} else {
+			s.dirs[p] = true
+		}
+		w.uint32(statusOK)
+		w.bool(proc == procMkdir)
+		if proc == procMkdir {
+			w.opaque([]byte(p))
+		}
+		w.bool(false)
+		w.bool(false)
+		w.bool(false)
+	case procRename:
+		from := stdpath.Join(string(r.opaque()), r.string())
+		to := stdpath.Join(string(r.opaque()), r.string())
+		s.files[to] = s.files[from]
+		delete(s.files, from)
+		w.uint32(statusOK)
+	default:
+		w.uint32(statusNotSupp)
+		w.bool(false)
+	}
+	return w.buf
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	c := &rpcClient{conn: conn}
+	for {
+		data, err := c.readRecord()
+		if err != nil {
+			return
+		}
+		r := &xdrReader{buf: data}
+		xid := r.uint32()
+		r.uint32()
+		r.uint32()
+		prog, _, proc := r.uint32(), r.uint32(), r.uint32()
+		r.uint32()
+		r.opaque()
+		r.uint32()
+		r.opaque()
+		res := s.handle(prog, proc, r)
+		var w xdrWriter
+		w.uint32(0)
+		w.uint32(xid)
+		w.uint32(msgReply)
+		w.uint32(replyAccepted)
+		w.uint32(authNone)
+		w.opaque(nil)
+		w.uint32(0)
+		w.buf = append(w.buf, res...)
+		binary.BigEndian.PutUint32(w.buf, uint32(len(w.buf)-4)|1<<31)
+		if _, err = conn.Write(w.buf); err != nil {
+			return
+		}
+	}
+}
+
+func mountFake(t *testing.T) (*Target, *fakeServer) {
+	s := &fakeServer{files: map[string][]byte{"/a.txt": []byte("hello world")}, dirs: map[string]bool{"/": true, "/dir": true}}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	port := l.Addr().(*net.TCPAddr).Port
+	target, err := Mount("127.0.0.1", "/export", Options{
+		Auth:      &Auth{MachineName: "test"},
+		MountPort: port,
+		NFSPort:   port,
+		Timeout:   5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = target.Close() })
+	return target, s
+}
+
+func TestReadDir(t *testing.T) {
+	target, _ := mountFake(t)
+	files, err := target.ReadDir("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	if strings.Join(names, ",") != "a.txt,dir" || files[0].Size() != 11 || !files[1].IsDir() {
+		t.Errorf("unexpected entries: %v", names)
+	}
+	if _, err = target.Stat("/dir/none"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected not exist, got %v", err)
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	target, s := mountFake(t)
+	f, err := target.Open("/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Seek(6, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil || string(data) != "world" {
+		t.Fatalf("read %q, %v", data, err)
+	}
+	w, err := target.Create("/dir/b.txt", 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	if _, err = io.Copy(w, bytes.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s.files["/dir/b.txt"], content) {
+		t.Errorf("the content written is wrong")
+	}
+	if err = target.MkdirAll("/dir/x/y", 0755); err != nil || !s.dirs["/dir/x/y"] {
+		t.Errorf("failed mkdir: %v", err)
+	}
+	if err = target.Rename("/dir/b.txt", "/c.txt"); err != nil || s.files["/c.txt"] == nil {
+		t.Errorf("failed rename: %v", err)
+	}
+	if err = target.RemoveAll("/dir"); err != nil || s.exists("/dir") || s.exists("/dir/x") {
+		t.Errorf("failed remove: %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package nfs
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// the onc rpc (rfc 5531) over tcp, the calls are sent one by one on the connection
+
+const (
+	msgCall  = 0
+	msgReply = 1
+
+	replyAccepted = 0
+	replyDenied   = 1
+
+	authNone = 0
+	authUnix = 1
+
+	// the largest record accepted, the replies of READ are at most the rtmax of the server
+	maxRecord = 64 << 20
+)
+
+// Auth is the credential of AUTH_UNIX the calls are sent with, the server checks the permissions by it
+type Auth struct {
+	MachineName string
+	UID         uint32
+	GID         uint32
+}
+
+func (a *Auth) encode(w *xdrWriter) {
+	if a == nil {
+		w.uint32(authNone)
+		w.opaque(nil)
+		return
+	}
+	var body xdrWriter
+	body.uint32(uint32(time.Now().Unix()))
+	body.string(a.MachineName)
+	body.uint32(a.UID)
+	body.uint32(a.GID)
+	// no auxiliary gids
+	body.uint32(0)
+	w.uint32(authUnix)
+	w.opaque(body.buf)
+}
+
+type rpcClient struct {
+	mu   sync.Mutex
+	conn net.Conn
+	xid  uint32
+	auth *Auth
+	// the timeout of a call
+	timeout time.Duration
+}
+
+// dialRPC connect the address, a reserved port is used as the source port if possible,
+// since most of the servers only accept the requests from them by default, which needs root
+func dialRPC(addr string, auth *Auth, timeout time.Duration) (*rpcClient, error) {
+	var conn net.Conn
+	var err error
+	for port := 1023; port >= 600 && conn == nil; port-- {
+		d := net.Dialer{Timeout: timeout, LocalAddr: &net.TCPAddr{Port: port}}
+		conn, err = d.Dial("tcp", addr)
+		if err != nil {
+			if errors.Is(err, syscall.EACCES) || errors.Is(err, syscall.EPERM) {
+				break
+			}
+			if errors.Is(err, syscall.EADDRINUSE) {
+				continue
+			}
+			return nil, err
+		}
+	}
+	if conn == nil {
+		conn, err = net.DialTimeout("tcp", addr, timeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &rpcClient{conn: conn, xid: uint32(time.Now().UnixNano()), auth: auth, timeout: timeout}, nil
+}
+
+// call call the procedure of the program, the results of it are returned
+func (c *rpcClient) call(prog, vers, proc uint32, args []byte) (*xdrReader, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.xid++
+	var w xdrWriter
+	// the record mark, filled below
+	w.uint32(0)
+	w.uint32(c.xid)
+	w.uint32(msgCall)
+	w.uint32(2)
+	w.uint32(prog)
+	w.uint32(vers)
+	w.uint32(proc)
+	c.auth.encode(&w)
+	// the verifier
+	w.uint32(authNone)
+	w.opaque(nil)
+	w.buf = append(w.buf, args...)
+	binary.BigEndian.PutUint32(w.buf, uint32(len(w.buf)-4)|1<<31)
+	if c.timeout > 0 {
+		_ = c.conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+	if _, err := c.conn.Write(w.buf); err != nil {
+		return nil, err
+	}
+	for {
+		data, err := c.readRecord()
+		if err != nil {
+			return nil, err
+		}
+		r := &xdrReader{buf: data}
+		// the replies of the calls timed out before are skipped
+		if r.uint32() != c.xid {
+			continue
+		}
+		if err = parseReply(r); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+}
+
+// readRecord read the fragments of a record until the last one
+func (c *rpcClient) readRecord() ([]byte, error) {
+	var data []byte
+	for {
+		var mark [4]byte
+		if _, err := io.ReadFull(c.conn, mark[:]); err != nil {
+			return nil, err
+		}
+		m := binary.BigEndian.Uint32(mark[:])
+		n := int(m &^ (1 << 31))
+		if len(data)+n > maxRecord {
+			return nil, fmt.Errorf("nfs: the record of %d bytes is too large", len(data)+n)
+		}
+		frag := make([]byte, n)
+		if _, err := io.ReadFull(c.conn, frag); err != nil {
+			return nil, err
+		}
+		data = append(data, frag...)
+		if m&(1<<31) != 0 {
+			return data, nil
+		}
+	}
+}
+
+// parseReply check the status of the reply, the reader is at the results after it
+func parseReply(r *xdrReader) error {
+	if r.uint32() != msgReply {
+		return fmt.Errorf("nfs: the message isn't a reply")
+	}
+	switch r.uint32() {
+	case replyAccepted:
+		// the verifier
+		r.uint32()
+		r.opaque()
+		switch stat := r.uint32(); stat {
+		case 0:
+			return r.err
+		case 1:
+			return fmt.Errorf("nfs: the program is unavailable")
+		case 2:
+			return fmt.Errorf("nfs: the version isn't supported, the server supports %d to %d", r.uint32(), r.uint32())
+		case 3:
+			return fmt.Errorf("nfs: the procedure is unavailable")
+		case 4:
+			return fmt.Errorf("nfs: the server can't decode the arguments")
+		default:
+			return fmt.Errorf("nfs: the call is failed with the status %d", stat)
+		}
+	case replyDenied:
+		if r.uint32() == 0 {
+			return fmt.Errorf("nfs: the rpc version isn't supported")
+		}
+		return fmt.Errorf("nfs: the authentication is failed with the status %d", r.uint32())
+	}
+	if r.err != nil {
+		return r.err
+	}
+	return fmt.Errorf("nfs: invalid reply")
+}
+
+func (c *rpcClient) close() error {
+	return c.conn.Close()
+}
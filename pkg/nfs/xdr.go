@@ -0,0 +1,113 @@
+package nfs
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// the encoding of xdr (rfc 4506), the values are big endian and aligned to 4 bytes
+
+var errShortData = errors.New("nfs: the data is shorter than expected")
+
+type xdrWriter struct {
+	buf []byte
+}
+
+func (w *xdrWriter) uint32(v uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	w.buf = append(w.buf, b[:]...)
+}
+
+func (w *xdrWriter) uint64(v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	w.buf = append(w.buf, b[:]...)
+}
+
+func (w *xdrWriter) bool(v bool) {
+	if v {
+		w.uint32(1)
+	} else {
+		w.uint32(0)
+	}
+}
+
+// fixed write the opaque of the fixed length, it's padded to 4 bytes
+func (w *xdrWriter) fixed(v []byte) {
+	w.buf = append(w.buf, v...)
+	if pad := len(v) % 4; pad > 0 {
+		w.buf = append(w.buf, make([]byte, 4-pad)...)
+	}
+}
+
+// opaque write the opaque of the variable length
+func (w *xdrWriter) opaque(v []byte) {
+	w.uint32(uint32(len(v)))
+	w.fixed(v)
+}
+
+func (w *xdrWriter) string(v string) {
+	w.opaque([]byte(v))
+}
+
+type xdrReader struct {
+	buf []byte
+	err error
+}
+
+func (r *xdrReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.buf) {
+		r.err = errShortData
+		r.buf = nil
+		return nil
+	}
+	v := r.buf[:n]
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *xdrReader) uint32() uint32 {
+	v := r.next(4)
+	if v == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(v)
+}
+
+func (r *xdrReader) uint64() uint64 {
+	v := r.next(8)
+	if v == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(v)
+}
+
+func (r *xdrReader) bool() bool {
+	return r.uint32() != 0
+}
+
+func (r *xdrReader) fixed(n int) []byte {
+	v := r.next(n)
+	if pad := n % 4; pad > 0 {
+		r.next(4 - pad)
+	}
+	return v
+}
+
+func (r *xdrReader) opaque() []byte {
+	n := r.uint32()
+	if n > math.MaxInt32 {
+		r.err = errShortData
+		return nil
+	}
+	return r.fixed(int(n))
+}
+
+func (r *xdrReader) string() string {
+	return string(r.opaque())
+}
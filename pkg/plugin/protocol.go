@@ -0,0 +1,123 @@
+// Package plugin is the protocol of the drivers out of the tree. a plugin is an executable in the
+// plugins dir, alist starts it on startup and calls it with json-rpc on its stdin and stdout,
+// the plugin serves the storages of its driver by calling Serve in main.
+//
+// the protocol is net/rpc with the json codec instead of the grpc of hashicorp/go-plugin:
+// grpc and protobuf would be new dependencies of alist and need protoc to change the protocol,
+// while the calls of a driver are a few methods with the small args. the json over stdio needs
+// nothing but the standard library, so a plugin can be written with only this package, or in
+// another language speaking json-rpc 1.0. go plugins (-buildmode=plugin) aren't used
+// since they must be built with the same go version and deps as alist, and don't work on windows.
+//
+// a plugin crashed is marked unavailable, it's started again on the next call after a while and
+// the storages are initialized in the new process, so the storages shouldn't keep the state which
+// can't be got by Init again
+package plugin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// ProtocolVersion is changed when the protocol is incompatible, the plugin
+	// with another version isn't loaded
+	ProtocolVersion = 1
+	// MagicEnv is set by alist when starting the plugin, so it isn't run by mistake
+	MagicEnv   = "ALIST_PLUGIN_MAGIC"
+	MagicValue = "alist-driver-plugin-f3c1"
+	// the name of the service of the rpc
+	serviceName = "Plugin"
+)
+
+// the errors returned by the storages, they're converted to the errors of alist by the messages
+var (
+	ErrNotSupport     = errors.New("not support")
+	ErrObjectNotFound = errors.New("object not found")
+)
+
+// Item is an item of the addition of the driver, shown in the form of the storage
+type Item struct {
+	Name string `json:"name"`
+	// string, number, bool, select or text
+	Type     string `json:"type"`
+	Default  string `json:"default"`
+	Options  string `json:"options"`
+	Required bool   `json:"required"`
+	Help     string `json:"help"`
+	// the value is encrypted in the database and redacted in the responses
+	Secret bool `json:"secret"`
+}
+
+// Info is the info of the driver served by the plugin
+type Info struct {
+	ProtocolVersion int `json:"protocol_version"`
+	// the name of the driver, it mustn't be the same as the drivers of alist
+	Name        string `json:"name"`
+	LocalSort   bool   `json:"local_sort"`
+	OnlyProxy   bool   `json:"only_proxy"`
+	NoCache     bool   `json:"no_cache"`
+	NoUpload    bool   `json:"no_upload"`
+	DefaultRoot string `json:"default_root"`
+	Additional  []Item `json:"additional"`
+}
+
+type Obj struct {
+	// the id of the object in the provider, or the path if the provider identifies the objects by the paths
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	IsFolder bool      `json:"is_folder"`
+	Thumb    string    `json:"thumb"`
+}
+
+// Link is the link of the file, the url with the header or the local file
+type Link struct {
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	// the path of the local file, alist and the plugin must be on the same host
+	FilePath string `json:"file_path"`
+	// the seconds the url is valid, 0 if it's unknown
+	Expiration int `json:"expiration"`
+}
+
+// Storage is a storage of the driver, the methods not supported should return ErrNotSupport
+type Storage interface {
+	// Init the storage with the addition in json
+	Init(ctx context.Context, addition string) error
+	Drop(ctx context.Context) error
+	List(ctx context.Context, dir Obj) ([]Obj, error)
+	Link(ctx context.Context, file Obj) (*Link, error)
+	MakeDir(ctx context.Context, parentDir Obj, dirName string) error
+	Move(ctx context.Context, srcObj, dstDir Obj) error
+	Rename(ctx context.Context, srcObj Obj, newName string) error
+	Copy(ctx context.Context, srcObj, dstDir Obj) error
+	Remove(ctx context.Context, obj Obj) error
+	// Put upload the local file at `path` to `dstDir` with the name, the file is removed by alist after it returns
+	Put(ctx context.Context, dstDir Obj, name string, path string) error
+}
+
+// Args is the args of the calls to the storages, the fields used depend on the method
+type Args struct {
+	// the id of the storage returned by Init
+	ID       uint64 `json:"id"`
+	Addition string `json:"addition"`
+	// the object called, such as the dir to list
+	Obj Obj `json:"obj"`
+	// the dst dir to move or copy to, or the parent dir to put to
+	Dst Obj `json:"dst"`
+	// the new name, the name of the dir to make, or the name of the file to put
+	Name string `json:"name"`
+	// the local file to put
+	Path string `json:"path"`
+}
+
+type Reply struct {
+	ID   uint64 `json:"id"`
+	Objs []Obj  `json:"objs"`
+	Link *Link  `json:"link"`
+}
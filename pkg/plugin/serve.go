@@ -0,0 +1,161 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"sync"
+)
+
+// server serve the storages of the driver, a storage is created by Init and identified by the id
+type server struct {
+	info Info
+	new  func() Storage
+
+	mu       sync.Mutex
+	next     uint64
+	storages map[uint64]Storage
+}
+
+func (s *server) storage(id uint64) (Storage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	storage, ok := s.storages[id]
+	if !ok {
+		return nil, fmt.Errorf("storage %d isn't initialized", id)
+	}
+	return storage, nil
+}
+
+func (s *server) Info(version int, reply *Info) error {
+	*reply = s.info
+	reply.ProtocolVersion = ProtocolVersion
+	return nil
+}
+
+func (s *server) Init(args *Args, reply *Reply) error {
+	storage := s.new()
+	if err := storage.Init(context.Background(), args.Addition); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next++
+	s.storages[s.next] = storage
+	reply.ID = s.next
+	return nil
+}
+
+func (s *server) Drop(args *Args, reply *Reply) error {
+	storage, err := s.storage(args.ID)
+	if err != nil {
+		// the storage failed to init is dropped too
+		return nil
+	}
+	s.mu.Lock()
+	delete(s.storages, args.ID)
+	s.mu.Unlock()
+	return storage.Drop(context.Background())
+}
+
+func (s *server) List(args *Args, reply *Reply) error {
+	storage, err := s.storage(args.ID)
+	if err != nil {
+		return err
+	}
+	reply.Objs, err = storage.List(context.Background(), args.Obj)
+	return err
+}
+
+func (s *server) Link(args *Args, reply *Reply) error {
+	storage, err := s.storage(args.ID)
+	if err != nil {
+		return err
+	}
+	reply.Link, err = storage.Link(context.Background(), args.Obj)
+	return err
+}
+
+func (s *server) MakeDir(args *Args, reply *Reply) error {
+	storage, err := s.storage(args.ID)
+	if err != nil {
+		return err
+	}
+	return storage.MakeDir(context.Background(), args.Obj, args.Name)
+}
+
+func (s *server) Move(args *Args, reply *Reply) error {
+	storage, err := s.storage(args.ID)
+	if err != nil {
+		return err
+	}
+	return storage.Move(context.Background(), args.Obj, args.Dst)
+}
+
+func (s *server) Rename(args *Args, reply *Reply) error {
+	storage, err := s.storage(args.ID)
+	if err != nil {
+		return err
+	}
+	return storage.Rename(context.Background(), args.Obj, args.Name)
+}
+
+func (s *server) Copy(args *Args, reply *Reply) error {
+	storage, err := s.storage(args.ID)
+	if err != nil {
+		return err
+	}
+	return storage.Copy(context.Background(), args.Obj, args.Dst)
+}
+
+func (s *server) Remove(args *Args, reply *Reply) error {
+	storage, err := s.storage(args.ID)
+	if err != nil {
+		return err
+	}
+	return storage.Remove(context.Background(), args.Obj)
+}
+
+func (s *server) Put(args *Args, reply *Reply) error {
+	storage, err := s.storage(args.ID)
+	if err != nil {
+		return err
+	}
+	return storage.Put(context.Background(), args.Dst, args.Name, args.Path)
+}
+
+// ServeConn serve the storages created by `new` on the conn until it's closed
+func ServeConn(conn io.ReadWriteCloser, info Info, new func() Storage) error {
+	srv := rpc.NewServer()
+	err := srv.RegisterName(serviceName, &server{info: info, new: new, storages: make(map[uint64]Storage)})
+	if err != nil {
+		return err
+	}
+	srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+	return nil
+}
+
+type stdio struct {
+	io.Reader
+	io.Writer
+}
+
+func (stdio) Close() error {
+	return os.Stdin.Close()
+}
+
+// Serve serve the storages created by `new` on the stdin and stdout, it's called in the main of the plugin
+// and returns when alist stops it. the logs of the plugin should be written to the stderr
+func Serve(info Info, new func() Storage) {
+	if os.Getenv(MagicEnv) != MagicValue {
+		fmt.Fprintf(os.Stderr, "this is a driver plugin of alist, put it in the plugins dir of alist instead of running it\n")
+		os.Exit(1)
+	}
+	if err := ServeConn(stdio{Reader: os.Stdin, Writer: os.Stdout}, info, new); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to serve: %v\n", err)
+		os.Exit(1)
+	}
+}
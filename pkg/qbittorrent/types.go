@@ -0,0 +1,36 @@
+package qbittorrent
+
+type TorrentInfo struct {
+	Hash        string  `json:"hash"`
+	Name        string  `json:"name"`
+	Category    string  `json:"category"`
+	State       string  `json:"state"`
+	Progress    float64 `json:"progress"` // 0 to 1
+	Size        int64   `json:"size"`
+	TotalSize   int64   `json:"total_size"`
+	Downloaded  int64   `json:"downloaded"`
+	SavePath    string  `json:"save_path"`
+	ContentPath string  `json:"content_path"`
+}
+
+// the states that the torrent is downloaded
+var completedStates = map[string]struct{}{
+	"uploading":  {},
+	"stalledUP":  {},
+	"pausedUP":   {},
+	"stoppedUP":  {},
+	"queuedUP":   {},
+	"forcedUP":   {},
+	"checkingUP": {},
+}
+
+// Completed return true if the torrent is downloaded completely
+func (t TorrentInfo) Completed() bool {
+	_, ok := completedStates[t.State]
+	return ok
+}
+
+// Errored return true if the torrent can't be downloaded
+func (t TorrentInfo) Errored() bool {
+	return t.State == "error" || t.State == "missingFiles"
+}
@@ -3,6 +3,7 @@ package task
 import "errors"
 
 var (
-	ErrTaskNotFound = errors.New("task not found")
-	ErrTaskRunning  = errors.New("task is running")
+	ErrTaskNotFound  = errors.New("task not found")
+	ErrTaskRunning   = errors.New("task is running")
+	ErrTaskSucceeded = errors.New("task is already succeeded")
 )
@@ -1,6 +1,10 @@
 package task
 
 import (
+	"context"
+	"sync/atomic"
+	"time"
+
 	"github.com/alist-org/alist/v3/pkg/generic_sync"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
@@ -12,6 +16,8 @@ type Manager[K comparable] struct {
 	curID    K
 	updateID func(*K)
 	tasks    generic_sync.MapOf[K, *Task[K]]
+	// the pending tasks aren't run after the manager is shut down
+	closed int32
 }
 
 func (tm *Manager[K]) Submit(task *Task[K]) K {
@@ -29,6 +35,11 @@ func (tm *Manager[K]) do(task *Task[K]) {
 		log.Debugf("task [%s] waiting for worker", task.Name)
 		select {
 		case <-tm.workerC:
+			if atomic.LoadInt32(&tm.closed) == 1 {
+				log.Debugf("task [%s] isn't started since shutting down", task.Name)
+				tm.workerC <- struct{}{}
+				return
+			}
 			log.Debugf("task [%s] starting", task.Name)
 			task.run()
 			log.Debugf("task [%s] ended", task.Name)
@@ -59,6 +70,13 @@ func (tm *Manager[K]) Retry(tid K) error {
 	if !ok {
 		return errors.WithStack(ErrTaskNotFound)
 	}
+	if !t.Done() {
+		return errors.WithStack(ErrTaskRunning)
+	}
+	if t.GetState() == SUCCEEDED {
+		return errors.WithStack(ErrTaskSucceeded)
+	}
+	t.reset()
 	tm.do(t)
 	return nil
 }
@@ -122,6 +140,26 @@ func (tm *Manager[K]) ClearDone() {
 	tm.RemoveByStates(SUCCEEDED, CANCELED, ERRORED)
 }
 
+// Shutdown stop starting the pending tasks and wait for the running ones until ctx is done, the ones
+// still running are canceled then. the tasks interrupted, pending or canceled by it, are returned
+func (tm *Manager[K]) Shutdown(ctx context.Context) []*Task[K] {
+	atomic.StoreInt32(&tm.closed, 1)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for len(tm.GetByStates(RUNNING, CANCELING)) > 0 {
+		select {
+		case <-ctx.Done():
+			running := tm.GetByStates(RUNNING, CANCELING)
+			for _, t := range running {
+				t.Cancel()
+			}
+			return append(tm.GetByStates(PENDING), running...)
+		case <-ticker.C:
+		}
+	}
+	return tm.GetByStates(PENDING)
+}
+
 func NewTaskManager[K comparable](maxWorker int, updateID ...func(*K)) *Manager[K] {
 	tm := &Manager[K]{
 		tasks:   generic_sync.MapOf[K, *Task[K]]{},
@@ -63,8 +63,16 @@ func (t Task[K]) GetErrMsg() string {
 	return t.Error.Error()
 }
 
+// OnFinished is called when a task of any manager is finished, it's set on startup
+var OnFinished func(name, state, errMsg string)
+
 func (t *Task[K]) run() {
 	t.state = RUNNING
+	if OnFinished != nil {
+		defer func() {
+			OnFinished(t.Name, t.state, t.GetErrMsg())
+		}()
+	}
 	defer func() {
 		if err := recover(); err != nil {
 			log.Errorf("error [%+v] while run task [%s]", err, t.Name)
@@ -92,6 +100,18 @@ func (t *Task[K]) retry() {
 	t.run()
 }
 
+// reset the task to pending so that it can be run again,
+// a new context is created if the old one is canceled
+func (t *Task[K]) reset() {
+	if t.cancel != nil && t.Ctx.Err() != nil {
+		t.Ctx, t.cancel = context.WithCancel(context.Background())
+	}
+	t.state = PENDING
+	t.status = ""
+	t.progress = 0
+	t.Error = nil
+}
+
 func (t *Task[K]) Done() bool {
 	return t.state == SUCCEEDED || t.state == CANCELED || t.state == ERRORED
 }
@@ -100,11 +120,11 @@ func (t *Task[K]) Cancel() {
 	if t.state == SUCCEEDED || t.state == CANCELED {
 		return
 	}
+	// maybe can't cancel
+	t.state = CANCELING
 	if t.cancel != nil {
 		t.cancel()
 	}
-	// maybe can't cancel
-	t.state = CANCELING
 }
 
 func WithCancelCtx[K comparable](task *Task[K]) *Task[K] {
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -94,3 +95,69 @@ func TestTask_Retry(t *testing.T) {
 		t.Errorf("task error: %+v, but expected nil", task.Error)
 	}
 }
+
+func TestManager_RetryCanceled(t *testing.T) {
+	tm := NewTaskManager(3, func(id *uint64) {
+		atomic.AddUint64(id, 1)
+	})
+	var num int32
+	id := tm.Submit(WithCancelCtx(&Task[uint64]{
+		Name: "test",
+		Func: func(task *Task[uint64]) error {
+			if atomic.AddInt32(&num, 1) == 1 {
+				<-task.Ctx.Done()
+			}
+			return nil
+		},
+	}))
+	task := tm.MustGet(id)
+	time.Sleep(time.Millisecond)
+	if err := tm.Cancel(id); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if task.GetState() != CANCELED {
+		t.Fatalf("task state: %s, but expected canceled", task.GetState())
+	}
+	if err := tm.Retry(id); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if task.GetState() != SUCCEEDED {
+		t.Errorf("task state: %s, but expected succeeded", task.GetState())
+	}
+	if err := tm.Retry(id); err == nil {
+		t.Error("retry a succeeded task, but expected error")
+	}
+}
+
+func TestTask_Shutdown(t *testing.T) {
+	tm := NewTaskManager(1, func(id *uint64) {
+		atomic.AddUint64(id, 1)
+	})
+	long := func(task *Task[uint64]) error {
+		select {
+		case <-task.Ctx.Done():
+			return task.Ctx.Err()
+		case <-time.After(time.Second * 5):
+			return nil
+		}
+	}
+	running := tm.Submit(WithCancelCtx(&Task[uint64]{Name: "running", Func: long}))
+	time.Sleep(time.Millisecond * 50)
+	pending := tm.Submit(WithCancelCtx(&Task[uint64]{Name: "pending", Func: long}))
+	time.Sleep(time.Millisecond * 100)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+	interrupted := tm.Shutdown(ctx)
+	if len(interrupted) != 2 {
+		t.Fatalf("expect 2 tasks interrupted, got %d", len(interrupted))
+	}
+	time.Sleep(time.Millisecond * 100)
+	if state := tm.MustGet(running).GetState(); state != CANCELED {
+		t.Errorf("the running task should be canceled, got %s", state)
+	}
+	if state := tm.MustGet(pending).GetState(); state != PENDING {
+		t.Errorf("the pending task should not be started, got %s", state)
+	}
+}
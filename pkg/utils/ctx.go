@@ -2,6 +2,7 @@ package utils
 
 import (
 	"context"
+	"time"
 )
 
 func IsCanceled(ctx context.Context) bool {
@@ -12,3 +13,18 @@ func IsCanceled(ctx context.Context) bool {
 		return false
 	}
 }
+
+// detached keep the values of the context, but it's never canceled
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
+
+// Detach get the context with the values of ctx, but it isn't canceled with ctx,
+// such as the work started by the request lasts after the request
+func Detach(ctx context.Context) context.Context {
+	return detached{ctx}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"fmt"
 	"net"
 	"net/http"
 	"strings"
@@ -47,3 +48,33 @@ func IsLocalIP(ip net.IP) bool {
 		(ip4[0] == 169 && ip4[1] == 254) || // 169.254.0.0/16
 		(ip4[0] == 192 && ip4[1] == 168) // 192.168.0.0/16
 }
+
+// IPInList check if the ip is in the list of the ips or the cidrs, one in a line
+func IPInList(ip, list string) bool {
+	addr := net.ParseIP(ip)
+	for _, s := range strings.Split(list, "\n") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		if s == ip {
+			return true
+		}
+		if _, n, err := net.ParseCIDR(s); err == nil && addr != nil && n.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckIPList check the lines of the list are the valid ips or cidrs
+func CheckIPList(list string) error {
+	for _, s := range strings.Split(list, "\n") {
+		if s = strings.TrimSpace(s); s == "" || net.ParseIP(s) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(s); err != nil {
+			return fmt.Errorf("invalid ip or cidr: %s", s)
+		}
+	}
+	return nil
+}
@@ -60,3 +60,18 @@ func EncodePath(path string, all ...bool) string {
 	}
 	return strings.Join(seg, "/")
 }
+
+// IsSubPath check if the path is the same as or under the parent
+func IsSubPath(parent, path string) bool {
+	parent, path = StandardizePath(parent), StandardizePath(path)
+	if parent == "/" || parent == path {
+		return true
+	}
+	return strings.HasPrefix(path, parent+"/")
+}
+
+// JoinBasePath join the path under the base path, the path is cleaned as a rooted one
+// first, so it can't be out of the base path by ".."
+func JoinBasePath(basePath, path string) string {
+	return stdpath.Join(basePath, stdpath.Clean("/"+path))
+}
@@ -5,3 +5,40 @@ import "testing"
 func TestEncodePath(t *testing.T) {
 	t.Log(EncodePath("http://localhost:5244/d/123#.png"))
 }
+
+func TestIsSubPath(t *testing.T) {
+	tests := []struct {
+		parent, path string
+		want         bool
+	}{
+		{"/", "/a/b", true},
+		{"/a", "/a", true},
+		{"/a", "/a/b", true},
+		{"/a/", "/a/b", true},
+		{"/a", "/ab", false},
+		{"/a/b", "/a", false},
+	}
+	for _, tt := range tests {
+		if got := IsSubPath(tt.parent, tt.path); got != tt.want {
+			t.Errorf("IsSubPath(%s, %s) = %v, want %v", tt.parent, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestJoinBasePath(t *testing.T) {
+	tests := []struct {
+		base, path, want string
+	}{
+		{"/", "/a/b", "/a/b"},
+		{"/a", "", "/a"},
+		{"/a", "/b/../c", "/a/c"},
+		{"/a", "/../b", "/a/b"},
+		{"/a", "../../b", "/a/b"},
+		{"/a", "/b/../../..", "/a"},
+	}
+	for _, tt := range tests {
+		if got := JoinBasePath(tt.base, tt.path); got != tt.want {
+			t.Errorf("JoinBasePath(%s, %s) = %s, want %s", tt.base, tt.path, got, tt.want)
+		}
+	}
+}
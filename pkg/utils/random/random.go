@@ -1,6 +1,8 @@
 package random
 
 import (
+	crand "crypto/rand"
+	"math/big"
 	"math/rand"
 	"time"
 
@@ -19,6 +21,20 @@ func String(n int) string {
 	return string(b)
 }
 
+// SecureString is like String but use crypto/rand, for the secrets
+func SecureString(n int) string {
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(letterBytes)))
+	for i := range b {
+		idx, err := crand.Int(crand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		b[i] = letterBytes[idx.Int64()]
+	}
+	return string(b)
+}
+
 func Token() string {
 	return "alist-" + uuid.NewString() + String(64)
 }
@@ -35,3 +35,14 @@ func SliceConvert[S any, D any](srcS []S, convert func(src S) (D, error)) ([]D,
 	}
 	return res, nil
 }
+
+// SliceFilter get the elements that `keep` returns true
+func SliceFilter[T any](arr []T, keep func(v T) bool) []T {
+	res := make([]T, 0, len(arr))
+	for _, v := range arr {
+		if keep(v) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -3,12 +3,20 @@ package common
 import (
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/pkg/errors"
 )
 
 var SecretKey []byte
 
+// ApiTokenPrefix is the prefix of the api tokens, to tell them from the jwt tokens
+const ApiTokenPrefix = "alist-api-"
+
 type UserClaims struct {
 	Username string `json:"username"`
 	jwt.RegisteredClaims
@@ -49,3 +57,16 @@ func ParseToken(tokenString string) (*UserClaims, error) {
 	}
 	return nil, errors.New("couldn't handle this token")
 }
+
+// ValidateClientPassword validate the password of the clients like webdav, ftp and sftp,
+// the app passwords are always accepted, the password of the user isn't accepted
+// if the user enabled 2FA and the app passwords are required
+func ValidateClientPassword(user *model.User, password string) error {
+	if db.ValidateAppPassword(user.ID, password) {
+		return nil
+	}
+	if user.OtpSecret != "" && setting.GetBool(conf.AppPasswordRequired) {
+		return errors.WithStack(errs.WrongPassword)
+	}
+	return user.ValidatePassword(password)
+}
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// OpenLink open the content of the link from offset,
+// it's used by the servers that can't redirect or proxy the request, such as sftp
+func OpenLink(link *model.Link, offset int64) (io.ReadCloser, error) {
+	// read data with native
+	if link.Data != nil {
+		if offset > 0 {
+			if _, err := io.CopyN(ioutil.Discard, link.Data, offset); err != nil {
+				_ = link.Data.Close()
+				return nil, err
+			}
+		}
+		return link.Data, nil
+	}
+	// local file
+	if link.FilePath != nil && *link.FilePath != "" {
+		f, err := os.Open(*link.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return f, nil
+	}
+	req, err := http.NewRequest(http.MethodGet, link.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for h, val := range link.Header {
+		req.Header[h] = val
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	res, err := HttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 400 {
+		all, _ := ioutil.ReadAll(res.Body)
+		_ = res.Body.Close()
+		return nil, errors.Errorf("failed to open link, status: %d, body: %s", res.StatusCode, all)
+	}
+	// the upstream doesn't support range
+	if offset > 0 && res.StatusCode != http.StatusPartialContent {
+		if _, err := io.CopyN(ioutil.Discard, res.Body, offset); err != nil {
+			_ = res.Body.Close()
+			return nil, err
+		}
+	}
+	return res.Body, nil
+}
@@ -0,0 +1,197 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// the size of each segment fetched with a ranged request
+const segmentSize int64 = 4 * 1024 * 1024
+
+// multiReadSeeker get the reader fetching the url of the link with the concurrent ranged
+// requests, nil if the link isn't configured to or the file is too small
+func multiReadSeeker(r *http.Request, link *model.Link, file model.Obj) io.ReadSeekCloser {
+	if link.Concurrency <= 1 || link.URL == "" || link.Status != 0 || file.GetSize() <= segmentSize {
+		return nil
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(r.Context())
+	return &multiReader{
+		ctx:         ctx,
+		cancel:      cancel,
+		link:        link,
+		size:        file.GetSize(),
+		limit:       rangeEnd(r.Header.Get("Range"), file.GetSize()),
+		concurrency: link.Concurrency,
+	}
+}
+
+// rangeEnd get the end of the single range requested, so the segments after it aren't fetched
+func rangeEnd(s string, size int64) int64 {
+	var start, end int64
+	if n, _ := fmt.Sscanf(s, "bytes=%d-%d", &start, &end); n == 2 && !strings.Contains(s, ",") && end >= start && end < size {
+		return end + 1
+	}
+	return size
+}
+
+type segment struct {
+	start, end int64
+	cancel     context.CancelFunc
+	done       chan struct{}
+	data       []byte
+	err        error
+}
+
+// multiReader fetch up to `concurrency` segments ahead of the offset, and read them in order.
+// only the segments after the offset are fetched, so the range requests aren't read to the end
+type multiReader struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	size   int64
+	// the segments aren't fetched after the limit
+	limit       int64
+	offset      int64
+	concurrency int
+	// the segments being fetched in order, the first one contains the offset
+	segments []*segment
+	// the start of the segment to fetch next
+	next int64
+
+	mu   sync.Mutex
+	link *model.Link
+}
+
+func (r *multiReader) Read(p []byte) (int, error) {
+	if r.offset >= r.limit {
+		return 0, io.EOF
+	}
+	if len(r.segments) == 0 {
+		r.next = r.offset
+	}
+	r.fill()
+	seg := r.segments[0]
+	select {
+	case <-seg.done:
+	case <-r.ctx.Done():
+		return 0, r.ctx.Err()
+	}
+	if seg.err != nil {
+		return 0, seg.err
+	}
+	n := copy(p, seg.data[r.offset-seg.start:])
+	r.offset += int64(n)
+	if r.offset >= seg.end {
+		r.segments = r.segments[1:]
+	}
+	return n, nil
+}
+
+// fill start fetching the segments until `concurrency` ones are in progress
+func (r *multiReader) fill() {
+	for len(r.segments) < r.concurrency && r.next < r.limit {
+		end := r.next + segmentSize
+		if end > r.limit {
+			end = r.limit
+		}
+		ctx, cancel := context.WithCancel(r.ctx)
+		seg := &segment{start: r.next, end: end, cancel: cancel, done: make(chan struct{})}
+		r.segments = append(r.segments, seg)
+		r.next = end
+		go func() {
+			defer close(seg.done)
+			seg.data, seg.err = r.fetch(ctx, seg.start, seg.end)
+		}()
+	}
+}
+
+func (r *multiReader) fetch(ctx context.Context, start, end int64) ([]byte, error) {
+	r.mu.Lock()
+	link := r.link
+	r.mu.Unlock()
+	res, err := requestRange(ctx, link, start, end)
+	if err != nil {
+		return nil, err
+	}
+	// the url may be expired while playing for a long time
+	if res.StatusCode == http.StatusForbidden && link.Refresh != nil {
+		_ = res.Body.Close()
+		r.mu.Lock()
+		// the link may be refreshed by another segment
+		if r.link == link {
+			r.link, err = link.Refresh(ctx)
+		}
+		link = r.link
+		r.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		if res, err = requestRange(ctx, link, start, end); err != nil {
+			return nil, err
+		}
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode != http.StatusPartialContent {
+		return nil, errors.Errorf("failed to fetch [%d, %d): unexpected status %s", start, end, res.Status)
+	}
+	data := make([]byte, end-start)
+	if _, err = io.ReadFull(res.Body, data); err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch [%d, %d)", start, end)
+	}
+	return data, nil
+}
+
+func requestRange(ctx context.Context, link *model.Link, start, end int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link.URL, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for h, val := range link.Header {
+		req.Header[h] = val
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end-1))
+	res, err := HttpClient.Do(req)
+	return res, errors.WithStack(err)
+}
+
+func (r *multiReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	}
+	if offset < 0 {
+		return 0, errors.New("seek: negative position")
+	}
+	if offset != r.offset {
+		r.drop()
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// drop cancel the segments fetching
+func (r *multiReader) drop() {
+	for _, seg := range r.segments {
+		seg.cancel()
+	}
+	r.segments = nil
+}
+
+func (r *multiReader) Close() error {
+	r.drop()
+	r.cancel()
+	return nil
+}
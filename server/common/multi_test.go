@@ -0,0 +1,52 @@
+package common
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestProxyMulti(t *testing.T) {
+	content := make([]byte, 3*segmentSize+100)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+	link := &model.Link{URL: srv.URL, Concurrency: 2}
+	file := &model.Object{Name: "a.bin", Size: int64(len(content))}
+
+	w := httptest.NewRecorder()
+	if err := Proxy(w, httptest.NewRequest(http.MethodGet, "/", nil), link, file); err != nil {
+		t.Fatalf("failed to proxy: %+v", err)
+	}
+	if !bytes.Equal(w.Body.Bytes(), content) {
+		t.Fatalf("the content proxied is wrong")
+	}
+	if requests != 4 {
+		t.Errorf("expect 4 segments fetched, got %d", requests)
+	}
+
+	atomic.StoreInt32(&requests, 0)
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Range", "bytes=100-199")
+	if err := Proxy(w, r, link, file); err != nil {
+		t.Fatalf("failed to proxy: %+v", err)
+	}
+	if w.Code != http.StatusPartialContent || !bytes.Equal(w.Body.Bytes(), content[100:200]) {
+		t.Fatalf("the range proxied is wrong")
+	}
+	if requests != 1 {
+		t.Errorf("expect 1 segment fetched for the range, got %d", requests)
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"strconv"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -17,10 +20,32 @@ import (
 
 var HttpClient = &http.Client{}
 
+// countWriter count the bytes written to the client
+type countWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
 func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.Obj) error {
+	defer metrics.ProxyStream()()
+	cw := &countWriter{ResponseWriter: w}
+	defer func() {
+		metrics.DownloadBytes(cw.n)
+	}()
+	w = cw
 	// read data with native
 	var err error
 	if link.Data != nil {
+		if rs := rangeReadSeeker(link, file); rs != nil {
+			serveContent(w, r, file, rs)
+			return nil
+		}
 		defer func() {
 			_ = link.Data.Close()
 		}()
@@ -43,6 +68,11 @@ func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.
 		}
 		return nil
 	}
+	// the provider throttles the single connection
+	if rs := multiReadSeeker(r, link, file); rs != nil {
+		serveContent(w, r, file, rs)
+		return nil
+	}
 	// local file
 	if link.FilePath != nil && *link.FilePath != "" {
 		f, err := os.Open(*link.FilePath)
@@ -60,22 +90,20 @@ func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.
 		http.ServeContent(w, r, file.GetName(), fileStat.ModTime(), f)
 		return nil
 	} else {
-		req, err := http.NewRequest(r.Method, link.URL, nil)
+		res, err := requestLink(r, link)
 		if err != nil {
 			return err
 		}
-		for h, val := range r.Header {
-			if strings.ToLower(h) == "authorization" {
-				continue
+		// the url may be expired while playing for a long time
+		if res.StatusCode == http.StatusForbidden && link.Refresh != nil {
+			_ = res.Body.Close()
+			link, err = link.Refresh(r.Context())
+			if err != nil {
+				return err
+			}
+			if res, err = requestLink(r, link); err != nil {
+				return err
 			}
-			req.Header[h] = val
-		}
-		for h, val := range link.Header {
-			req.Header[h] = val
-		}
-		res, err := HttpClient.Do(req)
-		if err != nil {
-			return err
 		}
 		defer func() {
 			_ = res.Body.Close()
@@ -98,3 +126,34 @@ func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.
 		return nil
 	}
 }
+
+func serveContent(w http.ResponseWriter, r *http.Request, file model.Obj, rs io.ReadSeekCloser) {
+	defer func() {
+		_ = rs.Close()
+	}()
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, file.GetName(), url.QueryEscape(file.GetName())))
+	// avoid sniffing the content type that reads the start of the file
+	if w.Header().Get("Content-Type") == "" && mime.TypeByExtension(path.Ext(file.GetName())) == "" {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	// ServeContent handles the Range and If-Range with the ETag set by the caller or the mtime
+	http.ServeContent(w, r, file.GetName(), file.ModTime(), rs)
+}
+
+// requestLink request the url of the link with the headers of the client and the link
+func requestLink(r *http.Request, link *model.Link) (*http.Response, error) {
+	req, err := http.NewRequest(r.Method, link.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for h, val := range r.Header {
+		if strings.ToLower(h) == "authorization" {
+			continue
+		}
+		req.Header[h] = val
+	}
+	for h, val := range link.Header {
+		req.Header[h] = val
+	}
+	return HttpClient.Do(req)
+}
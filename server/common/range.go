@@ -0,0 +1,78 @@
+package common
+
+import (
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// rangeReadSeeker get the seekable reader of the Data link to serve the range requests,
+// nil if the link can't seek or the driver has handled the range itself
+func rangeReadSeeker(link *model.Link, file model.Obj) io.ReadSeekCloser {
+	if link.Status != 0 || file.GetSize() < 0 {
+		return nil
+	}
+	if rs, ok := link.Data.(io.ReadSeekCloser); ok {
+		return rs
+	}
+	if link.RangeReader == nil {
+		return nil
+	}
+	return &rangeReader{
+		size: file.GetSize(),
+		rc:   link.Data,
+		open: link.RangeReader,
+	}
+}
+
+// rangeReader seek by reading from the new offset with RangeReader of the link,
+// the reader is only reopened when reading after the offset is changed
+type rangeReader struct {
+	size   int64
+	offset int64
+	// the reader at the offset, nil if it should be reopened
+	rc   io.ReadCloser
+	open func(offset int64) (io.ReadCloser, error)
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.rc == nil {
+		rc, err := r.open(r.offset)
+		if err != nil {
+			return 0, err
+		}
+		r.rc = rc
+	}
+	n, err := r.rc.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *rangeReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	}
+	if offset < 0 {
+		return 0, errors.New("seek: negative position")
+	}
+	if offset != r.offset && r.rc != nil {
+		_ = r.rc.Close()
+		r.rc = nil
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+func (r *rangeReader) Close() error {
+	if r.rc == nil {
+		return nil
+	}
+	return r.rc.Close()
+}
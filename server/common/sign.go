@@ -1,13 +1,23 @@
 package common
 
 import (
+	stdpath "path"
+
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
 )
 
-func Sign(obj model.Obj, encrypt bool) string {
+// Sign sign the object in the parent dir for the client of the ip, it's empty if the dir isn't encrypted
+func Sign(obj model.Obj, parent string, encrypt bool, ip string) string {
 	if obj.IsDir() || !encrypt {
 		return ""
 	}
-	return sign.Sign(obj.GetName())
+	return sign.Sign(stdpath.Join(parent, obj.GetName()), ip)
+}
+
+// GetDownUrl get the signed url of downloading the file in path through alist, used by the drivers
+// re-exposing the files of other storages when the link of the file is local
+func GetDownUrl(path, ip string) string {
+	return GetApiUrl(nil) + "/d" + utils.EncodePath(path, true) + "?sign=" + sign.Sign(path, ip)
 }
@@ -0,0 +1,535 @@
+package ftp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/event"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/internal/throttle"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type command struct {
+	fn        func(s *session, arg string)
+	needLogin bool
+	needArg   bool
+}
+
+var commands = map[string]command{
+	"USER": {fn: cmdUser, needArg: true},
+	"PASS": {fn: cmdPass},
+	"AUTH": {fn: cmdAuth, needArg: true},
+	"PBSZ": {fn: cmdPbsz},
+	"PROT": {fn: cmdProt, needArg: true},
+	"FEAT": {fn: cmdFeat},
+	"SYST": {fn: cmdSyst},
+	"OPTS": {fn: cmdOpts},
+	"NOOP": {fn: cmdNoop},
+	"QUIT": {fn: cmdQuit},
+	"TYPE": {fn: cmdNoop, needArg: true},
+	"MODE": {fn: cmdNoop, needArg: true},
+	"STRU": {fn: cmdNoop, needArg: true},
+	"ABOR": {fn: cmdAbor},
+
+	"PWD":  {fn: cmdPwd, needLogin: true},
+	"XPWD": {fn: cmdPwd, needLogin: true},
+	"CWD":  {fn: cmdCwd, needLogin: true, needArg: true},
+	"XCWD": {fn: cmdCwd, needLogin: true, needArg: true},
+	"CDUP": {fn: cmdCdup, needLogin: true},
+	"XCUP": {fn: cmdCdup, needLogin: true},
+	"PASV": {fn: cmdPasv, needLogin: true},
+	"EPSV": {fn: cmdEpsv, needLogin: true},
+	"PORT": {fn: cmdPort, needLogin: true, needArg: true},
+	"EPRT": {fn: cmdEprt, needLogin: true, needArg: true},
+	"LIST": {fn: cmdList, needLogin: true},
+	"NLST": {fn: cmdNlst, needLogin: true},
+	"MLSD": {fn: cmdMlsd, needLogin: true},
+	"MLST": {fn: cmdMlst, needLogin: true},
+	"SIZE": {fn: cmdSize, needLogin: true, needArg: true},
+	"MDTM": {fn: cmdMdtm, needLogin: true, needArg: true},
+	"REST": {fn: cmdRest, needLogin: true, needArg: true},
+	"ALLO": {fn: cmdAllo, needLogin: true},
+	"RETR": {fn: cmdRetr, needLogin: true, needArg: true},
+	"STOR": {fn: cmdStor, needLogin: true, needArg: true},
+	"DELE": {fn: cmdDele, needLogin: true, needArg: true},
+	"MKD":  {fn: cmdMkd, needLogin: true, needArg: true},
+	"XMKD": {fn: cmdMkd, needLogin: true, needArg: true},
+	"RMD":  {fn: cmdRmd, needLogin: true, needArg: true},
+	"XRMD": {fn: cmdRmd, needLogin: true, needArg: true},
+	"RNFR": {fn: cmdRnfr, needLogin: true, needArg: true},
+	"RNTO": {fn: cmdRnto, needLogin: true, needArg: true},
+}
+
+// replyError reply the error of alist with the proper code
+func (s *session) replyError(err error) {
+	if errs.IsObjectNotFound(err) {
+		s.reply(550, "No such file or directory")
+		return
+	}
+	if errors.Is(err, errs.PermissionDenied) {
+		s.reply(550, "Permission denied")
+		return
+	}
+	log.Errorf("ftp error: %+v", err)
+	s.reply(550, err.Error())
+}
+
+func cmdUser(s *session, arg string) {
+	s.username, s.user = arg, nil
+	s.reply(331, "User name okay, need password")
+}
+
+func cmdPass(s *session, arg string) {
+	if s.username == "" {
+		s.reply(503, "Login with USER first")
+		return
+	}
+	ip := audit.RemoteIP(s.conn.RemoteAddr())
+	// the clients failed too many times are banned for a while, against guessing the passwords
+	if throttle.Banned(throttle.KindLogin, ip) > 0 {
+		s.reply(530, "Too many unsuccessful attempts, try again later")
+		return
+	}
+	user, err := db.GetUserByName(s.username)
+	if err != nil || common.ValidateClientPassword(user, arg) != nil {
+		throttle.Fail(throttle.KindLogin, ip)
+		event.PublishLoginFailed(s.username, ip, "ftp")
+		s.reply(530, "Login incorrect")
+		return
+	}
+	if !user.AllowIP(ip) {
+		s.reply(530, "Login from this ip is denied")
+		return
+	}
+	throttle.Reset(throttle.KindLogin, ip)
+	s.user, s.cwd = user, "/"
+	s.reply(230, "User logged in")
+}
+
+func (s *session) ctx() context.Context {
+	return audit.WithIP(context.WithValue(context.Background(), "user", s.user), audit.RemoteIP(s.conn.RemoteAddr()))
+}
+
+func cmdAuth(s *session, arg string) {
+	if s.server.tlsConfig == nil {
+		s.reply(502, "TLS is not enabled")
+		return
+	}
+	if s.tls {
+		s.reply(503, "Already using TLS")
+		return
+	}
+	if mode := strings.ToUpper(arg); mode != "TLS" && mode != "TLS-C" && mode != "SSL" {
+		s.reply(504, "Unsupported security mechanism")
+		return
+	}
+	s.reply(234, "AUTH command okay, starting TLS")
+	if err := s.upgradeTLS(); err != nil {
+		log.Debugf("failed ftps handshake with %s: %+v", s.conn.RemoteAddr(), err)
+		s.quit = true
+	}
+}
+
+func cmdPbsz(s *session, arg string) {
+	if !s.tls {
+		s.reply(503, "Use AUTH first")
+		return
+	}
+	s.reply(200, "PBSZ=0")
+}
+
+func cmdProt(s *session, arg string) {
+	if !s.tls {
+		s.reply(503, "Use AUTH first")
+		return
+	}
+	switch strings.ToUpper(arg) {
+	case "C":
+		s.protected = false
+	case "P":
+		s.protected = true
+	default:
+		s.reply(536, "Unsupported protection level")
+		return
+	}
+	s.reply(200, "Protection level set")
+}
+
+func cmdFeat(s *session, arg string) {
+	features := []string{"UTF8", "SIZE", "MDTM", "REST STREAM", "MLST type*;size*;modify*;", "EPSV", "EPRT"}
+	if s.server.tlsConfig != nil {
+		features = append(features, "AUTH TLS", "PBSZ", "PROT")
+	}
+	s.replyLines(211, "Features:", features, "End")
+}
+
+func cmdSyst(s *session, arg string) {
+	s.reply(215, "UNIX Type: L8")
+}
+
+func cmdOpts(s *session, arg string) {
+	if strings.ToUpper(arg) == "UTF8 ON" {
+		s.reply(200, "UTF8 mode enabled")
+		return
+	}
+	s.reply(501, "Option not understood")
+}
+
+func cmdNoop(s *session, arg string) {
+	s.reply(200, "Command okay")
+}
+
+func cmdQuit(s *session, arg string) {
+	s.reply(221, "Goodbye")
+	s.quit = true
+}
+
+func cmdAbor(s *session, arg string) {
+	// the transfers are synchronous, so there is nothing to abort
+	s.closePasv()
+	s.reply(226, "Closing data connection")
+}
+
+func quote(p string) string {
+	return `"` + strings.ReplaceAll(p, `"`, `""`) + `"`
+}
+
+func cmdPwd(s *session, arg string) {
+	s.reply(257, quote(s.cwd)+" is the current directory")
+}
+
+func cmdCwd(s *session, arg string) {
+	obj, err := fs.Get(s.ctx(), s.path(arg))
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	if !obj.IsDir() {
+		s.reply(550, "Not a directory")
+		return
+	}
+	s.cwd = s.relPath(arg)
+	s.reply(250, "Directory changed to "+s.cwd)
+}
+
+func cmdCdup(s *session, arg string) {
+	cmdCwd(s, "..")
+}
+
+// list the objs of the path, or the obj itself if it's a file
+func (s *session) list(arg string) ([]model.Obj, error) {
+	reqPath := s.path(arg)
+	obj, err := fs.Get(s.ctx(), reqPath)
+	if err != nil {
+		return nil, err
+	}
+	if !obj.IsDir() {
+		return []model.Obj{obj}, nil
+	}
+	meta, _ := db.GetNearestMeta(reqPath)
+	return fs.List(context.WithValue(s.ctx(), "meta", meta), reqPath)
+}
+
+// transfer open the data connection and call fn with it
+func (s *session) transfer(fn func(conn net.Conn) error) {
+	s.reply(150, "Opening data connection")
+	conn, err := s.openDataConn()
+	if err != nil {
+		s.reply(425, "Can't open data connection: "+err.Error())
+		return
+	}
+	err = fn(conn)
+	_ = conn.Close()
+	if err != nil {
+		log.Errorf("ftp transfer error: %+v", err)
+		s.reply(426, "Transfer aborted: "+err.Error())
+		return
+	}
+	s.reply(226, "Transfer complete")
+}
+
+// writeLines write the lines to the data connection
+func (s *session) writeLines(lines []string) {
+	s.transfer(func(conn net.Conn) error {
+		w := bufio.NewWriter(conn)
+		for _, line := range lines {
+			if _, err := w.WriteString(line + "\r\n"); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	})
+}
+
+func cmdList(s *session, arg string) {
+	objs, err := s.list(listArg(arg))
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	lines := make([]string, len(objs))
+	for i, obj := range objs {
+		lines[i] = formatList(obj)
+	}
+	s.writeLines(lines)
+}
+
+func cmdNlst(s *session, arg string) {
+	objs, err := s.list(listArg(arg))
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	lines := make([]string, len(objs))
+	for i, obj := range objs {
+		lines[i] = obj.GetName()
+	}
+	s.writeLines(lines)
+}
+
+func cmdMlsd(s *session, arg string) {
+	reqPath := s.path(arg)
+	meta, _ := db.GetNearestMeta(reqPath)
+	objs, err := fs.List(context.WithValue(s.ctx(), "meta", meta), reqPath)
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	lines := make([]string, len(objs))
+	for i, obj := range objs {
+		lines[i] = formatFacts(obj, obj.GetName())
+	}
+	s.writeLines(lines)
+}
+
+func cmdMlst(s *session, arg string) {
+	obj, err := fs.Get(s.ctx(), s.path(arg))
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	s.replyLines(250, "Listing "+s.relPath(arg), []string{formatFacts(obj, s.relPath(arg))}, "End")
+}
+
+func cmdSize(s *session, arg string) {
+	obj, err := fs.Get(s.ctx(), s.path(arg))
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	if obj.IsDir() {
+		s.reply(550, "Not a file")
+		return
+	}
+	s.reply(213, strconv.FormatInt(obj.GetSize(), 10))
+}
+
+func cmdMdtm(s *session, arg string) {
+	obj, err := fs.Get(s.ctx(), s.path(arg))
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	s.reply(213, formatTime(obj.ModTime()))
+}
+
+func cmdRest(s *session, arg string) {
+	offset, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || offset < 0 {
+		s.reply(501, "Syntax error in parameters or arguments")
+		return
+	}
+	s.restOffset = offset
+	s.reply(350, fmt.Sprintf("Restarting at %d", offset))
+}
+
+// cmdAllo record the size of the file to upload, the storages that need the size can use it
+func cmdAllo(s *session, arg string) {
+	size, _, _ := strings.Cut(arg, " ")
+	s.allocSize, _ = strconv.ParseInt(size, 10, 64)
+	s.reply(200, "Command okay")
+}
+
+func cmdRetr(s *session, arg string) {
+	reqPath := s.path(arg)
+	obj, err := fs.Get(s.ctx(), reqPath)
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	if obj.IsDir() {
+		s.reply(550, "Not a file")
+		return
+	}
+	ip, _, _ := net.SplitHostPort(s.conn.RemoteAddr().String())
+	link, _, err := fs.Link(s.ctx(), reqPath, model.LinkArgs{IP: ip})
+	audit.RecordDownload(s.ctx(), reqPath, obj, err)
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	rc, err := common.OpenLink(link, s.restOffset)
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	defer rc.Close()
+	r := ratelimit.NewReader(s.ctx(), rc, fs.DownloadLimiters(s.ctx(), reqPath)...)
+	s.transfer(func(conn net.Conn) error {
+		_, err := io.Copy(conn, r)
+		return err
+	})
+}
+
+func cmdStor(s *session, arg string) {
+	if !s.user.CanWrite() {
+		s.reply(550, "Permission denied")
+		return
+	}
+	if s.restOffset > 0 {
+		s.reply(550, "Resuming upload is not supported")
+		return
+	}
+	reqPath := s.path(arg)
+	size := s.allocSize
+	if size <= 0 {
+		// the size is unknown if the client doesn't send ALLO, just like the chunked webdav uploading
+		size = -1
+	}
+	s.transfer(func(conn net.Conn) error {
+		// the data connection is streamed into the storage directly
+		stream := &model.FileStream{
+			Obj: &model.Object{
+				Name:     stdpath.Base(reqPath),
+				Size:     size,
+				Modified: time.Now(),
+			},
+			ReadCloser: conn,
+		}
+		err := fs.PutDirectly(s.ctx(), stdpath.Dir(reqPath), stream)
+		fs.ClearCache(stdpath.Dir(reqPath))
+		return err
+	})
+}
+
+func cmdDele(s *session, arg string) {
+	if !s.user.CanRemove() {
+		s.reply(550, "Permission denied")
+		return
+	}
+	reqPath := s.path(arg)
+	obj, err := fs.Get(s.ctx(), reqPath)
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	if obj.IsDir() {
+		s.reply(550, "Not a file")
+		return
+	}
+	s.remove(reqPath)
+}
+
+func cmdRmd(s *session, arg string) {
+	if !s.user.CanRemove() {
+		s.reply(550, "Permission denied")
+		return
+	}
+	reqPath := s.path(arg)
+	obj, err := fs.Get(s.ctx(), reqPath)
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	if !obj.IsDir() {
+		s.reply(550, "Not a directory")
+		return
+	}
+	s.remove(reqPath)
+}
+
+func (s *session) remove(reqPath string) {
+	err := fs.Remove(s.ctx(), reqPath)
+	fs.ClearCache(stdpath.Dir(reqPath))
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	s.reply(250, "Requested file action okay, completed")
+}
+
+func cmdMkd(s *session, arg string) {
+	if !s.user.CanWrite() {
+		s.reply(550, "Permission denied")
+		return
+	}
+	reqPath := s.path(arg)
+	err := fs.MakeDir(s.ctx(), reqPath)
+	fs.ClearCache(stdpath.Dir(reqPath))
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	s.reply(257, quote(s.relPath(arg))+" created")
+}
+
+func cmdRnfr(s *session, arg string) {
+	reqPath := s.path(arg)
+	if _, err := fs.Get(s.ctx(), reqPath); err != nil {
+		s.replyError(err)
+		return
+	}
+	s.renameFrom = reqPath
+	s.reply(350, "Ready for RNTO")
+}
+
+func cmdRnto(s *session, arg string) {
+	if s.renameFrom == "" {
+		s.reply(503, "Use RNFR first")
+		return
+	}
+	src, dst := s.renameFrom, s.path(arg)
+	srcDir, srcName := stdpath.Split(src)
+	dstDir, dstName := stdpath.Split(dst)
+	var err error
+	if srcDir == dstDir {
+		if !s.user.CanRename() {
+			s.reply(550, "Permission denied")
+			return
+		}
+		err = fs.Rename(s.ctx(), src, dstName)
+	} else {
+		if !s.user.CanMove() || (srcName != dstName && !s.user.CanRename()) {
+			s.reply(550, "Permission denied")
+			return
+		}
+		var added bool
+		// the name is kept if the object is moved to another storage by a task
+		added, err = fs.Move(s.ctx(), src, dstDir)
+		if err == nil && !added && srcName != dstName {
+			err = fs.Rename(s.ctx(), stdpath.Join(dstDir, srcName), dstName)
+		}
+	}
+	fs.ClearCache(srcDir)
+	fs.ClearCache(dstDir)
+	if err != nil {
+		s.replyError(err)
+		return
+	}
+	s.reply(250, "Requested file action okay, completed")
+}
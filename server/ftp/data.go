@@ -0,0 +1,183 @@
+package ftp
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/pkg/errors"
+)
+
+const dataTimeout = 30 * time.Second
+
+// listenPasv listen on a port in the passive port range, or a random port if the range is not set
+func (s *session) listenPasv() (net.Listener, error) {
+	s.closePasv()
+	host, _, _ := net.SplitHostPort(s.conn.LocalAddr().String())
+	start, end := conf.Conf.FTP.PasvPortStart, conf.Conf.FTP.PasvPortEnd
+	if start <= 0 || end < start {
+		return net.Listen("tcp", net.JoinHostPort(host, "0"))
+	}
+	for port := start; port <= end; port++ {
+		l, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err == nil {
+			return l, nil
+		}
+	}
+	return nil, errors.Errorf("no available passive port in [%d, %d]", start, end)
+}
+
+func (s *session) closePasv() {
+	if s.pasv != nil {
+		_ = s.pasv.Close()
+		s.pasv = nil
+	}
+}
+
+// pasvHost get the ipv4 that the clients connect to for the passive mode
+func (s *session) pasvHost() net.IP {
+	if host := conf.Conf.FTP.PublicHost; host != "" {
+		if ip := net.ParseIP(host); ip != nil {
+			return ip.To4()
+		}
+		if ips, err := net.LookupIP(host); err == nil {
+			for _, ip := range ips {
+				if ip.To4() != nil {
+					return ip.To4()
+				}
+			}
+		}
+	}
+	if addr, ok := s.conn.LocalAddr().(*net.TCPAddr); ok {
+		return addr.IP.To4()
+	}
+	return nil
+}
+
+func cmdPasv(s *session, arg string) {
+	ip := s.pasvHost()
+	if ip == nil {
+		s.reply(425, "Can't open passive connection, use EPSV instead")
+		return
+	}
+	l, err := s.listenPasv()
+	if err != nil {
+		s.reply(425, err.Error())
+		return
+	}
+	s.pasv, s.activeAddr = l, ""
+	port := l.Addr().(*net.TCPAddr).Port
+	s.reply(227, fmt.Sprintf("Entering Passive Mode (%d,%d,%d,%d,%d,%d)", ip[0], ip[1], ip[2], ip[3], port>>8, port&0xff))
+}
+
+func cmdEpsv(s *session, arg string) {
+	if strings.ToUpper(arg) == "ALL" {
+		s.reply(200, "EPSV ALL command successful")
+		return
+	}
+	l, err := s.listenPasv()
+	if err != nil {
+		s.reply(425, err.Error())
+		return
+	}
+	s.pasv, s.activeAddr = l, ""
+	s.reply(229, fmt.Sprintf("Entering Extended Passive Mode (|||%d|)", l.Addr().(*net.TCPAddr).Port))
+}
+
+// setActive check and set the address of the client for active mode,
+// only the ip of the control connection is allowed to prevent the bounce attack
+func (s *session) setActive(ip net.IP, port int) {
+	remote, ok := s.conn.RemoteAddr().(*net.TCPAddr)
+	if ip == nil || port <= 0 || port > 65535 {
+		s.reply(501, "Syntax error in parameters or arguments")
+		return
+	}
+	if !ok || !remote.IP.Equal(ip) {
+		s.reply(504, "The address must be the same as the control connection")
+		return
+	}
+	s.closePasv()
+	s.activeAddr = net.JoinHostPort(ip.String(), strconv.Itoa(port))
+	s.reply(200, "Command okay")
+}
+
+func cmdPort(s *session, arg string) {
+	parts := strings.Split(arg, ",")
+	if len(parts) != 6 {
+		s.reply(501, "Syntax error in parameters or arguments")
+		return
+	}
+	nums := make([]int, 6)
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 0 || n > 255 {
+			s.reply(501, "Syntax error in parameters or arguments")
+			return
+		}
+		nums[i] = n
+	}
+	s.setActive(net.IPv4(byte(nums[0]), byte(nums[1]), byte(nums[2]), byte(nums[3])), nums[4]<<8|nums[5])
+}
+
+// cmdEprt handle the EPRT command like `EPRT |2|::1|1234|`
+func cmdEprt(s *session, arg string) {
+	if len(arg) < 2 {
+		s.reply(501, "Syntax error in parameters or arguments")
+		return
+	}
+	parts := strings.Split(arg[1:len(arg)-1], arg[:1])
+	if len(parts) != 3 {
+		s.reply(501, "Syntax error in parameters or arguments")
+		return
+	}
+	port, _ := strconv.Atoi(parts[2])
+	s.setActive(net.ParseIP(parts[1]), port)
+}
+
+// openDataConn open the data connection that set by the last PASV/EPSV/PORT/EPRT
+func (s *session) openDataConn() (net.Conn, error) {
+	var conn net.Conn
+	if s.pasv != nil {
+		l := s.pasv
+		s.pasv = nil
+		defer l.Close()
+		if tl, ok := l.(*net.TCPListener); ok {
+			_ = tl.SetDeadline(time.Now().Add(dataTimeout))
+		}
+		c, err := l.Accept()
+		if err != nil {
+			return nil, err
+		}
+		// only the client of the control connection is allowed to connect
+		remote, _ := s.conn.RemoteAddr().(*net.TCPAddr)
+		dataRemote, _ := c.RemoteAddr().(*net.TCPAddr)
+		if remote == nil || dataRemote == nil || !remote.IP.Equal(dataRemote.IP) {
+			_ = c.Close()
+			return nil, errors.New("the data connection is not from the client")
+		}
+		conn = c
+	} else if s.activeAddr != "" {
+		c, err := net.DialTimeout("tcp", s.activeAddr, dataTimeout)
+		if err != nil {
+			return nil, err
+		}
+		conn = c
+	} else {
+		return nil, errors.New("use PORT or PASV first")
+	}
+	if s.protected {
+		tc := tls.Server(conn, s.server.tlsConfig)
+		_ = tc.SetDeadline(time.Now().Add(dataTimeout))
+		if err := tc.Handshake(); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		_ = tc.SetDeadline(time.Time{})
+		conn = tc
+	}
+	return conn, nil
+}
@@ -0,0 +1,53 @@
+package ftp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+// formatList format the obj like `ls -l` for the LIST command
+func formatList(obj model.Obj) string {
+	mode := "-rw-r--r--"
+	if obj.IsDir() {
+		mode = "drwxr-xr-x"
+	}
+	modified := obj.ModTime()
+	layout := "Jan _2 15:04"
+	if time.Since(modified) > 180*24*time.Hour || modified.After(time.Now()) {
+		layout = "Jan _2  2006"
+	}
+	return fmt.Sprintf("%s 1 alist alist %12d %s %s", mode, obj.GetSize(), modified.Format(layout), obj.GetName())
+}
+
+// formatFacts format the obj for the MLSD and MLST commands, see RFC 3659
+func formatFacts(obj model.Obj, name string) string {
+	var b strings.Builder
+	if obj.IsDir() {
+		b.WriteString("type=dir;")
+	} else {
+		b.WriteString("type=file;")
+		fmt.Fprintf(&b, "size=%d;", obj.GetSize())
+	}
+	if !obj.ModTime().IsZero() {
+		fmt.Fprintf(&b, "modify=%s;", formatTime(obj.ModTime()))
+	}
+	b.WriteString(" ")
+	b.WriteString(name)
+	return b.String()
+}
+
+// formatTime format the time for the MDTM command and the modify fact
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102150405")
+}
+
+// listArg get the path of the LIST and NLST command, the options like `-la` are ignored
+func listArg(arg string) string {
+	for strings.HasPrefix(arg, "-") {
+		_, arg, _ = strings.Cut(arg, " ")
+	}
+	return arg
+}
@@ -0,0 +1,70 @@
+package ftp
+
+import (
+	"crypto/tls"
+	"net"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrServerClosed = errors.New("ftp: Server closed")
+
+// Server serve the mounted tree with ftp, the users login with their alist username and password,
+// the clients can upgrade the connections to FTPS with AUTH TLS if it's enabled
+type Server struct {
+	tlsConfig *tls.Config
+	mu        sync.Mutex
+	listener  net.Listener
+	closed    bool
+}
+
+func NewServer() (*Server, error) {
+	s := &Server{}
+	if conf.Conf.FTP.TLS {
+		cert, err := tls.LoadX509KeyPair(conf.Conf.Scheme.CertFile, conf.Conf.Scheme.KeyFile)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to load the cert for ftps")
+		}
+		s.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	return s, nil
+}
+
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	s.listener = l
+	s.mu.Unlock()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		go func() {
+			defer func() {
+				if e := recover(); e != nil {
+					log.Errorf("ftp session of %s panic: %+v", conn.RemoteAddr(), e)
+				}
+			}()
+			newSession(s, conn).serve()
+		}()
+	}
+}
+
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
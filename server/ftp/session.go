@@ -0,0 +1,149 @@
+package ftp
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// the connections are closed after idle for this duration
+const idleTimeout = 15 * time.Minute
+
+type session struct {
+	server *Server
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+
+	username string
+	user     *model.User
+	// the current dir, relative to the base path of the user
+	cwd string
+
+	// the state set by the previous commands
+	restOffset int64
+	allocSize  int64
+	renameFrom string
+
+	// passive listener or the address of the client for active mode
+	pasv       net.Listener
+	activeAddr string
+
+	// the control connection is upgraded with AUTH TLS
+	tls bool
+	// the data connections are protected with PROT P
+	protected bool
+	quit      bool
+}
+
+func newSession(server *Server, conn net.Conn) *session {
+	s := &session{server: server, cwd: "/"}
+	s.setConn(conn)
+	return s
+}
+
+func (s *session) setConn(conn net.Conn) {
+	s.conn = conn
+	s.reader = bufio.NewReader(conn)
+	s.writer = bufio.NewWriter(conn)
+}
+
+func (s *session) serve() {
+	defer func() {
+		s.closePasv()
+		_ = s.conn.Close()
+	}()
+	s.reply(220, "Welcome to AList FTP server")
+	for !s.quit {
+		_ = s.conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd, arg := parseLine(line)
+		if cmd == "PASS" {
+			log.Debugf("ftp %s: PASS ***", s.conn.RemoteAddr())
+		} else {
+			log.Debugf("ftp %s: %s %s", s.conn.RemoteAddr(), cmd, arg)
+		}
+		s.handle(cmd, arg)
+	}
+}
+
+func parseLine(line string) (string, string) {
+	line = strings.TrimRight(line, "\r\n")
+	cmd, arg, _ := strings.Cut(line, " ")
+	return strings.ToUpper(cmd), arg
+}
+
+func (s *session) handle(cmd, arg string) {
+	c, ok := commands[cmd]
+	if !ok {
+		s.reply(502, "Command not implemented")
+		return
+	}
+	if c.needLogin && s.user == nil {
+		s.reply(530, "Please login with USER and PASS")
+		return
+	}
+	if c.needArg && arg == "" {
+		s.reply(501, "Syntax error in parameters or arguments")
+		return
+	}
+	c.fn(s, arg)
+	// REST, ALLO and RNFR only affect the next command
+	if cmd != "REST" {
+		s.restOffset = 0
+	}
+	if cmd != "ALLO" && cmd != "REST" {
+		s.allocSize = 0
+	}
+	if cmd != "RNFR" {
+		s.renameFrom = ""
+	}
+}
+
+func (s *session) reply(code int, msg string) {
+	_, _ = fmt.Fprintf(s.writer, "%d %s\r\n", code, msg)
+	_ = s.writer.Flush()
+}
+
+// replyLines reply a multi-line response, the lines are prefixed with a space
+func (s *session) replyLines(code int, first string, lines []string, last string) {
+	_, _ = fmt.Fprintf(s.writer, "%d-%s\r\n", code, first)
+	for _, line := range lines {
+		_, _ = fmt.Fprintf(s.writer, " %s\r\n", line)
+	}
+	s.reply(code, last)
+}
+
+// path get the path in alist of the path in the command
+func (s *session) path(p string) string {
+	return stdpath.Join(s.user.BasePath, s.relPath(p))
+}
+
+// relPath get the path relative to the base path of the user,
+// the `..` can't go up beyond the root
+func (s *session) relPath(p string) string {
+	if !stdpath.IsAbs(p) {
+		p = stdpath.Join(s.cwd, p)
+	}
+	return stdpath.Clean(p)
+}
+
+func (s *session) upgradeTLS() error {
+	conn := tls.Server(s.conn, s.server.tlsConfig)
+	if err := conn.Handshake(); err != nil {
+		return err
+	}
+	s.setConn(conn)
+	s.tls = true
+	return nil
+}
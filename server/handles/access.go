@@ -0,0 +1,63 @@
+package handles
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type AccessReportReq struct {
+	Start *time.Time `json:"start" form:"start"`
+	End   *time.Time `json:"end" form:"end"`
+	Link  string     `json:"link" form:"link"` // direct or share, all the links if it's empty
+	Limit int        `json:"limit" form:"limit"`
+}
+
+type AccessReportResp struct {
+	TopFiles   []model.AccessStat `json:"top_files"`
+	TopClients []model.AccessStat `json:"top_clients"`
+	TopShares  []model.AccessStat `json:"top_shares"` // the names are the ids of the shares
+	Daily      []model.AccessStat `json:"daily"`
+}
+
+// AccessReport report the downloads in the range, the last 30 days by default
+func AccessReport(c *gin.Context) {
+	var req AccessReportReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	end := time.Now()
+	if req.End != nil {
+		end = *req.End
+	}
+	start := end.AddDate(0, 0, -30)
+	if req.Start != nil {
+		start = *req.Start
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 10
+	}
+	var resp AccessReportResp
+	var err error
+	if resp.TopFiles, err = db.GetAccessStats("path", req.Link, start, end, req.Limit); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if resp.TopClients, err = db.GetAccessStats("ip", req.Link, start, end, req.Limit); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if resp.TopShares, err = db.GetAccessStats("share_id", model.AccessShare, start, end, req.Limit); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if resp.Daily, err = db.GetAccessStats("day", req.Link, start, end, 0); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, resp)
+}
@@ -0,0 +1,94 @@
+package handles
+
+import (
+	stdpath "path"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+func ListAclRules(c *gin.Context) {
+	userId, err := strconv.Atoi(c.Query("user_id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	rules, err := db.GetAclRulesByUserId(uint(userId))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, rules)
+}
+
+// checkAclRule standardize the path of the rule and check the action and the glob
+func checkAclRule(r *model.AclRule) error {
+	switch r.Action {
+	case model.AclRead, model.AclWrite, model.AclDelete, model.AclShare:
+	default:
+		return errors.Errorf("invalid action: %s", r.Action)
+	}
+	r.Path = utils.StandardizePath(r.Path)
+	if _, err := stdpath.Match(r.Path, "/"); err != nil {
+		return errors.Wrapf(err, "invalid path: %s", r.Path)
+	}
+	if _, err := db.GetUserById(r.UserID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func CreateAclRule(c *gin.Context) {
+	var req model.AclRule
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := checkAclRule(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := db.CreateAclRule(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c, gin.H{
+			"id": req.ID,
+		})
+	}
+}
+
+func UpdateAclRule(c *gin.Context) {
+	var req model.AclRule
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := checkAclRule(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := db.UpdateAclRule(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func DeleteAclRule(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := db.DeleteAclRuleById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
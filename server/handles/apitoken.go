@@ -0,0 +1,85 @@
+package handles
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// ListApiTokens list the api tokens of the current user
+func ListApiTokens(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	tokens, err := db.GetApiTokensByUserId(user.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, tokens)
+}
+
+type CreateApiTokenReq struct {
+	Name      string     `json:"name" binding:"required"`
+	Paths     string     `json:"paths"`
+	Scope     string     `json:"scope"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreateApiToken create an api token for the current user, the token is only returned once
+func CreateApiToken(c *gin.Context) {
+	var req CreateApiTokenReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "Guest user can not create api tokens", 403)
+		return
+	}
+	switch req.Scope {
+	case "":
+		req.Scope = model.ApiTokenFull
+	case model.ApiTokenFull, model.ApiTokenRead, model.ApiTokenUpload:
+	default:
+		common.ErrorStrResp(c, "invalid scope: "+req.Scope, 400)
+		return
+	}
+	token := common.ApiTokenPrefix + random.SecureString(40)
+	t := &model.ApiToken{
+		UserID:    user.ID,
+		Name:      req.Name,
+		Hash:      utils.GetSHA256Encode(token),
+		Paths:     req.Paths,
+		Scope:     req.Scope,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := db.CreateApiToken(t); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"id":    t.ID,
+		"token": token,
+	})
+}
+
+func DeleteApiToken(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if err := db.DeleteApiToken(user.ID, uint(id)); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -0,0 +1,70 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// ListAppPasswords list the app passwords of the current user
+func ListAppPasswords(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	ps, err := db.GetAppPasswordsByUserId(user.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, ps)
+}
+
+type CreateAppPasswordReq struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// CreateAppPassword create an app password for the current user, the password is only returned once
+func CreateAppPassword(c *gin.Context) {
+	var req CreateAppPasswordReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "Guest user can not create app passwords", 403)
+		return
+	}
+	password := random.SecureString(24)
+	p := &model.AppPassword{
+		UserID: user.ID,
+		Name:   req.Name,
+		Hash:   utils.GetSHA256Encode(password),
+	}
+	if err := db.CreateAppPassword(p); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"id":       p.ID,
+		"password": password,
+	})
+}
+
+func DeleteAppPassword(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if err := db.DeleteAppPassword(user.ID, uint(id)); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -0,0 +1,32 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditLogReq struct {
+	common.PageReq
+	model.AuditQuery
+}
+
+// ListAuditLogs query the audit logs, the latest first
+func ListAuditLogs(c *gin.Context) {
+	var req AuditLogReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	logs, total, err := db.GetAuditLogs(req.AuditQuery, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: logs,
+		Total:   total,
+	})
+}
@@ -4,20 +4,22 @@ import (
 	"bytes"
 	"encoding/base64"
 	"image/png"
-	"time"
+	"math"
+	"strconv"
+	"strings"
 
-	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/event"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/throttle"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
 	"github.com/pquerna/otp/totp"
-)
-
-var loginCache = cache.NewMemCache[int]()
-var (
-	defaultDuration = time.Minute * 5
-	defaultTimes    = 5
+	log "github.com/sirupsen/logrus"
 )
 
 type LoginReq struct {
@@ -26,13 +28,27 @@ type LoginReq struct {
 	OtpCode  string `json:"otp_code"`
 }
 
+// loginFailed count the failed login of the ip, the ip is banned for a while if it fails too many times
+func loginFailed(ip, username string) {
+	throttle.Fail(throttle.KindLogin, ip)
+	event.PublishLoginFailed(username, ip, "web")
+}
+
+// loginBanned check if the ip is banned for failing too many times, it's responded with 429 if so
+func loginBanned(c *gin.Context, ip string) bool {
+	left := throttle.Banned(throttle.KindLogin, ip)
+	if left <= 0 {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(left.Seconds()))))
+	common.ErrorStrResp(c, "Too many unsuccessful attempts have been made, Try again later.", 429)
+	return true
+}
+
 func Login(c *gin.Context) {
 	// check count of login
 	ip := c.ClientIP()
-	count, ok := loginCache.Get(ip)
-	if ok && count >= defaultTimes {
-		common.ErrorStrResp(c, "Too many unsuccessful sign-in attempts have been made using an incorrect username or password, Try again later.", 429)
-		loginCache.Expire(ip, defaultDuration)
+	if loginBanned(c, ip) {
 		return
 	}
 	// check username
@@ -44,22 +60,29 @@ func Login(c *gin.Context) {
 	user, err := db.GetUserByName(req.Username)
 	if err != nil {
 		common.ErrorResp(c, err, 400)
-		loginCache.Set(ip, count+1)
+		loginFailed(ip, req.Username)
 		return
 	}
 	// validate password
 	if err := user.ValidatePassword(req.Password); err != nil {
 		common.ErrorResp(c, err, 400)
-		loginCache.Set(ip, count+1)
+		loginFailed(ip, req.Username)
+		return
+	}
+	if !user.AllowIP(ip) {
+		common.ErrorStrResp(c, "You can't sign in from this ip", 403)
 		return
 	}
 	// check 2FA
 	if user.OtpSecret != "" {
-		if !totp.Validate(req.OtpCode, user.OtpSecret) {
+		if !validateOtp(user, req.OtpCode) {
 			common.ErrorStrResp(c, "Invalid 2FA code", 402)
-			loginCache.Set(ip, count+1)
+			loginFailed(ip, req.Username)
 			return
 		}
+	} else if user.IsAdmin() && setting.GetBool(conf.AdminRequire2FA) {
+		common.ErrorStrResp(c, "2FA is required for the admin", 403)
+		return
 	}
 	// generate token
 	token, err := common.GenerateToken(user.Username)
@@ -68,7 +91,7 @@ func Login(c *gin.Context) {
 		return
 	}
 	common.SuccessResp(c, gin.H{"token": token})
-	loginCache.Del(ip)
+	throttle.Reset(throttle.KindLogin, ip)
 }
 
 type UserResp struct {
@@ -96,7 +119,12 @@ func UpdateCurrent(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	user := c.MustGet("user").(*model.User)
+	// the user in ctx is the effective one, so get the stored one to update
+	user, err := db.GetUserById(c.MustGet("user").(*model.User).ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
 	user.Username = req.Username
 	if req.Password != "" {
 		user.Password = req.Password
@@ -157,10 +185,73 @@ func Verify2FA(c *gin.Context) {
 		common.ErrorStrResp(c, "Invalid 2FA code", 400)
 		return
 	}
-	user.OtpSecret = req.Secret
+	user, err := db.GetUserById(user.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	user.OtpSecret = model.EncryptedString(req.Secret)
+	codes, hashes := newRecoveryCodes()
+	user.OtpRecoveryCodes = hashes
 	if err := db.UpdateUser(user); err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
-		common.SuccessResp(c)
+		common.SuccessResp(c, gin.H{"recovery_codes": codes})
+	}
+}
+
+// validateOtp check the code of 2FA, it can be one of the recovery codes too
+func validateOtp(user *model.User, code string) bool {
+	if totp.Validate(code, string(user.OtpSecret)) {
+		return true
+	}
+	ok, err := db.UseRecoveryCode(user.ID, code)
+	if err != nil {
+		log.Errorf("failed use recovery code: %+v", err)
+	}
+	return ok
+}
+
+// newRecoveryCodes return the recovery codes and the sha256 of them to store
+func newRecoveryCodes() ([]string, string) {
+	codes := make([]string, 10)
+	hashes := make([]string, 10)
+	for i := range codes {
+		codes[i] = random.SecureString(10)
+		hashes[i] = utils.GetSHA256Encode(codes[i])
+	}
+	return codes, strings.Join(hashes, ",")
+}
+
+type RecoveryCodesReq struct {
+	OtpCode string `json:"otp_code" binding:"required"`
+}
+
+// RegenerateRecoveryCodes replace the recovery codes of 2FA, the code of 2FA is required
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var req RecoveryCodesReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user, err := db.GetUserById(c.MustGet("user").(*model.User).ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if user.OtpSecret == "" {
+		common.ErrorStrResp(c, "2FA is not enabled", 400)
+		return
+	}
+	if !totp.Validate(req.OtpCode, string(user.OtpSecret)) {
+		common.ErrorStrResp(c, "Invalid 2FA code", 400)
+		return
+	}
+	codes, hashes := newRecoveryCodes()
+	user.OtpRecoveryCodes = hashes
+	if err := db.UpdateUser(user); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c, gin.H{"recovery_codes": codes})
 	}
 }
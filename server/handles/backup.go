@@ -0,0 +1,71 @@
+package handles
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/backup"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/alist-org/alist/v3/server/static"
+	"github.com/gin-gonic/gin"
+)
+
+type ExportBackupReq struct {
+	// the secrets are encrypted with it if set
+	Password string `json:"password"`
+}
+
+// ExportBackup download the bundle of the settings, the users with their groups and acl rules, the storages and the metas
+func ExportBackup(c *gin.Context) {
+	var req ExportBackupReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	b, err := backup.Export(req.Password)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="alist-backup-%s.json"`, time.Now().Format("20060102150405")))
+	c.JSON(200, b)
+}
+
+// ImportBackup restore the bundle uploaded in the file field of the form,
+// the storages changed are initialized again
+func ImportBackup(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	defer f.Close()
+	var b backup.Bundle
+	if err := utils.Json.NewDecoder(f).Decode(&b); err != nil {
+		common.ErrorStrResp(c, "invalid backup: "+err.Error(), 400)
+		return
+	}
+	res, err := backup.Import(&b, c.PostForm("password"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	storages, err := op.ReloadStorages(c)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	// the settings of the site may be changed
+	static.UpdateIndex()
+	common.SuccessResp(c, gin.H{
+		"imported": res,
+		"storages": storages,
+	})
+}
@@ -0,0 +1,48 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/checksum"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// BuildManifest walk the path and return the manifest of the hashes of the files
+func BuildManifest(c *gin.Context) {
+	var req checksum.Args
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Path = utils.StandardizePath(req.Path)
+	manifest, err := checksum.Build(c, req)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, manifest)
+}
+
+type VerifyManifestReq struct {
+	Manifest checksum.Manifest `json:"manifest" binding:"required"`
+	// verify the files in another path instead of the path of the manifest
+	Path string `json:"path"`
+}
+
+// VerifyManifest check the files with the manifest got by BuildManifest
+func VerifyManifest(c *gin.Context) {
+	var req VerifyManifestReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.Path != "" {
+		req.Path = utils.StandardizePath(req.Path)
+	}
+	report, err := checksum.Verify(c, &req.Manifest, req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, report)
+}
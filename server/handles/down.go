@@ -5,10 +5,13 @@ import (
 	stdpath "path"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/diskcache"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
 	"github.com/alist-org/alist/v3/internal/sign"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
@@ -27,11 +30,12 @@ func Down(c *gin.Context) {
 		Proxy(c)
 		return
 	} else {
-		link, _, err := fs.Link(c, rawPath, model.LinkArgs{
+		link, file, err := fs.Link(c, rawPath, model.LinkArgs{
 			IP:     c.ClientIP(),
 			Header: c.Request.Header,
 			Type:   c.Query("type"),
 		})
+		audit.RecordDownload(c, rawPath, file, err)
 		if err != nil {
 			common.ErrorResp(c, err, 500)
 			return
@@ -49,6 +53,10 @@ func Proxy(c *gin.Context) {
 		return
 	}
 	if canProxy(storage, filename) {
+		if err := fs.CheckDownloadQuota(c); err != nil {
+			common.ErrorResp(c, err, 429)
+			return
+		}
 		downProxyUrl := storage.GetStorage().DownProxyUrl
 		if downProxyUrl != "" {
 			_, ok := c.GetQuery("d")
@@ -56,7 +64,7 @@ func Proxy(c *gin.Context) {
 				URL := fmt.Sprintf("%s%s?sign=%s",
 					strings.Split(downProxyUrl, "\n")[0],
 					utils.EncodePath(rawPath, true),
-					sign.Sign(filename))
+					sign.Sign(rawPath, ""))
 				c.Redirect(302, URL)
 				return
 			}
@@ -65,11 +73,13 @@ func Proxy(c *gin.Context) {
 			Header: c.Request.Header,
 			Type:   c.Query("type"),
 		})
+		audit.RecordDownload(c, rawPath, file, err)
 		if err != nil {
 			common.ErrorResp(c, err, 500)
 			return
 		}
-		err = common.Proxy(c.Writer, c.Request, link, file)
+		w := ratelimit.NewResponseWriter(c, c.Writer, fs.DownloadLimiters(c, rawPath)...)
+		err = common.Proxy(w, c.Request, diskcache.Wrap(rawPath, link, file), file)
 		if err != nil {
 			common.ErrorResp(c, err, 500, true)
 			return
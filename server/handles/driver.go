@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/plugin"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
 )
@@ -26,3 +27,8 @@ func GetDriverInfo(c *gin.Context) {
 	}
 	common.SuccessResp(c, items)
 }
+
+// ListPlugins list the plugins loaded and the drivers they serve
+func ListPlugins(c *gin.Context) {
+	common.SuccessResp(c, plugin.GetPlugins())
+}
@@ -0,0 +1,197 @@
+package handles
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/throttle"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+func ListFileDrops(c *gin.Context) {
+	var req common.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	drops, total, err := db.GetFileDrops(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: drops,
+		Total:   total,
+	})
+}
+
+func CreateFileDrop(c *gin.Context) {
+	var req model.FileDrop
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.ID = 0
+	req.Key = random.SecureString(16)
+	req.CreatorID = c.MustGet("user").(*model.User).ID
+	req.Path = utils.StandardizePath(req.Path)
+	if err := db.CreateFileDrop(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c, req)
+	}
+}
+
+func UpdateFileDrop(c *gin.Context) {
+	var req model.FileDrop
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	old, err := db.GetFileDropById(req.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	// the link and the creator can't be changed
+	req.Key, req.CreatorID, req.CreatedAt = old.Key, old.CreatorID, old.CreatedAt
+	req.Path = utils.StandardizePath(req.Path)
+	if err := db.UpdateFileDrop(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func DeleteFileDrop(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := db.DeleteFileDropById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// getFileDrop get the available file drop of the key in query
+func getFileDrop(c *gin.Context) (*model.FileDrop, bool) {
+	drop, err := db.GetFileDropByKey(c.Query("key"))
+	if err != nil || !drop.Available() {
+		common.ErrorStrResp(c, "the file drop doesn't exist or is expired", 404)
+		return nil, false
+	}
+	return drop, true
+}
+
+type FileDropInfoResp struct {
+	Remark       string     `json:"remark"`
+	MaxFileSize  int64      `json:"max_file_size"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	NeedPassword bool       `json:"need_password"`
+}
+
+// FileDropInfo return the info of the file drop for the anonymous users, the path isn't exposed
+func FileDropInfo(c *gin.Context) {
+	drop, ok := getFileDrop(c)
+	if !ok {
+		return
+	}
+	common.SuccessResp(c, FileDropInfoResp{
+		Remark:       drop.Remark,
+		MaxFileSize:  drop.MaxFileSize,
+		ExpiresAt:    drop.ExpiresAt,
+		NeedPassword: drop.Password != "",
+	})
+}
+
+// FileDropUpload upload a file into the dir of the file drop anonymously,
+// the file is renamed if there is one with the same name, so nothing is overwritten
+func FileDropUpload(c *gin.Context) {
+	ip := c.ClientIP()
+	if loginBanned(c, ip) {
+		return
+	}
+	drop, ok := getFileDrop(c)
+	if !ok {
+		return
+	}
+	if drop.Password != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader("Password")), []byte(drop.Password)) != 1 {
+		common.ErrorStrResp(c, "password is incorrect", 403)
+		throttle.Fail(throttle.KindLogin, ip)
+		return
+	}
+	name, err := url.PathUnescape(c.GetHeader("File-Name"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\\") {
+		common.ErrorStrResp(c, "invalid file name", 400)
+		return
+	}
+	size, err := strconv.ParseInt(c.GetHeader("Content-Length"), 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if drop.MaxFileSize > 0 && size > drop.MaxFileSize {
+		common.ErrorStrResp(c, fmt.Sprintf("the file is larger than %d bytes", drop.MaxFileSize), 413)
+		return
+	}
+	creator, err := db.GetUserById(drop.CreatorID)
+	if err == nil {
+		creator, err = db.GetUserByName(creator.Username)
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	c.Set("user", creator)
+	name = uniqueName(c, drop.Path, name)
+	stream := &model.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     size,
+			Modified: time.Now(),
+		},
+		ReadCloser: http.MaxBytesReader(c.Writer, c.Request.Body, size),
+		Mimetype:   c.GetHeader("Content-Type"),
+	}
+	if err := fs.PutDirectly(c, drop.Path, stream); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	log.Infof("file drop %d: %s is uploaded by %s", drop.ID, name, ip)
+	common.SuccessResp(c, gin.H{"name": name})
+}
+
+// uniqueName add a number to the name if it exists in the dir, e.g. a (1).txt
+func uniqueName(c *gin.Context, dir, name string) string {
+	ext := stdpath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; i < 1000; i++ {
+		if _, err := fs.Get(c, stdpath.Join(dir, name)); err != nil {
+			return name
+		}
+		name = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+	return fmt.Sprintf("%s (%d)%s", base, time.Now().UnixNano(), ext)
+}
@@ -0,0 +1,197 @@
+package handles
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	stdpath "path"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/archive"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type ArchiveListReq struct {
+	common.PageReq
+	Path      string `json:"path" form:"path"`
+	Password  string `json:"password" form:"password"`
+	InnerPath string `json:"inner_path" form:"inner_path"`
+}
+
+type ArchiveListResp struct {
+	Content []ObjResp `json:"content"`
+	Total   int64     `json:"total"`
+	// the sign of the archive to download the entries with /ap
+	Sign string `json:"sign"`
+}
+
+// FsArchiveList list the dir in the archive as a folder
+func FsArchiveList(c *gin.Context) {
+	var req ArchiveListReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	req.Path = stdpath.Join(user.BasePath, req.Path)
+	meta, err := db.GetNearestMeta(req.Path)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	c.Set("meta", meta)
+	if !canAccess(user, meta, req.Path, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect", 403)
+		return
+	}
+	if !archive.Supported(stdpath.Base(req.Path)) {
+		common.ErrorResp(c, errs.ArchiveNotSupported, 400)
+		return
+	}
+	objs, err := archive.List(c, req.Path, req.InnerPath)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	total, objs := pagination(objs, &req.PageReq)
+	resp := ArchiveListResp{
+		Content: toObjResp(c, "", objs, false),
+		Total:   int64(total),
+	}
+	if isEncrypt(meta, req.Path) {
+		resp.Sign = sign.Sign(req.Path, c.ClientIP())
+	}
+	common.SuccessResp(c, resp)
+}
+
+// ArchiveProxy download the file of the `inner` path in the archive, `archive_pass` is
+// the password of the encrypted files
+func ArchiveProxy(c *gin.Context) {
+	rawPath := c.MustGet("path").(string)
+	if err := fs.CheckDownloadQuota(c); err != nil {
+		common.ErrorResp(c, err, 429)
+		return
+	}
+	entry, rc, err := archive.Open(c, rawPath, c.Query("inner"), c.Query("archive_pass"))
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	defer rc.Close()
+	name := stdpath.Base(entry.Path)
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, name, url.QueryEscape(name)))
+	c.Header("Content-Length", strconv.FormatInt(entry.Size, 10))
+	w := ratelimit.NewResponseWriter(c, c.Writer, fs.DownloadLimiters(c, rawPath)...)
+	if _, err := io.Copy(w, rc); err != nil {
+		log.Errorf("failed to read [%s] in [%s]: %+v", entry.Path, rawPath, err)
+	}
+}
+
+type ArchiveExtractReq struct {
+	Path      string `json:"path"`
+	Password  string `json:"password"`
+	InnerPath string `json:"inner_path"`
+	DstDir    string `json:"dst_dir"`
+	// the password of the encrypted files in the archive
+	ArchivePass string `json:"archive_pass"`
+}
+
+// FsArchiveExtract add the task extracting the archive to the dst dir
+func FsArchiveExtract(c *gin.Context) {
+	var req ArchiveExtractReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	req.Path = stdpath.Join(user.BasePath, req.Path)
+	req.DstDir = stdpath.Join(user.BasePath, req.DstDir)
+	meta, err := db.GetNearestMeta(req.Path)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if !canAccess(user, meta, req.Path, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect", 403)
+		return
+	}
+	if !user.CanWrite() {
+		dstMeta, err := db.GetNearestMeta(req.DstDir)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		if !canWrite(dstMeta, req.DstDir) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+	}
+	if err := archive.Extract(c, req.Path, req.InnerPath, req.DstDir, req.ArchivePass); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+type ArchiveCompressReq struct {
+	SrcDir   string   `json:"src_dir"`
+	Names    []string `json:"names"`
+	Password string   `json:"password"`
+	// the path of the archive created, the format is decided by the ext
+	DstPath string `json:"dst_path"`
+}
+
+// FsArchiveCompress add the task packing the objects to an archive
+func FsArchiveCompress(c *gin.Context) {
+	var req ArchiveCompressReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if len(req.Names) == 0 {
+		common.ErrorStrResp(c, "empty file names", 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	req.SrcDir = stdpath.Join(user.BasePath, req.SrcDir)
+	req.DstPath = stdpath.Join(user.BasePath, req.DstPath)
+	meta, err := db.GetNearestMeta(req.SrcDir)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	c.Set("meta", meta)
+	if !canAccess(user, meta, req.SrcDir, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect", 403)
+		return
+	}
+	if !user.CanWrite() {
+		dstMeta, err := db.GetNearestMeta(stdpath.Dir(req.DstPath))
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		if !canWrite(dstMeta, stdpath.Dir(req.DstPath)) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+	}
+	if err := archive.Compress(c, req.SrcDir, req.Names, req.DstPath); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
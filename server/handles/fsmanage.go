@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
@@ -81,16 +82,18 @@ func FsMove(c *gin.Context) {
 	}
 	req.SrcDir = stdpath.Join(user.BasePath, req.SrcDir)
 	req.DstDir = stdpath.Join(user.BasePath, req.DstDir)
-	for _, name := range req.Names {
-		err := fs.Move(c, stdpath.Join(req.SrcDir, name), req.DstDir)
-		if err != nil {
-			common.ErrorResp(c, err, 500)
-			return
-		}
+	addedTasks, err := fs.BatchMove(c, req.SrcDir, req.Names, req.DstDir)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if addedTasks > 0 {
+		common.SuccessResp(c, fmt.Sprintf("Added %d tasks", addedTasks))
+	} else {
+		fs.ClearCache(req.SrcDir)
+		fs.ClearCache(req.DstDir)
+		common.SuccessResp(c)
 	}
-	fs.ClearCache(req.SrcDir)
-	fs.ClearCache(req.DstDir)
-	common.SuccessResp(c)
 }
 
 func FsCopy(c *gin.Context) {
@@ -110,23 +113,15 @@ func FsCopy(c *gin.Context) {
 	}
 	req.SrcDir = stdpath.Join(user.BasePath, req.SrcDir)
 	req.DstDir = stdpath.Join(user.BasePath, req.DstDir)
-	var addedTask []string
-	for _, name := range req.Names {
-		ok, err := fs.Copy(c, stdpath.Join(req.SrcDir, name), req.DstDir)
-		if ok {
-			addedTask = append(addedTask, name)
-		}
-		if err != nil {
-			common.ErrorResp(c, err, 500)
-			return
-		}
-	}
-	if len(req.Names) != len(addedTask) {
-		fs.ClearCache(req.DstDir)
+	addedTasks, err := fs.BatchCopy(c, req.SrcDir, req.Names, req.DstDir)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
 	}
-	if len(addedTask) > 0 {
-		common.SuccessResp(c, fmt.Sprintf("Added %d tasks", len(addedTask)))
+	if addedTasks > 0 {
+		common.SuccessResp(c, fmt.Sprintf("Added %d tasks", addedTasks))
 	} else {
+		fs.ClearCache(req.DstDir)
 		common.SuccessResp(c)
 	}
 }
@@ -177,12 +172,9 @@ func FsRemove(c *gin.Context) {
 		return
 	}
 	req.Dir = stdpath.Join(user.BasePath, req.Dir)
-	for _, name := range req.Names {
-		err := fs.Remove(c, stdpath.Join(req.Dir, name))
-		if err != nil {
-			common.ErrorResp(c, err, 500)
-			return
-		}
+	if err := fs.BatchRemove(c, req.Dir, req.Names); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
 	}
 	//fs.ClearCache(req.Dir)
 	common.SuccessResp(c)
@@ -228,12 +220,24 @@ func FsPut(c *gin.Context) {
 		ReadCloser:   c.Request.Body,
 		Mimetype:     c.GetHeader("Content-Type"),
 		WebPutAsTask: asTask,
+		// the client can supply the sha1 for the storages support rapid upload
+		Sha1: c.GetHeader("File-Sha1"),
 	}
 	if asTask {
-		err = fs.PutAsTask(dir, stream)
+		// the task has no ctx of the user, so check the acl here
+		if err := fs.CheckAcl(c, dir, model.AclWrite); err != nil {
+			common.ErrorResp(c, err, 403)
+			return
+		}
+		err = fs.PutAsTask(c, dir, stream)
+		audit.RecordWith(c, model.AuditUpload, stdpath.Join(dir, name), "", size, err)
 	} else {
 		err = fs.PutDirectly(c, dir, stream)
 	}
+	if errors.Is(err, errs.TrafficQuotaExceeded) {
+		common.ErrorResp(c, err, 429)
+		return
+	}
 	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
@@ -260,7 +264,7 @@ func Link(c *gin.Context) {
 			URL: fmt.Sprintf("%s/p%s?d&sign=%s",
 				common.GetApiUrl(c.Request),
 				utils.EncodePath(req.Path, true),
-				sign.Sign(stdpath.Base(rawPath))),
+				sign.Sign(req.Path, c.ClientIP())),
 		})
 		return
 	}
@@ -0,0 +1,51 @@
+package handles
+
+import (
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/media"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type FsMediaInfoReq struct {
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+}
+
+// FsMediaInfo get the exif of the image, or the duration, the resolution and the codecs of the video
+func FsMediaInfo(c *gin.Context) {
+	var req FsMediaInfoReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	req.Path = stdpath.Join(user.BasePath, req.Path)
+	meta, err := db.GetNearestMeta(req.Path)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	c.Set("meta", meta)
+	if !canAccess(user, meta, req.Path, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect", 403)
+		return
+	}
+	info, err := media.Get(c, req.Path)
+	if err != nil {
+		if errors.Is(errors.Cause(err), media.ErrNotSupported) {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, info)
+}
@@ -2,10 +2,12 @@ package handles
 
 import (
 	"fmt"
+	"io"
 	stdpath "path"
 	"strings"
 	"time"
 
+	"github.com/Xhofe/go-cache"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/errs"
@@ -16,19 +18,24 @@ import (
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 type ListReq struct {
 	common.PageReq
 	Path     string `json:"path" form:"path"`
 	Password string `json:"password" form:"password"`
-	Refresh  bool   `json:"refresh"`
+	Refresh  bool   `json:"refresh" form:"refresh"`
+	// list the pages by the marker instead of the page number, so the large dirs needn't be listed at once
+	UseMarker bool   `json:"use_marker" form:"use_marker"`
+	Marker    string `json:"marker" form:"marker"`
 }
 
 type DirReq struct {
 	Path      string `json:"path" form:"path"`
 	Password  string `json:"password" form:"password"`
 	ForceRoot bool   `json:"force_root" form:"force_root"`
+	Refresh   bool   `json:"refresh" form:"refresh"`
 }
 
 type ObjResp struct {
@@ -39,13 +46,19 @@ type ObjResp struct {
 	Sign     string    `json:"sign"`
 	Thumb    string    `json:"thumb"`
 	Type     int       `json:"type"`
+	// the hash given by the provider, omitted if unknown
+	Hash *model.HashInfo `json:"hash,omitempty"`
 }
 
 type FsListResp struct {
 	Content []ObjResp `json:"content"`
 	Total   int64     `json:"total"`
 	Readme  string    `json:"readme"`
+	Header  string    `json:"header"`
+	Footer  string    `json:"footer"`
 	Write   bool      `json:"write"`
+	// the marker of the next page in the marker mode, empty if it's the last page
+	NextMarker string `json:"next_marker,omitempty"`
 }
 
 func FsList(c *gin.Context) {
@@ -73,20 +86,48 @@ func FsList(c *gin.Context) {
 		common.ErrorStrResp(c, "Refresh without permission", 403)
 		return
 	}
-	objs, err := fs.List(c, req.Path, req.Refresh)
+	var objs []model.Obj
+	var total int64
+	var next string
+	if req.UseMarker {
+		// the total is unknown until all the pages are listed
+		total = -1
+		objs, next, err = fs.ListPage(c, req.Path, req.Marker, markerPageSize(req.PerPage), req.Refresh)
+	} else {
+		objs, err = fs.List(c, req.Path, req.Refresh)
+		if err == nil {
+			var n int
+			n, objs = pagination(objs, &req.PageReq)
+			total = int64(n)
+		}
+	}
 	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
-	total, objs := pagination(objs, &req.PageReq)
+	content := toObjResp(c, req.Path, objs, isEncrypt(meta, req.Path))
+	setThumbs(c, content, req.Path)
 	common.SuccessResp(c, FsListResp{
-		Content: toObjResp(objs, isEncrypt(meta, req.Path)),
-		Total:   int64(total),
-		Readme:  getReadme(meta, req.Path),
-		Write:   user.CanWrite() || canWrite(meta, req.Path),
+		Content:    content,
+		Total:      total,
+		Readme:     getReadme(c, meta, req.Path),
+		Header:     getHeader(c, meta, req.Path),
+		Footer:     getFooter(c, meta, req.Path),
+		Write:      user.CanWrite() || canWrite(meta, req.Path),
+		NextMarker: next,
 	})
 }
 
+// the max size of the pages in the marker mode, the whole dir isn't listed in a response
+const maxMarkerPageSize = 1000
+
+func markerPageSize(perPage int) int {
+	if perPage > maxMarkerPageSize {
+		return maxMarkerPageSize
+	}
+	return perPage
+}
+
 func FsDirs(c *gin.Context) {
 	var req DirReq
 	if err := c.ShouldBind(&req); err != nil {
@@ -114,7 +155,11 @@ func FsDirs(c *gin.Context) {
 		common.ErrorStrResp(c, "password is incorrect", 403)
 		return
 	}
-	objs, err := fs.List(c, req.Path)
+	if !user.CanWrite() && !canWrite(meta, req.Path) && req.Refresh {
+		common.ErrorStrResp(c, "Refresh without permission", 403)
+		return
+	}
+	objs, err := fs.List(c, req.Path, req.Refresh)
 	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
@@ -141,13 +186,74 @@ func filterDirs(objs []model.Obj) []DirResp {
 	return dirs
 }
 
-func getReadme(meta *model.Meta, path string) string {
+func getReadme(c *gin.Context, meta *model.Meta, path string) string {
 	if meta != nil && (utils.PathEqual(meta.Path, path) || meta.RSub) {
-		return meta.Readme
+		return getMetaDoc(c, meta.Readme, path)
+	}
+	return ""
+}
+
+func getHeader(c *gin.Context, meta *model.Meta, path string) string {
+	if meta != nil && (utils.PathEqual(meta.Path, path) || meta.HeaderSub) {
+		return getMetaDoc(c, meta.Header, path)
+	}
+	return ""
+}
+
+func getFooter(c *gin.Context, meta *model.Meta, path string) string {
+	if meta != nil && (utils.PathEqual(meta.Path, path) || meta.FooterSub) {
+		return getMetaDoc(c, meta.Footer, path)
+	}
+	return ""
+}
+
+// the max size of the file read as the readme, the header or the footer
+const metaDocMaxSize = 1 << 20
+
+// the contents of the files are cached by the paths, sizes and modified times
+var metaDocCache = cache.NewMemCache(cache.WithShards[string](16))
+
+// getMetaDoc get the readme, the header or the footer of the dir, it's the content in markdown,
+// or read from the first existing file in the dir if it's like `@README.md,top.md`
+func getMetaDoc(c *gin.Context, content, dir string) string {
+	if !strings.HasPrefix(content, "@") || strings.Contains(content, "\n") {
+		return content
+	}
+	for _, name := range strings.Split(content[1:], ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || strings.Contains(name, "/") {
+			continue
+		}
+		path := stdpath.Join(dir, name)
+		obj, err := fs.Lookup(c, path)
+		if err != nil || obj == nil || obj.IsDir() {
+			continue
+		}
+		key := fmt.Sprintf("%s:%d:%d", path, obj.GetSize(), obj.ModTime().UnixNano())
+		if s, ok := metaDocCache.Get(key); ok {
+			return s
+		}
+		s, err := readMetaDoc(c, path)
+		if err != nil {
+			log.Warnf("failed read [%s] for the meta: %+v", path, err)
+			continue
+		}
+		metaDocCache.Set(key, s, cache.WithEx[string](time.Hour))
+		return s
 	}
 	return ""
 }
 
+func readMetaDoc(c *gin.Context, path string) (string, error) {
+	stream, err := fs.Open(c, path)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+	data, err := io.ReadAll(io.LimitReader(stream, metaDocMaxSize))
+	return string(data), errors.WithStack(err)
+}
+
 func canAccess(user *model.User, meta *model.Meta, path string, password string) bool {
 	// if is not guest, can access
 	if user.CanAccessWithoutPassword() {
@@ -166,6 +272,9 @@ func canAccess(user *model.User, meta *model.Meta, path string, password string)
 }
 
 func isEncrypt(meta *model.Meta, path string) bool {
+	if sign.Required(path) {
+		return true
+	}
 	if meta == nil || meta.Password == "" {
 		return false
 	}
@@ -189,7 +298,8 @@ func pagination(objs []model.Obj, req *common.PageReq) (int, []model.Obj) {
 	return total, objs[start:end]
 }
 
-func toObjResp(objs []model.Obj, encrypt bool) []ObjResp {
+// toObjResp convert the objects in the parent dir to the responses, they're signed if the dir is encrypted
+func toObjResp(c *gin.Context, parent string, objs []model.Obj, encrypt bool) []ObjResp {
 	var resp []ObjResp
 	for _, obj := range objs {
 		thumb := ""
@@ -205,14 +315,23 @@ func toObjResp(objs []model.Obj, encrypt bool) []ObjResp {
 			Size:     obj.GetSize(),
 			IsDir:    obj.IsDir(),
 			Modified: obj.ModTime(),
-			Sign:     common.Sign(obj, encrypt),
+			Sign:     common.Sign(obj, parent, encrypt, c.ClientIP()),
 			Thumb:    thumb,
 			Type:     tp,
+			Hash:     hashResp(obj),
 		})
 	}
 	return resp
 }
 
+func hashResp(obj model.Obj) *model.HashInfo {
+	h := obj.GetHash()
+	if h.Empty() {
+		return nil
+	}
+	return &h
+}
+
 type FsGetReq struct {
 	Path     string `json:"path" form:"path"`
 	Password string `json:"password" form:"password"`
@@ -224,6 +343,8 @@ type FsGetResp struct {
 	Readme   string    `json:"readme"`
 	Provider string    `json:"provider"`
 	Related  []ObjResp `json:"related"`
+	// the external subtitles of the video
+	Subtitles []model.VideoSubtitle `json:"subtitles,omitempty"`
 }
 
 func FsGet(c *gin.Context) {
@@ -265,12 +386,12 @@ func FsGet(c *gin.Context) {
 		}
 		if storage.Config().MustProxy() || storage.GetStorage().WebProxy {
 			if storage.GetStorage().DownProxyUrl != "" {
-				rawURL = fmt.Sprintf("%s%s?sign=%s", strings.Split(storage.GetStorage().DownProxyUrl, "\n")[0], req.Path, sign.Sign(obj.GetName()))
+				rawURL = fmt.Sprintf("%s%s?sign=%s", strings.Split(storage.GetStorage().DownProxyUrl, "\n")[0], req.Path, sign.Sign(req.Path, ""))
 			} else {
 				rawURL = fmt.Sprintf("%s/p%s?sign=%s",
 					common.GetApiUrl(c.Request),
 					utils.EncodePath(req.Path, true),
-					sign.Sign(obj.GetName()))
+					sign.Sign(req.Path, c.ClientIP()))
 			}
 		} else {
 			// file have raw url
@@ -288,25 +409,31 @@ func FsGet(c *gin.Context) {
 		}
 	}
 	var related []model.Obj
+	var subtitles []model.VideoSubtitle
 	parentPath := stdpath.Dir(req.Path)
+	parentMeta, _ := db.GetNearestMeta(parentPath)
 	sameLevelFiles, err := fs.List(c, parentPath)
 	if err == nil {
 		related = filterRelated(sameLevelFiles, obj)
+		if !obj.IsDir() {
+			subtitles = findSubtitles(c, sameLevelFiles, req.Path, isEncrypt(parentMeta, parentPath))
+		}
 	}
-	parentMeta, _ := db.GetNearestMeta(parentPath)
 	common.SuccessResp(c, FsGetResp{
 		ObjResp: ObjResp{
 			Name:     obj.GetName(),
 			Size:     obj.GetSize(),
 			IsDir:    obj.IsDir(),
 			Modified: obj.ModTime(),
-			Sign:     common.Sign(obj, isEncrypt(meta, req.Path)),
+			Sign:     common.Sign(obj, stdpath.Dir(req.Path), isEncrypt(meta, req.Path), c.ClientIP()),
 			Type:     utils.GetFileType(obj.GetName()),
+			Hash:     hashResp(obj),
 		},
-		RawURL:   rawURL,
-		Readme:   getReadme(meta, req.Path),
-		Provider: provider,
-		Related:  toObjResp(related, isEncrypt(parentMeta, parentPath)),
+		RawURL:    rawURL,
+		Readme:    getReadme(c, meta, req.Path),
+		Provider:  provider,
+		Related:   toObjResp(c, parentPath, related, isEncrypt(parentMeta, parentPath)),
+		Subtitles: subtitles,
 	})
 }
 
@@ -0,0 +1,68 @@
+package handles
+
+import (
+	"io"
+	"net/url"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/internal/subtitle"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// FsSubtitle serve the subtitle for the players, it's verified like the downloads.
+// srt is converted to vtt, so it can be loaded by the browsers
+func FsSubtitle(c *gin.Context) {
+	rawPath := c.MustGet("path").(string)
+	if !subtitle.Supported(rawPath) {
+		common.ErrorResp(c, errors.WithStack(errs.NotSupport), 404)
+		return
+	}
+	file, err := fs.Open(c, rawPath)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	defer file.Close()
+	if file.GetSize() > subtitle.MaxSize {
+		common.ErrorStrResp(c, "the subtitle is too large", 400)
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(file, subtitle.MaxSize))
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if strings.ToLower(utils.Ext(rawPath)) == "srt" {
+		data = subtitle.ToVTT(data)
+	}
+	c.Data(200, subtitle.ContentType(rawPath), data)
+}
+
+// findSubtitles find the external subtitles of the video in the objects of the same dir
+func findSubtitles(c *gin.Context, objs []model.Obj, path string, encrypt bool) []model.VideoSubtitle {
+	if utils.GetFileType(path) != conf.VIDEO {
+		return nil
+	}
+	dir := stdpath.Dir(path)
+	var subtitles []model.VideoSubtitle
+	for _, s := range subtitle.Find(objs, stdpath.Base(path)) {
+		query := url.Values{"path": {stdpath.Join(dir, s.Obj.GetName())}}
+		if encrypt {
+			query.Set("sign", sign.Sign(stdpath.Join(dir, s.Obj.GetName()), c.ClientIP()))
+		}
+		subtitles = append(subtitles, model.VideoSubtitle{
+			Language: s.Language,
+			URL:      common.GetApiUrl(c.Request) + "/api/fs/subtitle?" + query.Encode(),
+		})
+	}
+	return subtitles
+}
@@ -0,0 +1,54 @@
+package handles
+
+import (
+	"net/url"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/thumb"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// FsThumb serve the thumbnail generated or proxied of the file, it's verified like the downloads
+func FsThumb(c *gin.Context) {
+	rawPath := c.MustGet("path").(string)
+	p, err := thumb.Get(c, rawPath)
+	if err != nil {
+		if errors.Is(errors.Cause(err), thumb.ErrNotSupported) {
+			common.ErrorResp(c, err, 404)
+			return
+		}
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	c.Header("Cache-Control", "max-age=86400")
+	c.File(p)
+}
+
+// setThumbs set the urls of the generated thumbnails for the files without the ones of the provider,
+// and the ones of the provider are replaced if they are proxied for the storage
+func setThumbs(c *gin.Context, objs []ObjResp, dirPath string) {
+	storage, _ := fs.GetStorage(dirPath)
+	proxy := thumb.ProxyProvider(storage)
+	for i := range objs {
+		obj := &objs[i]
+		if obj.IsDir {
+			continue
+		}
+		if obj.Thumb != "" {
+			if !proxy {
+				continue
+			}
+		} else if (obj.Type != conf.IMAGE && obj.Type != conf.VIDEO) || !thumb.Supported(obj.Name) {
+			continue
+		}
+		query := url.Values{"path": {stdpath.Join(dirPath, obj.Name)}}
+		if obj.Sign != "" {
+			query.Set("sign", obj.Sign)
+		}
+		obj.Thumb = common.GetApiUrl(c.Request) + "/api/fs/thumb?" + query.Encode()
+	}
+}
@@ -0,0 +1,138 @@
+package handles
+
+import (
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/media"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type FsTimelineReq struct {
+	common.PageReq
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+	Refresh  bool   `json:"refresh" form:"refresh"`
+}
+
+type TimelineObjResp struct {
+	ObjResp
+	// the path of the dir of the image
+	Parent string    `json:"parent"`
+	Time   time.Time `json:"time"`
+}
+
+type TimelineGroup struct {
+	Date  string            `json:"date"`
+	Items []TimelineObjResp `json:"items"`
+}
+
+type TimelineDate struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+type FsTimelineResp struct {
+	Total int `json:"total"`
+	// the counts of the images by the date of all the pages
+	Dates []TimelineDate `json:"dates"`
+	// the images of the page grouped by the date
+	Groups []TimelineGroup `json:"groups"`
+}
+
+// the date of the capture time is in the time zone of the camera, the modified time
+// is in the local time zone
+func timelineDate(item media.TimelineItem) string {
+	if item.Captured {
+		return item.Time.Format("2006-01-02")
+	}
+	return item.Time.Local().Format("2006-01-02")
+}
+
+// FsTimeline get the images under the path grouped by the capture date from the latest
+func FsTimeline(c *gin.Context) {
+	var req FsTimelineReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	req.Path = stdpath.Join(user.BasePath, req.Path)
+	meta, err := db.GetNearestMeta(req.Path)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	c.Set("meta", meta)
+	if !canAccess(user, meta, req.Path, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect", 403)
+		return
+	}
+	if !user.CanWrite() && !canWrite(meta, req.Path) && req.Refresh {
+		common.ErrorStrResp(c, "Refresh without permission", 403)
+		return
+	}
+	items, err := media.Timeline(c, req.Path, req.Refresh)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	// the sub folders may have the other passwords and hides
+	checker := newAccessChecker(user, req.Password)
+	var visible []media.TimelineItem
+	for _, item := range items {
+		ok, err := checker.check(stdpath.Dir(item.Path), item.Obj.GetName())
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		if ok {
+			visible = append(visible, item)
+		}
+	}
+	resp := FsTimelineResp{Total: len(visible), Dates: []TimelineDate{}, Groups: []TimelineGroup{}}
+	for _, item := range visible {
+		date := timelineDate(item)
+		if n := len(resp.Dates); n > 0 && resp.Dates[n-1].Date == date {
+			resp.Dates[n-1].Count++
+		} else {
+			resp.Dates = append(resp.Dates, TimelineDate{Date: date, Count: 1})
+		}
+	}
+	start := (req.Page - 1) * req.PerPage
+	if start > len(visible) {
+		start = len(visible)
+	}
+	end := start + req.PerPage
+	if end > len(visible) || end < 0 {
+		end = len(visible)
+	}
+	for _, item := range visible[start:end] {
+		parent := stdpath.Dir(item.Path)
+		p, _ := checker.parent(parent)
+		objs := toObjResp(c, parent, []model.Obj{item.Obj}, isEncrypt(p.meta, parent))
+		setThumbs(c, objs, parent)
+		obj := TimelineObjResp{
+			ObjResp: objs[0],
+			Parent:  utils.StandardizePath(strings.TrimPrefix(parent, user.BasePath)),
+			Time:    item.Time,
+		}
+		date := timelineDate(item)
+		if n := len(resp.Groups); n > 0 && resp.Groups[n-1].Date == date {
+			resp.Groups[n-1].Items = append(resp.Groups[n-1].Items, obj)
+		} else {
+			resp.Groups = append(resp.Groups, TimelineGroup{Date: date, Items: []TimelineObjResp{obj}})
+		}
+	}
+	common.SuccessResp(c, resp)
+}
@@ -0,0 +1,54 @@
+package handles
+
+import (
+	"net/http"
+	"path/filepath"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/transcode"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// FsTranscode start transcoding the video, it's verified like the downloads.
+// it's redirected to the playlist of the session, so the segments are relative to it
+func FsTranscode(c *gin.Context) {
+	rawPath := c.MustGet("path").(string)
+	id, err := transcode.Start(c, rawPath, c.Query("preset"))
+	if err != nil {
+		switch errors.Cause(err) {
+		case transcode.ErrDisabled:
+			common.ErrorResp(c, err, 403)
+		case transcode.ErrTooManySessions:
+			common.ErrorResp(c, err, 429)
+		default:
+			common.ErrorResp(c, err, 500)
+		}
+		return
+	}
+	c.Redirect(http.StatusFound, common.GetApiUrl(c.Request)+"/api/fs/transcode/"+id+"/index.m3u8")
+}
+
+// FsTranscodeFile serve the playlist and the segments of the session, the id of
+// the session is random and only known by the one started it
+func FsTranscodeFile(c *gin.Context) {
+	name := c.Param("file")
+	p, err := transcode.File(c, c.Param("id"), name)
+	if err != nil {
+		if errs.IsObjectNotFound(err) || errors.Is(errors.Cause(err), transcode.ErrSessionNotFound) {
+			common.ErrorResp(c, err, 404)
+			return
+		}
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if filepath.Ext(name) == ".m3u8" {
+		// the playlist grows while transcoding
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Content-Type", "application/vnd.apple.mpegurl")
+	} else {
+		c.Header("Content-Type", "video/mp2t")
+	}
+	c.File(p)
+}
@@ -0,0 +1,256 @@
+package handles
+
+import (
+	stdpath "path"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// the resumable upload: create a session, put the chunks in order with the offset,
+// query the offset to resume if a chunk is broken, then complete it to put the file to the storage
+
+type UploadCreateReq struct {
+	Path     string `json:"path" binding:"required"`
+	Size     int64  `json:"size"`
+	Mimetype string `json:"mimetype"`
+	Sha1     string `json:"sha1"`
+	AsTask   bool   `json:"as_task"`
+}
+
+type UploadReq struct {
+	Id string `json:"id" form:"id" binding:"required"`
+}
+
+func FsUploadCreate(c *gin.Context) {
+	var req UploadCreateReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.Size < 0 {
+		common.ErrorStrResp(c, "size can't be negative", 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	req.Path = stdpath.Join(user.BasePath, req.Path)
+	if !user.CanWrite() {
+		meta, err := db.GetNearestMeta(req.Path)
+		if err != nil {
+			if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+				common.ErrorResp(c, err, 500, true)
+				return
+			}
+		}
+		if !canWrite(meta, req.Path) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+	}
+	if err := fs.CheckAcl(c, stdpath.Dir(req.Path), model.AclWrite); err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	storage, err := fs.GetStorage(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if storage.Config().NoUpload {
+		common.ErrorResp(c, errs.UploadNotSupported, 400)
+		return
+	}
+	session := &model.UploadSession{
+		UserId:   user.ID,
+		Path:     req.Path,
+		Size:     req.Size,
+		Mimetype: req.Mimetype,
+		Sha1:     req.Sha1,
+		AsTask:   req.AsTask,
+	}
+	if err := fs.CreateUpload(session); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, session)
+}
+
+// getUploadSession get the session of the current user
+func getUploadSession(c *gin.Context, id string) (*model.UploadSession, bool) {
+	session, err := db.GetUploadSessionById(id)
+	user := c.MustGet("user").(*model.User)
+	if err != nil || session.UserId != user.ID {
+		common.ErrorStrResp(c, "upload session not found", 404)
+		return nil, false
+	}
+	return session, true
+}
+
+func FsUploadStatus(c *gin.Context) {
+	var req UploadReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	session, ok := getUploadSession(c, req.Id)
+	if !ok {
+		return
+	}
+	common.SuccessResp(c, session)
+}
+
+// FsUploadChunk append the body to the session, the headers `Upload-Id` and `Upload-Offset` are required
+func FsUploadChunk(c *gin.Context) {
+	session, ok := getUploadSession(c, c.GetHeader("Upload-Id"))
+	if !ok {
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	err = fs.UploadChunk(session, offset, c.Request.Body)
+	if err != nil {
+		switch errors.Cause(err) {
+		case errs.UploadOffsetMismatch, errs.UploadSessionBusy:
+			common.ErrorResp(c, err, 409)
+		case errs.UploadSizeExceeded:
+			common.ErrorResp(c, err, 400)
+		default:
+			common.ErrorResp(c, err, 500)
+		}
+		return
+	}
+	common.SuccessResp(c, session)
+}
+
+func FsUploadComplete(c *gin.Context) {
+	var req UploadReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	session, ok := getUploadSession(c, req.Id)
+	if !ok {
+		return
+	}
+	if err := fs.CompleteUpload(c, session); err != nil {
+		switch errors.Cause(err) {
+		case errs.UploadNotCompleted, errs.UploadSessionBusy:
+			common.ErrorResp(c, err, 409)
+		default:
+			common.ErrorResp(c, err, 500)
+		}
+		return
+	}
+	fs.ClearCache(stdpath.Dir(session.Path))
+	common.SuccessResp(c)
+}
+
+func FsUploadCancel(c *gin.Context) {
+	var req UploadReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	session, ok := getUploadSession(c, req.Id)
+	if !ok {
+		return
+	}
+	if err := fs.CancelUpload(session); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// the direct upload: get the urls of the storage and put the file or the parts of it to them,
+// then complete it with the etags of the parts, the content doesn't pass through alist
+
+type DirectUploadReq struct {
+	Path string `json:"path" binding:"required"`
+	Size int64  `json:"size"`
+}
+
+type DirectUploadCompleteReq struct {
+	Path     string                   `json:"path" binding:"required"`
+	UploadID string                   `json:"upload_id"`
+	Parts    []model.DirectUploadPart `json:"parts"`
+}
+
+// checkUploadPermission check if the user in ctx can upload the file to the path
+func checkUploadPermission(c *gin.Context, path string) bool {
+	user := c.MustGet("user").(*model.User)
+	if user.CanWrite() {
+		return true
+	}
+	meta, err := db.GetNearestMeta(path)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return false
+	}
+	if !canWrite(meta, path) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return false
+	}
+	return true
+}
+
+// FsDirectUpload get the urls to upload the file to the storage directly,
+// the client should upload it through alist if the code is 501
+func FsDirectUpload(c *gin.Context) {
+	var req DirectUploadReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.Size < 0 {
+		common.ErrorStrResp(c, "size can't be negative", 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	req.Path = stdpath.Join(user.BasePath, req.Path)
+	if !checkUploadPermission(c, req.Path) {
+		return
+	}
+	res, err := fs.DirectUpload(c, req.Path, req.Size)
+	if errors.Is(err, errs.NotSupport) {
+		common.ErrorResp(c, err, 501)
+		return
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, res)
+}
+
+func FsDirectUploadComplete(c *gin.Context) {
+	var req DirectUploadCompleteReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	req.Path = stdpath.Join(user.BasePath, req.Path)
+	if !checkUploadPermission(c, req.Path) {
+		return
+	}
+	err := fs.CompleteDirectUpload(c, req.Path, req.UploadID, req.Parts)
+	if errors.Is(err, errs.NotSupport) {
+		common.ErrorResp(c, err, 501)
+		return
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
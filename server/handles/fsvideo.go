@@ -0,0 +1,93 @@
+package handles
+
+import (
+	"net/url"
+	stdpath "path"
+	"sort"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/internal/transcode"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type FsVideoPreviewReq struct {
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+}
+
+// FsVideoPreview get the streams of the video in the qualities for the players, the ones
+// transcoded by the provider are preferred, then the ones transcoded by alist
+func FsVideoPreview(c *gin.Context) {
+	var req FsVideoPreviewReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	req.Path = stdpath.Join(user.BasePath, req.Path)
+	meta, err := db.GetNearestMeta(req.Path)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	c.Set("meta", meta)
+	if !canAccess(user, meta, req.Path, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect", 403)
+		return
+	}
+	preview, err := fs.VideoPreview(c, req.Path)
+	if errors.Is(err, errs.NotImplement) {
+		preview, err = transcodePreview(c, meta, req.Path)
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if objs, err := fs.List(c, stdpath.Dir(req.Path)); err == nil {
+		preview.Subtitles = append(preview.Subtitles, findSubtitles(c, objs, req.Path, isEncrypt(meta, req.Path))...)
+	}
+	common.SuccessResp(c, preview)
+}
+
+// transcodePreview get the streams transcoded by alist in the presets
+func transcodePreview(c *gin.Context, meta *model.Meta, path string) (*model.VideoPreview, error) {
+	if !transcode.Enabled() {
+		return nil, errs.NotImplement
+	}
+	if _, err := fs.Get(c, path); err != nil {
+		return nil, err
+	}
+	presets, err := transcode.Presets()
+	if err != nil {
+		return nil, err
+	}
+	preview := &model.VideoPreview{}
+	for name, preset := range presets {
+		query := url.Values{"path": {path}, "preset": {name}}
+		if isEncrypt(meta, path) {
+			query.Set("sign", sign.Sign(path, c.ClientIP()))
+		}
+		preview.Qualities = append(preview.Qualities, model.VideoQuality{
+			Name:   name,
+			Height: preset.Height,
+			URL:    common.GetApiUrl(c.Request) + "/api/fs/transcode?" + query.Encode(),
+		})
+	}
+	// the original height is 0, it's the highest
+	sort.Slice(preview.Qualities, func(i, j int) bool {
+		hi, hj := preview.Qualities[i].Height, preview.Qualities[j].Height
+		if hi == 0 || hj == 0 {
+			return hj == 0 && hi != 0
+		}
+		return hi < hj
+	})
+	return preview, nil
+}
@@ -0,0 +1,136 @@
+package handles
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type ZipReq struct {
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+}
+
+// FsZip stream the dir as a zip generated on the fly, the files are read one by one
+// and written to the response directly, so nothing is stored on the server
+func FsZip(c *gin.Context) {
+	var req ZipReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	req.Path = stdpath.Join(user.BasePath, req.Path)
+	meta, err := db.GetNearestMeta(req.Path)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	c.Set("meta", meta)
+	if !canAccess(user, meta, req.Path, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect", 403)
+		return
+	}
+	dir, err := fs.Get(c, req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if !dir.IsDir() {
+		common.ErrorStrResp(c, "not a folder", 400)
+		return
+	}
+	if err := fs.CheckDownloadQuota(c); err != nil {
+		common.ErrorResp(c, err, 429)
+		return
+	}
+	name := dir.GetName()
+	if req.Path == "/" {
+		name = "root"
+	}
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"; filename*=UTF-8''%s.zip`, name, url.QueryEscape(name)))
+	w := ratelimit.NewResponseWriter(c, c.Writer, fs.DownloadLimiters(c, req.Path)...)
+	z := &zipper{user: user, password: req.Password, w: zip.NewWriter(w)}
+	// the response has been started, so the error can only be logged
+	if err := z.addDir(c, req.Path, ""); err != nil {
+		log.Errorf("failed to zip [%s]: %+v", req.Path, err)
+		return
+	}
+	if err := z.w.Close(); err != nil {
+		log.Errorf("failed to zip [%s]: %+v", req.Path, err)
+	}
+}
+
+type zipper struct {
+	user     *model.User
+	password string
+	w        *zip.Writer
+}
+
+// addDir add the objects in the dir to the zip under the name, the sub dirs that
+// can't be accessed with the password are skipped
+func (z *zipper) addDir(ctx context.Context, path, name string) error {
+	objs, err := fs.List(ctx, path)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		objPath := stdpath.Join(path, obj.GetName())
+		objName := stdpath.Join(name, obj.GetName())
+		if !obj.IsDir() {
+			if err := z.addFile(ctx, objPath, objName, obj); err != nil {
+				return err
+			}
+			continue
+		}
+		meta, err := db.GetNearestMeta(objPath)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return err
+		}
+		if !canAccess(z.user, meta, objPath, z.password) {
+			continue
+		}
+		if _, err := z.w.CreateHeader(&zip.FileHeader{Name: objName + "/", Modified: obj.ModTime()}); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := z.addDir(context.WithValue(ctx, "meta", meta), objPath, objName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (z *zipper) addFile(ctx context.Context, path, name string, obj model.Obj) error {
+	file, err := fs.Open(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	// the files are stored without compression, most of the large ones are compressed already
+	w, err := z.w.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store,
+		Modified: obj.ModTime(),
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = io.Copy(w, file)
+	return errors.Wrapf(err, "failed to read [%s]", path)
+}
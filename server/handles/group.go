@@ -0,0 +1,126 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListGroups(c *gin.Context) {
+	groups, err := db.GetGroups()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, groups)
+}
+
+func CreateGroup(c *gin.Context) {
+	var req model.Group
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := db.CreateGroup(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c, gin.H{
+			"id": req.ID,
+		})
+	}
+}
+
+func UpdateGroup(c *gin.Context) {
+	var req model.Group
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := db.UpdateGroup(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func DeleteGroup(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := db.DeleteGroupById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// GroupUsers return the ids of the users in the group
+func GroupUsers(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	ids, err := db.GetUserIdsByGroupId(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, ids)
+}
+
+func UserGroups(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	groups, err := db.GetGroupsByUserId(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, groups)
+}
+
+type SetUserGroupsReq struct {
+	UserId   uint   `json:"user_id" binding:"required"`
+	GroupIds []uint `json:"group_ids"`
+}
+
+func SetUserGroups(c *gin.Context) {
+	var req SetUserGroupsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user, err := db.GetUserById(req.UserId)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if user.IsAdmin() || user.IsGuest() {
+		common.ErrorResp(c, errs.GroupAdminOrGuest, 400)
+		return
+	}
+	for _, id := range req.GroupIds {
+		if _, err := db.GetGroupById(id); err != nil {
+			common.ErrorResp(c, err, 400)
+			return
+		}
+	}
+	if err := db.SetUserGroups(req.UserId, req.GroupIds); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -0,0 +1,60 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/search"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type BuildIndexReq struct {
+	Paths []string `json:"paths"`
+}
+
+func BuildIndex(c *gin.Context) {
+	var req BuildIndexReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if len(req.Paths) == 0 {
+		req.Paths = []string{"/"}
+	}
+	if err := search.BuildIndex(req.Paths); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func UpdateIndex(c *gin.Context) {
+	var req BuildIndexReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if len(req.Paths) == 0 {
+		req.Paths = []string{"/"}
+	}
+	if err := search.UpdateIndex(req.Paths); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func StopIndex(c *gin.Context) {
+	search.StopIndex()
+	common.SuccessResp(c)
+}
+
+func ClearIndex(c *gin.Context) {
+	if err := search.Clear(c); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func GetIndexProgress(c *gin.Context) {
+	common.SuccessResp(c, search.GetProgress())
+}
@@ -0,0 +1,130 @@
+package handles
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/schedule"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type JobResp struct {
+	model.Job
+	NextRun *time.Time `json:"next_run"`
+}
+
+func toJobResp(job model.Job) JobResp {
+	resp := JobResp{Job: job}
+	if next := schedule.NextRun(job.ID); !next.IsZero() {
+		resp.NextRun = &next
+	}
+	return resp
+}
+
+func ListJobs(c *gin.Context) {
+	jobs, err := db.GetJobs()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	resp := make([]JobResp, 0, len(jobs))
+	for _, job := range jobs {
+		resp = append(resp, toJobResp(job))
+	}
+	common.SuccessResp(c, resp)
+}
+
+func GetJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	job, err := db.GetJobById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, toJobResp(*job))
+}
+
+func CreateJob(c *gin.Context) {
+	var req model.Job
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := schedule.CreateJob(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c, gin.H{
+			"id": req.ID,
+		})
+	}
+}
+
+func UpdateJob(c *gin.Context) {
+	var req model.Job
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := schedule.UpdateJob(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func DeleteJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := schedule.DeleteJobById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func RunJob(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := schedule.RunJob(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func ListJobRuns(c *gin.Context) {
+	var req struct {
+		common.PageReq
+		ID uint `json:"id" form:"id"`
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	runs, total, err := db.GetJobRuns(req.ID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: runs,
+		Total:   total,
+	})
+}
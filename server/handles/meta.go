@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/hook"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
@@ -44,6 +45,10 @@ func CreateMeta(c *gin.Context) {
 		common.ErrorStrResp(c, fmt.Sprintf("%s is illegal: %s", r, err.Error()), 400)
 		return
 	}
+	if err := hook.CheckMetaFilter(req.Filter); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
 	req.Path = utils.StandardizePath(req.Path)
 	if err := db.CreateMeta(&req); err != nil {
 		common.ErrorResp(c, err, 500, true)
@@ -63,6 +68,10 @@ func UpdateMeta(c *gin.Context) {
 		common.ErrorStrResp(c, fmt.Sprintf("%s is illegal: %s", r, err.Error()), 400)
 		return
 	}
+	if err := hook.CheckMetaFilter(req.Filter); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
 	req.Path = utils.StandardizePath(req.Path)
 	if err := db.UpdateMeta(&req); err != nil {
 		common.ErrorResp(c, err, 500, true)
@@ -0,0 +1,62 @@
+package handles
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/archive"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/fssync"
+	"github.com/alist-org/alist/v3/internal/metrics"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/schedule"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/task"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+var taskManagers = []struct {
+	typ string
+	tm  *task.Manager[uint64]
+}{
+	{"upload", fs.UploadTaskManager},
+	{"copy", fs.CopyTaskManager},
+	{"move", fs.MoveTaskManager},
+	{"job", schedule.JobTaskManager},
+	{"extract", archive.ExtractTaskManager},
+	{"compress", archive.CompressTaskManager},
+	{"download", tool.DownTaskManager},
+	{"post", tool.PostTaskManager},
+	{"transfer", tool.TransferTaskManager},
+	{"sync", fssync.SyncTaskManager},
+}
+
+// the undone tasks by the state, the pending ones are waiting in the queue
+var _ = metrics.NewGaugeFunc("alist_tasks", "the count of the undone tasks by the type and the state",
+	[]string{"type", "state"}, func(set func(value float64, labels ...string)) {
+		for _, m := range taskManagers {
+			for _, state := range []string{task.PENDING, task.RUNNING, task.CANCELING} {
+				set(float64(len(m.tm.GetByStates(state))), m.typ, state)
+			}
+		}
+	})
+
+// Metrics write the metrics in the prometheus format, the token is required if it's set
+func Metrics(c *gin.Context) {
+	if !setting.GetBool(conf.MetricsEnabled) {
+		common.ErrorStrResp(c, "metrics is disabled", 404)
+		return
+	}
+	if token := setting.GetStr(conf.MetricsToken); token != "" {
+		got := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			common.ErrorStrResp(c, "invalid metrics token", 401)
+			return
+		}
+	}
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(200)
+	_ = metrics.Write(c.Writer)
+}
@@ -0,0 +1,141 @@
+package handles
+
+import (
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type SetAria2Req struct {
+	Uri    string `json:"uri" form:"uri"`
+	Secret string `json:"secret" form:"secret"`
+}
+
+func SetAria2(c *gin.Context) {
+	var req SetAria2Req
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	items := []model.SettingItem{
+		{Key: conf.Aria2Uri, Value: req.Uri, Type: conf.TypeString, Group: model.ARIA2, Flag: model.PRIVATE},
+		{Key: conf.Aria2Secret, Value: req.Secret, Type: conf.TypeString, Group: model.ARIA2, Flag: model.PRIVATE},
+	}
+	setOfflineDownloadTool(c, "aria2", items)
+}
+
+type SetQbittorrentReq struct {
+	Url string `json:"url" form:"url"`
+}
+
+func SetQbittorrent(c *gin.Context) {
+	var req SetQbittorrentReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	items := []model.SettingItem{
+		{Key: conf.QbittorrentUrl, Value: req.Url, Type: conf.TypeString, Group: model.QBITTORRENT, Flag: model.PRIVATE},
+	}
+	setOfflineDownloadTool(c, "qbittorrent", items)
+}
+
+// setOfflineDownloadTool save the settings of the tool and init it with them
+func setOfflineDownloadTool(c *gin.Context, name string, items []model.SettingItem) {
+	if err := db.SaveSettingItems(items); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	t, err := tool.Get(name)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	info, err := t.Init()
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, info)
+}
+
+// OfflineDownloadTools list the names of the tools that are ready
+func OfflineDownloadTools(c *gin.Context) {
+	names := make([]string, 0)
+	for _, t := range tool.All() {
+		if t.IsReady() {
+			names = append(names, t.Name())
+		}
+	}
+	common.SuccessResp(c, names)
+}
+
+type AddOfflineDownloadReq struct {
+	Urls []string `json:"urls"`
+	Path string   `json:"path"`
+	Tool string   `json:"tool"`
+}
+
+func AddOfflineDownload(c *gin.Context) {
+	var req AddOfflineDownloadReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	addOfflineDownload(c, req)
+}
+
+type AddAria2Req struct {
+	Urls []string `json:"urls"`
+	Path string   `json:"path"`
+}
+
+// AddAria2 is kept for the old clients, it's the same as AddOfflineDownload with aria2
+func AddAria2(c *gin.Context) {
+	var req AddAria2Req
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	addOfflineDownload(c, AddOfflineDownloadReq{Urls: req.Urls, Path: req.Path, Tool: "aria2"})
+}
+
+func addOfflineDownload(c *gin.Context, req AddOfflineDownloadReq) {
+	user := c.MustGet("user").(*model.User)
+	if !user.CanAddAria2Tasks() {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return
+	}
+	t, err := tool.Get(req.Tool)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if !t.IsReady() {
+		common.ErrorStrResp(c, req.Tool+" not ready", 500)
+		return
+	}
+	req.Path = stdpath.Join(user.BasePath, req.Path)
+	if err := fs.CheckAcl(c, req.Path, model.AclWrite); err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	for _, url := range req.Urls {
+		err := tool.AddURL(c, &tool.AddURLArgs{
+			URL:        url,
+			DstDirPath: req.Path,
+			Tool:       req.Tool,
+		})
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	common.SuccessResp(c)
+}
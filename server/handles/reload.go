@@ -0,0 +1,19 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/bootstrap"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/alist-org/alist/v3/server/static"
+	"github.com/gin-gonic/gin"
+)
+
+// Reload apply the changes of the config file and the storages without restarting
+func Reload(c *gin.Context) {
+	res, err := bootstrap.Reload(c)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	static.UpdateIndex()
+	common.SuccessResp(c, res)
+}
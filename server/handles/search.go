@@ -0,0 +1,181 @@
+package handles
+
+import (
+	stdpath "path"
+	"regexp"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/search"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type SearchReq struct {
+	common.PageReq
+	model.SearchFilters
+	Parent   string `json:"parent" form:"parent"`
+	Keywords string `json:"keywords" form:"keywords"`
+	Password string `json:"password" form:"password"`
+	// Mount limit the results to the storage mounted at the path
+	Mount string `json:"mount" form:"mount"`
+}
+
+func FsSearch(c *gin.Context) {
+	var req SearchReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	if strings.TrimSpace(req.Keywords) == "" && req.SearchFilters.IsEmpty() {
+		common.ErrorStrResp(c, "keywords or filters are required", 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	req.Parent = stdpath.Join(user.BasePath, req.Parent)
+	if req.Mount != "" {
+		mount := stdpath.Join(user.BasePath, req.Mount)
+		if !utils.IsSubPath(req.Parent, mount) && !utils.IsSubPath(mount, req.Parent) {
+			common.SuccessResp(c, common.PageResp{Content: []model.SearchNode{}, Total: 0})
+			return
+		}
+		// search the deeper one of the parent and the mount
+		if utils.IsSubPath(req.Parent, mount) {
+			req.Parent = mount
+		}
+	}
+	meta, err := db.GetNearestMeta(req.Parent)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	if !canAccess(user, meta, req.Parent, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect", 403)
+		return
+	}
+	var nodes []model.SearchNode
+	if search.Enabled() {
+		nodes, err = search.Search(c, req.Parent, req.Keywords, req.SearchFilters)
+		if err == nil {
+			nodes, err = filterSearchNodes(user, nodes, req.Password)
+		}
+	} else {
+		// the search api of the storages has no modified time in the results
+		if strings.TrimSpace(req.Keywords) == "" || req.SearchFilters.HasDate() {
+			common.ErrorStrResp(c, "the search index is required to search without keywords or by the modified time", 400)
+			return
+		}
+		nodes, err = fs.Search(c, req.Parent, req.Keywords)
+		if err == nil {
+			nodes, err = filterSearchNodes(user, utils.SliceFilter(nodes, req.SearchFilters.Match), req.Password)
+		}
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	for i := range nodes {
+		nodes[i].Parent = utils.StandardizePath(strings.TrimPrefix(nodes[i].Parent, user.BasePath))
+	}
+	total, nodes := paginationNodes(nodes, &req.PageReq)
+	common.SuccessResp(c, common.PageResp{
+		Content: nodes,
+		Total:   int64(total),
+	})
+}
+
+// filterSearchNodes remove the nodes in the folders that the user
+// can't access with the password, and the nodes hidden by the metas
+func filterSearchNodes(user *model.User, nodes []model.SearchNode, password string) ([]model.SearchNode, error) {
+	checker := newAccessChecker(user, password)
+	res := make([]model.SearchNode, 0, len(nodes))
+	for _, node := range nodes {
+		ok, err := checker.check(node.Parent, node.Name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			res = append(res, node)
+		}
+	}
+	return res, nil
+}
+
+type parentAccess struct {
+	ok    bool
+	meta  *model.Meta
+	hides []*regexp.Regexp
+}
+
+// accessChecker check if the objects in the folders can be accessed by the user with the
+// password, and they aren't hidden by the metas. the results are cached by the folder
+type accessChecker struct {
+	user     *model.User
+	password string
+	parents  map[string]parentAccess
+}
+
+func newAccessChecker(user *model.User, password string) *accessChecker {
+	return &accessChecker{user: user, password: password, parents: make(map[string]parentAccess)}
+}
+
+func (a *accessChecker) parent(parent string) (parentAccess, error) {
+	p, ok := a.parents[parent]
+	if ok {
+		return p, nil
+	}
+	meta, err := db.GetNearestMeta(parent)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return p, err
+	}
+	p.meta = meta
+	p.ok = canAccess(a.user, meta, parent, a.password)
+	if p.ok && !a.user.CanSeeHides() && meta != nil && meta.Hide != "" &&
+		(utils.PathEqual(meta.Path, parent) || meta.HSub) {
+		for _, r := range strings.Split(meta.Hide, "\n") {
+			if re, err := regexp.Compile(r); err == nil {
+				p.hides = append(p.hides, re)
+			}
+		}
+	}
+	a.parents[parent] = p
+	return p, nil
+}
+
+func (a *accessChecker) check(parent, name string) (bool, error) {
+	p, err := a.parent(parent)
+	if err != nil {
+		return false, err
+	}
+	return p.ok && !hidden(p.hides, name), nil
+}
+
+func hidden(hides []*regexp.Regexp, name string) bool {
+	for _, re := range hides {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func paginationNodes(nodes []model.SearchNode, req *common.PageReq) (int, []model.SearchNode) {
+	total := len(nodes)
+	start := (req.Page - 1) * req.PerPage
+	if start > total {
+		return total, []model.SearchNode{}
+	}
+	end := start + req.PerPage
+	if end > total || end < 0 {
+		end = total
+	}
+	return total, nodes[start:end]
+}
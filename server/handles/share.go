@@ -0,0 +1,310 @@
+package handles
+
+import (
+	"crypto/subtle"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/diskcache"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/internal/throttle"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// ListShares list the shares of the current user
+func ListShares(c *gin.Context) {
+	listShares(c, c.MustGet("user").(*model.User).ID)
+}
+
+// ListAllShares list the shares of all the users for the admin
+func ListAllShares(c *gin.Context) {
+	listShares(c, 0)
+}
+
+func listShares(c *gin.Context, userId uint) {
+	var req common.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	shares, total, err := db.GetShares(userId, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: shares,
+		Total:   total,
+	})
+}
+
+type ShareReq struct {
+	ID           uint       `json:"id"`
+	Path         string     `json:"path"`
+	Remark       string     `json:"remark"`
+	Password     string     `json:"password"`
+	MaxDownloads int64      `json:"max_downloads"`
+	RateLimit    int64      `json:"rate_limit"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	// the password of the meta protecting the path, it's required to share the path
+	MetaPassword string `json:"meta_password"`
+}
+
+func CreateShare(c *gin.Context) {
+	var req ShareReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "Guest user can not create shares", 403)
+		return
+	}
+	path := stdpath.Join(user.BasePath, req.Path)
+	if err := fs.CheckAcl(c, path, model.AclShare); err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := db.GetNearestMeta(path)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if !canAccess(user, meta, path, req.MetaPassword) {
+		common.ErrorStrResp(c, "password is incorrect", 403)
+		return
+	}
+	if _, err := fs.Get(c, path); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	share := &model.Share{
+		Key:          random.SecureString(8),
+		UserID:       user.ID,
+		Path:         path,
+		Remark:       req.Remark,
+		Password:     req.Password,
+		MaxDownloads: req.MaxDownloads,
+		RateLimit:    req.RateLimit,
+		ExpiresAt:    req.ExpiresAt,
+	}
+	err = db.CreateShare(share)
+	audit.Record(c, model.AuditShare, path, err)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, share)
+}
+
+// getOwnShare get the share of the current user, the admin can get any share
+func getOwnShare(c *gin.Context, id uint) (*model.Share, bool) {
+	user := c.MustGet("user").(*model.User)
+	share, err := db.GetShareById(id)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return nil, false
+	}
+	if share.UserID != user.ID && !user.IsAdmin() {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return nil, false
+	}
+	return share, true
+}
+
+// UpdateShare update the limits of the share, the path can't be changed
+func UpdateShare(c *gin.Context) {
+	var req ShareReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	share, ok := getOwnShare(c, req.ID)
+	if !ok {
+		return
+	}
+	share.Remark = req.Remark
+	share.Password = req.Password
+	share.MaxDownloads = req.MaxDownloads
+	share.RateLimit = req.RateLimit
+	share.ExpiresAt = req.ExpiresAt
+	if err := db.UpdateShare(share); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func DeleteShare(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if _, ok := getOwnShare(c, uint(id)); !ok {
+		return
+	}
+	if err := db.DeleteShareById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// openShare check the share and the password, set the creator to ctx,
+// and return the path in alist of the relative path in the share
+func openShare(c *gin.Context, key, password, path string) (*model.Share, string, bool) {
+	ip := c.ClientIP()
+	if loginBanned(c, ip) {
+		return nil, "", false
+	}
+	share, err := db.GetShareByKey(key)
+	if err != nil || share.Expired() {
+		common.ErrorStrResp(c, "the share doesn't exist or is expired", 404)
+		return nil, "", false
+	}
+	if share.Password != "" && subtle.ConstantTimeCompare([]byte(password), []byte(share.Password)) != 1 {
+		common.ErrorStrResp(c, "password is incorrect", 403)
+		throttle.Fail(throttle.KindLogin, ip)
+		return nil, "", false
+	}
+	creator, err := db.GetUserById(share.UserID)
+	if err == nil {
+		creator, err = db.GetUserByName(creator.Username)
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return nil, "", false
+	}
+	c.Set("user", creator)
+	// clean the path as an absolute one first, so it can't go out of the share by ..
+	return share, stdpath.Join(share.Path, stdpath.Join("/", path)), true
+}
+
+type ShareGetReq struct {
+	Key      string `json:"key" form:"key" binding:"required"`
+	Password string `json:"password" form:"password"`
+	Path     string `json:"path" form:"path"`
+}
+
+type ShareGetResp struct {
+	ObjResp
+	Remark    string     `json:"remark"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	// the objects in the dir if it's a dir
+	Content []ObjResp `json:"content"`
+}
+
+// ShareGet get the shared object or the object in the shared folder, and list it if it's a dir
+func ShareGet(c *gin.Context) {
+	var req ShareGetReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	share, path, ok := openShare(c, req.Key, req.Password, req.Path)
+	if !ok {
+		return
+	}
+	obj, err := fs.Get(c, path)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	resp := ShareGetResp{
+		ObjResp: ObjResp{
+			Name:     obj.GetName(),
+			Size:     obj.GetSize(),
+			IsDir:    obj.IsDir(),
+			Modified: obj.ModTime(),
+			Type:     utils.GetFileType(obj.GetName()),
+			Hash:     hashResp(obj),
+		},
+		Remark:    share.Remark,
+		ExpiresAt: share.ExpiresAt,
+	}
+	if obj.IsDir() {
+		meta, err := db.GetNearestMeta(path)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		c.Set("meta", meta)
+		objs, err := fs.List(c, path)
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		resp.Content = toObjResp(c, "", objs, false)
+	}
+	common.SuccessResp(c, resp)
+}
+
+// ShareDownPath open the share and set the path of the file to download,
+// it's before the middlewares checking the path, such as the anti-leech
+func ShareDownPath(c *gin.Context) {
+	share, path, ok := openShare(c, c.Param("key"), c.Query("pwd"), c.Param("path"))
+	if !ok {
+		return
+	}
+	c.Set("path", path)
+	c.Set("share", share)
+	c.Next()
+}
+
+// ShareDown download the shared file or the file in the shared folder through alist,
+// so the download is counted and limited by the rate limit of the share
+func ShareDown(c *gin.Context) {
+	share := c.MustGet("share").(*model.Share)
+	path := c.GetString("path")
+	// the downloads are counted in the traffic of the creator
+	if err := fs.CheckDownloadQuota(c); err != nil {
+		common.ErrorResp(c, err, 429)
+		return
+	}
+	link, file, err := fs.Link(c, path, model.LinkArgs{
+		Header: c.Request.Header,
+		Type:   c.Query("type"),
+	})
+	audit.RecordDownload(c, path, file, err)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if file.IsDir() {
+		common.ErrorStrResp(c, "can't download a folder", 400)
+		return
+	}
+	// the following range requests of a download aren't counted
+	if rg := c.GetHeader("Range"); rg == "" || strings.HasPrefix(rg, "bytes=0-") {
+		ok, err := db.IncreaseShareDownloads(share.ID)
+		if err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		if !ok {
+			common.ErrorStrResp(c, "the share reaches the max downloads", 403)
+			return
+		}
+	}
+	limiters := append(fs.DownloadLimiters(c, path), ratelimit.Share(share.ID, share.RateLimit))
+	w := ratelimit.NewResponseWriter(c, c.Writer, limiters...)
+	if err := common.Proxy(w, c.Request, diskcache.Wrap(path, link, file), file); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+}
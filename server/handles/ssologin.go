@@ -0,0 +1,83 @@
+package handles
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/sso"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// ssoStates keep the nonce of the state until the callback
+var ssoStates = cache.NewMemCache[string]()
+
+func ssoRedirectURI(c *gin.Context) string {
+	return common.GetApiUrl(c.Request) + "/api/auth/sso/callback"
+}
+
+// SSOLogin redirect to the oidc provider
+func SSOLogin(c *gin.Context) {
+	if !sso.Enabled() {
+		common.ErrorStrResp(c, "sso login is disabled", 403)
+		return
+	}
+	state, nonce := random.SecureString(32), random.SecureString(32)
+	u, err := sso.AuthURL(c, ssoRedirectURI(c), state, nonce)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	ssoStates.Set(state, nonce, cache.WithEx[string](10*time.Minute))
+	c.Redirect(http.StatusFound, u)
+}
+
+// SSOCallback login the user linked to the id token, the token of alist is saved
+// to the local storage of the browser, then redirect to the home page
+func SSOCallback(c *gin.Context) {
+	if !sso.Enabled() {
+		common.ErrorStrResp(c, "sso login is disabled", 403)
+		return
+	}
+	if e := c.Query("error"); e != "" {
+		common.ErrorStrResp(c, fmt.Sprintf("sso login failed: %s %s", e, c.Query("error_description")), 400)
+		return
+	}
+	nonce, ok := ssoStates.GetDel(c.Query("state"))
+	if !ok {
+		common.ErrorStrResp(c, "invalid state", 400)
+		return
+	}
+	idToken, err := sso.Exchange(c, ssoRedirectURI(c), c.Query("code"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	claims, err := sso.Verify(c, idToken, nonce)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user, err := sso.GetUser(claims)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	token, err := common.GenerateToken(user.Username)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	home := utils.StandardizePath(setting.GetStr(conf.BasePath))
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(fmt.Sprintf(`<!DOCTYPE html>
+<html><head><script>
+localStorage.setItem("token", %q);
+location.replace(%q);
+</script></head><body></body></html>`, token, home)))
+}
@@ -11,6 +11,12 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+type StorageResp struct {
+	model.Storage
+	Usage  *model.StorageQuota `json:"usage"`
+	Health *op.StorageHealth   `json:"health"`
+}
+
 func ListStorages(c *gin.Context) {
 	var req common.PageReq
 	if err := c.ShouldBind(&req); err != nil {
@@ -25,11 +31,47 @@ func ListStorages(c *gin.Context) {
 		return
 	}
 	common.SuccessResp(c, common.PageResp{
-		Content: storages,
+		Content: toStorageResp(storages),
 		Total:   total,
 	})
 }
 
+// toStorageResp attach the health and the usage of storages, only the usage in the cache is attached,
+// since most of the providers need to be requested, the others are got by GetStorageUsage
+func toStorageResp(storages []model.Storage) []StorageResp {
+	resp := make([]StorageResp, len(storages))
+	for i := range storages {
+		resp[i].Storage = storages[i]
+		resp[i].Storage.RedactSecrets()
+		if storages[i].Disabled {
+			continue
+		}
+		storageDriver, err := op.GetStorageByVirtualPath(storages[i].MountPath)
+		if err != nil {
+			continue
+		}
+		health := op.GetStorageHealth(storageDriver)
+		resp[i].Health = &health
+		resp[i].Usage = op.GetCachedStorageQuota(storageDriver)
+	}
+	return resp
+}
+
+func GetStorageUsage(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	quota, err := op.GetStorageQuotaById(c, uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, quota)
+}
+
 func CreateStorage(c *gin.Context) {
 	var req model.Storage
 	if err := c.ShouldBind(&req); err != nil {
@@ -110,5 +152,77 @@ func GetStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
+	storage.RedactSecrets()
 	common.SuccessResp(c, storage)
 }
+
+type ClearCacheReq struct {
+	// the whole storage is cleared if the id is given
+	ID   uint   `json:"id" form:"id"`
+	Path string `json:"path" form:"path"`
+}
+
+// ClearStorageCache clear the list cache of a path and the dirs under it, or a whole storage
+func ClearStorageCache(c *gin.Context) {
+	var req ClearCacheReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	path := req.Path
+	if req.ID != 0 {
+		storage, err := db.GetStorageById(req.ID)
+		if err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		path = storage.MountPath
+	}
+	if path == "" {
+		common.ErrorStrResp(c, "either id or path is required", 400)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"count": op.ClearCacheUnder(path),
+	})
+}
+
+// CheckStorageHealth probe the storage now instead of waiting for the periodic probe
+func CheckStorageHealth(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	storage, err := db.GetStorageById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	storageDriver, err := op.GetStorageByVirtualPath(storage.MountPath)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, op.CheckStorageHealth(c, storageDriver))
+}
+
+type StorageTokenReq struct {
+	ID           uint   `json:"id" binding:"required"`
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// UpdateStorageToken supply a new refresh token to the storage with the expired one
+func UpdateStorageToken(c *gin.Context) {
+	var req StorageTokenReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.UpdateStorageToken(c, req.ID, req.RefreshToken); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
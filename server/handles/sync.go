@@ -0,0 +1,44 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/fssync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type SyncReq struct {
+	fssync.Args
+	DryRun bool `json:"dry_run"`
+}
+
+// Sync return the actions to make the dst same as the src if it's a dry run,
+// or add a task to do the sync
+func Sync(c *gin.Context) {
+	var req SyncReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.SrcPath = utils.StandardizePath(req.SrcPath)
+	req.DstPath = utils.StandardizePath(req.DstPath)
+	if utils.IsSubPath(req.SrcPath, req.DstPath) || utils.IsSubPath(req.DstPath, req.SrcPath) {
+		common.ErrorStrResp(c, "the src and dst can't contain each other", 400)
+		return
+	}
+	if !req.DryRun {
+		tid := fssync.Submit(req.Args)
+		common.SuccessResp(c, gin.H{
+			"task_id": tid,
+		})
+		return
+	}
+	actions, err := fssync.Plan(c, req.Args)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"actions": actions,
+	})
+}
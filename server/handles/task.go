@@ -3,8 +3,12 @@ package handles
 import (
 	"strconv"
 
-	"github.com/alist-org/alist/v3/internal/aria2"
+	"github.com/alist-org/alist/v3/internal/archive"
+	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/fssync"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/schedule"
 	"github.com/alist-org/alist/v3/pkg/task"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
@@ -30,17 +34,6 @@ func getTaskInfoUint(task *task.Task[uint64]) TaskInfo {
 	}
 }
 
-func getTaskInfoStr(task *task.Task[string]) TaskInfo {
-	return TaskInfo{
-		ID:       task.ID,
-		Name:     task.Name,
-		State:    task.GetState(),
-		Status:   task.GetStatus(),
-		Progress: task.GetProgress(),
-		Error:    task.GetErrMsg(),
-	}
-}
-
 func getTaskInfosUint(tasks []*task.Task[uint64]) []TaskInfo {
 	var infos []TaskInfo
 	for _, t := range tasks {
@@ -49,25 +42,22 @@ func getTaskInfosUint(tasks []*task.Task[uint64]) []TaskInfo {
 	return infos
 }
 
-func getTaskInfosStr(tasks []*task.Task[string]) []TaskInfo {
-	var infos []TaskInfo
-	for _, t := range tasks {
-		infos = append(infos, getTaskInfoStr(t))
-	}
-	return infos
-}
-
 func UndoneDownTask(c *gin.Context) {
-	common.SuccessResp(c, getTaskInfosStr(aria2.DownTaskManager.ListUndone()))
+	common.SuccessResp(c, getTaskInfosUint(tool.DownTaskManager.ListUndone()))
 }
 
 func DoneDownTask(c *gin.Context) {
-	common.SuccessResp(c, getTaskInfosStr(aria2.DownTaskManager.ListDone()))
+	common.SuccessResp(c, getTaskInfosUint(tool.DownTaskManager.ListDone()))
 }
 
 func CancelDownTask(c *gin.Context) {
-	tid := c.Query("tid")
-	if err := aria2.DownTaskManager.Cancel(tid); err != nil {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := tool.DownTaskManager.Cancel(tid); err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
 		common.SuccessResp(c)
@@ -75,8 +65,13 @@ func CancelDownTask(c *gin.Context) {
 }
 
 func DeleteDownTask(c *gin.Context) {
-	tid := c.Query("tid")
-	if err := aria2.DownTaskManager.Remove(tid); err != nil {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := tool.DownTaskManager.Remove(tid); err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
 		common.SuccessResp(c)
@@ -84,16 +79,16 @@ func DeleteDownTask(c *gin.Context) {
 }
 
 func ClearDoneDownTasks(c *gin.Context) {
-	aria2.DownTaskManager.ClearDone()
+	tool.DownTaskManager.ClearDone()
 	common.SuccessResp(c)
 }
 
 func UndoneTransferTask(c *gin.Context) {
-	common.SuccessResp(c, getTaskInfosUint(aria2.TransferTaskManager.ListUndone()))
+	common.SuccessResp(c, getTaskInfosUint(tool.TransferTaskManager.ListUndone()))
 }
 
 func DoneTransferTask(c *gin.Context) {
-	common.SuccessResp(c, getTaskInfosUint(aria2.TransferTaskManager.ListDone()))
+	common.SuccessResp(c, getTaskInfosUint(tool.TransferTaskManager.ListDone()))
 }
 
 func CancelTransferTask(c *gin.Context) {
@@ -103,7 +98,7 @@ func CancelTransferTask(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := aria2.TransferTaskManager.Cancel(tid); err != nil {
+	if err := tool.TransferTaskManager.Cancel(tid); err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
 		common.SuccessResp(c)
@@ -117,7 +112,7 @@ func DeleteTransferTask(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := aria2.TransferTaskManager.Remove(tid); err != nil {
+	if err := tool.TransferTaskManager.Remove(tid); err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
 		common.SuccessResp(c)
@@ -125,7 +120,48 @@ func DeleteTransferTask(c *gin.Context) {
 }
 
 func ClearDoneTransferTasks(c *gin.Context) {
-	aria2.TransferTaskManager.ClearDone()
+	tool.TransferTaskManager.ClearDone()
+	common.SuccessResp(c)
+}
+
+func UndonePostTask(c *gin.Context) {
+	common.SuccessResp(c, getTaskInfosUint(tool.PostTaskManager.ListUndone()))
+}
+
+func DonePostTask(c *gin.Context) {
+	common.SuccessResp(c, getTaskInfosUint(tool.PostTaskManager.ListDone()))
+}
+
+func CancelPostTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := tool.PostTaskManager.Cancel(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func DeletePostTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := tool.PostTaskManager.Remove(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func ClearDonePostTasks(c *gin.Context) {
+	tool.PostTaskManager.ClearDone()
 	common.SuccessResp(c)
 }
 
@@ -210,3 +246,240 @@ func ClearDoneCopyTasks(c *gin.Context) {
 	fs.CopyTaskManager.ClearDone()
 	common.SuccessResp(c)
 }
+
+func UndoneExtractTask(c *gin.Context) {
+	common.SuccessResp(c, getTaskInfosUint(archive.ExtractTaskManager.ListUndone()))
+}
+
+func DoneExtractTask(c *gin.Context) {
+	common.SuccessResp(c, getTaskInfosUint(archive.ExtractTaskManager.ListDone()))
+}
+
+func CancelExtractTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := archive.ExtractTaskManager.Cancel(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func DeleteExtractTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := archive.ExtractTaskManager.Remove(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func ClearDoneExtractTasks(c *gin.Context) {
+	archive.ExtractTaskManager.ClearDone()
+	common.SuccessResp(c)
+}
+
+func UndoneCompressTask(c *gin.Context) {
+	common.SuccessResp(c, getTaskInfosUint(archive.CompressTaskManager.ListUndone()))
+}
+
+func DoneCompressTask(c *gin.Context) {
+	common.SuccessResp(c, getTaskInfosUint(archive.CompressTaskManager.ListDone()))
+}
+
+func CancelCompressTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := archive.CompressTaskManager.Cancel(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func DeleteCompressTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := archive.CompressTaskManager.Remove(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func ClearDoneCompressTasks(c *gin.Context) {
+	archive.CompressTaskManager.ClearDone()
+	common.SuccessResp(c)
+}
+
+func UndoneMoveTask(c *gin.Context) {
+	common.SuccessResp(c, getTaskInfosUint(fs.MoveTaskManager.ListUndone()))
+}
+
+func DoneMoveTask(c *gin.Context) {
+	common.SuccessResp(c, getTaskInfosUint(fs.MoveTaskManager.ListDone()))
+}
+
+func CancelMoveTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := fs.MoveTaskManager.Cancel(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func RetryMoveTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := fs.MoveTaskManager.Retry(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func DeleteMoveTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := fs.MoveTaskManager.Remove(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func ClearDoneMoveTasks(c *gin.Context) {
+	fs.MoveTaskManager.ClearDone()
+	common.SuccessResp(c)
+}
+
+func UndoneJobTask(c *gin.Context) {
+	common.SuccessResp(c, getTaskInfosUint(schedule.JobTaskManager.ListUndone()))
+}
+
+func DoneJobTask(c *gin.Context) {
+	common.SuccessResp(c, getTaskInfosUint(schedule.JobTaskManager.ListDone()))
+}
+
+func CancelJobTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := schedule.JobTaskManager.Cancel(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func DeleteJobTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := schedule.JobTaskManager.Remove(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func ClearDoneJobTasks(c *gin.Context) {
+	schedule.JobTaskManager.ClearDone()
+	common.SuccessResp(c)
+}
+
+func UndoneSyncTask(c *gin.Context) {
+	common.SuccessResp(c, getTaskInfosUint(fssync.SyncTaskManager.ListUndone()))
+}
+
+func DoneSyncTask(c *gin.Context) {
+	common.SuccessResp(c, getTaskInfosUint(fssync.SyncTaskManager.ListDone()))
+}
+
+func CancelSyncTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := fssync.SyncTaskManager.Cancel(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func DeleteSyncTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := fssync.SyncTaskManager.Remove(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func ClearDoneSyncTasks(c *gin.Context) {
+	fssync.SyncTaskManager.ClearDone()
+	common.SuccessResp(c)
+}
+
+// ListInterruptedTasks list the tasks not finished when the server was shut down
+func ListInterruptedTasks(c *gin.Context) {
+	tasks, err := db.GetInterruptedTasks()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, tasks)
+}
+
+func ClearInterruptedTasks(c *gin.Context) {
+	if err := db.DeleteInterruptedTasks(); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
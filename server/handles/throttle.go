@@ -0,0 +1,28 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/throttle"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// ListThrottledClients list the ips counted by the throttle and the bans of them
+func ListThrottledClients(c *gin.Context) {
+	common.SuccessResp(c, throttle.List())
+}
+
+type UnbanReq struct {
+	IP string `json:"ip" binding:"required"`
+	// the kind to unban, all the kinds if it's empty
+	Kind string `json:"kind"`
+}
+
+func UnbanClient(c *gin.Context) {
+	var req UnbanReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	throttle.Unban(req.IP, req.Kind)
+	common.SuccessResp(c)
+}
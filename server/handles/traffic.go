@@ -0,0 +1,88 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListTrafficQuotas(c *gin.Context) {
+	quotas, err := db.GetTrafficQuotas()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, quotas)
+}
+
+func CreateTrafficQuota(c *gin.Context) {
+	var req model.TrafficQuota
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := ratelimit.CreateTrafficQuota(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c, gin.H{
+			"id": req.ID,
+		})
+	}
+}
+
+func UpdateTrafficQuota(c *gin.Context) {
+	var req model.TrafficQuota
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := ratelimit.UpdateTrafficQuota(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func DeleteTrafficQuota(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := ratelimit.DeleteTrafficQuotaById(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// GetTrafficUsage get the traffic of the user in the current day and month
+func GetTrafficUsage(c *gin.Context) {
+	idStr := c.Query("user_id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, ratelimit.GetTrafficUsages(uint(id)))
+}
+
+// ResetTrafficUsage clear the traffic of the user in the current day and month
+func ResetTrafficUsage(c *gin.Context) {
+	idStr := c.Query("user_id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := ratelimit.ResetTrafficUsages(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
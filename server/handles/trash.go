@@ -0,0 +1,116 @@
+package handles
+
+import (
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type TrashListReq struct {
+	common.PageReq
+	Path string `json:"path" form:"path"`
+}
+
+type TrashObjResp struct {
+	Id       string    `json:"id"`
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	IsDir    bool      `json:"is_dir"`
+	Modified time.Time `json:"modified"`
+	Thumb    string    `json:"thumb"`
+	Type     int       `json:"type"`
+}
+
+func FsTrashList(c *gin.Context) {
+	var req TrashListReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	req.Path = stdpath.Join(user.BasePath, req.Path)
+	objs, err := fs.ListTrash(c, req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	total, objs := pagination(objs, &req.PageReq)
+	common.SuccessResp(c, common.PageResp{
+		Content: toTrashObjResp(objs),
+		Total:   int64(total),
+	})
+}
+
+type TrashReq struct {
+	Path string   `json:"path"`
+	Ids  []string `json:"ids"`
+}
+
+func FsTrashRestore(c *gin.Context) {
+	var req TrashReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if len(req.Ids) == 0 {
+		common.ErrorStrResp(c, "Empty file ids", 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	req.Path = stdpath.Join(user.BasePath, req.Path)
+	if err := fs.RestoreTrash(c, req.Path, req.Ids); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func FsTrashPurge(c *gin.Context) {
+	var req TrashReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if len(req.Ids) == 0 {
+		common.ErrorStrResp(c, "Empty file ids", 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	req.Path = stdpath.Join(user.BasePath, req.Path)
+	if err := fs.PurgeTrash(c, req.Path, req.Ids); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func toTrashObjResp(objs []model.Obj) []TrashObjResp {
+	resp := make([]TrashObjResp, 0, len(objs))
+	for _, obj := range objs {
+		thumb := ""
+		if t, ok := obj.(model.Thumb); ok {
+			thumb = t.Thumb()
+		}
+		tp := conf.FOLDER
+		if !obj.IsDir() {
+			tp = utils.GetFileType(obj.GetName())
+		}
+		resp = append(resp, TrashObjResp{
+			Id:       obj.GetID(),
+			Name:     obj.GetName(),
+			Size:     obj.GetSize(),
+			IsDir:    obj.IsDir(),
+			Modified: obj.ModTime(),
+			Thumb:    thumb,
+			Type:     tp,
+		})
+	}
+	return resp
+}
@@ -39,6 +39,10 @@ func CreateUser(c *gin.Context) {
 		common.ErrorStrResp(c, "admin or guest user can not be created", 400, true)
 		return
 	}
+	if err := req.IPAcl.Validate(); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
 	if err := db.CreateUser(&req); err != nil {
 		common.ErrorResp(c, err, 500, true)
 	} else {
@@ -61,12 +65,18 @@ func UpdateUser(c *gin.Context) {
 		common.ErrorStrResp(c, "role can not be changed", 400)
 		return
 	}
+	if err := req.IPAcl.Validate(); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
 	if req.Password == "" {
 		req.Password = user.Password
 	}
 	if req.OtpSecret == "" {
 		req.OtpSecret = user.OtpSecret
 	}
+	// the recovery codes aren't in the request, they are kept with the 2FA
+	req.OtpRecoveryCodes = user.OtpRecoveryCodes
 	if err := db.UpdateUser(&req); err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
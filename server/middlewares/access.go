@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog record the download by the link after it's served, the path
+// and the share of the link are set by the previous handlers
+func AccessLog(c *gin.Context) {
+	c.Next()
+	path := c.GetString("path")
+	status := c.Writer.Status()
+	// the errors are responded in json with the status 200 and aborted
+	if path == "" || status >= 400 || c.IsAborted() {
+		return
+	}
+	l := model.AccessLog{
+		Link:       model.AccessDirect,
+		Path:       path,
+		IP:         c.ClientIP(),
+		Status:     status,
+		Redirected: status >= 300,
+	}
+	if share, ok := c.Get("share"); ok {
+		l.Link, l.ShareID = model.AccessShare, share.(*model.Share).ID
+	}
+	if user, ok := c.Get("user"); ok {
+		l.UserID = user.(*model.User).ID
+	}
+	if rg := c.GetHeader("Range"); rg != "" && !strings.HasPrefix(rg, "bytes=0-") {
+		l.Partial = true
+	}
+	if !l.Redirected && c.Writer.Size() > 0 {
+		l.Bytes = int64(c.Writer.Size())
+	}
+	audit.RecordAccess(l)
+}
@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"github.com/alist-org/alist/v3/internal/antileech"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// AntiLeech reject the download by the referer and the user agent, it's after Down setting the path
+func AntiLeech(c *gin.Context) {
+	req := antileech.Request{
+		Referer:   c.Request.Referer(),
+		UserAgent: c.Request.UserAgent(),
+		Host:      c.Request.Host,
+	}
+	if storage, err := fs.GetStorage(c.MustGet("path").(string)); err == nil {
+		req.StorageID = storage.GetStorage().ID
+	}
+	redirect, err := antileech.Check(req)
+	if err == nil {
+		c.Next()
+		return
+	}
+	if redirect != "" {
+		c.Redirect(302, redirect)
+	} else {
+		common.ErrorResp(c, err, 403)
+	}
+	c.Abort()
+}
@@ -0,0 +1,13 @@
+package middlewares
+
+import (
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/gin-gonic/gin"
+)
+
+// ClientIP set the ip of the client to the context of the request,
+// so the operations requested by the client are recorded in the audit log
+func ClientIP(c *gin.Context) {
+	c.Request = c.Request.WithContext(audit.WithIP(c.Request.Context(), c.ClientIP()))
+	c.Next()
+}
@@ -1,12 +1,16 @@
 package middlewares
 
 import (
+	"strings"
+
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/sso"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -21,6 +25,9 @@ func Auth(c *gin.Context) {
 			c.Abort()
 			return
 		}
+		if !allowIP(c, admin) {
+			return
+		}
 		c.Set("user", admin)
 		log.Debugf("use admin token: %+v", admin)
 		c.Next()
@@ -33,13 +40,42 @@ func Auth(c *gin.Context) {
 			c.Abort()
 			return
 		}
+		if !allowIP(c, guest) {
+			return
+		}
 		c.Set("user", guest)
 		log.Debugf("use empty token: %+v", guest)
 		c.Next()
 		return
 	}
+	if strings.HasPrefix(token, common.ApiTokenPrefix) {
+		user, apiToken, err := apiTokenUser(token)
+		if err != nil {
+			common.ErrorResp(c, err, 401)
+			c.Abort()
+			return
+		}
+		if !allowIP(c, user) {
+			return
+		}
+		c.Set("user", user)
+		c.Set("api_token", apiToken)
+		log.Debugf("use api token %d: %+v", apiToken.ID, user)
+		c.Next()
+		return
+	}
 	userClaims, err := common.ParseToken(token)
 	if err != nil {
+		// maybe an id token of the oidc provider
+		if user, ssoErr := ssoUser(c, token); ssoErr == nil {
+			if !allowIP(c, user) {
+				return
+			}
+			c.Set("user", user)
+			log.Debugf("use sso token: %+v", user)
+			c.Next()
+			return
+		}
 		common.ErrorResp(c, err, 401)
 		c.Abort()
 		return
@@ -50,12 +86,62 @@ func Auth(c *gin.Context) {
 		c.Abort()
 		return
 	}
+	if !allowIP(c, user) {
+		return
+	}
 	c.Set("user", user)
 	log.Debugf("use login token: %+v", user)
 	c.Next()
 }
 
+// allowIP check if the user can access from the ip of the client, it's responded with 403 if not
+func allowIP(c *gin.Context, user *model.User) bool {
+	if user.AllowIP(c.ClientIP()) {
+		return true
+	}
+	common.ErrorStrResp(c, "You can't access from this ip", 403)
+	c.Abort()
+	return false
+}
+
+// apiTokenUser get the effective user of the api token
+func apiTokenUser(token string) (*model.User, *model.ApiToken, error) {
+	apiToken, err := db.GetApiTokenByToken(token)
+	if err != nil {
+		return nil, nil, errors.New("invalid api token")
+	}
+	if apiToken.Expired() {
+		return nil, nil, errors.New("api token is expired")
+	}
+	user, err := db.GetUserById(apiToken.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err = db.GetUserByName(user.Username)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, apiToken, nil
+}
+
+// ssoUser get the user of the id token issued by the oidc provider, the token can be a bearer token
+func ssoUser(c *gin.Context, token string) (*model.User, error) {
+	if !sso.Enabled() {
+		return nil, errors.New("sso login is disabled")
+	}
+	claims, err := sso.Verify(c, strings.TrimPrefix(token, "Bearer "), "")
+	if err != nil {
+		return nil, err
+	}
+	return sso.GetUser(claims)
+}
+
 func AuthAdmin(c *gin.Context) {
+	if _, ok := c.Get("api_token"); ok {
+		common.ErrorStrResp(c, "The api token can not access the admin api", 403)
+		c.Abort()
+		return
+	}
 	user := c.MustGet("user").(*model.User)
 	if !user.IsAdmin() {
 		common.ErrorStrResp(c, "You are not an admin", 403)
@@ -64,3 +150,13 @@ func AuthAdmin(c *gin.Context) {
 		c.Next()
 	}
 }
+
+// NoApiToken reject the requests authorized by the api token
+func NoApiToken(c *gin.Context) {
+	if _, ok := c.Get("api_token"); ok {
+		common.ErrorStrResp(c, "The api token can not access this api", 403)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
@@ -1,7 +1,6 @@
 package middlewares
 
 import (
-	stdpath "path"
 	"strings"
 
 	"github.com/alist-org/alist/v3/internal/db"
@@ -15,9 +14,16 @@ import (
 )
 
 func Down(c *gin.Context) {
-	rawPath := parsePath(c.Param("path"))
+	down(c, parsePath(c.Param("path")))
+}
+
+// DownQuery is Down for the path in the query, such as the thumbnails
+func DownQuery(c *gin.Context) {
+	down(c, parsePath(c.Query("path")))
+}
+
+func down(c *gin.Context, rawPath string) {
 	c.Set("path", rawPath)
-	filename := stdpath.Base(rawPath)
 	meta, err := db.GetNearestMeta(rawPath)
 	if err != nil {
 		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
@@ -29,7 +35,7 @@ func Down(c *gin.Context) {
 	// verify sign
 	if needSign(meta, rawPath) {
 		s := c.Query("sign")
-		err = sign.Verify(filename, strings.TrimSuffix(s, "/"))
+		err = sign.Verify(rawPath, c.ClientIP(), strings.TrimSuffix(s, "/"))
 		if err != nil {
 			common.ErrorResp(c, err, 401)
 			c.Abort()
@@ -46,6 +52,9 @@ func parsePath(path string) string {
 }
 
 func needSign(meta *model.Meta, path string) bool {
+	if sign.Required(path) {
+		return true
+	}
 	if meta == nil || meta.Password == "" {
 		return false
 	}
@@ -0,0 +1,20 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics record the count and the duration of the requests by the route
+func Metrics(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+	route := c.FullPath()
+	if route == "" {
+		// the unmatched paths aren't recorded separately to limit the series
+		route = "unmatched"
+	}
+	metrics.ObserveRequest(route, c.Request.Method, c.Writer.Status(), time.Since(start))
+}
@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/throttle"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// Throttle limit the requests of the client ip by the kind, the banned ip is responded with 429
+func Throttle(kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		left, err := throttle.Allow(kind, c.ClientIP())
+		if err == nil {
+			c.Next()
+			return
+		}
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(left.Seconds()))))
+		common.ErrorResp(c, err, 429)
+		c.Abort()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/alist-org/alist/v3/cmd/flags"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/message"
+	"github.com/alist-org/alist/v3/internal/throttle"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/alist/v3/server/handles"
 	"github.com/alist-org/alist/v3/server/middlewares"
@@ -15,28 +16,57 @@ import (
 func Init(r *gin.Engine) {
 	common.SecretKey = []byte(conf.Conf.JwtSecret)
 	Cors(r)
+	r.Use(middlewares.Metrics, middlewares.ClientIP)
 	r.Use(middlewares.StoragesLoaded)
 	WebDav(r.Group("/dav"))
 
 	r.GET("/favicon.ico", handles.Favicon)
+	r.GET("/metrics", handles.Metrics)
 	r.GET("/i/:link/:name", handles.Plist)
-	r.GET("/d/*path", middlewares.Down, handles.Down)
-	r.GET("/p/*path", middlewares.Down, handles.Proxy)
+	link := middlewares.Throttle(throttle.KindLink)
+	r.GET("/d/*path", link, middlewares.Down, middlewares.AntiLeech, middlewares.AccessLog, handles.Down)
+	r.GET("/p/*path", link, middlewares.Down, middlewares.AntiLeech, middlewares.AccessLog, handles.Proxy)
+	r.GET("/ap/*path", link, middlewares.Down, middlewares.AntiLeech, middlewares.AccessLog, handles.ArchiveProxy)
+	r.GET("/sd/:key/*path", link, handles.ShareDownPath, middlewares.AntiLeech, middlewares.AccessLog, handles.ShareDown)
 
-	api := r.Group("/api")
+	api := r.Group("/api", middlewares.Throttle(throttle.KindApi))
 	auth := api.Group("", middlewares.Auth)
 
 	api.POST("/auth/login", handles.Login)
+	api.GET("/auth/sso/login", handles.SSOLogin)
+	api.GET("/auth/sso/callback", handles.SSOCallback)
+	// the thumbnails, the subtitles and the videos are loaded by the players without the token
+	api.GET("/fs/thumb", middlewares.DownQuery, handles.FsThumb)
+	api.GET("/fs/subtitle", middlewares.DownQuery, handles.FsSubtitle)
+	api.GET("/fs/transcode", middlewares.DownQuery, handles.FsTranscode)
+	api.GET("/fs/transcode/:id/:file", handles.FsTranscodeFile)
 	auth.GET("/me", handles.CurrentUser)
-	auth.POST("/me/update", handles.UpdateCurrent)
-	auth.POST("/auth/2fa/generate", handles.Generate2FA)
-	auth.POST("/auth/2fa/verify", handles.Verify2FA)
+	// the account can't be managed with the api token
+	account := auth.Group("", middlewares.NoApiToken)
+	account.POST("/me/update", handles.UpdateCurrent)
+	account.POST("/auth/2fa/generate", handles.Generate2FA)
+	account.POST("/auth/2fa/verify", handles.Verify2FA)
+	account.POST("/auth/2fa/recovery_codes", handles.RegenerateRecoveryCodes)
+	account.GET("/me/app_password/list", handles.ListAppPasswords)
+	account.POST("/me/app_password/create", handles.CreateAppPassword)
+	account.POST("/me/app_password/delete", handles.DeleteAppPassword)
+	account.GET("/me/api_token/list", handles.ListApiTokens)
+	account.POST("/me/api_token/create", handles.CreateApiToken)
+	account.POST("/me/api_token/delete", handles.DeleteApiToken)
 
 	// no need auth
 	public := api.Group("/public")
 	public.Any("/settings", handles.PublicSettings)
+	public.GET("/drop/info", handles.FileDropInfo)
+	public.POST("/share/get", handles.ShareGet)
+	public.PUT("/drop/upload", handles.FileDropUpload)
 
 	_fs(auth.Group("/fs"))
+	share := auth.Group("/share")
+	share.GET("/list", handles.ListShares)
+	share.POST("/create", handles.CreateShare)
+	share.POST("/update", handles.UpdateShare)
+	share.POST("/delete", handles.DeleteShare)
 	admin(auth.Group("/admin", middlewares.AuthAdmin))
 	if flags.Dev {
 		dev(r.Group("/dev"))
@@ -52,6 +82,79 @@ func admin(g *gin.RouterGroup) {
 	meta.POST("/update", handles.UpdateMeta)
 	meta.POST("/delete", handles.DeleteMeta)
 
+	g.POST("/sync", handles.Sync)
+
+	checksum := g.Group("/checksum")
+	checksum.POST("/manifest", handles.BuildManifest)
+	checksum.POST("/verify", handles.VerifyManifest)
+
+	job := g.Group("/job")
+	job.GET("/list", handles.ListJobs)
+	job.GET("/get", handles.GetJob)
+	job.POST("/create", handles.CreateJob)
+	job.POST("/update", handles.UpdateJob)
+	job.POST("/delete", handles.DeleteJob)
+	job.POST("/run", handles.RunJob)
+	job.GET("/runs", handles.ListJobRuns)
+
+	g.GET("/audit/list", handles.ListAuditLogs)
+	g.POST("/reload", handles.Reload)
+	g.POST("/backup/export", handles.ExportBackup)
+	g.POST("/backup/import", handles.ImportBackup)
+	g.GET("/access/report", handles.AccessReport)
+
+	rateLimit := g.Group("/rate_limit")
+	rateLimit.GET("/list", handles.ListRateLimits)
+	rateLimit.POST("/create", handles.CreateRateLimit)
+	rateLimit.POST("/update", handles.UpdateRateLimit)
+	rateLimit.POST("/delete", handles.DeleteRateLimit)
+
+	antiLeech := g.Group("/anti_leech")
+	antiLeech.GET("/list", handles.ListAntiLeeches)
+	antiLeech.POST("/create", handles.CreateAntiLeech)
+	antiLeech.POST("/update", handles.UpdateAntiLeech)
+	antiLeech.POST("/delete", handles.DeleteAntiLeech)
+
+	throttled := g.Group("/throttle")
+	throttled.GET("/list", handles.ListThrottledClients)
+	throttled.POST("/unban", handles.UnbanClient)
+
+	trafficQuota := g.Group("/traffic_quota")
+	trafficQuota.GET("/list", handles.ListTrafficQuotas)
+	trafficQuota.POST("/create", handles.CreateTrafficQuota)
+	trafficQuota.POST("/update", handles.UpdateTrafficQuota)
+	trafficQuota.POST("/delete", handles.DeleteTrafficQuota)
+	trafficQuota.GET("/usage", handles.GetTrafficUsage)
+	trafficQuota.POST("/reset", handles.ResetTrafficUsage)
+
+	webhook := g.Group("/webhook")
+	webhook.GET("/list", handles.ListWebhooks)
+	webhook.POST("/create", handles.CreateWebhook)
+	webhook.POST("/update", handles.UpdateWebhook)
+	webhook.POST("/delete", handles.DeleteWebhook)
+	webhook.POST("/test", handles.TestWebhook)
+
+	hook := g.Group("/hook")
+	hook.GET("/list", handles.ListHooks)
+	hook.POST("/create", handles.CreateHook)
+	hook.POST("/update", handles.UpdateHook)
+	hook.POST("/delete", handles.DeleteHook)
+	hook.POST("/check", handles.CheckHook)
+
+	g.GET("/share/list", handles.ListAllShares)
+
+	drop := g.Group("/drop")
+	drop.GET("/list", handles.ListFileDrops)
+	drop.POST("/create", handles.CreateFileDrop)
+	drop.POST("/update", handles.UpdateFileDrop)
+	drop.POST("/delete", handles.DeleteFileDrop)
+
+	acl := g.Group("/acl")
+	acl.GET("/list", handles.ListAclRules)
+	acl.POST("/create", handles.CreateAclRule)
+	acl.POST("/update", handles.UpdateAclRule)
+	acl.POST("/delete", handles.DeleteAclRule)
+
 	user := g.Group("/user")
 	user.GET("/list", handles.ListUsers)
 	user.GET("/get", handles.GetUser)
@@ -59,6 +162,15 @@ func admin(g *gin.RouterGroup) {
 	user.POST("/update", handles.UpdateUser)
 	user.POST("/cancel_2fa", handles.Cancel2FAById)
 	user.POST("/delete", handles.DeleteUser)
+	user.GET("/groups", handles.UserGroups)
+	user.POST("/set_groups", handles.SetUserGroups)
+
+	group := g.Group("/group")
+	group.GET("/list", handles.ListGroups)
+	group.GET("/users", handles.GroupUsers)
+	group.POST("/create", handles.CreateGroup)
+	group.POST("/update", handles.UpdateGroup)
+	group.POST("/delete", handles.DeleteGroup)
 
 	storage := g.Group("/storage")
 	storage.GET("/list", handles.ListStorages)
@@ -68,11 +180,21 @@ func admin(g *gin.RouterGroup) {
 	storage.POST("/delete", handles.DeleteStorage)
 	storage.POST("/enable", handles.EnableStorage)
 	storage.POST("/disable", handles.DisableStorage)
+	storage.GET("/usage", handles.GetStorageUsage)
+	storage.POST("/clear_cache", handles.ClearStorageCache)
+	storage.POST("/check", handles.CheckStorageHealth)
+	storage.POST("/token", handles.UpdateStorageToken)
+
+	s3 := g.Group("/s3")
+	s3.GET("/list", handles.ListS3Keys)
+	s3.POST("/create", handles.CreateS3Key)
+	s3.POST("/delete", handles.DeleteS3Key)
 
 	driver := g.Group("/driver")
 	driver.GET("/list", handles.ListDriverInfo)
 	driver.GET("/names", handles.ListDriverNames)
 	driver.GET("/info", handles.GetDriverInfo)
+	driver.GET("/plugins", handles.ListPlugins)
 
 	setting := g.Group("/setting")
 	setting.GET("/get", handles.GetSetting)
@@ -81,6 +203,14 @@ func admin(g *gin.RouterGroup) {
 	setting.POST("/delete", handles.DeleteSetting)
 	setting.POST("/reset_token", handles.ResetToken)
 	setting.POST("/set_aria2", handles.SetAria2)
+	setting.POST("/set_qbit", handles.SetQbittorrent)
+
+	index := g.Group("/index")
+	index.POST("/build", handles.BuildIndex)
+	index.POST("/update", handles.UpdateIndex)
+	index.POST("/stop", handles.StopIndex)
+	index.POST("/clear", handles.ClearIndex)
+	index.GET("/progress", handles.GetIndexProgress)
 
 	task := g.Group("/task")
 	task.GET("/down/undone", handles.UndoneDownTask)
@@ -93,6 +223,11 @@ func admin(g *gin.RouterGroup) {
 	task.POST("/transfer/cancel", handles.CancelTransferTask)
 	task.POST("/transfer/delete", handles.DeleteTransferTask)
 	task.POST("/transfer/clear_done", handles.ClearDoneTransferTasks)
+	task.GET("/post/undone", handles.UndonePostTask)
+	task.GET("/post/done", handles.DonePostTask)
+	task.POST("/post/cancel", handles.CancelPostTask)
+	task.POST("/post/delete", handles.DeletePostTask)
+	task.POST("/post/clear_done", handles.ClearDonePostTasks)
 	task.GET("/upload/undone", handles.UndoneUploadTask)
 	task.GET("/upload/done", handles.DoneUploadTask)
 	task.POST("/upload/cancel", handles.CancelUploadTask)
@@ -103,6 +238,34 @@ func admin(g *gin.RouterGroup) {
 	task.POST("/copy/cancel", handles.CancelCopyTask)
 	task.POST("/copy/delete", handles.DeleteCopyTask)
 	task.POST("/copy/clear_done", handles.ClearDoneCopyTasks)
+	task.GET("/extract/undone", handles.UndoneExtractTask)
+	task.GET("/extract/done", handles.DoneExtractTask)
+	task.POST("/extract/cancel", handles.CancelExtractTask)
+	task.POST("/extract/delete", handles.DeleteExtractTask)
+	task.POST("/extract/clear_done", handles.ClearDoneExtractTasks)
+	task.GET("/compress/undone", handles.UndoneCompressTask)
+	task.GET("/compress/done", handles.DoneCompressTask)
+	task.POST("/compress/cancel", handles.CancelCompressTask)
+	task.POST("/compress/delete", handles.DeleteCompressTask)
+	task.POST("/compress/clear_done", handles.ClearDoneCompressTasks)
+	task.GET("/move/undone", handles.UndoneMoveTask)
+	task.GET("/move/done", handles.DoneMoveTask)
+	task.POST("/move/cancel", handles.CancelMoveTask)
+	task.POST("/move/retry", handles.RetryMoveTask)
+	task.POST("/move/delete", handles.DeleteMoveTask)
+	task.POST("/move/clear_done", handles.ClearDoneMoveTasks)
+	task.GET("/job/undone", handles.UndoneJobTask)
+	task.GET("/job/done", handles.DoneJobTask)
+	task.POST("/job/cancel", handles.CancelJobTask)
+	task.POST("/job/delete", handles.DeleteJobTask)
+	task.POST("/job/clear_done", handles.ClearDoneJobTasks)
+	task.GET("/sync/undone", handles.UndoneSyncTask)
+	task.GET("/sync/done", handles.DoneSyncTask)
+	task.POST("/sync/cancel", handles.CancelSyncTask)
+	task.POST("/sync/delete", handles.DeleteSyncTask)
+	task.POST("/sync/clear_done", handles.ClearDoneSyncTasks)
+	task.GET("/interrupted/list", handles.ListInterruptedTasks)
+	task.POST("/interrupted/clear", handles.ClearInterruptedTasks)
 
 	ms := g.Group("/message")
 	ms.POST("/get", message.HttpInstance.GetHandle)
@@ -111,17 +274,40 @@ func admin(g *gin.RouterGroup) {
 
 func _fs(g *gin.RouterGroup) {
 	g.Any("/list", handles.FsList)
-	g.Any("/get", handles.FsGet)
+	g.Any("/get", middlewares.Throttle(throttle.KindLink), handles.FsGet)
 	g.Any("/other", handles.FsOther)
+	g.Any("/video_preview", handles.FsVideoPreview)
+	g.Any("/media_info", handles.FsMediaInfo)
+	g.Any("/timeline", handles.FsTimeline)
 	g.Any("/dirs", handles.FsDirs)
+	g.GET("/zip", handles.FsZip)
+	g.Any("/archive/list", handles.FsArchiveList)
+	g.POST("/archive/extract", handles.FsArchiveExtract)
+	g.POST("/archive/compress", handles.FsArchiveCompress)
+	g.POST("/search", handles.FsSearch)
 	g.POST("/mkdir", handles.FsMkdir)
 	g.POST("/rename", handles.FsRename)
 	g.POST("/move", handles.FsMove)
 	g.POST("/copy", handles.FsCopy)
 	g.POST("/remove", handles.FsRemove)
 	g.PUT("/put", handles.FsPut)
-	g.POST("/link", middlewares.AuthAdmin, handles.Link)
+	upload := g.Group("/upload")
+	upload.POST("/create", handles.FsUploadCreate)
+	upload.GET("/status", handles.FsUploadStatus)
+	upload.PUT("/chunk", handles.FsUploadChunk)
+	upload.POST("/complete", handles.FsUploadComplete)
+	upload.POST("/cancel", handles.FsUploadCancel)
+	upload.POST("/direct", handles.FsDirectUpload)
+	upload.POST("/direct/complete", handles.FsDirectUploadComplete)
+	g.POST("/link", middlewares.AuthAdmin, middlewares.Throttle(throttle.KindLink), handles.Link)
 	g.POST("/add_aria2", handles.AddAria2)
+	g.POST("/add_offline_download", handles.AddOfflineDownload)
+	g.GET("/offline_download_tools", handles.OfflineDownloadTools)
+
+	trash := g.Group("/trash", middlewares.AuthAdmin)
+	trash.POST("/list", handles.FsTrashList)
+	trash.POST("/restore", handles.FsTrashRestore)
+	trash.POST("/purge", handles.FsTrashPurge)
 }
 
 func Cors(r *gin.Engine) {
@@ -0,0 +1,309 @@
+package s3
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"net/http"
+	stdpath "path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+const (
+	xmlns           = "http://s3.amazonaws.com/doc/2006-03-01/"
+	timeFormat      = "2006-01-02T15:04:05.000Z"
+	maxKeysDefault  = 1000
+	storageStandard = "STANDARD"
+)
+
+type owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+func toOwner(user *model.User) owner {
+	return owner{ID: strconv.Itoa(int(user.ID)), DisplayName: user.Username}
+}
+
+type bucketInfo struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+type listAllMyBucketsResult struct {
+	XMLName xml.Name     `xml:"ListAllMyBucketsResult"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Owner   owner        `xml:"Owner"`
+	Buckets []bucketInfo `xml:"Buckets>Bucket"`
+}
+
+type objectInfo struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type listBucketResult struct {
+	XMLName        xml.Name       `xml:"ListBucketResult"`
+	Xmlns          string         `xml:"xmlns,attr"`
+	Name           string         `xml:"Name"`
+	Prefix         string         `xml:"Prefix"`
+	Delimiter      string         `xml:"Delimiter,omitempty"`
+	MaxKeys        int            `xml:"MaxKeys"`
+	EncodingType   string         `xml:"EncodingType,omitempty"`
+	IsTruncated    bool           `xml:"IsTruncated"`
+	Contents       []objectInfo   `xml:"Contents"`
+	CommonPrefixes []commonPrefix `xml:"CommonPrefixes"`
+	// v1
+	Marker     *string `xml:"Marker"`
+	NextMarker string  `xml:"NextMarker,omitempty"`
+	// v2
+	KeyCount              *int   `xml:"KeyCount"`
+	ContinuationToken     string `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string `xml:"NextContinuationToken,omitempty"`
+	StartAfter            string `xml:"StartAfter,omitempty"`
+}
+
+type locationConstraint struct {
+	XMLName xml.Name `xml:"LocationConstraint"`
+	Xmlns   string   `xml:"xmlns,attr"`
+}
+
+// etag of the object, it's not the md5 of the content, so contains `-`
+// to make the clients like rclone don't check it as md5
+func etag(obj model.Obj) string {
+	return `"` + strconv.FormatInt(obj.ModTime().UnixNano(), 16) + "-" + strconv.FormatInt(obj.GetSize(), 16) + `"`
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+func getBucket(ctx context.Context, user *model.User, bucket string) (model.Obj, error) {
+	obj, err := fs.Get(ctx, bucketPath(user, bucket))
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			return nil, ErrNoSuchBucket
+		}
+		return nil, err
+	}
+	if !obj.IsDir() {
+		return nil, ErrNoSuchBucket
+	}
+	return obj, nil
+}
+
+// listDir list the objects in dir with the meta of it, just like the webdav does
+func listDir(ctx context.Context, path string) ([]model.Obj, error) {
+	meta, _ := db.GetNearestMeta(path)
+	return fs.List(context.WithValue(ctx, "meta", meta), path)
+}
+
+func (s *Server) listBuckets(w http.ResponseWriter, r *http.Request) {
+	user := getUser(r.Context())
+	objs, err := listDir(r.Context(), user.BasePath)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	res := listAllMyBucketsResult{
+		Xmlns:   xmlns,
+		Owner:   toOwner(user),
+		Buckets: make([]bucketInfo, 0, len(objs)),
+	}
+	for _, obj := range objs {
+		if !obj.IsDir() {
+			continue
+		}
+		res.Buckets = append(res.Buckets, bucketInfo{
+			Name:         obj.GetName(),
+			CreationDate: formatTime(obj.ModTime()),
+		})
+	}
+	writeXML(w, http.StatusOK, res)
+}
+
+func (s *Server) headBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	if _, err := getBucket(r.Context(), getUser(r.Context()), bucket); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) getBucketLocation(w http.ResponseWriter, r *http.Request, bucket string) {
+	if _, err := getBucket(r.Context(), getUser(r.Context()), bucket); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeXML(w, http.StatusOK, locationConstraint{Xmlns: xmlns})
+}
+
+type entry struct {
+	Key string
+	// nil if the entry is a common prefix
+	Obj model.Obj
+}
+
+// listEntries list the objects with `prefix` in the dir `root`,
+// the sub dirs are returned as common prefixes if delimiter is `/`, or walked into if it's empty
+func listEntries(ctx context.Context, root, prefix, delimiter string) ([]entry, error) {
+	var res []entry
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		objs, err := listDir(ctx, stdpath.Join(root, dir))
+		if err != nil {
+			if errs.IsObjectNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		for _, obj := range objs {
+			key := dir + obj.GetName()
+			if obj.IsDir() {
+				key += "/"
+			}
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			if !obj.IsDir() {
+				res = append(res, entry{Key: key, Obj: obj})
+				continue
+			}
+			if delimiter != "" {
+				res = append(res, entry{Key: key})
+				continue
+			}
+			if err := walk(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// start from the deepest dir the prefix determined
+	dir := ""
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		dir = prefix[:i+1]
+	}
+	if err := walk(dir); err != nil {
+		return nil, err
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Key < res[j].Key
+	})
+	return res, nil
+}
+
+func (s *Server) listObjects(w http.ResponseWriter, r *http.Request, bucket string) {
+	ctx := r.Context()
+	user := getUser(ctx)
+	query := r.URL.Query()
+	if _, err := getBucket(ctx, user, bucket); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+	if delimiter != "" && delimiter != "/" {
+		writeError(w, r, ErrNotImplemented)
+		return
+	}
+	maxKeys := maxKeysDefault
+	if mk := query.Get("max-keys"); mk != "" {
+		n, err := strconv.Atoi(mk)
+		if err != nil || n < 0 {
+			writeError(w, r, ErrInvalidArgument)
+			return
+		}
+		if n < maxKeys {
+			maxKeys = n
+		}
+	}
+	res := listBucketResult{
+		Xmlns:        xmlns,
+		Name:         bucket,
+		Prefix:       prefix,
+		Delimiter:    delimiter,
+		MaxKeys:      maxKeys,
+		EncodingType: query.Get("encoding-type"),
+	}
+	v2 := query.Get("list-type") == "2"
+	var marker string
+	if v2 {
+		res.ContinuationToken = query.Get("continuation-token")
+		res.StartAfter = query.Get("start-after")
+		if res.ContinuationToken != "" {
+			token, err := base64.StdEncoding.DecodeString(res.ContinuationToken)
+			if err != nil {
+				writeError(w, r, ErrInvalidArgument)
+				return
+			}
+			marker = string(token)
+		} else {
+			marker = res.StartAfter
+		}
+	} else {
+		marker = query.Get("marker")
+		res.Marker = &marker
+	}
+	entries, err := listEntries(ctx, bucketPath(user, bucket), prefix, delimiter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if marker != "" {
+		i := sort.Search(len(entries), func(i int) bool {
+			return entries[i].Key > marker
+		})
+		entries = entries[i:]
+	}
+	if len(entries) > maxKeys {
+		entries = entries[:maxKeys]
+		res.IsTruncated = true
+	}
+	encodeKey := func(key string) string {
+		if res.EncodingType == "url" {
+			return encode(key, false)
+		}
+		return key
+	}
+	for _, e := range entries {
+		if e.Obj == nil {
+			res.CommonPrefixes = append(res.CommonPrefixes, commonPrefix{Prefix: encodeKey(e.Key)})
+			continue
+		}
+		res.Contents = append(res.Contents, objectInfo{
+			Key:          encodeKey(e.Key),
+			LastModified: formatTime(e.Obj.ModTime()),
+			ETag:         etag(e.Obj),
+			Size:         e.Obj.GetSize(),
+			StorageClass: storageStandard,
+		})
+	}
+	if res.IsTruncated && len(entries) > 0 {
+		last := entries[len(entries)-1].Key
+		if v2 {
+			res.NextContinuationToken = base64.StdEncoding.EncodeToString([]byte(last))
+		} else {
+			res.NextMarker = encodeKey(last)
+		}
+	}
+	if v2 {
+		keyCount := len(entries)
+		res.KeyCount = &keyCount
+	}
+	writeXML(w, http.StatusOK, res)
+}
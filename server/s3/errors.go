@@ -0,0 +1,97 @@
+package s3
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	log "github.com/sirupsen/logrus"
+)
+
+// Error is an error defined by the s3 api,
+// see https://docs.aws.amazon.com/AmazonS3/latest/API/ErrorResponses.html
+type Error struct {
+	Code    string
+	Message string
+	Status  int
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+var (
+	ErrAccessDenied          = &Error{"AccessDenied", "Access Denied", http.StatusForbidden}
+	ErrInvalidAccessKeyId    = &Error{"InvalidAccessKeyId", "The access key Id you provided does not exist in our records.", http.StatusForbidden}
+	ErrSignatureDoesNotMatch = &Error{"SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.", http.StatusForbidden}
+	ErrRequestTimeTooSkewed  = &Error{"RequestTimeTooSkewed", "The difference between the request time and the server's time is too large.", http.StatusForbidden}
+	ErrExpiredPresignRequest = &Error{"AccessDenied", "Request has expired", http.StatusForbidden}
+	ErrMissingAuth           = &Error{"AccessDenied", "Anonymous access is not allowed", http.StatusForbidden}
+	ErrAuthorizationHeader   = &Error{"AuthorizationHeaderMalformed", "The authorization header is malformed.", http.StatusBadRequest}
+	ErrBadDigest             = &Error{"XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed.", http.StatusBadRequest}
+	ErrInvalidArgument       = &Error{"InvalidArgument", "Invalid Argument", http.StatusBadRequest}
+	ErrInvalidPart           = &Error{"InvalidPart", "One or more of the specified parts could not be found.", http.StatusBadRequest}
+	ErrInvalidPartOrder      = &Error{"InvalidPartOrder", "The list of parts was not in ascending order.", http.StatusBadRequest}
+	ErrMalformedXML          = &Error{"MalformedXML", "The XML you provided was not well-formed.", http.StatusBadRequest}
+	ErrMissingContentLength  = &Error{"MissingContentLength", "You must provide the Content-Length HTTP header.", http.StatusLengthRequired}
+	ErrNoSuchBucket          = &Error{"NoSuchBucket", "The specified bucket does not exist", http.StatusNotFound}
+	ErrNoSuchKey             = &Error{"NoSuchKey", "The specified key does not exist.", http.StatusNotFound}
+	ErrNoSuchUpload          = &Error{"NoSuchUpload", "The specified multipart upload does not exist.", http.StatusNotFound}
+	ErrNotImplemented        = &Error{"NotImplemented", "A header you provided implies functionality that is not implemented.", http.StatusNotImplemented}
+	ErrInternalError         = &Error{"InternalError", "We encountered an internal error. Please try again.", http.StatusInternalServerError}
+	ErrSlowDown              = &Error{"SlowDown", "The traffic quota is exceeded.", http.StatusTooManyRequests}
+)
+
+type errorResponse struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	Resource  string   `xml:"Resource"`
+	RequestId string   `xml:"RequestId"`
+}
+
+// toError convert the error returned by fs to the error of s3 api
+func toError(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	if errs.IsObjectNotFound(err) {
+		return ErrNoSuchKey
+	}
+	if errors.Is(err, errs.TrafficQuotaExceeded) {
+		return ErrSlowDown
+	}
+	return &Error{
+		Code:    ErrInternalError.Code,
+		Message: err.Error(),
+		Status:  ErrInternalError.Status,
+	}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	e := toError(err)
+	if e.Status >= 500 {
+		log.Errorf("s3 %s %s: %+v", r.Method, r.URL.Path, err)
+	} else {
+		log.Debugf("s3 %s %s: %+v", r.Method, r.URL.Path, err)
+	}
+	if r.Method == http.MethodHead {
+		w.WriteHeader(e.Status)
+		return
+	}
+	writeXML(w, e.Status, errorResponse{
+		Code:     e.Code,
+		Message:  e.Message,
+		Resource: r.URL.Path,
+	})
+}
+
+func writeXML(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	if err := xml.NewEncoder(w).Encode(v); err != nil {
+		log.Errorf("failed to encode s3 response: %+v", err)
+	}
+}
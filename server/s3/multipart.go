@@ -0,0 +1,433 @@
+package s3
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	maxPartNumber = 10000
+	// the uploads not completed in this duration will be aborted
+	uploadExpiration = 24 * time.Hour
+)
+
+type part struct {
+	Number   int
+	ETag     string
+	Size     int64
+	Modified time.Time
+}
+
+// upload is a multipart upload, the parts are saved in temp dir until it's completed
+type upload struct {
+	ID        string
+	UserId    uint
+	Bucket    string
+	Key       string
+	Initiated time.Time
+	dir       string
+	mu        sync.Mutex
+	parts     map[int]part
+}
+
+func (u *upload) partPath(number int) string {
+	return filepath.Join(u.dir, strconv.Itoa(number))
+}
+
+type uploadManager struct {
+	mu      sync.Mutex
+	uploads map[string]*upload
+	tempDir string
+}
+
+func newUploadManager() *uploadManager {
+	m := &uploadManager{
+		uploads: make(map[string]*upload),
+		tempDir: filepath.Join(conf.Conf.TempDir, "s3"),
+	}
+	// the uploads are kept in memory, so the parts left by last run are useless
+	if err := os.RemoveAll(m.tempDir); err != nil {
+		log.Errorf("failed to clear s3 temp dir: %+v", err)
+	}
+	go m.expireLoop()
+	return m
+}
+
+func (m *uploadManager) create(user *model.User, bucket, key string) (*upload, error) {
+	id := uuid.NewString()
+	u := &upload{
+		ID:        id,
+		UserId:    user.ID,
+		Bucket:    bucket,
+		Key:       key,
+		Initiated: time.Now(),
+		dir:       filepath.Join(m.tempDir, id),
+		parts:     make(map[int]part),
+	}
+	if err := os.MkdirAll(u.dir, 0777); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.uploads[id] = u
+	m.mu.Unlock()
+	return u, nil
+}
+
+func (m *uploadManager) get(user *model.User, bucket, key, id string) (*upload, error) {
+	m.mu.Lock()
+	u, ok := m.uploads[id]
+	m.mu.Unlock()
+	if !ok || u.UserId != user.ID || u.Bucket != bucket || u.Key != key {
+		return nil, ErrNoSuchUpload
+	}
+	return u, nil
+}
+
+func (m *uploadManager) remove(u *upload) {
+	m.mu.Lock()
+	delete(m.uploads, u.ID)
+	m.mu.Unlock()
+	if err := os.RemoveAll(u.dir); err != nil {
+		log.Errorf("failed to remove parts of upload [%s]: %+v", u.ID, err)
+	}
+}
+
+func (m *uploadManager) list(user *model.User, bucket string) []*upload {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []*upload
+	for _, u := range m.uploads {
+		if u.UserId == user.ID && u.Bucket == bucket {
+			res = append(res, u)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Key != res[j].Key {
+			return res[i].Key < res[j].Key
+		}
+		return res[i].Initiated.Before(res[j].Initiated)
+	})
+	return res
+}
+
+func (m *uploadManager) expireLoop() {
+	for range time.Tick(time.Hour) {
+		var expired []*upload
+		m.mu.Lock()
+		for _, u := range m.uploads {
+			if time.Since(u.Initiated) > uploadExpiration {
+				expired = append(expired, u)
+			}
+		}
+		m.mu.Unlock()
+		for _, u := range expired {
+			log.Infof("s3 upload [%s] of [%s/%s] expired", u.ID, u.Bucket, u.Key)
+			m.remove(u)
+		}
+	}
+}
+
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadId string   `xml:"UploadId"`
+}
+
+func (s *Server) createMultipartUpload(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	ctx := r.Context()
+	user := getUser(ctx)
+	if !user.CanWrite() {
+		writeError(w, r, ErrAccessDenied)
+		return
+	}
+	if _, err := getBucket(ctx, user, bucket); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if _, err := objectPath(user, bucket, key); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	u, err := s.uploads.create(user, bucket, key)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeXML(w, http.StatusOK, initiateMultipartUploadResult{
+		Xmlns:    xmlns,
+		Bucket:   bucket,
+		Key:      key,
+		UploadId: u.ID,
+	})
+}
+
+func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	user := getUser(r.Context())
+	query := r.URL.Query()
+	u, err := s.uploads.get(user, bucket, key, query.Get("uploadId"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	number, err := strconv.Atoi(query.Get("partNumber"))
+	if err != nil || number < 1 || number > maxPartNumber {
+		writeError(w, r, ErrInvalidArgument)
+		return
+	}
+	body, _, err := requestBody(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	f, err := os.Create(u.partPath(number))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	h := md5.New()
+	size, err := io.Copy(io.MultiWriter(f, h), body)
+	_ = f.Close()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	p := part{
+		Number:   number,
+		ETag:     hex.EncodeToString(h.Sum(nil)),
+		Size:     size,
+		Modified: time.Now(),
+	}
+	u.mu.Lock()
+	u.parts[number] = p
+	u.mu.Unlock()
+	w.Header().Set("ETag", `"`+p.ETag+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name `xml:"CompleteMultipartUpload"`
+	Parts   []struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	} `xml:"Part"`
+}
+
+type completeMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+// multiReadCloser read the parts in order and close them all at last
+type multiReadCloser struct {
+	io.Reader
+	files []*os.File
+}
+
+func (m *multiReadCloser) Close() error {
+	var err error
+	for _, f := range m.files {
+		if e := f.Close(); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	ctx := r.Context()
+	user := getUser(ctx)
+	u, err := s.uploads.get(user, bucket, key, r.URL.Query().Get("uploadId"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	reqPath, err := objectPath(user, bucket, key)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var req completeMultipartUpload
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Parts) == 0 {
+		writeError(w, r, ErrMalformedXML)
+		return
+	}
+	u.mu.Lock()
+	parts := make([]part, 0, len(req.Parts))
+	for i, p := range req.Parts {
+		if i > 0 && p.PartNumber <= req.Parts[i-1].PartNumber {
+			u.mu.Unlock()
+			writeError(w, r, ErrInvalidPartOrder)
+			return
+		}
+		uploaded, ok := u.parts[p.PartNumber]
+		if !ok || strings.Trim(p.ETag, `"`) != uploaded.ETag {
+			u.mu.Unlock()
+			writeError(w, r, ErrInvalidPart)
+			return
+		}
+		parts = append(parts, uploaded)
+	}
+	u.mu.Unlock()
+
+	var (
+		size    int64
+		readers = make([]io.Reader, 0, len(parts))
+		files   = make([]*os.File, 0, len(parts))
+		md5s    = md5.New()
+	)
+	for _, p := range parts {
+		f, err := os.Open(u.partPath(p.Number))
+		if err != nil {
+			for _, f := range files {
+				_ = f.Close()
+			}
+			writeError(w, r, err)
+			return
+		}
+		files = append(files, f)
+		readers = append(readers, f)
+		size += p.Size
+		b, _ := hex.DecodeString(p.ETag)
+		md5s.Write(b)
+	}
+	stream := &model.FileStream{
+		Obj: &model.Object{
+			Name:     stdpath.Base(reqPath),
+			Size:     size,
+			Modified: time.Now(),
+		},
+		ReadCloser: &multiReadCloser{Reader: io.MultiReader(readers...), files: files},
+		Mimetype:   "application/octet-stream",
+	}
+	if err := fs.PutDirectly(ctx, stdpath.Dir(reqPath), stream); err != nil {
+		_ = stream.Close()
+		writeError(w, r, err)
+		return
+	}
+	s.uploads.remove(u)
+	writeXML(w, http.StatusOK, completeMultipartUploadResult{
+		Xmlns:    xmlns,
+		Location: r.URL.Path,
+		Bucket:   bucket,
+		Key:      key,
+		ETag:     fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(md5s.Sum(nil)), len(parts)),
+	})
+}
+
+func (s *Server) abortMultipartUpload(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	u, err := s.uploads.get(getUser(r.Context()), bucket, key, r.URL.Query().Get("uploadId"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	s.uploads.remove(u)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type partInfo struct {
+	PartNumber   int    `xml:"PartNumber"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+}
+
+type listPartsResult struct {
+	XMLName      xml.Name   `xml:"ListPartsResult"`
+	Xmlns        string     `xml:"xmlns,attr"`
+	Bucket       string     `xml:"Bucket"`
+	Key          string     `xml:"Key"`
+	UploadId     string     `xml:"UploadId"`
+	StorageClass string     `xml:"StorageClass"`
+	IsTruncated  bool       `xml:"IsTruncated"`
+	Parts        []partInfo `xml:"Part"`
+}
+
+func (s *Server) listParts(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	u, err := s.uploads.get(getUser(r.Context()), bucket, key, r.URL.Query().Get("uploadId"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	res := listPartsResult{
+		Xmlns:        xmlns,
+		Bucket:       bucket,
+		Key:          key,
+		UploadId:     u.ID,
+		StorageClass: storageStandard,
+	}
+	u.mu.Lock()
+	for _, p := range u.parts {
+		res.Parts = append(res.Parts, partInfo{
+			PartNumber:   p.Number,
+			LastModified: formatTime(p.Modified),
+			ETag:         `"` + p.ETag + `"`,
+			Size:         p.Size,
+		})
+	}
+	u.mu.Unlock()
+	sort.Slice(res.Parts, func(i, j int) bool {
+		return res.Parts[i].PartNumber < res.Parts[j].PartNumber
+	})
+	writeXML(w, http.StatusOK, res)
+}
+
+type uploadInfo struct {
+	Key          string `xml:"Key"`
+	UploadId     string `xml:"UploadId"`
+	Initiated    string `xml:"Initiated"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type listMultipartUploadsResult struct {
+	XMLName     xml.Name     `xml:"ListMultipartUploadsResult"`
+	Xmlns       string       `xml:"xmlns,attr"`
+	Bucket      string       `xml:"Bucket"`
+	IsTruncated bool         `xml:"IsTruncated"`
+	Uploads     []uploadInfo `xml:"Upload"`
+}
+
+func (s *Server) listMultipartUploads(w http.ResponseWriter, r *http.Request, bucket string) {
+	user := getUser(r.Context())
+	if _, err := getBucket(r.Context(), user, bucket); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	prefix := r.URL.Query().Get("prefix")
+	res := listMultipartUploadsResult{Xmlns: xmlns, Bucket: bucket}
+	for _, u := range s.uploads.list(user, bucket) {
+		if !strings.HasPrefix(u.Key, prefix) {
+			continue
+		}
+		res.Uploads = append(res.Uploads, uploadInfo{
+			Key:          u.Key,
+			UploadId:     u.ID,
+			Initiated:    formatTime(u.Initiated),
+			StorageClass: storageStandard,
+		})
+	}
+	writeXML(w, http.StatusOK, res)
+}
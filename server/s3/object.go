@@ -0,0 +1,263 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/diskcache"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/server/common"
+)
+
+func getObject(ctx context.Context, user *model.User, bucket, key string) (model.Obj, error) {
+	if _, err := getBucket(ctx, user, bucket); err != nil {
+		return nil, err
+	}
+	path, err := objectPath(user, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := fs.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	// the dir can be got only with the key ends with `/`
+	if obj.IsDir() != strings.HasSuffix(key, "/") {
+		return nil, ErrNoSuchKey
+	}
+	return obj, nil
+}
+
+func setObjectHeader(w http.ResponseWriter, obj model.Obj) {
+	w.Header().Set("ETag", etag(obj))
+	w.Header().Set("Last-Modified", obj.ModTime().UTC().Format(http.TimeFormat))
+	w.Header().Set("Accept-Ranges", "bytes")
+}
+
+func (s *Server) headObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	obj, err := getObject(r.Context(), getUser(r.Context()), bucket, key)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	setObjectHeader(w, obj)
+	w.Header().Set("Content-Length", strconv.FormatInt(obj.GetSize(), 10))
+	if mimetype := mime.TypeByExtension(stdpath.Ext(obj.GetName())); mimetype != "" {
+		w.Header().Set("Content-Type", mimetype)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// objectWriter keep the headers of object set by us,
+// instead of the ones from the upstream when proxying
+type objectWriter struct {
+	http.ResponseWriter
+	obj model.Obj
+}
+
+func (w *objectWriter) WriteHeader(statusCode int) {
+	setObjectHeader(w.ResponseWriter, w.obj)
+	w.ResponseWriter.Header().Del("Content-Disposition")
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	ctx := r.Context()
+	user := getUser(ctx)
+	obj, err := getObject(ctx, user, bucket, key)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if obj.IsDir() {
+		setObjectHeader(w, obj)
+		w.Header().Set("Content-Length", "0")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if err := fs.CheckDownloadQuota(ctx); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	// the object is got, so the path is valid
+	path, _ := objectPath(user, bucket, key)
+	// the s3 clients don't follow redirects, so the content is always proxied
+	link, _, err := fs.Link(ctx, path, model.LinkArgs{Header: r.Header})
+	audit.RecordDownload(ctx, path, obj, err)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	// the headers used by s3 make no sense to the upstream
+	for h := range r.Header {
+		if strings.HasPrefix(h, "X-Amz-") || h == "Authorization" {
+			r.Header.Del(h)
+		}
+	}
+	lw := ratelimit.NewResponseWriter(ctx, w, fs.DownloadLimiters(ctx, path)...)
+	if err := common.Proxy(&objectWriter{ResponseWriter: lw, obj: obj}, r, diskcache.Wrap(path, link, obj), obj); err != nil {
+		writeError(w, r, err)
+	}
+}
+
+func (s *Server) putObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	ctx := r.Context()
+	user := getUser(ctx)
+	if !user.CanWrite() {
+		writeError(w, r, ErrAccessDenied)
+		return
+	}
+	if r.Header.Get("X-Amz-Copy-Source") != "" {
+		writeError(w, r, ErrNotImplemented)
+		return
+	}
+	if _, err := getBucket(ctx, user, bucket); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	reqPath, err := objectPath(user, bucket, key)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	// the clients create a dir with an empty object ends with `/`
+	if strings.HasSuffix(key, "/") {
+		if err := fs.MakeDir(ctx, reqPath); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("ETag", `""`)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	body, size, err := requestBody(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	stream := &model.FileStream{
+		Obj: &model.Object{
+			Name:     stdpath.Base(reqPath),
+			Size:     size,
+			Modified: time.Now(),
+		},
+		ReadCloser: body,
+		Mimetype:   r.Header.Get("Content-Type"),
+	}
+	if err := fs.PutDirectly(ctx, stdpath.Dir(reqPath), stream); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", etag(stream))
+	w.WriteHeader(http.StatusOK)
+}
+
+// requestBody get the body and the size of the payload,
+// the body sent with aws-chunked encoding is decoded and verified by the reader set in authenticate
+func requestBody(r *http.Request) (io.ReadCloser, int64, error) {
+	if r.Header.Get("X-Amz-Content-Sha256") == streamingSignedPayload {
+		size, err := strconv.ParseInt(r.Header.Get("X-Amz-Decoded-Content-Length"), 10, 64)
+		if err != nil {
+			return nil, 0, ErrMissingContentLength
+		}
+		return r.Body, size, nil
+	}
+	if strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") {
+		return nil, 0, ErrNotImplemented
+	}
+	if r.ContentLength < 0 {
+		return nil, 0, ErrMissingContentLength
+	}
+	return r.Body, r.ContentLength, nil
+}
+
+func removeObject(ctx context.Context, user *model.User, bucket, key string) error {
+	if _, err := getObject(ctx, user, bucket, key); err != nil {
+		// deleting an object that doesn't exist is ok in s3
+		if err == ErrNoSuchKey || errs.IsObjectNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	path, err := objectPath(user, bucket, key)
+	if err != nil {
+		return err
+	}
+	return fs.Remove(ctx, path)
+}
+
+func (s *Server) deleteObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	ctx := r.Context()
+	user := getUser(ctx)
+	if !user.CanRemove() {
+		writeError(w, r, ErrAccessDenied)
+		return
+	}
+	if err := removeObject(ctx, user, bucket, key); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type deleteRequest struct {
+	XMLName xml.Name `xml:"Delete"`
+	Quiet   bool     `xml:"Quiet"`
+	Objects []struct {
+		Key string `xml:"Key"`
+	} `xml:"Object"`
+}
+
+type deletedObject struct {
+	Key string `xml:"Key"`
+}
+
+type deleteError struct {
+	Key     string `xml:"Key"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+type deleteResult struct {
+	XMLName xml.Name        `xml:"DeleteResult"`
+	Xmlns   string          `xml:"xmlns,attr"`
+	Deleted []deletedObject `xml:"Deleted"`
+	Errors  []deleteError   `xml:"Error"`
+}
+
+func (s *Server) deleteObjects(w http.ResponseWriter, r *http.Request, bucket string) {
+	ctx := r.Context()
+	user := getUser(ctx)
+	if !user.CanRemove() {
+		writeError(w, r, ErrAccessDenied)
+		return
+	}
+	var req deleteRequest
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, ErrMalformedXML)
+		return
+	}
+	res := deleteResult{Xmlns: xmlns}
+	for _, o := range req.Objects {
+		if err := removeObject(ctx, user, bucket, o.Key); err != nil {
+			e := toError(err)
+			res.Errors = append(res.Errors, deleteError{Key: o.Key, Code: e.Code, Message: e.Message})
+			continue
+		}
+		if !req.Quiet {
+			res.Deleted = append(res.Deleted, deletedObject{Key: o.Key})
+		}
+	}
+	writeXML(w, http.StatusOK, res)
+}
@@ -0,0 +1,163 @@
+package s3
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// hashReader check the sha256 of the payload when it reaches EOF
+type hashReader struct {
+	io.ReadCloser
+	hash     hash.Hash
+	expected string
+}
+
+func newHashReader(rc io.ReadCloser, expected string) io.ReadCloser {
+	return &hashReader{ReadCloser: rc, hash: sha256.New(), expected: expected}
+}
+
+func (h *hashReader) Read(p []byte) (int, error) {
+	n, err := h.ReadCloser.Read(p)
+	h.hash.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(h.hash.Sum(nil)) != h.expected {
+		return n, ErrBadDigest
+	}
+	return n, err
+}
+
+// chunkSigner calculate the signatures of the chunks, each one is chained to the previous one,
+// see https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-streaming.html
+type chunkSigner struct {
+	key  []byte
+	s    *signature
+	prev string
+}
+
+// newChunkSigner create the signer with the seed signature of the request
+func newChunkSigner(secretKey string, s *signature) *chunkSigner {
+	return &chunkSigner{key: signingKey(secretKey, s), s: s, prev: s.Signature}
+}
+
+// next get the signature of the chunk with the sha256 of its data
+func (cs *chunkSigner) next(hashed []byte) string {
+	stringToSign := strings.Join([]string{
+		signV4Algorithm + "-PAYLOAD",
+		cs.s.Time.Format(iso8601Format),
+		cs.s.scope(),
+		cs.prev,
+		emptySHA256,
+		hex.EncodeToString(hashed),
+	}, "\n")
+	cs.prev = hex.EncodeToString(hmacSHA256(cs.key, stringToSign))
+	return cs.prev
+}
+
+// chunkedReader decode the body with aws-chunked encoding, such as:
+//
+//	10000;chunk-signature=xxx\r\n<65536 bytes>\r\n0;chunk-signature=xxx\r\n\r\n
+//
+// the signature of every chunk is verified when the chunk is read,
+// ErrSignatureDoesNotMatch is returned if any of them mismatches
+type chunkedReader struct {
+	rc     io.ReadCloser
+	r      *bufio.Reader
+	signer *chunkSigner
+	// the sha256 and the signature of the current chunk
+	hash hash.Hash
+	sig  string
+	left int64
+	done bool
+}
+
+func newChunkedReader(rc io.ReadCloser, signer *chunkSigner) io.ReadCloser {
+	return &chunkedReader{rc: rc, r: bufio.NewReader(rc), signer: signer, hash: sha256.New()}
+}
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	if c.done {
+		return 0, io.EOF
+	}
+	if c.left == 0 {
+		if err := c.nextChunk(); err != nil {
+			return 0, err
+		}
+		if c.done {
+			return 0, io.EOF
+		}
+	}
+	if int64(len(p)) > c.left {
+		p = p[:c.left]
+	}
+	n, err := c.r.Read(p)
+	c.hash.Write(p[:n])
+	c.left -= int64(n)
+	if c.left == 0 && err == nil {
+		// the chunk data is ended with \r\n
+		err = c.discardCRLF()
+		if err == nil {
+			err = c.verify()
+		}
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// verify check the signature of the chunk read
+func (c *chunkedReader) verify() error {
+	expected := c.signer.next(c.hash.Sum(nil))
+	c.hash.Reset()
+	if !hmac.Equal([]byte(expected), []byte(c.sig)) {
+		return ErrSignatureDoesNotMatch
+	}
+	return nil
+}
+
+func (c *chunkedReader) nextChunk() error {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	line = strings.TrimSpace(line)
+	sizeStr, ext, _ := strings.Cut(line, ";")
+	size, err := strconv.ParseInt(sizeStr, 16, 64)
+	if err != nil || size < 0 {
+		return ErrInvalidArgument
+	}
+	if !strings.HasPrefix(ext, "chunk-signature=") {
+		return ErrSignatureDoesNotMatch
+	}
+	c.sig = strings.TrimPrefix(ext, "chunk-signature=")
+	if size == 0 {
+		// the last chunk is signed too, the trailers are ignored
+		c.done = true
+		return c.verify()
+	}
+	c.left = size
+	return nil
+}
+
+func (c *chunkedReader) discardCRLF() error {
+	b := make([]byte, 2)
+	if _, err := io.ReadFull(c.r, b); err != nil {
+		return err
+	}
+	if string(b) != "\r\n" {
+		return ErrInvalidArgument
+	}
+	return nil
+}
+
+func (c *chunkedReader) Close() error {
+	return c.rc.Close()
+}
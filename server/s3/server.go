@@ -0,0 +1,130 @@
+package s3
+
+import (
+	"context"
+	"net/http"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// Server expose the mounted tree with the s3 api in path style, like http://host:port/bucket/key,
+// the buckets are the dirs in the base path of the user
+type Server struct {
+	uploads *uploadManager
+}
+
+func NewServer() *Server {
+	return &Server{
+		uploads: newUploadManager(),
+	}
+}
+
+func getUser(ctx context.Context) *model.User {
+	return ctx.Value("user").(*model.User)
+}
+
+// splitPath split the path of the request to bucket and key
+func splitPath(path string) (string, string) {
+	path = strings.TrimPrefix(path, "/")
+	bucket, key, _ := strings.Cut(path, "/")
+	return bucket, key
+}
+
+// bucketPath get the path of the bucket in alist
+func bucketPath(user *model.User, bucket string) string {
+	return stdpath.Join(user.BasePath, bucket)
+}
+
+// objectPath get the path of the object in alist, the keys with `..` leaving the bucket are denied
+func objectPath(user *model.User, bucket, key string) (string, error) {
+	dir := bucketPath(user, bucket)
+	path := stdpath.Join(dir, key)
+	if !strings.HasPrefix(path, dir+"/") {
+		return "", ErrAccessDenied
+	}
+	return path, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, err := authenticate(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	r = r.WithContext(audit.WithIP(context.WithValue(r.Context(), "user", user), utils.ClientIP(r)))
+	bucket, key := splitPath(r.URL.Path)
+	if bucket == "." || bucket == ".." {
+		writeError(w, r, ErrAccessDenied)
+		return
+	}
+	query := r.URL.Query()
+	if bucket == "" {
+		if r.Method == http.MethodGet {
+			s.listBuckets(w, r)
+			return
+		}
+		writeError(w, r, ErrNotImplemented)
+		return
+	}
+	if key == "" {
+		switch r.Method {
+		case http.MethodGet:
+			if query.Has("location") {
+				s.getBucketLocation(w, r, bucket)
+			} else if query.Has("uploads") {
+				s.listMultipartUploads(w, r, bucket)
+			} else {
+				s.listObjects(w, r, bucket)
+			}
+		case http.MethodHead:
+			s.headBucket(w, r, bucket)
+		case http.MethodPost:
+			if query.Has("delete") {
+				s.deleteObjects(w, r, bucket)
+			} else {
+				writeError(w, r, ErrNotImplemented)
+			}
+		default:
+			// create or delete the bucket is not allowed,
+			// the buckets are managed by alist
+			writeError(w, r, ErrNotImplemented)
+		}
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		if query.Has("uploadId") {
+			s.listParts(w, r, bucket, key)
+		} else {
+			s.getObject(w, r, bucket, key)
+		}
+	case http.MethodHead:
+		s.headObject(w, r, bucket, key)
+	case http.MethodPut:
+		if query.Has("uploadId") && query.Has("partNumber") {
+			s.uploadPart(w, r, bucket, key)
+		} else {
+			s.putObject(w, r, bucket, key)
+		}
+	case http.MethodPost:
+		if query.Has("uploads") {
+			s.createMultipartUpload(w, r, bucket, key)
+		} else if query.Has("uploadId") {
+			s.completeMultipartUpload(w, r, bucket, key)
+		} else {
+			writeError(w, r, ErrNotImplemented)
+		}
+	case http.MethodDelete:
+		if query.Has("uploadId") {
+			s.abortMultipartUpload(w, r, bucket, key)
+		} else {
+			s.deleteObject(w, r, bucket, key)
+		}
+	default:
+		writeError(w, r, ErrNotImplemented)
+	}
+}
@@ -0,0 +1,30 @@
+package s3
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestObjectPath(t *testing.T) {
+	user := &model.User{BasePath: "/home/a"}
+	tests := []struct {
+		key  string
+		want string
+		ok   bool
+	}{
+		{"x.txt", "/home/a/bucket/x.txt", true},
+		{"dir/", "/home/a/bucket/dir", true},
+		{"dir/../x.txt", "/home/a/bucket/x.txt", true},
+		{"../other/x.txt", "", false},
+		{"../../b/x.txt", "", false},
+		{"dir/..", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, err := objectPath(user, "bucket", tt.key)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("objectPath(%q) = %q, %v", tt.key, got, err)
+		}
+	}
+}
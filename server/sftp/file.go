@@ -0,0 +1,140 @@
+package sftp
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+)
+
+// the max gap of the forward seeking that reading and discarding is cheaper than reopening
+const maxSkip = 1024 * 1024
+
+// reader read the file sequentially with the link of it,
+// the link will be reopened when the client seeks backward or jumps too far
+type reader struct {
+	ctx    context.Context
+	path   string
+	size   int64
+	mu     sync.Mutex
+	rc     io.ReadCloser
+	offset int64
+}
+
+func newReader(ctx context.Context, path string, size int64) *reader {
+	return &reader{ctx: ctx, path: path, size: size}
+}
+
+func (r *reader) open(offset int64) error {
+	if r.rc != nil {
+		_ = r.rc.Close()
+		r.rc = nil
+	}
+	link, _, err := fs.Link(r.ctx, r.path, model.LinkArgs{})
+	if err != nil {
+		return err
+	}
+	rc, err := common.OpenLink(link, offset)
+	if err != nil {
+		return err
+	}
+	r.rc = ratelimit.NewReadCloser(r.ctx, rc, fs.DownloadLimiters(r.ctx, r.path)...)
+	r.offset = offset
+	return nil
+}
+
+func (r *reader) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	if r.rc == nil || off < r.offset || off-r.offset > maxSkip {
+		if err := r.open(off); err != nil {
+			return 0, toError(err)
+		}
+	}
+	if off > r.offset {
+		n, err := io.CopyN(ioutil.Discard, r.rc, off-r.offset)
+		r.offset += n
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := io.ReadFull(r.rc, p)
+	r.offset += int64(n)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (r *reader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.rc == nil {
+		return nil
+	}
+	err := r.rc.Close()
+	r.rc = nil
+	return err
+}
+
+// writer store the uploading file to a temp file since the clients may write it out of order,
+// and put it to the storage when it's closed
+type writer struct {
+	ctx  context.Context
+	path string
+	*os.File
+}
+
+func newWriter(ctx context.Context, path string) (*writer, error) {
+	if err := os.MkdirAll(tempDir(), 0777); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f, err := os.CreateTemp(tempDir(), "file-*")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &writer{ctx: ctx, path: path, File: f}, nil
+}
+
+func (w *writer) Close() error {
+	info, err := w.File.Stat()
+	if err != nil {
+		_ = w.File.Close()
+		_ = os.Remove(w.File.Name())
+		return err
+	}
+	if _, err := w.File.Seek(0, io.SeekStart); err != nil {
+		_ = w.File.Close()
+		_ = os.Remove(w.File.Name())
+		return err
+	}
+	stream := &model.FileStream{
+		Obj: &model.Object{
+			Name:     stdpath.Base(w.path),
+			Size:     info.Size(),
+			Modified: time.Now(),
+		},
+		// the temp file will be closed and removed after putting
+		ReadCloser: w.File,
+	}
+	err = fs.PutDirectly(w.ctx, stdpath.Dir(w.path), stream)
+	if err != nil {
+		// it may fail before putting, such as the storage not found
+		_ = w.File.Close()
+		_ = os.Remove(w.File.Name())
+	}
+	fs.ClearCache(stdpath.Dir(w.path))
+	return toError(err)
+}
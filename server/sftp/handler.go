@@ -0,0 +1,201 @@
+package sftp
+
+import (
+	"context"
+	"io"
+	"os"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"github.com/pkg/sftp"
+	log "github.com/sirupsen/logrus"
+)
+
+type handler struct {
+	ctx  context.Context
+	user *model.User
+}
+
+func newHandlers(ctx context.Context) sftp.Handlers {
+	h := &handler{ctx: ctx, user: ctx.Value("user").(*model.User)}
+	return sftp.Handlers{
+		FileGet:  h,
+		FilePut:  h,
+		FileCmd:  h,
+		FileList: h,
+	}
+}
+
+// path get the path in alist of the request path
+func (h *handler) path(p string) string {
+	return stdpath.Join(h.user.BasePath, p)
+}
+
+// toError convert the errors of alist to the status errors of sftp
+func toError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errs.IsObjectNotFound(err) {
+		return sftp.ErrSSHFxNoSuchFile
+	}
+	if errors.Is(err, errs.PermissionDenied) {
+		return sftp.ErrSSHFxPermissionDenied
+	}
+	if errors.Is(err, errs.NotImplement) || errors.Is(err, errs.NotSupport) {
+		return sftp.ErrSSHFxOpUnsupported
+	}
+	log.Errorf("sftp error: %+v", err)
+	return err
+}
+
+func (h *handler) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	reqPath := h.path(r.Filepath)
+	obj, err := fs.Get(h.ctx, reqPath)
+	if err != nil {
+		return nil, toError(err)
+	}
+	if obj.IsDir() {
+		return nil, sftp.ErrSSHFxFailure
+	}
+	audit.RecordDownload(h.ctx, reqPath, obj, nil)
+	return newReader(h.ctx, reqPath, obj.GetSize()), nil
+}
+
+func (h *handler) Filewrite(r *sftp.Request) (io.WriterAt, error) {
+	if !h.user.CanWrite() {
+		return nil, sftp.ErrSSHFxPermissionDenied
+	}
+	return newWriter(h.ctx, h.path(r.Filepath))
+}
+
+func (h *handler) Filecmd(r *sftp.Request) error {
+	reqPath := h.path(r.Filepath)
+	switch r.Method {
+	case "Setstat":
+		// the attributes can't be changed, but the clients always set them after uploading
+		return nil
+	case "Rename":
+		return h.rename(reqPath, h.path(r.Target))
+	case "Rmdir", "Remove":
+		if !h.user.CanRemove() {
+			return sftp.ErrSSHFxPermissionDenied
+		}
+		err := fs.Remove(h.ctx, reqPath)
+		fs.ClearCache(stdpath.Dir(reqPath))
+		return toError(err)
+	case "Mkdir":
+		if !h.user.CanWrite() {
+			return sftp.ErrSSHFxPermissionDenied
+		}
+		err := fs.MakeDir(h.ctx, reqPath)
+		fs.ClearCache(stdpath.Dir(reqPath))
+		return toError(err)
+	}
+	return sftp.ErrSSHFxOpUnsupported
+}
+
+func (h *handler) rename(src, dst string) error {
+	srcDir, srcName := stdpath.Split(src)
+	dstDir, dstName := stdpath.Split(dst)
+	var err error
+	if srcDir == dstDir {
+		if !h.user.CanRename() {
+			return sftp.ErrSSHFxPermissionDenied
+		}
+		err = fs.Rename(h.ctx, src, dstName)
+	} else {
+		if !h.user.CanMove() || (srcName != dstName && !h.user.CanRename()) {
+			return sftp.ErrSSHFxPermissionDenied
+		}
+		var added bool
+		// the name is kept if the object is moved to another storage by a task
+		added, err = fs.Move(h.ctx, src, dstDir)
+		if err == nil && !added && srcName != dstName {
+			err = fs.Rename(h.ctx, stdpath.Join(dstDir, srcName), dstName)
+		}
+	}
+	fs.ClearCache(srcDir)
+	fs.ClearCache(dstDir)
+	return toError(err)
+}
+
+func (h *handler) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	reqPath := h.path(r.Filepath)
+	switch r.Method {
+	case "List":
+		meta, _ := db.GetNearestMeta(reqPath)
+		objs, err := fs.List(context.WithValue(h.ctx, "meta", meta), reqPath)
+		if err != nil {
+			return nil, toError(err)
+		}
+		l := make(listerAt, len(objs))
+		for i := range objs {
+			l[i] = toFileInfo(objs[i])
+		}
+		return l, nil
+	case "Stat":
+		obj, err := fs.Get(h.ctx, reqPath)
+		if err != nil {
+			return nil, toError(err)
+		}
+		return listerAt{toFileInfo(obj)}, nil
+	}
+	return nil, sftp.ErrSSHFxOpUnsupported
+}
+
+type listerAt []os.FileInfo
+
+func (l listerAt) ListAt(ls []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+	n := copy(ls, l[offset:])
+	if n < len(ls) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+type fileInfo struct {
+	model.Obj
+}
+
+func toFileInfo(obj model.Obj) os.FileInfo {
+	return fileInfo{Obj: obj}
+}
+
+func (f fileInfo) Name() string {
+	return f.GetName()
+}
+
+func (f fileInfo) Size() int64 {
+	return f.GetSize()
+}
+
+func (f fileInfo) Mode() os.FileMode {
+	if f.IsDir() {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+func (f fileInfo) ModTime() time.Time {
+	return f.Obj.ModTime()
+}
+
+func (f fileInfo) Sys() interface{} {
+	return nil
+}
+
+// tempDir the dir to store the uploading files
+func tempDir() string {
+	return stdpath.Join(conf.Conf.TempDir, "sftp")
+}
@@ -0,0 +1,168 @@
+package sftp
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/event"
+	"github.com/alist-org/alist/v3/internal/throttle"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+	"github.com/pkg/sftp"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+var ErrServerClosed = errors.New("sftp: Server closed")
+
+// Server serve the mounted tree with sftp, the users login with their alist username and password
+type Server struct {
+	config   *ssh.ServerConfig
+	mu       sync.Mutex
+	listener net.Listener
+	closed   bool
+}
+
+func NewServer(hostKeyFile string) (*Server, error) {
+	signer, err := loadHostKey(hostKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			ip := audit.RemoteIP(conn.RemoteAddr())
+			// the clients failed too many times are banned for a while, against guessing the passwords
+			if throttle.Banned(throttle.KindLogin, ip) > 0 {
+				return nil, throttle.ErrThrottled
+			}
+			user, err := db.GetUserByName(conn.User())
+			if err == nil {
+				err = common.ValidateClientPassword(user, string(password))
+			}
+			if err != nil {
+				throttle.Fail(throttle.KindLogin, ip)
+				event.PublishLoginFailed(conn.User(), ip, "sftp")
+				return nil, err
+			}
+			if !user.AllowIP(ip) {
+				return nil, errors.New("login from this ip is denied")
+			}
+			throttle.Reset(throttle.KindLogin, ip)
+			return nil, nil
+		},
+		ServerVersion: "SSH-2.0-AList",
+	}
+	config.AddHostKey(signer)
+	return &Server{config: config}, nil
+}
+
+// loadHostKey load the host key from file, a new ed25519 key will be generated if not exists
+func loadHostKey(file string) (ssh.Signer, error) {
+	if !utils.Exists(file) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		b, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		f, err := utils.CreateNestedFile(file)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to create host key file")
+		}
+		err = pem.Encode(f, &pem.Block{Type: "PRIVATE KEY", Bytes: b})
+		_ = f.Close()
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to write host key file")
+		}
+		log.Infof("generated a new sftp host key: %s", file)
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	signer, err := ssh.ParsePrivateKey(b)
+	return signer, errors.WithMessage(err, "failed to parse host key")
+}
+
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	s.listener = l
+	s.mu.Unlock()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	sconn, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		log.Debugf("failed sftp handshake with %s: %+v", conn.RemoteAddr(), err)
+		_ = conn.Close()
+		return
+	}
+	defer sconn.Close()
+	go ssh.DiscardRequests(reqs)
+	user, err := db.GetUserByName(sconn.User())
+	if err != nil {
+		log.Errorf("failed get sftp user %s: %+v", sconn.User(), err)
+		return
+	}
+	ctx := audit.WithIP(context.WithValue(context.Background(), "user", user), audit.RemoteIP(sconn.RemoteAddr()))
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			log.Errorf("failed accept sftp channel: %+v", err)
+			return
+		}
+		go func(in <-chan *ssh.Request) {
+			// only the sftp subsystem is accepted, no shell or exec
+			for req := range in {
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+			}
+		}(requests)
+		go func() {
+			defer channel.Close()
+			server := sftp.NewRequestServer(channel, newHandlers(ctx))
+			if err := server.Serve(); err != nil && err.Error() != "EOF" {
+				log.Errorf("sftp server of %s stopped: %+v", sconn.User(), err)
+			}
+			_ = server.Close()
+		}()
+	}
+}
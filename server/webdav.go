@@ -5,8 +5,11 @@ import (
 	"net/http"
 
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/event"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/throttle"
 	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/alist/v3/server/webdav"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -58,17 +61,30 @@ func WebDAVAuth(c *gin.Context) {
 		c.Abort()
 		return
 	}
+	// the clients failed too many times are banned for a while, against guessing the passwords
+	if throttle.Banned(throttle.KindLogin, c.ClientIP()) > 0 {
+		c.Status(http.StatusTooManyRequests)
+		c.Abort()
+		return
+	}
 	user, err := db.GetUserByName(username)
-	if err != nil || user.ValidatePassword(password) != nil {
+	if err != nil || common.ValidateClientPassword(user, password) != nil {
 		if c.Request.Method == "OPTIONS" {
 			c.Set("user", guest)
 			c.Next()
 			return
 		}
+		throttle.Fail(throttle.KindLogin, c.ClientIP())
+		event.PublishLoginFailed(username, c.ClientIP(), "webdav")
 		c.Status(http.StatusUnauthorized)
 		c.Abort()
 		return
 	}
+	if !user.AllowIP(c.ClientIP()) {
+		c.Status(http.StatusForbidden)
+		c.Abort()
+		return
+	}
 	if !user.CanWebdavRead() {
 		if c.Request.Method == "OPTIONS" {
 			c.Set("user", guest)
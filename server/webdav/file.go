@@ -35,11 +35,13 @@ func moveFiles(ctx context.Context, src, dst string, overwrite bool) (status int
 	if srcDir == dstDir {
 		err = fs.Rename(ctx, src, dstName)
 	} else {
-		err = fs.Move(ctx, src, dstDir)
+		var added bool
+		added, err = fs.Move(ctx, src, dstDir)
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
-		if srcName != dstName {
+		// the name is kept if the object is moved to another storage by a task
+		if !added && srcName != dstName {
 			err = fs.Rename(ctx, path.Join(dstDir, srcName), dstName)
 		}
 	}
@@ -70,7 +72,7 @@ func copyFiles(ctx context.Context, src, dst string, overwrite bool) (status int
 // Allowed values for depth are 0, 1 or infiniteDepth. For each visited node,
 // walkFS calls walkFn. If a visited file system node is a directory and
 // walkFn returns path.SkipDir, walkFS will skip traversal of this node.
-func walkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn func(reqPath string, info model.Obj, err error) error) error {
+func walkFS(ctx context.Context, rt root, depth int, name string, info model.Obj, walkFn func(reqPath string, info model.Obj, err error) error) error {
 	// This implementation is based on Walk's code in the standard path/path package.
 	err := walkFn(name, info, nil)
 	if err != nil {
@@ -100,12 +102,18 @@ func walkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn
 
 	for _, fileInfo := range objs {
 		filename := path.Join(name, fileInfo.GetName())
+		// the folders with the passwords are hidden for the users can't access them
+		if fileInfo.IsDir() {
+			if ok, err := rt.accessible(filename); err != nil || !ok {
+				continue
+			}
+		}
 		if err != nil {
 			if err := walkFn(filename, fileInfo, err); err != nil && err != filepath.SkipDir {
 				return err
 			}
 		} else {
-			err = walkFS(ctx, depth, filename, fileInfo, walkFn)
+			err = walkFS(ctx, rt, depth, filename, fileInfo, walkFn)
 			if err != nil {
 				if !fileInfo.IsDir() || err != filepath.SkipDir {
 					return err
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 var (
@@ -91,6 +93,17 @@ type LockSystem interface {
 	// See http://www.webdav.org/specs/rfc4918.html#rfc.section.9.11.1 for
 	// when to use each error.
 	Unlock(now time.Time, token string) error
+
+	// Locks returns the active locks that apply to the named resource, that
+	// are the lock of the resource and the infinite depth locks of its
+	// ancestors. It's used for the lockdiscovery property.
+	Locks(now time.Time, name string) []ActiveLock
+}
+
+// ActiveLock is a lock that has been created and not expired or unlocked yet.
+type ActiveLock struct {
+	Token   string
+	Details LockDetails
 }
 
 // LockDetails are a lock's metadata.
@@ -109,6 +122,9 @@ type LockDetails struct {
 	// ZeroDepth is whether the lock has zero depth. If it does not have zero
 	// depth, it has infinite depth.
 	ZeroDepth bool
+	// RootHref is the URL path of the root resource in the LOCK request,
+	// the Root is the path in alist that the base path of the user is joined.
+	RootHref string
 }
 
 // NewMemLS returns a new in-memory LockSystem.
@@ -116,7 +132,6 @@ func NewMemLS() LockSystem {
 	return &memLS{
 		byName:  make(map[string]*memLSNode),
 		byToken: make(map[string]*memLSNode),
-		gen:     uint64(time.Now().Unix()),
 	}
 }
 
@@ -124,15 +139,15 @@ type memLS struct {
 	mu      sync.Mutex
 	byName  map[string]*memLSNode
 	byToken map[string]*memLSNode
-	gen     uint64
 	// byExpiry only contains those nodes whose LockDetails have a finite
 	// Duration and are yet to expire.
 	byExpiry byExpiry
 }
 
+// nextToken returns a unique token, the clients such as Windows Explorer
+// require it to be an absolute URI, see RFC 4918 Appendix C.
 func (m *memLS) nextToken() string {
-	m.gen++
-	return strconv.FormatUint(m.gen, 10)
+	return "opaquelocktoken:" + uuid.NewString()
 }
 
 func (m *memLS) collectExpiredNodes(now time.Time) {
@@ -288,6 +303,22 @@ func (m *memLS) Unlock(now time.Time, token string) error {
 	return nil
 }
 
+func (m *memLS) Locks(now time.Time, name string) []ActiveLock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.collectExpiredNodes(now)
+
+	var locks []ActiveLock
+	walkToRoot(slashClean(name), func(name0 string, first bool) bool {
+		n := m.byName[name0]
+		if n != nil && n.token != "" && (first || !n.details.ZeroDepth) {
+			locks = append(locks, ActiveLock{Token: n.token, Details: n.details})
+		}
+		return true
+	})
+	return locks
+}
+
 func (m *memLS) canCreate(name string, zeroDepth bool) bool {
 	return walkToRoot(name, func(name0 string, first bool) bool {
 		n := m.byName[name0]
@@ -321,6 +321,47 @@ func TestMemLSNonCanonicalRoot(t *testing.T) {
 	}
 }
 
+func TestMemLSLocks(t *testing.T) {
+	now := time.Unix(0, 0)
+	m := NewMemLS().(*memLS)
+	create := func(root string, zeroDepth bool) string {
+		token, err := m.Create(now, LockDetails{
+			Root:      root,
+			Duration:  1 * time.Second,
+			ZeroDepth: zeroDepth,
+		})
+		if err != nil {
+			t.Fatalf("Create %q: %v", root, err)
+		}
+		return token
+	}
+	infinite := create("/a", false)
+	zero := create("/b", true)
+
+	testCases := []struct {
+		name string
+		want []string
+	}{
+		{"/", nil},
+		{"/a", []string{infinite}},
+		{"/a/c/d", []string{infinite}},
+		{"/b", []string{zero}},
+		{"/b/c", nil},
+	}
+	for _, tc := range testCases {
+		var got []string
+		for _, l := range m.Locks(now, tc.name) {
+			got = append(got, l.Token)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("name=%q: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	if got := m.Locks(now.Add(2*time.Second), "/a"); len(got) != 0 {
+		t.Errorf("after expiry: got %d locks, want 0", len(got))
+	}
+}
+
 func TestMemLSExpiry(t *testing.T) {
 	m := NewMemLS().(*memLS)
 	testCases := []string{
@@ -14,6 +14,8 @@ import (
 	"net/http"
 	"path"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/alist-org/alist/v3/internal/model"
 )
@@ -144,16 +146,18 @@ var liveProps = map[xml.Name]struct {
 	},
 	{Space: "DAV:", Local: "getetag"}: {
 		findFn: findETag,
-		// findETag implements ETag as the concatenated hex values of a file's
-		// modification time and size. This is not a reliable synchronization
+		// findETag implements ETag as the hash given by the provider, or the
+		// concatenated hex values of a file's modification time and size if
+		// there isn't. This is not a reliable synchronization
 		// mechanism for directories, so we do not advertise getetag for DAV
 		// collections.
 		dir: false,
 	},
 
-	// TODO: The lockdiscovery property requires LockSystem to list the
-	// active locks on a resource.
-	{Space: "DAV:", Local: "lockdiscovery"}: {},
+	{Space: "DAV:", Local: "lockdiscovery"}: {
+		findFn: findLockDiscovery,
+		dir:    true,
+	},
 	{Space: "DAV:", Local: "supportedlock"}: {
 		findFn: findSupportedLock,
 		dir:    true,
@@ -166,7 +170,7 @@ var liveProps = map[xml.Name]struct {
 //
 // Each Propstat has a unique status and each property name will only be part
 // of one Propstat element.
-func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name) ([]Propstat, error) {
+func props(ctx context.Context, ls LockSystem, name string, fi model.Obj, pnames []xml.Name) ([]Propstat, error) {
 	//f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
 	//if err != nil {
 	//	return nil, err
@@ -197,7 +201,7 @@ func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name)
 		}
 		// Otherwise, it must either be a live property or we don't know it.
 		if prop := liveProps[pn]; prop.findFn != nil && (prop.dir || !isDir) {
-			innerXML, err := prop.findFn(ctx, ls, fi.GetName(), fi)
+			innerXML, err := prop.findFn(ctx, ls, name, fi)
 			if err != nil {
 				return nil, err
 			}
@@ -256,7 +260,7 @@ func propnames(ctx context.Context, ls LockSystem, fi model.Obj) ([]xml.Name, er
 // returned if they are named in 'include'.
 //
 // See http://www.webdav.org/specs/rfc4918.html#METHOD_PROPFIND
-func allprop(ctx context.Context, ls LockSystem, fi model.Obj, include []xml.Name) ([]Propstat, error) {
+func allprop(ctx context.Context, ls LockSystem, name string, fi model.Obj, include []xml.Name) ([]Propstat, error) {
 	pnames, err := propnames(ctx, ls, fi)
 	if err != nil {
 		return nil, err
@@ -271,7 +275,7 @@ func allprop(ctx context.Context, ls LockSystem, fi model.Obj, include []xml.Nam
 			pnames = append(pnames, pn)
 		}
 	}
-	return props(ctx, ls, fi, pnames)
+	return props(ctx, ls, name, fi, pnames)
 }
 
 // Patch patches the properties of resource name. The return values are
@@ -460,12 +464,25 @@ func findETag(ctx context.Context, ls LockSystem, name string, fi model.Obj) (st
 			return etag, err
 		}
 	}
+	// the hash of the content only changes when the content changes,
+	// so the clients can skip downloading the unchanged files
+	if h := fi.GetHash(); !h.Empty() {
+		return fmt.Sprintf(`"%s:%s"`, h.Type, h.Value), nil
+	}
 	// The Apache http 2.4 web server by default concatenates the
 	// modification time and size of a file. We replicate the heuristic
 	// with nanosecond granularity.
 	return fmt.Sprintf(`"%x%x"`, fi.ModTime().UnixNano(), fi.GetSize()), nil
 }
 
+func findLockDiscovery(ctx context.Context, ls LockSystem, name string, fi model.Obj) (string, error) {
+	var b strings.Builder
+	for _, l := range ls.Locks(time.Now(), name) {
+		b.WriteString(activeLockXML(l.Token, l.Details))
+	}
+	return b.String(), nil
+}
+
 func findSupportedLock(ctx context.Context, ls LockSystem, name string, fi model.Obj) (string, error) {
 	return `` +
 		`<D:lockentry xmlns:D="DAV:">` +
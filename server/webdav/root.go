@@ -0,0 +1,83 @@
+package webdav
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	pkgerr "github.com/pkg/errors"
+)
+
+var errOutOfRoot = errors.New("webdav: the path is out of the root of the user")
+
+// root is the virtual root of the user, the paths of the requests are mapped under the
+// base path of the user, and the paths in alist are mapped back to the hrefs under the prefix
+type root struct {
+	prefix string
+	user   *model.User
+}
+
+func (h *Handler) root(ctx context.Context) root {
+	return root{prefix: h.Prefix, user: ctx.Value("user").(*model.User)}
+}
+
+// abs get the path in alist of the path of the request, it can't be out of the base path
+func (rt root) abs(reqPath string) string {
+	return utils.JoinBasePath(rt.user.BasePath, reqPath)
+}
+
+// href get the href of the path in alist, false if it's out of the root
+func (rt root) href(p string) (string, bool) {
+	base := utils.StandardizePath(rt.user.BasePath)
+	if !utils.IsSubPath(base, p) {
+		return "", false
+	}
+	rel := p
+	if base != "/" {
+		rel = strings.TrimPrefix(p, base)
+	}
+	return path.Join(rt.prefix, rel), true
+}
+
+// accessible check if the user can access the path without the password of the meta,
+// there is no way to input the password in webdav
+func (rt root) accessible(p string) (bool, error) {
+	if rt.user.CanAccessWithoutPassword() {
+		return true, nil
+	}
+	meta, err := db.GetNearestMeta(p)
+	if err != nil {
+		if pkgerr.Is(pkgerr.Cause(err), errs.MetaNotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+	if meta.Password == "" {
+		return true, nil
+	}
+	return !utils.PathEqual(meta.Path, p) && !meta.PSub, nil
+}
+
+// resolve get the path in alist of the url path of the request
+func (h *Handler) resolve(ctx context.Context, urlPath string) (string, int, error) {
+	reqPath, status, err := h.stripPrefix(urlPath)
+	if err != nil {
+		return "", status, err
+	}
+	rt := h.root(ctx)
+	p := rt.abs(reqPath)
+	ok, err := rt.accessible(p)
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	if !ok {
+		return "", http.StatusForbidden, errs.PermissionDenied
+	}
+	return p, http.StatusOK, nil
+}
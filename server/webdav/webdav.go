@@ -8,16 +8,21 @@ package webdav // import "golang.org/x/net/webdav"
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/diskcache"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
 	"github.com/alist-org/alist/v3/internal/sign"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
@@ -71,6 +76,10 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			status, err = h.handleProppatch(w, r)
 		}
 	}
+	// the operation is denied by the acl rules of the user
+	if status >= http.StatusBadRequest && errors.Is(err, errs.PermissionDenied) {
+		status = http.StatusForbidden
+	}
 
 	if status != 0 {
 		w.WriteHeader(status)
@@ -154,6 +163,8 @@ func (h *Handler) confirmLocks(r *http.Request, src, dst string) (release func()
 			if err != nil {
 				return nil, status, err
 			}
+			// the locks are named by the path in alist
+			lsrc = h.root(r.Context()).abs(lsrc)
 		}
 		release, err = h.LockSystem.Confirm(time.Now(), lsrc, dst, l.conditions...)
 		if err == ErrConfirmationFailed {
@@ -172,13 +183,11 @@ func (h *Handler) confirmLocks(r *http.Request, src, dst string) (release func()
 }
 
 func (h *Handler) handleOptions(w http.ResponseWriter, r *http.Request) (status int, err error) {
-	reqPath, status, err := h.stripPrefix(r.URL.Path)
+	ctx := r.Context()
+	reqPath, status, err := h.resolve(ctx, r.URL.Path)
 	if err != nil {
 		return status, err
 	}
-	ctx := r.Context()
-	user := ctx.Value("user").(*model.User)
-	reqPath = path.Join(user.BasePath, reqPath)
 	allow := "OPTIONS, LOCK, PUT, MKCOL"
 	if fi, err := fs.Get(ctx, reqPath); err == nil {
 		if fi.IsDir() {
@@ -196,14 +205,12 @@ func (h *Handler) handleOptions(w http.ResponseWriter, r *http.Request) (status
 }
 
 func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (status int, err error) {
-	reqPath, status, err := h.stripPrefix(r.URL.Path)
+	// TODO: check locks for read-only access??
+	ctx := r.Context()
+	reqPath, status, err := h.resolve(ctx, r.URL.Path)
 	if err != nil {
 		return status, err
 	}
-	// TODO: check locks for read-only access??
-	ctx := r.Context()
-	user := ctx.Value("user").(*model.User)
-	reqPath = path.Join(user.BasePath, reqPath)
 	fi, err := fs.Get(ctx, reqPath)
 	if err != nil {
 		return http.StatusNotFound, err
@@ -216,14 +223,20 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 		return http.StatusInternalServerError, err
 	}
 	w.Header().Set("ETag", etag)
+	if r.Method == http.MethodGet {
+		audit.RecordDownload(ctx, reqPath, fi, nil)
+	}
 	// Let ServeContent determine the Content-Type header.
 	storage, _ := fs.GetStorage(reqPath)
 	if storage.GetStorage().WebdavNative() {
+		if err := fs.CheckDownloadQuota(ctx); err != nil {
+			return http.StatusTooManyRequests, err
+		}
 		link, _, err := fs.Link(ctx, reqPath, model.LinkArgs{Header: r.Header})
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
-		err = common.Proxy(w, r, link, fi)
+		err = common.Proxy(ratelimit.NewResponseWriter(ctx, w, fs.DownloadLimiters(ctx, reqPath)...), r, diskcache.Wrap(reqPath, link, fi), fi)
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
@@ -231,7 +244,7 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 		u := fmt.Sprintf("%s/p%s?sign=%s",
 			common.GetApiUrl(r),
 			utils.EncodePath(reqPath, true),
-			sign.Sign(path.Base(reqPath)))
+			sign.Sign(reqPath, utils.ClientIP(r)))
 		http.Redirect(w, r, u, 302)
 	} else {
 		link, _, err := fs.Link(ctx, reqPath, model.LinkArgs{IP: utils.ClientIP(r)})
@@ -244,7 +257,8 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 }
 
 func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) (status int, err error) {
-	reqPath, status, err := h.stripPrefix(r.URL.Path)
+	ctx := r.Context()
+	reqPath, status, err := h.resolve(ctx, r.URL.Path)
 	if err != nil {
 		return status, err
 	}
@@ -253,10 +267,6 @@ func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) (status i
 		return status, err
 	}
 	defer release()
-
-	ctx := r.Context()
-	user := ctx.Value("user").(*model.User)
-	reqPath = path.Join(user.BasePath, reqPath)
 	// TODO: return MultiStatus where appropriate.
 
 	// "godoc os RemoveAll" says that "If the path does not exist, RemoveAll
@@ -275,8 +285,21 @@ func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) (status i
 	return http.StatusNoContent, nil
 }
 
+// contentLength get the size of the uploading body, the chunked uploads of some clients such as
+// the Finder of macOS give it in X-Expected-Entity-Length, -1 if it's unknown
+func contentLength(r *http.Request) int64 {
+	if r.ContentLength >= 0 {
+		return r.ContentLength
+	}
+	if size, err := strconv.ParseInt(r.Header.Get("X-Expected-Entity-Length"), 10, 64); err == nil && size >= 0 {
+		return size
+	}
+	return -1
+}
+
 func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int, err error) {
-	reqPath, status, err := h.stripPrefix(r.URL.Path)
+	ctx := r.Context()
+	reqPath, status, err := h.resolve(ctx, r.URL.Path)
 	if err != nil {
 		return status, err
 	}
@@ -287,12 +310,9 @@ func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int,
 	defer release()
 	// TODO(rost): Support the If-Match, If-None-Match headers? See bradfitz'
 	// comments in http.checkEtag.
-	ctx := r.Context()
-	user := ctx.Value("user").(*model.User)
-	reqPath = path.Join(user.BasePath, reqPath)
 	obj := model.Object{
 		Name:     path.Base(reqPath),
-		Size:     r.ContentLength,
+		Size:     contentLength(r),
 		Modified: time.Now(),
 	}
 	stream := &model.FileStream{
@@ -302,6 +322,9 @@ func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int,
 	}
 	err = fs.PutDirectly(ctx, path.Dir(reqPath), stream)
 
+	if errors.Is(err, errs.TrafficQuotaExceeded) {
+		return http.StatusTooManyRequests, err
+	}
 	// TODO(rost): Returning 405 Method Not Allowed might not be appropriate.
 	if err != nil {
 		return http.StatusMethodNotAllowed, err
@@ -320,7 +343,8 @@ func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int,
 }
 
 func (h *Handler) handleMkcol(w http.ResponseWriter, r *http.Request) (status int, err error) {
-	reqPath, status, err := h.stripPrefix(r.URL.Path)
+	ctx := r.Context()
+	reqPath, status, err := h.resolve(ctx, r.URL.Path)
 	if err != nil {
 		return status, err
 	}
@@ -330,10 +354,6 @@ func (h *Handler) handleMkcol(w http.ResponseWriter, r *http.Request) (status in
 	}
 	defer release()
 
-	ctx := r.Context()
-	user := ctx.Value("user").(*model.User)
-	reqPath = path.Join(user.BasePath, reqPath)
-
 	if r.ContentLength > 0 {
 		return http.StatusUnsupportedMediaType, nil
 	}
@@ -378,9 +398,17 @@ func (h *Handler) handleCopyMove(w http.ResponseWriter, r *http.Request) (status
 	}
 
 	ctx := r.Context()
-	user := ctx.Value("user").(*model.User)
-	src = path.Join(user.BasePath, src)
-	dst = path.Join(user.BasePath, dst)
+	rt := h.root(ctx)
+	src, dst = rt.abs(src), rt.abs(dst)
+	for _, p := range []string{src, dst} {
+		ok, err := rt.accessible(p)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if !ok {
+			return http.StatusForbidden, errs.PermissionDenied
+		}
+	}
 
 	if r.Method == "COPY" {
 		// Section 7.5.1 says that a COPY only needs to lock the destination,
@@ -436,7 +464,6 @@ func (h *Handler) handleLock(w http.ResponseWriter, r *http.Request) (retStatus
 	}
 
 	ctx := r.Context()
-	user := ctx.Value("user").(*model.User)
 	token, ld, now, created := "", LockDetails{}, time.Now(), false
 	if li == (lockInfo{}) {
 		// An empty lockInfo means to refresh the lock.
@@ -470,16 +497,17 @@ func (h *Handler) handleLock(w http.ResponseWriter, r *http.Request) (retStatus
 				return http.StatusBadRequest, errInvalidDepth
 			}
 		}
-		reqPath, status, err := h.stripPrefix(r.URL.Path)
-		reqPath = path.Join(user.BasePath, reqPath)
+		reqPath, status, err := h.resolve(ctx, r.URL.Path)
 		if err != nil {
 			return status, err
 		}
+		href, _ := h.root(ctx).href(reqPath)
 		ld = LockDetails{
 			Root:      reqPath,
 			Duration:  duration,
 			OwnerXML:  li.Owner.InnerXML,
 			ZeroDepth: depth == 0,
+			RootHref:  href,
 		}
 		token, err = h.LockSystem.Create(now, ld)
 		if err != nil {
@@ -494,17 +522,30 @@ func (h *Handler) handleLock(w http.ResponseWriter, r *http.Request) (retStatus
 			}
 		}()
 
-		// ??? Why create resource here?
-		//// Create the resource if it didn't previously exist.
-		//if _, err := h.FileSystem.Stat(ctx, reqPath); err != nil {
-		//	f, err := h.FileSystem.OpenFile(ctx, reqPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
-		//	if err != nil {
-		//		// TODO: detect missing intermediate dirs and return http.StatusConflict?
-		//		return http.StatusInternalServerError, err
-		//	}
-		//	f.Close()
-		//	created = true
-		//}
+		// Create the resource if it didn't previously exist, the clients such as
+		// MS Office lock the new file before writing it, and check it exists.
+		if _, err := fs.Get(ctx, reqPath); err != nil {
+			if !errs.IsObjectNotFound(err) {
+				return http.StatusInternalServerError, err
+			}
+			if _, err := fs.Get(ctx, path.Dir(reqPath)); err != nil {
+				return http.StatusConflict, err
+			}
+			obj := model.Object{
+				Name:     path.Base(reqPath),
+				Modified: now,
+			}
+			stream := &model.FileStream{
+				Obj:        &obj,
+				ReadCloser: io.NopCloser(strings.NewReader("")),
+				Mimetype:   "application/octet-stream",
+			}
+			if err := fs.PutDirectly(ctx, path.Dir(reqPath), stream); err != nil {
+				return http.StatusInternalServerError, err
+			}
+			fs.ClearCache(path.Dir(reqPath))
+			created = true
+		}
 
 		// http://www.webdav.org/specs/rfc4918.html#HEADER_Lock-Token says that the
 		// Lock-Token value is a Coded-URL. We add angle brackets.
@@ -546,13 +587,11 @@ func (h *Handler) handleUnlock(w http.ResponseWriter, r *http.Request) (status i
 }
 
 func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status int, err error) {
-	reqPath, status, err := h.stripPrefix(r.URL.Path)
+	ctx := r.Context()
+	reqPath, status, err := h.resolve(ctx, r.URL.Path)
 	if err != nil {
 		return status, err
 	}
-	ctx := r.Context()
-	user := ctx.Value("user").(*model.User)
-	reqPath = path.Join(user.BasePath, reqPath)
 	fi, err := fs.Get(ctx, reqPath)
 	if err != nil {
 		if errs.IsObjectNotFound(err) {
@@ -573,6 +612,7 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 	}
 
 	mw := multistatusWriter{w: w}
+	rt := h.root(ctx)
 
 	walkFn := func(reqPath string, info model.Obj, err error) error {
 		if err != nil {
@@ -590,21 +630,24 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 			}
 			pstats = append(pstats, pstat)
 		} else if pf.Allprop != nil {
-			pstats, err = allprop(ctx, h.LockSystem, info, pf.Prop)
+			pstats, err = allprop(ctx, h.LockSystem, reqPath, info, pf.Prop)
 		} else {
-			pstats, err = props(ctx, h.LockSystem, info, pf.Prop)
+			pstats, err = props(ctx, h.LockSystem, reqPath, info, pf.Prop)
 		}
 		if err != nil {
 			return err
 		}
-		href := path.Join(h.Prefix, strings.TrimPrefix(reqPath, user.BasePath))
+		href, ok := rt.href(reqPath)
+		if !ok {
+			return errOutOfRoot
+		}
 		if href != "/" && info.IsDir() {
 			href += "/"
 		}
 		return mw.write(makePropstatResponse(href, pstats))
 	}
 
-	walkErr := walkFS(ctx, depth, reqPath, fi, walkFn)
+	walkErr := walkFS(ctx, rt, depth, reqPath, fi, walkFn)
 	closeErr := mw.close()
 	if walkErr != nil {
 		return http.StatusInternalServerError, walkErr
@@ -616,7 +659,8 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 }
 
 func (h *Handler) handleProppatch(w http.ResponseWriter, r *http.Request) (status int, err error) {
-	reqPath, status, err := h.stripPrefix(r.URL.Path)
+	ctx := r.Context()
+	reqPath, status, err := h.resolve(ctx, r.URL.Path)
 	if err != nil {
 		return status, err
 	}
@@ -626,10 +670,6 @@ func (h *Handler) handleProppatch(w http.ResponseWriter, r *http.Request) (statu
 	}
 	defer release()
 
-	ctx := r.Context()
-	user := ctx.Value("user").(*model.User)
-	reqPath = path.Join(user.BasePath, reqPath)
-
 	if _, err := fs.Get(ctx, reqPath); err != nil {
 		if errs.IsObjectNotFound(err) {
 			return http.StatusNotFound, err